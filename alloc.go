@@ -0,0 +1,36 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package ojg
+
+// Allocator provides the byte buffers used by the writers and parsers. An
+// application can provide an Allocator to use a region allocator or to
+// instrument allocations. When no Allocator is set buffers are allocated
+// with make and released buffers are left to the garbage collector.
+type Allocator interface {
+	// Get returns a buffer with a length of zero and a capacity of at least
+	// size.
+	Get(size int) []byte
+
+	// Put releases a buffer that is no longer used.
+	Put(buf []byte)
+}
+
+// Alloc returns an empty buffer with a capacity of at least size from the
+// allocator or from make if the allocator is nil.
+func Alloc(a Allocator, size int) []byte {
+	if a == nil {
+		return make([]byte, 0, size)
+	}
+	if buf := a.Get(size); size <= cap(buf) {
+		return buf[:0]
+	}
+	return make([]byte, 0, size)
+}
+
+// Free releases a buffer to the allocator if the allocator is not nil and
+// the buffer has some capacity.
+func Free(a Allocator, buf []byte) {
+	if a != nil && 0 < cap(buf) {
+		a.Put(buf[:0])
+	}
+}
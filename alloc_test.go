@@ -0,0 +1,67 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package ojg_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/pretty"
+	"github.com/ohler55/ojg/sen"
+	"github.com/ohler55/ojg/tt"
+)
+
+type countAlloc struct {
+	gets int
+	puts int
+}
+
+func (ca *countAlloc) Get(size int) []byte {
+	ca.gets++
+	return make([]byte, 0, size)
+}
+
+func (ca *countAlloc) Put(buf []byte) {
+	ca.puts++
+}
+
+type shortAlloc struct{}
+
+func (shortAlloc) Get(size int) []byte { return nil }
+func (shortAlloc) Put(buf []byte)      {}
+
+func TestAllocator(t *testing.T) {
+	var ca countAlloc
+	wr := oj.Writer{Options: ojg.Options{Allocator: &ca, InitSize: 64}}
+	tt.Equal(t, `[1,2]`, wr.JSON([]any{1, 2}))
+	tt.Equal(t, 1, ca.gets)
+	wr.InitSize = 4096
+	tt.Equal(t, `[1,2]`, wr.JSON([]any{1, 2}))
+	tt.Equal(t, 2, ca.gets)
+	tt.Equal(t, 1, ca.puts)
+
+	ca = countAlloc{}
+	p := oj.Parser{Allocator: &ca}
+	v, err := p.ParseReader(strings.NewReader(`{"a":[1,2]}`))
+	tt.Nil(t, err)
+	tt.Equal(t, map[string]any{"a": []any{int64(1), int64(2)}}, v)
+	tt.Equal(t, 2, ca.gets) // tmp and read buffers
+	tt.Equal(t, 1, ca.puts)
+
+	ca = countAlloc{}
+	sp := sen.Parser{Allocator: &ca}
+	v, err = sp.ParseReader(strings.NewReader(`{a:[1 2]}`))
+	tt.Nil(t, err)
+	tt.Equal(t, map[string]any{"a": []any{int64(1), int64(2)}}, v)
+	tt.Equal(t, 1, ca.puts)
+
+	ca = countAlloc{}
+	pw := pretty.Writer{Options: ojg.Options{Allocator: &ca}, Width: 80, MaxDepth: 3}
+	tt.Equal(t, "[1, 2]", string(pw.Encode([]any{1, 2})))
+	tt.Equal(t, 1, ca.gets)
+
+	// An allocator that returns buffers that are too small is not trusted.
+	tt.Equal(t, 8, cap(ojg.Alloc(shortAlloc{}, 8)))
+}
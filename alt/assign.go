@@ -0,0 +1,147 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package alt
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/ohler55/ojg"
+)
+
+// Assign copies the fields of the src struct to the fields of the dst
+// struct with the same JSON keys using the DefaultRecomposer. See
+// Recomposer.Assign() for details.
+func Assign(dst, src any, options ...*ojg.Options) (unmatched []string, err error) {
+	return DefaultRecomposer.Assign(dst, src, options...)
+}
+
+// Assign copies the fields of the src struct to the fields of the dst
+// struct, which must be a pointer to a struct, that have the same JSON
+// keys. The src and dst are usually different types such as a DTO and a
+// domain type. The src keys are resolved as they are by Decompose() with
+// the options provided, including the UseTags and KeyFunc options, and the
+// dst keys as they are by Recompose(). If no options are provided json tags
+// are used. Values that can be assigned or converted directly are, others
+// such as nested structs of a different type are decomposed and recomposed
+// into the dst field. That avoids a marshal and unmarshal round trip. The
+// keys of the src fields that do not match a dst field are returned in the
+// order of the src fields.
+func (r *Recomposer) Assign(dst, src any, options ...*ojg.Options) (unmatched []string, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			if tm, ok := rec.(*ojg.ErrTypeMismatch); ok {
+				tm.Path = "$" + tm.Path
+			}
+			err = ojg.NewError(rec)
+			unmatched = nil
+		}
+	}()
+	opt := DefaultOptions
+	opt.CreateKey = ""
+	opt.UseTags = true
+	if 0 < len(options) {
+		opt = *options[0]
+	}
+	dv := reflect.ValueOf(dst)
+	if dv.Kind() != reflect.Ptr || dv.IsNil() || dv.Elem().Kind() != reflect.Struct {
+		panic(fmt.Errorf("assign destination must be a non-nil pointer to a struct, not a %T", dst))
+	}
+	dv = dv.Elem()
+	sv := reflect.ValueOf(src)
+	if sv.Kind() == reflect.Ptr && !sv.IsNil() {
+		sv = sv.Elem()
+	}
+	if sv.Kind() != reflect.Struct {
+		panic(fmt.Errorf("assign source must be a struct or a pointer to a struct, not a %T", src))
+	}
+	si := getSinfo(sv.Interface(), false)
+	var keys []string
+	vals := map[string]reflect.Value{}
+	for _, fi := range si.getFields(&opt) {
+		if !fi.inGroups(opt.Groups) {
+			continue
+		}
+		fv, ferr := sv.FieldByIndexErr(fi.index)
+		if ferr != nil { // nil embedded pointer
+			continue
+		}
+		key := fi.outKey(&opt)
+		keys = append(keys, key)
+		vals[key] = fv
+	}
+	var im map[string]reflect.StructField
+	if c := r.types[dv.Type()]; c != nil {
+		im = c.indexes
+	} else {
+		im = indexType(dv.Type())
+	}
+	used := map[string]bool{}
+	for k, sf := range im {
+		key := k
+		fv, has := vals[key]
+		if !has {
+			key = sf.Name
+			if fv, has = vals[key]; !has {
+				name := []byte(sf.Name)
+				name[0] |= 0x20
+				key = string(name)
+				if fv, has = vals[key]; !has {
+					key = strings.ToLower(key)
+					fv, has = vals[key]
+				}
+			}
+		}
+		if !has {
+			continue
+		}
+		used[key] = true
+		sf := sf
+		if df := fieldByIndex(dv, sf.Index); df.IsValid() {
+			r.assignField(df, fv, &sf, key, &opt)
+		}
+	}
+	for _, k := range keys {
+		if !used[k] {
+			unmatched = append(unmatched, k)
+		}
+	}
+	return
+}
+
+func (r *Recomposer) assignField(dv, sv reflect.Value, sf *reflect.StructField, key string, opt *Options) {
+	switch {
+	case sv.Type().AssignableTo(dv.Type()):
+		dv.Set(sv)
+	case convertibleKinds(sv.Kind(), dv.Kind()):
+		dv.Set(sv.Convert(dv.Type()))
+	default:
+		if v := decompose(sv.Interface(), opt); v != nil {
+			r.setValueAt(v, dv, sf, key, -1)
+		}
+	}
+}
+
+// convertibleKinds returns true if a value of one kind can be converted to
+// the other without changing its meaning. Conversions such as an int to a
+// string are not included.
+func convertibleKinds(from, to reflect.Kind) bool {
+	switch {
+	case isNumberKind(from) && isNumberKind(to):
+		return true
+	case from == to:
+		return from == reflect.Bool || from == reflect.String
+	}
+	return false
+}
+
+func isNumberKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
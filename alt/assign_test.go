@@ -0,0 +1,84 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package alt_test
+
+import (
+	"testing"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/alt"
+	"github.com/ohler55/ojg/tt"
+)
+
+type assignAddrDTO struct {
+	Street string `json:"street"`
+	Zip    int    `json:"zip"`
+}
+
+type assignDTO struct {
+	ID       int64          `json:"id"`
+	FullName string         `json:"name"`
+	Score    float32        `json:"score"`
+	Address  *assignAddrDTO `json:"address"`
+	Tags     []string       `json:"tags"`
+	Extra    string         `json:"extra"`
+}
+
+type assignAddr struct {
+	Street string
+	Zip    int64
+}
+
+type assignUser struct {
+	ID      int
+	Name    string
+	Score   float64
+	Address assignAddr
+	Tags    []string
+	Missing bool
+}
+
+func TestAssign(t *testing.T) {
+	src := assignDTO{
+		ID:       7,
+		FullName: "Ann",
+		Score:    2.5,
+		Address:  &assignAddrDTO{Street: "Main", Zip: 12345},
+		Tags:     []string{"a", "b"},
+		Extra:    "x",
+	}
+	var dst assignUser
+	unmatched, err := alt.Assign(&dst, &src)
+	tt.Nil(t, err)
+	tt.Equal(t, []string{"extra"}, unmatched)
+	tt.Equal(t, 7, dst.ID)
+	tt.Equal(t, "Ann", dst.Name)
+	tt.Equal(t, 2.5, dst.Score)
+	tt.Equal(t, "Main", dst.Address.Street)
+	tt.Equal(t, 12345, dst.Address.Zip)
+	tt.Equal(t, []string{"a", "b"}, dst.Tags)
+
+	// Keys from a KeyFunc.
+	type camel struct {
+		FirstName string
+	}
+	type snake struct {
+		FirstName string `json:"first_name"`
+	}
+	var sn snake
+	unmatched, err = alt.Assign(&sn, camel{FirstName: "Bob"}, &ojg.Options{KeyFunc: ojg.SnakeCase})
+	tt.Nil(t, err)
+	tt.Equal(t, 0, len(unmatched))
+	tt.Equal(t, "Bob", sn.FirstName)
+
+	_, err = alt.Assign(dst, &src)
+	tt.NotNil(t, err)
+	_, err = alt.Assign(&dst, 3)
+	tt.NotNil(t, err)
+
+	type badAddr struct {
+		Address []int
+	}
+	_, err = alt.Assign(&badAddr{}, &src)
+	tt.NotNil(t, err)
+}
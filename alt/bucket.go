@@ -0,0 +1,138 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package alt
+
+import (
+	"math"
+	"time"
+
+	"github.com/ohler55/ojg"
+)
+
+// Bucket describes how numbers and times are floored into buckets. A Bucket
+// is converted into a Converter with the Converter() method and can then be
+// used with Convert() or as the Converter option for Decompose() and Alter().
+type Bucket struct {
+	// Size is the width of numeric buckets. Integers and floats are floored
+	// to a multiple of Size so with a Size of 10 the value 27 becomes 20 and
+	// -3 becomes -10. A zero Size leaves numbers unchanged.
+	Size float64
+
+	// Period is the width of time buckets such as time.Minute, time.Hour,
+	// or 24 * time.Hour. Strings in RFC3339 or 2006-01-02 form are parsed
+	// and floored to a multiple of Period in UTC and replaced with a
+	// time.Time. The time.Time elements of arrays and members of objects
+	// are floored the same way. A zero Period leaves strings and times
+	// unchanged.
+	Period time.Duration
+
+	// Keys if not empty restricts bucketing to the values of object members
+	// with one of the keys. Otherwise all values are bucketed.
+	Keys []string
+}
+
+// Converter returns a Converter that applies the bucketing.
+func (b *Bucket) Converter() *Converter {
+	var c ojg.Converter
+	if 0 < len(b.Keys) {
+		keys := make(map[string]bool, len(b.Keys))
+		for _, k := range b.Keys {
+			keys[k] = true
+		}
+		c.Map = []func(val map[string]any) (any, bool){
+			func(val map[string]any) (any, bool) {
+				for k, v := range val {
+					if keys[k] {
+						if bv, ok := b.bucket(v); ok {
+							val[k] = bv
+						}
+					}
+				}
+				return val, false
+			},
+		}
+		return &c
+	}
+	if b.Size != 0 {
+		c.Int = []func(val int64) (any, bool){
+			func(val int64) (any, bool) { return b.bucketInt(val), true },
+		}
+		c.Float = []func(val float64) (any, bool){
+			func(val float64) (any, bool) { return b.bucketFloat(val), true },
+		}
+	}
+	if b.Period != 0 {
+		c.String = []func(val string) (any, bool){b.bucketString}
+		// A Converter has no functions for time.Time values so, as with
+		// Keys, they are bucketed as the members of arrays and objects.
+		c.Array = []func(val []any) (any, bool){
+			func(val []any) (any, bool) {
+				for i, v := range val {
+					if tv, ok := v.(time.Time); ok {
+						val[i] = b.bucketTime(tv)
+					}
+				}
+				return val, false
+			},
+		}
+		c.Map = []func(val map[string]any) (any, bool){
+			func(val map[string]any) (any, bool) {
+				for k, v := range val {
+					if tv, ok := v.(time.Time); ok {
+						val[k] = b.bucketTime(tv)
+					}
+				}
+				return val, false
+			},
+		}
+	}
+	return &c
+}
+
+func (b *Bucket) bucket(v any) (any, bool) {
+	if b.Size != 0 {
+		switch tv := v.(type) {
+		case int64:
+			return b.bucketInt(tv), true
+		case int:
+			return b.bucketInt(int64(tv)), true
+		case float64:
+			return b.bucketFloat(tv), true
+		}
+	}
+	if b.Period != 0 {
+		switch tv := v.(type) {
+		case string:
+			return b.bucketString(tv)
+		case time.Time:
+			return b.bucketTime(tv), true
+		}
+	}
+	return v, false
+}
+
+func (b *Bucket) bucketInt(v int64) any {
+	if size := int64(b.Size); float64(size) == b.Size {
+		r := v % size
+		if r < 0 {
+			r += size
+		}
+		return v - r
+	}
+	return b.bucketFloat(float64(v))
+}
+
+func (b *Bucket) bucketFloat(v float64) any {
+	return math.Floor(v/b.Size) * b.Size
+}
+
+func (b *Bucket) bucketString(v string) (any, bool) {
+	if t, ok := ojg.TimeRFC3339Converter.String[0](v); ok {
+		return b.bucketTime(t.(time.Time)), true
+	}
+	return v, false
+}
+
+func (b *Bucket) bucketTime(v time.Time) time.Time {
+	return v.UTC().Truncate(b.Period)
+}
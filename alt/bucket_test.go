@@ -0,0 +1,65 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package alt_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ohler55/ojg/alt"
+	"github.com/ohler55/ojg/tt"
+)
+
+func TestBucketNumbers(t *testing.T) {
+	b := alt.Bucket{Size: 10}
+	val := []any{int64(27), int64(-3), int64(30), 12.5, -0.5, "27"}
+	tt.Equal(t, []any{int64(20), int64(-10), int64(30), 10.0, -10.0, "27"}, b.Converter().Convert(val))
+
+	b = alt.Bucket{Size: 0.25}
+	tt.Equal(t, []any{1.0, 1.5}, alt.Decompose([]any{1, 1.6}, &alt.Options{Converter: b.Converter()}))
+}
+
+func TestBucketTimes(t *testing.T) {
+	b := alt.Bucket{Period: time.Hour}
+	val := map[string]any{
+		"at":    "2025-03-05T10:41:12.123Z",
+		"local": "2025-03-05T10:41:12-05:00",
+		"day":   "2025-03-05",
+		"name":  "not a time",
+		"count": int64(17),
+	}
+	tt.Equal(t, map[string]any{
+		"at":    time.Date(2025, 3, 5, 10, 0, 0, 0, time.UTC),
+		"local": time.Date(2025, 3, 5, 15, 0, 0, 0, time.UTC),
+		"day":   time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC),
+		"name":  "not a time",
+		"count": int64(17),
+	}, b.Converter().Convert(val))
+
+	b = alt.Bucket{Period: 24 * time.Hour}
+	tt.Equal(t, time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC), b.Converter().Convert("2025-03-05T23:59:59Z"))
+
+	// time.Time values are bucketed with or without Keys.
+	at := time.Date(2025, 3, 5, 10, 41, 12, 0, time.FixedZone("EST", -5*3600))
+	expect := time.Date(2025, 3, 5, 15, 0, 0, 0, time.UTC)
+	for _, b := range []alt.Bucket{{Period: time.Hour}, {Period: time.Hour, Keys: []string{"at"}}} {
+		val := map[string]any{"at": at, "list": []any{at}}
+		out := b.Converter().Convert(val).(map[string]any)
+		tt.Equal(t, expect, out["at"])
+		if len(b.Keys) == 0 {
+			tt.Equal(t, []any{expect}, out["list"])
+		}
+	}
+}
+
+func TestBucketKeys(t *testing.T) {
+	b := alt.Bucket{Size: 100, Period: time.Minute, Keys: []string{"amount", "at"}}
+	val := []any{
+		map[string]any{"amount": int64(250), "id": int64(250), "at": "2025-03-05T10:41:12Z"},
+		map[string]any{"nested": map[string]any{"amount": 99.5, "at": time.Date(2025, 3, 5, 1, 2, 3, 0, time.UTC)}},
+	}
+	tt.Equal(t, []any{
+		map[string]any{"amount": int64(200), "id": int64(250), "at": time.Date(2025, 3, 5, 10, 41, 0, 0, time.UTC)},
+		map[string]any{"nested": map[string]any{"amount": 0.0, "at": time.Date(2025, 3, 5, 1, 2, 0, 0, time.UTC)}},
+	}, b.Converter().Convert(val))
+}
@@ -14,44 +14,59 @@ type composer struct {
 	full    string
 	rtype   reflect.Type
 	indexes map[string]reflect.StructField
+	// parsed default tag values keyed the same as indexes
+	defaults map[string]*fieldDefault
+	// migrations keyed by the version they upgrade from
+	migrations map[int64]MigrateFunc
 }
 
 func indexType(rt reflect.Type) (im map[string]reflect.StructField) {
-	i := rt.NumField()
-	if 0 < i {
+	if 0 < rt.NumField() {
 		im = map[string]reflect.StructField{}
-		for i--; 0 <= i; i-- {
-			f := rt.Field(i)
-			if 0 < len(f.PkgPath) {
-				continue
-			}
-			if f.Anonymous {
-				fim := indexType(f.Type)
-				// prepend index and add to im
-				for k := range fim {
-					ff := fim[k]
-					ff.Index = append([]int{i}, ff.Index...)
-					im[k] = ff
-				}
-			} else if k, _ := f.Tag.Lookup("json"); 0 < len(k) {
-				parts := strings.Split(k, ",")
-				switch parts[0] {
-				case "":
-					k = strings.ToLower(f.Name)
-				case "-":
-					if 1 < len(parts) {
-						k = "-"
-					} else {
-						continue
-					}
-				default:
-					k = parts[0]
+		for _, sf := range StructFields(rt, true, true) {
+			k := sf.Name
+			if tag := sf.Tag.Get("json"); 0 < len(tag) {
+				if sf.Tagged {
+					k = sf.Key
+				} else {
+					k = strings.ToLower(sf.Name)
 				}
-				im[k] = f
-			} else {
-				im[f.Name] = f
 			}
+			im[k] = sf.StructField
+		}
+	}
+	return
+}
+
+// indexDefaults parses the default tags of the indexed fields once so they
+// are not parsed again each time a value is recomposed.
+func indexDefaults(im map[string]reflect.StructField) (dm map[string]*fieldDefault) {
+	for k, sf := range im {
+		if def, ok := sf.Tag.Lookup("default"); ok {
+			if dm == nil {
+				dm = map[string]*fieldDefault{}
+			}
+			dm[k] = parseDefault(def, sf.Type)
 		}
 	}
 	return
 }
+
+// fieldByIndex returns the field at the index path, allocating any nil
+// embedded pointers along the way. An invalid value is returned if a nil
+// embedded pointer can not be set because it is unexported.
+func fieldByIndex(rv reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if 0 < i && rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				if !rv.CanSet() {
+					return reflect.Value{}
+				}
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+	return rv
+}
@@ -4,7 +4,6 @@ package alt
 
 import (
 	"encoding/base64"
-	"fmt"
 	"math"
 	"reflect"
 	"time"
@@ -176,6 +175,9 @@ func reflectValue(rv reflect.Value, val any, opt *Options) (v any) {
 	case reflect.Complex64, reflect.Complex128:
 		v = reflectComplex(rv, opt)
 	case reflect.Map:
+		if rv.IsNil() && opt.NilCollections == ojg.NilAsNull {
+			return nil
+		}
 		v = reflectMap(rv, opt)
 	case reflect.Ptr:
 		elem := rv.Elem()
@@ -185,6 +187,9 @@ func reflectValue(rv reflect.Value, val any, opt *Options) (v any) {
 			v = nil
 		}
 	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() && opt.NilCollections == ojg.NilAsNull {
+			return nil
+		}
 		v = reflectArray(rv, opt)
 	case reflect.Struct:
 		v = reflectStruct(rv, val, opt)
@@ -219,6 +224,9 @@ func reflectStruct(rv reflect.Value, val any, opt *Options) any {
 	fields := si.getFields(opt)
 	addr := rv.UnsafeAddr()
 	for _, fi := range fields {
+		if !fi.inGroups(opt.Groups) || fi.unreachable(rv) || (fi.omitZero || opt.OmitZero) && fi.isZero(rv) {
+			continue
+		}
 		if v, fv, omit := fi.value(fi, rv, addr); !omit {
 			if fv.IsValid() {
 				if opt.NestEmbed && fv.Kind() == reflect.Struct {
@@ -227,7 +235,7 @@ func reflectStruct(rv reflect.Value, val any, opt *Options) any {
 					v = decompose(v, opt)
 				}
 			}
-			condMapSet(obj, fi.key, v, opt)
+			condMapSet(obj, fi.outKey(opt), v, opt)
 		}
 	}
 	return obj
@@ -246,6 +254,9 @@ func reflectEmbed(rv reflect.Value, val any, opt *Options) any {
 	}
 	fields := si.getFields(opt)
 	for _, fi := range fields {
+		if !fi.inGroups(opt.Groups) || fi.unreachable(rv) || (fi.omitZero || opt.OmitZero) && fi.isZero(rv) {
+			continue
+		}
 		if v, fv, omit := fi.ivalue(fi, rv, 0); !omit {
 			if fv.IsValid() {
 				if opt.NestEmbed && fv.Kind() == reflect.Struct {
@@ -254,7 +265,7 @@ func reflectEmbed(rv reflect.Value, val any, opt *Options) any {
 					v = decompose(v, opt)
 				}
 			}
-			condMapSet(obj, fi.key, v, opt)
+			condMapSet(obj, fi.outKey(opt), v, opt)
 		}
 	}
 	return obj
@@ -276,20 +287,12 @@ func reflectMap(rv reflect.Value, opt *Options) any {
 	obj := map[string]any{}
 	it := rv.MapRange()
 	for it.Next() {
-		k := it.Key().Interface()
 		var g any
 		vv := it.Value()
 		if !isNil(vv) {
 			g = decompose(vv.Interface(), opt)
 		}
-		var (
-			ks string
-			ok bool
-		)
-		if ks, ok = k.(string); !ok {
-			ks = fmt.Sprint(k)
-		}
-		condMapSet(obj, ks, g, opt)
+		condMapSet(obj, mapKeyString(it.Key()), g, opt)
 	}
 	return obj
 }
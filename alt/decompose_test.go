@@ -373,3 +373,37 @@ func BenchmarkDecompose(b *testing.B) {
 		_ = alt.Decompose(&a, &alt.Options{UseTags: true})
 	}
 }
+
+func TestDecomposeOmitZero(t *testing.T) {
+	type Sample struct {
+		At    time.Time `json:"at,omitzero"`
+		Count int       `json:"count,omitzero"`
+		Name  string    `json:"name"`
+	}
+	v := alt.Decompose(&Sample{}, &alt.Options{UseTags: true})
+	tt.Equal(t, map[string]any{"name": ""}, v)
+
+	v = alt.Decompose(&Sample{Count: 3}, &alt.Options{OmitZero: true})
+	tt.Equal(t, map[string]any{"count": 3}, v)
+}
+
+func TestDecomposeGroups(t *testing.T) {
+	type Sample struct {
+		Name   string
+		Secret string `ojg:"groups=admin"`
+	}
+	v := alt.Decompose(&Sample{Name: "x", Secret: "y"}, &alt.Options{Groups: []string{"public"}})
+	tt.Equal(t, map[string]any{"name": "x"}, v)
+
+	v = alt.Decompose(&Sample{Name: "x", Secret: "y"}, &alt.Options{Groups: []string{"admin"}})
+	tt.Equal(t, map[string]any{"name": "x", "secret": "y"}, v)
+}
+
+func TestDecomposeKeyFunc(t *testing.T) {
+	type Sample struct {
+		UserID int
+		Name   string `json:"n"`
+	}
+	v := alt.Decompose(&Sample{UserID: 1, Name: "x"}, &alt.Options{KeyFunc: ojg.SnakeCase, UseTags: true})
+	tt.Equal(t, map[string]any{"user_id": 1, "n": "x"}, v)
+}
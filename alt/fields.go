@@ -0,0 +1,180 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package alt
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// StructField is a field of a struct type that is encoded along with the
+// path to it through any anonymous struct fields.
+type StructField struct {
+	reflect.StructField
+
+	// Key is the json tag name if the fields were resolved by tag and the
+	// field has a tag name, otherwise the field name. A json tag of "-,"
+	// gives a key of "-".
+	Key string
+
+	// Tagged is true if the key was taken from a json tag.
+	Tagged bool
+
+	// Offset is the offset of the field from the start of the outermost
+	// struct. It is only valid if Indirect is false.
+	Offset uintptr
+
+	// Indirect is true if the path to the field passes through an
+	// embedded pointer.
+	Indirect bool
+}
+
+type embedLevel struct {
+	rt       reflect.Type
+	index    []int
+	offset   uintptr
+	indirect bool
+}
+
+// StructFields returns the fields of a struct type that are encoded
+// following the rules of encoding/json. If promote is true the fields of
+// anonymous struct fields, including those of unexported struct types and
+// those reached through pointers, are promoted to the outer struct. A
+// promoted field with the same key as a field at a shallower depth is
+// hidden by the shallower field. When more than one field with the same
+// key is at the shallowest depth the one with a json tag wins and if that
+// does not resolve the conflict all of them are dropped. If byTag is true
+// json tags provide keys, fields tagged with "-" are skipped, and an
+// anonymous struct field with a tag name is not promoted. The fields of a
+// struct field with a json tag option of inline or squash, such as
+// `json:",inline"`, are always promoted whether or not the field is
+// anonymous or the json tags are used for keys. The Index of each
+// returned field is the full index from the outer struct and the fields are
+// in index order.
+func StructFields(rt reflect.Type, byTag, promote bool) []StructField {
+	var fields []StructField
+	visited := map[reflect.Type]bool{}
+	next := []embedLevel{{rt: rt}}
+	for 0 < len(next) {
+		current := next
+		next = nil
+		// A type embedded more than once at the same depth is walked for
+		// each so the duplicate keys cancel out as they do with
+		// encoding/json. Types seen at a shallower depth are not walked
+		// again since all their fields would be hidden.
+		for _, lev := range current {
+			if visited[lev.rt] {
+				continue
+			}
+			for i := 0; i < lev.rt.NumField(); i++ {
+				f := lev.rt.Field(i)
+				var name string
+				tag := f.Tag.Get("json")
+				if byTag {
+					if tag == "-" {
+						continue
+					}
+					name, _, _ = strings.Cut(tag, ",")
+				}
+				index := append(append(make([]int, 0, len(lev.index)+1), lev.index...), i)
+				if f.Anonymous && promote && len(name) == 0 || inlineTag(tag) && (f.Anonymous || f.IsExported()) {
+					et := f.Type
+					indirect := lev.indirect
+					if et.Kind() == reflect.Ptr {
+						et = et.Elem()
+						indirect = true
+					}
+					if et.Kind() == reflect.Struct {
+						next = append(next, embedLevel{
+							rt:       et,
+							index:    index,
+							offset:   lev.offset + f.Offset,
+							indirect: indirect,
+						})
+						continue
+					}
+				}
+				if !f.IsExported() {
+					continue
+				}
+				sf := StructField{
+					StructField: f,
+					Key:         f.Name,
+					Tagged:      0 < len(name),
+					Offset:      lev.offset + f.Offset,
+					Indirect:    lev.indirect,
+				}
+				if sf.Tagged {
+					sf.Key = name
+				}
+				sf.Index = index
+				fields = append(fields, sf)
+			}
+		}
+		for _, lev := range current {
+			visited[lev.rt] = true
+		}
+	}
+	return dominantFields(fields)
+}
+
+// inlineTag returns true if a json tag has the inline or squash option.
+func inlineTag(tag string) bool {
+	_, opts, _ := strings.Cut(tag, ",")
+	for 0 < len(opts) {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		if opt == "inline" || opt == "squash" {
+			return true
+		}
+	}
+	return false
+}
+
+// dominantFields removes the fields hidden by other fields with the same
+// key. The fields are expected to be in order of depth.
+func dominantFields(fields []StructField) []StructField {
+	byKey := map[string][]int{}
+	for i, f := range fields {
+		byKey[f.Key] = append(byKey[f.Key], i)
+	}
+	keep := make([]bool, len(fields))
+	for _, ia := range byKey {
+		depth := len(fields[ia[0]].Index)
+		var top []int
+		var tagged []int
+		for _, i := range ia {
+			if len(fields[i].Index) != depth {
+				break
+			}
+			top = append(top, i)
+			if fields[i].Tagged {
+				tagged = append(tagged, i)
+			}
+		}
+		switch {
+		case len(top) == 1:
+			keep[top[0]] = true
+		case len(tagged) == 1:
+			keep[tagged[0]] = true
+		}
+	}
+	var out []StructField
+	for i, f := range fields {
+		if keep[i] {
+			out = append(out, f)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a := out[i].Index
+		b := out[j].Index
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+	return out
+}
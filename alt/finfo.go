@@ -4,6 +4,7 @@ package alt
 
 import (
 	"reflect"
+	"strings"
 	"unsafe"
 )
 
@@ -20,10 +21,82 @@ type valFunc func(fi *finfo, rv reflect.Value, addr uintptr) (v any, fv reflect.
 type finfo struct {
 	rt     reflect.Type
 	key    string
+	name   string // struct field name
 	value  valFunc
 	ivalue valFunc
 	index  []int
 	offset uintptr
+	zeroer byte // isZeroer implemented by the value (v) or pointer (p)
+
+	omitZero bool
+	tagged   bool // key set by a json tag
+	indirect bool // reached through an embedded pointer
+	groups   []string
+}
+
+type isZeroer interface {
+	IsZero() bool
+}
+
+var isZeroerType = reflect.TypeOf((*isZeroer)(nil)).Elem()
+
+// isZero returns true if the field is the zero value. An IsZero() method is
+// used if the field type has one, otherwise reflect.Value.IsZero() is used.
+// Fields of a nil embedded pointer are zero.
+func (f *finfo) isZero(rv reflect.Value) bool {
+	fv, err := rv.FieldByIndexErr(f.index)
+	if err != nil {
+		return true
+	}
+	switch f.zeroer {
+	case 'v':
+		if fv.Kind() == reflect.Ptr && fv.IsNil() {
+			return true
+		}
+		return fv.Interface().(isZeroer).IsZero()
+	case 'p':
+		if fv.CanAddr() {
+			return fv.Addr().Interface().(isZeroer).IsZero()
+		}
+	}
+	return fv.IsZero()
+}
+
+// ojgTagGroups returns the groups listed by the groups= option of the ojg
+// tag of the field. The rest of the tag after groups= is the comma
+// separated list of groups so the option must be the last one in the tag.
+func ojgTagGroups(f *reflect.StructField) []string {
+	tag := f.Tag.Get("ojg")
+	if i := strings.Index(tag, "groups="); i == 0 || (0 < i && tag[i-1] == ',') {
+		return strings.Split(tag[i+len("groups="):], ",")
+	}
+	return nil
+}
+
+// inGroups returns true if the field should be written when the active
+// groups are selected. Fields without groups are always written as are all
+// fields when there are no active groups.
+func (f *finfo) inGroups(active []string) bool {
+	if len(active) == 0 || len(f.groups) == 0 {
+		return true
+	}
+	for _, g := range f.groups {
+		for _, a := range active {
+			if g == a {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// outKey returns the key for the field which is converted by the KeyFunc
+// option unless the key was set with a json tag.
+func (f *finfo) outKey(opt *Options) string {
+	if opt.KeyFunc != nil && !f.tagged {
+		return opt.KeyFunc(f.name)
+	}
+	return f.key
 }
 
 func valString(fi *finfo, rv reflect.Value, addr uintptr) (any, reflect.Value, bool) {
@@ -97,11 +170,19 @@ func newFinfo(f *reflect.StructField, key string, fx byte) *finfo {
 	fi := finfo{
 		rt:     f.Type,
 		key:    key,
+		name:   f.Name,
 		index:  f.Index,
 		value:  valJustVal, // replace as necessary later
 		ivalue: valJustVal, // replace as necessary later
 		offset: f.Offset,
 	}
+	switch {
+	case fi.rt.Implements(isZeroerType):
+		fi.zeroer = 'v'
+	case reflect.PointerTo(fi.rt).Implements(isZeroerType):
+		fi.zeroer = 'p'
+	}
+	fi.groups = ojgTagGroups(f)
 	// Check for interfaces first since almost any type can implement one of
 	// the supported interfaces.
 	vp := reflect.New(fi.rt).Interface()
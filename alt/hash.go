@@ -0,0 +1,221 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package alt
+
+import (
+	"encoding/binary"
+	"hash"
+	"hash/fnv"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/ohler55/ojg/gen"
+)
+
+// HashOptions are the numeric normalization rules used when calculating a
+// hash with Hash() or Hash128(). All integer types are always hashed as the
+// same value so int8(3) and uint64(3) have the same hash. Float32 values are
+// hashed as the shortest decimal representation so float32(1.1) and 1.1 have
+// the same hash. Negative zero is hashed as zero.
+type HashOptions struct {
+	// IntegralFloats if true hashes floats with integer values, such as 3.0,
+	// the same as the matching integer.
+	IntegralFloats bool
+
+	// FloatPrecision if greater than zero rounds floats to that many
+	// significant digits before hashing.
+	FloatPrecision int
+}
+
+// DefaultHashOptions are the options used by Hash() and Hash128() when no
+// options are provided.
+var DefaultHashOptions = HashOptions{}
+
+const (
+	hashNil    = 'z'
+	hashTrue   = 't'
+	hashFalse  = 'f'
+	hashInt    = 'i'
+	hashUint   = 'u'
+	hashFloat  = 'd'
+	hashString = 's'
+	hashTime   = 'T'
+	hashArray  = 'a'
+	hashObject = 'o'
+)
+
+type hasher struct {
+	opt     *HashOptions
+	newHash func() hash.Hash
+	buf     []byte
+}
+
+// Hash returns a 64 bit hash of the value. Equivalent documents have the same
+// hash regardless of the order of the keys in maps. The hash is calculated
+// without building a canonical representation of the document so it is
+// suitable for deduplication, caching, and change detection of large
+// documents. The hash is based on FNV-1a and is not cryptographically secure.
+func Hash(v any, opts ...*HashOptions) uint64 {
+	h := newHasher(func() hash.Hash { return fnv.New64a() }, opts)
+	w := h.newHash()
+	h.write(w, v)
+
+	return binary.BigEndian.Uint64(w.Sum(nil))
+}
+
+// Hash128 returns a 128 bit hash of the value. The rules are the same as for
+// Hash().
+func Hash128(v any, opts ...*HashOptions) (sum [16]byte) {
+	h := newHasher(fnv.New128a, opts)
+	w := h.newHash()
+	h.write(w, v)
+	copy(sum[:], w.Sum(nil))
+
+	return
+}
+
+func newHasher(newHash func() hash.Hash, opts []*HashOptions) *hasher {
+	h := hasher{opt: &DefaultHashOptions, newHash: newHash, buf: make([]byte, 0, 64)}
+	if 0 < len(opts) && opts[0] != nil {
+		h.opt = opts[0]
+	}
+	return &h
+}
+
+func (h *hasher) write(w hash.Hash, v any) {
+	switch tv := v.(type) {
+	case nil:
+		h.writeTag(w, hashNil)
+	case bool:
+		if tv {
+			h.writeTag(w, hashTrue)
+		} else {
+			h.writeTag(w, hashFalse)
+		}
+	case int:
+		h.writeUint64(w, hashInt, uint64(tv))
+	case int8:
+		h.writeUint64(w, hashInt, uint64(tv))
+	case int16:
+		h.writeUint64(w, hashInt, uint64(tv))
+	case int32:
+		h.writeUint64(w, hashInt, uint64(tv))
+	case int64:
+		h.writeUint64(w, hashInt, uint64(tv))
+	case uint:
+		h.writeUint(w, uint64(tv))
+	case uint8:
+		h.writeUint64(w, hashInt, uint64(tv))
+	case uint16:
+		h.writeUint64(w, hashInt, uint64(tv))
+	case uint32:
+		h.writeUint64(w, hashInt, uint64(tv))
+	case uint64:
+		h.writeUint(w, tv)
+	case float32:
+		f, _ := strconv.ParseFloat(strconv.FormatFloat(float64(tv), 'g', -1, 32), 64)
+		h.writeFloat(w, f)
+	case float64:
+		h.writeFloat(w, tv)
+	case string:
+		h.writeString(w, hashString, tv)
+	case time.Time:
+		h.writeUint64(w, hashTime, uint64(tv.UnixNano()))
+	case []any:
+		h.writeUint64(w, hashArray, uint64(len(tv)))
+		for _, m := range tv {
+			h.write(w, m)
+		}
+	case map[string]any:
+		var sum [16]byte
+		for k, m := range tv {
+			h.addMember(sum[:], k, m)
+		}
+		h.writeObject(w, len(tv), sum[:])
+	case gen.Int:
+		h.writeUint64(w, hashInt, uint64(tv))
+	case gen.Float:
+		h.writeFloat(w, float64(tv))
+	case gen.Array:
+		h.writeUint64(w, hashArray, uint64(len(tv)))
+		for _, m := range tv {
+			h.write(w, m)
+		}
+	case gen.Object:
+		var sum [16]byte
+		for k, m := range tv {
+			h.addMember(sum[:], k, m)
+		}
+		h.writeObject(w, len(tv), sum[:])
+	case Simplifier:
+		h.write(w, tv.Simplify())
+	default:
+		h.write(w, reflectValue(reflect.ValueOf(v), v, &Options{}))
+	}
+}
+
+// addMember hashes a key and value pair and adds the result to sum. Since
+// addition is commutative the order of the members does not change the
+// final sum.
+func (h *hasher) addMember(sum []byte, k string, v any) {
+	w := h.newHash()
+	h.writeString(w, hashString, k)
+	h.write(w, v)
+	var scratch [16]byte
+	ms := w.Sum(scratch[:0])
+	var carry uint16
+	for i := len(ms) - 1; 0 <= i; i-- {
+		carry += uint16(sum[i]) + uint16(ms[i])
+		sum[i] = byte(carry)
+		carry >>= 8
+	}
+}
+
+func (h *hasher) writeObject(w hash.Hash, size int, sum []byte) {
+	h.buf = append(h.buf[:0], hashObject)
+	h.buf = binary.BigEndian.AppendUint64(h.buf, uint64(size))
+	h.buf = append(h.buf, sum[:w.Size()]...)
+	_, _ = w.Write(h.buf)
+}
+
+func (h *hasher) writeTag(w hash.Hash, tag byte) {
+	h.buf = append(h.buf[:0], tag)
+	_, _ = w.Write(h.buf)
+}
+
+func (h *hasher) writeUint64(w hash.Hash, tag byte, u uint64) {
+	h.buf = append(h.buf[:0], tag)
+	h.buf = binary.BigEndian.AppendUint64(h.buf, u)
+	_, _ = w.Write(h.buf)
+}
+
+func (h *hasher) writeUint(w hash.Hash, u uint64) {
+	if u <= math.MaxInt64 {
+		h.writeUint64(w, hashInt, u)
+	} else {
+		h.writeUint64(w, hashUint, u)
+	}
+}
+
+func (h *hasher) writeFloat(w hash.Hash, f float64) {
+	if f == 0 { // includes negative zero
+		f = 0
+	}
+	if 0 < h.opt.FloatPrecision {
+		f, _ = strconv.ParseFloat(strconv.FormatFloat(f, 'g', h.opt.FloatPrecision, 64), 64)
+	}
+	if h.opt.IntegralFloats && f == math.Trunc(f) && math.MinInt64 <= f && f < math.MaxInt64 {
+		h.writeUint64(w, hashInt, uint64(int64(f)))
+		return
+	}
+	h.writeUint64(w, hashFloat, math.Float64bits(f))
+}
+
+func (h *hasher) writeString(w hash.Hash, tag byte, s string) {
+	h.buf = append(h.buf[:0], tag)
+	h.buf = binary.BigEndian.AppendUint64(h.buf, uint64(len(s)))
+	h.buf = append(h.buf, s...)
+	_, _ = w.Write(h.buf)
+}
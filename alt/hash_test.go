@@ -0,0 +1,89 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package alt_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ohler55/ojg/alt"
+	"github.com/ohler55/ojg/gen"
+	"github.com/ohler55/ojg/tt"
+)
+
+func TestHashSame(t *testing.T) {
+	tm := time.Date(2025, time.March, 4, 5, 6, 7, 8, time.UTC)
+	for i, pair := range [][2]any{
+		{nil, nil},
+		{3, int8(3)},
+		{3, uint64(3)},
+		{int64(-3), int16(-3)},
+		{gen.Int(3), uint8(3)},
+		{1.1, float32(1.1)},
+		{1.5, gen.Float(1.5)},
+		{0.0, -1.0 * 0.0},
+		{"abc", gen.String("abc")},
+		{true, gen.Bool(true)},
+		{tm, gen.Time(tm)},
+		{[]any{1, "x", nil}, gen.Array{gen.Int(1), gen.String("x"), nil}},
+		{
+			map[string]any{"a": 1, "b": []any{true}, "c": map[string]any{"d": 2.5}},
+			gen.Object{"c": gen.Object{"d": gen.Float(2.5)}, "b": gen.Array{gen.True}, "a": gen.Int(1)},
+		},
+	} {
+		tt.Equal(t, alt.Hash(pair[0]), alt.Hash(pair[1]), i)
+		tt.Equal(t, alt.Hash128(pair[0]), alt.Hash128(pair[1]), i)
+	}
+}
+
+func TestHashDifferent(t *testing.T) {
+	for i, pair := range [][2]any{
+		{nil, false},
+		{true, false},
+		{1, 1.0},
+		{1, "1"},
+		{uint64(1 << 63), int64(-1 << 63)},
+		{[]any{1, 2}, []any{2, 1}},
+		{[]any{"ab"}, []any{"a", "b"}},
+		{[]any{}, map[string]any{}},
+		{map[string]any{"a": 1}, map[string]any{"a": 2}},
+		{map[string]any{"a": 1}, map[string]any{"b": 1}},
+		{map[string]any{"a": map[string]any{"b": 1}}, map[string]any{"a": map[string]any{}, "b": 1}},
+	} {
+		tt.Equal(t, false, alt.Hash(pair[0]) == alt.Hash(pair[1]), i)
+		tt.Equal(t, false, alt.Hash128(pair[0]) == alt.Hash128(pair[1]), i)
+	}
+}
+
+func TestHashKeyOrder(t *testing.T) {
+	m0 := map[string]any{}
+	m1 := map[string]any{}
+	for i := 0; i < 100; i++ {
+		m0[string(rune('a'+i%26))+string(rune('A'+i/26))] = i
+	}
+	for i := 99; 0 <= i; i-- {
+		m1[string(rune('a'+i%26))+string(rune('A'+i/26))] = i
+	}
+	tt.Equal(t, alt.Hash(m0), alt.Hash(m1))
+	tt.Equal(t, alt.Hash128(m0), alt.Hash128(m1))
+}
+
+func TestHashOptions(t *testing.T) {
+	opt := alt.HashOptions{IntegralFloats: true}
+	tt.Equal(t, alt.Hash(3, &opt), alt.Hash(3.0, &opt))
+	tt.Equal(t, alt.Hash(-2, &opt), alt.Hash(gen.Float(-2.0), &opt))
+	tt.Equal(t, false, alt.Hash(3, &opt) == alt.Hash(3.5, &opt))
+
+	opt = alt.HashOptions{FloatPrecision: 3}
+	tt.Equal(t, alt.Hash(1.2345, &opt), alt.Hash(1.23, &opt))
+	tt.Equal(t, false, alt.Hash(1.2345) == alt.Hash(1.23))
+}
+
+func TestHashReflect(t *testing.T) {
+	type Sample struct {
+		A int
+		B string
+	}
+	tt.Equal(t, alt.Hash(&Sample{A: 1, B: "x"}), alt.Hash(Sample{A: 1, B: "x"}))
+	tt.Equal(t, false, alt.Hash(&Sample{A: 1, B: "x"}) == alt.Hash(&Sample{A: 2, B: "x"}))
+}
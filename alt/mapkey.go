@@ -0,0 +1,107 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package alt
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/ohler55/ojg"
+)
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// MapKeys returns the keys of a map along with the strings used for those
+// keys in JSON, optionally sorted by the strings. It is used by the writers
+// so that maps with integer and encoding.TextMarshaler keys are written as
+// encoding/json writes them. When the key kind is string the names are not
+// built and nil is returned for names since kv.String() is the name.
+func MapKeys(rv reflect.Value, sorted bool) (keys []reflect.Value, names []string) {
+	keys = rv.MapKeys()
+	if rv.Type().Key().Kind() == reflect.String {
+		if sorted {
+			sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		}
+		return
+	}
+	names = make([]string, len(keys))
+	for i, kv := range keys {
+		names[i] = mapKeyString(kv)
+	}
+	if sorted {
+		sort.Sort(&keyNames{keys: keys, names: names})
+	}
+	return
+}
+
+type keyNames struct {
+	keys  []reflect.Value
+	names []string
+}
+
+func (kn *keyNames) Len() int           { return len(kn.keys) }
+func (kn *keyNames) Less(i, j int) bool { return kn.names[i] < kn.names[j] }
+func (kn *keyNames) Swap(i, j int) {
+	kn.keys[i], kn.keys[j] = kn.keys[j], kn.keys[i]
+	kn.names[i], kn.names[j] = kn.names[j], kn.names[i]
+}
+
+// mapKeyString returns the string for a map key. As with encoding/json,
+// keys that are strings are used as is, keys that implement
+// encoding.TextMarshaler are marshaled, and integer keys are formatted in
+// base 10.
+func mapKeyString(kv reflect.Value) string {
+	if kv.Kind() == reflect.String {
+		return kv.String()
+	}
+	if tm, ok := kv.Interface().(encoding.TextMarshaler); ok {
+		b, err := tm.MarshalText()
+		if err != nil {
+			panic(err)
+		}
+		return string(b)
+	}
+	switch kv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(kv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(kv.Uint(), 10)
+	}
+	return fmt.Sprint(kv.Interface())
+}
+
+// mapKeyValue is the reverse of mapKeyString and returns the map key of
+// the key type for a string.
+func mapKeyValue(key string, kt reflect.Type) reflect.Value {
+	if kt.Kind() == reflect.String {
+		return reflect.ValueOf(key).Convert(kt)
+	}
+	if reflect.PointerTo(kt).Implements(textUnmarshalerType) {
+		kv := reflect.New(kt)
+		if err := kv.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(key)); err != nil {
+			panic(err)
+		}
+		return kv.Elem()
+	}
+	kv := reflect.New(kt).Elem()
+	switch kt.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(key, 10, kt.Bits())
+		if err != nil {
+			panic(&ojg.ErrTypeMismatch{Message: fmt.Sprintf("can not convert map key %q to a %s", key, kt)})
+		}
+		kv.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(key, 10, kt.Bits())
+		if err != nil {
+			panic(&ojg.ErrTypeMismatch{Message: fmt.Sprintf("can not convert map key %q to a %s", key, kt)})
+		}
+		kv.SetUint(u)
+	default:
+		panic(&ojg.ErrTypeMismatch{Message: fmt.Sprintf("can not recompose a map with %s keys", kt)})
+	}
+	return kv
+}
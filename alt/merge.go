@@ -0,0 +1,115 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package alt
+
+import (
+	"fmt"
+)
+
+// MergeStrategy identifies how Merge() combines arrays. Objects are always
+// merged recursively.
+type MergeStrategy byte
+
+const (
+	// MergeDeep merges arrays element by element so the elements at the
+	// same index are merged and extra elements of the longer array are
+	// kept.
+	MergeDeep = MergeStrategy('d')
+
+	// MergeReplaceArrays replaces an array with the array of the later
+	// layer.
+	MergeReplaceArrays = MergeStrategy('r')
+
+	// MergeAppendArrays appends the elements of the array of the later
+	// layer to the earlier array.
+	MergeAppendArrays = MergeStrategy('a')
+)
+
+// String returns the name of the strategy as used by ParseMergeStrategy.
+func (ms MergeStrategy) String() string {
+	switch ms {
+	case MergeDeep:
+		return "deep"
+	case MergeReplaceArrays:
+		return "replace-arrays"
+	case MergeAppendArrays:
+		return "append-arrays"
+	}
+	return fmt.Sprintf("MergeStrategy(%d)", byte(ms))
+}
+
+// ParseMergeStrategy returns the strategy for one of the names deep,
+// replace-arrays, or append-arrays.
+func ParseMergeStrategy(name string) (MergeStrategy, error) {
+	for _, ms := range []MergeStrategy{MergeDeep, MergeReplaceArrays, MergeAppendArrays} {
+		if ms.String() == name {
+			return ms, nil
+		}
+	}
+	return 0, fmt.Errorf("%q is not a valid merge strategy", name)
+}
+
+// Merge layers simple data values in order with each layer overriding the
+// layers before it. Objects (map[string]any) are merged recursively and
+// arrays ([]any) are combined according to the strategy. Any other value,
+// including nil, replaces the earlier value. The layers are not modified;
+// objects and arrays that are combined are copies.
+func Merge(strategy MergeStrategy, layers ...any) (result any) {
+	for i, layer := range layers {
+		if i == 0 {
+			result = layer
+			continue
+		}
+		result = merge(result, layer, strategy)
+	}
+	return
+}
+
+func merge(base, over any, strategy MergeStrategy) any {
+	switch to := over.(type) {
+	case map[string]any:
+		tb, ok := base.(map[string]any)
+		if !ok {
+			return over
+		}
+		obj := make(map[string]any, len(tb)+len(to))
+		for k, v := range tb {
+			obj[k] = v
+		}
+		for k, v := range to {
+			if bv, has := obj[k]; has {
+				obj[k] = merge(bv, v, strategy)
+			} else {
+				obj[k] = v
+			}
+		}
+		return obj
+	case []any:
+		tb, ok := base.([]any)
+		if !ok {
+			return over
+		}
+		switch strategy {
+		case MergeAppendArrays:
+			a := make([]any, 0, len(tb)+len(to))
+			a = append(a, tb...)
+			return append(a, to...)
+		case MergeDeep:
+			size := len(tb)
+			if size < len(to) {
+				size = len(to)
+			}
+			a := make([]any, size)
+			copy(a, tb)
+			for i, v := range to {
+				if i < len(tb) {
+					a[i] = merge(tb[i], v, strategy)
+				} else {
+					a[i] = v
+				}
+			}
+			return a
+		}
+	}
+	return over
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package alt_test
+
+import (
+	"testing"
+
+	"github.com/ohler55/ojg/alt"
+	"github.com/ohler55/ojg/sen"
+	"github.com/ohler55/ojg/tt"
+)
+
+func TestMerge(t *testing.T) {
+	base := sen.MustParse([]byte(`{a: {x: 1 list: [1 {b: 2}]} keep: true}`))
+	over := sen.MustParse([]byte(`{a: {y: 2 list: [3 {c: 4} 5]} keep: null}`))
+	for _, d := range []struct {
+		strategy alt.MergeStrategy
+		expect   string
+	}{
+		{strategy: alt.MergeDeep, expect: `{a:{list:[3 {b:2 c:4}5] x:1 y:2} keep:null}`},
+		{strategy: alt.MergeReplaceArrays, expect: `{a:{list:[3 {c:4}5] x:1 y:2} keep:null}`},
+		{strategy: alt.MergeAppendArrays, expect: `{a:{list:[1 {b:2}3 {c:4}5] x:1 y:2} keep:null}`},
+	} {
+		result := alt.Merge(d.strategy, base, over)
+		tt.Equal(t, d.expect, sen.String(result, &sen.Options{Sort: true}), d.strategy)
+	}
+	// The layers are not modified.
+	tt.Equal(t, `{a:{list:[1 {b:2}] x:1} keep:true}`, sen.String(base, &sen.Options{Sort: true}))
+
+	tt.Equal(t, 3, alt.Merge(alt.MergeDeep, map[string]any{"a": 1}, []any{2}, 3))
+	tt.Nil(t, alt.Merge(alt.MergeDeep))
+}
+
+func TestParseMergeStrategy(t *testing.T) {
+	for _, name := range []string{"deep", "replace-arrays", "append-arrays"} {
+		ms, err := alt.ParseMergeStrategy(name)
+		tt.Nil(t, err)
+		tt.Equal(t, name, ms.String())
+	}
+	_, err := alt.ParseMergeStrategy("other")
+	tt.NotNil(t, err)
+	tt.Equal(t, "MergeStrategy(0)", alt.MergeStrategy(0).String())
+}
@@ -3,8 +3,10 @@
 package alt
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"math"
 	"reflect"
 	"strconv"
@@ -30,6 +32,18 @@ type RecomposeFunc func(map[string]any) (any, error)
 // returning the recomposed object or an error.
 type RecomposeAnyFunc func(any) (any, error)
 
+// DecodeHookFunc is called with the JSONPath of a value such as
+// "$.labels" or "$.items[2].price", the value, and the type it is about to
+// be recomposed into. The value returned replaces the value. If the
+// returned value can be assigned to the target type it is assigned as is,
+// otherwise it is recomposed as usual. Returning an error stops the
+// recompose with that error.
+type DecodeHookFunc func(path string, value any, target reflect.Type) (any, error)
+
+// MigrateFunc upgrades the decomposed data for a struct type from one
+// version to the next, returning the upgraded data or an error.
+type MigrateFunc func(map[string]any) (map[string]any, error)
+
 // Recomposer is used to recompose simple data into structs.
 type Recomposer struct {
 
@@ -38,11 +52,43 @@ type Recomposer struct {
 
 	composers map[string]*composer
 
+	// types holds the same composers keyed by type so each instantiation
+	// of a generic type has its own entry even if the names collide.
+	types map[reflect.Type]*composer
+
 	// NumConvMethod specifies the json.Number conversion method.
 	NumConvMethod ojg.NumConvMethod
+
+	// DisallowUnknownFields if true causes an error to be returned when an
+	// object being recomposed into a struct has a member that does not match
+	// any of the struct fields.
+	DisallowUnknownFields bool
+
+	// DecodeHook if not nil is called before each object member value or
+	// array element is recomposed into a typed location such as a struct
+	// field, slice element, or map value. Values recomposed into an any
+	// are not passed to the hook. See DecodeHookFunc for details.
+	DecodeHook DecodeHookFunc
+
+	// steps are the path steps to the current value when a DecodeHook is
+	// set.
+	steps []string
+
+	// BigIntAsString if true allows a string value holding an integer to
+	// be recomposed into an integer field. That is the form written by the
+	// oj writer with the BigIntAsString option.
+	BigIntAsString bool
+
+	// VersionKey identifies the member of a decomposed object that holds the
+	// version used to select migrations registered with RegisterMigration.
+	// If empty "v" is used.
+	VersionKey string
 }
 
-var jsonUnmarshalerType reflect.Type
+var (
+	jsonUnmarshalerType reflect.Type
+	timeType            = reflect.TypeOf(time.Time{})
+)
 
 func init() {
 	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
@@ -77,6 +123,30 @@ func (r *Recomposer) RegisterUnmarshalerComposer(fun RecomposeAnyFunc) {
 	}
 }
 
+// RegisterMigration registers a function that upgrades the decomposed
+// data for a struct type from the version provided to the next version. The
+// version is read from the VersionKey member of the data and a missing
+// version is treated as version 0. Before data is recomposed into the type
+// the migrations are applied in order starting with the version of the data
+// until there is no migration registered for the next version. The function
+// may modify and return the map provided or return a new map. Registering
+// a migration also registers the type as with RegisterComposer.
+func (r *Recomposer) RegisterMigration(val any, version int, fun MigrateFunc) error {
+	if fun == nil {
+		return fmt.Errorf("a migration function is required")
+	}
+	c, err := r.registerComposer(reflect.TypeOf(val), nil)
+	if err != nil {
+		return err
+	}
+	if c.migrations == nil {
+		c.migrations = map[int64]MigrateFunc{}
+	}
+	c.migrations[int64(version)] = fun
+
+	return nil
+}
+
 func (r *Recomposer) registerComposer(rt reflect.Type, fun RecomposeFunc) (*composer, error) {
 	if rt.Kind() == reflect.Ptr {
 		rt = rt.Elem()
@@ -86,7 +156,8 @@ func (r *Recomposer) registerComposer(rt reflect.Type, fun RecomposeFunc) (*comp
 	if rt.Kind() != reflect.Struct {
 		return nil, fmt.Errorf("only structs can be recomposed. %s is not a struct type", rt)
 	}
-	c := r.composers[full]
+	// Function local types can share a full name so look up by type.
+	c := r.types[rt]
 	if c == nil {
 		c = &composer{
 			fun:   fun,
@@ -95,8 +166,8 @@ func (r *Recomposer) registerComposer(rt reflect.Type, fun RecomposeFunc) (*comp
 			rtype: rt,
 		}
 		c.indexes = indexType(c.rtype)
-		r.composers[c.short] = c
-		r.composers[c.full] = c
+		c.defaults = indexDefaults(c.indexes)
+		r.addComposer(c)
 	} else {
 		if fun != nil {
 			c.fun = fun
@@ -115,7 +186,7 @@ func (r *Recomposer) registerComposer(rt reflect.Type, fun RecomposeFunc) (*comp
 		case reflect.Array, reflect.Slice, reflect.Map, reflect.Ptr:
 			ft = ft.Elem()
 		}
-		if _, has := r.composers[ft.Name()]; has {
+		if _, has := r.types[ft]; has {
 			continue
 		}
 		_, _ = r.registerComposer(ft, nil)
@@ -123,6 +194,44 @@ func (r *Recomposer) registerComposer(rt reflect.Type, fun RecomposeFunc) (*comp
 	return c, nil
 }
 
+func (r *Recomposer) addComposer(c *composer) {
+	r.composers[c.short] = c
+	r.composers[c.full] = c
+	// Generic type names include the package path of each type
+	// argument. Also register the name without those paths if it is not
+	// already taken so Page[Item] can be used in place of
+	// Page[github.com/example/app.Item].
+	if bare := bareTypeName(c.short); bare != c.short {
+		if _, has := r.composers[bare]; !has {
+			r.composers[bare] = c
+		}
+	}
+	if r.types == nil {
+		r.types = map[reflect.Type]*composer{}
+	}
+	r.types[c.rtype] = c
+}
+
+// bareTypeName removes the package paths from the type arguments of a
+// generic type name.
+func bareTypeName(name string) string {
+	if strings.IndexByte(name, '[') < 0 {
+		return name
+	}
+	var b []byte
+	start := 0 // start of the current identifier
+	for i := 0; i < len(name); i++ {
+		switch name[i] {
+		case '[', ']', ',', '*', ' ':
+			b = append(b, name[start:i+1]...)
+			start = i + 1
+		case '.', '/':
+			start = i + 1
+		}
+	}
+	return string(append(b, name[start:]...))
+}
+
 func (r *Recomposer) registerAnyComposer(rt reflect.Type, fun RecomposeAnyFunc) (*composer, error) {
 	if rt.Kind() == reflect.Ptr {
 		rt = rt.Elem()
@@ -131,7 +240,7 @@ func (r *Recomposer) registerAnyComposer(rt reflect.Type, fun RecomposeAnyFunc)
 	if rt.Kind() != reflect.Struct {
 		return nil, fmt.Errorf("only structs can be recomposed. %s is not a struct type", rt)
 	}
-	c := r.composers[full]
+	c := r.types[rt]
 	if c == nil {
 		c = &composer{
 			any:   fun,
@@ -140,8 +249,8 @@ func (r *Recomposer) registerAnyComposer(rt reflect.Type, fun RecomposeAnyFunc)
 			rtype: rt,
 		}
 		c.indexes = indexType(c.rtype)
-		r.composers[c.short] = c
-		r.composers[c.full] = c
+		c.defaults = indexDefaults(c.indexes)
+		r.addComposer(c)
 	} else {
 		c.any = fun
 	}
@@ -162,6 +271,24 @@ func (r *Recomposer) Recompose(v any, tv ...any) (out any, err error) {
 
 // MustRecompose simple data into more complex go types.
 func (r *Recomposer) MustRecompose(v any, tv ...any) (out any) {
+	if r.DecodeHook != nil && r.steps == nil {
+		// The path is tracked in a copy so the Recomposer can still be
+		// shared.
+		rc := *r
+		rc.steps = make([]string, 0, 16)
+		return rc.MustRecompose(v, tv...)
+	}
+	defer func() {
+		if rec := recover(); rec != nil {
+			switch tr := rec.(type) {
+			case *ojg.ErrTypeMismatch:
+				tr.Path = "$" + tr.Path
+			case *ojg.ErrUnknownFieldAt:
+				tr.Path = "$" + tr.Path
+			}
+			panic(rec)
+		}
+	}()
 	if 0 < len(tv) {
 		if um, ok := tv[0].(json.Unmarshaler); ok {
 			if comp := r.composers["json.Unmarshaler"]; comp != nil {
@@ -234,7 +361,7 @@ func (r *Recomposer) recompAny(v any) any {
 			tn, _ := cv.(string)
 			if c := r.composers[tn]; c != nil {
 				if c.fun != nil {
-					val, err := c.fun(tv)
+					val, err := c.fun(r.migrate(c, tv))
 					if err != nil {
 						panic(err)
 					}
@@ -276,7 +403,7 @@ func (r *Recomposer) recompAny(v any) any {
 			if c := r.composers[tn]; c != nil {
 				simple, _ := tv.Simplify().(map[string]any)
 				if c.fun != nil {
-					val, err := c.fun(simple)
+					val, err := c.fun(r.migrate(c, simple))
 					if err != nil {
 						panic(err)
 					}
@@ -304,7 +431,7 @@ func (r *Recomposer) recompAny(v any) any {
 			v = tv.String()
 		}
 	default:
-		panic(fmt.Errorf("can not recompose a %T", v))
+		panic(ojg.Errorf(ojg.ErrUnsupportedType, "can not recompose a %T", v))
 	}
 	return v
 }
@@ -317,13 +444,25 @@ func (r *Recomposer) recomp(v any, rv reflect.Value) {
 		}
 		rv = rv.Elem()
 	}
+	if rv.Kind() != reflect.Interface && r.unmarshalJSON(v, rv) {
+		return
+	}
 	switch rv.Kind() {
 	case reflect.Slice:
 		va, ok := (v).([]any)
 		if !ok {
+			if s, ok := v.(string); ok && rv.Type().Elem().Kind() == reflect.Uint8 {
+				// Byte slices are encoded as base64 by encoding/json.
+				b, err := base64.StdEncoding.DecodeString(s)
+				if err != nil {
+					panic(&ojg.ErrTypeMismatch{Message: fmt.Sprintf("can not decode base64 for a %s: %s", rv.Type(), err)})
+				}
+				rv.Set(reflect.ValueOf(b).Convert(rv.Type()))
+				break
+			}
 			vv := reflect.ValueOf(v)
 			if vv.Kind() != reflect.Slice {
-				panic(fmt.Errorf("can only recompose a %s from a []any, not a %T", rv.Type(), v))
+				panic(&ojg.ErrTypeMismatch{Message: fmt.Sprintf("can only recompose a %s from a []any, not a %T", rv.Type(), v)})
 			}
 			va = make([]any, vv.Len())
 			for i := len(va) - 1; 0 <= i; i-- {
@@ -337,19 +476,19 @@ func (r *Recomposer) recomp(v any, rv reflect.Value) {
 			et = et.Elem()
 			for i := 0; i < size; i++ {
 				ev := reflect.New(et)
-				r.recomp(va[i], ev)
+				r.recompAt(va[i], ev, "", i)
 				av.Index(i).Set(ev)
 			}
 		} else {
 			for i := 0; i < size; i++ {
-				r.setValue(va[i], av.Index(i), nil)
+				r.setValueAt(va[i], av.Index(i), nil, "", i)
 			}
 		}
 		rv.Set(av)
 	case reflect.Array:
 		vv := reflect.ValueOf(v)
 		if vv.Kind() != reflect.Slice {
-			panic(fmt.Errorf("can only recompose a %s from a []any, not a %T", rv.Type(), v))
+			panic(&ojg.ErrTypeMismatch{Message: fmt.Sprintf("can only recompose a %s from a []any, not a %T", rv.Type(), v)})
 		}
 		inSize := vv.Len()
 		size := rv.Len()
@@ -361,7 +500,7 @@ func (r *Recomposer) recomp(v any, rv reflect.Value) {
 			// actual type of the element value if the slice input is []any.
 			ev := vv.Index(i).Interface()
 			ri := rv.Index(i)
-			r.setValue(ev, ri, nil)
+			r.setValueAt(ev, ri, nil, "", i)
 		}
 	case reflect.Map:
 		if v == nil {
@@ -372,7 +511,7 @@ func (r *Recomposer) recomp(v any, rv reflect.Value) {
 		if !ok {
 			vv := reflect.ValueOf(v)
 			if vv.Kind() != reflect.Map {
-				panic(fmt.Errorf("can only recompose a map from a map[string]any, not a %T", v))
+				panic(&ojg.ErrTypeMismatch{Message: fmt.Sprintf("can only recompose a map from a map[string]any, not a %T", v)})
 			}
 			vm = map[string]any{}
 			iter := vv.MapRange()
@@ -384,29 +523,30 @@ func (r *Recomposer) recomp(v any, rv reflect.Value) {
 		if rv.IsNil() {
 			rv.Set(reflect.MakeMapWithSize(rv.Type(), len(vm)))
 		}
+		kt := rv.Type().Key()
 		switch {
 		case et.Kind() == reflect.Interface:
 			for k, m := range vm {
-				rv.SetMapIndex(reflect.ValueOf(k), reflect.ValueOf(r.recompAny(m)))
+				rv.SetMapIndex(mapKeyValue(k, kt), reflect.ValueOf(r.recompAny(m)))
 			}
 		case et.Kind() == reflect.Ptr:
 			et = et.Elem()
 			for k, m := range vm {
 				ev := reflect.New(et)
-				r.recomp(m, ev)
-				rv.SetMapIndex(reflect.ValueOf(k), ev)
+				r.recompAt(m, ev, k, -1)
+				rv.SetMapIndex(mapKeyValue(k, kt), ev)
 			}
 		default:
 			for k, m := range vm {
 				ev := reflect.New(et)
-				r.recomp(m, ev)
-				rv.SetMapIndex(reflect.ValueOf(k), ev.Elem())
+				r.recompAt(m, ev, k, -1)
+				rv.SetMapIndex(mapKeyValue(k, kt), ev.Elem())
 			}
 		}
 	case reflect.Struct:
 		vm, ok := (v).(map[string]any)
 		if !ok {
-			if c := r.composers[rv.Type().Name()]; c != nil && c.any != nil {
+			if c := r.types[rv.Type()]; c != nil && c.any != nil {
 				if val, err := c.any(v); err == nil {
 					if val == nil {
 						break
@@ -423,7 +563,7 @@ func (r *Recomposer) recomp(v any, rv reflect.Value) {
 			}
 			vv := reflect.ValueOf(v)
 			if vv.Kind() != reflect.Map {
-				panic(fmt.Errorf("can only recompose a %s from a map[string]any, not a %T", rv.Type(), v))
+				panic(&ojg.ErrTypeMismatch{Message: fmt.Sprintf("can only recompose a %s from a map[string]any, not a %T", rv.Type(), v)})
 			}
 			vm = map[string]any{}
 			iter := vv.MapRange()
@@ -432,6 +572,8 @@ func (r *Recomposer) recomp(v any, rv reflect.Value) {
 				vm[k] = iter.Value().Interface()
 			}
 		}
+		c := r.types[rv.Type()]
+		vm = r.migrate(c, vm)
 		if as != nil {
 			for k, m := range vm {
 				if r.CreateKey == k {
@@ -444,7 +586,7 @@ func (r *Recomposer) recomp(v any, rv reflect.Value) {
 			return
 		}
 		var im map[string]reflect.StructField
-		if c := r.composers[rv.Type().Name()]; c != nil {
+		if c != nil {
 			if c.fun != nil {
 				if val, err := c.fun(vm); err == nil {
 					vv := reflect.ValueOf(val)
@@ -462,22 +604,52 @@ func (r *Recomposer) recomp(v any, rv reflect.Value) {
 			c, _ = r.registerComposer(rv.Type(), nil)
 			im = c.indexes
 		}
+		var used map[string]bool
+		if r.DisallowUnknownFields {
+			used = map[string]bool{r.CreateKey: true}
+			if 0 < len(c.migrations) {
+				used[r.versionKey()] = true
+			}
+		}
 		for k := range im {
 			sf := im[k]
-			f := rv.FieldByIndex(sf.Index)
 			var m any
 			var has bool
-			if m, has = vm[k]; !has {
-				if m, has = vm[sf.Name]; !has {
+			key := k
+			if m, has = vm[key]; !has {
+				key = sf.Name
+				if m, has = vm[key]; !has {
 					name := []byte(sf.Name)
 					name[0] |= 0x20
-					if m, has = vm[string(name)]; !has {
-						m, has = vm[strings.ToLower(string(name))]
+					key = string(name)
+					if m, has = vm[key]; !has {
+						key = strings.ToLower(key)
+						m, has = vm[key]
 					}
 				}
 			}
+			if has && used != nil {
+				used[key] = true
+			}
 			if has && m != nil {
-				r.setValue(m, f, &sf)
+				if f := fieldByIndex(rv, sf.Index); f.IsValid() {
+					r.setValueAt(m, f, &sf, key, -1)
+				}
+			} else if fd := c.defaults[k]; fd != nil && !has {
+				if f := fieldByIndex(rv, sf.Index); f.IsValid() {
+					r.setDefault(fd, f, &sf, k)
+				}
+			}
+		}
+		if used != nil {
+			for k := range vm {
+				if !used[k] {
+					panic(&ojg.ErrUnknownFieldAt{
+						Path:    pathStep(k, -1),
+						Key:     k,
+						Message: fmt.Sprintf("%s %q for %s", ojg.ErrUnknownField, k, rv.Type()),
+					})
+				}
 			}
 		}
 	case reflect.Interface:
@@ -485,19 +657,26 @@ func (r *Recomposer) recomp(v any, rv reflect.Value) {
 		rv.Set(reflect.ValueOf(v))
 
 	case reflect.Bool:
-		rv.Set(reflect.ValueOf(v))
+		setConverted(v, rv)
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
 		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
 		reflect.Float32, reflect.Float64,
 		reflect.String:
-		rv.Set(reflect.ValueOf(v).Convert(rv.Type()))
+		setConverted(v, rv)
 
 	default:
-		panic(fmt.Errorf("can not convert (%T)%v to a %s", v, v, rv.Type()))
+		panic(typeMismatch(v, rv))
 	}
 }
 
 func (r *Recomposer) setValue(v any, rv reflect.Value, sf *reflect.StructField) {
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+	default:
+		if r.unmarshalJSON(v, rv) {
+			return
+		}
+	}
 	switch rv.Kind() {
 	case reflect.Bool:
 		if s, ok := v.(string); ok && sf != nil && strings.Contains(sf.Tag.Get("json"), ",string") {
@@ -507,7 +686,7 @@ func (r *Recomposer) setValue(v any, rv reflect.Value, sf *reflect.StructField)
 				panic(err)
 			}
 		} else {
-			rv.Set(reflect.ValueOf(v))
+			setConverted(v, rv)
 		}
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
 		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
@@ -523,8 +702,10 @@ func (r *Recomposer) setValue(v any, rv reflect.Value, sf *reflect.StructField)
 			} else {
 				panic(err)
 			}
+		} else if s, sok := v.(string); sok && r.BigIntAsString {
+			setIntString(s, rv)
 		} else {
-			rv.Set(reflect.ValueOf(v).Convert(rv.Type()))
+			setConverted(v, rv)
 		}
 	case reflect.Float32, reflect.Float64:
 		if s, ok := v.(string); ok && sf != nil && strings.Contains(sf.Tag.Get("json"), ",string") {
@@ -540,10 +721,10 @@ func (r *Recomposer) setValue(v any, rv reflect.Value, sf *reflect.StructField)
 				panic(err)
 			}
 		} else {
-			rv.Set(reflect.ValueOf(v).Convert(rv.Type()))
+			setConverted(v, rv)
 		}
 	case reflect.String:
-		rv.Set(reflect.ValueOf(v).Convert(rv.Type()))
+		setConverted(v, rv)
 	case reflect.Interface:
 		v = r.recompAny(v)
 		rv.Set(reflect.ValueOf(v))
@@ -552,16 +733,286 @@ func (r *Recomposer) setValue(v any, rv reflect.Value, sf *reflect.StructField)
 		r.recomp(v, ev)
 		rv.Set(ev)
 	default:
-		if reflect.PtrTo(rv.Type()).Implements(jsonUnmarshalerType) {
-			ev := rv.Addr().Interface().(json.Unmarshaler)
-			if comp := r.composers["json.Unmarshaler"]; comp != nil {
-				b, _ := comp.any(v) // Special case. Must return []byte.
-				if err := ev.UnmarshalJSON(b.([]byte)); err != nil {
-					panic(err)
-				}
-				return
+		r.recomp(v, rv)
+	}
+}
+
+// fieldDefault is the value of a default struct tag parsed for the type of
+// the field or the error encountered when parsing.
+type fieldDefault struct {
+	value any
+	err   error
+}
+
+// parseDefault parses the value of a default tag for a field type. The tag
+// value is used as is for string and time.Time fields, with times in RFC 3339
+// format. For other types the tag value is parsed as JSON, such as
+// `default:"[1,2]"` for a []int, and then recomposed into the field.
+func parseDefault(def string, rt reflect.Type) *fieldDefault {
+	for rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	var fd fieldDefault
+	switch {
+	case rt == timeType:
+		fd.value, fd.err = time.Parse(time.RFC3339Nano, def)
+	case rt.Kind() == reflect.String:
+		fd.value = def
+	default:
+		dec := json.NewDecoder(strings.NewReader(def))
+		dec.UseNumber()
+		var v any
+		if fd.err = dec.Decode(&v); fd.err != nil {
+			break
+		}
+		if _, err := dec.Token(); err != io.EOF {
+			fd.err = fmt.Errorf("unexpected data after the value in %q", def)
+			break
+		}
+		fd.value = defaultNumbers(v)
+	}
+	return &fd
+}
+
+// setDefault sets a field to the parsed value of its default tag.
+func (r *Recomposer) setDefault(fd *fieldDefault, rv reflect.Value, sf *reflect.StructField, key string) {
+	if 0 < len(key) {
+		defer addMismatchPath(key, -1)
+	}
+	if fd.err != nil {
+		panic(&ojg.ErrTypeMismatch{Message: fmt.Sprintf("invalid default for %s: %s", sf.Name, fd.err)})
+	}
+	if rv.Kind() == reflect.Ptr {
+		ev := reflect.New(rv.Type().Elem())
+		r.setDefault(fd, ev.Elem(), sf, "")
+		rv.Set(ev)
+		return
+	}
+	if t, ok := fd.value.(time.Time); ok {
+		rv.Set(reflect.ValueOf(t))
+		return
+	}
+	r.setValue(fd.value, rv, nil)
+}
+
+// defaultNumbers replaces the json.Number values in a parsed default with
+// an int64 or float64 as the oj parser would.
+func defaultNumbers(v any) any {
+	switch tv := v.(type) {
+	case json.Number:
+		if i, err := tv.Int64(); err == nil {
+			return i
+		}
+		f, _ := tv.Float64()
+		return f
+	case []any:
+		for i, m := range tv {
+			tv[i] = defaultNumbers(m)
+		}
+	case map[string]any:
+		for k, m := range tv {
+			tv[k] = defaultNumbers(m)
+		}
+	}
+	return v
+}
+
+// unmarshalJSON calls the UnmarshalJSON method of the value if the value
+// implements json.Unmarshaler and returns true if it was called. A
+// json.RawMessage, such as a value the oj parser kept as raw bytes, is
+// passed as is. Other values are converted to JSON by the composer
+// registered with RegisterUnmarshalerComposer and are left for reflection
+// if there is no such composer.
+func (r *Recomposer) unmarshalJSON(v any, rv reflect.Value) bool {
+	if !rv.CanAddr() || !reflect.PtrTo(rv.Type()).Implements(jsonUnmarshalerType) {
+		return false
+	}
+	b, ok := v.(json.RawMessage)
+	if !ok {
+		comp := r.composers["json.Unmarshaler"]
+		if comp == nil {
+			return false
+		}
+		bv, _ := comp.any(v) // Special case. Must return []byte.
+		b = bv.([]byte)
+	}
+	if err := rv.Addr().Interface().(json.Unmarshaler).UnmarshalJSON(b); err != nil {
+		panic(err)
+	}
+	return true
+}
+
+func (r *Recomposer) recompAt(v any, rv reflect.Value, key string, index int) {
+	defer addMismatchPath(key, index)
+	if r.steps != nil {
+		r.steps = append(r.steps, pathStep(key, index))
+		defer r.popStep()
+		var done bool
+		if v, done = r.decodeHook(v, rv.Elem()); done {
+			return
+		}
+	}
+	r.recomp(v, rv)
+}
+
+func (r *Recomposer) setValueAt(v any, rv reflect.Value, sf *reflect.StructField, key string, index int) {
+	defer addMismatchPath(key, index)
+	if r.steps != nil {
+		r.steps = append(r.steps, pathStep(key, index))
+		defer r.popStep()
+		var done bool
+		if v, done = r.decodeHook(v, rv); done {
+			return
+		}
+	}
+	r.setValue(v, rv, sf)
+}
+
+func (r *Recomposer) popStep() {
+	r.steps = r.steps[:len(r.steps)-1]
+}
+
+// decodeHook calls the DecodeHook and returns the value to recompose. If
+// the value returned by the hook can be assigned to the target directly it
+// is and true is returned.
+func (r *Recomposer) decodeHook(v any, target reflect.Value) (any, bool) {
+	v, err := r.DecodeHook("$"+strings.Join(r.steps, ""), v, target.Type())
+	if err != nil {
+		panic(err)
+	}
+	if v != nil {
+		if vv := reflect.ValueOf(v); vv.Type().AssignableTo(target.Type()) {
+			target.Set(vv)
+			return v, true
+		}
+	}
+	return v, false
+}
+
+// versionKey returns the key of the version member used by migrations.
+func (r *Recomposer) versionKey() string {
+	if 0 < len(r.VersionKey) {
+		return r.VersionKey
+	}
+	return "v"
+}
+
+// migrate applies the migrations of a composer to the data starting with
+// the version of the data.
+func (r *Recomposer) migrate(c *composer, vm map[string]any) map[string]any {
+	if c == nil || len(c.migrations) == 0 {
+		return vm
+	}
+	key := r.versionKey()
+	var version int64
+	switch tv := vm[key].(type) {
+	case nil:
+	case int64:
+		version = tv
+	case int:
+		version = int64(tv)
+	case float64:
+		if version = int64(tv); float64(version) != tv {
+			panic(&ojg.ErrTypeMismatch{Path: "." + key, Message: fmt.Sprintf("%v is not a valid version", tv)})
+		}
+	case json.Number:
+		var err error
+		if version, err = tv.Int64(); err != nil {
+			panic(&ojg.ErrTypeMismatch{Path: "." + key, Message: fmt.Sprintf("%s is not a valid version", tv)})
+		}
+	default:
+		panic(&ojg.ErrTypeMismatch{Path: "." + key, Message: fmt.Sprintf("a %T is not a valid version", tv)})
+	}
+	for {
+		fun := c.migrations[version]
+		if fun == nil {
+			break
+		}
+		var err error
+		if vm, err = fun(vm); err != nil {
+			panic(err)
+		}
+		version++
+	}
+	return vm
+}
+
+// addMismatchPath is deferred to prepend the key or index, if the index is
+// not negative, to the path of an ojg.ErrTypeMismatch or
+// ojg.ErrUnknownFieldAt that is being raised.
+func addMismatchPath(key string, index int) {
+	if rec := recover(); rec != nil {
+		switch tr := rec.(type) {
+		case *ojg.ErrTypeMismatch:
+			tr.Path = pathStep(key, index) + tr.Path
+		case *ojg.ErrUnknownFieldAt:
+			tr.Path = pathStep(key, index) + tr.Path
+		}
+		panic(rec)
+	}
+}
+
+// pathStep returns the JSONPath step for the key or, if the index is not
+// negative, the index.
+func pathStep(key string, index int) string {
+	if 0 <= index {
+		return "[" + strconv.Itoa(index) + "]"
+	}
+	if simpleKey(key) {
+		return "." + key
+	}
+	return "['" + strings.ReplaceAll(key, "'", `\'`) + "']"
+}
+
+func simpleKey(key string) bool {
+	if len(key) == 0 {
+		return false
+	}
+	for i, b := range []byte(key) {
+		switch {
+		case 'a' <= b && b <= 'z', 'A' <= b && b <= 'Z', b == '_':
+		case '0' <= b && b <= '9':
+			if i == 0 {
+				return false
 			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// setIntString sets an integer or unsigned integer value from a decimal
+// string such as one written with the BigIntAsString option or panics with
+// an ojg.ErrTypeMismatch if the string is not a valid value of that type.
+func setIntString(s string, rv reflect.Value) {
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil && !rv.OverflowInt(i) {
+			rv.SetInt(i)
+			return
+		}
+	default:
+		if u, err := strconv.ParseUint(s, 10, 64); err == nil && !rv.OverflowUint(u) {
+			rv.SetUint(u)
+			return
 		}
-		r.recomp(v, rv)
 	}
+	panic(&ojg.ErrTypeMismatch{Message: fmt.Sprintf("%q is not a valid %s", s, rv.Type())})
+}
+
+// setConverted sets rv to v converted to the type of rv or panics with an
+// ojg.ErrTypeMismatch if v can not be converted.
+func setConverted(v any, rv reflect.Value) {
+	vv := reflect.ValueOf(v)
+	if !vv.IsValid() || !vv.CanConvert(rv.Type()) {
+		panic(&ojg.ErrTypeMismatch{
+			Message: fmt.Sprintf("value of type %T cannot be converted to type %s", v, rv.Type()),
+		})
+	}
+	rv.Set(vv.Convert(rv.Type()))
+}
+
+func typeMismatch(v any, rv reflect.Value) error {
+	return &ojg.ErrTypeMismatch{Message: fmt.Sprintf("can not convert (%T)%v to a %s", v, v, rv.Type())}
 }
@@ -4,7 +4,9 @@ package alt_test
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -134,6 +136,26 @@ func TestRecomposeReflect(t *testing.T) {
 	tt.Equal(t, 3, d.Val)
 }
 
+func TestRecomposeDisallowUnknownFields(t *testing.T) {
+	r := alt.MustNewRecomposer("^", nil)
+	r.DisallowUnknownFields = true
+	var p Parent
+	_, err := r.Recompose(map[string]any{"^": "Parent", "name": "Pat", "num": 3, "Spouse": nil}, &p)
+	tt.Nil(t, err)
+	tt.Equal(t, "Pat", p.Name)
+
+	_, err = r.Recompose(map[string]any{"name": "Pat", "extra": true}, &p)
+	tt.NotNil(t, err)
+	tt.Equal(t, `unknown field "extra" for alt_test.Parent at '$.extra'`, err.Error())
+
+	_, err = r.Recompose(map[string]any{"children": []any{map[string]any{"nom": "x"}}}, &p)
+	tt.ErrorIs(t, err, ojg.ErrUnknownField)
+	var uf *ojg.ErrUnknownFieldAt
+	tt.ErrorAs(t, err, &uf)
+	tt.Equal(t, "$.children[0].nom", uf.Path)
+	tt.Equal(t, "nom", uf.Key)
+}
+
 func TestRecomposeAttrSetter(t *testing.T) {
 	src := map[string]any{"type": "Setter", "a": 3, "b": "bee"}
 	r, err := alt.NewRecomposer("type", map[any]alt.RecomposeFunc{&Setter{}: nil})
@@ -856,3 +878,181 @@ func TestRecomposeReflectArray(t *testing.T) {
 
 	tt.Panic(t, func() { _ = r.MustRecompose(map[string]any{"a": 1}, &tri) })
 }
+
+type Contact struct {
+	V     int
+	First string
+	Last  string
+	Email string
+}
+
+func TestRecomposeMigration(t *testing.T) {
+	r := alt.MustNewRecomposer("^", nil)
+	// Version 0 had a single name member.
+	err := r.RegisterMigration(&Contact{}, 0, func(m map[string]any) (map[string]any, error) {
+		name, _ := m["name"].(string)
+		first, last, _ := strings.Cut(name, " ")
+		delete(m, "name")
+		m["first"] = first
+		m["last"] = last
+		return m, nil
+	})
+	tt.Nil(t, err)
+	// Version 1 called email mail.
+	tt.Nil(t, r.RegisterMigration(Contact{}, 1, func(m map[string]any) (map[string]any, error) {
+		m["email"] = m["mail"]
+		delete(m, "mail")
+		m["v"] = int64(2)
+		return m, nil
+	}))
+	r.DisallowUnknownFields = true
+
+	var c Contact
+	_, err = r.Recompose(map[string]any{"name": "Ann Bee", "mail": "ann@x.com"}, &c)
+	tt.Nil(t, err)
+	tt.Equal(t, Contact{V: 2, First: "Ann", Last: "Bee", Email: "ann@x.com"}, c)
+
+	c = Contact{}
+	_, err = r.Recompose(map[string]any{"v": int64(1), "first": "Cy", "mail": "cy@x.com"}, &c)
+	tt.Nil(t, err)
+	tt.Equal(t, Contact{V: 2, First: "Cy", Email: "cy@x.com"}, c)
+
+	c = Contact{}
+	_, err = r.Recompose(map[string]any{"v": 2.0, "first": "Di", "email": "di@x.com"}, &c)
+	tt.Nil(t, err)
+	tt.Equal(t, Contact{V: 2, First: "Di", Email: "di@x.com"}, c)
+
+	// Migrations also apply when the type comes from the create key.
+	v, err := r.Recompose(map[string]any{"^": "Contact", "name": "Ed Fox"})
+	tt.Nil(t, err)
+	tt.Equal(t, &Contact{V: 2, First: "Ed", Last: "Fox"}, v)
+
+	_, err = r.Recompose(map[string]any{"v": "one"}, &c)
+	var tm *ojg.ErrTypeMismatch
+	tt.ErrorAs(t, err, &tm)
+	tt.Equal(t, "$.v", tm.Path)
+
+	_, err = r.Recompose([]any{map[string]any{"v": 1.5}}, &[]Contact{})
+	tt.ErrorAs(t, err, &tm)
+	tt.Equal(t, "$[0].v", tm.Path)
+
+	tt.NotNil(t, r.RegisterMigration(Contact{}, 3, nil))
+	tt.NotNil(t, r.RegisterMigration(3, 0, func(m map[string]any) (map[string]any, error) { return m, nil }))
+
+	r.VersionKey = "version"
+	tt.Nil(t, r.RegisterMigration(Contact{}, 2, func(m map[string]any) (map[string]any, error) {
+		return nil, fmt.Errorf("no more")
+	}))
+	_, err = r.Recompose(map[string]any{"version": int64(2)}, &c)
+	tt.NotNil(t, err)
+}
+
+type genPair[K comparable, V any] struct {
+	Key K
+	Val V
+}
+
+type genPage[T any] struct {
+	Items []T `json:"items"`
+	Total int `json:"total"`
+	First *T  `json:"first"`
+}
+
+func TestRecomposeGeneric(t *testing.T) {
+	src := genPage[genPair[string, int]]{
+		Items: []genPair[string, int]{{Key: "a", Val: 1}, {Key: "b", Val: 2}},
+		Total: 2,
+		First: &genPair[string, int]{Key: "a", Val: 1},
+	}
+	r := alt.MustNewRecomposer("^", map[any]alt.RecomposeFunc{
+		&genPage[genPair[string, int]]{}: nil,
+		&genPair[string, int]{}:          nil,
+		&genPair[string, bool]{}:         nil,
+	})
+	for _, full := range []bool{false, true} {
+		data := alt.Decompose(&src, &ojg.Options{CreateKey: "^", FullTypePath: full})
+		v, err := r.Recompose(data)
+		tt.Nil(t, err)
+		tt.Equal(t, &src, v)
+	}
+	// Type arguments can be named without package paths.
+	v, err := r.Recompose(map[string]any{
+		"^":     "genPage[genPair[string,int]]",
+		"items": []any{map[string]any{"key": "c", "val": 3}},
+	})
+	tt.Nil(t, err)
+	tt.Equal(t, &genPage[genPair[string, int]]{Items: []genPair[string, int]{{Key: "c", Val: 3}}}, v)
+
+	// Each instantiation uses its own fields.
+	v, err = r.Recompose(map[string]any{"^": "genPair[string,bool]", "key": "x", "val": true})
+	tt.Nil(t, err)
+	tt.Equal(t, &genPair[string, bool]{Key: "x", Val: true}, v)
+
+	var page genPage[genPair[string, bool]]
+	_, err = r.Recompose(map[string]any{"items": []any{map[string]any{"key": "y", "val": false}}, "total": 1}, &page)
+	tt.Nil(t, err)
+	tt.Equal(t, genPage[genPair[string, bool]]{Items: []genPair[string, bool]{{Key: "y"}}, Total: 1}, page)
+}
+
+type withDefaults struct {
+	Name    string         `json:"name" default:"anon"`
+	Count   int            `json:"count" default:"3"`
+	Ratio   float64        `json:"ratio" default:"0.5"`
+	On      bool           `json:"on" default:"true"`
+	Tags    []string       `json:"tags" default:"[\"a\",\"b\"]"`
+	Limit   *int64         `json:"limit" default:"9007199254740993"`
+	When    time.Time      `json:"when" default:"2025-01-02T03:04:05Z"`
+	Extra   map[string]any `json:"extra" default:"{\"x\":1}"`
+	Plain   int            `json:"plain"`
+	Present string         `json:"present" default:"unused"`
+}
+
+func TestRecomposeDefaultTag(t *testing.T) {
+	var wd withDefaults
+	_, err := alt.Recompose(map[string]any{"present": "here", "on": false, "count": nil}, &wd)
+	tt.Nil(t, err)
+	tt.NotNil(t, wd.Limit)
+	tt.Equal(t, int64(9007199254740993), *wd.Limit)
+	wd.Limit = nil
+	tt.Equal(t, withDefaults{
+		Name:    "anon",
+		Count:   0,
+		Ratio:   0.5,
+		On:      false,
+		Tags:    []string{"a", "b"},
+		When:    time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Extra:   map[string]any{"x": int64(1)},
+		Present: "here",
+	}, wd)
+
+	// The parsed default is not shared between recomposed values.
+	type anyDefault struct {
+		A any `json:"a" default:"{\"x\":1}"`
+	}
+	var ad anyDefault
+	_, err = alt.Recompose(map[string]any{}, &ad)
+	tt.Nil(t, err)
+	ad.A.(map[string]any)["x"] = 2
+	_, err = alt.Recompose(map[string]any{}, &ad)
+	tt.Nil(t, err)
+	tt.Equal(t, map[string]any{"x": int64(1)}, ad.A)
+
+	type bad struct {
+		N int `json:"n" default:"many"`
+	}
+	var b bad
+	_, err = alt.Recompose(map[string]any{}, &b)
+	tt.NotNil(t, err, "invalid default")
+	var tm *ojg.ErrTypeMismatch
+	tt.Equal(t, true, errors.As(err, &tm))
+	tt.Equal(t, "$.n", tm.Path)
+
+	type trailing struct {
+		N int `json:"n" default:"1 2"`
+	}
+	var tr trailing
+	_, err = alt.Recompose(map[string]any{}, &tr)
+	tt.NotNil(t, err, "trailing data in default")
+	tt.Equal(t, true, errors.As(err, &tm))
+	tt.Equal(t, "$.n", tm.Path)
+}
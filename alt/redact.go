@@ -0,0 +1,202 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package alt
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/ohler55/ojg/gen"
+)
+
+// DefaultRedactMask is the value that replaces redacted values when the
+// Mask of a Redactor is empty.
+const DefaultRedactMask = "***"
+
+// Redactor replaces the values of object members and struct fields with
+// keys that match one of the Keys patterns. Struct fields with a redact json
+// tag option such as `json:"password,redact"` are always replaced. It is
+// intended for scrubbing sensitive data such as passwords and tokens from
+// data before it is logged or stored. The same rules are applied by the oj
+// Writer Redact option.
+type Redactor struct {
+	// Keys are patterns matched against object keys ignoring case. A '*'
+	// in a pattern matches any sequence of characters so "*_token" matches
+	// "auth_token" and "Refresh_Token".
+	Keys []string
+
+	// Mask replaces redacted values. The default is DefaultRedactMask.
+	Mask string
+}
+
+// Redact replaces the values of all object members and struct fields at any
+// depth in data that should be redacted according to the Keys patterns and
+// the redact json tag option. The data is modified in place and returned.
+// Structs are only modified if reached through a pointer, a slice, or an
+// interface so that they can be set. Redacted struct fields and typed map
+// values that can not hold the mask are set to their zero value.
+func (r *Redactor) Redact(data any) any {
+	r.redact(data)
+
+	return data
+}
+
+// MaskValue returns the Mask or DefaultRedactMask if the Mask is empty.
+func (r *Redactor) MaskValue() string {
+	if len(r.Mask) == 0 {
+		return DefaultRedactMask
+	}
+	return r.Mask
+}
+
+// Match returns true if the key matches one of the Keys patterns.
+func (r *Redactor) Match(key string) bool {
+	if len(r.Keys) == 0 {
+		return false
+	}
+	key = strings.ToLower(key)
+	for _, p := range r.Keys {
+		if matchKeyPattern(strings.ToLower(p), key) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactTag returns true if the json tag of the field includes the redact
+// option.
+func RedactTag(f *reflect.StructField) bool {
+	if tag, ok := f.Tag.Lookup("json"); ok {
+		for _, opt := range strings.Split(tag, ",")[1:] {
+			if opt == "redact" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r *Redactor) redact(data any) {
+	switch td := data.(type) {
+	case nil, bool, int64, float64, string:
+	case []any:
+		for _, v := range td {
+			r.redact(v)
+		}
+	case map[string]any:
+		for k, v := range td {
+			if r.Match(k) {
+				td[k] = r.MaskValue()
+			} else {
+				r.redact(v)
+			}
+		}
+	case gen.Array:
+		for _, v := range td {
+			r.redact(v)
+		}
+	case gen.Object:
+		for k, v := range td {
+			if r.Match(k) {
+				td[k] = gen.String(r.MaskValue())
+			} else {
+				r.redact(v)
+			}
+		}
+	default:
+		r.redactValue(reflect.ValueOf(data))
+	}
+}
+
+func (r *Redactor) redactValue(rv reflect.Value) {
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !rv.IsNil() {
+			r.redactValue(rv.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			r.redactValue(rv.Index(i))
+		}
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			for _, kv := range rv.MapKeys() {
+				r.redactMapValue(rv.MapIndex(kv))
+			}
+			return
+		}
+		for _, kv := range rv.MapKeys() {
+			if r.Match(kv.String()) {
+				rv.SetMapIndex(kv, r.maskFor(rv.Type().Elem()))
+			} else {
+				r.redactMapValue(rv.MapIndex(kv))
+			}
+		}
+	case reflect.Struct:
+		if !rv.CanSet() {
+			return
+		}
+		for _, sf := range StructFields(rv.Type(), true, true) {
+			fv, err := rv.FieldByIndexErr(sf.Index)
+			if err != nil || !fv.CanSet() {
+				continue
+			}
+			if RedactTag(&sf.StructField) || r.Match(sf.Key) {
+				fv.Set(r.maskFor(fv.Type()))
+			} else {
+				r.redactValue(fv)
+			}
+		}
+	}
+}
+
+// redactMapValue redacts a map value which can not be set directly so only
+// values that refer to other data are redacted.
+func (r *Redactor) redactMapValue(mv reflect.Value) {
+	switch mv.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+		r.redactValue(mv)
+	}
+}
+
+// maskFor returns the mask as a value of the type or the zero value of the
+// type if the mask can not be assigned to it.
+func (r *Redactor) maskFor(rt reflect.Type) reflect.Value {
+	mv := reflect.ValueOf(r.MaskValue())
+	switch {
+	case mv.Type().AssignableTo(rt):
+		return mv
+	case rt.Kind() == reflect.String:
+		return mv.Convert(rt)
+	}
+	return reflect.Zero(rt)
+}
+
+// matchKeyPattern returns true if the key matches the pattern where a '*'
+// in the pattern matches any sequence of bytes.
+func matchKeyPattern(pattern, key string) bool {
+	var pi, ki int
+	star := -1
+	mark := 0
+	for ki < len(key) {
+		switch {
+		case pi < len(pattern) && pattern[pi] == '*':
+			star = pi
+			mark = ki
+			pi++
+		case pi < len(pattern) && pattern[pi] == key[ki]:
+			pi++
+			ki++
+		case 0 <= star:
+			pi = star + 1
+			mark++
+			ki = mark
+		default:
+			return false
+		}
+	}
+	for pi < len(pattern) && pattern[pi] == '*' {
+		pi++
+	}
+	return pi == len(pattern)
+}
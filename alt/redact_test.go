@@ -0,0 +1,100 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package alt_test
+
+import (
+	"testing"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/alt"
+	"github.com/ohler55/ojg/gen"
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/sen"
+	"github.com/ohler55/ojg/tt"
+)
+
+func TestRedactorRedact(t *testing.T) {
+	data := sen.MustParse([]byte(`{
+  user: {name: bob Password: secret}
+  auth_token: abc
+  sessions: [{id: 1 refresh_token: xyz} {id: 2 token: plain}]
+}`))
+	r := alt.Redactor{Keys: []string{"password", "*_token"}}
+	result := r.Redact(data)
+	tt.Equal(t,
+		`{auth_token:*** sessions:[{id:1 refresh_token:***}{id:2 token:plain}] user:{Password:*** name:bob}}`,
+		sen.String(result, &sen.Options{Sort: true}))
+
+	r = alt.Redactor{Keys: []string{"user"}, Mask: "[redacted]"}
+	result = r.Redact(map[string]any{"user": map[string]any{"name": "bob"}})
+	tt.Equal(t, map[string]any{"user": "[redacted]"}, result)
+
+	var empty alt.Redactor
+	tt.Equal(t, []any{int64(1)}, empty.Redact([]any{int64(1)}))
+	tt.Equal(t, alt.DefaultRedactMask, empty.MaskValue())
+}
+
+type redactAccount struct {
+	Name     string            `json:"name"`
+	Secret   string            `json:"secret,redact"`
+	PIN      int               `json:"pin,redact"`
+	APIToken string            `json:"api_token"`
+	Extra    map[string]string `json:"extra"`
+	Sub      *redactAccount    `json:"sub,omitempty"`
+}
+
+func TestRedactorRedactStruct(t *testing.T) {
+	newAccount := func() *redactAccount {
+		return &redactAccount{
+			Name:     "bob",
+			Secret:   "s1",
+			PIN:      1234,
+			APIToken: "abc",
+			Extra:    map[string]string{"password": "pw", "color": "red"},
+			Sub:      &redactAccount{Name: "sub", Secret: "s2"},
+		}
+	}
+	r := alt.Redactor{Keys: []string{"*_token", "password"}}
+	result := r.Redact([]any{newAccount()})
+	a := result.([]any)[0].(*redactAccount)
+	tt.Equal(t, "bob", a.Name)
+	tt.Equal(t, "***", a.Secret)
+	tt.Equal(t, 0, a.PIN)
+	tt.Equal(t, "***", a.APIToken)
+	tt.Equal(t, map[string]string{"password": "***", "color": "red"}, a.Extra)
+	tt.Equal(t, "***", a.Sub.Secret)
+
+	// The writer applies the same rules.
+	wr := oj.Writer{Options: ojg.Options{UseTags: true, Sort: true}, Redact: &r}
+	tt.Equal(t,
+		`{"api_token":"***","extra":{"color":"red","password":"***"},"name":"bob","pin":"***","secret":"***",`+
+			`"sub":{"api_token":"***","extra":{},"name":"sub","pin":"***","secret":"***"}}`,
+		wr.JSON(newAccount()))
+
+	node := gen.Object{"password": gen.String("pw"), "list": gen.Array{gen.Object{"auth_token": gen.Int(1)}}}
+	r.Redact(node)
+	tt.Equal(t, `{list:[{auth_token:***}] password:***}`, sen.String(node, &ojg.Options{Sort: true}))
+}
+
+func TestRedactorMatch(t *testing.T) {
+	r := alt.Redactor{Keys: []string{"password", "*_token", "api*key", "x*"}}
+	for _, d := range []struct {
+		key    string
+		expect bool
+	}{
+		{key: "password", expect: true},
+		{key: "PassWord", expect: true},
+		{key: "passwords", expect: false},
+		{key: "auth_token", expect: true},
+		{key: "_token", expect: true},
+		{key: "token", expect: false},
+		{key: "auth_token_x", expect: false},
+		{key: "apikey", expect: true},
+		{key: "api_secret_key", expect: true},
+		{key: "api_keys", expect: false},
+		{key: "x", expect: true},
+		{key: "", expect: false},
+	} {
+		tt.Equal(t, d.expect, r.Match(d.key), d.key)
+	}
+}
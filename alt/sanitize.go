@@ -0,0 +1,232 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package alt
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ohler55/ojg/gen"
+)
+
+// SanitizeAction identifies how Sanitize replaces a value that can not be
+// encoded as strict JSON.
+type SanitizeAction byte
+
+const (
+	// SanitizeNull replaces the value with nil.
+	SanitizeNull = SanitizeAction(0)
+
+	// SanitizeRemove removes the value from the containing array or
+	// object. A removed top level value is replaced with nil.
+	SanitizeRemove = SanitizeAction('r')
+
+	// SanitizeString replaces the value with a string that describes it
+	// such as "NaN", "+Inf", "func()", or "cycle".
+	SanitizeString = SanitizeAction('s')
+)
+
+// SanitizePolicy is the policy Sanitize applies to each kind of value that
+// can not be encoded as strict JSON. The zero value replaces everything
+// with nil.
+type SanitizePolicy struct {
+	// NonFinite is the action for the NaN, +Inf, and -Inf float values.
+	NonFinite SanitizeAction
+
+	// Unsupported is the action for channels, functions, complex numbers,
+	// and unsafe pointers.
+	Unsupported SanitizeAction
+
+	// Cycle is the action for an array or object that contains itself.
+	Cycle SanitizeAction
+}
+
+// SanitizeFix describes a value replaced or removed by Sanitize.
+type SanitizeFix struct {
+	// Path is the normalized JSONPath of the value such as "$.a[2]".
+	Path string
+
+	// Reason is why the value was replaced such as "NaN", "+Inf",
+	// "func()", or "cycle".
+	Reason string
+
+	// Action is the action applied.
+	Action SanitizeAction
+}
+
+type sanitizer struct {
+	policy    *SanitizePolicy
+	fixes     []SanitizeFix
+	path      []any
+	ancestors []uintptr
+}
+
+// Sanitize walks the data and replaces the values that can not be encoded
+// as strict JSON according to the policy so that a later Marshal of the
+// result can not fail part way through writing. A nil policy is the same
+// as the zero value. Arrays and objects of type []any and map[string]any
+// are modified in place. Other values such as structs are converted with
+// Decompose() when they hold a value to replace. The sanitized data is
+// returned along with a description of each value that was replaced or
+// removed. Cycles are only detected through []any and map[string]any
+// values.
+func Sanitize(data any, policy *SanitizePolicy) (any, []SanitizeFix) {
+	s := sanitizer{policy: policy}
+	if s.policy == nil {
+		s.policy = &SanitizePolicy{}
+	}
+	v, keep := s.sanitize(data)
+	if !keep {
+		v = nil
+	}
+	return v, s.fixes
+}
+
+// sanitize returns the sanitized value and false if the value is to be
+// removed.
+func (s *sanitizer) sanitize(v any) (any, bool) {
+	switch tv := v.(type) {
+	case nil, bool, string, time.Time,
+		int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64,
+		gen.Bool, gen.Int, gen.String, gen.Time, gen.Big:
+		return v, true
+	case float64:
+		return s.float(v, tv)
+	case float32:
+		return s.float(v, float64(tv))
+	case gen.Float:
+		return s.float(v, float64(tv))
+	case []any:
+		if s.isAncestor(reflect.ValueOf(tv)) {
+			return s.fix(v, "cycle", s.policy.Cycle)
+		}
+		s.ancestors = append(s.ancestors, reflect.ValueOf(tv).Pointer())
+		out := tv[:0]
+		for i, m := range tv {
+			s.path = append(s.path, i)
+			if m, keep := s.sanitize(m); keep {
+				out = append(out, m)
+			}
+			s.path = s.path[:len(s.path)-1]
+		}
+		clear(tv[len(out):])
+		s.ancestors = s.ancestors[:len(s.ancestors)-1]
+		return out, true
+	case map[string]any:
+		if s.isAncestor(reflect.ValueOf(tv)) {
+			return s.fix(v, "cycle", s.policy.Cycle)
+		}
+		s.ancestors = append(s.ancestors, reflect.ValueOf(tv).Pointer())
+		for k, m := range tv {
+			s.path = append(s.path, k)
+			if m, keep := s.sanitize(m); keep {
+				tv[k] = m
+			} else {
+				delete(tv, k)
+			}
+			s.path = s.path[:len(s.path)-1]
+		}
+		s.ancestors = s.ancestors[:len(s.ancestors)-1]
+		return v, true
+	case gen.Node:
+		// Other gen types are encodable.
+		return v, true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer, reflect.Complex64, reflect.Complex128:
+		return s.fix(v, rv.Type().String(), s.policy.Unsupported)
+	case reflect.Float32, reflect.Float64:
+		return s.float(v, rv.Float())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Bool, reflect.String:
+		return v, true
+	}
+	// Only decompose if there is something to fix.
+	before := len(s.fixes)
+	// No type key is added since the result is meant to be written.
+	opt := DefaultOptions
+	opt.CreateKey = ""
+	dv, _ := s.sanitize(Decompose(v, &opt))
+	if before < len(s.fixes) {
+		return dv, true
+	}
+	return v, true
+}
+
+func (s *sanitizer) float(v any, f float64) (any, bool) {
+	switch {
+	case math.IsNaN(f):
+		return s.fix(v, "NaN", s.policy.NonFinite)
+	case math.IsInf(f, 1):
+		return s.fix(v, "+Inf", s.policy.NonFinite)
+	case math.IsInf(f, -1):
+		return s.fix(v, "-Inf", s.policy.NonFinite)
+	}
+	return v, true
+}
+
+func (s *sanitizer) fix(v any, reason string, action SanitizeAction) (any, bool) {
+	s.fixes = append(s.fixes, SanitizeFix{Path: s.pathString(), Reason: reason, Action: action})
+	switch action {
+	case SanitizeRemove:
+		return nil, false
+	case SanitizeString:
+		return reason, true
+	}
+	return nil, true
+}
+
+func (s *sanitizer) isAncestor(rv reflect.Value) bool {
+	// Empty values can not contain themselves and empty slices may all
+	// share the same pointer.
+	if rv.Len() == 0 {
+		return false
+	}
+	p := rv.Pointer()
+	for _, a := range s.ancestors {
+		if a == p {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *sanitizer) pathString() string {
+	var b strings.Builder
+	b.WriteByte('$')
+	for _, p := range s.path {
+		switch tp := p.(type) {
+		case int:
+			b.WriteByte('[')
+			b.WriteString(strconv.Itoa(tp))
+			b.WriteByte(']')
+		case string:
+			if isPlainKey(tp) {
+				b.WriteByte('.')
+				b.WriteString(tp)
+			} else {
+				b.WriteByte('[')
+				b.WriteString(strconv.Quote(tp))
+				b.WriteByte(']')
+			}
+		}
+	}
+	return b.String()
+}
+
+func isPlainKey(key string) bool {
+	if len(key) == 0 {
+		return false
+	}
+	for i, b := range []byte(key) {
+		if !('a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' || b == '_' || (0 < i && '0' <= b && b <= '9')) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package alt_test
+
+import (
+	"math"
+	"sort"
+	"testing"
+
+	"github.com/ohler55/ojg/alt"
+	"github.com/ohler55/ojg/tt"
+)
+
+type sanitizeSample struct {
+	Name  string
+	Ratio float64
+}
+
+func sortFixes(fixes []alt.SanitizeFix) {
+	sort.Slice(fixes, func(i, j int) bool { return fixes[i].Path < fixes[j].Path })
+}
+
+func TestSanitize(t *testing.T) {
+	data := map[string]any{
+		"a":     []any{1, math.NaN(), "x", math.Inf(-1)},
+		"b":     math.Inf(1),
+		"f":     func() {},
+		"c":     make(chan int),
+		"s":     &sanitizeSample{Name: "s", Ratio: math.NaN()},
+		"ok":    &sanitizeSample{Name: "ok", Ratio: 1.5},
+		"empty": []any{[]any{}},
+		"x y":   complex(1, 2),
+	}
+	ok := data["ok"]
+	v, fixes := alt.Sanitize(data, nil)
+	sortFixes(fixes)
+	tt.Equal(t, []alt.SanitizeFix{
+		{Path: "$.a[1]", Reason: "NaN"},
+		{Path: "$.a[3]", Reason: "-Inf"},
+		{Path: "$.b", Reason: "+Inf"},
+		{Path: "$.c", Reason: "chan int"},
+		{Path: "$.f", Reason: "func()"},
+		{Path: "$.s.ratio", Reason: "NaN"},
+		{Path: `$["x y"]`, Reason: "complex128"},
+	}, fixes)
+	tt.Equal(t, map[string]any{
+		"a":     []any{1, nil, "x", nil},
+		"b":     nil,
+		"f":     nil,
+		"c":     nil,
+		"s":     map[string]any{"name": "s", "ratio": nil},
+		"ok":    ok,
+		"empty": []any{[]any{}},
+		"x y":   nil,
+	}, v)
+	tt.Equal(t, true, ok == data["ok"])
+
+	policy := alt.SanitizePolicy{NonFinite: alt.SanitizeString, Unsupported: alt.SanitizeRemove}
+	v, fixes = alt.Sanitize([]any{math.NaN(), func() {}, 2}, &policy)
+	tt.Equal(t, []any{"NaN", 2}, v)
+	tt.Equal(t, 2, len(fixes))
+	tt.Equal(t, alt.SanitizeRemove, fixes[1].Action)
+
+	v, _ = alt.Sanitize(func() {}, &policy)
+	tt.Nil(t, v)
+}
+
+func TestSanitizeCycle(t *testing.T) {
+	obj := map[string]any{"a": 1}
+	obj["self"] = obj
+	shared := []any{1}
+	v, fixes := alt.Sanitize(map[string]any{"obj": obj, "s1": shared, "s2": shared}, &alt.SanitizePolicy{Cycle: alt.SanitizeString})
+	tt.Equal(t, []alt.SanitizeFix{{Path: "$.obj.self", Reason: "cycle", Action: alt.SanitizeString}}, fixes)
+	tt.Equal(t, map[string]any{
+		"obj": map[string]any{"a": 1, "self": "cycle"},
+		"s1":  []any{1},
+		"s2":  []any{1},
+	}, v)
+}
+
+func TestSanitizeStructNoTypeKey(t *testing.T) {
+	v, fixes := alt.Sanitize([]any{&sanitizeSample{Name: "s", Ratio: math.Inf(1)}}, nil)
+	tt.Equal(t, 1, len(fixes))
+	tt.Equal(t, map[string]any{"name": "s", "ratio": nil}, v.([]any)[0])
+	obj, _ := v.([]any)[0].(map[string]any)
+	tt.Equal(t, 2, len(obj))
+}
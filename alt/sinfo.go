@@ -93,124 +93,78 @@ func buildFields(rt reflect.Type, u byte, omitEmpty bool) (fa []*finfo) {
 }
 
 func buildTagFields(rt reflect.Type, nested, omitEmpty bool) (fa []*finfo) {
-	for i := rt.NumField() - 1; 0 <= i; i-- {
-		f := rt.Field(i)
-		name := []byte(f.Name)
-		if len(name) == 0 || 'a' <= name[0] {
-			continue
-		}
+	for _, sf := range StructFields(rt, true, nested) {
 		var fx byte
-		if f.Anonymous && nested {
-			if f.Type.Kind() == reflect.Ptr {
-				for _, fi := range buildTagFields(f.Type.Elem(), nested, omitEmpty) {
-					fi.index = append([]int{i}, fi.index...)
-					fi.value = fi.ivalue
-					fa = append(fa, fi)
-				}
-			} else {
-				for _, fi := range buildTagFields(f.Type, nested, omitEmpty) {
-					fi.index = append([]int{i}, fi.index...)
-					fi.offset += f.Offset
-					fa = append(fa, fi)
-				}
-			}
-		} else {
-			key := f.Name
-			if tag, ok := f.Tag.Lookup("json"); ok && 0 < len(tag) {
-				parts := strings.Split(tag, ",")
-				switch parts[0] {
-				case "":
-					key = f.Name
-				case "-":
-					if 1 < len(parts) {
-						key = "-"
-					} else {
-						continue
-					}
-				default:
-					key = parts[0]
-				}
-				for _, p := range parts[1:] {
-					switch p {
-					case "omitempty":
-						fx |= omitMask
-					case "string":
-						fx |= strMask
-					}
+		omitZero := false
+		if tag := sf.Tag.Get("json"); 0 < len(tag) {
+			for _, p := range strings.Split(tag, ",")[1:] {
+				switch p {
+				case "omitempty":
+					fx |= omitMask
+				case "omitzero":
+					omitZero = true
+				case "string":
+					fx |= strMask
 				}
 			}
-			fa = append(fa, newFinfo(&f, key, fx))
 		}
+		fi := newFinfo(&sf.StructField, sf.Key, fx)
+		fi.omitZero = omitZero
+		fi.tagged = sf.Tagged
+		fa = append(fa, fi.promoted(&sf))
 	}
 	return
 }
 
 func buildExactFields(rt reflect.Type, nested, omitEmpty bool) (fa []*finfo) {
-	for i := rt.NumField() - 1; 0 <= i; i-- {
-		f := rt.Field(i)
-		name := []byte(f.Name)
-		if len(name) == 0 || 'a' <= name[0] {
-			continue
-		}
-		switch {
-		case f.Anonymous && nested:
-			if f.Type.Kind() == reflect.Ptr {
-				for _, fi := range buildExactFields(f.Type.Elem(), nested, omitEmpty) {
-					fi.index = append([]int{i}, fi.index...)
-					fi.value = fi.ivalue
-					fa = append(fa, fi)
-				}
-			} else {
-				for _, fi := range buildExactFields(f.Type, nested, omitEmpty) {
-					fi.index = append([]int{i}, fi.index...)
-					fi.offset += f.Offset
-					fa = append(fa, fi)
-				}
-			}
-		case omitEmpty:
-			fa = append(fa, newFinfo(&f, f.Name, omitMask))
-		default:
-			fa = append(fa, newFinfo(&f, f.Name, 0x00))
-		}
+	var fx byte
+	if omitEmpty {
+		fx = omitMask
+	}
+	for _, sf := range StructFields(rt, false, nested) {
+		fa = append(fa, newFinfo(&sf.StructField, sf.Name, fx).promoted(&sf))
 	}
 	return
 }
 
 func buildLowFields(rt reflect.Type, nested, omitEmpty bool) (fa []*finfo) {
-	for i := rt.NumField() - 1; 0 <= i; i-- {
-		f := rt.Field(i)
-		name := []byte(f.Name)
-		if len(name) == 0 || 'a' <= name[0] {
-			continue
-		}
-		if f.Anonymous && nested {
-			if f.Type.Kind() == reflect.Ptr {
-				for _, fi := range buildLowFields(f.Type.Elem(), nested, omitEmpty) {
-					fi.index = append([]int{i}, fi.index...)
-					fi.value = fi.ivalue
-					fa = append(fa, fi)
-				}
-			} else {
-				for _, fi := range buildLowFields(f.Type, nested, omitEmpty) {
-					fi.index = append([]int{i}, fi.index...)
-					fi.offset += f.Offset
-					fa = append(fa, fi)
-				}
+	var fx byte
+	if omitEmpty {
+		fx = omitMask
+	}
+	for _, sf := range StructFields(rt, false, nested) {
+		name := []byte(sf.Name)
+		if 3 < len(name) {
+			if name[0] < 0x80 {
+				name[0] |= 0x20
 			}
 		} else {
-			if 3 < len(name) {
-				if name[0] < 0x80 {
-					name[0] |= 0x20
-				}
-			} else {
-				name = bytes.ToLower(name)
-			}
-			if omitEmpty {
-				fa = append(fa, newFinfo(&f, string(name), omitMask))
-			} else {
-				fa = append(fa, newFinfo(&f, string(name), 0x00))
-			}
+			name = bytes.ToLower(name)
 		}
+		fa = append(fa, newFinfo(&sf.StructField, string(name), fx).promoted(&sf))
 	}
 	return
 }
+
+// promoted sets the offset of a field from the start of the outer struct
+// and switches to the index based value function if the field is reached
+// through an embedded pointer.
+func (fi *finfo) promoted(sf *StructField) *finfo {
+	fi.offset = sf.Offset
+	if sf.Indirect {
+		fi.value = fi.ivalue
+		fi.indirect = true
+	}
+	return fi
+}
+
+// unreachable returns true if the field is behind a nil embedded pointer. As
+// with encoding/json such fields are skipped.
+func (fi *finfo) unreachable(rv reflect.Value) bool {
+	if !fi.indirect {
+		return false
+	}
+	_, err := rv.FieldByIndexErr(fi.index)
+
+	return err != nil
+}
@@ -0,0 +1,208 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package alt
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ohler55/ojg/gen"
+)
+
+// Getter is the interface for types that can get values from data. The
+// jp.Expr type implements the Getter interface.
+type Getter interface {
+	// Get the values identified by the Getter in the data.
+	Get(data any) []any
+}
+
+// SortRule identifies the arrays to sort and the keys used to order the
+// elements of those arrays.
+type SortRule struct {
+	// Arrays identifies the arrays to sort such as jp.MustParseString("$.items").
+	Arrays Getter
+
+	// Keys are relative to each array element, such as
+	// jp.MustParseString("@.id"). The first value found for a key is used
+	// for comparison and elements are compared by each key in order until
+	// there is a difference. Elements without a value for a key are placed
+	// before those that have a value. If there are no keys then the elements
+	// themselves are compared.
+	Keys []Getter
+}
+
+type sortItem struct {
+	v    any
+	keys []any
+}
+
+// SortArrays sorts the arrays identified by the rules in place so that
+// documents that differ only in the order of array elements can be compared
+// with Diff() or Hash(). Rules are applied in the order provided. Values are
+// ordered first by type with nil first followed by booleans, numbers,
+// strings, times, arrays, and then objects. Values of the same type are
+// ordered by value. The sort is stable. The data argument is returned.
+func SortArrays(data any, rules ...*SortRule) any {
+	for _, rule := range rules {
+		if rule == nil || rule.Arrays == nil {
+			continue
+		}
+		for _, a := range rule.Arrays.Get(data) {
+			switch ta := a.(type) {
+			case []any:
+				for i, v := range sortItems(ta, rule.Keys) {
+					ta[i] = v.v
+				}
+			case gen.Array:
+				list := make([]any, len(ta))
+				for i, n := range ta {
+					list[i] = n
+				}
+				for i, v := range sortItems(list, rule.Keys) {
+					ta[i], _ = v.v.(gen.Node)
+				}
+			}
+		}
+	}
+	return data
+}
+
+func sortItems(list []any, keys []Getter) []*sortItem {
+	items := make([]*sortItem, len(list))
+	for i, v := range list {
+		item := sortItem{v: v}
+		if len(keys) == 0 {
+			item.keys = []any{v}
+		} else {
+			item.keys = make([]any, len(keys))
+			for j, k := range keys {
+				if r := k.Get(v); 0 < len(r) {
+					item.keys[j] = r[0]
+				}
+			}
+		}
+		items[i] = &item
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		for k, v := range items[i].keys {
+			if c := compareValues(v, items[j].keys[k]); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+	return items
+}
+
+func sortRank(v any) int {
+	switch v.(type) {
+	case nil:
+		return 0
+	case bool:
+		return 1
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return 2
+	case string:
+		return 3
+	case time.Time:
+		return 4
+	case []any:
+		return 5
+	case map[string]any:
+		return 6
+	}
+	return 7
+}
+
+func sortValue(v any) any {
+	if s, ok := v.(Simplifier); ok {
+		v = s.Simplify()
+	}
+	return v
+}
+
+// compareValues returns a negative value if v0 is less than v1, zero if they
+// are equal, and a positive value if v0 is greater than v1.
+func compareValues(v0, v1 any) int {
+	v0 = sortValue(v0)
+	v1 = sortValue(v1)
+	r0 := sortRank(v0)
+	if r1 := sortRank(v1); r0 != r1 {
+		return r0 - r1
+	}
+	switch t0 := v0.(type) {
+	case bool:
+		t1, _ := v1.(bool)
+		switch {
+		case t0 == t1:
+			return 0
+		case t1:
+			return -1
+		}
+		return 1
+	case string:
+		return strings.Compare(t0, v1.(string))
+	case time.Time:
+		return t0.Compare(v1.(time.Time))
+	case []any:
+		t1, _ := v1.([]any)
+		for i, m := range t0 {
+			if len(t1) <= i {
+				return 1
+			}
+			if c := compareValues(m, t1[i]); c != 0 {
+				return c
+			}
+		}
+		return len(t0) - len(t1)
+	case map[string]any:
+		t1, _ := v1.(map[string]any)
+		k0 := sortedKeys(t0)
+		k1 := sortedKeys(t1)
+		for i, k := range k0 {
+			if len(k1) <= i {
+				return 1
+			}
+			if c := strings.Compare(k, k1[i]); c != 0 {
+				return c
+			}
+			if c := compareValues(t0[k], t1[k]); c != 0 {
+				return c
+			}
+		}
+		return len(k0) - len(k1)
+	}
+	if i0, ok := asInt(v0); ok {
+		if i1, ok := asInt(v1); ok {
+			switch {
+			case i0 < i1:
+				return -1
+			case i1 < i0:
+				return 1
+			}
+			return 0
+		}
+	}
+	if f0, ok := asFloat(v0); ok {
+		if f1, ok := asFloat(v1); ok {
+			switch {
+			case f0 < f1:
+				return -1
+			case f1 < f0:
+				return 1
+			}
+		}
+	}
+	return 0
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package alt_test
+
+import (
+	"testing"
+
+	"github.com/ohler55/ojg/alt"
+	"github.com/ohler55/ojg/gen"
+	"github.com/ohler55/ojg/jp"
+	"github.com/ohler55/ojg/sen"
+	"github.com/ohler55/ojg/tt"
+)
+
+func TestSortArraysKeys(t *testing.T) {
+	data := sen.MustParse([]byte(`{
+  items: [
+    {id: 3 name: c tags: [z x y]}
+    {id: 1 name: a tags: [b a]}
+    {name: none}
+    {id: 2 name: b2 tags: []}
+    {id: 2 name: b1 tags: [c]}
+  ]
+}`))
+	result := alt.SortArrays(data,
+		&alt.SortRule{
+			Arrays: jp.MustParseString("$.items"),
+			Keys:   []alt.Getter{jp.MustParseString("@.id"), jp.MustParseString("@.name")},
+		},
+		&alt.SortRule{Arrays: jp.MustParseString("$.items[*].tags")},
+	)
+	tt.Equal(t,
+		"{items:[{name:none}{id:1 name:a tags:[a b]}{id:2 name:b1 tags:[c]}{id:2 name:b2 tags:[]}{id:3 name:c tags:[x y z]}]}",
+		sen.String(result, &sen.Options{Sort: true}))
+}
+
+func TestSortArraysMixed(t *testing.T) {
+	data := []any{
+		map[string]any{"b": 1},
+		"x",
+		[]any{2},
+		2.5,
+		nil,
+		true,
+		map[string]any{"a": 1},
+		[]any{1, 2},
+		int8(1),
+		false,
+		"a",
+	}
+	alt.SortArrays(data, &alt.SortRule{Arrays: jp.R()})
+	tt.Equal(t, "[null false true 1 2.5 a x [1 2][2]{a:1}{b:1}]", sen.String(data, &sen.Options{Sort: true}))
+}
+
+func TestSortArraysGen(t *testing.T) {
+	data := gen.Object{"list": gen.Array{gen.Int(3), nil, gen.String("a"), gen.Float(1.5)}}
+	alt.SortArrays(data, &alt.SortRule{Arrays: jp.C("list")}, nil)
+	tt.Equal(t, `{"list":[null,1.5,3,"a"]}`, data.String())
+}
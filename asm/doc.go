@@ -18,6 +18,9 @@ An example of a plan in SEN format is (the first asm is optional):
 	  [set $.asm.hello world]  // output is now {good: bad, hello: world}
 	]
 
+A plan can also be compiled with Compile into a Program. A Program can not
+be modified and can be executed concurrently against many roots.
+
 The functions available are:
 
 	      !=: Returns true if any the argument are not equal. An alias is !==.
@@ -0,0 +1,111 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package asm
+
+import (
+	"fmt"
+
+	"github.com/ohler55/ojg"
+)
+
+// Program is a compiled plan. Unlike a Plan, a Program can not be modified
+// after it is created and it can be executed concurrently from multiple
+// goroutines against different roots. Literal arrays and maps in the plan
+// are copied on each evaluation so that one execution never sees changes
+// made by another.
+type Program struct {
+	fn Fn
+}
+
+// Compile a plan described by simple data into a Program. The plan is
+// copied so later changes to the plan argument do not affect the returned
+// Program.
+func Compile(plan []any) (*Program, error) {
+	if len(plan) == 0 {
+		return nil, fmt.Errorf("can not compile an empty plan")
+	}
+	p := NewPlan(dupLiteral(plan).([]any))
+	prog := Program{fn: p.Fn}
+	protectLiterals(&prog.fn)
+
+	return &prog, nil
+}
+
+// MustCompile is the same as Compile except it panics on error.
+func MustCompile(plan []any) *Program {
+	prog, err := Compile(plan)
+	if err != nil {
+		panic(err)
+	}
+	return prog
+}
+
+// Execute the program against the provided root. The root is modified by
+// the program and must not be shared with other concurrent executions.
+func (p *Program) Execute(root map[string]any) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = ojg.NewError(r)
+		}
+	}()
+	p.fn.Eval(root, root, p.fn.Args...)
+
+	return
+}
+
+// Simplify the program in to simple types that can be encodes as JSON or
+// SEN.
+func (p *Program) Simplify() any {
+	return p.fn.Simplify()
+}
+
+// String return a string representation of the program.
+func (p *Program) String() string {
+	return p.fn.String()
+}
+
+// protectLiterals walks the compiled functions and wraps the Eval of any
+// function that has literal array or map arguments so that each evaluation
+// is given a copy of those arguments instead of the shared originals.
+func protectLiterals(f *Fn) {
+	var lits []int
+	for i, a := range f.Args {
+		switch ta := a.(type) {
+		case *Fn:
+			protectLiterals(ta)
+		case []any, map[string]any:
+			lits = append(lits, i)
+		}
+	}
+	if 0 < len(lits) {
+		eval := f.Eval
+		f.Eval = func(root map[string]any, at any, args ...any) any {
+			dup := make([]any, len(args))
+			copy(dup, args)
+			for _, i := range lits {
+				if i < len(dup) {
+					dup[i] = dupLiteral(dup[i])
+				}
+			}
+			return eval(root, at, dup...)
+		}
+	}
+}
+
+func dupLiteral(v any) any {
+	switch tv := v.(type) {
+	case []any:
+		a := make([]any, len(tv))
+		for i, m := range tv {
+			a[i] = dupLiteral(m)
+		}
+		return a
+	case map[string]any:
+		m := make(map[string]any, len(tv))
+		for k, mv := range tv {
+			m[k] = dupLiteral(mv)
+		}
+		return m
+	}
+	return v
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package asm_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/ohler55/ojg/asm"
+	"github.com/ohler55/ojg/sen"
+	"github.com/ohler55/ojg/tt"
+)
+
+func TestProgramExecute(t *testing.T) {
+	plan := sen.MustParse([]byte(`[
+  [set $.asm {good: bye}]
+  [set $.asm.hello [get $.src.name]]
+]`)).([]any)
+	prog, err := asm.Compile(plan)
+	tt.Nil(t, err)
+	tt.Equal(t, "[asm [set $.asm {good:bye}][set $.asm.hello [get $.src.name]]]", prog.String())
+
+	// Changes to the original plan must not change the program.
+	plan[0] = []any{"set", "$.asm", 7}
+
+	root := map[string]any{"src": map[string]any{"name": "one"}}
+	err = prog.Execute(root)
+	tt.Nil(t, err)
+	tt.Equal(t, "{asm:{good:bye hello:one} src:{name:one}}", sen.String(root, &sopt))
+
+	// The literal map must be fresh on each execution.
+	root2 := map[string]any{"src": map[string]any{"name": "two"}}
+	err = prog.Execute(root2)
+	tt.Nil(t, err)
+	tt.Equal(t, "{asm:{good:bye hello:two} src:{name:two}}", sen.String(root2, &sopt))
+	tt.Equal(t, "{asm:{good:bye hello:one} src:{name:one}}", sen.String(root, &sopt))
+}
+
+func TestProgramConcurrent(t *testing.T) {
+	prog := asm.MustCompile(sen.MustParse([]byte(`[
+  [set $.asm {list: []}]
+  [set $.asm.list [append $.asm.list [get $.src.x]]]
+  [set $.asm.double ["+" $.src.x $.src.x]]
+]`)).([]any))
+
+	var wg sync.WaitGroup
+	results := make([]string, 20)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			root := map[string]any{"src": map[string]any{"x": i}}
+			if err := prog.Execute(root); err != nil {
+				results[i] = err.Error()
+				return
+			}
+			results[i] = sen.String(root["asm"], &sopt)
+		}(i)
+	}
+	wg.Wait()
+	for i, s := range results {
+		tt.Equal(t, sen.String(map[string]any{"double": i + i, "list": []any{i}}, &sopt), s)
+	}
+}
+
+func TestProgramErrors(t *testing.T) {
+	_, err := asm.Compile(nil)
+	tt.NotNil(t, err)
+	tt.Panic(t, func() { _ = asm.MustCompile([]any{}) })
+
+	prog := asm.MustCompile([]any{[]any{"set", "$.asm"}})
+	err = prog.Execute(map[string]any{})
+	tt.NotNil(t, err)
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/ohler55/ojg/jp"
+	"github.com/ohler55/ojg/oj"
+)
+
+type envAssign struct {
+	name string
+	x    jp.Expr
+}
+
+var (
+	envs      = []*envAssign{}
+	envFormat = "shell"
+)
+
+type envValue struct {
+}
+
+func (ev envValue) String() string {
+	return ""
+}
+
+func (ev envValue) Set(s string) error {
+	name, path, ok := strings.Cut(s, "=")
+	if !ok {
+		return fmt.Errorf("expected <name>=<path> but got %q", s)
+	}
+	if !validEnvName(name) {
+		return fmt.Errorf("%q is not a valid variable name", name)
+	}
+	x, err := jp.ParseString(path)
+	if err == nil {
+		envs = append(envs, &envAssign{name: name, x: x})
+	}
+	return err
+}
+
+func validEnvName(name string) bool {
+	if len(name) == 0 {
+		return false
+	}
+	for i, b := range []byte(name) {
+		switch {
+		case 'a' <= b && b <= 'z', 'A' <= b && b <= 'Z', b == '_':
+		case '0' <= b && b <= '9':
+			if i == 0 {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// envText returns the text for a value. Strings are not quoted, other
+// scalars are written as JSON, and arrays and objects as compact JSON. A
+// missing value is an empty string.
+func envText(v any, found bool) string {
+	if !found {
+		return ""
+	}
+	switch tv := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return tv
+	}
+	return oj.JSON(v, &oj.Options{Sort: sortKeys})
+}
+
+// writeEnv writes one assignment for each -env option using the first value
+// found at the path.
+func writeEnv(w io.Writer, v any) {
+	for _, ea := range envs {
+		var found bool
+		var val any
+		if r := ea.x.Get(v); 0 < len(r) {
+			val = r[0]
+			found = true
+		}
+		text := envText(val, found)
+		switch envFormat {
+		case "github":
+			if strings.ContainsAny(text, "\r\n") {
+				delim := "EOF"
+				for i := 1; strings.Contains(text, delim); i++ {
+					delim = "EOF" + strconv.Itoa(i)
+				}
+				_, _ = fmt.Fprintf(w, "%s<<%s\n%s\n%s\n", ea.name, delim, text, delim)
+			} else {
+				_, _ = fmt.Fprintf(w, "%s=%s\n", ea.name, text)
+			}
+		default:
+			_, _ = fmt.Fprintf(w, "%s='%s'\n", ea.name, strings.ReplaceAll(text, "'", `'\''`))
+		}
+	}
+}
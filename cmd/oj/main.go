@@ -78,6 +78,11 @@ func init() {
 	flag.Var(&exValue{}, "x", "extract path")
 	flag.Var(&matchValue{}, "m", "match equation/script")
 	flag.Var(&delValue{}, "d", "delete path")
+	flag.Var(&envValue{}, "env", "write a <name>=<path> assignment of the first value at the path")
+	flag.StringVar(&envFormat, "env-format", envFormat, `format of -env assignments. Supported values are:
+  shell - single quoted shell assignments such as NAME='value'
+  github - GitHub Actions output format for appending to $GITHUB_OUTPUT
+`)
 	flag.BoolVar(&dig, "dig", dig, "dig into a large document using the tokenizer")
 	flag.BoolVar(&showVersion, "version", showVersion, "display version and exit")
 	flag.StringVar(&planDef, "a", planDef, "assembly plan or plan file using @<plan>")
@@ -141,6 +146,33 @@ Oj can also be used to assemble new JSON output from input data. An assembly
 plan that describes how to assemble the new JSON if specified by the -a
 option. The -fn option will display the documentation for assembly.
 
+Values can be written as variable assignments for use in shell scripts or
+CI workflows with the -env option which takes a <name>=<path> argument and
+can be repeated. The first value found at each path is written. Strings are
+written without quotes while other values are written as JSON. The
+-env-format option selects single quoted shell assignments (the default) or
+the GitHub Actions output format.
+
+  eval "$(oj -env NAME=$.name -env COUNT=$.count myfile.json)"
+  oj -env-format github -env version=$.version package.json >> "$GITHUB_OUTPUT"
+
+Documents can be layered with the merge subcommand. Use "oj merge -h" for
+details.
+
+  oj merge base.json override.json
+
+Sensitive values can be masked with the redact subcommand and a rules file.
+Use "oj redact -h" for details.
+
+  oj redact -rules rules.sen captured.json
+
+The stats and schema subcommands describe documents. Stats reports counts and
+sizes while schema infers a JSON Schema. Both support JSON, SEN, and text
+output. Use "oj stats -h" or "oj schema -h" for details.
+
+  oj stats feed.json
+  oj schema -format text feed.json
+
 Pretty mode output can be used with JSON or the -sen option. It indents
 according to a defined width and maximum depth in a best effort approach. The
 -p takes a pattern of <width>.<max-depth>.<align> where width and max-depth
@@ -150,6 +182,26 @@ are integers and align is a boolean.
 		flag.PrintDefaults()
 		fmt.Fprintln(os.Stderr)
 	}
+	if 1 < len(os.Args) {
+		var sub func(args []string) error
+		switch os.Args[1] {
+		case "merge":
+			sub = runMerge
+		case "redact":
+			sub = runRedact
+		case "stats":
+			sub = runStats
+		case "schema":
+			sub = runSchema
+		}
+		if sub != nil {
+			if err := sub(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "*-*-* %s\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
 	flag.Parse() // get config file if specified
 	if showVersion {
 		fmt.Printf("oj %s\n", version)
@@ -170,6 +222,7 @@ are integers and align is a boolean.
 	extracts = extracts[:0]
 	matches = matches[:0]
 	dels = dels[:0]
+	envs = envs[:0]
 	if err := run(); err != nil {
 		fmt.Fprintf(os.Stderr, "*-*-* %s\n", err)
 		os.Exit(1)
@@ -213,6 +266,9 @@ func run() (err error) {
 			files = append(files, arg)
 		}
 	}
+	if envFormat != "shell" && envFormat != "github" {
+		return fmt.Errorf("%q is not a supported -env-format", envFormat)
+	}
 	if 0 < len(convName) {
 		switch strings.ToLower(convName) {
 		case "nano":
@@ -338,70 +394,34 @@ func digParse(r io.Reader) error {
 	if color {
 		annotateColor = ojg.Gray
 	}
-	// Pick a function that satisfies omit, annotate, and senOut
-	// values. Determining the function before the actual calling means few
-	// conditional paths during the repeated calls later.
-	if omit {
-		if annotate {
-			if senOut {
-				fn = func(path jp.Expr, data any) {
-					if data != nil && data != "" {
-						fmt.Printf("%s// %s\n", annotateColor, path)
-						writeSEN(data)
-					}
-				}
-			} else {
-				fn = func(path jp.Expr, data any) {
-					if data != nil && data != "" {
-						fmt.Printf("%s// %s\n", annotateColor, path)
-						writeJSON(data)
-					}
-				}
-			}
-		} else {
-			if senOut {
-				fn = func(path jp.Expr, data any) {
-					if data != nil && data != "" {
-						writeSEN(data)
-					}
-				}
-			} else {
-				fn = func(path jp.Expr, data any) {
-					if data != nil && data != "" {
-						writeJSON(data)
-					}
-				}
+	// Pick a function that satisfies omit and annotate values. Determining
+	// the function before the actual calling means few conditional paths
+	// during the repeated calls later.
+	switch {
+	case omit && annotate:
+		fn = func(path jp.Expr, data any) {
+			if data != nil && data != "" {
+				fmt.Printf("%s// %s\n", annotateColor, path)
+				writeOut(data)
 			}
 		}
-	} else {
-		if annotate {
-			if senOut {
-				fn = func(path jp.Expr, data any) {
-					fmt.Printf("%s// %s\n", annotateColor, path)
-					writeSEN(data)
-				}
-			} else {
-				fn = func(path jp.Expr, data any) {
-					fmt.Printf("%s// %s\n", annotateColor, path)
-					writeJSON(data)
-				}
-			}
-		} else {
-			if senOut {
-				fn = func(path jp.Expr, data any) {
-					writeSEN(data)
-				}
-			} else {
-				fn = func(path jp.Expr, data any) {
-					writeJSON(data)
-				}
+	case omit:
+		fn = func(path jp.Expr, data any) {
+			if data != nil && data != "" {
+				writeOut(data)
 			}
 		}
+	case annotate:
+		fn = func(path jp.Expr, data any) {
+			fmt.Printf("%s// %s\n", annotateColor, path)
+			writeOut(data)
+		}
+	default:
+		fn = func(path jp.Expr, data any) {
+			writeOut(data)
+		}
 	}
-	if lazy {
-		return sen.MatchLoad(r, fn, extracts...)
-	}
-	return oj.MatchLoad(r, fn, extracts...)
+	return inputFormat().TokenizeLoad(r, jp.NewMatchHandler(fn, extracts...))
 }
 
 func write(v any) bool {
@@ -424,30 +444,24 @@ func write(v any) bool {
 		_ = x.Del(v)
 	}
 	switch {
+	case 0 < len(envs):
+		writeEnv(os.Stdout, v)
 	case 0 < len(extracts):
 		if wrapExtract {
 			var w []any
 			for _, x := range extracts {
 				w = append(w, x.Get(v)...)
 			}
-			if senOut {
-				writeSEN(w)
-			} else {
-				writeJSON(w)
-			}
+			writeOut(w)
 		} else {
 			for _, x := range extracts {
 				for _, v2 := range x.Get(v) {
-					if senOut {
-						writeSEN(v2)
-					} else {
-						writeJSON(v2)
-					}
+					writeOut(v2)
 				}
 			}
 		}
 	case senOut:
-		writeSEN(v)
+		writeOut(v)
 	default:
 		if plan != nil {
 			root["src"] = v
@@ -458,12 +472,34 @@ func write(v any) bool {
 				v = root["asm"]
 			}
 		}
-		writeJSON(v)
+		writeOut(v)
 	}
 	return false
 }
 
-func writeJSON(v any) {
+// inputFormat returns the registered format used for reading when digging.
+func inputFormat() ojg.Format {
+	if lazy {
+		return ojg.LookupFormat("sen")
+	}
+	return ojg.LookupFormat("json")
+}
+
+// outputFormat returns the registered format selected for output.
+func outputFormat() ojg.Format {
+	if senOut {
+		return ojg.LookupFormat("sen")
+	}
+	return ojg.LookupFormat("json")
+}
+
+func writeOut(v any) {
+	writeFormat(outputFormat(), v)
+}
+
+// writeFormat writes the value to stdout in the format provided using the
+// options selected by the command line flags.
+func writeFormat(f ojg.Format, v any) {
 	if options == nil {
 		o := ojg.Options{}
 		if bright {
@@ -502,47 +538,9 @@ func writeJSON(v any) {
 		parsePrettyOpt()
 	}
 	if prettyOn {
-		_ = pretty.WriteJSON(os.Stdout, v, options, float64(width)+float64(maxDepth)/10.0, align)
-	} else {
-		_ = oj.Write(os.Stdout, v, options)
-	}
-	_, _ = os.Stdout.Write([]byte{'\n'})
-}
-
-func writeSEN(v any) {
-	if options == nil {
-		o := ojg.Options{}
-		switch {
-		case html:
-			o = ojg.HTMLOptions
-			o.Color = true
-			o.HTMLUnsafe = false
-		case bright:
-			o = ojg.BrightOptions
-			o.Color = true
-		case color || sortKeys || tab:
-			o = ojg.DefaultOptions
-			o.Color = color
-		}
-		o.Indent = indent
-		o.Tab = tab
-		o.HTMLUnsafe = !safe
-		o.TimeFormat = time.RFC3339Nano
-		o.Sort = sortKeys
-		options = &o
-	}
-	if omit {
-		// Use alt.Alter to remove empty since it handles recursive removal.
-		v = alt.Alter(v, &ojg.Options{OmitNil: true, OmitEmpty: true})
-	}
-	if 0 < len(prettyOpt) {
-		parsePrettyOpt()
-	}
-	if prettyOn {
-		_ = pretty.WriteSEN(os.Stdout, v, options, float64(width)+float64(maxDepth)/10.0, align)
-	} else {
-		_ = sen.Write(os.Stdout, v, options)
+		f = &pretty.Format{Format: f, Width: width, MaxDepth: maxDepth, Align: align}
 	}
+	_ = f.Write(os.Stdout, v, options)
 	_, _ = os.Stdout.Write([]byte{'\n'})
 }
 
@@ -0,0 +1,81 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ohler55/ojg/alt"
+	"github.com/ohler55/ojg/sen"
+)
+
+// runMerge implements the merge subcommand which layers the documents in
+// the files provided and writes the result.
+func runMerge(args []string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err, _ = r.(error)
+		}
+	}()
+	loadConfig()
+	strategyName := alt.MergeDeep.String()
+	fs := flag.NewFlagSet("merge", flag.ContinueOnError)
+	fs.StringVar(&strategyName, "strategy", strategyName, `how arrays are merged. Supported values are:
+  deep - elements at the same index are merged
+  replace-arrays - arrays in later documents replace earlier arrays
+  append-arrays - elements of arrays in later documents are appended
+`)
+	fs.IntVar(&indent, "i", indent, "indent")
+	fs.BoolVar(&sortKeys, "s", sortKeys, "sort")
+	fs.BoolVar(&color, "c", color, "color")
+	fs.BoolVar(&tab, "t", tab, "indent with tabs")
+	fs.BoolVar(&senOut, "sen", senOut, "output in Simple Encoding Notation")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `
+usage: %s merge [<options>] <file>...
+
+Merge layers JSON or SEN documents in order with each document overriding the
+ones before it. Objects are merged recursively. Arrays are merged according to
+the -strategy option. Other values in later documents replace earlier values.
+If a file contains more than one document each is a separate layer.
+
+  oj merge -strategy append-arrays base.json override.sen
+
+`, filepath.Base(os.Args[0]))
+		fs.PrintDefaults()
+		fmt.Fprintln(os.Stderr)
+	}
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+	var strategy alt.MergeStrategy
+	if strategy, err = alt.ParseMergeStrategy(strategyName); err != nil {
+		return
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("merge requires at least one file")
+	}
+	// The SEN parser accepts JSON as well as SEN.
+	var p sen.Parser
+	var layers []any
+	for _, file := range fs.Args() {
+		var f *os.File
+		if f, err = os.Open(file); err != nil {
+			return
+		}
+		_, err = p.ParseReader(f, func(v any) bool {
+			layers = append(layers, v)
+			return false
+		})
+		_ = f.Close()
+		if err != nil {
+			return
+		}
+	}
+	merged := alt.Merge(strategy, layers...)
+	writeOut(merged)
+	return
+}
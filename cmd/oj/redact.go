@@ -0,0 +1,167 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/ohler55/ojg/alt"
+	"github.com/ohler55/ojg/jp"
+	"github.com/ohler55/ojg/sen"
+)
+
+// redactRules are the rules read from a redact rules file.
+type redactRules struct {
+	redactor alt.Redactor
+	paths    []jp.Expr
+}
+
+// runRedact implements the redact subcommand which masks values in the
+// documents of the files provided, or stdin, according to a rules file.
+func runRedact(args []string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err, _ = r.(error)
+		}
+	}()
+	loadConfig()
+	rulesFile := ""
+	fs := flag.NewFlagSet("redact", flag.ContinueOnError)
+	fs.StringVar(&rulesFile, "rules", rulesFile, "SEN or JSON file with the redaction rules")
+	fs.IntVar(&indent, "i", indent, "indent")
+	fs.BoolVar(&sortKeys, "s", sortKeys, "sort")
+	fs.BoolVar(&color, "c", color, "color")
+	fs.BoolVar(&tab, "t", tab, "indent with tabs")
+	fs.BoolVar(&senOut, "sen", senOut, "output in Simple Encoding Notation")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `
+usage: %s redact -rules <rules-file> [<options>] [<file>]...
+
+Redact replaces sensitive values in JSON or SEN documents with a mask. If no
+files are specified input is read from stdin. The rules file is a SEN or JSON
+object with these members:
+
+  keys  - patterns matched against object keys at any depth ignoring case
+          where '*' matches any sequence of characters
+  paths - JSONPath expressions of the values to redact
+  mask  - the replacement value, the default is "***"
+
+  {keys: [password "*_token"] paths: ["$.users[*].ssn"] mask: "***"}
+
+  oj redact -rules rules.sen captured.json
+
+`, filepath.Base(os.Args[0]))
+		fs.PrintDefaults()
+		fmt.Fprintln(os.Stderr)
+	}
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+	if len(rulesFile) == 0 {
+		return fmt.Errorf("redact requires a -rules file")
+	}
+	var rules *redactRules
+	if rules, err = loadRedactRules(rulesFile); err != nil {
+		return
+	}
+	// The SEN parser accepts JSON as well as SEN.
+	var p sen.Parser
+	cb := func(v any) bool {
+		v = rules.apply(v)
+		writeOut(v)
+		return false
+	}
+	if fs.NArg() == 0 {
+		_, err = p.ParseReader(os.Stdin, cb)
+		return
+	}
+	for _, file := range fs.Args() {
+		var f *os.File
+		if f, err = os.Open(file); err != nil {
+			return
+		}
+		_, err = p.ParseReader(f, cb)
+		_ = f.Close()
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+func loadRedactRules(file string) (*redactRules, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	return readRedactRules(f)
+}
+
+func readRedactRules(r io.Reader) (*redactRules, error) {
+	v, err := (&sen.Parser{}).ParseReader(r)
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("redact rules must be an object")
+	}
+	var rules redactRules
+	for k, v := range obj {
+		switch k {
+		case "keys":
+			if rules.redactor.Keys, err = redactStrings(k, v); err != nil {
+				return nil, err
+			}
+		case "paths":
+			var paths []string
+			if paths, err = redactStrings(k, v); err != nil {
+				return nil, err
+			}
+			for _, path := range paths {
+				var x jp.Expr
+				if x, err = jp.ParseString(path); err != nil {
+					return nil, err
+				}
+				rules.paths = append(rules.paths, x)
+			}
+		case "mask":
+			if rules.redactor.Mask, ok = v.(string); !ok {
+				return nil, fmt.Errorf("redact rules mask must be a string")
+			}
+		default:
+			return nil, fmt.Errorf("%q is not a valid redact rules member", k)
+		}
+	}
+	return &rules, nil
+}
+
+func redactStrings(key string, v any) (strs []string, err error) {
+	list, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("redact rules %s must be an array of strings", key)
+	}
+	for _, s := range list {
+		str, ok := s.(string)
+		if !ok {
+			return nil, fmt.Errorf("redact rules %s must be an array of strings", key)
+		}
+		strs = append(strs, str)
+	}
+	return
+}
+
+func (rules *redactRules) apply(v any) any {
+	v = rules.redactor.Redact(v)
+	mask := rules.redactor.MaskValue()
+	for _, x := range rules.paths {
+		v = x.MustModify(v, func(element any) (any, bool) { return mask, true })
+	}
+	return v
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const schemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// schemaNode collects the types seen at a path along with the members of
+// the objects and the elements of the arrays at that path.
+type schemaNode struct {
+	types   map[string]bool
+	objects int64
+	props   map[string]*schemaNode
+	present map[string]int64 // objects at the path that have the member
+	items   *schemaNode
+}
+
+// runSchema implements the schema subcommand which infers a JSON Schema
+// from the documents in the files provided or stdin.
+func runSchema(args []string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err, _ = r.(error)
+		}
+	}()
+	loadConfig()
+	format := "json"
+	fs := flag.NewFlagSet("schema", flag.ContinueOnError)
+	fs.StringVar(&format, "format", format, "output format of json, sen, or text")
+	fs.IntVar(&indent, "i", indent, "indent")
+	fs.BoolVar(&color, "c", color, "color")
+	fs.BoolVar(&tab, "t", tab, "indent with tabs")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `
+usage: %s schema [<options>] [<file>]...
+
+Schema infers a JSON Schema that all the JSON or SEN documents read conform
+to. If no files are specified input is read from stdin. Object members that
+are present in every object at a path are listed as required. Integers are
+reported as numbers when floats are also seen at the same path. The text
+format lists each path along with the types found there and marks members
+that are not always present as optional.
+
+  oj schema -format text feed.json
+
+`, filepath.Base(os.Args[0]))
+		fs.PrintDefaults()
+		fmt.Fprintln(os.Stderr)
+	}
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+	root := newSchemaNode()
+	if err = readDocs(fs.Args(), root.add); err != nil {
+		return
+	}
+	report := root.schema()
+	report["$schema"] = schemaDialect
+
+	return writeReport(format, report, func() string {
+		var b strings.Builder
+		root.text(&b, "$", false)
+		return b.String()
+	})
+}
+
+func newSchemaNode() *schemaNode {
+	return &schemaNode{types: map[string]bool{}}
+}
+
+func (sn *schemaNode) add(v any) {
+	sn.types[valueKind(v)] = true
+	switch tv := v.(type) {
+	case []any:
+		if sn.items == nil {
+			sn.items = newSchemaNode()
+		}
+		for _, m := range tv {
+			sn.items.add(m)
+		}
+	case map[string]any:
+		if sn.props == nil {
+			sn.props = map[string]*schemaNode{}
+			sn.present = map[string]int64{}
+		}
+		sn.objects++
+		for k, m := range tv {
+			p := sn.props[k]
+			if p == nil {
+				p = newSchemaNode()
+				sn.props[k] = p
+			}
+			sn.present[k]++
+			p.add(m)
+		}
+	}
+}
+
+// typeNames returns the sorted type names with integer folded into number
+// if both were seen.
+func (sn *schemaNode) typeNames() []string {
+	names := make([]string, 0, len(sn.types))
+	for k := range sn.types {
+		if k == "integer" && sn.types["number"] {
+			continue
+		}
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (sn *schemaNode) schema() map[string]any {
+	s := map[string]any{}
+	switch names := sn.typeNames(); len(names) {
+	case 0:
+		// Elements of arrays that were always empty can be anything.
+		return s
+	case 1:
+		s["type"] = names[0]
+	default:
+		list := make([]any, len(names))
+		for i, name := range names {
+			list[i] = name
+		}
+		s["type"] = list
+	}
+	if sn.props != nil {
+		props := map[string]any{}
+		var required []any
+		for _, k := range sn.keys() {
+			props[k] = sn.props[k].schema()
+			if sn.present[k] == sn.objects {
+				required = append(required, k)
+			}
+		}
+		s["properties"] = props
+		if 0 < len(required) {
+			s["required"] = required
+		}
+	}
+	if sn.items != nil {
+		s["items"] = sn.items.schema()
+	}
+	return s
+}
+
+func (sn *schemaNode) keys() []string {
+	keys := make([]string, 0, len(sn.props))
+	for k := range sn.props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (sn *schemaNode) text(b *strings.Builder, path string, optional bool) {
+	names := sn.typeNames()
+	if len(names) == 0 {
+		names = []string{"any"}
+	}
+	fmt.Fprintf(b, "%s %s", path, strings.Join(names, "|"))
+	if optional {
+		b.WriteString(" (optional)")
+	}
+	b.WriteByte('\n')
+	for _, k := range sn.keys() {
+		sn.props[k].text(b, path+childPath(k), sn.present[k] < sn.objects)
+	}
+	if sn.items != nil {
+		sn.items.text(b, path+"[*]", false)
+	}
+}
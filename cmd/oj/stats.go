@@ -0,0 +1,220 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/sen"
+)
+
+// docStats are the statistics gathered from the documents read by the stats
+// subcommand.
+type docStats struct {
+	documents        int64
+	values           int64
+	maxDepth         int64
+	maxArrayLength   int64
+	maxObjectMembers int64
+	maxStringLength  int64
+	types            map[string]int64
+	keys             map[string]bool
+	paths            map[string]bool
+}
+
+// runStats implements the stats subcommand which describes the shape and
+// size of the documents in the files provided or stdin.
+func runStats(args []string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err, _ = r.(error)
+		}
+	}()
+	loadConfig()
+	format := "text"
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	fs.StringVar(&format, "format", format, "output format of json, sen, or text")
+	fs.IntVar(&indent, "i", indent, "indent")
+	fs.BoolVar(&color, "c", color, "color")
+	fs.BoolVar(&tab, "t", tab, "indent with tabs")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, `
+usage: %s stats [<options>] [<file>]...
+
+Stats reads JSON or SEN documents and reports the number of documents and
+values, a count of each type of value, the maximum nesting depth, the largest
+array, object, and string, and the number of unique keys and paths. If no
+files are specified input is read from stdin. All the documents read
+contribute to a single report.
+
+  oj stats -format json feed.json
+
+`, filepath.Base(os.Args[0]))
+		fs.PrintDefaults()
+		fmt.Fprintln(os.Stderr)
+	}
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+	st := docStats{
+		types: map[string]int64{},
+		keys:  map[string]bool{},
+		paths: map[string]bool{},
+	}
+	if err = readDocs(fs.Args(), func(v any) {
+		st.documents++
+		st.add(v, "$", 1)
+	}); err != nil {
+		return
+	}
+	return writeReport(format, st.report(), st.text)
+}
+
+func (st *docStats) add(v any, path string, depth int64) {
+	st.values++
+	st.maxDepth = max(st.maxDepth, depth)
+	st.paths[path] = true
+	kind := valueKind(v)
+	st.types[kind]++
+	switch tv := v.(type) {
+	case string:
+		st.maxStringLength = max(st.maxStringLength, int64(utf8.RuneCountInString(tv)))
+	case []any:
+		st.maxArrayLength = max(st.maxArrayLength, int64(len(tv)))
+		for _, m := range tv {
+			st.add(m, path+"[*]", depth+1)
+		}
+	case map[string]any:
+		st.maxObjectMembers = max(st.maxObjectMembers, int64(len(tv)))
+		for k, m := range tv {
+			st.keys[k] = true
+			st.add(m, path+childPath(k), depth+1)
+		}
+	}
+}
+
+func (st *docStats) report() map[string]any {
+	types := map[string]any{}
+	for k, n := range st.types {
+		types[k] = n
+	}
+	return map[string]any{
+		"documents":        st.documents,
+		"values":           st.values,
+		"types":            types,
+		"maxDepth":         st.maxDepth,
+		"maxArrayLength":   st.maxArrayLength,
+		"maxObjectMembers": st.maxObjectMembers,
+		"maxStringLength":  st.maxStringLength,
+		"uniqueKeys":       int64(len(st.keys)),
+		"uniquePaths":      int64(len(st.paths)),
+	}
+}
+
+func (st *docStats) text() string {
+	var b strings.Builder
+	line := func(label string, n int64) {
+		fmt.Fprintf(&b, "%-20s %d\n", label+":", n)
+	}
+	line("documents", st.documents)
+	line("values", st.values)
+	kinds := make([]string, 0, len(st.types))
+	for k := range st.types {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	for _, k := range kinds {
+		line("  "+k, st.types[k])
+	}
+	line("max depth", st.maxDepth)
+	line("max array length", st.maxArrayLength)
+	line("max object members", st.maxObjectMembers)
+	line("max string length", st.maxStringLength)
+	line("unique keys", int64(len(st.keys)))
+	line("unique paths", int64(len(st.paths)))
+
+	return b.String()
+}
+
+// valueKind returns the JSON Schema type name for a value.
+func valueKind(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return "integer"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return "number"
+}
+
+func childPath(key string) string {
+	if len(key) == 0 {
+		return `[""]`
+	}
+	for i, b := range []byte(key) {
+		if !('a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' || b == '_' || (0 < i && '0' <= b && b <= '9')) {
+			return "[" + strconv.Quote(key) + "]"
+		}
+	}
+	return "." + key
+}
+
+// readDocs reads the JSON or SEN documents in the files or stdin if there
+// are no files and calls cb for each one.
+func readDocs(files []string, cb func(v any)) (err error) {
+	// The SEN parser accepts JSON as well as SEN.
+	var p sen.Parser
+	pcb := func(v any) bool {
+		cb(v)
+		return false
+	}
+	if len(files) == 0 {
+		_, err = p.ParseReader(os.Stdin, pcb)
+		return
+	}
+	for _, file := range files {
+		var f *os.File
+		if f, err = os.Open(file); err != nil {
+			return
+		}
+		_, err = p.ParseReader(f, pcb)
+		_ = f.Close()
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// writeReport writes the report in the format requested. The text function
+// provides the text format.
+func writeReport(format string, report any, text func() string) error {
+	if f := ojg.LookupFormat(strings.ToLower(format)); f != nil {
+		sortKeys = true
+		writeFormat(f, report)
+		return nil
+	}
+	switch strings.ToLower(format) {
+	case "text", "":
+		fmt.Print(text())
+	default:
+		return fmt.Errorf("%q is not a valid format, must be json, sen, or text", format)
+	}
+	return nil
+}
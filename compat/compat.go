@@ -0,0 +1,161 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package compat
+
+import (
+	"io"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/gen"
+	"github.com/ohler55/ojg/jp"
+	"github.com/ohler55/ojg/oj"
+)
+
+// writeOptions are the options for Marshal and MarshalIndent. They are
+// set here instead of using the GoOptions so later changes to the
+// GoOptions do not change the output.
+var writeOptions = ojg.Options{
+	InitSize:       256,
+	UseTags:        true,
+	KeyExact:       true,
+	BytesAs:        ojg.BytesAsBase64,
+	NilCollections: ojg.NilAsNull,
+	WriteLimit:     1024,
+}
+
+// Marshal returns the JSON encoding of the value with the same keys and
+// values as the encoding/json package. Struct fields may be written in a
+// different order.
+func Marshal(v any) ([]byte, error) {
+	opt := writeOptions
+	return oj.Marshal(v, &opt)
+}
+
+// MarshalIndent is the same as Marshal except the JSON is indented with
+// the number of spaces provided.
+func MarshalIndent(v any, indent int) ([]byte, error) {
+	opt := writeOptions
+	opt.Indent = indent
+	return oj.Marshal(v, &opt)
+}
+
+// Unmarshal parses the JSON and stores the result in the value pointed to
+// by vp using the same rules as the encoding/json package.
+func Unmarshal(data []byte, vp any) error {
+	return oj.Unmarshal(data, vp)
+}
+
+// Parse the JSON into a value made up of nil, bool, int64, float64,
+// string, []any, and map[string]any.
+func Parse(data []byte) (any, error) {
+	var p oj.Parser
+	return p.Parse(data)
+}
+
+// ParseReader is the same as Parse except the JSON is read from the
+// reader.
+func ParseReader(r io.Reader) (any, error) {
+	var p oj.Parser
+	return p.ParseReader(r)
+}
+
+// ParseOrdered is the same as Parse except objects are returned as
+// *oj.OMap values that keep the members in the order they were read.
+// Plain converts the result to the types returned by Parse.
+func ParseOrdered(data []byte) (any, error) {
+	p := oj.Parser{OrderedObjects: true}
+	return p.Parse(data)
+}
+
+// Plain returns the value with any *oj.OMap or gen.Node values, at any
+// depth, converted to the map[string]any, []any, and scalar values returned
+// by Parse. Arrays and objects are copied only where needed.
+func Plain(v any) any {
+	pv, _ := plain(v)
+	return pv
+}
+
+// plain returns the converted value and true if it differs from the value
+// provided.
+func plain(v any) (any, bool) {
+	switch tv := v.(type) {
+	case *oj.OMap:
+		return tv.Simplify(), true
+	case gen.Node:
+		return tv.Simplify(), true
+	case []any:
+		var a []any
+		for i, m := range tv {
+			if pm, changed := plain(m); changed {
+				if a == nil {
+					a = make([]any, len(tv))
+					copy(a, tv)
+				}
+				a[i] = pm
+			}
+		}
+		if a != nil {
+			return a, true
+		}
+	case map[string]any:
+		var obj map[string]any
+		for k, m := range tv {
+			if pm, changed := plain(m); changed {
+				if obj == nil {
+					obj = make(map[string]any, len(tv))
+					for k2, m2 := range tv {
+						obj[k2] = m2
+					}
+				}
+				obj[k] = pm
+			}
+		}
+		if obj != nil {
+			return obj, true
+		}
+	}
+	return v, false
+}
+
+// ParseExpr parses a JSONPath expression.
+func ParseExpr(path string) (jp.Expr, error) {
+	return jp.ParseString(path)
+}
+
+// Get returns all the values in the data that match the JSONPath.
+func Get(data any, path string) ([]any, error) {
+	x, err := jp.ParseString(path)
+	if err != nil {
+		return nil, err
+	}
+	return x.Get(data), nil
+}
+
+// First returns the first value in the data that matches the JSONPath or
+// nil if there is no match.
+func First(data any, path string) (any, error) {
+	x, err := jp.ParseString(path)
+	if err != nil {
+		return nil, err
+	}
+	return x.First(data), nil
+}
+
+// Set the values in the data that match the JSONPath. Missing objects and
+// arrays on the path are created.
+func Set(data any, path string, value any) error {
+	x, err := jp.ParseString(path)
+	if err != nil {
+		return err
+	}
+	return x.Set(data, value)
+}
+
+// Del removes the values in the data that match the JSONPath.
+func Del(data any, path string) error {
+	x, err := jp.ParseString(path)
+	if err != nil {
+		return err
+	}
+	return x.Del(data)
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package compat_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ohler55/ojg/compat"
+	"github.com/ohler55/ojg/gen"
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/tt"
+)
+
+type sample struct {
+	Name  string `json:"name"`
+	Count int    `json:"count,omitempty"`
+	Tags  []string
+	Raw   []byte
+}
+
+func TestMarshal(t *testing.T) {
+	v := &sample{Name: "x", Tags: []string{"a"}, Raw: []byte("hi")}
+	out, err := compat.Marshal(v)
+	tt.Nil(t, err)
+	std, _ := json.Marshal(v)
+	tt.Equal(t, oj.MustParse(std), oj.MustParse(out))
+
+	out, err = compat.MarshalIndent(&sample{Name: "x"}, 2)
+	tt.Nil(t, err)
+	tt.Equal(t, `{
+  "Raw": null,
+  "Tags": null,
+  "name": "x"
+}`, string(out))
+
+	_, err = compat.Marshal(make(chan int))
+	tt.NotNil(t, err)
+}
+
+func TestUnmarshal(t *testing.T) {
+	var v sample
+	tt.Nil(t, compat.Unmarshal([]byte(`{"name":"y","count":3,"Tags":["b"],"Raw":"aGk="}`), &v))
+	tt.Equal(t, sample{Name: "y", Count: 3, Tags: []string{"b"}, Raw: []byte("hi")}, v)
+	tt.NotNil(t, compat.Unmarshal([]byte(`{"name":`), &v))
+}
+
+func TestParse(t *testing.T) {
+	v, err := compat.Parse([]byte(`{"a":[1,2.5,"x",true,null]}`))
+	tt.Nil(t, err)
+	tt.Equal(t, map[string]any{"a": []any{int64(1), 2.5, "x", true, nil}}, v)
+
+	v, err = compat.ParseReader(strings.NewReader(`[1]`))
+	tt.Nil(t, err)
+	tt.Equal(t, []any{int64(1)}, v)
+
+	_, err = compat.Parse([]byte(`[1,`))
+	tt.NotNil(t, err)
+}
+
+func TestParseOrdered(t *testing.T) {
+	v, err := compat.ParseOrdered([]byte(`{"b":1,"a":{"d":2,"c":3}}`))
+	tt.Nil(t, err)
+	om, ok := v.(*oj.OMap)
+	tt.Equal(t, true, ok)
+	tt.Equal(t, []string{"b", "a"}, om.Keys())
+	tt.Equal(t, map[string]any{"b": int64(1), "a": map[string]any{"d": int64(2), "c": int64(3)}}, compat.Plain(v))
+}
+
+func TestPlain(t *testing.T) {
+	var om oj.OMap
+	om.Set("x", 1)
+	plain := []any{1, map[string]any{"a": &om, "b": 2}}
+	tt.Equal(t, []any{1, map[string]any{"a": map[string]any{"x": 1}, "b": 2}}, compat.Plain(plain))
+	// The original is not changed.
+	tt.Equal(t, &om, plain[1].(map[string]any)["a"])
+
+	tt.Equal(t, []any{int64(1), "y"}, compat.Plain(gen.Array{gen.Int(1), gen.String("y")}))
+
+	unchanged := []any{1, map[string]any{"a": 2}}
+	tt.Equal(t, unchanged, compat.Plain(unchanged))
+}
+
+func TestExpr(t *testing.T) {
+	data := map[string]any{"a": []any{map[string]any{"b": 1}, map[string]any{"b": 2}}}
+
+	x, err := compat.ParseExpr("a[*].b")
+	tt.Nil(t, err)
+	tt.Equal(t, "a[*].b", x.String())
+
+	result, err := compat.Get(data, "a[*].b")
+	tt.Nil(t, err)
+	tt.Equal(t, []any{1, 2}, result)
+
+	first, err := compat.First(data, "a[1].b")
+	tt.Nil(t, err)
+	tt.Equal(t, 2, first)
+
+	tt.Nil(t, compat.Set(data, "a[0].c", 3))
+	first, _ = compat.First(data, "a[0].c")
+	tt.Equal(t, 3, first)
+
+	tt.Nil(t, compat.Del(data, "a[0].b"))
+	result, _ = compat.Get(data, "a[*].b")
+	tt.Equal(t, []any{2}, result)
+
+	_, err = compat.Get(data, "a[")
+	tt.NotNil(t, err)
+	_, err = compat.First(data, "a[")
+	tt.NotNil(t, err)
+	tt.NotNil(t, compat.Set(data, "a[", 1))
+	tt.NotNil(t, compat.Del(data, "a["))
+	_, err = compat.ParseExpr("a[")
+	tt.NotNil(t, err)
+}
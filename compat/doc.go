@@ -0,0 +1,21 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+/*
+Package compat is a small and stable layer over the most commonly used parts
+of the oj, jp, and alt packages. The functions in this package follow
+semantic versioning strictly. Once released, the signature and behavior of a
+function are not changed other than in a new major version. The packages
+below are free to add options and change defaults, and compat adapts to
+those changes so that code built against it keeps compiling and behaving
+the same.
+
+Newer features are adopted through adapters such as ParseOrdered, which
+returns objects that keep their key order, and Plain, which converts the
+results of the newer subsystems back into the map[string]any and []any
+values older code expects. A large code base can then move one call site
+at a time.
+
+When a function in this package is replaced, the old function is kept as a
+shim marked as deprecated that calls the replacement.
+*/
+package compat
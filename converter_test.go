@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/sen"
 	"github.com/ohler55/ojg/tt"
 )
 
@@ -149,3 +150,46 @@ func TestConverterMongo(t *testing.T) {
 	tt.Equal(t, map[string]any{"$numberDecimal": "123.456", "x": 3}, v2[4])
 	tt.Equal(t, map[string]any{"$numberDecimal": 3}, v2[5])
 }
+
+func TestNewConverterFromRules(t *testing.T) {
+	rules := sen.MustParse([]byte(`[
+  {type: string match: "^\\d{4}-\\d{2}-\\d{2}" to: time}
+  {key: "*_at" type: int to: time unit: ms}
+  {key: "count" type: string to: int}
+  {type: float to: int}
+]`))
+	c, err := ojg.NewConverterFromRules(rules)
+	tt.Nil(t, err)
+	v := c.Convert(map[string]any{
+		"day":        "2024-05-06",
+		"name":       "1999 party",
+		"created_at": int64(1700000000123),
+		"count":      "12",
+		"other":      "12",
+		"list":       []any{2.0, 2.5},
+	})
+	tt.Equal(t, map[string]any{
+		"day":        time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC),
+		"name":       "1999 party",
+		"created_at": time.Date(2023, 11, 14, 22, 13, 20, 123000000, time.UTC),
+		"count":      int64(12),
+		"other":      "12",
+		"list":       []any{int64(2), 2.5},
+	}, v)
+
+	for _, bad := range []string{
+		`5`,
+		`[7]`,
+		`{type: string}`,
+		`{to: int}`,
+		`{type: bool to: int}`,
+		`{type: int to: int match: x}`,
+		`{type: string to: int match: "["}`,
+		`{type: string to: int extra: x}`,
+		`{key: x to: time unit: days}`,
+		`{key: 3 to: int}`,
+	} {
+		_, err = ojg.NewConverterFromRules(sen.MustParse([]byte(bad)))
+		tt.NotNil(t, err, bad)
+	}
+}
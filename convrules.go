@@ -0,0 +1,266 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package ojg
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// NewConverterFromRules builds a Converter from declarative rules so that
+// conversions can be described in configuration such as a SEN or JSON file
+// instead of code. The rules are either a []any of rule objects or a single
+// rule object. Each rule is a map[string]any with these members:
+//
+//	key     - a pattern matched against object keys where '*' matches any
+//	          sequence of characters. If present the rule only applies to
+//	          the values of matching object members.
+//	type    - the type of value matched, one of string, int, float, or
+//	          number (int or float). Required unless key is provided.
+//	match   - a regular expression that a string value must match.
+//	to      - the conversion, one of time, int, float, string, or bool.
+//	layout  - the time layout used to parse strings when converting to a
+//	          time. The default is RFC3339 with optional fractional seconds
+//	          or 2006-01-02.
+//	unit    - the unit of numbers converted to a time, one of s, ms, us, or
+//	          ns. The default is s.
+//
+// A value is left unchanged if it can not be converted. As an example the
+// following rules, in SEN format, convert strings that look like dates and
+// the epoch seconds of any member with a key ending in "_at" to times.
+//
+//	[
+//	  {type: string match: "^\\d{4}-\\d{2}-\\d{2}" to: time}
+//	  {key: "*_at" type: int to: time unit: s}
+//	]
+func NewConverterFromRules(rules any) (*Converter, error) {
+	var list []any
+	switch tr := rules.(type) {
+	case []any:
+		list = tr
+	case map[string]any:
+		list = []any{tr}
+	default:
+		return nil, fmt.Errorf("converter rules must be an array or object, not a %T", rules)
+	}
+	var c Converter
+	for i, r := range list {
+		obj, ok := r.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("converter rule %d must be an object, not a %T", i, r)
+		}
+		cr, err := newConvRule(obj)
+		if err != nil {
+			return nil, fmt.Errorf("converter rule %d: %w", i, err)
+		}
+		cr.addTo(&c)
+	}
+	return &c, nil
+}
+
+type convRule struct {
+	key    string
+	hasKey bool
+	kind   string
+	match  *regexp.Regexp
+	to     string
+	// layout and unit are used for time conversions.
+	layout string
+	unit   time.Duration
+}
+
+func newConvRule(obj map[string]any) (*convRule, error) {
+	cr := convRule{unit: time.Second}
+	for k, v := range obj {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s must be a string, not a %T", k, v)
+		}
+		switch k {
+		case "key":
+			cr.key = s
+			cr.hasKey = true
+		case "type":
+			switch s {
+			case "string", "int", "float", "number":
+				cr.kind = s
+			default:
+				return nil, fmt.Errorf("%q is not a valid type", s)
+			}
+		case "match":
+			var err error
+			if cr.match, err = regexp.Compile(s); err != nil {
+				return nil, err
+			}
+		case "to":
+			switch s {
+			case "time", "int", "float", "string", "bool":
+				cr.to = s
+			default:
+				return nil, fmt.Errorf("%q is not a valid conversion", s)
+			}
+		case "layout":
+			cr.layout = s
+		case "unit":
+			switch s {
+			case "s":
+				cr.unit = time.Second
+			case "ms":
+				cr.unit = time.Millisecond
+			case "us":
+				cr.unit = time.Microsecond
+			case "ns":
+				cr.unit = time.Nanosecond
+			default:
+				return nil, fmt.Errorf("%q is not a valid unit", s)
+			}
+		default:
+			return nil, fmt.Errorf("%q is not a valid rule member", k)
+		}
+	}
+	switch {
+	case len(cr.to) == 0:
+		return nil, fmt.Errorf("a to conversion is required")
+	case len(cr.kind) == 0 && !cr.hasKey:
+		return nil, fmt.Errorf("a type or key is required")
+	case cr.match != nil && len(cr.kind) != 0 && cr.kind != "string":
+		return nil, fmt.Errorf("match only applies to strings")
+	}
+	return &cr, nil
+}
+
+func (cr *convRule) addTo(c *Converter) {
+	if cr.hasKey {
+		c.Map = append(c.Map, func(val map[string]any) (any, bool) {
+			for k, m := range val {
+				if matchPattern(cr.key, k) {
+					if cv, ok := cr.convert(m); ok {
+						val[k] = cv
+					}
+				}
+			}
+			// Not converted as a whole so the members are still visited.
+			return val, false
+		})
+		return
+	}
+	switch cr.kind {
+	case "string":
+		c.String = append(c.String, func(val string) (any, bool) { return cr.convert(val) })
+	case "int":
+		c.Int = append(c.Int, func(val int64) (any, bool) { return cr.convert(val) })
+	case "float":
+		c.Float = append(c.Float, func(val float64) (any, bool) { return cr.convert(val) })
+	case "number":
+		c.Int = append(c.Int, func(val int64) (any, bool) { return cr.convert(val) })
+		c.Float = append(c.Float, func(val float64) (any, bool) { return cr.convert(val) })
+	}
+}
+
+func (cr *convRule) convert(v any) (any, bool) {
+	switch tv := v.(type) {
+	case string:
+		if (len(cr.kind) != 0 && cr.kind != "string") || (cr.match != nil && !cr.match.MatchString(tv)) {
+			break
+		}
+		return cr.fromString(tv)
+	case int64:
+		if cr.kind == "int" || cr.kind == "number" || len(cr.kind) == 0 {
+			return cr.fromInt(tv)
+		}
+	case float64:
+		if cr.kind == "float" || cr.kind == "number" || len(cr.kind) == 0 {
+			return cr.fromFloat(tv)
+		}
+	}
+	return v, false
+}
+
+func (cr *convRule) fromString(s string) (any, bool) {
+	switch cr.to {
+	case "time":
+		layouts := []string{time.RFC3339Nano, "2006-01-02"}
+		if 0 < len(cr.layout) {
+			layouts = []string{cr.layout}
+		}
+		for _, layout := range layouts {
+			if t, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+				return t, true
+			}
+		}
+	case "int":
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i, true
+		}
+	case "float":
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f, true
+		}
+	case "bool":
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b, true
+		}
+	}
+	return s, false
+}
+
+func (cr *convRule) fromInt(i int64) (any, bool) {
+	switch cr.to {
+	case "time":
+		return time.Unix(0, i*int64(cr.unit)).UTC(), true
+	case "float":
+		return float64(i), true
+	case "string":
+		return strconv.FormatInt(i, 10), true
+	case "bool":
+		return i != 0, true
+	}
+	return i, false
+}
+
+func (cr *convRule) fromFloat(f float64) (any, bool) {
+	switch cr.to {
+	case "time":
+		return time.Unix(0, int64(f*float64(cr.unit))).UTC(), true
+	case "int":
+		if f == float64(int64(f)) {
+			return int64(f), true
+		}
+	case "string":
+		return strconv.FormatFloat(f, 'g', -1, 64), true
+	case "bool":
+		return f != 0.0, true
+	}
+	return f, false
+}
+
+// matchPattern returns true if the key matches the pattern where '*'
+// matches any sequence of characters.
+func matchPattern(pattern, key string) bool {
+	var pi, ki int
+	star := -1
+	mark := 0
+	for ki < len(key) {
+		switch {
+		case pi < len(pattern) && pattern[pi] == '*':
+			star = pi
+			mark = ki
+			pi++
+		case pi < len(pattern) && pattern[pi] == key[ki]:
+			pi++
+			ki++
+		case 0 <= star:
+			pi = star + 1
+			mark++
+			ki = mark
+		default:
+			return false
+		}
+	}
+	for pi < len(pattern) && pattern[pi] == '*' {
+		pi++
+	}
+	return pi == len(pattern)
+}
@@ -3,6 +3,7 @@
 package ojg
 
 import (
+	"errors"
 	"fmt"
 	"runtime/debug"
 )
@@ -10,17 +11,153 @@ import (
 // ErrorWithStack if true the Error() call will include the stack.
 var ErrorWithStack = false
 
+var (
+	// ErrSyntax is matched by errors.Is for errors caused by malformed
+	// JSON, SEN, or JSONPath input.
+	ErrSyntax = errors.New("syntax error")
+
+	// ErrUnsupportedType is matched by errors.Is for errors caused by a
+	// value that can not be encoded or recomposed.
+	ErrUnsupportedType = errors.New("unsupported type")
+
+	// ErrDepthExceeded is matched by errors.Is for errors caused by data
+	// nested deeper than a configured limit.
+	ErrDepthExceeded = errors.New("depth exceeded")
+
+	// ErrUnknownField is matched by errors.Is for errors caused by an
+	// object member that does not match a field of the target struct.
+	ErrUnknownField = errors.New("unknown field")
+
+	// ErrOutputLimit is matched by errors.Is for errors caused by output
+	// that exceeds a configured size limit.
+	ErrOutputLimit = errors.New("output limit exceeded")
+
+	// ErrBlankKey is matched by errors.Is for errors caused by an object
+	// key that is empty or only whitespace when the policy does not allow
+	// such keys.
+	ErrBlankKey = errors.New("blank key")
+
+	// ErrUnsafeKey is matched by errors.Is for errors caused by an object
+	// key on the unsafe key list when the policy does not allow such keys.
+	ErrUnsafeKey = errors.New("unsafe key")
+
+	// ErrInputLimit is matched by errors.Is for errors caused by input that
+	// exceeds a configured size limit such as a maximum string length.
+	ErrInputLimit = errors.New("input limit exceeded")
+
+	// ErrCycle is matched by errors.Is for errors caused by a value that
+	// refers back to itself, directly or through other values.
+	ErrCycle = errors.New("reference cycle")
+)
+
+// Parse errors are classified by one of the following in addition to
+// ErrSyntax so callers can use errors.Is to branch on the class of error.
+var (
+	// ErrUnexpectedEOF is matched by errors.Is for errors caused by input
+	// that ends before the document is complete.
+	ErrUnexpectedEOF = errors.New("unexpected end of input")
+
+	// ErrUnexpectedChar is matched by errors.Is for errors caused by a
+	// character that is not valid where it appears such as a missing comma
+	// or an extra close bracket.
+	ErrUnexpectedChar = errors.New("unexpected character")
+
+	// ErrInvalidNumber is matched by errors.Is for errors caused by a
+	// malformed number.
+	ErrInvalidNumber = errors.New("invalid number")
+
+	// ErrInvalidString is matched by errors.Is for errors caused by an
+	// invalid character, escape, or unicode sequence in a string.
+	ErrInvalidString = errors.New("invalid string")
+
+	// ErrInvalidLiteral is matched by errors.Is for errors caused by a
+	// misspelled true, false, or null.
+	ErrInvalidLiteral = errors.New("invalid literal")
+)
+
+// ErrTypeMismatch is the error for a value that is not of the type expected
+// at a location such as a string where a struct field is an int. It is
+// matched with errors.As.
+type ErrTypeMismatch struct {
+	// Path is the location of the value as a JSONPath. It is empty if the
+	// location is not known.
+	Path string
+
+	// Message describes the mismatch.
+	Message string
+}
+
+// Error returns a string representation of the error.
+func (err *ErrTypeMismatch) Error() string {
+	if len(err.Path) == 0 {
+		return err.Message
+	}
+	return fmt.Sprintf("%s at '%s'", err.Message, err.Path)
+}
+
+// ErrUnknownFieldAt is the error for an object member that does not match
+// a field of the struct it is being recomposed into. It is matched by
+// errors.Is against ErrUnknownField and with errors.As for the details.
+type ErrUnknownFieldAt struct {
+	// Path is the location of the member as a JSONPath that ends with the
+	// key of the member.
+	Path string
+
+	// Key is the key of the member.
+	Key string
+
+	// Message describes the error.
+	Message string
+}
+
+// Error returns a string representation of the error.
+func (err *ErrUnknownFieldAt) Error() string {
+	if len(err.Path) == 0 {
+		return err.Message
+	}
+	return fmt.Sprintf("%s at '%s'", err.Message, err.Path)
+}
+
+// Is returns true if the target is ErrUnknownField.
+func (err *ErrUnknownFieldAt) Is(target error) bool {
+	return target == ErrUnknownField
+}
+
+// Errorf formats an error in the same way as fmt.Errorf but the returned
+// error is also matched by errors.Is against the kind, one of the ErrXxx
+// sentinel errors.
+func Errorf(kind error, format string, args ...any) error {
+	return &kindError{kind: kind, err: fmt.Errorf(format, args...)}
+}
+
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (err *kindError) Error() string {
+	return err.err.Error()
+}
+
+func (err *kindError) Unwrap() []error {
+	return []error{err.kind, err.err}
+}
+
 // Error struct to hold an error message and a stack trace.
 type Error struct {
 	msg   string
 	stack []byte
+	err   error
 }
 
 // NewError creates a new Error instance, capturing the stack when created.
+// If r is an error it is wrapped and available with errors.Unwrap().
 func NewError(r any) *Error {
+	err, _ := r.(error)
 	return &Error{
 		msg:   fmt.Sprintf("%v", r),
 		stack: debug.Stack(),
+		err:   err,
 	}
 }
 
@@ -36,3 +173,8 @@ func (err *Error) Error() string {
 func (err *Error) Stack() []byte {
 	return err.stack
 }
+
+// Unwrap returns the error that was recovered, if any.
+func (err *Error) Unwrap() error {
+	return err.err
+}
@@ -3,6 +3,8 @@
 package ojg_test
 
 import (
+	"errors"
+	"fmt"
 	"strings"
 	"testing"
 
@@ -23,3 +25,34 @@ func TestError(t *testing.T) {
 	tt.Equal(t, true, strings.Contains(lines[0], "some error"))
 	tt.Equal(t, true, strings.Contains(lines[len(lines)-2], "testing.go"))
 }
+
+func TestErrorUnwrap(t *testing.T) {
+	cause := fmt.Errorf("cause")
+	err := ojg.NewError(cause)
+	tt.Equal(t, true, errors.Is(err, cause))
+	tt.Nil(t, ojg.NewError("text").Unwrap())
+}
+
+func TestErrorf(t *testing.T) {
+	err := ojg.Errorf(ojg.ErrSyntax, "bad %s", "input")
+	tt.Equal(t, "bad input", err.Error())
+	tt.ErrorIs(t, err, ojg.ErrSyntax)
+	tt.Equal(t, false, errors.Is(err, ojg.ErrUnsupportedType))
+
+	cause := fmt.Errorf("cause")
+	err = ojg.Errorf(ojg.ErrUnsupportedType, "wrapped: %w", cause)
+	tt.ErrorIs(t, err, ojg.ErrUnsupportedType)
+	tt.ErrorIs(t, err, cause)
+	tt.ErrorIs(t, ojg.NewError(err), ojg.ErrUnsupportedType)
+}
+
+func TestErrTypeMismatch(t *testing.T) {
+	err := &ojg.ErrTypeMismatch{Message: "not an int"}
+	tt.Equal(t, "not an int", err.Error())
+	err.Path = "$.a[1]"
+	tt.Equal(t, "not an int at '$.a[1]'", err.Error())
+
+	var tm *ojg.ErrTypeMismatch
+	tt.ErrorAs(t, ojg.NewError(err), &tm)
+	tt.Equal(t, "$.a[1]", tm.Path)
+}
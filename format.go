@@ -0,0 +1,78 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package ojg
+
+import (
+	"io"
+	"sort"
+	"sync"
+)
+
+// Format is an encoding such as JSON or SEN. It provides the parsing,
+// tokenizing, and writing for the encoding so that tools can be written
+// once against the Format and then used with any registered format. The oj
+// package registers the "json" format and the sen package registers the
+// "sen" format. The pretty package wraps a Format to write pretty output.
+type Format interface {
+	// Name returns the name of the format such as "json".
+	Name() string
+
+	// Parse a document.
+	Parse(buf []byte) (any, error)
+
+	// ParseReader parses each document read from r and calls cb with
+	// each one.
+	ParseReader(r io.Reader, cb func(any)) error
+
+	// Tokenize calls the handler for each token in the document.
+	Tokenize(buf []byte, handler TokenHandler) error
+
+	// TokenizeLoad calls the handler for each token in the documents read
+	// from r.
+	TokenizeLoad(r io.Reader, handler TokenHandler) error
+
+	// Write the data to w using the options provided. If opts is nil the
+	// default options of the format are used. Options such as Indent,
+	// Sort, and Color apply as they do for the writers of the format.
+	Write(w io.Writer, data any, opts *Options) error
+
+	// Bytes returns the encoded data using the options provided as with
+	// Write.
+	Bytes(data any, opts *Options) ([]byte, error)
+}
+
+var (
+	formatMu sync.Mutex
+	formats  = map[string]Format{}
+)
+
+// RegisterFormat registers a format by its name replacing any format
+// already registered with the same name.
+func RegisterFormat(f Format) {
+	formatMu.Lock()
+	formats[f.Name()] = f
+	formatMu.Unlock()
+}
+
+// LookupFormat returns the format registered with the name or nil if there
+// is no such format. A format is registered when the package that provides
+// it is imported.
+func LookupFormat(name string) Format {
+	formatMu.Lock()
+	defer formatMu.Unlock()
+
+	return formats[name]
+}
+
+// FormatNames returns the sorted names of the registered formats.
+func FormatNames() []string {
+	formatMu.Lock()
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	formatMu.Unlock()
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package gen
+
+// Annotated wraps a Node along with metadata about the Node such as the
+// source file and line it was read from, where it came from, or whether it
+// has been validated. The metadata is carried along with the Node through
+// Dup and jp.Walk. Alter and Simplify return the wrapped Node converted and
+// drop the metadata. Writers encode only the wrapped Node.
+type Annotated struct {
+	// Node is the wrapped Node.
+	Node Node
+
+	// Meta is the metadata for the Node.
+	Meta map[string]any
+}
+
+// Annotate wraps a Node with metadata. If the Node is already an
+// *Annotated then the metadata is added to the existing metadata.
+func Annotate(n Node, meta map[string]any) *Annotated {
+	if an, ok := n.(*Annotated); ok {
+		if an.Meta == nil {
+			an.Meta = make(map[string]any, len(meta))
+		}
+		for k, v := range meta {
+			an.Meta[k] = v
+		}
+		return an
+	}
+	return &Annotated{Node: n, Meta: meta}
+}
+
+// MetaOf returns the metadata of a Node or nil if the Node is not an
+// *Annotated.
+func MetaOf(n Node) map[string]any {
+	if an, ok := n.(*Annotated); ok {
+		return an.Meta
+	}
+	return nil
+}
+
+// Unannotated returns the Node wrapped by an *Annotated or the Node itself
+// if it is not an *Annotated.
+func Unannotated(n Node) Node {
+	if an, ok := n.(*Annotated); ok {
+		return an.Node
+	}
+	return n
+}
+
+// String returns a string representation of the wrapped Node.
+func (n *Annotated) String() string {
+	if n.Node == nil {
+		return "null"
+	}
+	return n.Node.String()
+}
+
+// Alter converts the wrapped Node into it's native type.
+func (n *Annotated) Alter() any {
+	if n.Node == nil {
+		return nil
+	}
+	return n.Node.Alter()
+}
+
+// Simplify makes a copy of the wrapped Node as simple types.
+func (n *Annotated) Simplify() any {
+	if n.Node == nil {
+		return nil
+	}
+	return n.Node.Simplify()
+}
+
+// Dup returns a deep duplicate of the wrapped Node along with a shallow copy
+// of the metadata.
+func (n *Annotated) Dup() Node {
+	dup := Annotated{}
+	if n.Node != nil {
+		dup.Node = n.Node.Dup()
+	}
+	if n.Meta != nil {
+		dup.Meta = make(map[string]any, len(n.Meta))
+		for k, v := range n.Meta {
+			dup.Meta[k] = v
+		}
+	}
+	return &dup
+}
+
+// Empty returns true if the wrapped Node is nil or empty.
+func (n *Annotated) Empty() bool {
+	return n.Node == nil || n.Node.Empty()
+}
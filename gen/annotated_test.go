@@ -0,0 +1,45 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package gen_test
+
+import (
+	"testing"
+
+	"github.com/ohler55/ojg/gen"
+	"github.com/ohler55/ojg/tt"
+)
+
+func TestAnnotated(t *testing.T) {
+	an := gen.Annotate(gen.Array{gen.Int(1), gen.Annotate(gen.String("x"), map[string]any{"line": 3})},
+		map[string]any{"file": "a.json"})
+	tt.Equal(t, `[1,"x"]`, an.String())
+	tt.Equal(t, []any{int64(1), "x"}, an.Simplify())
+	tt.Equal(t, false, an.Empty())
+	tt.Equal(t, map[string]any{"file": "a.json"}, gen.MetaOf(an))
+	tt.Nil(t, gen.MetaOf(gen.Int(1)))
+	tt.Equal(t, gen.Int(1), gen.Unannotated(gen.Int(1)))
+
+	dup := an.Dup().(*gen.Annotated)
+	tt.Equal(t, "a.json", dup.Meta["file"])
+	dup.Meta["file"] = "b.json"
+	tt.Equal(t, "a.json", an.Meta["file"])
+	inner := dup.Node.(gen.Array)[1].(*gen.Annotated)
+	tt.Equal(t, 3, inner.Meta["line"])
+	tt.Equal(t, true, inner != an.Node.(gen.Array)[1])
+
+	// Annotating an annotated node merges the metadata.
+	tt.Equal(t, true, gen.Annotate(an, map[string]any{"valid": true}) == an)
+	tt.Equal(t, map[string]any{"file": "a.json", "valid": true}, an.Meta)
+	tt.Equal(t, gen.Array{gen.Int(1), inner}, gen.Unannotated(dup))
+
+	tt.Equal(t, []any{int64(1), "x"}, an.Alter())
+
+	empty := &gen.Annotated{}
+	tt.Equal(t, "null", empty.String())
+	tt.Nil(t, empty.Simplify())
+	tt.Nil(t, empty.Alter())
+	tt.Equal(t, true, empty.Empty())
+	tt.Nil(t, empty.Dup().(*gen.Annotated).Node)
+	tt.Nil(t, gen.Annotate(gen.Int(2), nil).Meta)
+	tt.Equal(t, map[string]any{"a": 1}, gen.Annotate(&gen.Annotated{Node: gen.Int(2)}, map[string]any{"a": 1}).Meta)
+}
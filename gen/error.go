@@ -2,7 +2,11 @@
 
 package gen
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/ohler55/ojg"
+)
 
 // ParseError represents a parse error.
 type ParseError struct {
@@ -15,3 +19,8 @@ type ParseError struct {
 func (err *ParseError) Error() string {
 	return fmt.Sprintf("%s at %d:%d", err.Message, err.Line, err.Column)
 }
+
+// Is returns true if the target is ojg.ErrSyntax.
+func (err *ParseError) Is(target error) bool {
+	return target == ojg.ErrSyntax
+}
@@ -8,6 +8,8 @@ import (
 	"fmt"
 	"io"
 	"unicode/utf8"
+
+	"github.com/ohler55/ojg"
 )
 
 const (
@@ -87,7 +89,7 @@ func (p *Parser) Parse(buf []byte, args ...any) (Node, error) {
 		if buf[1] == 0xBB && buf[2] == 0xBF {
 			err = p.parseBuffer(buf[3:], true)
 		} else {
-			return nil, fmt.Errorf("expected BOM at 1:3")
+			return nil, ojg.Errorf(ojg.ErrSyntax, "expected BOM at 1:3")
 		}
 	} else {
 		err = p.parseBuffer(buf, true)
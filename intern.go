@@ -0,0 +1,89 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package ojg
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultStringCacheMax is the maximum number of strings held by a
+// StringCache with a Max of zero.
+const DefaultStringCacheMax = 10000
+
+// stringShardCnt is the number of shards in a StringCache. Must be a power
+// of 2.
+const stringShardCnt = 32
+
+// DefaultStringCache is the StringCache used by the parsers when interning
+// is enabled and no other cache is provided.
+var DefaultStringCache StringCache
+
+// StringCache interns strings so that equal strings share the same memory
+// and are only allocated once. Once the cache is full new strings are
+// returned without being added so that a stream of unique strings can not
+// grow the cache without bound. A StringCache is safe for concurrent use.
+// Strings are spread across shards, each with its own lock, so concurrent
+// parsers rarely contend.
+type StringCache struct {
+	// Max is the maximum number of strings held. If zero the
+	// DefaultStringCacheMax is used.
+	Max int
+
+	cnt    atomic.Int64
+	shards [stringShardCnt]stringShard
+}
+
+type stringShard struct {
+	mu   sync.Mutex
+	strs map[string]string
+}
+
+// Intern returns a string equal to the bytes, reusing a previously interned
+// string if there is one.
+func (sc *StringCache) Intern(b []byte) string {
+	// FNV-1a hash to pick the shard.
+	h := uint32(2166136261)
+	for _, c := range b {
+		h ^= uint32(c)
+		h *= 16777619
+	}
+	shard := &sc.shards[h&(stringShardCnt-1)]
+	shard.mu.Lock()
+	if s, has := shard.strs[string(b)]; has {
+		shard.mu.Unlock()
+		return s
+	}
+	s := string(b)
+	max := sc.Max
+	if max <= 0 {
+		max = DefaultStringCacheMax
+	}
+	if sc.cnt.Add(1) <= int64(max) {
+		if shard.strs == nil {
+			shard.strs = map[string]string{}
+		}
+		shard.strs[s] = s
+	} else {
+		sc.cnt.Add(-1)
+	}
+	shard.mu.Unlock()
+
+	return s
+}
+
+// Len returns the number of strings in the cache.
+func (sc *StringCache) Len() int {
+	return int(sc.cnt.Load())
+}
+
+// Clear removes all the strings from the cache.
+func (sc *StringCache) Clear() {
+	for i := range sc.shards {
+		shard := &sc.shards[i]
+		shard.mu.Lock()
+		sc.cnt.Add(-int64(len(shard.strs)))
+		clear(shard.strs)
+		shard.mu.Unlock()
+	}
+}
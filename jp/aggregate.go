@@ -0,0 +1,75 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package jp
+
+import (
+	"math"
+
+	"github.com/ohler55/ojg/alt"
+)
+
+// Sum returns the sum of the numeric values that match the expression.
+// Values are converted with alt.Float() so numeric strings are included
+// while values that can not be converted, such as arrays, objects, and
+// booleans, are skipped.
+func Sum(x Expr, data any) (sum float64) {
+	for _, f := range numbers(x, data) {
+		sum += f
+	}
+	return
+}
+
+// Avg returns the average of the numeric values that match the expression
+// and true or zero and false if there are no numeric matches. Values are
+// converted as with Sum().
+func Avg(x Expr, data any) (float64, bool) {
+	nums := numbers(x, data)
+	if len(nums) == 0 {
+		return 0, false
+	}
+	var sum float64
+	for _, f := range nums {
+		sum += f
+	}
+	return sum / float64(len(nums)), true
+}
+
+// Min returns the smallest of the numeric values that match the expression
+// and true or zero and false if there are no numeric matches. Values are
+// converted as with Sum().
+func Min(x Expr, data any) (float64, bool) {
+	nums := numbers(x, data)
+	if len(nums) == 0 {
+		return 0, false
+	}
+	low := nums[0]
+	for _, f := range nums[1:] {
+		low = math.Min(low, f)
+	}
+	return low, true
+}
+
+// Max returns the largest of the numeric values that match the expression
+// and true or zero and false if there are no numeric matches. Values are
+// converted as with Sum().
+func Max(x Expr, data any) (float64, bool) {
+	nums := numbers(x, data)
+	if len(nums) == 0 {
+		return 0, false
+	}
+	high := nums[0]
+	for _, f := range nums[1:] {
+		high = math.Max(high, f)
+	}
+	return high, true
+}
+
+func numbers(x Expr, data any) (nums []float64) {
+	nan := math.NaN()
+	for _, v := range x.Get(data) {
+		if f := alt.Float(v, nan); !math.IsNaN(f) {
+			nums = append(nums, f)
+		}
+	}
+	return
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package jp_test
+
+import (
+	"testing"
+
+	"github.com/ohler55/ojg/jp"
+	"github.com/ohler55/ojg/tt"
+)
+
+func TestAggregate(t *testing.T) {
+	data := map[string]any{
+		"items": []any{
+			map[string]any{"price": int64(4)},
+			map[string]any{"price": 1.5},
+			map[string]any{"price": "2.5"},
+			map[string]any{"price": true},
+			map[string]any{"price": []any{9}},
+			map[string]any{},
+		},
+	}
+	x := jp.MustParseString("$.items[*].price")
+	tt.Equal(t, 8.0, jp.Sum(x, data))
+
+	avg, ok := jp.Avg(x, data)
+	tt.Equal(t, true, ok)
+	tt.Equal(t, 8.0/3.0, avg)
+
+	low, ok := jp.Min(x, data)
+	tt.Equal(t, true, ok)
+	tt.Equal(t, 1.5, low)
+
+	high, ok := jp.Max(x, data)
+	tt.Equal(t, true, ok)
+	tt.Equal(t, 4.0, high)
+
+	none := jp.MustParseString("$.items[*].none")
+	tt.Equal(t, 0.0, jp.Sum(none, data))
+	_, ok = jp.Avg(none, data)
+	tt.Equal(t, false, ok)
+	_, ok = jp.Min(none, data)
+	tt.Equal(t, false, ok)
+	_, ok = jp.Max(none, data)
+	tt.Equal(t, false, ok)
+}
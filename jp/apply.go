@@ -0,0 +1,293 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package jp
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/alt"
+	"github.com/ohler55/ojg/gen"
+)
+
+const (
+	applySet    = 's'
+	applyDel    = 'd'
+	applyInsert = 'i'
+)
+
+// Op is an operation applied by Apply. Ops are created with SetOp, DelOp,
+// and InsertOp.
+type Op struct {
+	kind  byte
+	path  Expr
+	value any
+}
+
+// SetOp returns an Op that sets the value at every location that matches
+// the path as with Set.
+func SetOp(path Expr, value any) Op {
+	return Op{kind: applySet, path: path, value: value}
+}
+
+// DelOp returns an Op that removes every element that matches the path as
+// with Remove.
+func DelOp(path Expr) Op {
+	return Op{kind: applyDel, path: path}
+}
+
+// InsertOp returns an Op that inserts the value into each array that
+// matches the path without the last fragment. The last fragment must be an
+// index and the value is inserted before the element at that index. An
+// index equal to the length of an array appends the value.
+func InsertOp(path Expr, value any) Op {
+	return Op{kind: applyInsert, path: path, value: value}
+}
+
+// Path returns the path of the operation.
+func (op Op) Path() Expr {
+	return op.path
+}
+
+// String returns a description of the operation.
+func (op Op) String() string {
+	switch op.kind {
+	case applySet:
+		return fmt.Sprintf("set %s", op.path)
+	case applyDel:
+		return fmt.Sprintf("del %s", op.path)
+	default:
+		return fmt.Sprintf("insert %s", op.path)
+	}
+}
+
+// applyStep is an operation at a single location in the data.
+type applyStep struct {
+	kind  byte
+	loc   Expr // for an insert the index in the array inserted into
+	value any
+	op    int
+}
+
+// Apply applies the operations to the data as a single change. The paths of
+// all the operations are evaluated against the data before any changes are
+// made so an operation does not see the changes made by other operations
+// and array indexes always refer to the original positions. Sets are
+// applied first followed by deletes and inserts. Either all of the
+// operations are applied or, if any fails, none are and an
+// *ErrPartialWrite is returned that identifies the failed operation. The
+// operations are first applied to a shadow copy of the data as with
+// SetManyAtomic so the same limits on the copy apply. The possibly replaced
+// data is returned.
+func Apply(data any, ops ...Op) (result any, err error) {
+	if _, err = applyOps(shadowCopy(data), ops); err != nil {
+		return data, err
+	}
+	return applyOps(data, ops)
+}
+
+func applyOps(data any, ops []Op) (result any, err error) {
+	result = data
+	var current int
+	defer func() {
+		if r := recover(); r != nil {
+			result = data
+			err = &ErrPartialWrite{Path: ops[current].path, Err: ojg.NewError(r)}
+		}
+	}()
+	var (
+		sets  []applyStep
+		moves []applyStep
+	)
+	for current = range ops {
+		op := ops[current]
+		if len(op.path) == 0 {
+			panic(fmt.Errorf("can not %s with an empty expression", op))
+		}
+		switch op.kind {
+		case applySet:
+			locs := op.path.Locate(data, 0)
+			if len(locs) == 0 && op.path.definite() {
+				locs = []Expr{op.path}
+			}
+			for _, loc := range locs {
+				sets = append(sets, applyStep{kind: applySet, loc: loc, value: op.value, op: current})
+			}
+		case applyDel:
+			for _, loc := range op.path.Locate(data, 0) {
+				moves = append(moves, applyStep{kind: applyDel, loc: loc, op: current})
+			}
+		case applyInsert:
+			moves = append(moves, insertSteps(data, op, current)...)
+		}
+	}
+	for _, s := range sets {
+		current = s.op
+		if isRootLoc(s.loc) {
+			result = s.value
+			continue
+		}
+		if err := s.loc.Set(result, s.value); err != nil {
+			panic(err)
+		}
+	}
+	// Apply deletes and inserts from the last location to the first so that
+	// the locations of those not yet applied are not changed. Where a delete
+	// and inserts share a location the delete is first and the inserts are
+	// in reverse order so the inserted values end up in order.
+	sort.SliceStable(moves, func(i, j int) bool {
+		if c := compareLoc(moves[i].loc, moves[j].loc); c != 0 {
+			return 0 < c
+		}
+		if moves[i].kind != moves[j].kind {
+			return moves[i].kind == applyDel
+		}
+		return moves[j].op < moves[i].op
+	})
+	var prev Expr
+	for _, s := range moves {
+		current = s.op
+		switch s.kind {
+		case applyDel:
+			if prev != nil && compareLoc(prev, s.loc) == 0 {
+				continue // already removed by a different op or path
+			}
+			prev = s.loc
+			if isRootLoc(s.loc) {
+				result = nil
+				continue
+			}
+			result = s.loc.MustRemove(result)
+		case applyInsert:
+			px := s.loc[:len(s.loc)-1]
+			index := int(s.loc[len(s.loc)-1].(Nth))
+			value := s.value
+			result = px.modify(result, func(element any) (any, bool) {
+				return insertValue(element, index, value), true
+			}, true)
+		}
+	}
+	return
+}
+
+// insertSteps returns the steps for an insert where the location of each
+// step is the array the value is inserted into followed by the index to
+// insert at.
+func insertSteps(data any, op Op, current int) (steps []applyStep) {
+	nth, ok := op.path[len(op.path)-1].(Nth)
+	if !ok {
+		panic(fmt.Errorf("can not %s where the last fragment is not an index", op))
+	}
+	px := op.path[:len(op.path)-1]
+	var locs []Expr
+	if len(px) == 0 || isRootLoc(px) {
+		locs = []Expr{{Root(0)}}
+	} else {
+		locs = px.Locate(data, 0)
+	}
+	for _, loc := range locs {
+		var target any
+		if isRootLoc(loc) {
+			target = data
+		} else {
+			target = loc.First(data)
+		}
+		size := -1
+		switch tv := target.(type) {
+		case []any:
+			size = len(tv)
+		case gen.Array:
+			size = len(tv)
+		default:
+			if rv := reflect.ValueOf(target); rv.Kind() == reflect.Slice {
+				size = rv.Len()
+			}
+		}
+		if size < 0 {
+			panic(fmt.Errorf("can not insert into a %T", target))
+		}
+		i := int(nth)
+		if i < 0 {
+			i += size
+		}
+		if i < 0 || size < i {
+			panic(fmt.Errorf("insert index %d out of range for an array of length %d", int(nth), size))
+		}
+		loc = append(append(Expr{}, loc...), Nth(i))
+		steps = append(steps, applyStep{kind: applyInsert, loc: loc, value: op.value, op: current})
+	}
+	return
+}
+
+func insertValue(target any, i int, value any) any {
+	switch tv := target.(type) {
+	case []any:
+		na := make([]any, 0, len(tv)+1)
+		na = append(na, tv[:i]...)
+		na = append(na, value)
+		return append(na, tv[i:]...)
+	case gen.Array:
+		n, ok := value.(gen.Node)
+		if !ok && value != nil {
+			if n = alt.Generify(value); n == nil {
+				panic(fmt.Errorf("can not insert a %T into a %T", value, target))
+			}
+		}
+		na := make(gen.Array, 0, len(tv)+1)
+		na = append(na, tv[:i]...)
+		na = append(na, n)
+		return append(na, tv[i:]...)
+	}
+	rv := reflect.ValueOf(target)
+	vv := reflect.ValueOf(value)
+	if !vv.IsValid() {
+		vv = reflect.Zero(rv.Type().Elem())
+	}
+	if !vv.Type().AssignableTo(rv.Type().Elem()) {
+		panic(fmt.Errorf("can not insert a %T into a %T", value, target))
+	}
+	na := reflect.MakeSlice(rv.Type(), 0, rv.Len()+1)
+	na = reflect.AppendSlice(na, rv.Slice(0, i))
+	na = reflect.Append(na, vv)
+	na = reflect.AppendSlice(na, rv.Slice(i, rv.Len()))
+
+	return na.Interface()
+}
+
+// isRootLoc returns true if the location is the data itself.
+func isRootLoc(loc Expr) bool {
+	if len(loc) != 1 {
+		return false
+	}
+	switch loc[0].(type) {
+	case Root, At:
+		return true
+	}
+	return false
+}
+
+// compareLoc compares two normalized locations in document order where a
+// location comes before the locations below it.
+func compareLoc(a, b Expr) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		switch fa := a[i].(type) {
+		case Child:
+			if fb, ok := b[i].(Child); ok && fa != fb {
+				if fa < fb {
+					return -1
+				}
+				return 1
+			}
+		case Nth:
+			if fb, ok := b[i].(Nth); ok && fa != fb {
+				if fa < fb {
+					return -1
+				}
+				return 1
+			}
+		}
+	}
+	return len(a) - len(b)
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package jp_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ohler55/ojg/gen"
+	"github.com/ohler55/ojg/jp"
+	"github.com/ohler55/ojg/sen"
+	"github.com/ohler55/ojg/tt"
+)
+
+func TestApply(t *testing.T) {
+	data := map[string]any{"a": 1, "list": []any{0, 1, 2, 3}}
+	result, err := jp.Apply(data,
+		jp.DelOp(jp.C("list").N(1)),
+		jp.SetOp(jp.C("list").N(2), 20),
+		jp.InsertOp(jp.C("list").N(1), "x"),
+		jp.InsertOp(jp.C("list").N(1), "y"),
+		jp.InsertOp(jp.C("list").N(4), "end"),
+		jp.DelOp(jp.C("a")),
+		jp.SetOp(jp.C("b").C("c"), true),
+	)
+	tt.Nil(t, err)
+	tt.Equal(t, "{b:{c:true} list:[0 x y 20 3 end]}", sen.String(result, &sen.Options{Sort: true}))
+
+	// Operations do not see the changes of other operations.
+	data = map[string]any{"a": 1, "b": 2}
+	_, err = jp.Apply(data, jp.SetOp(jp.C("b"), 3), jp.DelOp(jp.MustParseString("$[?(@ == 3)]")))
+	tt.Nil(t, err)
+	tt.Equal(t, "{a:1 b:3}", sen.String(data, &sen.Options{Sort: true}))
+
+	list := []any{1, 2, 3}
+	result, err = jp.Apply(list, jp.InsertOp(jp.R().N(-1), 0), jp.DelOp(jp.MustParseString("$[0,0]")))
+	tt.Nil(t, err)
+	tt.Equal(t, []any{2, 0, 3}, result)
+	tt.Equal(t, []any{1, 2, 3}, list)
+
+	result, err = jp.Apply(gen.Array{gen.Int(1)}, jp.InsertOp(jp.N(0), 0))
+	tt.Nil(t, err)
+	tt.Equal(t, gen.Array{gen.Int(0), gen.Int(1)}, result)
+
+	result, err = jp.Apply([]int{1, 2}, jp.InsertOp(jp.N(2), 3))
+	tt.Nil(t, err)
+	tt.Equal(t, []int{1, 2, 3}, result)
+
+	result, err = jp.Apply(list, jp.SetOp(jp.R(), "replaced"))
+	tt.Nil(t, err)
+	tt.Equal(t, "replaced", result)
+	tt.Equal(t, "set $", jp.SetOp(jp.R(), 1).String())
+	tt.Equal(t, "$", jp.DelOp(jp.R()).Path().String())
+}
+
+func TestApplyRollback(t *testing.T) {
+	data := map[string]any{"a": 1, "list": []any{1, 2}}
+	for _, ops := range [][]jp.Op{
+		{jp.SetOp(jp.C("a"), 2), jp.SetOp(jp.C("a").C("b"), 3)},
+		{jp.DelOp(jp.C("list").N(0)), jp.InsertOp(jp.C("list").N(5), 3)},
+		{jp.DelOp(jp.C("list").N(0)), jp.InsertOp(jp.C("a").N(0), 3)},
+		{jp.SetOp(jp.C("a"), 2), jp.InsertOp(jp.C("list").C("x"), 3)},
+		{jp.SetOp(jp.C("a"), 2), jp.DelOp(jp.Expr{})},
+	} {
+		result, err := jp.Apply(data, ops...)
+		var pe *jp.ErrPartialWrite
+		tt.Equal(t, true, errors.As(err, &pe), ops)
+		tt.Equal(t, ops[1].Path(), pe.Path)
+		tt.Equal(t, "{a:1 list:[1 2]}", sen.String(result, &sen.Options{Sort: true}))
+		tt.Equal(t, "{a:1 list:[1 2]}", sen.String(data, &sen.Options{Sort: true}))
+	}
+	_, err := jp.Apply([]int{1}, jp.InsertOp(jp.N(0), "x"))
+	tt.NotNil(t, err)
+}
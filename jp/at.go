@@ -12,9 +12,9 @@ func (f At) Append(buf []byte, bracket, first bool) []byte {
 	return buf
 }
 
-func (f At) locate(pp Expr, data any, rest Expr, max int) (locs []Expr) {
+func (f At) locate(pp Expr, data, root any, rest Expr, max int) (locs []Expr) {
 	if 0 < len(rest) {
-		locs = rest[0].locate(append(pp, f), data, rest[1:], max)
+		locs = rest[0].locate(append(pp, f), data, root, rest[1:], max)
 	}
 	return
 }
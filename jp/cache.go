@@ -0,0 +1,133 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package jp
+
+import (
+	"container/list"
+	"sync"
+)
+
+// DefaultCacheSize is the initial maximum number of expressions kept by
+// Cached.
+const DefaultCacheSize = 1024
+
+// CacheStats are the counters for the expression cache used by Cached.
+type CacheStats struct {
+	// Hits is the number of calls to Cached that found the expression in
+	// the cache.
+	Hits uint64
+
+	// Misses is the number of calls to Cached that parsed the expression.
+	Misses uint64
+
+	// Evictions is the number of expressions removed to make room for
+	// others.
+	Evictions uint64
+
+	// Len is the number of expressions in the cache.
+	Len int
+
+	// Size is the maximum number of expressions in the cache.
+	Size int
+}
+
+type cacheEntry struct {
+	path string
+	x    Expr
+}
+
+var exprCache = struct {
+	sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	order   list.List // most recently used first
+	stats   CacheStats
+}{size: DefaultCacheSize, entries: map[string]*list.Element{}}
+
+// Cached returns the Expr for a path string from a least recently used
+// cache or, if not in the cache, parses the path and adds the Expr to the
+// cache. Paths that fail to parse are not cached. The returned Expr is
+// shared with other callers so it must not be modified but it can be
+// evaluated concurrently and fragments can be appended since the Expr is
+// returned without extra capacity. Cached is safe for concurrent use.
+func Cached(path string) (Expr, error) {
+	exprCache.Lock()
+	if e, has := exprCache.entries[path]; has {
+		exprCache.order.MoveToFront(e)
+		exprCache.stats.Hits++
+		x := e.Value.(*cacheEntry).x
+		exprCache.Unlock()
+		return x, nil
+	}
+	exprCache.stats.Misses++
+	exprCache.Unlock()
+
+	x, err := ParseString(path)
+	if err != nil {
+		return nil, err
+	}
+	x = x[:len(x):len(x)]
+
+	exprCache.Lock()
+	defer exprCache.Unlock()
+	if e, has := exprCache.entries[path]; has { // added by another caller
+		exprCache.order.MoveToFront(e)
+		return e.Value.(*cacheEntry).x, nil
+	}
+	if 0 < exprCache.size {
+		exprCache.entries[path] = exprCache.order.PushFront(&cacheEntry{path: path, x: x})
+		trimCache()
+	}
+	return x, nil
+}
+
+// MustCached is the same as Cached except it panics on a parse error.
+func MustCached(path string) Expr {
+	x, err := Cached(path)
+	if err != nil {
+		panic(err)
+	}
+	return x
+}
+
+// SetCacheSize sets the maximum number of expressions kept by Cached and
+// removes the least recently used expressions if there are more than the
+// new size. A size of zero or less turns off caching.
+func SetCacheSize(size int) {
+	exprCache.Lock()
+	defer exprCache.Unlock()
+	exprCache.size = max(size, 0)
+	trimCache()
+}
+
+// ClearCache removes all the expressions from the cache used by Cached and
+// resets the counters.
+func ClearCache() {
+	exprCache.Lock()
+	defer exprCache.Unlock()
+	clear(exprCache.entries)
+	exprCache.order.Init()
+	exprCache.stats = CacheStats{}
+}
+
+// GetCacheStats returns the counters for the cache used by Cached.
+func GetCacheStats() CacheStats {
+	exprCache.Lock()
+	defer exprCache.Unlock()
+	stats := exprCache.stats
+	stats.Len = exprCache.order.Len()
+	stats.Size = exprCache.size
+
+	return stats
+}
+
+// trimCache removes the least recently used entries until the cache is no
+// larger than the size. The cache must be locked.
+func trimCache() {
+	for exprCache.size < exprCache.order.Len() {
+		e := exprCache.order.Back()
+		exprCache.order.Remove(e)
+		delete(exprCache.entries, e.Value.(*cacheEntry).path)
+		exprCache.stats.Evictions++
+	}
+}
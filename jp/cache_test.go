@@ -0,0 +1,72 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package jp_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/ohler55/ojg/jp"
+	"github.com/ohler55/ojg/tt"
+)
+
+func TestCached(t *testing.T) {
+	jp.ClearCache()
+	defer jp.SetCacheSize(jp.DefaultCacheSize)
+
+	x, err := jp.Cached("$.a[1].b")
+	tt.Nil(t, err)
+	tt.Equal(t, "$.a[1].b", x.String())
+	x2 := jp.MustCached("$.a[1].b")
+	tt.Equal(t, true, &x[0] == &x2[0], "same expression")
+
+	// Appending does not change the cached expression.
+	_ = x.C("c")
+	tt.Equal(t, "$.a[1].b", jp.MustCached("$.a[1].b").String())
+
+	_, err = jp.Cached("$.a[")
+	tt.NotNil(t, err)
+	tt.Panic(t, func() { jp.MustCached("$.a[") })
+
+	stats := jp.GetCacheStats()
+	tt.Equal(t, jp.CacheStats{Hits: 2, Misses: 3, Len: 1, Size: jp.DefaultCacheSize}, stats)
+
+	jp.SetCacheSize(2)
+	_ = jp.MustCached("$.x")
+	_ = jp.MustCached("$.a[1].b") // most recent so $.x is evicted next
+	_ = jp.MustCached("$.y")
+	stats = jp.GetCacheStats()
+	tt.Equal(t, 1, int(stats.Evictions))
+	tt.Equal(t, 2, stats.Len)
+	_ = jp.MustCached("$.a[1].b")
+	tt.Equal(t, stats.Hits+1, jp.GetCacheStats().Hits)
+
+	jp.SetCacheSize(0)
+	tt.Equal(t, 0, jp.GetCacheStats().Len)
+	_ = jp.MustCached("$.z")
+	tt.Equal(t, 0, jp.GetCacheStats().Len)
+}
+
+func TestCachedConcurrent(t *testing.T) {
+	jp.ClearCache()
+	defer jp.SetCacheSize(jp.DefaultCacheSize)
+	jp.SetCacheSize(8)
+
+	data := map[string]any{"a": []any{1, 2, 3}}
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				x := jp.MustCached(fmt.Sprintf("$.a[%d]", (g+i)%12))
+				_ = x.Get(data)
+			}
+		}(g)
+	}
+	wg.Wait()
+	stats := jp.GetCacheStats()
+	tt.Equal(t, 800, int(stats.Hits+stats.Misses))
+	tt.Equal(t, 8, stats.Len)
+}
@@ -4,6 +4,7 @@ package jp
 
 import (
 	"reflect"
+	"sort"
 
 	"github.com/ohler55/ojg/gen"
 )
@@ -23,19 +24,28 @@ func (f Descent) Append(buf []byte, bracket, first bool) []byte {
 	return buf
 }
 
-func (f Descent) locate(pp Expr, data any, rest Expr, max int) (locs []Expr) {
+func (f Descent) locate(pp Expr, data, root any, rest Expr, max int) (locs []Expr) {
 	if len(rest) == 0 { // last one
 		loc := make(Expr, len(pp))
 		copy(loc, pp)
 		locs = append(locs, loc)
 	} else {
-		locs = locateContinueFrag(locs, pp, data, rest, max)
+		locs = locateContinueFrag(locs, pp, data, root, rest, max)
 	}
 	cp := append(pp, nil) // place holder
 	mx := max
 	switch td := data.(type) {
 	case map[string]any:
-		for k, v := range td {
+		keys := make([]string, 0, len(td))
+		for k := range td {
+			keys = append(keys, k)
+		}
+		// Only a limited locate needs the first matches in document order.
+		if 0 < max {
+			sort.Strings(keys)
+		}
+		for _, k := range keys {
+			v := td[k]
 			cp[len(pp)] = Child(k)
 			if 0 < max {
 				mx = max - len(locs)
@@ -43,7 +53,7 @@ func (f Descent) locate(pp Expr, data any, rest Expr, max int) (locs []Expr) {
 					break
 				}
 			}
-			locs = append(locs, f.locate(cp, v, rest, mx)...)
+			locs = append(locs, f.locate(cp, v, root, rest, mx)...)
 		}
 	case []any:
 		for i, v := range td {
@@ -54,10 +64,18 @@ func (f Descent) locate(pp Expr, data any, rest Expr, max int) (locs []Expr) {
 					break
 				}
 			}
-			locs = append(locs, f.locate(cp, v, rest, mx)...)
+			locs = append(locs, f.locate(cp, v, root, rest, mx)...)
 		}
 	case gen.Object:
-		for k, v := range td {
+		keys := make([]string, 0, len(td))
+		for k := range td {
+			keys = append(keys, k)
+		}
+		if 0 < max {
+			sort.Strings(keys)
+		}
+		for _, k := range keys {
+			v := td[k]
 			cp[len(pp)] = Child(k)
 			if 0 < max {
 				mx = max - len(locs)
@@ -65,7 +83,7 @@ func (f Descent) locate(pp Expr, data any, rest Expr, max int) (locs []Expr) {
 					break
 				}
 			}
-			locs = append(locs, f.locate(cp, v, rest, mx)...)
+			locs = append(locs, f.locate(cp, v, root, rest, mx)...)
 		}
 	case gen.Array:
 		for i, v := range td {
@@ -76,7 +94,7 @@ func (f Descent) locate(pp Expr, data any, rest Expr, max int) (locs []Expr) {
 					break
 				}
 			}
-			locs = append(locs, f.locate(cp, v, rest, mx)...)
+			locs = append(locs, f.locate(cp, v, root, rest, mx)...)
 		}
 	case Keyed:
 		keys := td.Keys()
@@ -89,7 +107,7 @@ func (f Descent) locate(pp Expr, data any, rest Expr, max int) (locs []Expr) {
 					break
 				}
 			}
-			locs = append(locs, f.locate(cp, v, rest, mx)...)
+			locs = append(locs, f.locate(cp, v, root, rest, mx)...)
 		}
 	case Indexed:
 		size := td.Size()
@@ -102,7 +120,7 @@ func (f Descent) locate(pp Expr, data any, rest Expr, max int) (locs []Expr) {
 					break
 				}
 			}
-			locs = append(locs, f.locate(cp, v, rest, mx)...)
+			locs = append(locs, f.locate(cp, v, root, rest, mx)...)
 		}
 	case nil, bool, string, float64, float32, gen.Bool, gen.Float, gen.String,
 		int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64, gen.Int:
@@ -126,7 +144,7 @@ func (f Descent) locate(pp Expr, data any, rest Expr, max int) (locs []Expr) {
 							break
 						}
 					}
-					locs = append(locs, f.locate(cp, rv.Interface(), rest, mx)...)
+					locs = append(locs, f.locate(cp, rv.Interface(), root, rest, mx)...)
 				}
 			}
 		case reflect.Slice, reflect.Array:
@@ -140,7 +158,7 @@ func (f Descent) locate(pp Expr, data any, rest Expr, max int) (locs []Expr) {
 							break
 						}
 					}
-					locs = append(locs, f.locate(cp, rv.Interface(), rest, mx)...)
+					locs = append(locs, f.locate(cp, rv.Interface(), root, rest, mx)...)
 				}
 			}
 		}
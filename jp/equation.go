@@ -16,6 +16,7 @@ type Equation struct {
 	result any
 	left   *Equation
 	right  *Equation
+	args   []*Equation // for functions registered with RegisterFunc
 }
 
 // MustParseEquation parses the string argument and returns an Equation or panics.
@@ -222,6 +223,11 @@ func Count(x Expr) *Equation {
 	return &Equation{o: count, left: &Equation{result: x}}
 }
 
+// Value creates and returns an Equation for a value function.
+func Value(x Expr) *Equation {
+	return &Equation{o: valueOp, left: &Equation{result: x}}
+}
+
 // Match creates and returns an Equation for a match function.
 func Match(left, right *Equation) *Equation {
 	return &Equation{o: match, left: left, right: right}
@@ -236,7 +242,7 @@ func Search(left, right *Equation) *Equation {
 func (e *Equation) Append(buf []byte, parens bool) []byte {
 	if e.o != nil {
 		switch e.o.code {
-		case not.code, length.code, count.code, match.code, search.code, group.code:
+		case userOpCode, not.code, length.code, count.code, valueOp.code, match.code, search.code, group.code, existsTest.code:
 			parens = false
 		}
 	}
@@ -256,7 +262,29 @@ func (e *Equation) Append(buf []byte, parens bool) []byte {
 			if e.left != nil {
 				buf = e.appendValue(buf, e.left.result)
 			}
-		case length.code, count.code:
+		case existsTest.code:
+			buf = e.appendValue(buf, e.left.result)
+		case userOpCode:
+			buf = append(buf, e.o.name...)
+			buf = append(buf, '(')
+			if e.o.varFun != nil {
+				for i, arg := range e.args {
+					if 0 < i {
+						buf = append(buf, ',', ' ')
+					}
+					buf = arg.Append(buf, false)
+				}
+			} else {
+				if e.left != nil {
+					buf = e.left.Append(buf, false)
+				}
+				if e.right != nil {
+					buf = append(buf, ',', ' ')
+					buf = e.right.Append(buf, false)
+				}
+			}
+			buf = append(buf, ')')
+		case length.code, count.code, valueOp.code:
 			buf = append(buf, e.o.name...)
 			buf = append(buf, '(')
 			buf = e.appendValue(buf, e.left.result)
@@ -319,6 +347,8 @@ func (e *Equation) appendValue(buf []byte, v any) []byte {
 		buf = append(buf, ']')
 	case Expr:
 		buf = tv.Append(buf)
+	case Range:
+		buf = tv.append(buf)
 	case *regexp.Regexp:
 		buf = AppendString(buf, tv.String(), '/')
 	}
@@ -335,12 +365,19 @@ func (e *Equation) buildScript(stack []any) []any {
 		stack = append(stack, e.result)
 		return stack
 	}
+	if e.o.varFun != nil {
+		stack = append(stack, e.o)
+		for _, arg := range e.args {
+			stack = arg.buildScript(stack)
+		}
+		return stack
+	}
 	switch e.o.code {
 	case get.code:
 		if e.left != nil {
 			stack = append(stack, e.left.result) // should always be an Expr
 		}
-	case not.code, length.code, count.code, group.code:
+	case not.code, length.code, count.code, valueOp.code, group.code, existsTest.code:
 		stack = append(stack, e.o)
 		if e.left == nil {
 			stack = append(stack, nil)
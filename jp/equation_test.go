@@ -59,6 +59,12 @@ func TestEquation(t *testing.T) {
 	eq = jp.In(jp.ConstInt(3), jp.ConstList([]any{int64(1), int64(2), int64(3)}))
 	tt.Equal(t, "(3 in [1,2,3])", eq.String())
 
+	eq = jp.In(jp.Get(jp.A().C("x")), jp.ConstList([]any{jp.Range{Min: 1, Max: 3}, int64(7)}))
+	tt.Equal(t, "(@.x in [1..3,7])", eq.String())
+	tt.Equal(t, 2, len(jp.R().F(eq).Get([]any{map[string]any{"x": 2}, map[string]any{"x": 5}, map[string]any{"x": 7}})))
+	tt.Equal(t, true, jp.Range{Min: 1, Max: 3}.Has(uint8(3)))
+	tt.Equal(t, false, jp.Range{Min: 1, Max: 3}.Has("2"))
+
 	eq = jp.Empty(jp.ConstList([]any{int64(1)}), jp.ConstBool(true))
 	tt.Equal(t, "([1] empty true)", eq.String())
 
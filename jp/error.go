@@ -0,0 +1,34 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package jp
+
+import "fmt"
+
+// ErrPartialWrite is the error returned when a write that is made up of
+// several steps fails after some of the steps have been applied. It is
+// matched with errors.As. SetMany counts each assignment as a step while
+// Modify counts each value changed by the modifier.
+type ErrPartialWrite struct {
+	// Applied is the number of steps that were applied to the data before
+	// the failure. It is always zero when an atomic function fails since
+	// the data is then left unchanged.
+	Applied int
+
+	// Path is the path of the step that failed. For SetMany it is the path
+	// of the assignment and for Modify it is the expression being applied
+	// or, for ModifyAtomic, the location of the failed value.
+	Path Expr
+
+	// Err is the error that caused the failure.
+	Err error
+}
+
+// Error returns a string representation of the error.
+func (err *ErrPartialWrite) Error() string {
+	return fmt.Sprintf("%s at '%s' after %d applied", err.Err, err.Path, err.Applied)
+}
+
+// Unwrap returns the error that caused the failure.
+func (err *ErrPartialWrite) Unwrap() error {
+	return err.Err
+}
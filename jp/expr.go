@@ -3,6 +3,7 @@
 package jp
 
 import (
+	"strconv"
 	"unsafe"
 )
 
@@ -56,6 +57,61 @@ func (x Expr) Normal() bool {
 	return true
 }
 
+// NormalizedPath returns the RFC 9535 normalized path for a location such
+// as those returned by Locate. Names are written in single quoted bracket
+// notation and indexes in brackets as in $['store']['book'][0]. Fragments
+// other than child and nth are written in their usual bracket form.
+func (x Expr) NormalizedPath() string {
+	buf := []byte{'$'}
+	for _, f := range x {
+		switch tf := f.(type) {
+		case Root, At, Bracket:
+			// not part of the path
+		case Child:
+			buf = append(buf, '[')
+			buf = appendNormalName(buf, string(tf))
+			buf = append(buf, ']')
+		case Nth:
+			buf = append(buf, '[')
+			buf = strconv.AppendInt(buf, int64(tf), 10)
+			buf = append(buf, ']')
+		default:
+			buf = f.Append(buf, true, false)
+		}
+	}
+	return string(buf)
+}
+
+// appendNormalName appends a single quoted name with the escapes required
+// for a normalized path.
+func appendNormalName(buf []byte, name string) []byte {
+	buf = append(buf, '\'')
+	for _, b := range []byte(name) {
+		switch b {
+		case '\b':
+			buf = append(buf, `\b`...)
+		case '\f':
+			buf = append(buf, `\f`...)
+		case '\n':
+			buf = append(buf, `\n`...)
+		case '\r':
+			buf = append(buf, `\r`...)
+		case '\t':
+			buf = append(buf, `\t`...)
+		case '\'', '\\':
+			buf = append(buf, '\\', b)
+		default:
+			if b < 0x20 {
+				buf = append(buf, `\u00`...)
+				buf = append(buf, hex[b>>4], hex[b&0x0f])
+			} else {
+				buf = append(buf, b)
+			}
+		}
+	}
+	return append(buf, '\'')
+}
+
 func isNil(v any) bool {
 	return (*[2]uintptr)(unsafe.Pointer(&v))[1] == 0
 }
@@ -3,7 +3,6 @@
 package jp
 
 import (
-	"fmt"
 	"reflect"
 	"sort"
 	"strings"
@@ -33,7 +32,7 @@ func MustNewFilter(str string) (f *Filter) {
 	p := &parser{buf: []byte(str)}
 	if len(p.buf) <= 3 ||
 		p.buf[0] != '[' || p.buf[1] != '?' || p.buf[len(p.buf)-1] != ']' {
-		panic(fmt.Errorf("a filter must start with a '[?' and end with ']'"))
+		panic(ojg.Errorf(ojg.ErrSyntax, "a filter must start with a '[?' and end with ']'"))
 	}
 	p.buf = p.buf[2 : len(p.buf)-1]
 	eq := precedentCorrect(p.readEq())
@@ -290,21 +289,23 @@ func (f *Filter) removeOne(value any) (out any, changed bool) {
 	return
 }
 
-func (f *Filter) locate(pp Expr, data any, rest Expr, max int) (locs []Expr) {
-	ns, lcs := f.evalWithRoot([]any{}, data, nil)
+func (f *Filter) locate(pp Expr, data, root any, rest Expr, max int) (locs []Expr) {
+	ns, lcs := f.evalWithRoot([]any{}, data, root)
 	stack, _ := ns.([]any)
+	// The matches are evaluated from last to first so walk them in reverse
+	// to return the locations in document order.
 	if len(rest) == 0 { // last one
-		for _, lc := range lcs {
-			locs = locateAppendFrag(locs, pp, lc)
+		for i := len(lcs) - 1; 0 <= i; i-- {
+			locs = locateAppendFrag(locs, pp, lcs[i])
 			if 0 < max && max <= len(locs) {
 				break
 			}
 		}
 	} else {
 		cp := append(pp, nil) // place holder
-		for i, lc := range lcs {
-			cp[len(pp)] = lc
-			locs = locateContinueFrag(locs, cp, stack[i], rest, max)
+		for i := len(lcs) - 1; 0 <= i; i-- {
+			cp[len(pp)] = lcs[i]
+			locs = locateContinueFrag(locs, cp, stack[i], root, rest, max)
 			if 0 < max && max <= len(locs) {
 				break
 			}
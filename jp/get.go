@@ -4,6 +4,7 @@ package jp
 
 import (
 	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/ohler55/ojg/gen"
@@ -38,6 +39,10 @@ func (x Expr) Get(data any) (results []any) {
 	if len(x) == 0 {
 		return
 	}
+	if x.hasParent() {
+		results, _ = x.walkParents(data, 0)
+		return
+	}
 	var v any
 	var prev any
 	var has bool
@@ -875,17 +880,40 @@ func (x Expr) Get(data any) (results []any) {
 	return
 }
 
-// First element of the data identified by the path.
+// First element of the data identified by the path. The first element is
+// the first in document order where array elements are in index order and
+// map members are in key order.
 func (x Expr) First(data any) any {
 	first, _ := x.FirstFound(data)
 	return first
 }
 
-// FirstFound element of the data identified by the path.
+// FirstN returns up to n elements of the data identified by the path in
+// document order as described for First().
+func (x Expr) FirstN(data any, n int) (results []any) {
+	if n <= 0 {
+		return
+	}
+	for _, loc := range x.Locate(data, n) {
+		if v, has := loc.FirstFound(data); has {
+			results = append(results, v)
+		}
+	}
+	return
+}
+
+// FirstFound element of the data identified by the path in document order
+// as described for First().
 func (x Expr) FirstFound(data any) (any, bool) {
 	if len(x) == 0 {
 		return nil, false
 	}
+	if x.hasParent() {
+		if values, _ := x.walkParents(data, 1); 0 < len(values) {
+			return values[0], true
+		}
+		return nil, false
+	}
 	var (
 		v    any
 		prev any
@@ -995,12 +1023,18 @@ func (x Expr) FirstFound(data any) (any, bool) {
 		case Wildcard:
 			switch tv := prev.(type) {
 			case map[string]any:
+				keys := make([]string, 0, len(tv))
+				for k := range tv {
+					keys = append(keys, k)
+				}
+				sort.Strings(keys)
 				if int(fi) == len(x)-1 { // last one
-					for _, v = range tv {
-						return v, true
+					if 0 < len(keys) {
+						return tv[keys[0]], true
 					}
 				} else {
-					for _, v = range tv {
+					for i := len(keys) - 1; 0 <= i; i-- {
+						v = tv[keys[i]]
 						switch v.(type) {
 						case nil, bool, string, float64, float32, gen.Bool, gen.Float, gen.String,
 							int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64, gen.Int:
@@ -1088,12 +1122,18 @@ func (x Expr) FirstFound(data any) (any, bool) {
 					}
 				}
 			case gen.Object:
+				keys := make([]string, 0, len(tv))
+				for k := range tv {
+					keys = append(keys, k)
+				}
+				sort.Strings(keys)
 				if int(fi) == len(x)-1 { // last one
-					for _, v = range tv {
-						return v, true
+					if 0 < len(keys) {
+						return tv[keys[0]], true
 					}
 				} else {
-					for _, v = range tv {
+					for i := len(keys) - 1; 0 <= i; i-- {
+						v = tv[keys[i]]
 						switch v.(type) {
 						case map[string]any, []any, gen.Object, gen.Array, Keyed, Indexed:
 							stack = append(stack, v)
@@ -1138,171 +1178,82 @@ func (x Expr) FirstFound(data any) (any, bool) {
 			di, _ := stack[len(stack)-1].(fragIndex)
 			// first pass expands, second continues evaluation
 			if (di & descentFlag) == 0 {
+				var children []any
 				switch tv := prev.(type) {
 				case map[string]any:
-					// Put prev back and slide fi.
-					stack[len(stack)-1] = prev
-					stack = append(stack, di|descentFlag)
-					if int(fi) == len(x)-1 { // last one
-						for _, v = range tv {
-							return v, true
-						}
+					keys := make([]string, 0, len(tv))
+					for k := range tv {
+						keys = append(keys, k)
 					}
-					for _, v = range tv {
-						switch v.(type) {
-						case nil, bool, string, float64, float32, gen.Bool, gen.Float, gen.String,
-							int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64, gen.Int:
-						case map[string]any, []any, gen.Object, gen.Array, Keyed, Indexed:
-							stack = append(stack, v)
-							stack = append(stack, fi|descentChildFlag)
-						default:
-							if rt := reflect.TypeOf(v); rt != nil {
-								switch rt.Kind() {
-								case reflect.Ptr, reflect.Slice, reflect.Struct, reflect.Array, reflect.Map:
-									stack = append(stack, v)
-								}
-							}
-						}
+					sort.Strings(keys)
+					children = make([]any, len(keys))
+					for i, k := range keys {
+						children[i] = tv[k]
 					}
 				case []any:
-					// Put prev back and slide fi.
-					stack[len(stack)-1] = prev
-					stack = append(stack, di|descentFlag)
-					if int(fi) == len(x)-1 { // last one
-						if 0 < len(tv) {
-							return tv[0], true
-						}
-					}
-					for i := len(tv) - 1; 0 <= i; i-- {
-						v = tv[i]
-						switch v.(type) {
-						case nil, bool, string, float64, float32, gen.Bool, gen.Float, gen.String,
-							int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64, gen.Int:
-						case map[string]any, []any, gen.Object, gen.Array, Keyed, Indexed:
-							stack = append(stack, v)
-							stack = append(stack, fi|descentChildFlag)
-						default:
-							if rt := reflect.TypeOf(v); rt != nil {
-								switch rt.Kind() {
-								case reflect.Ptr, reflect.Slice, reflect.Struct, reflect.Array, reflect.Map:
-									stack = append(stack, v)
-								}
-							}
-						}
-					}
+					children = tv
 				case Keyed:
 					keys := tv.Keys()
-					// Put prev back and slide fi.
-					stack[len(stack)-1] = prev
-					stack = append(stack, di|descentFlag)
-					if int(fi) == len(x)-1 { // last one
-						if 0 < len(keys) {
-							return tv.ValueForKey(keys[0])
-						}
-					}
-					for i := len(keys) - 1; 0 <= i; i-- {
-						v, _ := tv.ValueForKey(keys[i])
-						switch v.(type) {
-						case nil, bool, string, float64, float32, gen.Bool, gen.Float, gen.String,
-							int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64, gen.Int:
-						case map[string]any, []any, gen.Object, gen.Array, Keyed, Indexed:
-							stack = append(stack, v)
-							stack = append(stack, fi|descentChildFlag)
-						default:
-							if rt := reflect.TypeOf(v); rt != nil {
-								switch rt.Kind() {
-								case reflect.Ptr, reflect.Slice, reflect.Struct, reflect.Array, reflect.Map:
-									stack = append(stack, v)
-								}
-							}
-						}
+					children = make([]any, len(keys))
+					for i, k := range keys {
+						children[i], _ = tv.ValueForKey(k)
 					}
 				case Indexed:
 					size := tv.Size()
-					if int(fi) == len(x)-1 { // last one
-						if 0 < size {
-							return tv.ValueAtIndex(0), true
-						}
-					} else {
-						for i := size - 1; 0 <= i; i-- {
-							v = tv.ValueAtIndex(i)
-							switch v.(type) {
-							case nil, bool, string, float64, float32, gen.Bool, gen.Float, gen.String,
-								int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64, gen.Int:
-							case map[string]any, []any, gen.Object, gen.Array, Keyed, Indexed:
-								stack = append(stack, v)
-							default:
-								if rt := reflect.TypeOf(v); rt != nil {
-									switch rt.Kind() {
-									case reflect.Ptr, reflect.Slice, reflect.Struct, reflect.Array, reflect.Map:
-										stack = append(stack, v)
-									}
-								}
-							}
-						}
+					children = make([]any, size)
+					for i := 0; i < size; i++ {
+						children[i] = tv.ValueAtIndex(i)
 					}
 				case gen.Object:
-					// Put prev back and slide fi.
-					stack[len(stack)-1] = prev
-					stack = append(stack, di|descentFlag)
-					if int(fi) == len(x)-1 { // last one
-						for _, v = range tv {
-							return v, true
-						}
+					keys := make([]string, 0, len(tv))
+					for k := range tv {
+						keys = append(keys, k)
 					}
-					for _, v = range tv {
-						switch v.(type) {
-						case map[string]any, []any, gen.Object, gen.Array, Keyed, Indexed:
-							stack = append(stack, v)
-							stack = append(stack, fi|descentChildFlag)
-						}
+					sort.Strings(keys)
+					children = make([]any, len(keys))
+					for i, k := range keys {
+						children[i] = tv[k]
 					}
 				case gen.Array:
-					// Put prev back and slide fi.
-					stack[len(stack)-1] = prev
-					stack = append(stack, di|descentFlag)
-					if int(fi) == len(x)-1 { // last one
-						if 0 < len(tv) {
-							return tv[0], true
-						}
-					}
-					for i := len(tv) - 1; 0 <= i; i-- {
-						v = tv[i]
-						switch v.(type) {
-						case map[string]any, []any, gen.Object, gen.Array, Keyed, Indexed:
-							stack = append(stack, v)
-							stack = append(stack, fi|descentChildFlag)
-						}
+					children = make([]any, len(tv))
+					for i, n := range tv {
+						children[i] = n
 					}
 				default:
-					got := reflectGetWild(tv)
-					stack[len(stack)-1] = prev
-					stack = append(stack, di|descentFlag)
-					if int(fi) == len(x)-1 { // last one
-						if 0 < len(got) {
-							return got[0], true
-						}
-					} else {
-						for _, v := range got {
-							switch v.(type) {
-							case nil, bool, string, float64, float32, gen.Bool, gen.Float, gen.String,
-								int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64, gen.Int:
-							case map[string]any, []any, gen.Object, gen.Array, Keyed, Indexed:
+					// reflectGetWild returns values in reverse order
+					children = reflectGetWild(tv)
+					for i, j := 0, len(children)-1; i < j; i, j = i+1, j-1 {
+						children[i], children[j] = children[j], children[i]
+					}
+				}
+				if int(fi) == len(x)-1 { // last one
+					if 0 < len(children) {
+						return children[0], true
+					}
+					continue
+				}
+				// Push the children before prev so that prev is evaluated
+				// first and the results are in document order.
+				for i := len(children) - 1; 0 <= i; i-- {
+					v = children[i]
+					switch v.(type) {
+					case nil, bool, string, float64, float32, gen.Bool, gen.Float, gen.String,
+						int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64, gen.Int:
+					case map[string]any, []any, gen.Object, gen.Array, Keyed, Indexed:
+						stack = append(stack, v)
+						stack = append(stack, fi|descentChildFlag)
+					default:
+						if rt := reflect.TypeOf(v); rt != nil {
+							switch rt.Kind() {
+							case reflect.Ptr, reflect.Slice, reflect.Struct, reflect.Array, reflect.Map:
 								stack = append(stack, v)
 								stack = append(stack, fi|descentChildFlag)
-							default:
-								if rt := reflect.TypeOf(v); rt != nil {
-									switch rt.Kind() {
-									case reflect.Ptr, reflect.Slice, reflect.Struct, reflect.Array, reflect.Map:
-										stack = append(stack, v)
-										stack = append(stack, fi|descentChildFlag)
-									}
-								}
 							}
 						}
 					}
-
 				}
+				stack = append(stack, prev)
+				stack = append(stack, di|descentFlag)
 			} else {
 				stack = append(stack, prev)
 			}
@@ -1919,7 +1870,7 @@ func reflectGetWildOne(data any) (any, bool) {
 		}
 		switch rt.Kind() {
 		case reflect.Struct:
-			for i := rd.NumField() - 1; 0 <= i; i-- {
+			for i := 0; i < rd.NumField(); i++ {
 				rv := rd.Field(i)
 				if rv.CanInterface() {
 					return rv.Interface(), true
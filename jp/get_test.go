@@ -419,6 +419,25 @@ func TestExprFirstOnNode(t *testing.T) {
 	}
 }
 
+func TestExprFirstDocumentOrder(t *testing.T) {
+	src := `{"d":[5,6],"b":{"c":[{"a":3},{"a":4}],"a":2},"a":1,"e":[{"a":7}]}`
+	for _, data := range []any{oj.MustParseString(src), alt.Generify(oj.MustParseString(src))} {
+		// Repeat to make sure map iteration order does not change the result.
+		for i := 0; i < 20; i++ {
+			tt.Equal(t, 1, jp.MustParseString("$..a").First(data))
+			tt.Equal(t, 1, jp.MustParseString("$.*").First(data))
+			tt.Equal(t, 2, jp.MustParseString("$.*.a").First(data))
+			tt.Equal(t, 3, jp.MustParseString("$..[?(@.a > 2)].a").First(data))
+			tt.Equal(t, "[1 2 3]", pretty.SEN(jp.MustParseString("$..a").FirstN(data, 3)))
+			tt.Equal(t, "[1 2 3 4 7]", pretty.SEN(jp.MustParseString("$..a").FirstN(data, 10)))
+			tt.Equal(t, `[1,{"a":2,"c":[{"a":3},{"a":4}]}]`,
+				oj.JSON(jp.MustParseString("$.*").FirstN(data, 2), &oj.Options{Sort: true}))
+		}
+	}
+	tt.Equal(t, 0, len(jp.MustParseString("$..a").FirstN(map[string]any{"a": 1}, 0)))
+	tt.Equal(t, 0, len(jp.MustParseString("$.x").FirstN(map[string]any{"a": 1}, 3)))
+}
+
 func TestExprGetNodes(t *testing.T) {
 	data := buildNodeTree(4, 3, 0)
 	for i, d := range getTestData {
@@ -964,7 +983,7 @@ func TestGetDescentReflect(t *testing.T) {
 	tt.Equal(t, "[A BA B]", pretty.SEN(path.Get(c)))
 	tt.Equal(t, "A", pretty.SEN(path.First(c)))
 
-	tt.Equal(t, "{a: {x: BA} x: B}", pretty.SEN(jp.R().D().First(c)))
+	tt.Equal(t, "{x: A}", pretty.SEN(jp.R().D().First(c)))
 }
 
 func TestGetSliceReflect(t *testing.T) {
@@ -1129,7 +1148,7 @@ func TestGetIndexed(t *testing.T) {
 		{src: "$[2][1]", expect: "12", first: true},
 		{src: "$[*][*]", expect: "11", first: true},
 		{src: "$..", expect: "1", first: true},
-		{src: "$..[1]", expect: "12", first: true},
+		{src: "$..[1]", expect: "2", first: true},
 		{src: "$[1,2][-2,0]", expect: "12", first: true},
 	} {
 		x := jp.MustParseString(d.src)
@@ -14,6 +14,10 @@ func (x Expr) Has(data any) bool {
 	if len(x) == 0 {
 		return false
 	}
+	if x.hasParent() {
+		_, locs := x.walkParents(data, 1)
+		return 0 < len(locs)
+	}
 	var v any
 	var prev any
 	var has bool
@@ -12,13 +12,28 @@ import (
 // paths to those values in the data. The returned slice is limited to the max
 // specified. A max of 0 or less indicates there is no maximum.
 func (x Expr) Locate(data any, max int) (locs []Expr) {
-	if 0 < len(x) {
-		locs = x[0].locate(nil, data, x[1:], max)
+	if x.hasParent() {
+		_, locs = x.walkParents(data, max)
+	} else if 0 < len(x) {
+		locs = x[0].locate(nil, data, data, x[1:], max)
 	}
 	return
 }
 
-func locateNthChildHas(pp Expr, f Frag, v any, rest Expr, max int) (locs []Expr) {
+// FirstLocated returns the first value described by the Expr along with the
+// normalized path to that value in the data. If there is no match the
+// returned location is nil. The location can be used to report or later
+// target the exact value matched when the Expr includes wildcards, descent,
+// or filters.
+func (x Expr) FirstLocated(data any) (value any, loc Expr) {
+	if locs := x.Locate(data, 1); 0 < len(locs) {
+		loc = locs[0]
+		value = loc.First(data)
+	}
+	return
+}
+
+func locateNthChildHas(pp Expr, f Frag, v, root any, rest Expr, max int) (locs []Expr) {
 	if len(rest) == 0 { // last one
 		loc := make(Expr, len(pp)+1)
 		copy(loc, pp)
@@ -29,12 +44,12 @@ func locateNthChildHas(pp Expr, f Frag, v any, rest Expr, max int) (locs []Expr)
 		case nil, bool, string, float64, float32, gen.Bool, gen.Float, gen.String,
 			int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64, gen.Int:
 		case map[string]any, []any, gen.Object, gen.Array, Keyed, Indexed:
-			locs = rest[0].locate(append(pp, f), v, rest[1:], max)
+			locs = rest[0].locate(append(pp, f), v, root, rest[1:], max)
 		default:
 			if rt := reflect.TypeOf(v); rt != nil {
 				switch rt.Kind() {
 				case reflect.Ptr, reflect.Slice, reflect.Struct, reflect.Array, reflect.Map:
-					locs = rest[0].locate(append(pp, f), v, rest[1:], max)
+					locs = rest[0].locate(append(pp, f), v, root, rest[1:], max)
 				}
 			}
 		}
@@ -50,7 +65,7 @@ func locateAppendFrag(locs []Expr, pp Expr, f Frag) []Expr {
 	return append(locs, loc)
 }
 
-func locateContinueFrag(locs []Expr, cp Expr, v any, rest Expr, max int) []Expr {
+func locateContinueFrag(locs []Expr, cp Expr, v, root any, rest Expr, max int) []Expr {
 	mx := max
 	if 0 < max {
 		mx = max - len(locs)
@@ -59,12 +74,12 @@ func locateContinueFrag(locs []Expr, cp Expr, v any, rest Expr, max int) []Expr
 	case nil, bool, string, float64, float32, gen.Bool, gen.Float, gen.String,
 		int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64, gen.Int:
 	case map[string]any, []any, gen.Object, gen.Array, Keyed, Indexed:
-		locs = append(locs, rest[0].locate(cp, v, rest[1:], mx)...)
+		locs = append(locs, rest[0].locate(cp, v, root, rest[1:], mx)...)
 	default:
 		if rt := reflect.TypeOf(v); rt != nil {
 			switch rt.Kind() {
 			case reflect.Ptr, reflect.Slice, reflect.Struct, reflect.Array, reflect.Map:
-				locs = append(locs, rest[0].locate(cp, v, rest[1:], mx)...)
+				locs = append(locs, rest[0].locate(cp, v, root, rest[1:], mx)...)
 			}
 		}
 	}
@@ -242,3 +242,20 @@ func TestExprLocateBracket(t *testing.T) {
 	x := jp.B().N(0).C("b")
 	tt.Equal(t, "[0]['b']", x.Locate(data, 0)[0].BracketString())
 }
+
+func TestExprFirstLocated(t *testing.T) {
+	data := map[string]any{
+		"a": map[string]any{"b": []any{int64(1), int64(2), map[string]any{"c": "x"}}},
+	}
+	value, loc := jp.MustParseString("$..c").FirstLocated(data)
+	tt.Equal(t, "x", value)
+	tt.Equal(t, "$.a.b[2].c", loc.String())
+
+	value, loc = jp.MustParseString("$.a.b[?(@ > 1)]").FirstLocated(data)
+	tt.Equal(t, 2, value)
+	tt.Equal(t, "$.a.b[1]", loc.String())
+
+	value, loc = jp.MustParseString("$.x.*").FirstLocated(data)
+	tt.Nil(t, value)
+	tt.Equal(t, true, loc == nil)
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package jp
+
+import (
+	"reflect"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/gen"
+)
+
+// Assignment is a path and the value to set at that path. A list of
+// assignments is applied in order by SetMany.
+type Assignment struct {
+	Path  Expr
+	Value any
+}
+
+// SetMany applies each assignment to the data in order as with Set. If an
+// assignment fails the remaining assignments are not applied and an
+// *ErrPartialWrite is returned that identifies the failed assignment and
+// the number applied before it.
+func SetMany(data any, assignments []Assignment) error {
+	for i, a := range assignments {
+		if err := a.Path.Set(data, a.Value); err != nil {
+			return &ErrPartialWrite{Applied: i, Path: a.Path, Err: err}
+		}
+	}
+	return nil
+}
+
+// SetManyAtomic is the same as SetMany except either all of the assignments
+// are applied or none are. The assignments are first applied to a shadow
+// copy of the data and only applied to the data if all succeed. Maps,
+// slices, pointers, and exported struct fields are copied for the shadow
+// but data held in unexported fields is shared.
+func SetManyAtomic(data any, assignments []Assignment) error {
+	if err := SetMany(shadowCopy(data), assignments); err != nil {
+		err.(*ErrPartialWrite).Applied = 0
+		return err
+	}
+	return SetMany(data, assignments)
+}
+
+// ModifyAtomic is the same as Modify except that if the modifier panics
+// the data is left unchanged. The modifier is applied to each location on
+// a shadow copy of the data and the altered values are only placed in the
+// data once the modifier has been called for every location. The same
+// limits on the shadow copy apply as for SetManyAtomic.
+func (x Expr) ModifyAtomic(
+	data any,
+	modifier func(element any) (altered any, changed bool)) (result any, err error) {
+
+	var loc Expr
+	defer func() {
+		if r := recover(); r != nil {
+			result = data
+			err = ojg.NewError(r)
+			if loc != nil {
+				err = &ErrPartialWrite{Path: loc, Err: err}
+			}
+		}
+	}()
+	x.checkModify()
+	shadow := shadowCopy(data)
+	var (
+		changes []Expr
+		values  []any
+	)
+	for _, loc = range x.Locate(shadow, 0) {
+		shadow = loc.modify(shadow, func(element any) (any, bool) {
+			altered, changed := modifier(element)
+			if changed {
+				changes = append(changes, loc)
+				values = append(values, altered)
+			}
+			return altered, changed
+		}, true)
+	}
+	loc = nil
+	result = data
+	for i, c := range changes {
+		v := values[i]
+		result = c.modify(result, func(any) (any, bool) { return v, true }, true)
+	}
+	return
+}
+
+// shadowCopy returns a deep copy of the data that can be written to without
+// changing the original.
+func shadowCopy(data any) any {
+	switch td := data.(type) {
+	case nil, bool, int, int64, float64, string:
+		return data
+	case []any:
+		a := make([]any, len(td))
+		for i, v := range td {
+			a[i] = shadowCopy(v)
+		}
+		return a
+	case map[string]any:
+		obj := make(map[string]any, len(td))
+		for k, v := range td {
+			obj[k] = shadowCopy(v)
+		}
+		return obj
+	case gen.Node:
+		return td.Dup()
+	}
+	return shadowValue(reflect.ValueOf(data)).Interface()
+}
+
+func shadowValue(rv reflect.Value) reflect.Value {
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return rv
+		}
+		if rv.Kind() == reflect.Interface {
+			cp := reflect.New(rv.Type()).Elem()
+			cp.Set(shadowValue(rv.Elem()))
+			return cp
+		}
+		cp := reflect.New(rv.Type().Elem())
+		cp.Elem().Set(shadowValue(rv.Elem()))
+		return cp
+	case reflect.Struct:
+		cp := reflect.New(rv.Type()).Elem()
+		cp.Set(rv)
+		for i := cp.NumField() - 1; 0 <= i; i-- {
+			if f := cp.Field(i); f.CanSet() {
+				f.Set(shadowValue(f))
+			}
+		}
+		return cp
+	case reflect.Slice:
+		if rv.IsNil() {
+			return rv
+		}
+		cp := reflect.MakeSlice(rv.Type(), rv.Len(), rv.Len())
+		for i := rv.Len() - 1; 0 <= i; i-- {
+			cp.Index(i).Set(shadowValue(rv.Index(i)))
+		}
+		return cp
+	case reflect.Array:
+		cp := reflect.New(rv.Type()).Elem()
+		for i := rv.Len() - 1; 0 <= i; i-- {
+			cp.Index(i).Set(shadowValue(rv.Index(i)))
+		}
+		return cp
+	case reflect.Map:
+		if rv.IsNil() {
+			return rv
+		}
+		cp := reflect.MakeMapWithSize(rv.Type(), rv.Len())
+		for it := rv.MapRange(); it.Next(); {
+			cp.SetMapIndex(it.Key(), shadowValue(it.Value()))
+		}
+		return cp
+	}
+	return rv
+}
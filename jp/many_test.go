@@ -0,0 +1,107 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package jp_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ohler55/ojg/jp"
+	"github.com/ohler55/ojg/sen"
+	"github.com/ohler55/ojg/tt"
+)
+
+type manyHolder struct {
+	Name  string
+	Items []any
+}
+
+func TestSetMany(t *testing.T) {
+	data := map[string]any{"a": 1, "b": "two"}
+	assignments := []jp.Assignment{
+		{Path: jp.C("a"), Value: 10},
+		{Path: jp.C("c").C("d"), Value: true},
+		{Path: jp.C("b").C("x"), Value: 3},
+		{Path: jp.C("e"), Value: 5},
+	}
+	err := jp.SetMany(data, assignments)
+	var pe *jp.ErrPartialWrite
+	tt.Equal(t, true, errors.As(err, &pe))
+	tt.Equal(t, 2, pe.Applied)
+	tt.Equal(t, "b.x", pe.Path.String())
+	tt.NotNil(t, pe.Unwrap())
+	tt.Equal(t, "{a:10 b:two c:{d:true}}", sen.String(data, &sen.Options{Sort: true}))
+
+	tt.Nil(t, jp.SetMany(data, assignments[:2]))
+}
+
+func TestSetManyAtomic(t *testing.T) {
+	data := map[string]any{"a": 1, "b": "two", "list": []any{1, 2}}
+	err := jp.SetManyAtomic(data, []jp.Assignment{
+		{Path: jp.C("a"), Value: 10},
+		{Path: jp.C("list").N(0), Value: 0},
+		{Path: jp.C("b").C("x"), Value: 3},
+	})
+	var pe *jp.ErrPartialWrite
+	tt.Equal(t, true, errors.As(err, &pe))
+	tt.Equal(t, 0, pe.Applied)
+	tt.Equal(t, "{a:1 b:two list:[1 2]}", sen.String(data, &sen.Options{Sort: true}))
+
+	tt.Nil(t, jp.SetManyAtomic(data, []jp.Assignment{
+		{Path: jp.C("a"), Value: 10},
+		{Path: jp.C("list").N(0), Value: 0},
+	}))
+	tt.Equal(t, "{a:10 b:two list:[0 2]}", sen.String(data, &sen.Options{Sort: true}))
+
+	holder := &manyHolder{Name: "x", Items: []any{1}}
+	err = jp.SetManyAtomic(holder, []jp.Assignment{
+		{Path: jp.C("Items").N(0), Value: 2},
+		{Path: jp.C("Name").C("y"), Value: 3},
+	})
+	tt.NotNil(t, err)
+	tt.Equal(t, 1, holder.Items[0])
+}
+
+func TestModifyPartial(t *testing.T) {
+	data := []any{1, 2, "three", 4}
+	_, err := jp.MustParseString("[*]").Modify(data, func(element any) (any, bool) {
+		return element.(int) * 10, true
+	})
+	var pe *jp.ErrPartialWrite
+	tt.Equal(t, true, errors.As(err, &pe))
+	tt.Equal(t, 2, pe.Applied)
+	tt.Equal(t, "[*]", pe.Path.String())
+	tt.Equal(t, "[10 20 three 4]", sen.String(data))
+
+	// Expression errors are not partial writes.
+	_, err = jp.D().Modify(data, func(element any) (any, bool) { return element, false })
+	tt.NotNil(t, err)
+	tt.Equal(t, false, errors.As(err, &pe))
+}
+
+func TestModifyAtomic(t *testing.T) {
+	data := map[string]any{"a": []any{1, 2, "three", 4}}
+	x := jp.MustParseString("a[*]")
+	result, err := x.ModifyAtomic(data, func(element any) (any, bool) {
+		return element.(int) * 10, true
+	})
+	var pe *jp.ErrPartialWrite
+	tt.Equal(t, true, errors.As(err, &pe))
+	tt.Equal(t, 0, pe.Applied)
+	tt.Equal(t, "a[2]", pe.Path.String())
+	tt.Equal(t, "{a:[1 2 three 4]}", sen.String(result))
+
+	result, err = x.ModifyAtomic(data, func(element any) (any, bool) {
+		if i, ok := element.(int); ok {
+			return i * 10, true
+		}
+		return fmt.Sprintf("%s!", element), true
+	})
+	tt.Nil(t, err)
+	tt.Equal(t, `{a:[10 20 "three!" 40]}`, sen.String(result))
+	tt.Equal(t, `{a:[10 20 "three!" 40]}`, sen.String(data))
+
+	_, err = jp.D().ModifyAtomic(data, func(element any) (any, bool) { return element, false })
+	tt.NotNil(t, err)
+}
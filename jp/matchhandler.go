@@ -12,9 +12,16 @@ type TargetRest struct {
 	Target Expr
 	// Rest is set when a Filter is included in the initializing target. Since
 	// Filters can only be evaluated when there is data for the evaluation a
-	// traget with a Filter is split with the pre-filter portion and the rest
-	// starting with the filter.
+	// traget with a Filter is split with the pre-filter portion followed by a
+	// wildcard and the rest starting with the filter. Each element the filter
+	// applies to is then built and evaluated on its own so the whole
+	// collection being filtered is never held in memory.
 	Rest Expr
+	// Nested is set when a Descent comes before the Filter. It is the
+	// Descent and the portion of the target after it followed by the Rest
+	// and is evaluated on each element to find the matches nested in the
+	// element.
+	Nested Expr
 }
 
 // PathHandler is a TokenHandler compatible with both the oj.TokenHandler and
@@ -38,7 +45,13 @@ func NewMatchHandler(onData func(path Expr, data any), targets ...Expr) *MatchHa
 		for i, f := range target {
 			if _, ok := f.(*Filter); ok {
 				tr.Rest = target[i:]
-				tr.Target = target[:i]
+				tr.Target = append(append(Expr{}, target[:i]...), Wildcard('*'))
+				for j := i - 1; 0 <= j; j-- {
+					if _, ok := target[j].(Descent); ok {
+						tr.Nested = append(append(Expr{Descent('.')}, target[j+1:i]...), target[i:]...)
+						break
+					}
+				}
 				break
 			}
 		}
@@ -113,8 +126,8 @@ func (h *MatchHandler) AddValue(v any) {
 		case []any:
 			h.Stack[len(h.Stack)-1] = append(ts, v)
 		}
-	} else if h.pathMatch(true) {
-		h.OnData(h.Path, v)
+	} else if h.pathMatch() {
+		h.emit(v)
 	}
 	h.incNth()
 }
@@ -128,7 +141,7 @@ func (h *MatchHandler) objArrayStart(v any, frag Frag) {
 			h.Stack[len(h.Stack)-1] = append(ts, v)
 		}
 		h.Stack = append(h.Stack, v)
-	} else if h.pathMatch(false) {
+	} else if h.pathMatch() {
 		h.Stack = append(h.Stack, v)
 	}
 	h.Path = append(h.Path, frag)
@@ -138,9 +151,7 @@ func (h *MatchHandler) objArrayEnd() {
 	h.Path = h.Path[:len(h.Path)-1]
 	if 0 < len(h.Stack) {
 		if len(h.Stack) == 1 {
-			if v, p, ok := h.checkRest(h.Stack[0]); ok {
-				h.OnData(p, v)
-			}
+			h.emit(h.Stack[0])
 		}
 		v := h.Stack[len(h.Stack)-1]
 		h.Stack = h.Stack[:len(h.Stack)-1]
@@ -164,7 +175,11 @@ func (h *MatchHandler) incNth() {
 	}
 }
 
-func (h *MatchHandler) checkRest(v any) (any, Expr, bool) {
+// emit calls OnData with the value at the current path or, if the matching
+// target has a Rest, with each match of the rest when evaluated on the value
+// as the only member of the collection being filtered. If the target also
+// has a Nested expression the matches nested in the value follow.
+func (h *MatchHandler) emit(v any) {
 	var tr *TargetRest
 	for _, t := range h.Targets {
 		if PathMatch(t.Target, h.Path) {
@@ -172,24 +187,27 @@ func (h *MatchHandler) checkRest(v any) (any, Expr, bool) {
 			break
 		}
 	}
-	p := h.Path
-	if tr != nil && tr.Rest != nil {
-		locs := tr.Rest.Locate(v, 1)
-		if len(locs) == 0 {
-			return nil, p, false
+	if tr == nil || tr.Rest == nil {
+		h.OnData(h.Path, v)
+		return
+	}
+	wrap := []any{v}
+	for _, loc := range tr.Rest.Locate(wrap, 0) {
+		p := append(append(Expr{}, h.Path...), loc[1:]...)
+		h.OnData(p, loc.First(wrap))
+	}
+	if tr.Nested != nil {
+		for _, loc := range tr.Nested.Locate(v, 0) {
+			p := append(append(Expr{}, h.Path...), loc...)
+			h.OnData(p, loc.First(v))
 		}
-		p = append(p, locs[0]...)
-		v = tr.Rest.First(v)
 	}
-	return v, p, true
 }
 
-func (h *MatchHandler) pathMatch(leaf bool) bool {
+func (h *MatchHandler) pathMatch() bool {
 	for _, tr := range h.Targets {
 		if PathMatch(tr.Target, h.Path) {
-			if !leaf || tr.Rest == nil {
-				return true
-			}
+			return true
 		}
 	}
 	return false
@@ -80,6 +80,21 @@ func TestMatchHandlerFilter(t *testing.T) {
 	for i, md := range []*matchHandlerData{
 		{target: "$[?@.x == 1]", src: "[{x:0 y:0} {x:1 y:1}]", expect: "$[1]: {x: 1 y: 1}\n"},
 		{target: "$[?@.x == 2]", src: "[{x:0 y:0} {x:1 y:1}]", expect: ""},
+		{target: "$[?@.x > 0]", src: "[{x:0} {x:1} {x:2}]", expect: "$[1]: {x: 1}\n$[2]: {x: 2}\n"},
+		{target: "$.a[?@ > 1]", src: "{a:[1 2 3]}", expect: "$.a[1]: 2\n$.a[2]: 3\n"},
+		{target: "$.a[?@.x == 1].y", src: "{a:[{x:1 y:1} {x:2 y:2} {x:1 y:3}]}", expect: "$.a[0].y: 1\n$.a[2].y: 3\n"},
+		{target: "$[?@.x == 1]", src: "{a:{x:1} b:{x:2}}", expect: "$.a: {x: 1}\n"},
+		{target: "$..[?(@.x == 1)]", src: "{a:{b:{x:1}}}", expect: "$.a.b: {x: 1}\n"},
+		{
+			target: "$..[?(@.x == 1)]",
+			src:    "[{x:1 c:[{x:1}]}]",
+			expect: "$[0]: {\n  c: [{x: 1}]\n  x: 1\n}\n$[0].c[0]: {x: 1}\n",
+		},
+		{
+			target: "$..a[?(@.x == 1)].y",
+			src:    "{a:[{x:1 y:1 a:[{x:1 y:2}]}] b:{a:[{x:2 y:3} {x:1 y:4}]}}",
+			expect: "$.a[0].y: 1\n$.a[0].a[0].y: 2\n$.b.a[1].y: 4\n",
+		},
 	} {
 		md.runTest(t, i)
 	}
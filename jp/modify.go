@@ -26,9 +26,11 @@ import (
 // will be the same object as the original. The modifier function will be
 // called with the elements that match the path and should return the original
 // element or if altered the altered value along with setting the returned
-// changed value to true.
+// changed value to true. If the modifier returns Nothing as the altered
+// value and true for changed the element is removed from the array or
+// object that contains it, the same as with Remove.
 func (x Expr) MustModify(data any, modifier func(element any) (altered any, changed bool)) any {
-	return x.modify(data, modifier, false)
+	return x.modifyRemoving(data, modifier, false)
 }
 
 // MustModifyOne modifies matching nodes and panics on an expression error.
@@ -39,7 +41,7 @@ func (x Expr) MustModify(data any, modifier func(element any) (altered any, chan
 // element or if altered the altered value along with setting the returned
 // changed value to true. The function returns after the first modification.
 func (x Expr) MustModifyOne(data any, modifier func(element any) (altered any, changed bool)) any {
-	return x.modify(data, modifier, true)
+	return x.modifyRemoving(data, modifier, true)
 }
 
 // Modify modifies matching nodes and panics on an expression error.  Modified
@@ -48,16 +50,10 @@ func (x Expr) MustModifyOne(data any, modifier func(element any) (altered any, c
 // the same object as the original. The modifier function will be called with
 // the elements that match the path and should return the original element or
 // if altered the altered value along with setting the returned changed value
-// to true.
+// to true. Returning Nothing removes the element as described for
+// MustModify.
 func (x Expr) Modify(data any, modifier func(element any) (altered any, changed bool)) (result any, err error) {
-	defer func() {
-		if r := recover(); r != nil {
-			err = ojg.NewError(r)
-		}
-	}()
-	result = x.modify(data, modifier, false)
-
-	return
+	return x.modifyCounted(data, modifier, false)
 }
 
 // ModifyOne modifies matching nodes and panics on an expression error.
@@ -68,25 +64,43 @@ func (x Expr) Modify(data any, modifier func(element any) (altered any, changed
 // element or if altered the altered value along with setting the returned
 // changed value to true. The function returns after the first modification.
 func (x Expr) ModifyOne(data any, modifier func(element any) (altered any, changed bool)) (result any, err error) {
+	return x.modifyCounted(data, modifier, true)
+}
+
+// modifyCounted counts the values changed by the modifier so that a panic
+// in the modifier can be reported as an ErrPartialWrite.
+func (x Expr) modifyCounted(
+	data any,
+	modifier func(element any) (altered any, changed bool),
+	one bool) (result any, err error) {
+
+	var (
+		applied    int
+		inModifier bool
+	)
 	defer func() {
 		if r := recover(); r != nil {
 			err = ojg.NewError(r)
+			if inModifier {
+				err = &ErrPartialWrite{Applied: applied, Path: x, Err: err}
+			}
 		}
 	}()
-	result = x.modify(data, modifier, true)
+	result = x.modifyRemoving(data, func(element any) (any, bool) {
+		inModifier = true
+		altered, changed := modifier(element)
+		inModifier = false
+		if changed {
+			applied++
+		}
+		return altered, changed
+	}, one)
 
 	return
 }
 
 func (x Expr) modify(data any, modifier func(element any) (altered any, changed bool), one bool) any {
-	if len(x) == 0 {
-		panic("can not modify with an empty expression")
-	}
-	if _, ok := x[len(x)-1].(Descent); ok {
-		ta := strings.Split(fmt.Sprintf("%T", x[len(x)-1]), ".")
-		panic(fmt.Sprintf("can not modify with an expression where the last fragment is a %s",
-			ta[len(ta)-1]))
-	}
+	x.checkModify()
 	wx := make(Expr, len(x)+1)
 	copy(wx[1:], x)
 	wx[0] = Nth(0)
@@ -914,6 +928,21 @@ done:
 	return wrap[0]
 }
 
+// checkModify panics if the expression can not be used to modify data.
+func (x Expr) checkModify() {
+	if len(x) == 0 {
+		panic("can not modify with an empty expression")
+	}
+	if x.hasParent() {
+		panic("can not modify with an expression that includes a parent")
+	}
+	if _, ok := x[len(x)-1].(Descent); ok {
+		ta := strings.Split(fmt.Sprintf("%T", x[len(x)-1]), ".")
+		panic(fmt.Sprintf("can not modify with an expression where the last fragment is a %s",
+			ta[len(ta)-1]))
+	}
+}
+
 func stackAddValue(stack []any, v any) []any {
 	switch v.(type) {
 	case map[string]any, []any, gen.Object, gen.Array, Keyed, Indexed:
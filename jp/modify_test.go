@@ -325,3 +325,57 @@ func TestExprModifyIndexedDescent(t *testing.T) {
 	})
 	tt.Equal(t, 16, jp.N(2).N(0).First(result))
 }
+
+func TestExprModifyRemove(t *testing.T) {
+	drop := func(element any) (any, bool) {
+		if n, ok := element.(int64); ok && n%2 == 1 {
+			return jp.Nothing, true
+		}
+		return element, false
+	}
+	data := map[string]any{"a": []any{int64(1), int64(2), int64(3), int64(4)}, "b": int64(5), "c": int64(6)}
+	result, err := jp.MustParseString("$.a[*]").Modify(data, drop)
+	tt.Nil(t, err)
+	tt.Equal(t, map[string]any{"a": []any{int64(2), int64(4)}, "b": int64(5), "c": int64(6)}, result)
+
+	result, err = jp.MustParseString("$.*").Modify(data, drop)
+	tt.Nil(t, err)
+	tt.Equal(t, map[string]any{"a": []any{int64(2), int64(4)}, "c": int64(6)}, result)
+
+	data = map[string]any{"x": []any{int64(1), map[string]any{"x": int64(3)}, int64(2)}}
+	result = jp.MustParseString("$..[?(@ == 1 || @ == 3)]").MustModify(data, drop)
+	tt.Equal(t, map[string]any{"x": []any{map[string]any{}, int64(2)}}, result)
+
+	list := []any{int64(1), int64(3), int64(2)}
+	result, err = jp.MustParseString("[*]").ModifyOne(list, drop)
+	tt.Nil(t, err)
+	tt.Equal(t, []any{int64(3), int64(2)}, result)
+
+	gd := gen.Object{"a": gen.Array{gen.Int(1), gen.Int(2)}}
+	result = jp.MustParseString("a[0]").MustModify(gd, func(element any) (any, bool) { return jp.Nothing, true })
+	tt.Equal(t, gen.Object{"a": gen.Array{gen.Int(2)}}, result)
+
+	result = jp.MustParseString("$").MustModify(list, func(element any) (any, bool) { return jp.Nothing, true })
+	tt.Nil(t, result)
+
+	// The filter no longer matches once x is removed but x is still removed.
+	nothing := func(element any) (any, bool) { return jp.Nothing, true }
+	data = map[string]any{"a": []any{
+		map[string]any{"x": int64(1), "y": int64(2)},
+		map[string]any{"x": int64(2)},
+		map[string]any{"x": int64(1)},
+	}}
+	result = jp.MustParseString("$.a[?(@.x == 1)].x").MustModify(data, nothing)
+	tt.Equal(t, map[string]any{"a": []any{
+		map[string]any{"y": int64(2)},
+		map[string]any{"x": int64(2)},
+		map[string]any{},
+	}}, result)
+
+	result = jp.MustParseString("$.a[?(@.x == 2)]").MustModify(data, nothing)
+	tt.Equal(t, map[string]any{"a": []any{map[string]any{"y": int64(2)}, map[string]any{}}}, result)
+
+	typed := map[string]map[string]any{"m": {"x": int64(1), "y": int64(2)}}
+	result = jp.MustParseString("$.m[?(@ == 1)]").MustModify(typed, nothing)
+	tt.Equal(t, map[string]map[string]any{"m": {"y": int64(2)}}, result)
+}
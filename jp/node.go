@@ -54,6 +54,9 @@ func (x Expr) GetNodes(n gen.Node) (results []gen.Node) {
 		_ = i.String()
 		return
 	}
+	if x.hasParent() {
+		return x.getParentNodes(n)
+	}
 	var v gen.Node
 	var prev gen.Node
 	var has bool
@@ -354,6 +357,12 @@ func (x Expr) FirstNode(n gen.Node) (result gen.Node) {
 	if len(x) == 0 {
 		return nil
 	}
+	if x.hasParent() {
+		if nodes := x.getParentNodes(n); 0 < len(nodes) {
+			result = nodes[0]
+		}
+		return
+	}
 	var v gen.Node
 	var prev gen.Node
 	var has bool
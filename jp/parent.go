@@ -0,0 +1,98 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package jp
+
+import (
+	"github.com/ohler55/ojg/gen"
+)
+
+// Parent is a ^ in a JSON path representation. It selects the array or
+// object that contains the current element so that a filter can match on a
+// member and the expression can continue from the element that holds it as
+// in $.store.book[?(@.price > 10)]^. The root has no parent. An element is
+// only returned once even if reached from more than one of its members.
+// Expressions with a Parent fragment can be used to get, locate, and walk
+// but not to set, modify, or remove values.
+type Parent byte
+
+// P appends a Parent fragment to the Expr.
+func (x Expr) P() Expr {
+	return append(x, Parent('^'))
+}
+
+// Parent appends a Parent fragment to the Expr.
+func (x Expr) Parent() Expr {
+	return append(x, Parent('^'))
+}
+
+// Append a fragment string representation of the fragment to the buffer
+// then returning the expanded buffer.
+func (f Parent) Append(buf []byte, bracket, first bool) []byte {
+	return append(buf, '^')
+}
+
+// locate is not used as Locate walks an expression with a Parent fragment
+// instead since the parent of the data is not available to locate.
+func (f Parent) locate(pp Expr, data, root any, rest Expr, max int) (locs []Expr) {
+	return nil
+}
+
+// Walk continues with the next in rest from the parent of the current
+// element.
+func (f Parent) Walk(rest, path Expr, nodes []any, cb func(path Expr, nodes []any)) {
+	if len(path) == 0 || len(nodes) < 2 {
+		return
+	}
+	path = append(Expr{}, path[:len(path)-1]...)
+	nodes = append([]any{}, nodes[:len(nodes)-1]...)
+	if 0 < len(rest) {
+		rest[0].Walk(rest[1:], path, nodes, cb)
+	} else {
+		cb(path, nodes)
+	}
+}
+
+// hasParent returns true if the expression includes a Parent fragment.
+func (x Expr) hasParent() bool {
+	for _, f := range x {
+		if _, ok := f.(Parent); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// walkParents returns the values and locations that match an expression
+// that includes a Parent fragment. Each location is only included once.
+func (x Expr) walkParents(data any, max int) (values []any, locs []Expr) {
+	var prefix Expr
+	switch x[0].(type) {
+	case Root, At:
+		prefix = x[:1]
+	}
+	seen := map[string]bool{}
+	x.Walk(data, func(path Expr, nodes []any) {
+		if 0 < max && max <= len(locs) {
+			return
+		}
+		loc := append(append(Expr{}, prefix...), path...)
+		key := loc.String()
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		locs = append(locs, loc)
+		values = append(values, nodes[len(nodes)-1])
+	})
+	return
+}
+
+func (x Expr) getParentNodes(n gen.Node) (results []gen.Node) {
+	values, _ := x.walkParents(n, 0)
+	for _, v := range values {
+		if node, ok := v.(gen.Node); ok {
+			results = append(results, node)
+		}
+	}
+	return
+}
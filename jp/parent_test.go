@@ -0,0 +1,56 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package jp_test
+
+import (
+	"testing"
+
+	"github.com/ohler55/ojg/gen"
+	"github.com/ohler55/ojg/jp"
+	"github.com/ohler55/ojg/sen"
+	"github.com/ohler55/ojg/tt"
+)
+
+func TestParentGet(t *testing.T) {
+	data := sen.MustParse([]byte(`{store:{book:[{title:a price:5} {title:b price:12} {title:c price:15}] bike:{price:20}}}`))
+	opt := sen.Options{Sort: true}
+
+	x := jp.MustParseString("$.store.book[?(@.price > 10)]^")
+	tt.Equal(t, "$.store.book[?(@.price > 10)]^", x.String())
+	result := x.Get(data)
+	tt.Equal(t, 1, len(result))
+	tt.Equal(t, 3, len(result[0].([]any)))
+
+	x = jp.MustParseString("$..price[?(@ > 14)]")
+	tt.Equal(t, 0, len(x.Get(data)))
+
+	x = jp.MustParseString("$..[?(@.price > 14)].title^.price")
+	tt.Equal(t, "[15]", sen.String(x.Get(data), &opt))
+
+	x = jp.MustParseString("$.store.*[?(@.price > 10)]^^.bike")
+	tt.Equal(t, "[{price:20}]", sen.String(x.Get(data), &opt))
+	tt.Equal(t, "{price:20}", sen.String(x.First(data), &opt))
+	tt.Equal(t, true, x.Has(data))
+	tt.Equal(t, []jp.Expr{jp.R().C("store").C("bike")}, x.Locate(data, 0))
+
+	tt.Equal(t, 0, len(jp.R().P().Get(data)))
+	tt.Equal(t, false, jp.R().P().Has(data))
+	tt.Nil(t, jp.R().Parent().First(data))
+
+	// ^ is no longer part of a dot name.
+	tt.Equal(t, "$['a^b']", jp.R().C("a^b").String())
+	tt.Equal(t, jp.R().C("a").P().C("b"), jp.MustParseString("$.a^.b"))
+
+	tt.NotNil(t, jp.MustParseString("$.a^.b").Set(data, 1))
+	tt.Panic(t, func() { jp.MustParseString("$.a^.b").MustModify(data, func(v any) (any, bool) { return v, false }) })
+	_, err := jp.ParseStrictString("$.a^")
+	tt.NotNil(t, err)
+}
+
+func TestParentGetNodes(t *testing.T) {
+	data := gen.Object{"a": gen.Array{gen.Int(1), gen.Int(2)}}
+	x := jp.MustParseString("$.a[*]^")
+	tt.Equal(t, []gen.Node{data["a"]}, x.GetNodes(data))
+	tt.Equal(t, data["a"], x.FirstNode(data))
+	tt.Nil(t, jp.MustParseString("$.b^").FirstNode(data))
+}
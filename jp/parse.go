@@ -17,7 +17,7 @@ const (
 	tokenMap = "" +
 		"................................" + // 0x00
 		"...o.o..........oooooooooooo...o" + // 0x20
-		".oooooooooooooooooooooooooo...oo" + // 0x40
+		".oooooooooooooooooooooooooo....o" + // 0x40
 		".oooooooooooooooooooooooooooooo." + // 0x60
 		"oooooooooooooooooooooooooooooooo" + // 0x80
 		"oooooooooooooooooooooooooooooooo" + // 0xa0
@@ -44,8 +44,9 @@ const (
 // approach over modes only adds 10% so a reasonable penalty for
 // maintainability.
 type parser struct {
-	buf []byte
-	pos int
+	buf    []byte
+	pos    int
+	strict bool
 }
 
 // ParseString parses a string into an Expr.
@@ -80,6 +81,50 @@ func MustParse(buf []byte) (x Expr) {
 	return
 }
 
+// ParseStrictString parses a string into an Expr using the strict RFC 9535
+// syntax.
+func ParseStrictString(s string) (x Expr, err error) {
+	return ParseStrict([]byte(s))
+}
+
+// MustParseStrictString parses a string into an Expr using the strict RFC
+// 9535 syntax and panics on error.
+func MustParseStrictString(s string) (x Expr) {
+	return MustParseStrict([]byte(s))
+}
+
+// ParseStrict parses a []byte into an Expr using the strict RFC 9535
+// syntax.
+func ParseStrict(buf []byte) (x Expr, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = ojg.NewError(r)
+		}
+	}()
+	x = MustParseStrict(buf)
+
+	return
+}
+
+// MustParseStrict parses a []byte into an Expr using the strict RFC 9535
+// syntax and panics on error. The expression must start with a $ and the
+// extensions to the standard are not allowed. Those are script
+// expressions such as [(@.x)], the in, empty, has, exists, =~, +, -, *,
+// and / operators, regular expression and array literals, and Nothing. A
+// filter query that is not compared to anything, as in [?@.x], is an
+// existence test as defined by the RFC.
+func MustParseStrict(buf []byte) (x Expr) {
+	p := &parser{buf: buf, strict: true}
+	if len(buf) == 0 || buf[0] != '$' {
+		p.raise("a query must start with a $")
+	}
+	x = p.readExpr()
+	if p.pos < len(buf) {
+		p.raise("parse error")
+	}
+	return
+}
+
 func (p *parser) readExpr() (x Expr) {
 	x = Expr{}
 	var f Frag
@@ -116,6 +161,12 @@ func (p *parser) nextFrag(first, lastDescent bool) (f Frag) {
 			f = p.afterDot()
 		case '*':
 			return Wildcard('*')
+		case '^':
+			if first || p.strict {
+				p.pos--
+			} else {
+				f = Parent('^')
+			}
 		case '[':
 			f = p.afterBracket()
 		case ']':
@@ -213,6 +264,9 @@ func (p *parser) afterBracket() Frag {
 	case '?':
 		return p.readFilter()
 	case '(':
+		if p.strict {
+			p.raise("script expressions are not allowed in strict mode")
+		}
 		return p.readProc()
 	case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
 		var i int
@@ -295,6 +349,13 @@ func (p *parser) readNum(b byte) any {
 	}
 	switch b {
 	case '.':
+		if p.pos+1 < len(p.buf) && p.buf[p.pos+1] == '.' { // a range such as 1..3
+			i, err := strconv.ParseInt(string(num), 10, 64)
+			if err != nil {
+				p.raise("invalid number '%s'", num)
+			}
+			return i
+		}
 		num = append(num, b)
 		p.pos++
 		for p.pos < len(p.buf) {
@@ -552,6 +613,9 @@ func (p *parser) readFilter() *Filter {
 	}
 	eq := precedentCorrect(p.readEq())
 	eq = reduceGroups(eq, nil)
+	if p.strict {
+		eq = existenceTests(eq)
+	}
 	b := p.nextNonSpace()
 	if len(p.buf) <= p.pos || b != ']' {
 		p.raise("not terminated")
@@ -579,6 +643,12 @@ func (p *parser) readProc() *Proc {
 // based on precedent which is contained in the other.
 func (p *parser) readEq() (eq *Equation) {
 	b := p.nextNonSpace()
+	if p.strict {
+		switch b {
+		case '[', '/', 'N':
+			p.raise("'%c' is not allowed in strict mode", b)
+		}
+	}
 	switch b {
 	case '!':
 		p.pos++
@@ -621,6 +691,10 @@ func (p *parser) readEq() (eq *Equation) {
 			eq = &Equation{result: nil}
 		default:
 			if o := opMap[string(token)]; o != nil {
+				if p.strict && !strictFunc(o) {
+					p.pos = before
+					p.raise("'%s' is not allowed in strict mode", token)
+				}
 				eq = p.readOpArgs(o)
 			} else {
 				p.pos = before
@@ -637,6 +711,8 @@ func (p *parser) readEq() (eq *Equation) {
 			return
 		case ']', 0:
 			return
+		case '.': // a range in a list
+			return
 		}
 		eq = &Equation{left: eq, o: p.readEqOp(), right: p.readEq()}
 	}
@@ -661,6 +737,9 @@ func (p *parser) readOpArgs(o *op) (eq *Equation) {
 	}
 	eq = &Equation{o: o}
 	p.pos++
+	if o.varFun != nil {
+		return p.readFuncArgs(o)
+	}
 	eq.left = p.readEq()
 	b := p.nextNonSpace()
 	if b == ',' {
@@ -675,6 +754,32 @@ func (p *parser) readOpArgs(o *op) (eq *Equation) {
 	return
 }
 
+// readFuncArgs reads the arguments to a function registered with
+// RegisterFunc. Each call gets a copy of the op with the argument count.
+func (p *parser) readFuncArgs(o *op) (eq *Equation) {
+	eq = &Equation{}
+	for p.nextNonSpace() != ')' {
+		arg := reduceGroups(precedentCorrect(p.readEq()), nil)
+		eq.args = append(eq.args, arg)
+		switch p.nextNonSpace() {
+		case ',':
+			p.pos++
+		case ')':
+		default:
+			p.raise("not terminated")
+		}
+	}
+	if len(p.buf) <= p.pos {
+		p.raise("not terminated")
+	}
+	p.pos++
+	fo := *o
+	fo.cnt = byte(len(eq.args))
+	eq.o = &fo
+
+	return
+}
+
 func (p *parser) readEqToken(token []byte) {
 	for _, t := range token {
 		if len(p.buf) <= p.pos || p.buf[p.pos] != t {
@@ -689,7 +794,19 @@ func (p *parser) readEqList() (list []any) {
 List:
 	for p.pos < len(p.buf) {
 		eq := p.readEq()
-		list = append(list, eq.result)
+		if p.pos+1 < len(p.buf) && p.buf[p.pos] == '.' && p.buf[p.pos+1] == '.' {
+			p.pos += 2
+			r := Range{Min: eq.result, Max: p.readEq().result}
+			if _, ok := rangeFloat(r.Min); !ok {
+				p.raise("a range must be numeric")
+			}
+			if _, ok := rangeFloat(r.Max); !ok {
+				p.raise("a range must be numeric")
+			}
+			list = append(list, r)
+		} else {
+			list = append(list, eq.result)
+		}
 		b := p.skipSpace()
 		switch b {
 		case ',':
@@ -735,9 +852,48 @@ func (p *parser) readEqOp() (o *op) {
 	if o == nil {
 		p.raise("'%s' is not a valid operation", token)
 	}
+	if p.strict {
+		switch o.code {
+		case eq.code, neq.code, lt.code, gt.code, lte.code, gte.code, or.code, and.code:
+		default:
+			p.raise("'%s' is not allowed in strict mode", token)
+		}
+	}
 	return
 }
 
+// strictFunc returns true if the function is one of the functions defined
+// by RFC 9535 or a registered function extension.
+func strictFunc(o *op) bool {
+	switch o.code {
+	case length.code, count.code, match.code, search.code, valueOp.code, userOpCode:
+		return true
+	}
+	return false
+}
+
+// existenceTests replaces filter queries that are used as logical
+// expressions with existence tests.
+func existenceTests(e *Equation) *Equation {
+	if e == nil {
+		return e
+	}
+	if e.o == nil {
+		if _, ok := e.result.(Expr); ok {
+			return &Equation{o: existsTest, left: e}
+		}
+		return e
+	}
+	switch e.o.code {
+	case not.code, group.code:
+		e.left = existenceTests(e.left)
+	case or.code, and.code:
+		e.left = existenceTests(e.left)
+		e.right = existenceTests(e.right)
+	}
+	return e
+}
+
 func (p *parser) skipSpace() (b byte) {
 	for p.pos < len(p.buf) {
 		b = p.buf[p.pos]
@@ -763,5 +919,5 @@ func (p *parser) nextNonSpace() (b byte) {
 
 func (p *parser) raise(format string, args ...any) {
 	msg := fmt.Sprintf(format, args...)
-	panic(fmt.Errorf("%s at %d in %s", msg, p.pos+1, p.buf))
+	panic(ojg.Errorf(ojg.ErrSyntax, "%s at %d in %s", msg, p.pos+1, p.buf))
 }
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/ohler55/ojg"
 	"github.com/ohler55/ojg/jp"
 	"github.com/ohler55/ojg/tt"
 )
@@ -153,3 +154,11 @@ func BenchmarkParse(b *testing.B) {
 		_ = jp.MustParse([]byte("@.abc.*[2,3]..xyz[2]"))
 	}
 }
+
+func TestParseErrorKind(t *testing.T) {
+	_, err := jp.ParseString("$.a[")
+	tt.ErrorIs(t, err, ojg.ErrSyntax)
+
+	_, err = jp.NewFilter("x")
+	tt.ErrorIs(t, err, ojg.ErrSyntax)
+}
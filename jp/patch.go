@@ -0,0 +1,208 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package jp
+
+import "github.com/ohler55/ojg"
+
+// PatchOp is an RFC 6902 JSON Patch operation. Op is one of "add",
+// "replace", or "remove" and Path is a JSON Pointer (RFC 6901) to the
+// changed value. Value is the value added or the replacement value. It is
+// not a copy so it should be written or copied before the data is
+// modified again.
+type PatchOp struct {
+	Op    string
+	Path  string
+	Value any
+}
+
+// Patch is a list of JSON Patch operations as recorded by the SetRecord,
+// DelRecord, and ModifyRecord functions.
+type Patch []PatchOp
+
+// Simplify returns the operation as a map[string]any suitable for writing
+// as JSON. The value member is only included for add and replace
+// operations.
+func (op PatchOp) Simplify() any {
+	obj := map[string]any{"op": op.Op, "path": op.Path}
+	if op.Op != "remove" {
+		obj["value"] = op.Value
+	}
+	return obj
+}
+
+// Simplify returns the patch as a []any suitable for writing as JSON.
+func (p Patch) Simplify() any {
+	list := make([]any, len(p))
+	for i, op := range p {
+		list[i] = op.Simplify()
+	}
+	return list
+}
+
+// SetRecord is the same as Set except the equivalent JSON Patch operations
+// are appended to the patch. A replace is recorded for each existing value
+// that is set and an add for each value that is added. If the expression
+// causes missing objects or arrays to be created the add is for the first
+// created value.
+func (x Expr) SetRecord(data, value any, patch *Patch) error {
+	return x.setRecord(data, value, false, patch)
+}
+
+// SetOneRecord is the same as SetOne except the equivalent JSON Patch
+// operation is appended to the patch.
+func (x Expr) SetOneRecord(data, value any, patch *Patch) error {
+	return x.setRecord(data, value, true, patch)
+}
+
+// DelRecord is the same as Del except the equivalent JSON Patch operations
+// are appended to the patch. Since Del replaces array elements with nil a
+// replace with null is recorded for an array element and a remove for an
+// object member.
+func (x Expr) DelRecord(data any, patch *Patch) error {
+	return x.delRecord(data, false, patch)
+}
+
+// DelOneRecord is the same as DelOne except the equivalent JSON Patch
+// operation is appended to the patch.
+func (x Expr) DelOneRecord(data any, patch *Patch) error {
+	return x.delRecord(data, true, patch)
+}
+
+// ModifyRecord is the same as Modify except a JSON Patch replace operation
+// is appended to the patch for each value changed by the modifier.
+func (x Expr) ModifyRecord(
+	data any,
+	modifier func(element any) (altered any, changed bool),
+	patch *Patch) (any, error) {
+
+	return x.modifyRecord(data, modifier, false, patch)
+}
+
+// ModifyOneRecord is the same as ModifyOne except a JSON Patch replace
+// operation is appended to the patch if the value is changed.
+func (x Expr) ModifyOneRecord(
+	data any,
+	modifier func(element any) (altered any, changed bool),
+	patch *Patch) (any, error) {
+
+	return x.modifyRecord(data, modifier, true, patch)
+}
+
+func (x Expr) setRecord(data, value any, one bool, patch *Patch) error {
+	max := 0
+	if one {
+		max = 1
+	}
+	before := x.Locate(data, max)
+	var created Expr
+	if len(before) == 0 && x.definite() {
+		created = x.firstMissing(data)
+	}
+	if err := x.set(data, value, "set", one); err != nil {
+		return err
+	}
+	existed := map[string]bool{}
+	for _, loc := range before {
+		existed[loc.String()] = true
+		*patch = append(*patch, PatchOp{Op: "replace", Path: pointer(loc), Value: value})
+	}
+	if created != nil {
+		if v, has := created.FirstFound(data); has {
+			*patch = append(*patch, PatchOp{Op: "add", Path: pointer(created), Value: v})
+		}
+		return nil
+	}
+	if one && 0 < len(before) {
+		return nil
+	}
+	for _, loc := range x.Locate(data, max) {
+		if !existed[loc.String()] {
+			*patch = append(*patch, PatchOp{Op: "add", Path: pointer(loc), Value: value})
+		}
+	}
+	return nil
+}
+
+func (x Expr) delRecord(data any, one bool, patch *Patch) error {
+	max := 0
+	if one {
+		max = 1
+	}
+	before := x.Locate(data, max)
+	if err := x.set(data, delFlag, "delete", one); err != nil {
+		return err
+	}
+	for _, loc := range before {
+		if _, ok := loc[len(loc)-1].(Nth); ok {
+			*patch = append(*patch, PatchOp{Op: "replace", Path: pointer(loc)})
+		} else {
+			*patch = append(*patch, PatchOp{Op: "remove", Path: pointer(loc)})
+		}
+	}
+	return nil
+}
+
+func (x Expr) modifyRecord(
+	data any,
+	modifier func(element any) (altered any, changed bool),
+	one bool,
+	patch *Patch) (result any, err error) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = ojg.NewError(r)
+		}
+	}()
+	result = data
+	for _, loc := range x.Locate(data, 0) {
+		var (
+			altered any
+			changed bool
+		)
+		result = loc.modify(result, func(element any) (any, bool) {
+			altered, changed = modifier(element)
+			return altered, changed
+		}, true)
+		if changed {
+			*patch = append(*patch, PatchOp{Op: "replace", Path: pointer(loc), Value: altered})
+			if one {
+				break
+			}
+		}
+	}
+	return
+}
+
+// definite returns true if the expression identifies at most one location.
+func (x Expr) definite() bool {
+	for _, f := range x {
+		switch f.(type) {
+		case Root, At, Bracket, Child, Nth:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// firstMissing returns the shortest leading part of a definite expression
+// that does not exist in the data.
+func (x Expr) firstMissing(data any) Expr {
+	for i := 1; i <= len(x); i++ {
+		switch x[i-1].(type) {
+		case Child, Nth:
+			if !x[:i].Has(data) {
+				return x[:i]
+			}
+		}
+	}
+	return nil
+}
+
+// pointer returns the JSON Pointer for a normalized location. Normalized
+// locations only contain Root, Child, and non-negative Nth fragments so
+// Pointer never fails for them.
+func pointer(loc Expr) string {
+	p, _ := loc.Pointer()
+	return p
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package jp_test
+
+import (
+	"testing"
+
+	"github.com/ohler55/ojg/jp"
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/tt"
+)
+
+func TestSetRecord(t *testing.T) {
+	data := oj.MustParseString(`{"a":[{"b":1},{"b":2},{"c":3}],"x/y":{"m~n":1}}`)
+	var patch jp.Patch
+	tt.Nil(t, jp.MustParseString("$.a[*].b").SetRecord(data, 5, &patch))
+	tt.Nil(t, jp.MustParseString("$.d.e").SetRecord(data, true, &patch))
+	tt.Nil(t, jp.MustParseString("$['x/y']['m~n']").SetOneRecord(data, 2, &patch))
+	tt.Equal(t,
+		`[{"op":"replace","path":"/a/0/b","value":5},`+
+			`{"op":"replace","path":"/a/1/b","value":5},`+
+			`{"op":"add","path":"/a/2/b","value":5},`+
+			`{"op":"add","path":"/d","value":{"e":true}},`+
+			`{"op":"replace","path":"/x~1y/m~0n","value":2}]`,
+		oj.JSON(patch, &oj.Options{Sort: true}))
+	tt.Equal(t, `{"a":[{"b":5},{"b":5},{"b":5,"c":3}],"d":{"e":true},"x/y":{"m~n":2}}`, oj.JSON(data, &oj.Options{Sort: true}))
+}
+
+func TestDelRecord(t *testing.T) {
+	data := oj.MustParseString(`{"a":[1,2,3],"b":{"c":1,"d":2}}`)
+	var patch jp.Patch
+	tt.Nil(t, jp.MustParseString("$.a[1]").DelRecord(data, &patch))
+	tt.Nil(t, jp.MustParseString("$.b.*").DelOneRecord(data, &patch))
+	tt.Equal(t, 2, len(patch))
+	tt.Equal(t, `{"op":"replace","path":"/a/1","value":null}`, oj.JSON(patch[0], &oj.Options{Sort: true}))
+	tt.Equal(t, "remove", patch[1].Op)
+	tt.Equal(t, `{"op":"remove","path":"`+patch[1].Path+`"}`, oj.JSON(patch[1], &oj.Options{Sort: true}))
+}
+
+func TestModifyRecord(t *testing.T) {
+	data := oj.MustParseString(`{"a":[1,2,3,4]}`)
+	var patch jp.Patch
+	double := func(element any) (any, bool) {
+		if n, ok := element.(int64); ok && n%2 == 0 {
+			return n * 10, true
+		}
+		return element, false
+	}
+	result, err := jp.MustParseString("$.a[*]").ModifyRecord(data, double, &patch)
+	tt.Nil(t, err)
+	tt.Equal(t, `{"a":[1,20,3,40]}`, oj.JSON(result))
+	tt.Equal(t, `[{"op":"replace","path":"/a/1","value":20},{"op":"replace","path":"/a/3","value":40}]`,
+		oj.JSON(patch, &oj.Options{Sort: true}))
+
+	patch = patch[:0]
+	_, err = jp.MustParseString("$.a[*]").ModifyOneRecord(data, func(element any) (any, bool) {
+		return element.(int64) + 1, true
+	}, &patch)
+	tt.Nil(t, err)
+	tt.Equal(t, `[{"op":"replace","path":"/a/0","value":2}]`, oj.JSON(patch, &oj.Options{Sort: true}))
+}
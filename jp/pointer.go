@@ -0,0 +1,132 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package jp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ohler55/ojg"
+)
+
+var (
+	pointerEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+// ParsePointer parses an RFC 6901 JSON Pointer such as /a/b/0 into an
+// Expr that starts with a root. An empty pointer is the root. A reference
+// token that is a non-negative integer without leading zeros becomes an
+// Nth fragment and any other token becomes a Child fragment so an object
+// member with a name such as "0" can not be reached with an expression
+// from a pointer. A URI fragment pointer that starts with # is also
+// accepted.
+func ParsePointer(ptr string) (x Expr, err error) {
+	if strings.HasPrefix(ptr, "#") {
+		s, err := unescapeURIFragment(ptr[1:])
+		if err != nil {
+			return nil, err
+		}
+		ptr = s
+	}
+	x = Expr{Root('$')}
+	if len(ptr) == 0 {
+		return
+	}
+	if ptr[0] != '/' {
+		return nil, ojg.Errorf(ojg.ErrSyntax, "a JSON Pointer must start with a '/' in %q", ptr)
+	}
+	for _, token := range strings.Split(ptr[1:], "/") {
+		for i := 0; i < len(token); i++ {
+			if token[i] == '~' && (len(token) <= i+1 || (token[i+1] != '0' && token[i+1] != '1')) {
+				return nil, ojg.Errorf(ojg.ErrSyntax, "invalid escape in JSON Pointer %q", ptr)
+			}
+		}
+		if pointerIndex(token) {
+			if i, err := strconv.Atoi(token); err == nil {
+				x = append(x, Nth(i))
+				continue
+			}
+		}
+		x = append(x, Child(pointerUnescaper.Replace(token)))
+	}
+	return
+}
+
+// MustParsePointer parses an RFC 6901 JSON Pointer into an Expr and panics
+// on error.
+func MustParsePointer(ptr string) Expr {
+	x, err := ParsePointer(ptr)
+	if err != nil {
+		panic(err)
+	}
+	return x
+}
+
+// Pointer returns the RFC 6901 JSON Pointer for the expression. Only
+// expressions made up of child and non-negative nth fragments after an
+// optional root or at can be represented as a pointer. An error is
+// returned for any other expression.
+func (x Expr) Pointer() (string, error) {
+	var b strings.Builder
+	for i, f := range x {
+		switch tf := f.(type) {
+		case Root, At:
+			if i != 0 {
+				return "", fmt.Errorf("can not represent %s as a JSON Pointer", x)
+			}
+		case Bracket:
+			// only affects the string representation
+		case Child:
+			b.WriteByte('/')
+			b.WriteString(pointerEscaper.Replace(string(tf)))
+		case Nth:
+			if tf < 0 {
+				return "", fmt.Errorf("can not represent %s as a JSON Pointer", x)
+			}
+			b.WriteByte('/')
+			b.WriteString(strconv.Itoa(int(tf)))
+		default:
+			return "", fmt.Errorf("can not represent %s as a JSON Pointer", x)
+		}
+	}
+	return b.String(), nil
+}
+
+// pointerIndex returns true if the token is an array index as described by
+// RFC 6901.
+func pointerIndex(token string) bool {
+	if len(token) == 0 || (token[0] == '0' && 1 < len(token)) {
+		return false
+	}
+	for _, b := range []byte(token) {
+		if b < '0' || '9' < b {
+			return false
+		}
+	}
+	return true
+}
+
+func unescapeURIFragment(s string) (string, error) {
+	if !strings.Contains(s, "%") {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if len(s) <= i+2 {
+			return "", ojg.Errorf(ojg.ErrSyntax, "invalid percent encoding in JSON Pointer %q", s)
+		}
+		v, err := strconv.ParseUint(s[i+1:i+3], 16, 8)
+		if err != nil {
+			return "", ojg.Errorf(ojg.ErrSyntax, "invalid percent encoding in JSON Pointer %q", s)
+		}
+		b.WriteByte(byte(v))
+		i += 2
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package jp_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/jp"
+	"github.com/ohler55/ojg/tt"
+)
+
+func TestParsePointer(t *testing.T) {
+	for _, c := range []struct {
+		ptr    string
+		expect jp.Expr
+	}{
+		{ptr: "", expect: jp.R()},
+		{ptr: "/", expect: jp.R().C("")},
+		{ptr: "/a/b/0", expect: jp.R().C("a").C("b").N(0)},
+		{ptr: "/a~1b/m~0n/01/-", expect: jp.R().C("a/b").C("m~n").C("01").C("-")},
+		{ptr: "/c%d/12", expect: jp.R().C("c%d").N(12)},
+		{ptr: "#/c%25d/%20", expect: jp.R().C("c%d").C(" ")},
+		{ptr: "#", expect: jp.R()},
+	} {
+		x, err := jp.ParsePointer(c.ptr)
+		tt.Nil(t, err, c.ptr)
+		tt.Equal(t, c.expect, x, c.ptr)
+	}
+	for _, ptr := range []string{"a/b", "/a~2", "/a~", "#/a%2", "#/%zz"} {
+		_, err := jp.ParsePointer(ptr)
+		tt.Equal(t, true, errors.Is(err, ojg.ErrSyntax), ptr)
+	}
+	tt.Panic(t, func() { jp.MustParsePointer("x") })
+
+	data := map[string]any{"a": []any{1, map[string]any{"b/c": 2}}}
+	tt.Equal(t, 2, jp.MustParsePointer("/a/1/b~1c").First(data))
+}
+
+func TestExprPointer(t *testing.T) {
+	for _, c := range []struct {
+		x      jp.Expr
+		expect string
+	}{
+		{x: jp.R(), expect: ""},
+		{x: jp.R().C("a").N(3).C("m~n/o"), expect: "/a/3/m~0n~1o"},
+		{x: jp.C("a").B().C("b"), expect: "/a/b"},
+		{x: jp.MustParseString("@.x[0]"), expect: "/x/0"},
+	} {
+		ptr, err := c.x.Pointer()
+		tt.Nil(t, err, c.x)
+		tt.Equal(t, c.expect, ptr, c.x)
+		back, err := jp.MustParsePointer(ptr).Pointer()
+		tt.Nil(t, err)
+		tt.Equal(t, ptr, back)
+	}
+	for _, x := range []jp.Expr{
+		jp.MustParseString("$.a[*]"),
+		jp.MustParseString("$..a"),
+		jp.R().N(-1),
+		jp.R().C("a").R(),
+	} {
+		_, err := x.Pointer()
+		tt.NotNil(t, err, x)
+	}
+}
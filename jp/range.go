@@ -0,0 +1,75 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package jp
+
+import "strconv"
+
+// Range is an inclusive numeric range that can be a member of the list
+// used with the in operator such as [?(@.status in [200,400..499])]. The Min
+// and Max should be int64 or float64 values.
+type Range struct {
+	Min any
+	Max any
+}
+
+// Has returns true if the value is a number between Min and Max inclusive.
+func (r Range) Has(v any) bool {
+	f, ok := rangeFloat(v)
+	if !ok {
+		return false
+	}
+	lo, lok := rangeFloat(r.Min)
+	hi, hok := rangeFloat(r.Max)
+
+	return lok && hok && lo <= f && f <= hi
+}
+
+func (r Range) append(buf []byte) []byte {
+	buf = appendRangeNum(buf, r.Min)
+	buf = append(buf, ".."...)
+
+	return appendRangeNum(buf, r.Max)
+}
+
+func appendRangeNum(buf []byte, v any) []byte {
+	switch tv := normalize(v).(type) {
+	case int64:
+		buf = strconv.AppendInt(buf, tv, 10)
+	case float64:
+		buf = strconv.AppendFloat(buf, tv, 'g', -1, 64)
+	}
+	return buf
+}
+
+func rangeFloat(v any) (float64, bool) {
+	switch tv := normalize(v).(type) {
+	case int64:
+		return float64(tv), true
+	case float64:
+		return tv, true
+	}
+	return 0.0, false
+}
+
+// inList returns true if the value is a member of the list.
+func inList(v any, list []any) bool {
+	for _, ev := range list {
+		switch tev := ev.(type) {
+		case Range:
+			if tev.Has(v) {
+				return true
+			}
+		case int64, float64:
+			if f, ok := rangeFloat(v); ok {
+				if ef, _ := rangeFloat(tev); f == ef {
+					return true
+				}
+			}
+		default:
+			if v == ev {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package jp
+
+import (
+	"sort"
+
+	"github.com/ohler55/ojg/gen"
+)
+
+// removed marks an element that a modifier returned Nothing for. It is a
+// gen.Node so that it can be placed in gen.Object and gen.Array values
+// until the marked elements are removed from their parents.
+type removed struct{}
+
+func (removed) String() string  { return "" }
+func (removed) Alter() any      { return nil }
+func (removed) Simplify() any   { return nil }
+func (r removed) Dup() gen.Node { return r }
+func (removed) Empty() bool     { return true }
+
+// modifyRemoving calls modify after replacing a Nothing returned by the
+// modifier with a removed marker and then removes the marked elements. The
+// locations that match are collected before modifying so the marked
+// elements are removed by index or key and not by matching the expression
+// again against the modified data.
+func (x Expr) modifyRemoving(
+	data any,
+	modifier func(element any) (altered any, changed bool),
+	one bool) any {
+
+	locs := x.Locate(data, 0)
+	var marked bool
+	data = x.modify(data, func(element any) (any, bool) {
+		altered, changed := modifier(element)
+		if changed && altered == Nothing {
+			marked = true
+			return removed{}, true
+		}
+		return altered, changed
+	}, one)
+	if !marked {
+		return data
+	}
+	if _, ok := data.(removed); ok {
+		return nil
+	}
+	// Remove the last first so the indexes of the locations not yet removed
+	// do not shift.
+	sort.Slice(locs, func(i, j int) bool { return locationLess(locs[j], locs[i]) })
+	for _, loc := range locs {
+		if v := loc.First(data); v == (removed{}) {
+			data = loc.MustRemoveOne(data)
+		}
+	}
+	return data
+}
+
+// locationLess returns true if location a is before location b in
+// document order with array elements ordered by index and object members
+// by key.
+func locationLess(a, b Expr) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		switch ta := a[i].(type) {
+		case Nth:
+			if tb, ok := b[i].(Nth); ok && ta != tb {
+				return ta < tb
+			}
+		case Child:
+			if tb, ok := b[i].(Child); ok && ta != tb {
+				return ta < tb
+			}
+		}
+	}
+	return len(a) < len(b)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -44,6 +45,13 @@ var (
 	count  = &op{prec: 0, code: 'C', name: "count", cnt: 1, getLeft: true}
 	match  = &op{prec: 0, code: 'M', name: "match", cnt: 2}
 	search = &op{prec: 0, code: 'S', name: "search", cnt: 2}
+	// valueOp is the value() function.
+	valueOp = &op{prec: 0, code: 'V', name: "value", cnt: 1, getLeft: true}
+
+	// existsTest is an RFC 9535 existence test for a filter query that is
+	// not compared to anything. It is written as just the query and should
+	// not be in the opMap.
+	existsTest = &op{prec: 0, code: 'E', name: "exists", cnt: 1}
 
 	// group is for an equation inside () so it represents the (). It should
 	// not be in the opMap.
@@ -74,6 +82,8 @@ var (
 		count.name:  count,
 		match.name:  match,
 		search.name: search,
+
+		valueOp.name: valueOp,
 	}
 	// Nothing can be used in scripts to indicate no value as in a script such
 	// as [?(@.x == Nothing)] this indicates there was no value as @.x. It is
@@ -85,6 +95,7 @@ type op struct {
 	name     string
 	uniFun   func(arg any) any
 	duoFun   func(left, right any) any
+	varFun   func(args ...any) any
 	prec     byte
 	cnt      byte
 	code     byte
@@ -146,7 +157,11 @@ func (s *Script) Append(buf []byte) []byte {
 			if 2 < len(bstack)-i {
 				right = bstack[i+2]
 			}
-			bstack[i] = s.appendOp(o, left, right)
+			if o.varFun != nil {
+				bstack[i] = s.appendFunc(o, bstack[i+1:i+1+int(o.cnt)])
+			} else {
+				bstack[i] = s.appendOp(o, left, right)
+			}
 			if i+int(o.cnt)+1 <= len(bstack) {
 				copy(bstack[i+1:], bstack[i+int(o.cnt)+1:])
 			}
@@ -202,8 +217,13 @@ func (s *Script) evalWithRoot(stack, data, root any) (any, Expr) {
 	case map[string]any:
 		dlen = len(td)
 		da := make([]any, 0, dlen)
-		for k, v := range td {
-			da = append(da, v)
+		keys := make([]string, 0, len(td))
+		for k := range td {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			da = append(da, td[k])
 			locKeys = append(locKeys, Child(k))
 		}
 		data = da
@@ -221,8 +241,13 @@ func (s *Script) evalWithRoot(stack, data, root any) (any, Expr) {
 	case gen.Object:
 		dlen = len(td)
 		da := make(gen.Array, 0, dlen)
-		for k, v := range td {
-			da = append(da, v)
+		keys := make([]string, 0, len(td))
+		for k := range td {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			da = append(da, td[k])
 			locKeys = append(locKeys, Child(k))
 		}
 		data = da
@@ -259,7 +284,11 @@ func (s *Script) evalWithRoot(stack, data, root any) (any, Expr) {
 				if o, ok := sstack[i-1].(*op); ok && o.getLeft {
 					var x Expr
 					if x, ok = ev.(Expr); ok {
-						ev = x.Get(v)
+						if _, ok = x[0].(Root); ok {
+							ev = x.Get(root)
+						} else {
+							ev = x.Get(v)
+						}
 					} else {
 						ev = nil
 					}
@@ -681,12 +710,7 @@ func evalStack(sstack []any) []any {
 		case in.code:
 			sstack[i] = false
 			if list, ok := right.([]any); ok {
-				for _, ev := range list {
-					if left == ev {
-						sstack[i] = true
-						break
-					}
-				}
+				sstack[i] = inList(left, list)
 			}
 		case empty.code:
 			sstack[i] = false
@@ -734,6 +758,13 @@ func evalStack(sstack []any) []any {
 			if nl, ok := left.([]any); ok {
 				sstack[i] = int64(len(nl))
 			}
+		case valueOp.code:
+			sstack[i] = Nothing
+			if nl, ok := left.([]any); ok && len(nl) == 1 {
+				sstack[i] = normalize(nl[0])
+			}
+		case existsTest.code:
+			sstack[i] = left != Nothing
 		case match.code:
 			sstack[i] = Nothing
 			if ls, ok := left.(string); ok {
@@ -759,10 +790,15 @@ func evalStack(sstack []any) []any {
 				}
 			}
 		default:
-			if o.uniFun != nil {
+			switch {
+			case o.uniFun != nil:
 				sstack[i] = o.uniFun(left)
-			} else if o.duoFun != nil {
+			case o.duoFun != nil:
 				sstack[i] = o.duoFun(left, right)
+			case o.varFun != nil:
+				args := make([]any, o.cnt)
+				copy(args, sstack[i+1:])
+				sstack[i] = o.varFun(args...)
 			}
 		}
 		if i+int(o.cnt)+1 <= len(sstack) {
@@ -800,9 +836,9 @@ func (s *Script) appendOp(o *op, left, right any) (pb *precBuf) {
 	case not.code:
 		pb.buf = append(pb.buf, o.name...)
 		pb.buf = s.appendValue(pb.buf, left, o.prec)
-	case group.code:
+	case group.code, existsTest.code:
 		pb.buf = s.appendValue(pb.buf, left, o.prec)
-	case length.code, count.code:
+	case length.code, count.code, valueOp.code:
 		pb.buf = append(pb.buf, o.name...)
 		pb.buf = append(pb.buf, '(')
 		pb.buf = s.appendValue(pb.buf, left, o.prec)
@@ -833,6 +869,23 @@ func (s *Script) appendOp(o *op, left, right any) (pb *precBuf) {
 	return
 }
 
+// appendFunc appends a function registered with RegisterFunc and the
+// arguments to the function.
+func (s *Script) appendFunc(o *op, args []any) (pb *precBuf) {
+	pb = &precBuf{prec: o.prec}
+	pb.buf = append(pb.buf, o.name...)
+	pb.buf = append(pb.buf, '(')
+	for i, arg := range args {
+		if 0 < i {
+			pb.buf = append(pb.buf, ',', ' ')
+		}
+		pb.buf = s.appendValue(pb.buf, arg, o.prec)
+	}
+	pb.buf = append(pb.buf, ')')
+
+	return
+}
+
 func (s *Script) appendValue(buf []byte, v any, prec byte) []byte {
 	switch tv := v.(type) {
 	case nil:
@@ -862,6 +915,8 @@ func (s *Script) appendValue(buf []byte, v any, prec byte) []byte {
 		buf = append(buf, ']')
 	case Expr:
 		buf = tv.Append(buf)
+	case Range:
+		buf = tv.append(buf)
 	case *regexp.Regexp:
 		buf = AppendString(buf, tv.String(), '/')
 	case *precBuf:
@@ -901,6 +956,7 @@ var builtInNames = map[string]bool{
 	"count":  true,
 	"match":  true,
 	"search": true,
+	"value":  true,
 	"true":   true,
 	"false":  true,
 	"null":   true,
@@ -942,3 +998,21 @@ func RegisterBinaryFunction(name string, getLeft, getRight bool, f func(left, ri
 		getRight: getRight,
 	}
 }
+
+// RegisterFunc registers a function for scripts that takes any number of
+// arguments as in [?custom(@.a, @.b, 3)]. Each argument is evaluated
+// before the function is called and a path argument provides the first
+// match or Nothing if there is no match. The function should return a
+// bool to be used as a filter test or a value that can be compared or
+// passed to other functions. Names must be alpha characters only.
+func RegisterFunc(name string, f func(args ...any) any) {
+	name = strings.ToLower(name)
+	if builtInNames[name] {
+		panic(fmt.Errorf("operation %s can not be replaced", name))
+	}
+	opMap[name] = &op{
+		name:   name,
+		varFun: f,
+		code:   userOpCode,
+	}
+}
@@ -137,6 +137,9 @@ func TestScriptParse(t *testing.T) {
 		{src: "(@[1:5} == 3)", err: "invalid slice syntax at 8 in (@[1:5} == 3)"},
 		{src: "(@ =~ /a[c/)", err: "error parsing regexp: missing closing ]: `[c` at 12 in (@ =~ /a[c/)"},
 		{src: "@.x in [1,2,3", err: "expected a comma or an array close at 14 in @.x in [1,2,3"},
+		{src: "(@.x in [200,400..499])", expect: "(@.x in [200,400..499])"},
+		{src: "(@.x in [1.5 .. 2.5, 'a'])", expect: "(@.x in [1.5..2.5,'a'])"},
+		{src: "(@.x in [1..'a'])", err: "a range must be numeric at 16 in (@.x in [1..'a'])"},
 
 		{src: "((($.x == 'abc')))", expect: "($.x == 'abc')"},
 	} {
@@ -330,6 +333,19 @@ func TestScriptEval(t *testing.T) {
 		{src: "(match(@.x, 'ab.'))", value: map[string]any{"x": "abc"}},
 		{src: "(match(@.x, 'ab'))", value: map[string]any{"x": "abc"}, noMatch: true},
 
+		{src: "(@ in [400,404,410])", value: int64(404)},
+		{src: "(@ in [400,404,410])", value: 404.0},
+		{src: "(@ in [400,404,410])", value: int64(405), noMatch: true},
+		{src: "(@ in [400.0,404])", value: int64(400)},
+		{src: "(@ in [200,400..499])", value: int64(451)},
+		{src: "(@ in [200,400..499])", value: int64(499)},
+		{src: "(@ in [200,400..499])", value: 400.5},
+		{src: "(@ in [200,400..499])", value: int64(500), noMatch: true},
+		{src: "(@ in [200,400..499])", value: "451", noMatch: true},
+		{src: "(@ in [0.5..1.5])", value: int64(1)},
+		{src: "(@ in ['a','b'])", value: "b"},
+		{src: "(@.status in [400..499])", value: map[string]any{"status": 404}},
+
 		{src: "(search(@.x, 'ab'))", value: map[string]any{"x": "abc"}},
 		{src: "(search(@.x, 'abx'))", value: map[string]any{"x": "abc"}, noMatch: true},
 	} {
@@ -428,3 +444,32 @@ func TestScriptRegisterBinaryFunction(t *testing.T) {
 
 	tt.Panic(t, func() { jp.RegisterBinaryFunction("length", false, false, func(left, right any) any { return nil }) })
 }
+
+func TestScriptRegisterFunc(t *testing.T) {
+	jp.RegisterFunc("between", func(args ...any) any {
+		if len(args) != 3 {
+			return false
+		}
+		v, _ := args[0].(int64)
+		lo, _ := args[1].(int64)
+		hi, _ := args[2].(int64)
+		return lo <= v && v <= hi
+	})
+	jp.RegisterFunc("zero", func(args ...any) any { return int64(len(args)) })
+
+	s := jp.MustNewScript("between(@.x, 2, @.max)")
+	tt.Equal(t, "(between(@.x, 2, @.max))", s.String())
+	data := []any{
+		map[string]any{"x": 1, "max": 5},
+		map[string]any{"x": 3, "max": 5},
+		map[string]any{"x": 4, "max": 3},
+	}
+	tt.Equal(t, []any{data[1]}, s.Eval([]any{}, data))
+
+	x := jp.MustParseString("$[?(between(@.x, 1, 4) && zero() == 0)]")
+	tt.Equal(t, "$[?(between(@.x, 1, 4) && zero() == 0)]", x.String())
+	tt.Equal(t, 3, len(x.Get(data)))
+
+	x = jp.MustParseStrictString("$[?between(@.x, 1, 2)]")
+	tt.Equal(t, 1, len(x.Get(data)))
+}
@@ -7,6 +7,7 @@ import (
 	"reflect"
 	"strings"
 
+	"github.com/ohler55/ojg"
 	"github.com/ohler55/ojg/alt"
 	"github.com/ohler55/ojg/gen"
 )
@@ -72,6 +73,9 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 	if len(x) == 0 {
 		return fmt.Errorf("can not %s with an empty expression", fun)
 	}
+	if x.hasParent() {
+		return fmt.Errorf("can not %s with an expression that includes a parent", fun)
+	}
 	switch x[len(x)-1].(type) {
 	case Root, At, Bracket, Descent, Slice, *Filter:
 		ta := strings.Split(fmt.Sprintf("%T", x[len(x)-1]), ".")
@@ -129,7 +133,7 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 					switch v.(type) {
 					case nil, gen.Bool, gen.Int, gen.Float, gen.String,
 						bool, string, float64, float32, int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64:
-						return fmt.Errorf("can not follow a %T at '%s'", v, x[:fi+1])
+						return followError(v, x[:fi+1])
 					case map[string]any, []any, gen.Object, gen.Array, Keyed, Indexed:
 						stack = append(stack, v)
 					default:
@@ -141,7 +145,7 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 						case reflect.Ptr, reflect.Slice, reflect.Struct, reflect.Array, reflect.Map:
 							stack = append(stack, v)
 						default:
-							return fmt.Errorf("can not follow a %T at '%s'", v, x[:fi+1])
+							return followError(v, x[:fi+1])
 						}
 					}
 				} else if value != delFlag {
@@ -175,7 +179,7 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 					switch v.(type) {
 					case nil, gen.Bool, gen.Int, gen.Float, gen.String,
 						bool, string, float64, float32, int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64:
-						return fmt.Errorf("can not follow a %T at '%s'", v, x[:fi+1])
+						return followError(v, x[:fi+1])
 					case map[string]any, []any, gen.Object, gen.Array, Keyed, Indexed:
 						stack = append(stack, v)
 					default:
@@ -187,7 +191,7 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 						case reflect.Ptr, reflect.Slice, reflect.Struct, reflect.Array, reflect.Map:
 							stack = append(stack, v)
 						default:
-							return fmt.Errorf("can not follow a %T at '%s'", v, x[:fi+1])
+							return followError(v, x[:fi+1])
 						}
 					}
 				} else if value != delFlag {
@@ -222,7 +226,7 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 					case gen.Object, gen.Array:
 						stack = append(stack, v)
 					default:
-						return fmt.Errorf("can not follow a %T at '%s'", v, x[:fi+1])
+						return followError(v, x[:fi+1])
 					}
 				} else if value != delFlag {
 					switch tc := x[fi+1].(type) {
@@ -252,7 +256,7 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 					switch v.(type) {
 					case nil, gen.Bool, gen.Int, gen.Float, gen.String,
 						bool, string, float64, float32, int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64:
-						return fmt.Errorf("can not follow a %T at '%s'", v, x[:fi+1])
+						return followError(v, x[:fi+1])
 					case map[string]any, []any, gen.Object, gen.Array, Keyed, Indexed:
 						stack = append(stack, v)
 					default:
@@ -264,7 +268,7 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 						case reflect.Ptr, reflect.Slice, reflect.Struct, reflect.Array, reflect.Map:
 							stack = append(stack, v)
 						default:
-							return fmt.Errorf("can not follow a %T at '%s'", v, x[:fi+1])
+							return followError(v, x[:fi+1])
 						}
 					}
 				}
@@ -291,7 +295,7 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 						switch v.(type) {
 						case bool, string, float64, float32, int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64,
 							nil, gen.Bool, gen.Int, gen.Float, gen.String:
-							return fmt.Errorf("can not follow a %T at '%s'", v, x[:fi+1])
+							return followError(v, x[:fi+1])
 						case map[string]any, []any, gen.Object, gen.Array, Keyed, Indexed:
 							stack = append(stack, v)
 						default:
@@ -303,7 +307,7 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 							case reflect.Ptr, reflect.Slice, reflect.Struct, reflect.Array, reflect.Map:
 								stack = append(stack, v)
 							default:
-								return fmt.Errorf("can not follow a %T at '%s'", v, x[:fi+1])
+								return followError(v, x[:fi+1])
 							}
 						}
 					}
@@ -330,7 +334,7 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 						switch v.(type) {
 						case bool, string, float64, float32, int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64,
 							nil, gen.Bool, gen.Int, gen.Float, gen.String:
-							return fmt.Errorf("can not follow a %T at '%s'", v, x[:fi+1])
+							return followError(v, x[:fi+1])
 						case map[string]any, []any, gen.Object, gen.Array, Keyed, Indexed:
 							stack = append(stack, v)
 						default:
@@ -342,7 +346,7 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 							case reflect.Ptr, reflect.Slice, reflect.Struct, reflect.Array, reflect.Map:
 								stack = append(stack, v)
 							default:
-								return fmt.Errorf("can not follow a %T at '%s'", v, x[:fi+1])
+								return followError(v, x[:fi+1])
 							}
 						}
 					}
@@ -369,7 +373,7 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 						case gen.Object, gen.Array:
 							stack = append(stack, v)
 						default:
-							return fmt.Errorf("can not follow a %T at '%s'", v, x[:fi+1])
+							return followError(v, x[:fi+1])
 						}
 					}
 				} else {
@@ -387,7 +391,7 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 					switch v.(type) {
 					case bool, string, float64, float32, int, uint, int8, int16, int32, int64, uint8, uint16, uint32, uint64,
 						nil, gen.Bool, gen.Int, gen.Float, gen.String:
-						return fmt.Errorf("can not follow a %T at '%s'", v, x[:fi+1])
+						return followError(v, x[:fi+1])
 					case map[string]any, []any, gen.Object, gen.Array, Keyed, Indexed:
 						stack = append(stack, v)
 					default:
@@ -399,7 +403,7 @@ func (x Expr) set(data, value any, fun string, one bool) error {
 						case reflect.Ptr, reflect.Slice, reflect.Struct, reflect.Array, reflect.Map:
 							stack = append(stack, v)
 						default:
-							return fmt.Errorf("can not follow a %T at '%s'", v, x[:fi+1])
+							return followError(v, x[:fi+1])
 						}
 					}
 				}
@@ -1222,3 +1226,7 @@ func reflectSetNth(data any, i int, v any) bool {
 	}
 	return false
 }
+
+func followError(v any, x Expr) error {
+	return &ojg.ErrTypeMismatch{Path: x.String(), Message: fmt.Sprintf("can not follow a %T", v)}
+}
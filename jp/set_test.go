@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/ohler55/ojg"
 	"github.com/ohler55/ojg/gen"
 	"github.com/ohler55/ojg/jp"
 	"github.com/ohler55/ojg/oj"
@@ -908,3 +909,11 @@ func TestSetStructPtrNil(t *testing.T) {
 	jp.C("b").Set(&data, nil)
 	tt.Nil(t, data.B)
 }
+
+func TestExprSetTypeMismatch(t *testing.T) {
+	err := jp.C("a").C("b").Set(map[string]any{"a": 4}, 3)
+	var tm *ojg.ErrTypeMismatch
+	tt.ErrorAs(t, err, &tm)
+	tt.Equal(t, "a", tm.Path)
+	tt.Equal(t, "can not follow a int at 'a'", err.Error())
+}
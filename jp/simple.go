@@ -0,0 +1,89 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package jp
+
+// SimpleGet returns the elements of the data identified by the path in the
+// same way as Get but it assumes the data only contains the simple types
+// returned by the oj parser so arrays must be []any and objects must be
+// map[string]any. Other types such as structs or gen.Node are not looked
+// inside. That avoids the type checks and reflection Get uses to support
+// any data and makes SimpleGet faster in hot loops over parser output.
+//
+// The Root, At, Child, Nth, Wildcard, and Union fragments are evaluated
+// directly. When any other fragment is reached the rest of the expression
+// is evaluated with Get.
+func (x Expr) SimpleGet(data any) []any {
+	if len(x) == 0 {
+		return nil
+	}
+	return x.simpleGet(data, nil)
+}
+
+func (x Expr) simpleGet(v any, results []any) []any {
+	for i, f := range x {
+		switch tf := f.(type) {
+		case Root, At, Bracket:
+			// No change to the value.
+		case Child:
+			obj, ok := v.(map[string]any)
+			if !ok {
+				return results
+			}
+			if v, ok = obj[string(tf)]; !ok {
+				return results
+			}
+		case Nth:
+			a, ok := v.([]any)
+			if !ok {
+				return results
+			}
+			n := int(tf)
+			if n < 0 {
+				n += len(a)
+			}
+			if n < 0 || len(a) <= n {
+				return results
+			}
+			v = a[n]
+		case Wildcard:
+			rest := x[i+1:]
+			switch tv := v.(type) {
+			case map[string]any:
+				for _, m := range tv {
+					results = rest.simpleGet(m, results)
+				}
+			case []any:
+				for _, m := range tv {
+					results = rest.simpleGet(m, results)
+				}
+			}
+			return results
+		case Union:
+			rest := x[i+1:]
+			for _, u := range tf {
+				switch tu := u.(type) {
+				case string:
+					if obj, ok := v.(map[string]any); ok {
+						if m, has := obj[tu]; has {
+							results = rest.simpleGet(m, results)
+						}
+					}
+				case int64:
+					if a, ok := v.([]any); ok {
+						n := int(tu)
+						if n < 0 {
+							n += len(a)
+						}
+						if 0 <= n && n < len(a) {
+							results = rest.simpleGet(a[n], results)
+						}
+					}
+				}
+			}
+			return results
+		default:
+			return append(results, append(Expr{At('@')}, x[i:]...).Get(v)...)
+		}
+	}
+	return append(results, v)
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package jp_test
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/ohler55/ojg/jp"
+	"github.com/ohler55/ojg/tt"
+)
+
+func TestExprSimpleGet(t *testing.T) {
+	data := buildTree(4, 3, 0)
+	for i, d := range getTestData {
+		switch d.data.(type) {
+		case nil, []any, map[string]any:
+		default:
+			continue // not simple data
+		}
+		if testing.Verbose() {
+			fmt.Printf("... %d: %s\n", i, d.path)
+		}
+		x, err := jp.ParseString(d.path)
+		tt.Nil(t, err)
+		var results []any
+		if d.data == nil {
+			results = x.SimpleGet(data)
+		} else {
+			results = x.SimpleGet(d.data)
+		}
+		sort.Slice(results, func(i, j int) bool {
+			iv, _ := results[i].(int)
+			jv, _ := results[j].(int)
+			return iv < jv
+		})
+		tt.Equal(t, d.expect, results, i, " : ", x)
+	}
+}
+
+func BenchmarkExprSimpleGet(b *testing.B) {
+	data := buildTree(4, 3, 0)
+	x := jp.MustParseString("$.a.*[1]")
+	for n := 0; n < b.N; n++ {
+		_ = x.SimpleGet(data)
+	}
+}
+
+func BenchmarkExprGet(b *testing.B) {
+	data := buildTree(4, 3, 0)
+	x := jp.MustParseString("$.a.*[1]")
+	for n := 0; n < b.N; n++ {
+		_ = x.Get(data)
+	}
+}
@@ -422,7 +422,7 @@ func (f Slice) startEndStep(size int) (start, end, step int) {
 	return
 }
 
-func (f Slice) locate(pp Expr, data any, rest Expr, max int) (locs []Expr) {
+func (f Slice) locate(pp Expr, data, root any, rest Expr, max int) (locs []Expr) {
 	switch td := data.(type) {
 	case []any:
 		start, end, step := f.startEndStep(len(td))
@@ -438,7 +438,7 @@ func (f Slice) locate(pp Expr, data any, rest Expr, max int) (locs []Expr) {
 				cp := append(pp, nil) // place holder
 				for i := start; i < end; i += step {
 					cp[len(pp)] = Nth(i)
-					locs = locateContinueFrag(locs, cp, td[i], rest, max)
+					locs = locateContinueFrag(locs, cp, td[i], root, rest, max)
 					if 0 < max && max <= len(locs) {
 						break
 					}
@@ -453,7 +453,7 @@ func (f Slice) locate(pp Expr, data any, rest Expr, max int) (locs []Expr) {
 				cp := append(pp, nil) // place holder
 				for i := start; end < i; i += step {
 					cp[len(pp)] = Nth(i)
-					locs = locateContinueFrag(locs, cp, td[i], rest, max)
+					locs = locateContinueFrag(locs, cp, td[i], root, rest, max)
 					if 0 < max && max <= len(locs) {
 						break
 					}
@@ -474,7 +474,7 @@ func (f Slice) locate(pp Expr, data any, rest Expr, max int) (locs []Expr) {
 				cp := append(pp, nil) // place holder
 				for i := start; i < end; i += step {
 					cp[len(pp)] = Nth(i)
-					locs = locateContinueFrag(locs, cp, td[i], rest, max)
+					locs = locateContinueFrag(locs, cp, td[i], root, rest, max)
 					if 0 < max && max <= len(locs) {
 						break
 					}
@@ -489,7 +489,7 @@ func (f Slice) locate(pp Expr, data any, rest Expr, max int) (locs []Expr) {
 				cp := append(pp, nil) // place holder
 				for i := start; end < i; i += step {
 					cp[len(pp)] = Nth(i)
-					locs = locateContinueFrag(locs, cp, td[i], rest, max)
+					locs = locateContinueFrag(locs, cp, td[i], root, rest, max)
 					if 0 < max && max <= len(locs) {
 						break
 					}
@@ -510,7 +510,7 @@ func (f Slice) locate(pp Expr, data any, rest Expr, max int) (locs []Expr) {
 				cp := append(pp, nil) // place holder
 				for i := start; i < end; i += step {
 					cp[len(pp)] = Nth(i)
-					locs = locateContinueFrag(locs, cp, td.ValueAtIndex(i), rest, max)
+					locs = locateContinueFrag(locs, cp, td.ValueAtIndex(i), root, rest, max)
 					if 0 < max && max <= len(locs) {
 						break
 					}
@@ -525,7 +525,7 @@ func (f Slice) locate(pp Expr, data any, rest Expr, max int) (locs []Expr) {
 				cp := append(pp, nil) // place holder
 				for i := start; end < i; i += step {
 					cp[len(pp)] = Nth(i)
-					locs = locateContinueFrag(locs, cp, td.ValueAtIndex(i), rest, max)
+					locs = locateContinueFrag(locs, cp, td.ValueAtIndex(i), root, rest, max)
 					if 0 < max && max <= len(locs) {
 						break
 					}
@@ -557,7 +557,7 @@ func (f Slice) locate(pp Expr, data any, rest Expr, max int) (locs []Expr) {
 						cp[len(pp)] = Nth(i)
 						rv := rd.Index(i)
 						if rv.CanInterface() {
-							locs = locateContinueFrag(locs, cp, rv.Interface(), rest, max)
+							locs = locateContinueFrag(locs, cp, rv.Interface(), root, rest, max)
 							if 0 < max && max <= len(locs) {
 								break
 							}
@@ -581,7 +581,7 @@ func (f Slice) locate(pp Expr, data any, rest Expr, max int) (locs []Expr) {
 						cp[len(pp)] = Nth(i)
 						rv := rd.Index(i)
 						if rv.CanInterface() {
-							locs = locateContinueFrag(locs, cp, rv.Interface(), rest, max)
+							locs = locateContinueFrag(locs, cp, rv.Interface(), root, rest, max)
 							if 0 < max && max <= len(locs) {
 								break
 							}
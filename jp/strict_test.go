@@ -0,0 +1,118 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package jp_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/jp"
+	"github.com/ohler55/ojg/sen"
+	"github.com/ohler55/ojg/tt"
+)
+
+func TestParseStrict(t *testing.T) {
+	for _, s := range []string{
+		"$.a[?@.x == 1]",
+		"$.a[?(@.x == 1 && @.y != 'b')]",
+		"$.a[?length(@.s) > 2]",
+		"$.a[?count(@.l[*]) == 2]",
+		"$.a[?match(@.s, 'a.c')]",
+		"$.a[?search(@.s, 'b')]",
+		"$.a[?value(@..x) == 1]",
+		"$['a'][0:2]",
+		"$..x",
+	} {
+		_, err := jp.ParseStrictString(s)
+		tt.Nil(t, err, s)
+	}
+	for _, s := range []string{
+		"a.b",
+		"@.a",
+		"$.a[(@.x)]",
+		"$.a[?(@.x in [1,2])]",
+		"$.a[?(@.x has true)]",
+		"$.a[?(@.x =~ /a.c/)]",
+		"$.a[?(@.x == Nothing)]",
+		"$.a[?(@.x + 1 == 2)]",
+		"$.a[?(empty(@.x))]",
+	} {
+		_, err := jp.ParseStrictString(s)
+		tt.Equal(t, true, errors.Is(err, ojg.ErrSyntax), s)
+	}
+	tt.Panic(t, func() { jp.MustParseStrictString("x") })
+}
+
+func TestStrictExistence(t *testing.T) {
+	data := sen.MustParse([]byte(`{a:[{x:1 l:[1 2]} {x:false} {y:3}]}`))
+
+	x := jp.MustParseStrictString("$.a[?@.x]")
+	tt.Equal(t, "$.a[?(@.x)]", x.String())
+	tt.Equal(t, "[{l:[1 2] x:1}{x:false}]", sen.String(x.Get(data), &sen.Options{Sort: true}))
+
+	x = jp.MustParseStrictString("$.a[?!@.x]")
+	tt.Equal(t, "[{y:3}]", sen.String(x.Get(data), &sen.Options{Sort: true}))
+
+	x = jp.MustParseStrictString("$.a[?(@.y || @.l[1] == 2)]")
+	tt.Equal(t, 2, len(x.Get(data)))
+
+	// The default syntax treats a lone path as a value.
+	tt.Equal(t, 0, len(jp.MustParseString("$.a[?@.x]").Get(data)))
+}
+
+func TestValueFunction(t *testing.T) {
+	data := sen.MustParse([]byte(`{a:[{b:{c:1}} {b:{c:2 d:{c:2}}}]}`))
+	x := jp.MustParseString("$.a[?(value(@..c) == 1)]")
+	tt.Equal(t, "$.a[?(value(@..c) == 1)]", x.String())
+	tt.Equal(t, "[{b:{c:1}}]", sen.String(x.Get(data)))
+
+	// More than one value is Nothing.
+	tt.Equal(t, 0, len(jp.MustParseString("$.a[?(value(@..c) == 2)]").Get(data)))
+
+	e := jp.Eq(jp.Value(jp.A().Descent().C("c")), jp.ConstInt(1))
+	f := e.Filter()
+	tt.Equal(t, "[?(value(@..c) == 1)]", f.String())
+	tt.Equal(t, 1, len(jp.R().C("a").F(e).Get(data)))
+}
+
+func TestNormalizedPath(t *testing.T) {
+	tt.Equal(t, "$", jp.R().NormalizedPath())
+	tt.Equal(t, `$['a'][0]['it\'s']['b\\c']['\n\u001f é']`,
+		jp.R().C("a").N(0).C("it's").C(`b\c`).C("\n\x1f é").NormalizedPath())
+
+	data := sen.MustParse([]byte(`{store:{book:[{title:x} {title:y}]}}`))
+	var paths []string
+	for _, loc := range jp.MustParseStrictString("$..title").Locate(data, 0) {
+		paths = append(paths, loc.NormalizedPath())
+	}
+	tt.Equal(t, []string{"$['store']['book'][0]['title']", "$['store']['book'][1]['title']"}, paths)
+}
+
+func TestStrictRootQuery(t *testing.T) {
+	data := sen.MustParse([]byte(`[{a:1} {a:2} {a:2} {a:3}]`))
+	locString := func(locs []jp.Expr) string {
+		var paths []string
+		for _, loc := range locs {
+			paths = append(paths, loc.NormalizedPath())
+		}
+		return strings.Join(paths, " ")
+	}
+	for _, d := range []struct {
+		src    string
+		expect string
+		locs   string
+	}{
+		{src: "$[?@.a == $[1].a]", expect: "[{a:2}{a:2}]", locs: "$[1] $[2]"},
+		{src: "$[?@.a == value($[3].a)]", expect: "[{a:3}]", locs: "$[3]"},
+		{src: "$[?count($[*]) == 4].a", expect: "[1 2 2 3]", locs: "$[0]['a'] $[1]['a'] $[2]['a'] $[3]['a']"},
+		{src: "$[?@.a > 1]", expect: "[{a:2}{a:2}{a:3}]", locs: "$[1] $[2] $[3]"},
+	} {
+		x := jp.MustParseStrictString(d.src)
+		tt.Equal(t, d.expect, sen.String(x.Get(data)), d.src)
+		tt.Equal(t, d.locs, locString(x.Locate(data, 0)), d.src)
+	}
+	_, loc := jp.MustParseStrictString("$[?@.a > 1]").FirstLocated(data)
+	tt.Equal(t, "$[1]", loc.NormalizedPath())
+}
@@ -64,6 +64,33 @@ top:
 			path[pi] = Child(k)
 			walk(path, v, cb, justLeaves)
 		}
+	case *gen.Annotated:
+		// The annotated node is passed to the callback in place of the
+		// wrapped node so the metadata is available.
+		switch tn := td.Node.(type) {
+		case gen.Array:
+			if !justLeaves {
+				cb(path, data)
+			}
+			pi := len(path)
+			path = append(path, nil)
+			for i, v := range tn {
+				path[pi] = Nth(i)
+				walk(path, v, cb, justLeaves)
+			}
+		case gen.Object:
+			if !justLeaves {
+				cb(path, data)
+			}
+			pi := len(path)
+			path = append(path, nil)
+			for k, v := range tn {
+				path[pi] = Child(k)
+				walk(path, v, cb, justLeaves)
+			}
+		default:
+			cb(path, data)
+		}
 	case alt.Simplifier:
 		data = td.Simplify()
 		goto top
@@ -72,6 +99,102 @@ top:
 	}
 }
 
+// WalkModify walks data in the same order as Walk and calls the cb callback
+// for each node with the normalized path to the node. If the callback
+// returns true for changed the node is replaced by the returned value or, if
+// the returned value is Nothing, removed from the array or object that
+// contains it. Nodes below a replaced or removed node are not walked. Arrays
+// and objects are modified in place but an array with removed elements is
+// shortened so the possibly new data is returned. Removing or replacing the
+// data itself returns nil or the replacement. The path is reused in each call
+// so if the path needs to be save it should be copied. Indexes in the path
+// are those of the original arrays. Only []any, map[string]any, gen.Array,
+// gen.Object, and *gen.Annotated nodes are walked into, all others are
+// treated as leaves. Values placed in a gen.Array or gen.Object must be
+// gen.Nodes.
+func WalkModify(data any, cb func(path Expr, value any) (altered any, changed bool)) any {
+	if v := walkModify(Expr{Root('$')}, data, cb); v != Nothing {
+		return v
+	}
+	return nil
+}
+
+func walkModify(path Expr, data any, cb func(path Expr, value any) (any, bool)) any {
+	if v, changed := cb(path, data); changed {
+		return v
+	}
+	pi := len(path)
+	path = append(path, nil)
+	switch td := data.(type) {
+	case []any:
+		n := 0
+		for i, v := range td {
+			path[pi] = Nth(i)
+			if v = walkModify(path, v, cb); v != Nothing {
+				td[n] = v
+				n++
+			}
+		}
+		clear(td[n:])
+		return td[:n]
+	case map[string]any:
+		for k, v := range td {
+			path[pi] = Child(k)
+			if v = walkModify(path, v, cb); v == Nothing {
+				delete(td, k)
+			} else {
+				td[k] = v
+			}
+		}
+	case gen.Array:
+		return walkModifyArray(path, td, cb)
+	case gen.Object:
+		walkModifyObject(path, td, cb)
+	case *gen.Annotated:
+		switch tn := td.Node.(type) {
+		case gen.Array:
+			td.Node = walkModifyArray(path, tn, cb)
+		case gen.Object:
+			walkModifyObject(path, tn, cb)
+		}
+	}
+	return data
+}
+
+func walkModifyArray(path Expr, array gen.Array, cb func(path Expr, value any) (any, bool)) gen.Array {
+	pi := len(path) - 1
+	n := 0
+	for i, v := range array {
+		path[pi] = Nth(i)
+		if nv := walkModify(path, v, cb); nv != Nothing {
+			array[n] = asNode(nv)
+			n++
+		}
+	}
+	clear(array[n:])
+	return array[:n]
+}
+
+func walkModifyObject(path Expr, obj gen.Object, cb func(path Expr, value any) (any, bool)) {
+	pi := len(path) - 1
+	for k, v := range obj {
+		path[pi] = Child(k)
+		if nv := walkModify(path, v, cb); nv == Nothing {
+			delete(obj, k)
+		} else {
+			obj[k] = asNode(nv)
+		}
+	}
+}
+
+// asNode returns the value as a gen.Node where a nil value is a nil node.
+func asNode(v any) gen.Node {
+	if v == nil {
+		return nil
+	}
+	return v.(gen.Node)
+}
+
 // Walk the matching elements in the data and call cb on the matches. The path
 // passed to the cb function is the normalized path to the current location
 // while the nodes are the chain of elements up to and including the current
@@ -4,6 +4,7 @@ package jp_test
 
 import (
 	"bytes"
+	"fmt"
 	"sort"
 	"strings"
 	"testing"
@@ -46,6 +47,46 @@ func TestWalk(t *testing.T) {
 	tt.Equal(t, `{"a[0]": 1 "a[1]": 2 "a[2]": 3 b: null c.x: 4 d: 5}`, pretty.SEN(leaves))
 }
 
+func TestWalkModify(t *testing.T) {
+	data := map[string]any{
+		"a": []any{1, "secret", 3, []any{"secret", 4}},
+		"b": map[string]any{"password": "abc", "c": "secret"},
+	}
+	var paths []string
+	result := jp.WalkModify(data, func(path jp.Expr, value any) (any, bool) {
+		paths = append(paths, path.String())
+		if path[len(path)-1] == jp.Child("password") {
+			return "***", true
+		}
+		if value == "secret" {
+			return jp.Nothing, true
+		}
+		return value, false
+	})
+	tt.Equal(t, `{a:[1 3 [4]] b:{password:***}}`, sen.String(result, &ojg.Options{Sort: true}))
+	sort.Strings(paths)
+	tt.Equal(t, `[$ $.a "$.a[0]" "$.a[1]" "$.a[2]" "$.a[3]" "$.a[3][0]" "$.a[3][1]" $.b $.b.c $.b.password]`,
+		string(sen.Bytes(paths)))
+
+	node := gen.Object{"a": gen.Array{gen.Int(1), nil, gen.String("x")}, "b": gen.Int(2)}
+	result = jp.WalkModify(node, func(path jp.Expr, value any) (any, bool) {
+		switch value {
+		case gen.String("x"):
+			return jp.Nothing, true
+		case gen.Int(2):
+			return gen.Int(3), true
+		}
+		return value, false
+	})
+	tt.Equal(t, `{a:[1 null] b:3}`, sen.String(result, &ojg.Options{Sort: true}))
+
+	result = jp.WalkModify([]any{1, 2}, func(path jp.Expr, value any) (any, bool) {
+		return "replaced", true
+	})
+	tt.Equal(t, "replaced", result)
+	tt.Nil(t, jp.WalkModify(1, func(path jp.Expr, value any) (any, bool) { return jp.Nothing, true }))
+}
+
 func TestWalkNode(t *testing.T) {
 	data := gen.Object{"a": gen.Array{gen.Int(1), gen.Int(2), gen.Int(3)}, "b": nil}
 	var paths []string
@@ -317,3 +358,24 @@ func TestExprWalkMap(t *testing.T) {
 		tt.Equal(t, wd.nodes, strings.Join(ns, " "), "%d: nodes mismatch for %s", i, wd.path)
 	}
 }
+
+func TestWalkAnnotated(t *testing.T) {
+	data := gen.Object{
+		"a": gen.Annotate(gen.Array{gen.Int(1), gen.Annotate(gen.Int(2), map[string]any{"line": 3})},
+			map[string]any{"line": 2}),
+		"b": gen.Annotate(gen.Object{"c": gen.True}, map[string]any{"line": 4}),
+	}
+	var lines []string
+	jp.Walk(data, func(path jp.Expr, value any) {
+		if an, ok := value.(*gen.Annotated); ok {
+			lines = append(lines, fmt.Sprintf("%s:%v", path, an.Meta["line"]))
+		}
+	})
+	sort.Strings(lines)
+	tt.Equal(t, []string{"$.a:2", "$.a[1]:3", "$.b:4"}, lines)
+
+	var paths []string
+	jp.Walk(data, func(path jp.Expr, value any) { paths = append(paths, path.String()) }, true)
+	sort.Strings(paths)
+	tt.Equal(t, []string{"$.a[0]", "$.a[1]", "$.b.c"}, paths)
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package ojg
+
+import (
+	"strings"
+	"unicode"
+)
+
+// SnakeCase converts a struct field name such as UserID to user_id. It can
+// be used as the KeyFunc option.
+func SnakeCase(field string) string {
+	return strings.Join(keyWords(field), "_")
+}
+
+// KebabCase converts a struct field name such as UserID to user-id. It can
+// be used as the KeyFunc option.
+func KebabCase(field string) string {
+	return strings.Join(keyWords(field), "-")
+}
+
+// CamelCase converts a struct field name such as UserID to userId. It can be
+// used as the KeyFunc option.
+func CamelCase(field string) string {
+	var b strings.Builder
+	for i, w := range keyWords(field) {
+		if 0 < i {
+			rs := []rune(w)
+			rs[0] = unicode.ToUpper(rs[0])
+			w = string(rs)
+		}
+		b.WriteString(w)
+	}
+	return b.String()
+}
+
+// keyWords splits a name into lowercase words. Words are separated by
+// underscores, dashes, and spaces and start at an uppercase letter that
+// follows a lowercase letter or digit or that is followed by a lowercase
+// letter after a run of uppercase letters as in HTTPServer.
+func keyWords(name string) (words []string) {
+	rs := []rune(name)
+	start := 0
+	add := func(end int) {
+		if start < end {
+			words = append(words, strings.ToLower(string(rs[start:end])))
+		}
+	}
+	for i, r := range rs {
+		switch {
+		case r == '_' || r == '-' || r == ' ':
+			add(i)
+			start = i + 1
+		case unicode.IsUpper(r) && start < i:
+			prev := rs[i-1]
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) ||
+				(unicode.IsUpper(prev) && i+1 < len(rs) && unicode.IsLower(rs[i+1])) {
+				add(i)
+				start = i
+			}
+		}
+	}
+	add(len(rs))
+	return
+}
@@ -0,0 +1,31 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package ojg_test
+
+import (
+	"testing"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/tt"
+)
+
+func TestKeyCase(t *testing.T) {
+	for _, td := range []struct {
+		field string
+		snake string
+		kebab string
+		camel string
+	}{
+		{field: "Name", snake: "name", kebab: "name", camel: "name"},
+		{field: "UserID", snake: "user_id", kebab: "user-id", camel: "userId"},
+		{field: "HTTPServer", snake: "http_server", kebab: "http-server", camel: "httpServer"},
+		{field: "Base64Data", snake: "base64_data", kebab: "base64-data", camel: "base64Data"},
+		{field: "ID", snake: "id", kebab: "id", camel: "id"},
+		{field: "already_snake", snake: "already_snake", kebab: "already-snake", camel: "alreadySnake"},
+		{field: "ÜberCool", snake: "über_cool", kebab: "über-cool", camel: "überCool"},
+	} {
+		tt.Equal(t, td.snake, ojg.SnakeCase(td.field), td.field)
+		tt.Equal(t, td.kebab, ojg.KebabCase(td.field), td.field)
+		tt.Equal(t, td.camel, ojg.CamelCase(td.field), td.field)
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ohler55/ojg"
+)
+
+// BatchWriter encodes values as newline delimited JSON (NDJSON) into an
+// internal buffer and writes the buffer to an io.Writer from a background
+// goroutine when the buffer reaches a size threshold or when an interval
+// has passed. Append only encodes the value so callers such as telemetry
+// or event emitters are not blocked by a slow io.Writer. A BatchWriter is
+// safe for concurrent use.
+type BatchWriter struct {
+	w        io.Writer
+	wr       Writer
+	size     int
+	limit    int
+	spare    []byte
+	err      error
+	closed   bool
+	mu       sync.Mutex // protects the Writer buffer and state
+	writeMu  sync.Mutex // serializes writes to w
+	kick     chan struct{}
+	stop     chan struct{}
+	done     chan struct{}
+	interval time.Duration
+}
+
+// NewBatchWriter returns a BatchWriter that writes to w once size bytes
+// are pending or, if interval is greater than zero, every interval. If the
+// opts are provided the first entry is used to encode values but values
+// are always written on a single line.
+func NewBatchWriter(w io.Writer, size int, interval time.Duration, opts ...*Options) *BatchWriter {
+	bw := BatchWriter{
+		w:        w,
+		wr:       Writer{Options: DefaultOptions},
+		size:     size,
+		kick:     make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+		interval: interval,
+	}
+	if 0 < len(opts) && opts[0] != nil {
+		bw.wr.Options = *opts[0]
+	}
+	bw.wr.Indent = 0
+	bw.wr.Tab = false
+	bw.wr.buf = make([]byte, 0, max(size, 256))
+	bw.wr.prepare()
+	go bw.run()
+
+	return &bw
+}
+
+// SetLimit sets the maximum number of bytes that can be pending. If a
+// value would exceed the limit Append drops it and returns an error
+// matching ojg.ErrOutputLimit. A limit of zero, the default, is no limit.
+func (bw *BatchWriter) SetLimit(limit int) {
+	bw.mu.Lock()
+	bw.limit = limit
+	bw.mu.Unlock()
+}
+
+// Append encodes the value and adds it to the pending output. An error is
+// returned if the value can not be encoded, if the BatchWriter is closed,
+// or if an earlier write to the io.Writer failed.
+func (bw *BatchWriter) Append(v any) (err error) {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+	if bw.closed {
+		return os.ErrClosed
+	}
+	if bw.err != nil {
+		return bw.err
+	}
+	start := len(bw.wr.buf)
+	defer func() {
+		if r := recover(); r != nil {
+			bw.wr.buf = bw.wr.buf[:start]
+			err = ojg.NewError(r)
+		}
+	}()
+	bw.wr.appendLine(v)
+	if 0 < bw.limit && bw.limit < len(bw.wr.buf) {
+		bw.wr.buf = bw.wr.buf[:start]
+		return ojg.Errorf(ojg.ErrOutputLimit, "batch writer pending output over %d bytes", bw.limit)
+	}
+	if bw.size <= len(bw.wr.buf) {
+		select {
+		case bw.kick <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// Flush writes the pending output and waits for the write to complete.
+func (bw *BatchWriter) Flush() error {
+	bw.flush()
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+	return bw.err
+}
+
+// Close flushes the pending output and stops the background goroutine.
+// The io.Writer is not closed. Any error from writing is returned.
+func (bw *BatchWriter) Close() error {
+	bw.mu.Lock()
+	if bw.closed {
+		bw.mu.Unlock()
+		return os.ErrClosed
+	}
+	bw.closed = true
+	bw.mu.Unlock()
+	close(bw.stop)
+	<-bw.done
+
+	return bw.Flush()
+}
+
+func (bw *BatchWriter) run() {
+	defer close(bw.done)
+	var tick <-chan time.Time
+	if 0 < bw.interval {
+		ticker := time.NewTicker(bw.interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case <-bw.kick:
+		case <-tick:
+		case <-bw.stop:
+			return
+		}
+		bw.flush()
+	}
+}
+
+// flush swaps the pending output with the spare buffer and writes it.
+func (bw *BatchWriter) flush() {
+	bw.writeMu.Lock()
+	defer bw.writeMu.Unlock()
+
+	bw.mu.Lock()
+	out := bw.wr.buf
+	bw.wr.buf = bw.spare[:0]
+	bw.mu.Unlock()
+
+	if 0 < len(out) {
+		if _, err := bw.w.Write(out); err != nil {
+			bw.mu.Lock()
+			if bw.err == nil {
+				bw.err = fmt.Errorf("batch write failed: %w", err)
+			}
+			bw.mu.Unlock()
+		}
+	}
+	bw.spare = out[:0]
+}
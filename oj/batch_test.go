@@ -0,0 +1,87 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/tt"
+)
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+	cnt int
+}
+
+func (sb *syncBuffer) Write(b []byte) (int, error) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	sb.cnt++
+	return sb.buf.Write(b)
+}
+
+func (sb *syncBuffer) String() string {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return sb.buf.String()
+}
+
+type failWriter struct{}
+
+func (failWriter) Write([]byte) (int, error) {
+	return 0, errors.New("fail")
+}
+
+func TestBatchWriter(t *testing.T) {
+	var sb syncBuffer
+	bw := oj.NewBatchWriter(&sb, 1<<20, 0, &ojg.Options{Indent: 2})
+	tt.Nil(t, bw.Append(map[string]any{"a": 1}))
+	tt.Nil(t, bw.Append([]any{1, 2}))
+	tt.Equal(t, "", sb.String())
+	tt.Nil(t, bw.Flush())
+	tt.Equal(t, "{\"a\":1}\n[1,2]\n", sb.String())
+
+	tt.Nil(t, bw.Append(3))
+	tt.Nil(t, bw.Close())
+	tt.Equal(t, "{\"a\":1}\n[1,2]\n3\n", sb.String())
+	tt.ErrorIs(t, bw.Append(4), os.ErrClosed)
+	tt.ErrorIs(t, bw.Close(), os.ErrClosed)
+}
+
+func TestBatchWriterThresholds(t *testing.T) {
+	var sb syncBuffer
+	bw := oj.NewBatchWriter(&sb, 8, 0)
+	tt.Nil(t, bw.Append("0123456789"))
+	for i := 0; i < 100 && len(sb.String()) == 0; i++ {
+		time.Sleep(time.Millisecond * 5)
+	}
+	tt.Equal(t, "\"0123456789\"\n", sb.String())
+	tt.Nil(t, bw.Close())
+
+	sb = syncBuffer{}
+	bw = oj.NewBatchWriter(&sb, 1<<20, time.Millisecond*10)
+	tt.Nil(t, bw.Append(true))
+	for i := 0; i < 100 && len(sb.String()) == 0; i++ {
+		time.Sleep(time.Millisecond * 5)
+	}
+	tt.Equal(t, "true\n", sb.String())
+	tt.Nil(t, bw.Close())
+}
+
+func TestBatchWriterErrors(t *testing.T) {
+	bw := oj.NewBatchWriter(failWriter{}, 1<<20, 0)
+	bw.SetLimit(8)
+	tt.ErrorIs(t, bw.Append("0123456789"), ojg.ErrOutputLimit)
+	tt.Nil(t, bw.Append(1))
+	tt.NotNil(t, bw.Flush())
+	tt.NotNil(t, bw.Append(2))
+	tt.NotNil(t, bw.Close())
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj
+
+import (
+	"io"
+	"net"
+
+	"github.com/ohler55/ojg"
+)
+
+// WriteBuffers writes the JSON for the data to w as net.Buffers. Instead of
+// growing one contiguous buffer for a large document the output is
+// collected in chunks of about the WriteLimit in size which are then all
+// written with one call to the net.Buffers WriteTo() method. When w is a
+// net.Conn such as a *net.TCPConn that is a single writev system call. The
+// chunks are taken from the Allocator if one is set and released after the
+// write.
+func (wr *Writer) WriteBuffers(w io.Writer, data any) (err error) {
+	cw := chunkWriter{alloc: wr.Allocator}
+	defer func() {
+		if r := recover(); r != nil {
+			wr.buf = wr.buf[:0]
+			err = ojg.NewError(r)
+		}
+		for _, chunk := range cw.chunks {
+			ojg.Free(cw.alloc, chunk)
+		}
+	}()
+	wr.MustWrite(&cw, data)
+	bufs := make(net.Buffers, len(cw.chunks))
+	copy(bufs, cw.chunks)
+	_, err = bufs.WriteTo(w)
+
+	return
+}
+
+// chunkWriter collects copies of the buffers written to it.
+type chunkWriter struct {
+	alloc  ojg.Allocator
+	chunks [][]byte
+}
+
+func (cw *chunkWriter) Write(p []byte) (int, error) {
+	chunk := append(ojg.Alloc(cw.alloc, len(p)), p...)
+	cw.chunks = append(cw.chunks, chunk)
+
+	return len(p), nil
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj_test
+
+import (
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/tt"
+)
+
+func TestWriteBuffers(t *testing.T) {
+	data := make([]any, 100)
+	for i := range data {
+		data[i] = map[string]any{"id": i, "name": strings.Repeat("x", i)}
+	}
+	expect := oj.JSON(data, &oj.Options{Sort: true})
+
+	var b strings.Builder
+	wr := oj.Writer{Options: oj.Options{Sort: true, WriteLimit: 64}}
+	tt.Nil(t, wr.WriteBuffers(&b, data))
+	tt.Equal(t, expect, b.String())
+
+	// Through a net.Conn.
+	client, server := net.Pipe()
+	done := make(chan string)
+	go func() {
+		out, _ := io.ReadAll(server)
+		done <- string(out)
+	}()
+	tt.Nil(t, wr.WriteBuffers(client, data))
+	_ = client.Close()
+	tt.Equal(t, expect, <-done)
+
+	tt.NotNil(t, wr.WriteBuffers(&shortWriter{max: 3}, data))
+	tt.NotNil(t, wr.WriteBuffers(&b, &Panik{}))
+}
@@ -4,15 +4,18 @@ package oj
 
 import (
 	"fmt"
-	"sort"
-	"strconv"
+	"reflect"
 	"time"
+	"unsafe"
 
 	"github.com/ohler55/ojg"
 	"github.com/ohler55/ojg/alt"
 )
 
 func (wr *Writer) colorJSON(data any, depth int) {
+	if 0 < len(typeEncoders) && wr.encode(data, depth) {
+		goto Written
+	}
 	switch td := data.(type) {
 	case nil:
 		wr.buf = append(wr.buf, wr.NullColor...)
@@ -28,55 +31,86 @@ func (wr *Writer) colorJSON(data any, depth int) {
 
 	case int:
 		wr.buf = append(wr.buf, wr.NumberColor...)
-		wr.buf = append(wr.buf, []byte(strconv.FormatInt(int64(td), 10))...)
+		wr.buf = wr.AppendInt(wr.buf, int64(td))
 	case int8:
 		wr.buf = append(wr.buf, wr.NumberColor...)
-		wr.buf = append(wr.buf, []byte(strconv.FormatInt(int64(td), 10))...)
+		wr.buf = wr.AppendInt(wr.buf, int64(td))
 	case int16:
 		wr.buf = append(wr.buf, wr.NumberColor...)
-		wr.buf = append(wr.buf, []byte(strconv.FormatInt(int64(td), 10))...)
+		wr.buf = wr.AppendInt(wr.buf, int64(td))
 	case int32:
 		wr.buf = append(wr.buf, wr.NumberColor...)
-		wr.buf = append(wr.buf, []byte(strconv.FormatInt(int64(td), 10))...)
+		wr.buf = wr.AppendInt(wr.buf, int64(td))
 	case int64:
 		wr.buf = append(wr.buf, wr.NumberColor...)
-		wr.buf = append(wr.buf, []byte(strconv.FormatInt(td, 10))...)
+		wr.buf = wr.AppendInt(wr.buf, td)
 	case uint:
 		wr.buf = append(wr.buf, wr.NumberColor...)
-		wr.buf = append(wr.buf, []byte(strconv.FormatInt(int64(td), 10))...)
+		wr.buf = wr.AppendUint(wr.buf, uint64(td))
 	case uint8:
 		wr.buf = append(wr.buf, wr.NumberColor...)
-		wr.buf = append(wr.buf, []byte(strconv.FormatInt(int64(td), 10))...)
+		wr.buf = wr.AppendUint(wr.buf, uint64(td))
 	case uint16:
 		wr.buf = append(wr.buf, wr.NumberColor...)
-		wr.buf = append(wr.buf, []byte(strconv.FormatInt(int64(td), 10))...)
+		wr.buf = wr.AppendUint(wr.buf, uint64(td))
 	case uint32:
 		wr.buf = append(wr.buf, wr.NumberColor...)
-		wr.buf = append(wr.buf, []byte(strconv.FormatInt(int64(td), 10))...)
+		wr.buf = wr.AppendUint(wr.buf, uint64(td))
 	case uint64:
 		wr.buf = append(wr.buf, wr.NumberColor...)
-		wr.buf = append(wr.buf, []byte(strconv.FormatInt(int64(td), 10))...)
+		wr.buf = wr.AppendUint(wr.buf, td)
 
 	case float32:
 		wr.buf = append(wr.buf, wr.NumberColor...)
-		wr.buf = append(wr.buf, []byte(strconv.FormatFloat(float64(td), 'g', -1, 32))...)
+		wr.buf = wr.AppendFloat(wr.buf, float64(td), 32)
 	case float64:
 		wr.buf = append(wr.buf, wr.NumberColor...)
-		wr.buf = append(wr.buf, []byte(strconv.FormatFloat(td, 'g', -1, 64))...)
+		wr.buf = wr.AppendFloat(wr.buf, td, 64)
 
 	case string:
 		wr.buf = append(wr.buf, wr.StringColor...)
-		wr.buf = ojg.AppendJSONString(wr.buf, td, !wr.HTMLUnsafe)
+		wr.buf = wr.appendString(wr.buf, wr.clipString(td), !wr.HTMLUnsafe)
 
 	case time.Time:
 		wr.buf = append(wr.buf, wr.TimeColor...)
 		wr.buf = wr.AppendTime(wr.buf, td, false)
 
 	case []any:
+		if td == nil && wr.NilCollections == ojg.NilAsNull {
+			wr.buf = append(wr.buf, wr.NullColor...)
+			wr.buf = append(wr.buf, "null"...)
+			break
+		}
+		if wr.truncated() {
+			wr.appendMarker(depth)
+			return
+		}
+		wr.level++
 		wr.colorArray(td, depth)
+		wr.level--
 
 	case map[string]any:
+		if td == nil && wr.NilCollections == ojg.NilAsNull {
+			wr.buf = append(wr.buf, wr.NullColor...)
+			wr.buf = append(wr.buf, "null"...)
+			break
+		}
+		if wr.truncated() {
+			wr.appendMarker(depth)
+			return
+		}
+		wr.level++
 		wr.colorObject(td, depth)
+		wr.level--
+
+	case *OMap:
+		if wr.truncated() {
+			wr.appendMarker(depth)
+			return
+		}
+		wr.level++
+		wr.colorOMap(td, depth)
+		wr.level--
 
 	default:
 		if simp, _ := data.(alt.Simplifier); simp != nil {
@@ -89,19 +123,38 @@ func (wr *Writer) colorJSON(data any, depth int) {
 			return
 		}
 		if !wr.NoReflect {
+			if rv := reflect.ValueOf(data); rv.Kind() == reflect.Func && wr.appendSeq(rv, depth) {
+				break
+			}
+			if wr.colorReflect(data, depth) {
+				break
+			}
 			if dec := alt.Decompose(data, &wr.Options); dec != nil {
 				wr.colorJSON(dec, depth)
 				return
 			}
 		}
-		wr.buf = ojg.AppendJSONString(wr.buf, fmt.Sprintf("%v", td), !wr.HTMLUnsafe)
+		if v, ok := wr.Fallback(data); ok {
+			wr.colorJSON(v, depth)
+			return
+		}
+		wr.buf = wr.appendString(wr.buf, fmt.Sprintf("%v", td), !wr.HTMLUnsafe)
 	}
+Written:
 	wr.buf = append(wr.buf, wr.NoColor...)
 
-	if wr.w != nil && wr.WriteLimit < len(wr.buf) {
+	if 0 < wr.MaxOutputBytes {
+		wr.checkOutput()
+	}
+	if wr.ctx != nil {
+		wr.checkContext()
+	}
+	if wr.w != nil && wr.WriteLimit < len(wr.buf) && wr.holds == 0 {
 		if _, err := wr.w.Write(wr.buf); err != nil {
 			panic(err)
 		}
+		wr.flushed += len(wr.buf) - wr.outStart
+		wr.outStart = 0
 		wr.buf = wr.buf[:0]
 	}
 }
@@ -137,14 +190,11 @@ func (wr *Writer) colorArray(n []any, depth int) {
 		}
 		cs = spaces[0:x]
 	}
+	first := true
 	for j, m := range n {
-		if 0 < j {
-			wr.buf = append(wr.buf, wr.SyntaxColor...)
-			wr.buf = append(wr.buf, ',')
-			wr.buf = append(wr.buf, wr.NoColor...)
+		if wr.colorElement(j, len(n), m, cs, d2, first) {
+			first = false
 		}
-		wr.buf = append(wr.buf, []byte(cs)...)
-		wr.colorJSON(m, d2)
 	}
 	wr.buf = append(wr.buf, []byte(is)...)
 	wr.buf = append(wr.buf, wr.SyntaxColor...)
@@ -183,92 +233,276 @@ func (wr *Writer) colorObject(n map[string]any, depth int) {
 		}
 		cs = spaces[0:x]
 	}
-	if wr.Sort {
-		keys := make([]string, 0, len(n))
-		for k := range n {
-			keys = append(keys, k)
-		}
-		sort.Strings(keys)
+	if wr.sorting {
+		keys := wr.sortedKeys(n)
 		for _, k := range keys {
-			m := n[k]
-			switch tm := m.(type) {
-			case nil:
-				if wr.OmitNil {
-					continue
-				}
-			case string:
-				if wr.OmitEmpty && len(tm) == 0 {
-					continue
-				}
-			case map[string]any:
-				if wr.OmitEmpty && len(tm) == 0 {
-					continue
-				}
-			case []any:
-				if wr.OmitEmpty && len(tm) == 0 {
-					continue
-				}
-			}
-			if first {
+			if wr.colorMember(k, n[k], cs, d2, first) {
 				first = false
-			} else {
-				wr.buf = append(wr.buf, wr.SyntaxColor...)
-				wr.buf = append(wr.buf, ',')
-				wr.buf = append(wr.buf, wr.NoColor...)
-			}
-			wr.buf = append(wr.buf, []byte(cs)...)
-			wr.buf = append(wr.buf, wr.KeyColor...)
-			wr.buf = ojg.AppendJSONString(wr.buf, k, !wr.HTMLUnsafe)
-			wr.buf = append(wr.buf, wr.NoColor...)
-			wr.buf = append(wr.buf, wr.SyntaxColor...)
-			wr.buf = append(wr.buf, ':')
-			wr.buf = append(wr.buf, wr.NoColor...)
-			if 0 < wr.Indent {
-				wr.buf = append(wr.buf, ' ')
 			}
-			wr.colorJSON(n[k], d2)
 		}
 	} else {
 		for k, m := range n {
-			switch tm := m.(type) {
-			case nil:
-				if wr.OmitNil {
-					continue
-				}
-			case string:
-				if wr.OmitEmpty && len(tm) == 0 {
-					continue
-				}
-			case map[string]any:
-				if wr.OmitEmpty && len(tm) == 0 {
-					continue
-				}
-			case []any:
-				if wr.OmitEmpty && len(tm) == 0 {
-					continue
-				}
-			}
-			if first {
+			if wr.colorMember(k, m, cs, d2, first) {
 				first = false
-			} else {
-				wr.buf = append(wr.buf, wr.SyntaxColor...)
-				wr.buf = append(wr.buf, ',')
-				wr.buf = append(wr.buf, wr.NoColor...)
 			}
-			wr.buf = append(wr.buf, []byte(cs)...)
-			wr.buf = append(wr.buf, wr.KeyColor...)
-			wr.buf = ojg.AppendJSONString(wr.buf, k, !wr.HTMLUnsafe)
-			wr.buf = append(wr.buf, wr.NoColor...)
-			wr.buf = append(wr.buf, wr.SyntaxColor...)
-			wr.buf = append(wr.buf, ':')
-			wr.buf = append(wr.buf, wr.NoColor...)
-			if 0 < wr.Indent {
-				wr.buf = append(wr.buf, ' ')
+		}
+	}
+	wr.buf = append(wr.buf, []byte(is)...)
+	wr.buf = append(wr.buf, wr.SyntaxColor...)
+	wr.buf = append(wr.buf, '}')
+}
+
+// colorMember writes an object member with a separator if not first and
+// returns true unless the member is omitted or not selected by the Include
+// and Exclude options.
+func (wr *Writer) colorMember(k string, m any, cs string, depth int, first bool) bool {
+	member := m
+	if wr.redactKey(k) {
+		m = wr.redactMask
+	}
+	if wr.omitMember(m) {
+		return false
+	}
+	return wr.colorPut(k, member, m, nil, cs, depth, first)
+}
+
+// colorPut writes an object member that has not been omitted. If raw is
+// not nil it is the JSON already encoded for the value. The member is the
+// value before redaction that is matched by the Include and Exclude
+// options.
+func (wr *Writer) colorPut(k string, member, m any, raw []byte, cs string, depth int, first bool) bool {
+	var parent pathState
+	if wr.filtering {
+		var ok bool
+		if parent, ok = wr.pathEnter(k, -1, 0, member); !ok {
+			return false
+		}
+	}
+	mark := len(wr.buf)
+	if !first {
+		wr.buf = append(wr.buf, wr.SyntaxColor...)
+		wr.buf = append(wr.buf, ',')
+		wr.buf = append(wr.buf, wr.NoColor...)
+	}
+	wr.buf = append(wr.buf, []byte(cs)...)
+	wr.buf = append(wr.buf, wr.KeyColor...)
+	wr.buf = wr.appendString(wr.buf, wr.keyFor(k), !wr.HTMLUnsafe)
+	wr.buf = append(wr.buf, wr.NoColor...)
+	wr.buf = append(wr.buf, wr.SyntaxColor...)
+	wr.buf = append(wr.buf, ':')
+	wr.buf = append(wr.buf, wr.NoColor...)
+	if 0 < wr.Indent {
+		wr.buf = append(wr.buf, ' ')
+	}
+	if raw == nil {
+		wr.colorJSON(m, depth)
+	} else {
+		switch raw[0] {
+		case '"':
+			wr.buf = append(wr.buf, wr.StringColor...)
+		case 't', 'f':
+			wr.buf = append(wr.buf, wr.BoolColor...)
+		case 'n':
+			wr.buf = append(wr.buf, wr.NullColor...)
+		case '{', '[':
+		default:
+			wr.buf = append(wr.buf, wr.NumberColor...)
+		}
+		wr.buf = append(wr.buf, raw...)
+		wr.buf = append(wr.buf, wr.NoColor...)
+	}
+	return !wr.filtering || wr.pathLeave(parent, mark, true)
+}
+
+// colorReflect writes a struct, slice, array, or map in color by walking it
+// with the same struct field information as when writing without color so
+// tag options such as redact are honored. False is returned if the value
+// is of another kind.
+func (wr *Writer) colorReflect(data any, depth int) bool {
+	rv := reflect.ValueOf(data)
+	if rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Struct:
+		if _, ok := rv.Interface().(time.Time); ok {
+			return false
+		}
+		wr.colorStruct(rv, depth)
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 || (rv.Kind() == reflect.Slice && rv.IsNil()) {
+			return false
+		}
+		wr.colorSlice(rv, depth)
+	case reflect.Map:
+		if rv.IsNil() {
+			return false
+		}
+		wr.colorMap(rv, depth)
+	default:
+		return false
+	}
+	return true
+}
+
+func (wr *Writer) colorStruct(rv reflect.Value, depth int) {
+	si := getSinfo(rv.Interface(), wr.OmitEmpty)
+	if !wr.enter(rv, depth) {
+		return
+	}
+	wr.buf = append(wr.buf, wr.SyntaxColor...)
+	wr.buf = append(wr.buf, '{')
+	wr.buf = append(wr.buf, wr.NoColor...)
+	is, cs := wr.indents(depth)
+	first := true
+	if 0 < len(wr.CreateKey) {
+		name := si.rt.Name()
+		if wr.FullTypePath {
+			name = si.rt.PkgPath() + "/" + name
+		}
+		first = !wr.colorMember(wr.CreateKey, name, cs, depth+1, first)
+	}
+	var addr uintptr
+	if rv.CanAddr() {
+		addr = rv.UnsafeAddr()
+	}
+	for _, fi := range wr.structFields(si) {
+		if (fi.skippable || wr.skipFields) && wr.skipField(fi, rv) {
+			continue
+		}
+		if wr.cycles {
+			wr.stepField(fi)
+		}
+		if wr.colorField(fi, rv, addr, cs, depth+1, first) {
+			first = false
+		}
+	}
+	wr.buf = append(wr.buf, []byte(is)...)
+	wr.buf = append(wr.buf, wr.SyntaxColor...)
+	wr.buf = append(wr.buf, '}')
+	wr.leave()
+}
+
+// colorField writes a struct field in color and returns false if it was
+// not written. Values encoded by the field itself, such as those with the
+// string or asnumber tag options, are colored by the JSON type written.
+func (wr *Writer) colorField(fi *finfo, rv reflect.Value, addr uintptr, cs string, depth int, first bool) bool {
+	var member any
+	if wr.filtering {
+		member = fieldMember(fi, rv)
+	}
+	if fi.redact || wr.redactKey(fi.key) {
+		return wr.colorPut(fi.key, member, wr.redactMask, nil, cs, depth, first)
+	}
+	var v any
+	var stat appendStatus
+	mark := len(wr.buf)
+	switch {
+	case wr.slowField && fi.sAppend != nil:
+		wr.buf, v, stat = fi.sAppend(fi, wr.buf, rv, addr, !wr.HTMLUnsafe)
+	case 0 < addr:
+		wr.buf, v, stat = fi.Append(fi, wr.buf, rv, addr, !wr.HTMLUnsafe)
+	default:
+		wr.buf, v, stat = fi.iAppend(fi, wr.buf, rv, addr, !wr.HTMLUnsafe)
+	}
+	var raw []byte
+	if stat == aWrote {
+		raw = append(raw, wr.buf[mark+fi.keyLen():]...)
+	}
+	wr.buf = wr.buf[:mark]
+	switch stat {
+	case aWrote:
+	case aSkip:
+		return false
+	case aChanged:
+		if wr.OmitNil && (*[2]uintptr)(unsafe.Pointer(&v))[1] == 0 {
+			return false
+		}
+	default:
+		if (fi.kind == reflect.Ptr || fi.kind == reflect.Interface) && (*[2]uintptr)(unsafe.Pointer(&v))[1] == 0 {
+			if wr.OmitNil {
+				return false
 			}
-			wr.colorJSON(m, d2)
+			v = nil
+		}
+	}
+	return wr.colorPut(fi.key, member, v, raw, cs, depth, first)
+}
+
+func (wr *Writer) colorSlice(rv reflect.Value, depth int) {
+	if !wr.enter(rv, depth) {
+		return
+	}
+	wr.buf = append(wr.buf, wr.SyntaxColor...)
+	wr.buf = append(wr.buf, '[')
+	wr.buf = append(wr.buf, wr.NoColor...)
+	is, cs := wr.indents(depth)
+	first := true
+	end := rv.Len()
+	for j := 0; j < end; j++ {
+		if wr.cycles {
+			wr.stepIndex(j)
+		}
+		if wr.colorElement(j, end, rv.Index(j).Interface(), cs, depth+1, first) {
+			first = false
+		}
+	}
+	wr.buf = append(wr.buf, []byte(is)...)
+	wr.buf = append(wr.buf, wr.SyntaxColor...)
+	wr.buf = append(wr.buf, ']')
+	wr.leave()
+}
+
+func (wr *Writer) colorMap(rv reflect.Value, depth int) {
+	if !wr.enter(rv, depth) {
+		return
+	}
+	wr.buf = append(wr.buf, wr.SyntaxColor...)
+	wr.buf = append(wr.buf, '{')
+	wr.buf = append(wr.buf, wr.NoColor...)
+	is, cs := wr.indents(depth)
+	first := true
+	keys, names := alt.MapKeys(rv, wr.sorting)
+	for i, kv := range keys {
+		var key string
+		if names == nil {
+			key = kv.String()
+		} else {
+			key = names[i]
+		}
+		rm := rv.MapIndex(kv)
+		if wr.cycles {
+			wr.stepKey(key, rm)
+		}
+		if wr.colorMember(key, rm.Interface(), cs, depth+1, first) {
+			first = false
 		}
 	}
 	wr.buf = append(wr.buf, []byte(is)...)
 	wr.buf = append(wr.buf, wr.SyntaxColor...)
 	wr.buf = append(wr.buf, '}')
+	wr.leave()
+}
+
+// colorElement writes an array element with a separator if not first and
+// returns true unless the element is not selected by the Include and
+// Exclude options.
+func (wr *Writer) colorElement(index, size int, m any, cs string, depth int, first bool) bool {
+	var parent pathState
+	if wr.filtering {
+		var ok bool
+		if parent, ok = wr.pathEnter("", index, size, m); !ok {
+			return false
+		}
+	}
+	mark := len(wr.buf)
+	if !first {
+		wr.buf = append(wr.buf, wr.SyntaxColor...)
+		wr.buf = append(wr.buf, ',')
+		wr.buf = append(wr.buf, wr.NoColor...)
+	}
+	wr.buf = append(wr.buf, []byte(cs)...)
+	wr.colorJSON(m, depth)
+
+	return !wr.filtering || wr.pathLeave(parent, mark, true)
 }
@@ -256,3 +256,34 @@ func TestColorMustJSON(t *testing.T) {
 	}}
 	tt.Equal(t, "btruex", string(wr.MustJSON(true)))
 }
+
+func TestColorScheme(t *testing.T) {
+	scheme := ojg.ColorScheme{
+		Syntax:  "s",
+		Key:     "k",
+		Null:    "n",
+		Bool:    "b",
+		Number:  "0",
+		String:  "q",
+		Time:    "t",
+		NoColor: "x",
+	}
+	opt := oj.Options{Color: true, Sort: true, KeyColor: "K", ColorScheme: &scheme}
+	tt.Equal(t, `s{xk"a"xs:xs[x01xs,xnnullxs]xs,xk"b"xs:xbtruexs}x`,
+		oj.JSON(map[string]any{"a": []any{1, nil}, "b": true}, &opt))
+
+	opt.ColorScheme = &ojg.SolarizedLightColorScheme
+	tt.Equal(t, "\x1b[38;5;136mtrue\x1b[m", oj.JSON(true, &opt))
+}
+
+func TestColorHTMLClass(t *testing.T) {
+	opt := oj.HTMLClassOptions
+	opt.Sort = true
+	tt.Equal(t,
+		`<span class="ojg-syntax">{</span><span class="ojg-key">"a"</span><span class="ojg-syntax">:</span>`+
+			`<span class="ojg-string">"\u003cb\u003e"</span><span class="ojg-syntax">,</span>`+
+			`<span class="ojg-key">"n"</span><span class="ojg-syntax">:</span><span class="ojg-null">null</span>`+
+			`<span class="ojg-syntax">}</span>`,
+		oj.JSON(map[string]any{"a": "<b>", "n": nil}, &opt))
+	tt.Equal(t, true, strings.Contains(ojg.HTMLClassCSS, ".ojg-key"))
+}
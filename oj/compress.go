@@ -0,0 +1,63 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/ohler55/ojg"
+)
+
+// CompressorFunc returns a compressing writer that writes to w. The
+// returned writer is closed after the JSON has been written to it.
+type CompressorFunc func(w io.Writer) (io.WriteCloser, error)
+
+var (
+	compressorsMu sync.RWMutex
+	compressors   = map[string]CompressorFunc{
+		"gzip": func(w io.Writer) (io.WriteCloser, error) {
+			return gzip.NewWriter(w), nil
+		},
+		"deflate": func(w io.Writer) (io.WriteCloser, error) {
+			return flate.NewWriter(w, flate.DefaultCompression)
+		},
+	}
+)
+
+// RegisterCompressor registers a compressor for the codec name used with
+// WriteCompressed. The "gzip" and "deflate" codecs are registered by
+// default. Other codecs such as zstd are not part of the standard library
+// but can be registered with a function that wraps the package of choice.
+// Registering an existing name replaces the compressor.
+func RegisterCompressor(codec string, fun CompressorFunc) {
+	compressorsMu.Lock()
+	compressors[codec] = fun
+	compressorsMu.Unlock()
+}
+
+// WriteCompressed writes the JSON for the data to w compressed with the
+// codec named. The JSON is written to the compressor in chunks of the
+// WriteLimit size as it is generated so the full JSON is never held in
+// memory. The compressor is closed, flushing any remaining output, before
+// returning but w is not closed.
+func (wr *Writer) WriteCompressed(w io.Writer, data any, codec string) (err error) {
+	compressorsMu.RLock()
+	fun := compressors[codec]
+	compressorsMu.RUnlock()
+	if fun == nil {
+		return fmt.Errorf("%w: compression codec %q is not registered", ojg.ErrUnsupportedType, codec)
+	}
+	var cw io.WriteCloser
+	if cw, err = fun(w); err != nil {
+		return
+	}
+	if err = wr.Write(cw, data); err != nil {
+		_ = cw.Close()
+		return
+	}
+	return cw.Close()
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/tt"
+)
+
+type nopCompressor struct {
+	io.Writer
+	closed bool
+}
+
+func (nc *nopCompressor) Close() error {
+	nc.closed = true
+	return nil
+}
+
+func TestWriteCompressed(t *testing.T) {
+	data := []any{}
+	for i := 0; i < 1000; i++ {
+		data = append(data, map[string]any{"id": i, "name": strings.Repeat("x", i%10)})
+	}
+	wr := oj.Writer{Options: ojg.DefaultOptions}
+	wr.Sort = true
+	expect := oj.JSON(data, &wr.Options)
+	wr.WriteLimit = 512
+
+	var buf bytes.Buffer
+	err := wr.WriteCompressed(&buf, data, "gzip")
+	tt.Nil(t, err)
+	tt.Equal(t, true, buf.Len() < len(expect))
+	zr, err := gzip.NewReader(&buf)
+	tt.Nil(t, err)
+	out, err := io.ReadAll(zr)
+	tt.Nil(t, err)
+	tt.Equal(t, expect, string(out))
+
+	buf.Reset()
+	err = wr.WriteCompressed(&buf, data, "deflate")
+	tt.Nil(t, err)
+	out, err = io.ReadAll(flate.NewReader(&buf))
+	tt.Nil(t, err)
+	tt.Equal(t, expect, string(out))
+
+	var nc *nopCompressor
+	oj.RegisterCompressor("nop", func(w io.Writer) (io.WriteCloser, error) {
+		nc = &nopCompressor{Writer: w}
+		return nc, nil
+	})
+	buf.Reset()
+	err = wr.WriteCompressed(&buf, []any{1, 2}, "nop")
+	tt.Nil(t, err)
+	tt.Equal(t, "[1,2]", buf.String())
+	tt.Equal(t, true, nc.closed)
+
+	err = wr.WriteCompressed(&buf, data, "zstd")
+	tt.ErrorIs(t, err, ojg.ErrUnsupportedType)
+}
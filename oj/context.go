@@ -0,0 +1,148 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj
+
+import (
+	"context"
+	"io"
+
+	"github.com/ohler55/ojg"
+)
+
+// contextCheckInterval is the number of values written between checks of
+// the context when encoding with a context.
+const contextCheckInterval = 1024
+
+// MarshalContext is the same as Marshal except the context is checked
+// periodically while encoding. If the context is canceled or its deadline
+// passes the encoding stops and an error that matches the context error
+// with errors.Is is returned.
+func MarshalContext(ctx context.Context, data any, args ...any) (out []byte, err error) {
+	var wr *Writer
+	if 0 < len(args) {
+		wr = pickWriter(args[0], true)
+	}
+	if wr == nil {
+		wr, _ = marshalPool.Get().(*Writer)
+		defer marshalPool.Put(wr)
+	} else {
+		wr.strict = true
+	}
+	defer func() {
+		wr.ctx = nil
+		if r := recover(); r != nil {
+			wr.buf = wr.buf[:0]
+			err = ojg.NewError(r)
+		}
+	}()
+	wr.setContext(ctx)
+	wr.MustJSON(data)
+	out = make([]byte, len(wr.buf))
+	copy(out, wr.buf)
+
+	return
+}
+
+// WriteContext is the same as Write except the context is checked
+// periodically while encoding. If the context is canceled or its deadline
+// passes the encoding stops and an error that matches the context error
+// with errors.Is is returned. Output already written to w is not undone.
+func (wr *Writer) WriteContext(ctx context.Context, w io.Writer, data any) (err error) {
+	defer func() {
+		wr.ctx = nil
+		if r := recover(); r != nil {
+			wr.buf = wr.buf[:0]
+			err = ojg.NewError(r)
+		}
+	}()
+	wr.setContext(ctx)
+	wr.MustWrite(w, data)
+	return
+}
+
+func (wr *Writer) setContext(ctx context.Context) {
+	if err := ctx.Err(); err != nil {
+		panic(err)
+	}
+	if ctx.Done() != nil {
+		wr.ctx = ctx
+		wr.ctxCount = 0
+	}
+}
+
+func (wr *Writer) checkContext() {
+	wr.ctxCount++
+	if wr.ctxCount < contextCheckInterval {
+		return
+	}
+	wr.ctxCount = 0
+	if err := wr.ctx.Err(); err != nil {
+		panic(err)
+	}
+}
+
+// ParseReaderContext is the same as Load except the context is checked
+// before each read and a read that blocks, such as one from a stalled
+// socket, is abandoned when the context is canceled or its deadline
+// passes. The error returned in that case matches the context error with
+// errors.Is.
+func ParseReaderContext(ctx context.Context, r io.Reader, args ...any) (any, error) {
+	p := parserPool.Get().(*Parser)
+	defer parserPool.Put(p)
+	return p.ParseReaderContext(ctx, r, args...)
+}
+
+// ParseReaderContext is the same as ParseReader except the context is
+// checked before each read and a read that blocks is abandoned when the
+// context is canceled or its deadline passes. An abandoned read continues
+// in the background until the reader returns so the reader should be
+// closed by the caller once the context is done.
+func (p *Parser) ParseReaderContext(ctx context.Context, r io.Reader, args ...any) (any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if ctx.Done() != nil {
+		r = &contextReader{ctx: ctx, r: r, done: make(chan readResult, 1)}
+	}
+	return p.ParseReader(r, args...)
+}
+
+type readResult struct {
+	n   int
+	err error
+}
+
+// contextReader reads into a separate buffer in a goroutine so that a read
+// abandoned because the context is done can not write into a buffer that
+// has been returned to the caller.
+type contextReader struct {
+	ctx  context.Context
+	r    io.Reader
+	buf  []byte
+	done chan readResult
+}
+
+func (cr *contextReader) Read(b []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	if cap(cr.buf) < len(b) {
+		cr.buf = make([]byte, len(b))
+	}
+	buf := cr.buf[:len(b)]
+	done := cr.done
+	go func() {
+		n, err := cr.r.Read(buf)
+		done <- readResult{n: n, err: err}
+	}()
+	select {
+	case rr := <-done:
+		copy(b, buf[:rr.n])
+		return rr.n, rr.err
+	case <-cr.ctx.Done():
+		// The buffer and channel belong to the abandoned read now.
+		cr.buf = nil
+		cr.done = make(chan readResult, 1)
+		return 0, cr.ctx.Err()
+	}
+}
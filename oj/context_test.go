@@ -0,0 +1,85 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/tt"
+)
+
+func TestMarshalContext(t *testing.T) {
+	out, err := oj.MarshalContext(context.Background(), []any{1, true})
+	tt.Nil(t, err)
+	tt.Equal(t, "[1,true]", string(out))
+
+	type point struct {
+		X int
+		Y int
+	}
+	big := make([]point, 10000)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = oj.MarshalContext(ctx, big)
+	tt.Equal(t, true, errors.Is(err, context.Canceled))
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	list := make([]any, 10000)
+	for i := range list {
+		list[i] = map[string]any{"x": i}
+	}
+	var wr oj.Writer
+	w := cancelWriter{cancel: cancel}
+	wr.WriteLimit = 64
+	err = wr.WriteContext(ctx, &w, list)
+	tt.Equal(t, true, errors.Is(err, context.Canceled))
+	tt.Equal(t, true, w.n < 10000*len(`{"x":0},`))
+}
+
+type cancelWriter struct {
+	cancel func()
+	n      int
+}
+
+func (w *cancelWriter) Write(b []byte) (int, error) {
+	w.n += len(b)
+	w.cancel()
+	return len(b), nil
+}
+
+type stalledReader struct {
+	r       io.Reader
+	release chan struct{}
+}
+
+func (sr *stalledReader) Read(b []byte) (int, error) {
+	n, err := sr.r.Read(b)
+	if err == io.EOF {
+		<-sr.release
+	}
+	return n, err
+}
+
+func TestParseReaderContext(t *testing.T) {
+	v, err := oj.ParseReaderContext(context.Background(), strings.NewReader(`{"a":[1,2]}`))
+	tt.Nil(t, err)
+	tt.Equal(t, map[string]any{"a": []any{1, 2}}, v)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	sr := stalledReader{r: strings.NewReader(`[1,2,`), release: make(chan struct{})}
+	defer close(sr.release)
+	var p oj.Parser
+	_, err = p.ParseReaderContext(ctx, &sr)
+	tt.Equal(t, true, errors.Is(err, context.DeadlineExceeded))
+
+	_, err = p.ParseReaderContext(ctx, strings.NewReader(`[]`))
+	tt.Equal(t, true, errors.Is(err, context.DeadlineExceeded))
+}
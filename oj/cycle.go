@@ -0,0 +1,267 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj
+
+import (
+	"reflect"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/jp"
+)
+
+// cycleDepth is the nesting of reflected arrays and objects beyond which
+// cycles are looked for if the CycleRefs option is not set. As with
+// encoding/json, most data is never that deep so the cost of tracking what
+// is being written is only paid by data that is deep or has a cycle.
+const cycleDepth = 1000
+
+// refFrame is a reflected array or object being written along with the step
+// to the member currently being written. Reflected structs, maps, and slices
+// record their identity so that a value that contains itself is detected
+// before it recurses without end. Generic []any and map[string]any values
+// are not tracked so that writing them is not slowed down. The path through
+// them is only needed when a cycle is found and is found then by searching.
+type refFrame struct {
+	rv     reflect.Value
+	ptr    uintptr
+	rt     reflect.Type
+	size   int
+	key    string
+	index  int
+	field  []int
+	member reflect.Value
+}
+
+// tracking returns true if a frame is kept for the current level.
+func (wr *Writer) tracking() bool {
+	return wr.cycles || cycleDepth < wr.level
+}
+
+// enter starts writing a reflected array or object. If the MaxWriteDepth is
+// reached the TruncateMarker is written instead or if the value is already
+// being written a reference to it is written or a cycle error raised. In
+// those cases false is returned and nothing is pushed. Otherwise the level is
+// incremented and, if tracking, a frame is pushed.
+func (wr *Writer) enter(rv reflect.Value, depth int) bool {
+	if wr.truncated() {
+		wr.appendMarker(depth)
+		return false
+	}
+	wr.level++
+	if !wr.tracking() {
+		return true
+	}
+	f := refFrame{index: -1, rv: rv, rt: rv.Type()}
+	f.ptr, _, f.size = refIdentity(rv)
+	if f.ptr != 0 {
+		for i, a := range wr.frames {
+			if a.ptr == f.ptr && a.rt == f.rt && a.size == f.size {
+				wr.level--
+				wr.appendCycle(i, depth)
+				return false
+			}
+		}
+	}
+	wr.frames = append(wr.frames, f)
+
+	return true
+}
+
+// enterPlain starts writing an ordered map, sequence, or parallel chunk that
+// can not be part of a cycle detected by the writer but which is stepped
+// through on the way to one. As with enter, false is returned if the
+// TruncateMarker was written instead.
+func (wr *Writer) enterPlain(rv reflect.Value, depth int) bool {
+	if wr.truncated() {
+		wr.appendMarker(depth)
+		return false
+	}
+	wr.level++
+	if wr.tracking() {
+		wr.frames = append(wr.frames, refFrame{index: -1, rv: rv})
+	}
+	return true
+}
+
+func (wr *Writer) leave() {
+	if wr.tracking() {
+		wr.frames = wr.frames[:len(wr.frames)-1]
+	}
+	wr.level--
+}
+
+// stepKey steps to the member of a map or ordered map.
+func (wr *Writer) stepKey(key string, member reflect.Value) {
+	f := &wr.frames[len(wr.frames)-1]
+	f.key = key
+	f.index = -1
+	f.member = member
+}
+
+// stepField steps to a struct field.
+func (wr *Writer) stepField(fi *finfo) {
+	f := &wr.frames[len(wr.frames)-1]
+	f.key = fi.key
+	f.index = -1
+	f.field = fi.index
+}
+
+// stepIndex steps to an element of a reflected slice or array.
+func (wr *Writer) stepIndex(index int) {
+	wr.frames[len(wr.frames)-1].index = index
+}
+
+// stepMember steps to an element of a sequence or parallel chunk.
+func (wr *Writer) stepMember(index int, member reflect.Value) {
+	f := &wr.frames[len(wr.frames)-1]
+	f.index = index
+	f.member = member
+}
+
+// memberValue returns the member currently being written.
+func (f *refFrame) memberValue() reflect.Value {
+	switch {
+	case f.member.IsValid():
+		return f.member
+	case f.field != nil:
+		v, _ := f.rv.FieldByIndexErr(f.field)
+		return v
+	case 0 <= f.index && (f.rv.Kind() == reflect.Slice || f.rv.Kind() == reflect.Array):
+		return f.rv.Index(f.index)
+	}
+	return reflect.Value{}
+}
+
+// appendCycle writes a reference to the value of the frame at index i or
+// panics with an ErrCycle error if the CycleRefs option is not set.
+func (wr *Writer) appendCycle(i int, depth int) {
+	if !wr.cycles {
+		wr.cycleError()
+	}
+	to := wr.framesPath(i, &wr.frames[i])
+	if !wr.CycleRefs {
+		panic(ojg.Errorf(ojg.ErrCycle, "reference cycle at '%s' to '%s'",
+			wr.framesPath(len(wr.frames), &wr.frames[i]), to))
+	}
+	p, _ := to.Pointer()
+	wr.appendJSON(map[string]any{"$ref": "#" + p}, depth)
+}
+
+// cycleError panics with an ErrCycle error for a cycle found past the
+// cycleDepth. The steps through the frames are only recorded with the
+// CycleRefs option so the paths are found by writing the top level value
+// again while recording them.
+func (wr *Writer) cycleError() {
+	cw := Writer{
+		Options: wr.Options,
+		strict:  wr.strict,
+		Include: wr.Include,
+		Exclude: wr.Exclude,
+		Redact:  wr.Redact,
+		search:  true,
+	}
+	cw.MaxOutputBytes = 0
+	cw.prepare()
+	cw.root = wr.root
+	cw.appendJSON(cw.view(wr.root), 0)
+
+	panic(ojg.Errorf(ojg.ErrCycle, "reference cycle"))
+}
+
+// framesPath returns the path to the value of the target frame reached
+// through the first n frames. The steps through generic values between the
+// frames are found by searching from the root or the member of the previous
+// frame.
+func (wr *Writer) framesPath(n int, target *refFrame) jp.Expr {
+	x := jp.R()
+	v := reflect.ValueOf(wr.root)
+	for k := 0; k < n; k++ {
+		f := &wr.frames[k]
+		x = wr.genericPath(x, v, f)
+		if 0 <= f.index {
+			x = x.N(f.index)
+		} else {
+			x = x.C(f.key)
+		}
+		v = f.memberValue()
+	}
+	return wr.genericPath(x, v, target)
+}
+
+// genericPath appends the steps through []any and map[string]any values from
+// v to the value of the target frame. If the value is not found no steps
+// are appended.
+func (wr *Writer) genericPath(x jp.Expr, v reflect.Value, target *refFrame) jp.Expr {
+	ptr, rt, size := refIdentity(target.rv)
+	if ptr == 0 {
+		return x
+	}
+	var find func(v reflect.Value) jp.Expr
+	find = func(v reflect.Value) jp.Expr {
+		if !v.IsValid() {
+			return nil
+		}
+		for v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr {
+			if p, t, z := refIdentity(v); p == ptr && t == rt && z == size {
+				return x
+			}
+			if v.IsNil() {
+				return nil
+			}
+			v = v.Elem()
+		}
+		if p, t, z := refIdentity(v); p == ptr && t == rt && z == size {
+			return x
+		}
+		if !v.CanInterface() {
+			return nil
+		}
+		switch tv := v.Interface().(type) {
+		case []any:
+			for i, m := range tv {
+				x = append(x, jp.Nth(i))
+				if found := find(reflect.ValueOf(m)); found != nil {
+					return found
+				}
+				x = x[:len(x)-1]
+			}
+		case map[string]any:
+			for k, m := range tv {
+				if wr.redactKey(k) {
+					continue
+				}
+				x = append(x, jp.Child(k))
+				if found := find(reflect.ValueOf(m)); found != nil {
+					return found
+				}
+				x = x[:len(x)-1]
+			}
+		}
+		return nil
+	}
+	if found := find(v); found != nil {
+		return found
+	}
+	return x
+}
+
+// refIdentity returns the identity of a value that is used to detect
+// cycles.
+func refIdentity(rv reflect.Value) (ptr uintptr, rt reflect.Type, size int) {
+	if !rv.IsValid() {
+		return
+	}
+	rt = rv.Type()
+	switch rv.Kind() {
+	case reflect.Struct:
+		if rv.CanAddr() {
+			ptr = rv.UnsafeAddr()
+		}
+	case reflect.Map, reflect.Ptr, reflect.Func:
+		ptr = rv.Pointer()
+	case reflect.Slice:
+		ptr = rv.Pointer()
+		size = rv.Len()
+	}
+	return
+}
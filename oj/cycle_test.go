@@ -0,0 +1,112 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/jp"
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/tt"
+)
+
+type cycleNode struct {
+	Name string         `json:"name"`
+	Next *cycleNode     `json:"next,omitempty"`
+	Kids []*cycleNode   `json:"kids,omitempty"`
+	Refs map[string]any `json:"refs,omitempty"`
+}
+
+func TestWriteCycle(t *testing.T) {
+	a := &cycleNode{Name: "a"}
+	b := &cycleNode{Name: "b", Next: a}
+	a.Kids = []*cycleNode{b}
+
+	_, err := oj.Marshal(a)
+	tt.NotNil(t, err)
+	tt.Equal(t, true, errors.Is(err, ojg.ErrCycle))
+	tt.Equal(t, "reference cycle at '$.kids[0].next' to '$'", err.Error())
+
+	opt := ojg.Options{UseTags: true, CycleRefs: true, OmitNil: true}
+	tt.Equal(t, `{"kids":[{"name":"b","next":{"$ref":"#"}}],"name":"a"}`, oj.JSON(a, &opt))
+	opt.Indent = 2
+	tt.Equal(t, `{
+  "kids": [
+    {
+      "name": "b",
+      "next": {
+        "$ref": "#"
+      }
+    }
+  ],
+  "name": "a"
+}`, oj.JSON(a, &opt))
+
+	// Generic containers and maps are part of the path.
+	a.Kids = nil
+	b.Next = nil
+	b.Refs = map[string]any{"up/1": a}
+	a.Refs = map[string]any{"x": []any{b}}
+	opt.Indent = 0
+	tt.Equal(t, `[{"name":"a","refs":{"x":[{"name":"b","refs":{"up/1":{"$ref":"#/0"}}}]}}]`,
+		oj.JSON([]any{a}, &opt))
+	_, err = oj.Marshal([]any{a})
+	tt.Equal(t, "reference cycle at '$[0].refs.x[0].refs['up/1']' to '$[0]'", err.Error())
+
+	// The same value in more than one place is not a cycle.
+	c := &cycleNode{Name: "c"}
+	d := &cycleNode{Name: "d", Kids: []*cycleNode{c, c}}
+	tt.Equal(t, `{"kids":[{"name":"c"},{"name":"c"}],"name":"d"}`, oj.JSON(d, &ojg.Options{UseTags: true, OmitNil: true}))
+}
+
+func TestWriteDeepNoCycle(t *testing.T) {
+	// Deeper than where cycles are looked for without the CycleRefs option.
+	var head *cycleNode
+	for i := 0; i < 1500; i++ {
+		head = &cycleNode{Name: "n", Next: head}
+	}
+	out, err := oj.Marshal(head, &ojg.Options{UseTags: true, OmitNil: true})
+	tt.Nil(t, err)
+	tt.Equal(t, 1500, bytes.Count(out, []byte(`"name":"n"`)))
+
+	head.Next.Next.Next = head.Next
+	_, err = oj.Marshal(head, &ojg.Options{UseTags: true, OmitNil: true})
+	tt.Equal(t, "reference cycle at '$.next.next.next' to '$.next'", err.Error())
+}
+
+func TestWriteCycleGenericPath(t *testing.T) {
+	a := &cycleNode{Name: "a"}
+	a.Kids = []*cycleNode{{Name: "b", Next: a}}
+	list := make([]any, 20)
+	list[13] = map[string]any{"y": nil, "x": []any{true, a}}
+
+	for _, opt := range []*ojg.Options{
+		{},
+		{Indent: 2, Sort: true},
+		{Parallel: 4, ParallelMin: 2},
+		{Parallel: 4, ParallelMin: 2, Indent: 2},
+	} {
+		_, err := oj.Marshal(list, opt)
+		tt.Equal(t, "reference cycle at '$[13].x[1].kids[0].next' to '$[13].x[1]'", err.Error())
+	}
+	out := oj.JSON(list, &ojg.Options{UseTags: true, CycleRefs: true, OmitNil: true, Parallel: 4, ParallelMin: 2})
+	tt.Equal(t, `{"name":"b","next":{"$ref":"#/13/x/1"}}`, oj.JSON(jp.MustParseString("$[13].x[1].kids[0]").First(oj.MustParseString(out)), &ojg.Options{Sort: true}))
+}
+
+func TestBatchWriterCycle(t *testing.T) {
+	var b bytes.Buffer
+	bw := oj.NewBatchWriter(&b, 1024, time.Hour)
+	a := &cycleNode{Name: "a"}
+	a.Next = a
+	tt.ErrorIs(t, bw.Append(a), ojg.ErrCycle)
+
+	// Nothing is left over from the failed append.
+	a.Next = nil
+	tt.Nil(t, bw.Append(a))
+	tt.Nil(t, bw.Close())
+	tt.Equal(t, "{\"kids\":[],\"name\":\"a\",\"next\":null,\"refs\":{}}\n", b.String())
+}
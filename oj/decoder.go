@@ -0,0 +1,510 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/alt"
+)
+
+const (
+	tokenTopValue = iota
+	tokenArrayStart
+	tokenArrayValue
+	tokenArrayComma
+	tokenObjectStart
+	tokenObjectKey
+	tokenObjectColon
+	tokenObjectValue
+	tokenObjectComma
+
+	decoderReadSize = 4096
+)
+
+// Decoder reads and decodes JSON values from an input stream. It follows the
+// semantics of the encoding/json Decoder so that call sites using a
+// json.Decoder can switch to an oj.Decoder without other changes. Values
+// are read with Decode() or as a stream of tokens with Token() and the two
+// can be mixed.
+type Decoder struct {
+	r          io.Reader
+	buf        []byte
+	off        int   // read offset in buf
+	base       int64 // input offset of buf[0]
+	line       int   // line at the start of buf
+	lineStart  int64 // input offset of the start of the line
+	err        error // read error, io.EOF once the reader is exhausted
+	p          Parser
+	rec        *alt.Recomposer
+	useNumber  bool
+	tokenState int
+	tokenStack []int
+}
+
+// NewDecoder returns a new Decoder that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	d := Decoder{
+		r:    r,
+		line: 1,
+		rec:  alt.MustNewRecomposer("", nil),
+	}
+	d.p.num.ForceFloat = true
+	d.rec.RegisterUnmarshalerComposer(recomposeToJSON)
+
+	return &d
+}
+
+// UseNumber causes the Decoder to decode numbers as a json.Number instead
+// of as a float64 when the destination is an any.
+func (d *Decoder) UseNumber() {
+	d.useNumber = true
+}
+
+// DisallowUnknownFields causes the Decoder to return an error when the
+// destination is a struct and the input contains object keys which do not
+// match any field in the destination.
+func (d *Decoder) DisallowUnknownFields() {
+	d.rec.DisallowUnknownFields = true
+}
+
+// Buffered returns a reader of the data remaining in the Decoder's buffer.
+// The reader is valid until the next call to Decode() or Token().
+func (d *Decoder) Buffered() io.Reader {
+	return bytes.NewReader(d.buf[d.off:])
+}
+
+// InputOffset returns the input stream byte offset of the current decoder
+// position.
+func (d *Decoder) InputOffset() int64 {
+	return d.base + int64(d.off)
+}
+
+// More reports whether there is another element in the current array or
+// object being decoded.
+func (d *Decoder) More() bool {
+	c, err := d.peek()
+	return err == nil && c != ']' && c != '}'
+}
+
+// Decode reads the next JSON value from the input and stores it in the
+// value pointed to by v. Numbers decoded into an any are float64 unless
+// UseNumber() has been called.
+func (d *Decoder) Decode(v any) (err error) {
+	if err = d.tokenPrepareForDecode(); err != nil {
+		return
+	}
+	if !d.tokenValueAllowed() {
+		var c byte
+		if c, err = d.peek(); err == nil {
+			err = d.syntaxError(fmt.Sprintf("unexpected character '%c'", c))
+		}
+		return
+	}
+	var raw []byte
+	if raw, err = d.readValue(); err != nil {
+		return
+	}
+	if um, ok := v.(json.Unmarshaler); ok {
+		err = um.UnmarshalJSON(raw)
+		d.off += len(raw)
+		d.tokenValueEnd()
+		return
+	}
+	var val any
+	if d.useNumber {
+		sub := Decoder{buf: raw, err: io.EOF, line: 1, useNumber: true}
+		sub.p.num.ForceFloat = true
+		val, err = sub.buildValue()
+	} else {
+		val, err = d.p.Parse(raw, ojg.NumConvFloat64)
+	}
+	if err != nil {
+		return d.valueError(err)
+	}
+	d.off += len(raw)
+	d.tokenValueEnd()
+	_, err = d.rec.Recompose(val, v)
+
+	return
+}
+
+// Token returns the next JSON token in the input stream. At the end of the
+// input stream io.EOF is returned. Commas and colons are elided. Arrays and
+// objects start and end with a json.Delim. Strings, including object keys,
+// are returned as a string, numbers as a float64 or json.Number if
+// UseNumber() has been called, true and false as a bool, and null as nil.
+func (d *Decoder) Token() (any, error) {
+	for {
+		c, err := d.peek()
+		if err != nil {
+			return nil, err
+		}
+		switch c {
+		case '[':
+			if !d.tokenValueAllowed() {
+				return nil, d.tokenError(c)
+			}
+			d.off++
+			d.tokenStack = append(d.tokenStack, d.tokenState)
+			d.tokenState = tokenArrayStart
+			return json.Delim('['), nil
+		case ']':
+			if d.tokenState != tokenArrayStart && d.tokenState != tokenArrayComma {
+				return nil, d.tokenError(c)
+			}
+			d.off++
+			d.tokenState = d.tokenStack[len(d.tokenStack)-1]
+			d.tokenStack = d.tokenStack[:len(d.tokenStack)-1]
+			d.tokenValueEnd()
+			return json.Delim(']'), nil
+		case '{':
+			if !d.tokenValueAllowed() {
+				return nil, d.tokenError(c)
+			}
+			d.off++
+			d.tokenStack = append(d.tokenStack, d.tokenState)
+			d.tokenState = tokenObjectStart
+			return json.Delim('{'), nil
+		case '}':
+			if d.tokenState != tokenObjectStart && d.tokenState != tokenObjectComma {
+				return nil, d.tokenError(c)
+			}
+			d.off++
+			d.tokenState = d.tokenStack[len(d.tokenStack)-1]
+			d.tokenStack = d.tokenStack[:len(d.tokenStack)-1]
+			d.tokenValueEnd()
+			return json.Delim('}'), nil
+		case ':':
+			if d.tokenState != tokenObjectColon {
+				return nil, d.tokenError(c)
+			}
+			d.off++
+			d.tokenState = tokenObjectValue
+			continue
+		case ',':
+			switch d.tokenState {
+			case tokenArrayComma:
+				d.tokenState = tokenArrayValue
+			case tokenObjectComma:
+				d.tokenState = tokenObjectKey
+			default:
+				return nil, d.tokenError(c)
+			}
+			d.off++
+			continue
+		case '"':
+			if d.tokenState == tokenObjectStart || d.tokenState == tokenObjectKey {
+				v, err := d.scalar()
+				if err != nil {
+					return nil, err
+				}
+				d.tokenState = tokenObjectColon
+				return v, nil
+			}
+		}
+		if !d.tokenValueAllowed() {
+			return nil, d.tokenError(c)
+		}
+		v, err := d.scalar()
+		if err != nil {
+			return nil, err
+		}
+		d.tokenValueEnd()
+		return v, nil
+	}
+}
+
+func (d *Decoder) tokenPrepareForDecode() error {
+	switch d.tokenState {
+	case tokenArrayComma:
+		c, err := d.peek()
+		if err != nil {
+			return err
+		}
+		if c != ',' {
+			return d.syntaxError("expected comma after array element")
+		}
+		d.off++
+		d.tokenState = tokenArrayValue
+	case tokenObjectColon:
+		c, err := d.peek()
+		if err != nil {
+			return err
+		}
+		if c != ':' {
+			return d.syntaxError("expected colon after object key")
+		}
+		d.off++
+		d.tokenState = tokenObjectValue
+	}
+	return nil
+}
+
+func (d *Decoder) tokenValueAllowed() bool {
+	switch d.tokenState {
+	case tokenTopValue, tokenArrayStart, tokenArrayValue, tokenObjectValue:
+		return true
+	}
+	return false
+}
+
+func (d *Decoder) tokenValueEnd() {
+	switch d.tokenState {
+	case tokenArrayStart, tokenArrayValue:
+		d.tokenState = tokenArrayComma
+	case tokenObjectValue:
+		d.tokenState = tokenObjectComma
+	}
+}
+
+func (d *Decoder) tokenError(c byte) error {
+	var context string
+	switch d.tokenState {
+	case tokenTopValue, tokenArrayStart, tokenArrayValue, tokenObjectValue:
+		context = " looking for beginning of value"
+	case tokenArrayComma:
+		context = " after array element"
+	case tokenObjectStart, tokenObjectKey:
+		context = " looking for beginning of object key string"
+	case tokenObjectColon:
+		context = " after object key"
+	case tokenObjectComma:
+		context = " after object key:value pair"
+	}
+	return d.syntaxError(fmt.Sprintf("invalid character '%c'%s", c, context))
+}
+
+// scalar reads a string, number, true, false, or null.
+func (d *Decoder) scalar() (v any, err error) {
+	var raw []byte
+	if raw, err = d.readValue(); err != nil {
+		return
+	}
+	if v, err = d.p.Parse(raw, ojg.NumConvFloat64); err != nil {
+		return nil, d.valueError(err)
+	}
+	switch v.(type) {
+	case string, bool, nil:
+	case float64:
+		if d.useNumber {
+			v = json.Number(raw)
+		}
+	default:
+		return nil, d.syntaxError(fmt.Sprintf("invalid character '%c' looking for beginning of value", raw[0]))
+	}
+	d.off += len(raw)
+
+	return
+}
+
+// buildValue builds a value from tokens so that numbers can be returned as
+// json.Number values.
+func (d *Decoder) buildValue() (v any, err error) {
+	var tok any
+	if tok, err = d.Token(); err != nil {
+		return
+	}
+	switch tok {
+	case json.Delim('['):
+		list := []any{}
+		for d.More() {
+			var m any
+			if m, err = d.buildValue(); err != nil {
+				return
+			}
+			list = append(list, m)
+		}
+		if _, err = d.Token(); err == nil {
+			v = list
+		}
+	case json.Delim('{'):
+		obj := map[string]any{}
+		for d.More() {
+			var k any
+			if k, err = d.Token(); err != nil {
+				return
+			}
+			key, _ := k.(string)
+			if obj[key], err = d.buildValue(); err != nil {
+				return
+			}
+		}
+		if _, err = d.Token(); err == nil {
+			v = obj
+		}
+	default:
+		v = tok
+	}
+	return
+}
+
+// peek skips white space and returns the next byte without consuming it.
+func (d *Decoder) peek() (byte, error) {
+	for {
+		for ; d.off < len(d.buf); d.off++ {
+			switch d.buf[d.off] {
+			case ' ', '\t', '\n', '\r':
+			default:
+				return d.buf[d.off], nil
+			}
+		}
+		if d.err != nil {
+			return 0, d.err
+		}
+		d.fill()
+	}
+}
+
+// readValue returns the bytes of the next complete value starting at the
+// current offset without consuming them.
+func (d *Decoder) readValue() ([]byte, error) {
+	if _, err := d.peek(); err != nil {
+		return nil, err
+	}
+	var (
+		depth    int
+		inString bool
+		escape   bool
+	)
+	i := d.off
+	for {
+		for ; i < len(d.buf); i++ {
+			b := d.buf[i]
+			switch {
+			case inString:
+				switch {
+				case escape:
+					escape = false
+				case b == '\\':
+					escape = true
+				case b == '"':
+					inString = false
+					if depth == 0 {
+						return d.buf[d.off : i+1], nil
+					}
+				}
+			case b == '"':
+				if depth == 0 && i != d.off {
+					return d.buf[d.off:i], nil
+				}
+				inString = true
+			case b == '[' || b == '{':
+				if depth == 0 && i != d.off {
+					return d.buf[d.off:i], nil
+				}
+				depth++
+			case b == ']' || b == '}':
+				if depth == 0 {
+					if i == d.off {
+						return d.buf[d.off : i+1], nil
+					}
+					return d.buf[d.off:i], nil
+				}
+				depth--
+				if depth == 0 {
+					return d.buf[d.off : i+1], nil
+				}
+			case depth == 0 && (b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == ',' || b == ':'):
+				if i == d.off {
+					return d.buf[d.off : i+1], nil
+				}
+				return d.buf[d.off:i], nil
+			}
+		}
+		if d.err != nil {
+			if depth == 0 && !inString {
+				return d.buf[d.off:i], nil
+			}
+			if d.err == io.EOF {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, d.err
+		}
+		rel := i - d.off
+		d.fill()
+		i = d.off + rel
+	}
+}
+
+// fill reads more data into the buffer, discarding data that has already
+// been consumed.
+func (d *Decoder) fill() {
+	if 0 < d.off {
+		for i, b := range d.buf[:d.off] {
+			if b == '\n' {
+				d.line++
+				d.lineStart = d.base + int64(i) + 1
+			}
+		}
+		n := copy(d.buf, d.buf[d.off:])
+		d.buf = d.buf[:n]
+		d.base += int64(d.off)
+		d.off = 0
+	}
+	if cap(d.buf)-len(d.buf) < decoderReadSize {
+		buf := make([]byte, len(d.buf), 2*cap(d.buf)+decoderReadSize)
+		copy(buf, d.buf)
+		d.buf = buf
+	}
+	if d.r == nil {
+		d.err = io.EOF
+		return
+	}
+	for {
+		n, err := d.r.Read(d.buf[len(d.buf):cap(d.buf)])
+		d.buf = d.buf[:len(d.buf)+n]
+		if err != nil {
+			d.err = err
+			return
+		}
+		if 0 < n {
+			return
+		}
+	}
+}
+
+// position returns the line and column of the current offset.
+func (d *Decoder) position() (line, column int) {
+	line = d.line
+	start := d.lineStart
+	for i, b := range d.buf[:d.off] {
+		if b == '\n' {
+			line++
+			start = d.base + int64(i) + 1
+		}
+	}
+	return line, int(d.base+int64(d.off)-start) + 1
+}
+
+func (d *Decoder) syntaxError(msg string) error {
+	line, column := d.position()
+	err := &ParseError{
+		Message: msg,
+		Line:    line,
+		Column:  column,
+		Offset:  int(d.base) + d.off,
+		Kind:    ojg.ErrUnexpectedChar,
+	}
+	err.SetSnippet(d.buf, d.off)
+
+	return err
+}
+
+// valueError adjusts the position of a parse error from a value that starts
+// at the current offset.
+func (d *Decoder) valueError(err error) error {
+	if pe, ok := err.(*ParseError); ok {
+		line, column := d.position()
+		if pe.Line <= 1 {
+			pe.Column += column - 1
+		}
+		pe.Line += line - 1
+		pe.Offset += int(d.base) + d.off
+	}
+	return err
+}
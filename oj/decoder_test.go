@@ -0,0 +1,222 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/tt"
+)
+
+type decoderSample struct {
+	Name  string
+	Count int
+	Tags  []string
+}
+
+func decodeTokens(src string, useNumber bool, oneByte bool) (toks []any, err error) {
+	var r io.Reader = strings.NewReader(src)
+	if oneByte {
+		r = iotest.OneByteReader(r)
+	}
+	dec := oj.NewDecoder(r)
+	if useNumber {
+		dec.UseNumber()
+	}
+	for {
+		var tok any
+		if tok, err = dec.Token(); err != nil {
+			break
+		}
+		toks = append(toks, tok)
+	}
+	return
+}
+
+func TestDecoderToken(t *testing.T) {
+	for _, src := range []string{
+		`{"a": [1, 2.5, true, false, null], "b": {"c": "x\ty"}, "d": []}`,
+		`[] {} "x" 1 [[{}]]`,
+		`[{"a":1},{"b":[2,3]}] 12345678901234567890`,
+	} {
+		for _, oneByte := range []bool{false, true} {
+			for _, useNumber := range []bool{false, true} {
+				dec := json.NewDecoder(strings.NewReader(src))
+				if useNumber {
+					dec.UseNumber()
+				}
+				var expect []any
+				for {
+					tok, err := dec.Token()
+					if err != nil {
+						tt.Equal(t, io.EOF, err)
+						break
+					}
+					expect = append(expect, tok)
+				}
+				toks, err := decodeTokens(src, useNumber, oneByte)
+				tt.Equal(t, io.EOF, err, src)
+				tt.Equal(t, expect, toks, src)
+			}
+		}
+	}
+}
+
+func TestDecoderTokenError(t *testing.T) {
+	for _, d := range []struct {
+		src    string
+		expect string
+	}{
+		{src: `[1 2]`, expect: "invalid character '2' after array element at 1:4"},
+		{src: `{1:2}`, expect: "invalid character '1' looking for beginning of object key string at 1:2"},
+		{src: `{"a" 2}`, expect: "invalid character '2' after object key at 1:6"},
+		{src: `[1,]`, expect: "invalid character ']' looking for beginning of value at 1:4"},
+		{src: "[\n  tru]", expect: "incomplete JSON at 2:6"},
+		{src: `]`, expect: "invalid character ']' looking for beginning of value at 1:1"},
+	} {
+		_, err := decodeTokens(d.src, false, false)
+		tt.NotNil(t, err, d.src)
+		tt.Equal(t, d.expect, err.Error(), d.src)
+	}
+	_, err := decodeTokens(`["abc`, false, false)
+	tt.Equal(t, io.ErrUnexpectedEOF, err)
+}
+
+func TestDecoderTokenErrorPosition(t *testing.T) {
+	_, err := decodeTokens(`["abc`, false, false)
+	tt.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+	_, err = decodeTokens("[\n  tru]", false, false)
+	var pe *oj.ParseError
+	tt.ErrorAs(t, err, &pe)
+	tt.ErrorAt(t, err, "[\n  tru]", 2, 6)
+}
+
+func TestDecoderDecode(t *testing.T) {
+	src := `{"name":"one","count":1,"tags":["a","b"]}
+{"name":"two","count":2}
+[1,2,3]
+{"x": 1.5}`
+	dec := oj.NewDecoder(iotest.OneByteReader(strings.NewReader(src)))
+	var sample decoderSample
+	tt.Nil(t, dec.Decode(&sample))
+	tt.Equal(t, decoderSample{Name: "one", Count: 1, Tags: []string{"a", "b"}}, sample)
+
+	sample = decoderSample{}
+	tt.Nil(t, dec.Decode(&sample))
+	tt.Equal(t, decoderSample{Name: "two", Count: 2}, sample)
+
+	var list []int
+	tt.Nil(t, dec.Decode(&list))
+	tt.Equal(t, []int{1, 2, 3}, list)
+
+	var v any
+	tt.Nil(t, dec.Decode(&v))
+	tt.Equal(t, map[string]any{"x": 1.5}, v)
+
+	tt.Equal(t, io.EOF, dec.Decode(&v))
+	tt.Equal(t, int64(len(src)), dec.InputOffset())
+}
+
+func TestDecoderDecodeStream(t *testing.T) {
+	// Token and Decode mixed as with a json.Decoder to decode a large array
+	// one element at a time.
+	src := `{"items": [{"name":"one","count":1},{"name":"two","count":2}]}`
+	dec := oj.NewDecoder(strings.NewReader(src))
+	tok, err := dec.Token()
+	tt.Nil(t, err)
+	tt.Equal(t, json.Delim('{'), tok)
+	tok, err = dec.Token()
+	tt.Nil(t, err)
+	tt.Equal(t, "items", tok)
+	tok, err = dec.Token()
+	tt.Nil(t, err)
+	tt.Equal(t, json.Delim('['), tok)
+	var names []string
+	for dec.More() {
+		var sample decoderSample
+		tt.Nil(t, dec.Decode(&sample))
+		names = append(names, sample.Name)
+	}
+	tt.Equal(t, []string{"one", "two"}, names)
+	tok, err = dec.Token()
+	tt.Nil(t, err)
+	tt.Equal(t, json.Delim(']'), tok)
+	tok, err = dec.Token()
+	tt.Nil(t, err)
+	tt.Equal(t, json.Delim('}'), tok)
+	_, err = dec.Token()
+	tt.Equal(t, io.EOF, err)
+}
+
+func TestDecoderUseNumber(t *testing.T) {
+	dec := oj.NewDecoder(strings.NewReader(`{"a": 1.50, "b": [12345678901234567890, -3]}`))
+	dec.UseNumber()
+	var v any
+	tt.Nil(t, dec.Decode(&v))
+	tt.Equal(t, map[string]any{
+		"a": json.Number("1.50"),
+		"b": []any{json.Number("12345678901234567890"), json.Number("-3")},
+	}, v)
+
+	dec = oj.NewDecoder(strings.NewReader(`{"name": "x", "count": 7}`))
+	dec.UseNumber()
+	var sample decoderSample
+	tt.Nil(t, dec.Decode(&sample))
+	tt.Equal(t, 7, sample.Count)
+}
+
+func TestDecoderDisallowUnknownFields(t *testing.T) {
+	src := `{"name":"one","count":1,"extra":true}`
+	var sample decoderSample
+	tt.Nil(t, oj.NewDecoder(strings.NewReader(src)).Decode(&sample))
+
+	dec := oj.NewDecoder(strings.NewReader(src))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(&sample)
+	tt.NotNil(t, err)
+	tt.Equal(t, true, strings.Contains(err.Error(), `unknown field "extra"`), err.Error())
+}
+
+func TestDecoderBuffered(t *testing.T) {
+	dec := oj.NewDecoder(strings.NewReader(`{"a":1} trailing`))
+	var v any
+	tt.Nil(t, dec.Decode(&v))
+	rest, err := io.ReadAll(dec.Buffered())
+	tt.Nil(t, err)
+	tt.Equal(t, " trailing", string(rest))
+	tt.Equal(t, int64(7), dec.InputOffset())
+}
+
+type decoderUnmarshaler struct {
+	raw string
+}
+
+func (du *decoderUnmarshaler) UnmarshalJSON(b []byte) error {
+	du.raw = string(b)
+	return nil
+}
+
+func TestDecoderUnmarshaler(t *testing.T) {
+	dec := oj.NewDecoder(strings.NewReader(`{"a": [1, 2]} 3`))
+	var du decoderUnmarshaler
+	tt.Nil(t, dec.Decode(&du))
+	tt.Equal(t, `{"a": [1, 2]}`, du.raw)
+}
+
+func TestDecoderReadError(t *testing.T) {
+	dec := oj.NewDecoder(iotest.ErrReader(errors.New("read failed")))
+	var v any
+	err := dec.Decode(&v)
+	tt.NotNil(t, err)
+	tt.Equal(t, "read failed", err.Error())
+
+	dec = oj.NewDecoder(iotest.ErrReader(errors.New("read failed")))
+	tt.NotPanics(t, func() { _ = dec.Decode(&v) })
+}
@@ -0,0 +1,169 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/alt"
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/sen"
+	"github.com/ohler55/ojg/tt"
+)
+
+type EmbedBase struct {
+	ID   int
+	Name string
+}
+
+type EmbedOther struct {
+	Name  string
+	Other int
+}
+
+type embedHidden struct {
+	Secret int
+	Shown  string `json:"shown"`
+}
+
+type EmbedInt int
+
+type embedInt int
+
+type EmbedTagged struct {
+	Name string `json:"name"`
+}
+
+type EmbedNode struct {
+	*EmbedNode
+	Value int
+}
+
+// Name is hidden by the outer field.
+type embedShadow struct {
+	EmbedBase
+	Name string
+}
+
+// Name conflicts at the same depth and is dropped.
+type embedConflict struct {
+	EmbedBase
+	EmbedOther
+}
+
+// The tagged Name wins the conflict.
+type embedTagWins struct {
+	EmbedBase
+	EmbedTagged
+}
+
+type embedPointers struct {
+	*EmbedBase
+	*EmbedOther
+	Extra bool
+}
+
+type embedUnexported struct {
+	embedHidden
+	embedInt
+	EmbedInt
+}
+
+type embedNamed struct {
+	EmbedBase  `json:"base"`
+	EmbedOther `json:"-"`
+	X          int
+}
+
+type embedDeep struct {
+	embedShadow
+	ID string
+}
+
+// The two EmbedBase copies at the same depth cancel.
+type embedTwice struct {
+	embedShadow
+	embedConflict
+}
+
+func TestEmbeddedCompatibility(t *testing.T) {
+	for _, v := range []any{
+		&embedShadow{EmbedBase: EmbedBase{ID: 1, Name: "inner"}, Name: "outer"},
+		&embedConflict{EmbedBase: EmbedBase{ID: 1, Name: "a"}, EmbedOther: EmbedOther{Name: "b", Other: 2}},
+		&embedTagWins{EmbedBase: EmbedBase{ID: 1, Name: "a"}, EmbedTagged: EmbedTagged{Name: "b"}},
+		&embedPointers{EmbedBase: &EmbedBase{ID: 1, Name: "a"}, Extra: true},
+		&embedPointers{},
+		&embedUnexported{embedHidden: embedHidden{Secret: 7, Shown: "yes"}, embedInt: 3, EmbedInt: 4},
+		&embedNamed{EmbedBase: EmbedBase{ID: 1}, EmbedOther: EmbedOther{Other: 2}, X: 3},
+		&embedDeep{embedShadow: embedShadow{EmbedBase: EmbedBase{ID: 1, Name: "a"}, Name: "b"}, ID: "top"},
+		&embedTwice{},
+		&EmbedNode{EmbedNode: &EmbedNode{Value: 1}, Value: 2},
+	} {
+		name := fmt.Sprintf("%T", v)
+		std, err := json.Marshal(v)
+		tt.Nil(t, err, name)
+		expect := oj.MustParse(std)
+		for _, opt := range []*ojg.Options{&ojg.GoOptions, {UseTags: true, KeyExact: true, Indent: 2}} {
+			js, err := oj.Marshal(v, opt)
+			tt.Nil(t, err, name)
+			tt.Equal(t, expect, oj.MustParse(js), name)
+		}
+		tt.Equal(t, expect, sen.MustParse(sen.Bytes(v, &ojg.GoOptions)), name)
+		tt.Equal(t, expect, alt.Decompose(v, &ojg.GoOptions), name)
+
+		stdBack := reflect.New(reflect.TypeOf(v).Elem()).Interface()
+		tt.Nil(t, json.Unmarshal(std, stdBack), name)
+		back := reflect.New(reflect.TypeOf(v).Elem()).Interface()
+		tt.Nil(t, oj.Unmarshal(std, back), name)
+		tt.Equal(t, stdBack, back, name)
+	}
+}
+
+type inlineMeta struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+type inlineSpec struct {
+	Replicas int `json:"replicas"`
+}
+
+type inlineObject struct {
+	Kind string      `json:"kind"`
+	Meta inlineMeta  `json:",inline"`
+	Spec *inlineSpec `json:"spec,squash"`
+	Size int         `json:"size"`
+}
+
+func TestInlineFields(t *testing.T) {
+	obj := inlineObject{
+		Kind: "Pod",
+		Meta: inlineMeta{Name: "web", Labels: map[string]string{"app": "web"}},
+		Spec: &inlineSpec{Replicas: 3},
+		Size: 7,
+	}
+	expect := map[string]any{"kind": "Pod", "name": "web", "labels": map[string]any{"app": "web"}, "replicas": 3, "size": 7}
+
+	j, err := oj.Marshal(&obj)
+	tt.Nil(t, err)
+	tt.Equal(t, expect, oj.MustParse(j))
+	tt.Equal(t, expect, oj.MustParse([]byte(oj.JSON(&obj, &ojg.Options{UseTags: true, Indent: 2}))))
+	tt.Equal(t, expect, sen.MustParse([]byte(sen.String(&obj, &ojg.Options{UseTags: true}))))
+	tt.Equal(t, expect, alt.Decompose(&obj, &ojg.Options{UseTags: true}))
+
+	// A nil inline pointer has no fields to write.
+	j, err = oj.Marshal(&inlineObject{Kind: "Pod"})
+	tt.Nil(t, err)
+	tt.Equal(t, map[string]any{"kind": "Pod", "name": "", "size": 0}, oj.MustParse(j))
+
+	var back inlineObject
+	tt.Nil(t, oj.Unmarshal([]byte(`{"kind":"Pod","name":"web","labels":{"app":"web"},"replicas":3,"size":7}`), &back))
+	tt.Equal(t, obj.Meta, back.Meta)
+	tt.NotNil(t, back.Spec)
+	tt.Equal(t, 3, back.Spec.Replicas)
+	tt.Equal(t, 7, back.Size)
+}
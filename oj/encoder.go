@@ -0,0 +1,109 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj
+
+import (
+	"io"
+	"strings"
+
+	"github.com/ohler55/ojg"
+)
+
+// Encoder writes JSON values to an output stream. It follows the API of the
+// encoding/json Encoder so that call sites using a json.Encoder can switch
+// to an oj.Encoder without other changes.
+type Encoder struct {
+	w      io.Writer
+	wr     Writer
+	prefix string
+	indent string
+	buf    []byte
+	redo   bool // leading spaces replaced by the indent after the prefix
+}
+
+// NewEncoder returns a new Encoder that writes to w. The args, if supplied,
+// are the same as for Marshal(). HTML characters are escaped by default.
+func NewEncoder(w io.Writer, args ...any) *Encoder {
+	e := Encoder{w: w}
+	var wr *Writer
+	if 0 < len(args) {
+		wr = pickWriter(args[0], true)
+	}
+	if wr == nil {
+		e.wr = Writer{Options: goOptions, buf: make([]byte, 0, 1024)}
+	} else {
+		e.wr = *wr
+	}
+	e.wr.strict = true
+
+	return &e
+}
+
+// Encode writes the JSON encoding of v to the stream followed by a newline.
+// Nothing is written if an error occurs.
+func (e *Encoder) Encode(v any) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			e.wr.buf = e.wr.buf[:0]
+			err = ojg.NewError(r)
+		}
+	}()
+	buf := e.wr.MustJSON(v)
+	if e.redo {
+		buf = e.reindent(buf)
+	}
+	buf = append(buf, '\n')
+	_, err = e.w.Write(buf)
+
+	return
+}
+
+// SetIndent instructs the encoder to format each subsequent encoded value as
+// if indented by the encoding/json package function Indent(dst, src, prefix,
+// indent). Calling SetIndent("", "") disables indentation.
+func (e *Encoder) SetIndent(prefix, indent string) {
+	e.prefix = prefix
+	e.indent = indent
+	e.wr.Tab = false
+	e.wr.Indent = 0
+	e.redo = false
+	switch {
+	case len(indent) == 0 && len(prefix) == 0:
+	case 0 < len(indent) && len(prefix) == 0 && strings.Trim(indent, " ") == "":
+		e.wr.Indent = len(indent)
+	default:
+		// Write with a space per level and then replace the leading spaces
+		// on each line. Strings in JSON never include a raw newline so the
+		// spaces at the start of a line are all indentation.
+		e.wr.Indent = 1
+		e.redo = true
+	}
+}
+
+// SetEscapeHTML specifies whether the characters <, >, and & should be
+// escaped in JSON strings. The default is true.
+func (e *Encoder) SetEscapeHTML(on bool) {
+	e.wr.HTMLUnsafe = !on
+}
+
+func (e *Encoder) reindent(src []byte) []byte {
+	buf := e.buf[:0]
+	start := true
+	for _, b := range src {
+		switch {
+		case start && b == ' ':
+			buf = append(buf, e.indent...)
+			continue
+		case b == '\n':
+			buf = append(buf, b)
+			buf = append(buf, e.prefix...)
+			start = true
+			continue
+		}
+		start = false
+		buf = append(buf, b)
+	}
+	e.buf = buf
+
+	return buf
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/tt"
+)
+
+type encoderSample struct {
+	List []any  `json:"list"`
+	Name string `json:"name"`
+	Tag  string `json:"tag,omitempty"`
+}
+
+func TestEncoderEncode(t *testing.T) {
+	values := []any{
+		&encoderSample{Name: "<one>", List: []any{1, 2.5, true, nil, map[string]any{"a": []any{}}}},
+		[]any{map[string]any{"x": "a&b"}, []any{}},
+		"done",
+	}
+	for _, indent := range [][2]string{{"", ""}, {"", "  "}, {"", "\t"}, {">", "--"}, {"# ", ""}} {
+		for _, escape := range []bool{true, false} {
+			var expect strings.Builder
+			jenc := json.NewEncoder(&expect)
+			jenc.SetIndent(indent[0], indent[1])
+			jenc.SetEscapeHTML(escape)
+
+			var actual strings.Builder
+			enc := oj.NewEncoder(&actual)
+			enc.SetIndent(indent[0], indent[1])
+			enc.SetEscapeHTML(escape)
+			for _, v := range values {
+				tt.Nil(t, jenc.Encode(v))
+				tt.Nil(t, enc.Encode(v))
+			}
+			tt.Equal(t, expect.String(), actual.String(), fmt.Sprintf("%q %v", indent, escape))
+		}
+	}
+}
+
+func TestEncoderOptions(t *testing.T) {
+	var out strings.Builder
+	enc := oj.NewEncoder(&out, &oj.Options{Sort: true, UseTags: true})
+	tt.Nil(t, enc.Encode(map[string]any{"b": 1, "a": 2}))
+	tt.Nil(t, enc.Encode(&encoderSample{List: []any{1}, Name: "x", Tag: "y"}))
+	tt.Equal(t, `{"a":2,"b":1}
+{"list":[1],"name":"x","tag":"y"}
+`, out.String())
+}
+
+func TestEncoderError(t *testing.T) {
+	var out strings.Builder
+	enc := oj.NewEncoder(&out)
+	err := enc.Encode([]any{1, func() {}})
+	tt.NotNil(t, err)
+	tt.Equal(t, "", out.String())
+
+	enc = oj.NewEncoder(&shortWriter{max: 3})
+	tt.NotNil(t, enc.Encode([]any{1, 2, 3}))
+}
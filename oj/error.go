@@ -2,16 +2,81 @@
 
 package oj
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ohler55/ojg"
+)
+
+// snippetSide is the maximum number of bytes on each side of an error that
+// are included in a ParseError snippet.
+const snippetSide = 20
 
 // ParseError represents a parse error.
 type ParseError struct {
 	Message string
 	Line    int
 	Column  int
+
+	// Offset is the byte offset of the error from the start of the input.
+	Offset int
+
+	// Snippet is the input on the same line around the error. It is empty
+	// if the input is not available.
+	Snippet string
+
+	// SnippetOffset is the offset of the error in the Snippet.
+	SnippetOffset int
+
+	// Kind is the class of the error, one of the ojg.ErrXxx parse error
+	// sentinels such as ojg.ErrInvalidNumber, or nil if the error has no
+	// class other than ojg.ErrSyntax.
+	Kind error
 }
 
 // Error returns a string representation of the error.
 func (err *ParseError) Error() string {
 	return fmt.Sprintf("%s at %d:%d", err.Message, err.Line, err.Column)
 }
+
+// Is returns true if the target is ojg.ErrSyntax or the Kind of the error.
+func (err *ParseError) Is(target error) bool {
+	return target == ojg.ErrSyntax || (err.Kind != nil && target == err.Kind)
+}
+
+// Context returns the snippet followed by a line with a caret under the
+// location of the error. An empty string is returned if there is no
+// snippet.
+func (err *ParseError) Context() string {
+	if len(err.Snippet) == 0 {
+		return ""
+	}
+	return err.Snippet + "\n" + strings.Repeat(" ", err.SnippetOffset) + "^"
+}
+
+// SetSnippet sets the Snippet and SnippetOffset of the error from the
+// buffer and the offset of the error in the buffer. Tabs are replaced with
+// spaces so the caret of Context lines up with the error.
+func (err *ParseError) SetSnippet(buf []byte, off int) {
+	off = min(max(off, 0), len(buf))
+	start := max(off-snippetSide, 0)
+	end := min(off+snippetSide, len(buf))
+	for i := off - 1; start <= i; i-- {
+		if buf[i] == '\n' || buf[i] == '\r' {
+			start = i + 1
+			break
+		}
+	}
+	for i := off; i < end; i++ {
+		if buf[i] == '\n' || buf[i] == '\r' {
+			end = i
+			break
+		}
+	}
+	if end <= start {
+		return
+	}
+	err.Snippet = strings.ReplaceAll(string(buf[start:end]), "\t", " ")
+	err.SnippetOffset = off - start
+}
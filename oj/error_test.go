@@ -0,0 +1,83 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/sen"
+	"github.com/ohler55/ojg/tt"
+)
+
+func TestParseErrorDetails(t *testing.T) {
+	for _, d := range []struct {
+		src     string
+		kind    error
+		offset  int
+		line    int
+		column  int
+		context string
+	}{
+		{src: `{"a": 1,}`, kind: ojg.ErrUnexpectedChar, offset: 8, line: 1, column: 9, context: "{\"a\": 1,}\n        ^"},
+		{src: "[1,\n 2.x]", kind: ojg.ErrInvalidNumber, offset: 7, line: 2, column: 4, context: " 2.x]\n   ^"},
+		{src: `["a\qb"]`, kind: ojg.ErrInvalidString, offset: 4, line: 1, column: 5, context: "[\"a\\qb\"]\n    ^"},
+		{src: `[tru]`, kind: ojg.ErrInvalidLiteral, offset: 4, line: 1, column: 5, context: "[tru]\n    ^"},
+		{src: `{"a": [1, 2`, kind: ojg.ErrUnexpectedEOF, offset: 11, line: 1, column: 12, context: "{\"a\": [1, 2\n           ^"},
+	} {
+		var p oj.Parser
+		_, err := p.Parse([]byte(d.src))
+		tt.NotNil(t, err, d.src)
+		tt.Equal(t, true, errors.Is(err, ojg.ErrSyntax), d.src)
+		tt.Equal(t, true, errors.Is(err, d.kind), d.src)
+		var pe *oj.ParseError
+		tt.Equal(t, true, errors.As(err, &pe), d.src)
+		tt.Equal(t, d.offset, pe.Offset, d.src)
+		tt.Equal(t, d.line, pe.Line, d.src)
+		tt.Equal(t, d.column, pe.Column, d.src)
+		tt.Equal(t, d.context, pe.Context(), d.src)
+
+		// The Validator, Tokenizer, and a reader report the same location.
+		err = oj.Validate([]byte(d.src))
+		tt.Equal(t, true, errors.As(err, &pe), d.src)
+		tt.Equal(t, d.offset, pe.Offset, d.src)
+		tt.Equal(t, true, errors.Is(err, d.kind), d.src)
+
+		// The Tokenizer does not report incomplete JSON.
+		if d.kind != ojg.ErrUnexpectedEOF {
+			err = oj.TokenizeString(d.src, &oj.ZeroHandler{})
+			tt.Equal(t, true, errors.As(err, &pe), d.src)
+			tt.Equal(t, d.offset, pe.Offset, d.src)
+			tt.Equal(t, true, errors.Is(err, d.kind), d.src)
+		}
+
+		_, err = p.ParseReader(strings.NewReader(d.src))
+		tt.Equal(t, true, errors.As(err, &pe), d.src)
+		tt.Equal(t, d.offset, pe.Offset, d.src)
+
+	}
+	// Offsets and columns continue across reader buffers.
+	var p oj.Parser
+	src := strings.Repeat(" ", 5000) + "[1,,2]"
+	_, err := p.ParseReader(strings.NewReader(src))
+	var pe *oj.ParseError
+	tt.Equal(t, true, errors.As(err, &pe))
+	tt.Equal(t, 5003, pe.Offset)
+	tt.Equal(t, 5004, pe.Column)
+	tt.Equal(t, strings.Repeat(" ", 17)+"[1,,2]\n"+strings.Repeat(" ", 20)+"^", pe.Context())
+
+	// The SEN parser errors are also classified.
+	_, err = sen.Parse([]byte("[1 2.x]"))
+	tt.Equal(t, true, errors.Is(err, ojg.ErrInvalidNumber))
+	tt.Equal(t, true, errors.As(err, &pe))
+	tt.Equal(t, 5, pe.Offset)
+
+	// A long line is clipped in the snippet.
+	_, err = p.Parse([]byte(`[` + strings.Repeat(`"abcdefghij",`, 10) + `x]`))
+	tt.Equal(t, true, errors.As(err, &pe))
+	tt.Equal(t, `fghij","abcdefghij",x]`, pe.Snippet)
+	tt.Equal(t, 20, pe.SnippetOffset)
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/ohler55/ojg"
+)
+
+// ETag returns a strong HTTP entity tag for the data along with the JSON
+// that the tag was calculated from. The JSON is written in canonical form
+// with object keys sorted so equal documents produce the same tag
+// regardless of map iteration order. Since the JSON is returned as well the
+// data only needs to be marshalled once to both tag and send a response.
+// The tag is the quoted hex encoding of the first 16 bytes of the SHA-256
+// hash of the JSON. If opts is provided the first entry is used for
+// writing except that the Sort option is always set.
+func ETag(data any, opts ...*Options) (etag string, body []byte, err error) {
+	wr := Writer{Options: goOptions, buf: make([]byte, 0, 1024), strict: true}
+	if 0 < len(opts) && opts[0] != nil {
+		wr.Options = *opts[0]
+	}
+	wr.Sort = true
+	defer func() {
+		if r := recover(); r != nil {
+			etag = ""
+			body = nil
+			err = ojg.NewError(r)
+		}
+	}()
+	wr.MustJSON(data)
+	body = wr.buf
+	sum := sha256.Sum256(body)
+	var buf [34]byte
+	buf[0] = '"'
+	hex.Encode(buf[1:33], sum[:16])
+	buf[33] = '"'
+	etag = string(buf[:])
+
+	return
+}
@@ -0,0 +1,35 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj_test
+
+import (
+	"testing"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/tt"
+)
+
+func TestETag(t *testing.T) {
+	etag, body, err := oj.ETag(map[string]any{"b": 2, "a": []any{1, "x"}})
+	tt.Nil(t, err)
+	tt.Equal(t, `{"a":[1,"x"],"b":2}`, string(body))
+	tt.Equal(t, 34, len(etag))
+	tt.Equal(t, `"`, etag[:1])
+
+	etag2, _, err := oj.ETag(map[string]any{"a": []any{1, "x"}, "b": 2})
+	tt.Nil(t, err)
+	tt.Equal(t, etag, etag2)
+
+	etag2, _, err = oj.ETag(map[string]any{"a": []any{1, "y"}, "b": 2})
+	tt.Nil(t, err)
+	tt.NotEqual(t, etag, etag2)
+
+	etag2, body, err = oj.ETag(map[string]any{"b": 2, "a": 1}, &ojg.Options{Indent: 2})
+	tt.Nil(t, err)
+	tt.Equal(t, "{\n  \"a\": 1,\n  \"b\": 2\n}", string(body))
+	tt.Equal(t, 34, len(etag2))
+
+	_, _, err = oj.ETag(func() {})
+	tt.NotNil(t, err)
+}
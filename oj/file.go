@@ -0,0 +1,35 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj
+
+import "os"
+
+// ParseFile parses the JSON in the file at the path. The args are the same
+// as for Parse.
+func ParseFile(path string, args ...any) (any, error) {
+	p := parserPool.Get().(*Parser)
+	defer parserPool.Put(p)
+	return p.ParseFile(path, args...)
+}
+
+// ParseFile parses the JSON in the file at the path. Where supported the
+// file is memory mapped and parsed in place without being copied into a
+// read buffer. Elsewhere, or if the file can not be mapped, it is read
+// with ParseReader. The file must not be truncated while it is being
+// parsed. ZeroCopy is ignored since the mapped memory is released before
+// returning. The args are the same as for Parse.
+func (p *Parser) ParseFile(path string, args ...any) (data any, err error) {
+	var f *os.File
+	if f, err = os.Open(path); err != nil {
+		return
+	}
+	defer func() { _ = f.Close() }()
+
+	if buf, unmap := mapFile(f); buf != nil {
+		defer unmap()
+		defer func(zeroCopy bool) { p.ZeroCopy = zeroCopy }(p.ZeroCopy)
+		p.ZeroCopy = false
+		return p.Parse(buf, args...)
+	}
+	return p.ParseReader(f, args...)
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/tt"
+)
+
+func TestParseFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sample.json")
+	tt.Nil(t, os.WriteFile(path, []byte(`{"a":[1,2.5,"three"],"b":{"c":null}}`), 0600))
+
+	v, err := oj.ParseFile(path)
+	tt.Nil(t, err)
+	tt.Equal(t, map[string]any{"a": []any{1, 2.5, "three"}, "b": map[string]any{"c": nil}}, v)
+
+	// Strings are copied even with ZeroCopy since the file is unmapped.
+	p := oj.Parser{ZeroCopy: true}
+	v, err = p.ParseFile(path)
+	tt.Nil(t, err)
+	tt.Equal(t, "three", v.(map[string]any)["a"].([]any)[2])
+	tt.Equal(t, true, p.ZeroCopy)
+
+	many := filepath.Join(dir, "many.json")
+	tt.Nil(t, os.WriteFile(many, []byte("1\n[2]\n{\"x\":3}\n"), 0600))
+	var results []any
+	_, err = oj.ParseFile(many, func(v any) { results = append(results, v) })
+	tt.Nil(t, err)
+	tt.Equal(t, []any{1, []any{2}, map[string]any{"x": 3}}, results)
+
+	empty := filepath.Join(dir, "empty.json")
+	tt.Nil(t, os.WriteFile(empty, nil, 0600))
+	v, err = oj.ParseFile(empty)
+	tt.Nil(t, err)
+	tt.Nil(t, v)
+
+	bad := filepath.Join(dir, "bad.json")
+	tt.Nil(t, os.WriteFile(bad, []byte(`{"a":`), 0600))
+	_, err = oj.ParseFile(bad)
+	tt.NotNil(t, err)
+
+	_, err = oj.ParseFile(filepath.Join(dir, "missing.json"))
+	tt.NotNil(t, err)
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/ohler55/ojg"
+)
+
+// JSONFormat is the ojg.Format for JSON. It is registered with the name
+// "json".
+var JSONFormat ojg.Format = jsonFormat{}
+
+func init() {
+	ojg.RegisterFormat(JSONFormat)
+}
+
+type jsonFormat struct{}
+
+func (jsonFormat) Name() string {
+	return "json"
+}
+
+func (jsonFormat) Parse(buf []byte) (any, error) {
+	var p Parser
+	return p.Parse(buf)
+}
+
+func (jsonFormat) ParseReader(r io.Reader, cb func(any)) error {
+	var p Parser
+	_, err := p.ParseReader(r, cb)
+	return err
+}
+
+func (jsonFormat) Tokenize(buf []byte, handler ojg.TokenHandler) error {
+	var t Tokenizer
+	return t.Parse(buf, handler)
+}
+
+func (jsonFormat) TokenizeLoad(r io.Reader, handler ojg.TokenHandler) error {
+	return TokenizeLoad(r, handler)
+}
+
+func (jsonFormat) Write(w io.Writer, data any, opts *ojg.Options) error {
+	wr := Writer{Options: ojg.DefaultOptions}
+	if opts != nil {
+		wr.Options = *opts
+	}
+	return wr.Write(w, data)
+}
+
+func (f jsonFormat) Bytes(data any, opts *ojg.Options) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := f.Write(&buf, data, opts); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/sen"
+	"github.com/ohler55/ojg/tt"
+)
+
+func TestFormat(t *testing.T) {
+	tt.Equal(t, []string{"json", "sen"}, ojg.FormatNames())
+	tt.Equal(t, true, ojg.LookupFormat("json") == oj.JSONFormat)
+	tt.Equal(t, true, ojg.LookupFormat("sen") == sen.SENFormat)
+	tt.Nil(t, ojg.LookupFormat("yaml"))
+
+	for _, d := range []struct {
+		name   string
+		src    string
+		expect string
+	}{
+		{name: "json", src: `{"a":[1,true,"x y"]}`, expect: `{"a":[1,true,"x y"]}`},
+		{name: "sen", src: `{a:[1 true "x y"]}`, expect: `{a:[1 true "x y"]}`},
+	} {
+		f := ojg.LookupFormat(d.name)
+		tt.Equal(t, d.name, f.Name())
+		v, err := f.Parse([]byte(d.src))
+		tt.Nil(t, err)
+		tt.Equal(t, map[string]any{"a": []any{1, true, "x y"}}, v)
+
+		var b []byte
+		b, err = f.Bytes(v, &ojg.Options{Sort: true})
+		tt.Nil(t, err)
+		tt.Equal(t, d.expect, string(b))
+
+		var sb strings.Builder
+		err = f.Write(&sb, v, nil)
+		tt.Nil(t, err)
+		tt.Equal(t, d.expect, sb.String())
+
+		var all []any
+		err = f.ParseReader(strings.NewReader(d.src+" "+d.src), func(v any) { all = append(all, v) })
+		tt.Nil(t, err)
+		tt.Equal(t, 2, len(all))
+
+		h := testHandler{}
+		err = f.Tokenize([]byte(d.src), &h)
+		tt.Nil(t, err)
+		tt.Equal(t, `{ a: [ 1 true x y ] } `, string(h.buf))
+	}
+}
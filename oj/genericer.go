@@ -24,10 +24,11 @@ func appendGenericer(fi *finfo, buf []byte, rv reflect.Value, addr uintptr, safe
 }
 
 func appendGenericerNotEmpty(fi *finfo, buf []byte, rv reflect.Value, addr uintptr, safe bool) ([]byte, any, appendStatus) {
-	v := rv.FieldByIndex(fi.index).Interface()
-	if (*[2]uintptr)(unsafe.Pointer(&v))[1] == 0 { // real nil check
+	fv := rv.FieldByIndex(fi.index)
+	if isEmptyValue(fv) {
 		return buf, nil, aSkip
 	}
+	v := fv.Interface()
 	buf = append(buf, fi.jkey...)
 	if g, ok := v.(alt.Genericer); ok {
 		if n := g.Generic(); n != nil {
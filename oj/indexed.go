@@ -0,0 +1,300 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/jp"
+)
+
+// IndexedDoc is a structural index of a JSON document that is read through
+// an io.ReaderAt such as an *os.File. The document is scanned once when the
+// index is built to record the byte range of every value along with the
+// keys of every object. JSONPath expressions are then evaluated by walking
+// the index and reading and parsing only the byte ranges of the values
+// that are needed. That makes repeated queries against a large file on disk
+// much cheaper than parsing the whole document for each query. The index
+// holds the keys and offsets but no values so it is much smaller than the
+// parsed document.
+//
+// The Root, Child, Nth, and Wildcard fragments of an expression are
+// evaluated against the index. When any other fragment is reached the
+// value at that point is parsed and the rest of the expression is evaluated
+// against the parsed value.
+type IndexedDoc struct {
+	r    io.ReaderAt
+	root indexEntry
+}
+
+type indexEntry struct {
+	start int64
+	end   int64
+	node  *indexNode // nil for anything other than an array or object
+}
+
+type indexNode struct {
+	keys []string // nil for arrays
+	kids []indexEntry
+}
+
+type indexer struct {
+	rd  *bufio.Reader
+	off int64
+}
+
+// NewIndexedDoc scans the size bytes of the reader and returns an index of
+// the JSON document. The scan checks the structure of the document but
+// values are only fully validated when they are read by a query.
+func NewIndexedDoc(r io.ReaderAt, size int64) (doc *IndexedDoc, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			doc = nil
+			err = ojg.NewError(rec)
+		}
+	}()
+	ix := indexer{rd: bufio.NewReaderSize(io.NewSectionReader(r, 0, size), 65536)}
+	b, ok := ix.skipSpace()
+	if !ok {
+		panic(ix.errorf("no JSON value"))
+	}
+	doc = &IndexedDoc{r: r, root: ix.value(b)}
+	if _, ok = ix.skipSpace(); ok {
+		panic(ix.errorf("extra characters after the JSON value"))
+	}
+	return
+}
+
+// Get the values in the document that match the expression.
+func (doc *IndexedDoc) Get(x jp.Expr) (results []any, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			results = nil
+			err = ojg.NewError(rec)
+		}
+	}()
+	return doc.get(doc.root, x, nil), nil
+}
+
+// First returns the first value in the document that matches the
+// expression or nil if there is no match.
+func (doc *IndexedDoc) First(x jp.Expr) (any, error) {
+	results, err := doc.Get(x)
+	if err != nil || len(results) == 0 {
+		return nil, err
+	}
+	return results[0], nil
+}
+
+func (doc *IndexedDoc) get(e indexEntry, rest jp.Expr, results []any) []any {
+	for 0 < len(rest) {
+		switch rest[0].(type) {
+		case jp.Root, jp.At, jp.Bracket:
+			rest = rest[1:]
+			continue
+		}
+		break
+	}
+	if len(rest) == 0 {
+		return append(results, doc.load(e))
+	}
+	switch f := rest[0].(type) {
+	case jp.Child:
+		if e.node != nil && e.node.keys != nil {
+			// As with parsing, the last of any duplicate keys wins.
+			for i := len(e.node.keys) - 1; 0 <= i; i-- {
+				if e.node.keys[i] == string(f) {
+					return doc.get(e.node.kids[i], rest[1:], results)
+				}
+			}
+		}
+	case jp.Nth:
+		if e.node != nil && e.node.keys == nil {
+			i := int(f)
+			if i < 0 {
+				i += len(e.node.kids)
+			}
+			if 0 <= i && i < len(e.node.kids) {
+				results = doc.get(e.node.kids[i], rest[1:], results)
+			}
+		}
+	case jp.Wildcard:
+		if e.node != nil {
+			for _, kid := range e.node.kids {
+				results = doc.get(kid, rest[1:], results)
+			}
+		}
+	default:
+		x := append(jp.Expr{jp.At('@')}, rest...)
+		results = append(results, x.Get(doc.load(e))...)
+	}
+	return results
+}
+
+// load reads and parses the value of an entry.
+func (doc *IndexedDoc) load(e indexEntry) any {
+	buf := make([]byte, e.end-e.start)
+	if n, err := doc.r.ReadAt(buf, e.start); n < len(buf) {
+		panic(err)
+	}
+	var p Parser
+	v, err := p.Parse(buf)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func (ix *indexer) read() (b byte, ok bool) {
+	var err error
+	if b, err = ix.rd.ReadByte(); err != nil {
+		if err != io.EOF {
+			panic(err)
+		}
+		return 0, false
+	}
+	ix.off++
+	return b, true
+}
+
+func (ix *indexer) unread() {
+	_ = ix.rd.UnreadByte()
+	ix.off--
+}
+
+func (ix *indexer) skipSpace() (b byte, ok bool) {
+	for {
+		if b, ok = ix.read(); !ok {
+			return
+		}
+		switch b {
+		case ' ', '\t', '\n', '\r':
+		default:
+			return
+		}
+	}
+}
+
+func (ix *indexer) mustRead(what string) byte {
+	b, ok := ix.skipSpace()
+	if !ok {
+		panic(ix.errorf("incomplete JSON, expected %s", what))
+	}
+	return b
+}
+
+func (ix *indexer) errorf(format string, args ...any) error {
+	return ojg.Errorf(ojg.ErrSyntax, "%s at offset %d", fmt.Sprintf(format, args...), ix.off)
+}
+
+// value scans the value that starts with b, the byte just read.
+func (ix *indexer) value(b byte) (e indexEntry) {
+	e.start = ix.off - 1
+	switch b {
+	case '{':
+		e.node = &indexNode{keys: []string{}}
+		if b = ix.mustRead("a key"); b != '}' {
+			for {
+				if b != '"' {
+					panic(ix.errorf("expected a key"))
+				}
+				e.node.keys = append(e.node.keys, ix.key())
+				if ix.mustRead("a colon") != ':' {
+					panic(ix.errorf("expected a colon"))
+				}
+				e.node.kids = append(e.node.kids, ix.value(ix.mustRead("a value")))
+				if b = ix.mustRead("a comma or close"); b == '}' {
+					break
+				}
+				if b != ',' {
+					panic(ix.errorf("expected a comma or close"))
+				}
+				b = ix.mustRead("a key")
+			}
+		}
+	case '[':
+		e.node = &indexNode{}
+		if b = ix.mustRead("a value"); b != ']' {
+			for {
+				e.node.kids = append(e.node.kids, ix.value(b))
+				if b = ix.mustRead("a comma or close"); b == ']' {
+					break
+				}
+				if b != ',' {
+					panic(ix.errorf("expected a comma or close"))
+				}
+				b = ix.mustRead("a value")
+			}
+		}
+	case '"':
+		ix.str()
+	case '}', ']', ',', ':':
+		panic(ix.errorf("unexpected character '%c'", b))
+	default:
+	scalar:
+		for {
+			b, ok := ix.read()
+			if !ok {
+				break
+			}
+			switch b {
+			case ' ', '\t', '\n', '\r', ',', ']', '}':
+				ix.unread()
+				break scalar
+			}
+		}
+	}
+	e.end = ix.off
+	return
+}
+
+// str reads the rest of a string value.
+func (ix *indexer) str() {
+	for {
+		b, ok := ix.read()
+		if !ok {
+			panic(ix.errorf("incomplete JSON, unterminated string"))
+		}
+		switch b {
+		case '"':
+			return
+		case '\\':
+			if _, ok = ix.read(); !ok {
+				panic(ix.errorf("incomplete JSON, unterminated string"))
+			}
+		}
+	}
+}
+
+func (ix *indexer) key() string {
+	var raw []byte
+	raw = append(raw, '"')
+	for {
+		b, ok := ix.read()
+		if !ok {
+			panic(ix.errorf("incomplete JSON, unterminated string"))
+		}
+		raw = append(raw, b)
+		switch b {
+		case '"':
+			if bytes.IndexByte(raw, '\\') < 0 {
+				return string(raw[1 : len(raw)-1])
+			}
+			var p Parser
+			v, err := p.Parse(raw)
+			if err != nil {
+				panic(err)
+			}
+			return v.(string)
+		case '\\':
+			if b, ok = ix.read(); !ok {
+				panic(ix.errorf("incomplete JSON, unterminated string"))
+			}
+			raw = append(raw, b)
+		}
+	}
+}
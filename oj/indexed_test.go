@@ -0,0 +1,90 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/jp"
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/tt"
+)
+
+func TestIndexedDoc(t *testing.T) {
+	src := []byte(`{
+  "store": {
+    "book": [
+      {"title": "Sayings", "price": 8.95, "tags": ["a", "b"]},
+      {"title": "Sword", "price": 12.99, "tags": []},
+      {"title": "Moby \"Dick\"", "price": 8.99, "isbn": null}
+    ],
+    "bicycle": {"color": "red", "price": 19.95},
+    "odd\tkey": true
+  },
+  "count": 3,
+  "dup": 1,
+  "dup": 2
+}`)
+	doc, err := oj.NewIndexedDoc(bytes.NewReader(src), int64(len(src)))
+	tt.Nil(t, err)
+	data := oj.MustParse(src)
+	for _, path := range []string{
+		"$",
+		"$.count",
+		"$.store.book[1].title",
+		"$.store.book[-1]",
+		"$.store.book[*].price",
+		"$.store.book[0].tags[*]",
+		"$.store.bicycle",
+		`$.store["odd\tkey"]`,
+		"$.store.book[?(@.price < 9)].title",
+		"$.store.book..price",
+		"$.store.book[1:3].title",
+		"$.missing",
+		"$.store.book[7]",
+		"$.count.x",
+		"$.dup",
+	} {
+		x := jp.MustParseString(path)
+		result, err := doc.Get(x)
+		tt.Nil(t, err, path)
+		tt.Equal(t, x.Get(data), result, path)
+	}
+	first, err := doc.First(jp.MustParseString("$.store.book[*].title"))
+	tt.Nil(t, err)
+	tt.Equal(t, "Sayings", first)
+
+	first, err = doc.First(jp.MustParseString("$.nothing"))
+	tt.Nil(t, err)
+	tt.Nil(t, first)
+}
+
+func TestIndexedDocErrors(t *testing.T) {
+	for _, src := range []string{
+		"",
+		"  ",
+		`{"a":1`,
+		`{"a" 1}`,
+		`{a:1}`,
+		`[1 2]`,
+		`["abc`,
+		`[1]]`,
+		`[,]`,
+	} {
+		_, err := oj.NewIndexedDoc(bytes.NewReader([]byte(src)), int64(len(src)))
+		tt.NotNil(t, err, src)
+		tt.Equal(t, true, errors.Is(err, ojg.ErrSyntax), src)
+	}
+	// Scalars are only validated when read.
+	src := []byte(`{"a":[1,trux]}`)
+	doc, err := oj.NewIndexedDoc(bytes.NewReader(src), int64(len(src)))
+	tt.Nil(t, err)
+	result, err := doc.Get(jp.C("a").N(0))
+	tt.Nil(t, err)
+	tt.Equal(t, []any{int64(1)}, result)
+	_, err = doc.Get(jp.C("a").N(1))
+	tt.NotNil(t, err)
+}
@@ -0,0 +1,312 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj
+
+import (
+	"math"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/ohler55/ojg"
+)
+
+// The JSON5 maps use json5Char for bytes that are valid only in JSON5
+// mode. JSON5 specific bytes in the standard maps are charErr and are
+// handled when charErr is encountered and the parser JSON5 flag is set.
+const (
+	json5Char = 'J'
+
+	//   0123456789abcdef0123456789abcdef
+	identMap = "" +
+		"................................" + // 0x00
+		"....J...........JJJJJJJJJJ......" + // 0x20
+		".JJJJJJJJJJJJJJJJJJJJJJJJJJ....J" + // 0x40
+		".JJJJJJJJJJJJJJJJJJJJJJJJJJ....." + // 0x60
+		"JJJJJJJJJJJJJJJJJJJJJJJJJJJJJJJJ" + // 0x80
+		"JJJJJJJJJJJJJJJJJJJJJJJJJJJJJJJJ" + // 0xa0
+		"JJJJJJJJJJJJJJJJJJJJJJJJJJJJJJJJ" + // 0xc0
+		"JJJJJJJJJJJJJJJJJJJJJJJJJJJJJJJJ" //   0xe0
+	//   0123456789abcdef0123456789abcdef
+	sqStringMap = "" +
+		"................................" + // 0x00
+		"RRRRRRRzRRRRRRRRRRRRRRRRRRRRRRRR" + // 0x20
+		"RRRRRRRRRRRRRRRRRRRRRRRRRRRRJRRR" + // 0x40
+		"RRRRRRRRRRRRRRRRRRRRRRRRRRRRRRRR" + // 0x60
+		"RRRRRRRRRRRRRRRRRRRRRRRRRRRRRRRR" + // 0x80
+		"RRRRRRRRRRRRRRRRRRRRRRRRRRRRRRRR" + // 0xa0
+		"RRRRRRRRRRRRRRRRRRRRRRRRRRRRRRRR" + // 0xc0
+		"RRRRRRRRRRRRRRRRRRRRRRRRRRRRRRRR" //   0xe0
+	//   0123456789abcdef0123456789abcdef
+	strEscMap = "" +
+		"..........J..J.................." + // 0x00
+		"JJJJJJJJJJJJJJJJJ.........JJJJJJ" + // 0x20
+		"JJJJJJJJJJJJJJJJJJJJJJJJJJJJJJJJ" + // 0x40
+		"JJJJJJJJJJJJJJJJJJJJJJJJJJJJJJJJ" + // 0x60
+		"JJJJJJJJJJJJJJJJJJJJJJJJJJJJJJJJ" + // 0x80
+		"JJJJJJJJJJJJJJJJJJJJJJJJJJJJJJJJ" + // 0xa0
+		"JJJJJJJJJJJJJJJJJJJJJJJJJJJJJJJJ" + // 0xc0
+		"JJJJJJJJJJJJJJJJJJJJJJJJJJJJJJJJ" //   0xe0
+	//   0123456789abcdef0123456789abcdef
+	hexEscMap = "" +
+		"................................" + // 0x00
+		"................JJJJJJJJJJ......" + // 0x20
+		".JJJJJJ........................." + // 0x40
+		".JJJJJJ........................." + // 0x60
+		"................................" + // 0x80
+		"................................" + // 0xa0
+		"................................" + // 0xc0
+		"................................" //   0xe0
+	//   0123456789abcdef0123456789abcdef
+	crMap = "" +
+		"..........J....................." + // 0x00
+		"................................" + // 0x20
+		"................................" + // 0x40
+		"................................" + // 0x60
+		"................................" + // 0x80
+		"................................" + // 0xa0
+		"................................" + // 0xc0
+		"................................" //   0xe0
+	//   0123456789abcdef0123456789abcdef
+	leadDotMap = "" +
+		"................................" + // 0x00
+		"................JJJJJJJJJJ......" + // 0x20
+		"................................" + // 0x40
+		"................................" + // 0x60
+		"................................" + // 0x80
+		"................................" + // 0xa0
+		"................................" + // 0xc0
+		"................................" //   0xe0
+	//   0123456789abcdef0123456789abcdef
+	hexNumMap = "" +
+		".........JJJJJ.................." + // 0x00
+		"J...........J..JJJJJJJJJJJ......" + // 0x20
+		".JJJJJJ......................J.." + // 0x40
+		".JJJJJJ......................J.." + // 0x60
+		"................................" + // 0x80
+		"................................" + // 0xa0
+		"................................" + // 0xc0
+		"................................" //   0xe0
+)
+
+// json5 handles bytes that are only valid when the JSON5 flag is set. The
+// updated offset is returned along with true if the byte was handled.
+func (p *Parser) json5(off int, b byte) (int, bool, error) {
+	if b == '\v' || b == '\f' {
+		switch p.mode[' '] {
+		case skipChar:
+			return off, true, nil
+		case numSpc:
+			p.add(p.num.AsNum())
+			p.mode = afterMap
+			return off, true, nil
+		}
+	}
+	switch p.mode {
+	case valueMap, commaMap:
+		switch b {
+		case '\'':
+			p.tmp = p.tmp[:0]
+			p.mode = sqStringMap
+			p.nextMode = afterMap
+		case 'I', 'N':
+			p.num.Reset()
+			p.tmp = append(p.tmp[:0], b)
+			p.mode = identMap
+			p.nextMode = afterMap
+		case '+':
+			p.num.Reset()
+			p.mode = negMap
+		case '.':
+			p.num.Reset()
+			p.mode = leadDotMap
+		default:
+			return off, false, nil
+		}
+	case key1Map, keyMap:
+		switch {
+		case b == '\'':
+			p.tmp = p.tmp[:0]
+			p.mode = sqStringMap
+			p.nextMode = colonMap
+		case identMap[b] == json5Char && (b < '0' || '9' < b):
+			p.tmp = append(p.tmp[:0], b)
+			p.mode = identMap
+			p.nextMode = colonMap
+		default:
+			return off, false, nil
+		}
+	case negMap:
+		switch b {
+		case 'I', 'N':
+			p.tmp = append(p.tmp[:0], b)
+			p.mode = identMap
+			p.nextMode = afterMap
+		case '.':
+			p.mode = leadDotMap
+		default:
+			return off, false, nil
+		}
+	case zeroMap:
+		if b != 'x' && b != 'X' {
+			return off, false, nil
+		}
+		p.ri = 0
+		p.mode = hexNumMap
+	case identMap:
+		if identMap[b] == json5Char {
+			p.tmp = append(p.tmp, b)
+			break
+		}
+		if err := p.identDone(off); err != nil {
+			return off, false, err
+		}
+		off-- // try the byte again
+	case leadDotMap:
+		if p.mode[b] != json5Char {
+			return off, false, nil
+		}
+		p.num.AddFrac(b)
+		p.mode = fracMap
+	case hexNumMap:
+		if v, ok := hexValue(b); ok {
+			if math.MaxInt64>>4 < p.num.I {
+				return off, false, p.newError(off, ojg.ErrInvalidNumber, "hex number too large")
+			}
+			p.num.I = p.num.I<<4 | uint64(v)
+			p.ri++
+			break
+		}
+		if p.ri == 0 {
+			return off, false, p.newError(off, ojg.ErrInvalidNumber, "invalid number")
+		}
+		p.add(p.num.AsNum())
+		p.mode = afterMap
+		off-- // try the byte again
+	case sqStringMap:
+		if p.mode[b] != json5Char {
+			return off, false, nil
+		}
+		p.smode = sqStringMap
+		p.mode = strEscMap
+	case escMap, strEscMap:
+		if strEscMap[b] != json5Char {
+			return off, false, nil
+		}
+		if p.mode == escMap {
+			p.smode = stringMap
+		}
+		p.mode = p.smode
+		switch b {
+		case '\n': // line continuation
+			p.line++
+			p.noff = off
+		case '\r':
+			p.mode = crMap
+		case 'x':
+			p.rn = 0
+			p.ri = 2
+			p.mode = hexEscMap
+		case 'u':
+			p.rn = 0
+			p.ri = 4
+			p.mode = hexEscMap
+		case '0':
+			p.tmp = append(p.tmp, 0)
+		case 'v':
+			p.tmp = append(p.tmp, '\v')
+		case 'b', 'f', 'n', 'r', 't':
+			p.tmp = append(p.tmp, escByteMap[b])
+		default:
+			p.tmp = append(p.tmp, b)
+		}
+	case hexEscMap:
+		if p.mode[b] != json5Char {
+			return off, false, nil
+		}
+		v, _ := hexValue(b)
+		p.rn = p.rn<<4 | rune(v)
+		p.ri--
+		if p.ri == 0 {
+			r := p.rn
+			switch {
+			case 0xD800 <= r && r < 0xDC00:
+				// As with double quoted strings a high surrogate is written
+				// as U+FFFD unless followed by a low surrogate escape.
+				p.hi = r
+				p.hiEnd = len(p.tmp) + 3
+			case 0xDC00 <= r && r < 0xE000 && p.hi != 0 && p.hiEnd == len(p.tmp):
+				p.tmp = p.tmp[:len(p.tmp)-3]
+				r = utf16.DecodeRune(p.hi, r)
+				p.hi = 0
+			}
+			p.tmp = utf8.AppendRune(p.tmp, r)
+			p.mode = p.smode
+		}
+	case crMap:
+		if b == '\n' {
+			p.line++
+			p.noff = off
+		} else {
+			off-- // not a \r\n pair so try the byte again
+		}
+		p.mode = p.smode
+	default:
+		return off, false, nil
+	}
+	return off, true, nil
+}
+
+// json5Last finishes any JSON5 value that does not need a terminating
+// byte. True is returned if a value was added.
+func (p *Parser) json5Last(off int) (bool, error) {
+	switch p.mode {
+	case identMap:
+		if err := p.identDone(off); err != nil {
+			return false, err
+		}
+		return p.mode == afterMap, nil
+	case hexNumMap:
+		if p.ri == 0 {
+			return false, p.newError(off, ojg.ErrInvalidNumber, "invalid number")
+		}
+		p.add(p.num.AsNum())
+		p.mode = afterMap
+		return true, nil
+	}
+	return false, nil
+}
+
+func (p *Parser) identDone(off int) error {
+	if p.nextMode == colonMap {
+		p.mode = colonMap
+		return p.pushKey(off, string(p.tmp))
+	}
+	var f float64
+	switch string(p.tmp) {
+	case "Infinity":
+		f = math.Inf(1)
+	case "NaN":
+		f = math.NaN()
+	default:
+		return p.newError(off, ojg.ErrInvalidLiteral, "unexpected identifier '%s'", p.tmp)
+	}
+	if p.num.Neg {
+		f = -f
+	}
+	p.add(f)
+	p.mode = afterMap
+
+	return nil
+}
+
+func hexValue(b byte) (byte, bool) {
+	switch {
+	case '0' <= b && b <= '9':
+		return b - '0', true
+	case 'a' <= b && b <= 'f':
+		return b - 'a' + 10, true
+	case 'A' <= b && b <= 'F':
+		return b - 'A' + 10, true
+	}
+	return 0, false
+}
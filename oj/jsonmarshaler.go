@@ -4,7 +4,9 @@ package oj
 
 import (
 	"encoding/json"
+	"fmt"
 	"reflect"
+	"sync"
 	"unsafe"
 )
 
@@ -24,21 +26,86 @@ func appendJSONMarshalerAddr(fi *finfo, buf []byte, rv reflect.Value, addr uintp
 }
 
 func appendJSONMarshalerNotEmpty(fi *finfo, buf []byte, rv reflect.Value, addr uintptr, safe bool) ([]byte, any, appendStatus) {
-	v := rv.FieldByIndex(fi.index).Interface()
-	if (*[2]uintptr)(unsafe.Pointer(&v))[1] == 0 { // real nil check
+	fv := rv.FieldByIndex(fi.index)
+	if isEmptyValue(fv) {
 		return buf, nil, aSkip
 	}
+	v := fv.Interface()
 	buf = append(buf, fi.jkey...)
 	return appendJSONMarshalerVal(buf, v)
 }
 
 func appendJSONMarshalerVal(buf []byte, v any) ([]byte, any, appendStatus) {
-	m := v.(json.Marshaler)
+	return appendMarshaled(buf, v.(json.Marshaler)), nil, aWrote
+}
+
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// appendMarshaled appends the output of MarshalJSON after validating it so
+// a misbehaving marshaler can not produce invalid JSON.
+func appendMarshaled(buf []byte, m json.Marshaler) []byte {
 	j, err := m.MarshalJSON()
 	if err != nil {
 		panic(err)
 	}
-	buf = append(buf, j...)
+	if err = Validate(j); err != nil {
+		panic(fmt.Errorf("%T MarshalJSON returned invalid JSON: %w", m, err))
+	}
+	return append(buf, j...)
+}
+
+const (
+	marshalNone = byte(iota)
+	marshalValue
+	marshalAddr
+)
+
+// marshalKinds caches the marshalKind of each type.
+var marshalKinds sync.Map
+
+// marshalKind returns marshalValue if values of the type implement
+// json.Marshaler, marshalAddr if a pointer to a value of the type does, and
+// otherwise marshalNone. Only container kinds and pointers are considered,
+// other kinds are left to appendJSON. Types with a registered encoder are
+// always marshalNone. The result is cached by type so the check is made
+// once per type and not for each value written.
+func marshalKind(rt reflect.Type) byte {
+	if 0 < len(typeEncoders) && hasEncoder(rt) {
+		// Registered encoders take precedence.
+		return marshalNone
+	}
+	if k, has := marshalKinds.Load(rt); has {
+		return k.(byte)
+	}
+	k := marshalNone
+	switch rt.Kind() {
+	case reflect.Struct, reflect.Slice, reflect.Array, reflect.Map, reflect.Ptr:
+		if rt.Implements(jsonMarshalerType) {
+			k = marshalValue
+		} else if reflect.PointerTo(rt).Implements(jsonMarshalerType) {
+			k = marshalAddr
+		}
+	}
+	marshalKinds.Store(rt, k)
+	return k
+}
 
-	return buf, nil, aWrote
+// reflectMarshaler returns the json.Marshaler for a reflect value with a
+// type of the marshal kind provided. A pointer receiver is only used if the
+// value is addressable.
+func reflectMarshaler(rv reflect.Value, kind byte) json.Marshaler {
+	switch kind {
+	case marshalValue:
+		if !rv.CanInterface() || (rv.Kind() == reflect.Ptr && rv.IsNil()) {
+			return nil
+		}
+		m, _ := rv.Interface().(json.Marshaler)
+		return m
+	case marshalAddr:
+		if rv.CanInterface() && rv.CanAddr() {
+			m, _ := rv.Addr().Interface().(json.Marshaler)
+			return m
+		}
+	}
+	return nil
 }
@@ -37,6 +37,13 @@ const (
 	numZero     = 'O'
 	strOk       = 'R'
 	escU        = 'U'
+	lineComment = 'S'
+	starComment = 'C'
+	commentEnd  = 'L'
+	starEnd     = 'T'
+	starDone    = 'P'
+	starNewline = 'D'
+	starChar    = 'H'
 	charErr     = '.'
 
 	//   0123456789abcdef0123456789abcdef
@@ -259,6 +266,46 @@ const (
 		"................................" + // 0xa0
 		"................................" + // 0xc0
 		"................................s" //   0xe0
+	//   0123456789abcdef0123456789abcdef
+	commentStartMap = "" +
+		"................................" + // 0x00
+		"..........C....S................" + // 0x20
+		"................................" + // 0x40
+		"................................" + // 0x60
+		"................................" + // 0x80
+		"................................" + // 0xa0
+		"................................" + // 0xc0
+		"................................" //   0xe0
+	//   0123456789abcdef0123456789abcdef
+	commentMap = "" +
+		"aaaaaaaaaaLaaaaaaaaaaaaaaaaaaaaa" + // 0x00
+		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa" + // 0x20
+		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa" + // 0x40
+		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa" + // 0x60
+		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa" + // 0x80
+		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa" + // 0xa0
+		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa" + // 0xc0
+		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa" //   0xe0
+	//   0123456789abcdef0123456789abcdef
+	starCommentMap = "" +
+		"aaaaaaaaaabaaaaaaaaaaaaaaaaaaaaa" + // 0x00
+		"aaaaaaaaaaTaaaaaaaaaaaaaaaaaaaaa" + // 0x20
+		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa" + // 0x40
+		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa" + // 0x60
+		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa" + // 0x80
+		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa" + // 0xa0
+		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa" + // 0xc0
+		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa" //   0xe0
+	//   0123456789abcdef0123456789abcdef
+	starEndMap = "" +
+		"HHHHHHHHHHDHHHHHHHHHHHHHHHHHHHHH" + // 0x00
+		"HHHHHHHHHHTHHHHPHHHHHHHHHHHHHHHH" + // 0x20
+		"HHHHHHHHHHHHHHHHHHHHHHHHHHHHHHHH" + // 0x40
+		"HHHHHHHHHHHHHHHHHHHHHHHHHHHHHHHH" + // 0x60
+		"HHHHHHHHHHHHHHHHHHHHHHHHHHHHHHHH" + // 0x80
+		"HHHHHHHHHHHHHHHHHHHHHHHHHHHHHHHH" + // 0xa0
+		"HHHHHHHHHHHHHHHHHHHHHHHHHHHHHHHH" + // 0xc0
+		"HHHHHHHHHHHHHHHHHHHHHHHHHHHHHHHH" //   0xe0
 )
 
 /*
@@ -309,6 +356,14 @@ func whichMode(mode string) string {
 		return "u"
 	case spaceMap:
 		return "space"
+	case commentStartMap:
+		return "commentStart"
+	case commentMap:
+		return "comment"
+	case starCommentMap:
+		return "starComment"
+	case starEndMap:
+		return "starEnd"
 	default:
 		return "unknown"
 	}
@@ -39,3 +39,13 @@ func TestMatchLoad(t *testing.T) {
 	tt.Nil(t, err)
 	tt.Equal(t, "$.a: 1", string(buf))
 }
+
+func TestMatchLoadFilter(t *testing.T) {
+	var buf []byte
+	err := oj.MatchLoad(strings.NewReader(`{"items":[{"id":1,"n":5},{"id":2,"n":12},{"id":3,"n":20}]}`),
+		func(path jp.Expr, data any) {
+			buf = fmt.Appendf(buf, "%s: %v\n", path, pretty.SEN(data))
+		}, jp.MustParseString("$.items[?(@.n > 10)].id"))
+	tt.Nil(t, err)
+	tt.Equal(t, "$.items[1].id: 2\n$.items[2].id: 3\n", string(buf))
+}
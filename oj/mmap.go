@@ -0,0 +1,26 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package oj
+
+import (
+	"math"
+	"os"
+	"syscall"
+)
+
+// mapFile returns the contents of the file mapped read only into memory
+// along with a function to unmap it. Nil is returned if the file can not
+// be mapped.
+func mapFile(f *os.File) ([]byte, func()) {
+	fi, err := f.Stat()
+	if err != nil || !fi.Mode().IsRegular() || fi.Size() == 0 || math.MaxInt < fi.Size() {
+		return nil, nil
+	}
+	buf, err := syscall.Mmap(int(f.Fd()), 0, int(fi.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil
+	}
+	return buf, func() { _ = syscall.Munmap(buf) }
+}
@@ -0,0 +1,12 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package oj
+
+import "os"
+
+// mapFile returns nil on platforms without memory mapped files.
+func mapFile(f *os.File) ([]byte, func()) {
+	return nil, nil
+}
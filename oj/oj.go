@@ -3,7 +3,9 @@
 package oj
 
 import (
+	"encoding/json"
 	"io"
+	"reflect"
 	"sync"
 
 	"github.com/ohler55/ojg"
@@ -26,6 +28,10 @@ var (
 	// HTMLOptions are the options that can be used to encode as HTML JSON.
 	HTMLOptions = ojg.HTMLOptions
 
+	// HTMLClassOptions are the options that can be used to encode as HTML
+	// JSON with CSS classes for the colors.
+	HTMLClassOptions = ojg.HTMLClassOptions
+
 	goOptions  = ojg.GoOptions
 	writerPool = sync.Pool{
 		New: func() any {
@@ -44,12 +50,12 @@ var (
 	}
 )
 
-// Parse JSON into a simple type. Arguments are optional and can be a bool,
-// func(any) bool for callbacks, or a chan any for chan based
+// Parse JSON into a simple type. Arguments are optional and can be an
+// AllowComments, func(any) bool for callbacks, or a chan any for chan based
 // result delivery.
 //
-// A bool indicates the NoComment parser attribute should be set to the bool
-// value.
+// An AllowComments argument sets the Comments parser attribute for the
+// call. If true then // and /* */ comments are allowed.
 //
 // A func argument is the callback for the parser if processing multiple
 // JSONs. If no callback function is provided the processing is limited to
@@ -62,12 +68,12 @@ func Parse(b []byte, args ...any) (n any, err error) {
 	return p.Parse(b, args...)
 }
 
-// MustParse JSON into a simple type. Arguments are optional and can be a bool,
-// func(any) bool for callbacks, or a chan any for chan based
+// MustParse JSON into a simple type. Arguments are optional and can be an
+// AllowComments, func(any) bool for callbacks, or a chan any for chan based
 // result delivery. Panics on error
 //
-// A bool indicates the NoComment parser attribute should be set to the bool
-// value.
+// An AllowComments argument sets the Comments parser attribute for the
+// call. If true then // and /* */ comments are allowed.
 //
 // A func argument is the callback for the parser if processing multiple
 // JSONs. If no callback function is provided the processing is limited to
@@ -112,6 +118,15 @@ func Load(r io.Reader, args ...any) (any, error) {
 	return p.ParseReader(r, args...)
 }
 
+// ParseReaderMany reads a stream of concatenated or newline delimited JSON
+// documents and calls the callback with each top level value. If the
+// callback returns an error parsing stops and that error is returned.
+func ParseReaderMany(r io.Reader, cb func(any) error) error {
+	p := parserPool.Get().(*Parser)
+	defer parserPool.Put(p)
+	return p.ParseReaderMany(r, cb)
+}
+
 // MustLoad a JSON from a io.Reader into a simple type. Panics on error.
 func MustLoad(r io.Reader, args ...any) (n any) {
 	p := parserPool.Get().(*Parser)
@@ -141,10 +156,26 @@ func ValidateReader(r io.Reader) error {
 	return v.ValidateReader(r)
 }
 
+// ValidateReaderLimits validates a JSON stream using bounded memory and
+// returns an error if the JSON is not valid or if it exceeds any of the
+// limits. A limit of zero or less is not checked. The maxDepth limits the
+// nesting of arrays and objects, maxStringLength limits the encoded length
+// of strings and keys, and maxSize limits the total number of bytes read.
+func ValidateReaderLimits(r io.Reader, maxDepth, maxStringLength, maxSize int) error {
+	v := Validator{MaxDepth: maxDepth, MaxStringLength: maxStringLength, MaxSize: maxSize}
+	return v.ValidateReader(r)
+}
+
 // Unmarshal parses the provided JSON and stores the result in the value
-// pointed to by vp.
+// pointed to by vp. Values that implement json.Unmarshaler, including vp
+// itself, are passed the raw JSON of their element. Elements of an array
+// are the exception as they are converted back to JSON before being passed
+// to UnmarshalJSON.
 func Unmarshal(data []byte, vp any, recomposer ...*alt.Recomposer) (err error) {
-	p := Parser{}
+	if um, ok := vp.(json.Unmarshaler); ok {
+		return um.UnmarshalJSON(data)
+	}
+	p := Parser{PathHook: unmarshalerHook(reflect.TypeOf(vp))}
 	p.num.ForceFloat = true
 	var v any
 	if v, err = p.Parse(data); err == nil {
@@ -219,6 +250,50 @@ func Write(w io.Writer, data any, args ...any) (err error) {
 	return wr.Write(w, data)
 }
 
+// MarshalMany returns newline delimited JSON (NDJSON) for the values
+// provided. The values can be a []any, a chan any, or any other slice, array,
+// or channel type. Each value is encoded on a single line. The args are the
+// same as for Marshal.
+func MarshalMany(values any, args ...any) (out []byte, err error) {
+	var wr *Writer
+	if 0 < len(args) {
+		wr = pickWriter(args[0], true)
+	}
+	if wr == nil {
+		wr, _ = marshalPool.Get().(*Writer)
+		defer marshalPool.Put(wr)
+	} else {
+		wr.strict = true
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			wr.buf = wr.buf[:0]
+			err = ojg.NewError(r)
+		}
+	}()
+	wr.w = nil
+	wr.appendMany(values)
+	out = make([]byte, len(wr.buf))
+	copy(out, wr.buf)
+
+	return
+}
+
+// WriteMany writes newline delimited JSON (NDJSON) for the values provided.
+// The values can be a []any, a chan any, or any other slice, array, or
+// channel type. The args are the same as for Write.
+func WriteMany(w io.Writer, values any, args ...any) (err error) {
+	var wr *Writer
+	if 0 < len(args) {
+		wr = pickWriter(args[0], false)
+	}
+	if wr == nil {
+		wr, _ = writerPool.Get().(*Writer)
+		defer writerPool.Put(wr)
+	}
+	return wr.WriteMany(w, values)
+}
+
 func pickWriter(arg any, strict bool) (wr *Writer) {
 	switch ta := arg.(type) {
 	case int:
@@ -253,7 +328,10 @@ func MatchString(data string, onData func(path jp.Expr, data any), targets ...jp
 }
 
 // MatchLoad parses a JSON document from an io.Reader and calls onData when a
-// data element that matches the target path is encountered.
+// data element that matches the target path is encountered. The document is
+// evaluated as it is read and only the matching elements, or for a target
+// with a filter the elements being filtered, are built so fields can be
+// extracted from documents too large to be held in memory.
 func MatchLoad(r io.Reader, onData func(path jp.Expr, data any), targets ...jp.Expr) error {
 	return TokenizeLoad(r, jp.NewMatchHandler(onData, targets...))
 }
@@ -3,6 +3,8 @@
 package oj_test
 
 import (
+	"math/rand"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -27,6 +29,31 @@ func TestValidateString(t *testing.T) {
 	tt.Nil(t, err)
 }
 
+func TestRoundTripGenDoc(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	spec := tt.DocSpec{MaxDepth: 4, MaxWidth: 6}
+	fails := func(doc any) bool {
+		v, err := oj.ParseString(oj.JSON(doc))
+		return err != nil || !reflect.DeepEqual(doc, v)
+	}
+	for i := 0; i < 500; i++ {
+		doc := tt.GenDoc(rng, &spec)
+		if fails(doc) {
+			t.Fatalf("round trip failed for %s", oj.JSON(tt.Minimize(doc, fails)))
+		}
+	}
+}
+
+func TestGenDocMinimize(t *testing.T) {
+	doc := map[string]any{"x": []any{int64(1), "ab\"c"}, "y": true}
+	min := tt.Minimize(doc, func(doc any) bool { return strings.Contains(oj.JSON(doc), `\"`) })
+	tt.Equal(t, `"`, min)
+
+	a := tt.GenDoc(rand.New(rand.NewSource(7)), nil)
+	b := tt.GenDoc(rand.New(rand.NewSource(7)), nil)
+	tt.Equal(t, true, reflect.DeepEqual(a, b))
+}
+
 /*
 func TestDev(t *testing.T) {
 	for _, d := range []data{
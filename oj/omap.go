@@ -0,0 +1,201 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj
+
+import "reflect"
+
+// OMap is an object that keeps its members in the order they were added. The
+// Parser returns OMap objects in place of map[string]any when the
+// OrderedObjects flag is set so that a document can be written again with
+// the original key order. The Writer always writes the members of an OMap in
+// order even if the Sort option is set. Other packages such as jp and alt
+// do not look inside an OMap but the Simplify method can be used to convert
+// to a map[string]any. The zero value is an empty OMap ready to use.
+type OMap struct {
+	keys []string
+	vals map[string]any
+}
+
+// Set the value of a member. A new key is added after the existing keys
+// while the value of an existing key is replaced without changing its
+// position.
+func (om *OMap) Set(key string, value any) {
+	if om.vals == nil {
+		om.vals = map[string]any{}
+	}
+	if _, has := om.vals[key]; !has {
+		om.keys = append(om.keys, key)
+	}
+	om.vals[key] = value
+}
+
+// Get the value of a member along with true if the member exists.
+func (om *OMap) Get(key string) (value any, has bool) {
+	value, has = om.vals[key]
+	return
+}
+
+// Delete a member.
+func (om *OMap) Delete(key string) {
+	if _, has := om.vals[key]; !has {
+		return
+	}
+	delete(om.vals, key)
+	for i, k := range om.keys {
+		if k == key {
+			om.keys = append(om.keys[:i], om.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// Keys returns the keys in order. The returned slice must not be modified.
+func (om *OMap) Keys() []string {
+	return om.keys
+}
+
+// Len returns the number of members.
+func (om *OMap) Len() int {
+	return len(om.keys)
+}
+
+// Simplify returns a map[string]any with the same members. Any OMap values
+// in arrays or objects below are also converted. Arrays and objects are
+// copied so the OMap is not modified.
+func (om *OMap) Simplify() any {
+	return simplifyOMaps(om)
+}
+
+// MarshalJSON returns the compact JSON for the OMap with the members in
+// order.
+func (om *OMap) MarshalJSON() ([]byte, error) {
+	return Marshal(om)
+}
+
+func simplifyOMaps(v any) any {
+	switch tv := v.(type) {
+	case *OMap:
+		obj := make(map[string]any, len(tv.keys))
+		for _, k := range tv.keys {
+			obj[k] = simplifyOMaps(tv.vals[k])
+		}
+		return obj
+	case []any:
+		a := make([]any, len(tv))
+		for i, m := range tv {
+			a[i] = simplifyOMaps(m)
+		}
+		return a
+	case map[string]any:
+		obj := make(map[string]any, len(tv))
+		for k, m := range tv {
+			obj[k] = simplifyOMaps(m)
+		}
+		return obj
+	}
+	return v
+}
+
+func (wr *Writer) appendOMap(n *OMap, depth int) {
+	d2 := depth + 1
+	var is string
+	var cs string
+	if wr.Tab {
+		is = tabs[1:min(depth+1, len(tabs))]
+		cs = tabs[0:min(d2+1, len(tabs))]
+	} else if 0 < wr.Indent {
+		is = spaces[1:min(depth*wr.Indent+1, len(spaces))]
+		cs = spaces[0:min(d2*wr.Indent+1, len(spaces))]
+	}
+	if !wr.enterPlain(reflect.ValueOf(n), depth) {
+		return
+	}
+	empty := true
+	wr.buf = append(wr.buf, '{')
+	for _, k := range n.keys {
+		m := n.vals[k]
+		if wr.redactKey(k) {
+			m = wr.redactMask
+		}
+		if wr.omitMember(m) {
+			continue
+		}
+		var parent pathState
+		if wr.filtering {
+			var ok bool
+			if parent, ok = wr.pathEnter(k, -1, 0, n.vals[k]); !ok {
+				continue
+			}
+		}
+		mark := len(wr.buf)
+		if wr.cycles {
+			wr.stepKey(k, reflect.ValueOf(m))
+		}
+		wr.buf = append(wr.buf, cs...)
+		wr.buf = wr.appendString(wr.buf, wr.keyFor(k), !wr.HTMLUnsafe)
+		wr.buf = append(wr.buf, ':')
+		if 0 < len(cs) {
+			wr.buf = append(wr.buf, ' ')
+		}
+		wr.appendJSON(m, d2)
+		wr.buf = append(wr.buf, ',')
+		if wr.filtering && !wr.pathLeave(parent, mark, true) {
+			continue
+		}
+		empty = false
+	}
+	if !empty {
+		if 0 < len(cs) {
+			wr.buf[len(wr.buf)-1] = '\n'
+			wr.buf = append(wr.buf, is...)
+		} else {
+			wr.buf = wr.buf[:len(wr.buf)-1]
+		}
+	}
+	wr.buf = append(wr.buf, '}')
+	wr.leave()
+}
+
+func (wr *Writer) colorOMap(n *OMap, depth int) {
+	wr.buf = append(wr.buf, wr.SyntaxColor...)
+	wr.buf = append(wr.buf, '{')
+	wr.buf = append(wr.buf, wr.NoColor...)
+
+	d2 := depth + 1
+	var is string
+	var cs string
+	if wr.Tab {
+		is = tabs[0:min(depth+1, len(tabs))]
+		cs = tabs[0:min(d2+1, len(tabs))]
+	} else if 0 < wr.Indent {
+		is = spaces[0:min(depth*wr.Indent+1, len(spaces))]
+		cs = spaces[0:min(d2*wr.Indent+1, len(spaces))]
+	}
+	first := true
+	for _, k := range n.keys {
+		if wr.colorMember(k, n.vals[k], cs, d2, first) {
+			first = false
+		}
+	}
+	wr.buf = append(wr.buf, is...)
+	wr.buf = append(wr.buf, wr.SyntaxColor...)
+	wr.buf = append(wr.buf, '}')
+}
+
+// omitMember returns true if an object member with the value should be
+// skipped because of the OmitNil or OmitEmpty options.
+func (wr *Writer) omitMember(m any) bool {
+	switch tm := m.(type) {
+	case nil:
+		return wr.OmitNil
+	case string:
+		return wr.OmitEmpty && len(tm) == 0
+	case map[string]any:
+		return wr.OmitEmpty && len(tm) == 0
+	case []any:
+		return wr.OmitEmpty && len(tm) == 0
+	case *OMap:
+		return wr.OmitEmpty && tm.Len() == 0
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/tt"
+)
+
+func TestParserOrderedObjects(t *testing.T) {
+	src := `{"z":1,"a":[{"y":true,"b":null}],"m":{"q":"x","c":{}},"z":2}`
+	p := oj.Parser{OrderedObjects: true}
+	v, err := p.Parse([]byte(src))
+	tt.Nil(t, err)
+	om, ok := v.(*oj.OMap)
+	tt.Equal(t, true, ok)
+	tt.Equal(t, []string{"z", "a", "m"}, om.Keys())
+	z, has := om.Get("z")
+	tt.Equal(t, true, has)
+	tt.Equal(t, 2, z)
+
+	tt.Equal(t, `{"z":2,"a":[{"y":true,"b":null}],"m":{"q":"x","c":{}}}`, oj.JSON(v))
+	tt.Equal(t, `{"z":2,"a":[{"y":true,"b":null}],"m":{"q":"x","c":{}}}`, oj.JSON(v, &oj.Options{Sort: true}))
+	tt.Equal(t, `{
+  "z": 2,
+  "a": [
+    {
+      "y": true,
+      "b": null
+    }
+  ],
+  "m": {
+    "q": "x",
+    "c": {}
+  }
+}`, oj.JSON(v, 2))
+	tt.Equal(t, `{"z":2,"a":[{"y":true}],"m":{"q":"x"}}`, oj.JSON(v, &oj.Options{OmitNil: true, OmitEmpty: true}))
+	m := &oj.OMap{}
+	m.Set("z", 2)
+	m.Set("a", 1)
+	m.Delete("a")
+	m.Delete("none")
+	inner := &oj.OMap{}
+	inner.Set("q", "x")
+	inner.Set("c", map[string]any{})
+	m.Set("m", inner)
+	tt.Equal(t, "{\n\t\"z\": 2,\n\t\"m\": {\n\t\t\"q\": \"x\",\n\t\t\"c\": {}\n\t}\n}", oj.JSON(m, &oj.Options{Tab: true}))
+
+	color := oj.JSON(v, &oj.Options{Color: true, SyntaxColor: "", KeyColor: "", NullColor: "", BoolColor: "",
+		NumberColor: "", StringColor: "", NoColor: ""})
+	tt.Equal(t, `{"z":2,"a":[{"y":true,"b":null}],"m":{"q":"x","c":{}}}`, color)
+
+	j, err := json.Marshal(v)
+	tt.Nil(t, err)
+	tt.Equal(t, `{"z":2,"a":[{"y":true,"b":null}],"m":{"q":"x","c":{}}}`, string(j))
+
+	tt.Equal(t, map[string]any{
+		"z": 2,
+		"a": []any{map[string]any{"y": true, "b": nil}},
+		"m": map[string]any{"q": "x", "c": map[string]any{}},
+	}, om.Simplify())
+	// Simplify does not modify the OMap.
+	a, _ := om.Get("a")
+	_, ok = a.([]any)[0].(*oj.OMap)
+	tt.Equal(t, true, ok)
+	tt.Equal(t, 3, om.Len())
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"sync"
+
+	"github.com/ohler55/ojg/alt"
+)
+
+const defaultParallelMin = 1024
+
+// appendParallel encodes a top level slice using multiple goroutines if the
+// Parallel option is set and the slice is large enough. Each goroutine
+// encodes a chunk of elements with a separate Writer that has the same
+// options and the chunks are then appended in order. False is returned if the data was not encoded.
+func (wr *Writer) appendParallel(data any) bool {
+	if wr.Parallel < 2 || data == nil || 0 < wr.MaxOutputBytes || wr.ctx != nil || wr.filtering {
+		return false
+	}
+	per := wr.ParallelMin
+	if per <= 0 {
+		per = defaultParallelMin
+	}
+	var (
+		size int
+		elem func(i int) any
+	)
+	switch td := data.(type) {
+	case []any:
+		size = len(td)
+		elem = func(i int) any { return td[i] }
+	default:
+		if wr.NoReflect {
+			return false
+		}
+		rv := reflect.ValueOf(data)
+		if rv.Kind() != reflect.Slice || rv.Type().Elem().Kind() == reflect.Uint8 {
+			return false
+		}
+		switch data.(type) {
+		case alt.Simplifier, alt.Genericer, json.Marshaler, encoding.TextMarshaler:
+			return false
+		}
+		size = rv.Len()
+		elem = func(i int) any { return rv.Index(i).Interface() }
+	}
+	cnt := size / per
+	if wr.Parallel < cnt {
+		cnt = wr.Parallel
+	}
+	if cnt < 2 {
+		return false
+	}
+	var cs string
+	if wr.Tab {
+		cs = tabs[0:2]
+	} else if 0 < wr.Indent {
+		x := wr.Indent + 1
+		if len(spaces) < x {
+			x = len(spaces)
+		}
+		cs = spaces[0:x]
+	}
+	chunks := make([][]byte, cnt)
+	fails := make([]any, cnt)
+	var wg sync.WaitGroup
+	for c := 0; c < cnt; c++ {
+		wg.Add(1)
+		go func(c int) {
+			defer wg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					fails[c] = r
+				}
+			}()
+			cw := Writer{
+				Options: wr.Options,
+				strict:  wr.strict,
+				Redact:  wr.Redact,
+			}
+			cw.buf = make([]byte, 0, wr.InitSize)
+			cw.prepare()
+			cw.root = data
+			cw.enterPlain(reflect.ValueOf(data), 0)
+			for i := c * size / cnt; i < (c+1)*size/cnt; i++ {
+				e := elem(i)
+				if cw.cycles {
+					cw.stepMember(i, reflect.ValueOf(e))
+				}
+				cw.buf = append(cw.buf, cs...)
+				cw.appendJSON(e, 1)
+				cw.buf = append(cw.buf, ',')
+			}
+			chunks[c] = cw.buf
+		}(c)
+	}
+	wg.Wait()
+	for _, r := range fails {
+		if r != nil {
+			panic(r)
+		}
+	}
+	wr.buf = append(wr.buf, '[')
+	for _, chunk := range chunks {
+		wr.buf = append(wr.buf, chunk...)
+		if wr.w != nil && wr.WriteLimit < len(wr.buf) {
+			if _, err := wr.w.Write(wr.buf[:len(wr.buf)-1]); err != nil {
+				panic(err)
+			}
+			wr.buf[0] = wr.buf[len(wr.buf)-1]
+			wr.buf = wr.buf[:1]
+		}
+	}
+	if 0 < len(cs) {
+		wr.buf[len(wr.buf)-1] = '\n'
+		wr.buf = append(wr.buf, ']')
+	} else {
+		wr.buf[len(wr.buf)-1] = ']'
+	}
+	return true
+}
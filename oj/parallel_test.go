@@ -0,0 +1,83 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/alt"
+	"github.com/ohler55/ojg/jp"
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/tt"
+)
+
+func TestWriteParallel(t *testing.T) {
+	type Item struct {
+		ID   int
+		Name string
+		Tags []string
+	}
+	items := make([]*Item, 103)
+	list := make([]any, len(items))
+	for i := range items {
+		items[i] = &Item{ID: i, Name: "item", Tags: []string{"a", "b"}}
+		list[i] = map[string]any{"id": i, "nested": []any{i, true, nil}}
+	}
+	for _, data := range []any{list, items} {
+		for _, opt := range []ojg.Options{{Sort: true}, {Sort: true, Indent: 2}, {Sort: true, Tab: true}} {
+			expect := oj.JSON(data, &opt)
+			popt := opt
+			popt.Parallel = 4
+			popt.ParallelMin = 10
+			tt.Equal(t, expect, oj.JSON(data, &popt))
+
+			var b strings.Builder
+			popt.WriteLimit = 50
+			tt.Nil(t, oj.Write(&b, data, &popt))
+			tt.Equal(t, expect, b.String())
+		}
+	}
+	// Too small to split.
+	tt.Equal(t, "[1,2,3]", oj.JSON([]any{1, 2, 3}, &ojg.Options{Parallel: 4}))
+	tt.Equal(t, "[]", oj.JSON([]any{}, &ojg.Options{Parallel: 4, ParallelMin: 1}))
+
+	// A failure in one chunk is returned.
+	bad := make([]any, 20)
+	bad[13] = func() {}
+	_, err := oj.Marshal(bad, &ojg.Options{Parallel: 4, ParallelMin: 2})
+	tt.NotNil(t, err)
+	tt.Equal(t, true, errors.Is(err, ojg.ErrUnsupportedType))
+}
+
+func TestWriteParallelRedact(t *testing.T) {
+	type Login struct {
+		User     string `json:"user"`
+		Password string `json:"password"`
+		Pin      string `json:"pin,redact"`
+	}
+	logins := make([]Login, 10)
+	list := make([]any, len(logins))
+	for i := range logins {
+		logins[i] = Login{User: "u", Password: "secret", Pin: "1234"}
+		list[i] = map[string]any{"user": "u", "password": "secret"}
+	}
+	for _, data := range []any{list, logins} {
+		wr := oj.Writer{
+			Options: ojg.Options{Sort: true, UseTags: true},
+			Redact:  &alt.Redactor{Keys: []string{"password"}},
+		}
+		expect := wr.JSON(data)
+		tt.Equal(t, false, strings.Contains(expect, "secret"))
+		wr.Parallel = 4
+		wr.ParallelMin = 1
+		tt.Equal(t, expect, wr.JSON(data))
+	}
+	wr := oj.Writer{
+		Options: ojg.Options{Parallel: 4, ParallelMin: 1},
+		Exclude: []jp.Expr{jp.MustParseString("$[*].password")},
+	}
+	tt.Equal(t, false, strings.Contains(wr.JSON(list), "secret"))
+}
@@ -4,14 +4,19 @@ package oj
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"reflect"
+	"unicode/utf16"
 	"unicode/utf8"
+	"unsafe"
 
 	"github.com/ohler55/ojg"
 	"github.com/ohler55/ojg/alt"
 	"github.com/ohler55/ojg/gen"
+	"github.com/ohler55/ojg/jp"
 )
 
 const (
@@ -38,13 +43,194 @@ type Parser struct {
 	mi         int
 	num        gen.Number
 	rn         rune
+	hi         rune // pending high surrogate
+	hiEnd      int
 	result     any
 	mode       string
 	nextMode   string
+	cmode      string // mode to return to after a comment
+	smode      string // string mode to return to after a JSON5 escape
+	path       jp.Expr
+	sdepth     int  // depth of a skipped value
+	sval       bool // true once a skipped value has started
+	sstr       bool // in a string of a skipped value
+	sesc       bool // after a backslash in a string of a skipped value
+	sraw       bool // keep the skipped value as a json.RawMessage
+	dropKey    bool // skip the value of the key just pushed
+	raw        []byte
+	numStart   int    // offset of the current number in the buffer
+	numRaw     []byte // start of a number from a previous buffer
+	zeroCopy   bool   // ZeroCopy applies to the current parse
 
 	// Reuse maps. Previously returned maps will no longer be valid or rather
 	// could be modified during parsing.
 	Reuse bool
+
+	// Comments if true allows // and /* */ comments (JSONC) anywhere white
+	// space is allowed. Comments are discarded.
+	Comments bool
+
+	// TrailingCommas if true allows a comma after the last element of an
+	// array or the last member of an object such as [1,2,] or {"a":1,}.
+	TrailingCommas bool
+
+	// JSON5 if true parses the JSON5 dialect which adds unquoted keys,
+	// single quoted strings, hexadecimal numbers, leading and trailing
+	// decimal points, explicit plus signs, Infinity, NaN, additional string
+	// escapes, trailing commas, and comments to JSON.
+	JSON5 bool
+
+	// PathHook if not nil is called with the path of each object member
+	// before the member value is parsed. The path starts with the root such
+	// as $.a[2].b and is only valid for the duration of the call. The
+	// returned PathAction determines whether the value is kept, skipped, or
+	// causes the parse to fail. Skipping a value avoids allocating memory for
+	// it. PathPrefixHook() returns a hook that skips by path prefix.
+	PathHook func(path jp.Expr) PathAction
+
+	// ZeroCopy if true causes Parse to return strings and keys that
+	// reference the bytes of the input buffer instead of copies when the
+	// string has no escape sequences. This is unsafe since the returned
+	// strings change if the buffer is modified so the buffer must not be
+	// modified or reused while the results are in use. Modifying the bytes of
+	// a key also corrupts the map holding it. It is intended for
+	// read-only workloads that parse large documents but only inspect a few
+	// values. ParseReader ignores the flag since the read buffer is reused.
+	ZeroCopy bool
+
+	// OrderedObjects if true returns objects as *OMap instead of
+	// map[string]any so that the order of the keys in the document is
+	// preserved. Reuse does not apply to OMap objects.
+	OrderedObjects bool
+
+	// NumberConv if not nil is called with the path and the raw text of
+	// each number. The value returned is used in place of the number which
+	// allows numbers such as money amounts to be decoded directly into a
+	// decimal type. If the value returned is nil the number is converted as
+	// usual. If an error is returned the parse fails with that error. The
+	// path is only valid for the duration of the call. Numbers in the
+	// JSON5 only forms such as hexadecimal or Infinity are not passed to
+	// NumberConv.
+	NumberConv NumberConvFunc
+
+	// DecodeHook if not nil is called by Unmarshal with the path, the
+	// parsed value, and the target type before each object member value or
+	// array element is assigned to a typed location. It allows per path
+	// conversions without registering composers. See alt.DecodeHookFunc
+	// for the handling of the returned value.
+	DecodeHook func(path jp.Expr, value any, target reflect.Type) (any, error)
+
+	// BlankKeys is the policy for object keys that are empty or only
+	// whitespace. Choices are ojg.BlankKeyAllow, ojg.BlankKeyError, or
+	// ojg.BlankKeyRename. A renamed key replaces any earlier member with the
+	// same key.
+	BlankKeys int
+
+	// BlankKeyName replaces blank keys when BlankKeys is
+	// ojg.BlankKeyRename. If empty ojg.DefaultBlankKeyName is used.
+	BlankKeyName string
+
+	// UnsafeKeys is the policy for object keys on the UnsafeKeyList such as
+	// "__proto__" that can be used for prototype pollution when the JSON is
+	// later consumed by JavaScript. Choices are ojg.UnsafeKeyAllow,
+	// ojg.UnsafeKeyError, or ojg.UnsafeKeyStrip. A stripped member value is
+	// skipped without being built.
+	UnsafeKeys int
+
+	// UnsafeKeyList are the keys checked by the UnsafeKeys policy. Keys
+	// must match exactly. If empty ojg.DefaultUnsafeKeys is used.
+	UnsafeKeyList []string
+
+	// DisallowUnknownFields if true causes Unmarshal to return an
+	// ojg.ErrUnknownFieldAt error with the path of the first object member
+	// that does not match a field of the struct it is recomposed into.
+	DisallowUnknownFields bool
+
+	// Allocator if not nil provides the read buffer used by ParseReader and
+	// the buffer used for tokens.
+	Allocator ojg.Allocator
+
+	// InternKeys if true interns object keys through the StringCache so
+	// that the keys repeated across many documents share memory and are
+	// not allocated again.
+	InternKeys bool
+
+	// InternMaxLen if greater than zero also interns string values that
+	// are no longer than InternMaxLen bytes.
+	InternMaxLen int
+
+	// StringCache is the cache used for interning. If nil the
+	// ojg.DefaultStringCache is used.
+	StringCache *ojg.StringCache
+
+	// InitBufSize if greater than zero is the initial capacity of the token
+	// buffer in bytes and of the value stack in elements.
+	InitBufSize int
+
+	// MaxBufSize if greater than zero limits the capacity that is retained
+	// by a reused parser. Buffers that grew larger while parsing an
+	// unusually large document are released when the next parse starts or
+	// when Reset is called.
+	MaxBufSize int
+
+	// InvalidUTF8 indicates how strings and keys that are not valid UTF-8
+	// are handled. Choices are ojg.UTF8Default, ojg.UTF8Pass,
+	// ojg.UTF8Replace, or ojg.UTF8Reject. The default keeps invalid bytes
+	// as is. A UTF-8 byte order mark at the start of the input is skipped
+	// regardless.
+	InvalidUTF8 int
+}
+
+// Reset clears the state of the parser and releases any references to
+// previously parsed data, including maps kept for the Reuse option, so
+// that a long lived parser can be safely reused. Buffers are retained for
+// reuse unless they are larger than the MaxBufSize.
+func (p *Parser) Reset() {
+	p.stack = p.stack[:cap(p.stack)]
+	clear(p.stack)
+	clear(p.maps)
+	p.maps = p.maps[:0]
+	p.mi = 0
+	p.result = nil
+	p.cb = nil
+	p.resultChan = nil
+	p.path = p.path[:0]
+	p.hi = 0
+	p.hiEnd = 0
+	p.initBuffers()
+}
+
+func (p *Parser) initBuffers() {
+	if 0 < p.MaxBufSize {
+		if p.MaxBufSize < cap(p.tmp) {
+			ojg.Free(p.Allocator, p.tmp)
+			p.tmp = nil
+		}
+		if p.MaxBufSize < cap(p.stack) {
+			p.stack = nil
+		}
+	}
+	size := p.InitBufSize
+	if p.stack == nil {
+		if size <= 0 {
+			size = stackInitSize
+		}
+		p.stack = make([]any, 0, size)
+		p.starts = make([]int, 0, 16)
+		p.maps = make([]map[string]any, 0, 16)
+	} else {
+		p.stack = p.stack[:0]
+		p.starts = p.starts[:0]
+	}
+	if p.tmp == nil {
+		size = p.InitBufSize
+		if size <= 0 {
+			size = tmpInitSize
+		}
+		p.tmp = ojg.Alloc(p.Allocator, size)
+	} else {
+		p.tmp = p.tmp[:0]
+	}
 }
 
 func recomposeToJSON(v any) (any, error) {
@@ -56,20 +242,47 @@ func init() {
 }
 
 // Unmarshal parses the provided JSON and stores the result in the value
-// pointed to by vp.
+// pointed to by vp. Values that implement json.Unmarshaler are handled as
+// they are by the Unmarshal function unless the PathHook is set.
 func (p *Parser) Unmarshal(data []byte, vp any, recomposer ...alt.Recomposer) (err error) {
+	if um, ok := vp.(json.Unmarshaler); ok {
+		return um.UnmarshalJSON(data)
+	}
 	var v any
 	orig := p.num.ForceFloat
 	p.num.ForceFloat = true
+	if p.PathHook == nil {
+		p.PathHook = unmarshalerHook(reflect.TypeOf(vp))
+		defer func() { p.PathHook = nil }()
+	}
 	if v, err = p.Parse(data); err == nil {
-		_, err = alt.Recompose(v, vp)
+		rec := alt.DefaultRecomposer
+		if 0 < len(recomposer) {
+			rec = recomposer[0]
+		}
+		rec.DisallowUnknownFields = rec.DisallowUnknownFields || p.DisallowUnknownFields
+		if p.DecodeHook != nil {
+			rec.DecodeHook = func(path string, value any, target reflect.Type) (any, error) {
+				x, err := jp.ParseString(path)
+				if err != nil {
+					return nil, err
+				}
+				return p.DecodeHook(x, value, target)
+			}
+		}
+		_, err = rec.Recompose(v, vp)
 	}
 	p.num.ForceFloat = orig
 	return
 }
 
+// AllowComments is an argument to Parse and ParseReader that sets the
+// Comments field of the Parser for that call only.
+type AllowComments bool
+
 // Parse a JSON string in to simple types. An error is returned if not valid JSON.
 func (p *Parser) Parse(buf []byte, args ...any) (any, error) {
+	defer func(comments bool) { p.Comments = comments }(p.Comments)
 	p.cb = nil
 	p.resultChan = nil
 	p.OnlyOne = true
@@ -88,36 +301,34 @@ func (p *Parser) Parse(buf []byte, args ...any) (any, error) {
 			p.Reuse = false
 		case ojg.NumConvMethod:
 			p.num.Conv = ta
+		case AllowComments:
+			p.Comments = bool(ta)
 		default:
 			return nil, fmt.Errorf("a %T is not a valid option type", a)
 		}
 	}
-	if p.stack == nil {
-		p.stack = make([]any, 0, stackInitSize)
-		p.tmp = make([]byte, 0, tmpInitSize)
-		p.starts = make([]int, 0, 16)
-		p.maps = make([]map[string]any, 0, 16)
-	} else {
-		p.stack = p.stack[:0]
-		p.tmp = p.tmp[:0]
-		p.starts = p.starts[:0]
-	}
+	p.initBuffers()
+	p.zeroCopy = p.ZeroCopy
 	p.result = nil
 	p.noff = -1
 	p.line = 1
+	p.base = 0
 	p.mode = valueMap
 	p.mi = 0
+	p.dropKey = false
 	var err error
 	// Skip BOM if present.
 	if 3 < len(buf) && buf[0] == 0xEF {
 		if buf[1] == 0xBB && buf[2] == 0xBF {
+			p.base = 3
 			err = p.parseBuffer(buf[3:], true)
 		} else {
-			return nil, fmt.Errorf("expected BOM at 1:3")
+			return nil, ojg.Errorf(ojg.ErrSyntax, "expected BOM at 1:3")
 		}
 	} else {
 		err = p.parseBuffer(buf, true)
 	}
+	p.src = nil
 	p.stack = p.stack[:cap(p.stack)]
 	for i := len(p.stack) - 1; 0 <= i; i-- {
 		p.stack[i] = nil
@@ -130,6 +341,7 @@ func (p *Parser) Parse(buf []byte, args ...any) (any, error) {
 // ParseReader reads JSON from an io.Reader. An error is returned if not valid
 // JSON.
 func (p *Parser) ParseReader(r io.Reader, args ...any) (data any, err error) {
+	defer func(comments bool) { p.Comments = comments }(p.Comments)
 	p.cb = nil
 	p.resultChan = nil
 	p.OnlyOne = true
@@ -148,25 +360,25 @@ func (p *Parser) ParseReader(r io.Reader, args ...any) (data any, err error) {
 			p.Reuse = false
 		case ojg.NumConvMethod:
 			p.num.Conv = ta
+		case AllowComments:
+			p.Comments = bool(ta)
 		default:
 			return nil, fmt.Errorf("a %T is not a valid option type", a)
 		}
 	}
-	if p.stack == nil {
-		p.stack = make([]any, 0, stackInitSize)
-		p.tmp = make([]byte, 0, tmpInitSize)
-		p.starts = make([]int, 0, 16)
-		p.maps = make([]map[string]any, 0, 16)
-	} else {
-		p.stack = p.stack[:0]
-		p.tmp = p.tmp[:0]
-		p.starts = p.starts[:0]
-	}
+	p.initBuffers()
+	p.zeroCopy = false
 	p.result = nil
 	p.noff = -1
 	p.line = 1
+	p.base = 0
 	p.mi = 0
-	buf := make([]byte, readBufSize)
+	p.dropKey = false
+	buf := ojg.Alloc(p.Allocator, readBufSize)[:readBufSize]
+	defer func() {
+		p.src = nil
+		ojg.Free(p.Allocator, buf)
+	}()
 	eof := false
 	var cnt int
 	cnt, err = r.Read(buf)
@@ -184,11 +396,11 @@ func (p *Parser) ParseReader(r io.Reader, args ...any) (data any, err error) {
 		skip = 3
 	}
 	for {
-		if 0 < skip {
-			err = p.parseBuffer(buf[skip:], eof)
-		} else {
-			err = p.parseBuffer(buf, eof)
-		}
+		chunk := buf[skip:]
+		p.base += skip
+		err = p.parseBuffer(chunk, eof)
+		p.noff -= len(chunk)
+		p.base += len(chunk)
 		if err != nil {
 			p.stack = p.stack[:cap(p.stack)]
 			for i := len(p.stack) - 1; 0 <= i; i-- {
@@ -217,10 +429,45 @@ func (p *Parser) ParseReader(r io.Reader, args ...any) (data any, err error) {
 	return
 }
 
+// stopMany carries the error returned by a ParseReaderMany callback out of
+// the parse.
+type stopMany struct {
+	err error
+}
+
+// ParseReaderMany reads a stream of concatenated or newline delimited JSON
+// documents and calls the callback with each top level value as soon as it
+// has been read. Only the document being parsed is held in memory. If the
+// callback returns an error parsing stops and that error is returned.
+func (p *Parser) ParseReaderMany(r io.Reader, cb func(any) error) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			stop, ok := rec.(*stopMany)
+			if !ok {
+				panic(rec)
+			}
+			p.stack = p.stack[:cap(p.stack)]
+			for i := len(p.stack) - 1; 0 <= i; i-- {
+				p.stack[i] = nil
+			}
+			p.stack = p.stack[:0]
+			err = stop.err
+		}
+	}()
+	_, err = p.ParseReader(r, func(v any) {
+		if cerr := cb(v); cerr != nil {
+			panic(&stopMany{err: cerr})
+		}
+	})
+	return
+}
+
 func (p *Parser) parseBuffer(buf []byte, last bool) error {
+	p.src = buf
 	var b byte
 	var i int
 	var off int
+	var err error
 	depth := len(p.starts)
 	for off = 0; off < len(buf); off++ {
 		b = buf[off]
@@ -237,6 +484,38 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 			continue
 		case colonColon:
 			p.mode = valueMap
+			if p.dropKey {
+				p.dropKey = false
+				p.startSkip(false)
+				continue
+			}
+			if p.PathHook != nil {
+				switch action := p.PathHook(p.keyPath()); action {
+				case PathSkip, PathRaw:
+					p.startSkip(action == PathRaw)
+				case PathReject:
+					return p.newError(off, nil, "%s rejected", p.path)
+				}
+			}
+			continue
+		case skipValue:
+			var done bool
+			start := off
+			if off, done, err = p.skipValue(buf, off); err != nil {
+				return err
+			}
+			if p.sraw {
+				p.raw = append(p.raw, buf[start:min(off+1, len(buf))]...)
+			}
+			switch {
+			case !done:
+			case p.sraw:
+				p.add(json.RawMessage(append([]byte{}, bytes.TrimLeft(p.raw, " \t\r\n")...)))
+				p.mode = afterMap
+			default:
+				p.stack = p.stack[:len(p.stack)-1] // drop the key
+				p.mode = afterMap
+			}
 			continue
 		case skipChar: // skip and continue
 			continue
@@ -250,15 +529,29 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 				p.nextMode = colonMap
 				continue
 			}
-			for i, b = range buf[off+1:] {
+			n := plainStringLen(buf[off+1:])
+			for i, b = range buf[off+1+n:] {
 				if stringMap[b] != strOk {
 					break
 				}
 			}
-			off += i
+			off += i + n
 			if b == '"' {
 				off++
-				p.stack = append(p.stack, gen.Key(buf[start:off]))
+				str := buf[start:off]
+				if ojg.UTF8Replace <= p.InvalidUTF8 {
+					if str, err = p.checkUTF8(off, str); err != nil {
+						return err
+					}
+				}
+				if p.zeroCopy && !p.InternKeys {
+					err = p.pushKey(off, bufString(str))
+				} else {
+					err = p.pushKey(off, p.keyString(str))
+				}
+				if err != nil {
+					return err
+				}
 				p.mode = colonMap
 			} else {
 				p.tmp = p.tmp[:0]
@@ -282,15 +575,26 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 				p.nextMode = afterMap
 				continue
 			}
-			for i, b = range buf[off+1:] {
+			n := plainStringLen(buf[off+1:])
+			for i, b = range buf[off+1+n:] {
 				if stringMap[b] != strOk {
 					break
 				}
 			}
-			off += i
+			off += i + n
 			if b == '"' {
 				off++
-				p.add(string(buf[start:off]))
+				str := buf[start:off]
+				if ojg.UTF8Replace <= p.InvalidUTF8 {
+					if str, err = p.checkUTF8(off, str); err != nil {
+						return err
+					}
+				}
+				if p.zeroCopy && p.InternMaxLen <= 0 {
+					p.add(bufString(str))
+				} else {
+					p.add(p.valueString(str))
+				}
 				p.mode = afterMap
 			} else {
 				p.tmp = p.tmp[:0]
@@ -300,7 +604,9 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 				continue
 			}
 		case numComma:
-			p.add(p.num.AsNum())
+			if err = p.addNum(buf, off); err != nil {
+				return err
+			}
 			if 0 < len(p.starts) {
 				if p.starts[len(p.starts)-1] == -1 {
 					p.mode = keyMap
@@ -308,7 +614,7 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 					p.mode = commaMap
 				}
 			} else {
-				return p.newError(off, "unexpected comma")
+				return p.newError(off, ojg.ErrUnexpectedChar, "unexpected comma")
 			}
 		case strSlash:
 			p.mode = escMap
@@ -320,6 +626,11 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 		case openObject:
 			p.starts = append(p.starts, -1)
 			p.mode = key1Map
+			if p.OrderedObjects {
+				p.stack = append(p.stack, &OMap{})
+				depth++
+				continue
+			}
 			var m map[string]any
 			if p.Reuse {
 				if p.mi < len(p.maps) {
@@ -341,10 +652,12 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 		case closeObject:
 			depth--
 			if depth < 0 || 0 <= p.starts[depth] {
-				return p.newError(off, "unexpected object close")
+				return p.newError(off, ojg.ErrUnexpectedChar, "unexpected object close")
 			}
 			if 256 < len(p.mode) && p.mode[256] == 'n' {
-				p.add(p.num.AsNum())
+				if err = p.addNum(buf, off); err != nil {
+					return err
+				}
 			}
 			p.starts = p.starts[0:depth]
 			n := p.stack[len(p.stack)-1]
@@ -354,8 +667,10 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 		case val0:
 			p.mode = zeroMap
 			p.num.Reset()
+			p.numStart = off
 		case valDigit:
 			p.num.Reset()
+			p.numStart = off
 			p.mode = digitMap
 			p.num.I = uint64(b - '0')
 			for i, b = range buf[off+1:] {
@@ -376,6 +691,7 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 		case valNeg:
 			p.mode = negMap
 			p.num.Reset()
+			p.numStart = off
 			p.num.Neg = true
 			continue
 		case escU:
@@ -392,12 +708,14 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 		case closeArray:
 			depth--
 			if depth < 0 || p.starts[depth] < 0 {
-				return p.newError(off, "unexpected array close")
+				return p.newError(off, ojg.ErrUnexpectedChar, "unexpected array close")
 			}
 			// Only modes with a close array are value, after, and numbers
 			// which are all over 256 long.
 			if p.mode[256] == 'n' {
-				p.add(p.num.AsNum())
+				if err = p.addNum(buf, off); err != nil {
+					return err
+				}
 			}
 			start := p.starts[len(p.starts)-1] + 1
 			p.starts = p.starts[:len(p.starts)-1]
@@ -467,10 +785,17 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 			continue
 		case strQuote:
 			p.mode = p.nextMode
+			if ojg.UTF8Replace <= p.InvalidUTF8 {
+				if p.tmp, err = p.checkUTF8(off, p.tmp); err != nil {
+					return err
+				}
+			}
 			if p.mode[':'] == colonColon {
-				p.stack = append(p.stack, gen.Key(p.tmp))
+				if err = p.pushKey(off, p.keyString(p.tmp)); err != nil {
+					return err
+				}
 			} else {
-				p.add(string(p.tmp))
+				p.add(p.valueString(p.tmp))
 			}
 		case numZero:
 			p.mode = zeroMap
@@ -480,10 +805,14 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 			p.num.AddDigit(b)
 			p.mode = digitMap
 		case numSpc:
-			p.add(p.num.AsNum())
+			if err = p.addNum(buf, off); err != nil {
+				return err
+			}
 			p.mode = afterMap
 		case numNewline:
-			p.add(p.num.AsNum())
+			if err = p.addNum(buf, off); err != nil {
+				return err
+			}
 			p.line++
 			p.noff = off
 			p.mode = afterMap
@@ -516,7 +845,19 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 				if len(p.runeBytes) < 6 {
 					p.runeBytes = make([]byte, 6)
 				}
-				n := utf8.EncodeRune(p.runeBytes, p.rn)
+				r := p.rn
+				switch {
+				case 0xD800 <= r && r < 0xDC00:
+					// A high surrogate is written as U+FFFD unless followed
+					// by a low surrogate escape.
+					p.hi = r
+					p.hiEnd = len(p.tmp) + 3
+				case 0xDC00 <= r && r < 0xE000 && p.hi != 0 && p.hiEnd == len(p.tmp):
+					p.tmp = p.tmp[:len(p.tmp)-3]
+					r = utf16.DecodeRune(p.hi, r)
+					p.hi = 0
+				}
+				n := utf8.EncodeRune(p.runeBytes, r)
 				p.tmp = append(p.tmp, p.runeBytes[:n]...)
 				p.mode = stringMap
 			}
@@ -526,7 +867,7 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 			case p.mode['r'] == tokenOk:
 				p.ri++
 				if "true"[p.ri] != b {
-					return p.newError(off, "expected true")
+					return p.newError(off, ojg.ErrInvalidLiteral, "expected true")
 				}
 				if 3 <= p.ri {
 					p.add(true)
@@ -535,7 +876,7 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 			case p.mode['a'] == tokenOk:
 				p.ri++
 				if "false"[p.ri] != b {
-					return p.newError(off, "expected false")
+					return p.newError(off, ojg.ErrInvalidLiteral, "expected false")
 				}
 				if 4 <= p.ri {
 					p.add(false)
@@ -544,14 +885,77 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 			case p.mode['u'] == tokenOk && p.mode['l'] == tokenOk:
 				p.ri++
 				if "null"[p.ri] != b {
-					return p.newError(off, "expected null")
+					return p.newError(off, ojg.ErrInvalidLiteral, "expected null")
 				}
 				if 3 <= p.ri {
 					p.add(nil)
 					p.mode = afterMap
 				}
 			}
+		case lineComment:
+			p.mode = commentMap
+			continue
+		case starComment:
+			p.mode = starCommentMap
+			continue
+		case commentEnd:
+			p.line++
+			p.noff = off
+			p.mode = p.cmode
+			continue
+		case starEnd:
+			p.mode = starEndMap
+			continue
+		case starDone:
+			p.mode = p.cmode
+			continue
+		case starNewline:
+			p.line++
+			p.noff = off
+			p.mode = starCommentMap
+			continue
+		case starChar:
+			p.mode = starCommentMap
+			continue
+		case json5Char:
+			if off, _, err = p.json5(off, b); err != nil {
+				return err
+			}
 		case charErr:
+			if p.JSON5 {
+				var ok bool
+				if off, ok, err = p.json5(off, b); err != nil {
+					return err
+				}
+				if ok {
+					break
+				}
+			}
+			if (p.TrailingCommas || p.JSON5) &&
+				((b == ']' && p.mode == commaMap) || (b == '}' && p.mode == keyMap)) {
+				// Drop the trailing comma and try the close again.
+				p.mode = afterMap
+				off--
+				continue
+			}
+			if b == '/' && (p.Comments || p.JSON5) {
+				switch p.mode[' '] {
+				case skipChar:
+					p.cmode = p.mode
+					p.mode = commentStartMap
+					continue
+				case numSpc:
+					// Finish the number and then try the '/' again.
+					if err = p.addNum(buf, off); err != nil {
+						return err
+					}
+					p.mode = afterMap
+					off--
+				default:
+					return p.byteError(off, p.mode, b, bytes.Runes(buf[off:])[0])
+				}
+				break
+			}
 			return p.byteError(off, p.mode, b, bytes.Runes(buf[off:])[0])
 		}
 		if depth == 0 && 256 < len(p.mode) && p.mode[256] == 'a' {
@@ -574,12 +978,31 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 			}
 		}
 	}
+	if !last && p.NumberConv != nil && p.inNumber() {
+		// Keep the start of a number that continues in the next buffer.
+		p.numRaw = append(p.numRaw, buf[p.numStart:]...)
+		p.numStart = 0
+	}
 	if last {
+		if p.mode == commentMap {
+			p.mode = p.cmode
+		}
+		var added bool
+		if p.JSON5 {
+			if added, err = p.json5Last(off); err != nil {
+				return err
+			}
+		}
 		if 0 < len(p.starts) || len(p.mode) == 256 { // valid finishing maps are one byte longer
-			return p.newError(off, "incomplete JSON")
+			return p.newError(min(off, len(buf)), ojg.ErrUnexpectedEOF, "incomplete JSON")
 		}
 		if p.mode[256] == 'n' {
-			p.add(p.num.AsNum())
+			if err = p.addNum(buf, len(buf)); err != nil {
+				return err
+			}
+			added = true
+		}
+		if added {
 			if p.cb == nil && p.resultChan == nil {
 				p.result = p.stack[0]
 			} else {
@@ -595,11 +1018,145 @@ func (p *Parser) parseBuffer(buf []byte, last bool) error {
 	return nil
 }
 
+// pushKey pushes an object key onto the stack after applying the
+// BlankKeys and UnsafeKeys policies.
+func (p *Parser) pushKey(off int, key string) error {
+	if p.BlankKeys != ojg.BlankKeyAllow && ojg.IsBlankKey(key) {
+		if p.BlankKeys == ojg.BlankKeyError {
+			return p.newError(off, ojg.ErrBlankKey, "blank key %q", key)
+		}
+		key = p.BlankKeyName
+		if len(key) == 0 {
+			key = ojg.DefaultBlankKeyName
+		}
+	}
+	if p.UnsafeKeys != ojg.UnsafeKeyAllow && p.unsafeKey(key) {
+		if p.UnsafeKeys == ojg.UnsafeKeyError {
+			return p.newError(off, ojg.ErrUnsafeKey, "unsafe key %q", key)
+		}
+		p.dropKey = true
+	}
+	p.stack = append(p.stack, gen.Key(key))
+
+	return nil
+}
+
+func (p *Parser) unsafeKey(key string) bool {
+	list := p.UnsafeKeyList
+	if len(list) == 0 {
+		list = ojg.DefaultUnsafeKeys
+	}
+	for _, k := range list {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// startSkip switches to skipping the value of the key on the top of the
+// stack. If raw is true the skipped bytes are kept as a json.RawMessage.
+func (p *Parser) startSkip(raw bool) {
+	p.mode = skipMap
+	p.sdepth = 0
+	p.sval = false
+	p.sstr = false
+	p.sesc = false
+	p.sraw = raw
+	p.raw = p.raw[:0]
+}
+
+func (p *Parser) keyString(b []byte) string {
+	if p.InternKeys {
+		return p.stringCache().Intern(b)
+	}
+	return string(b)
+}
+
+func (p *Parser) valueString(b []byte) string {
+	if 0 < p.InternMaxLen && len(b) <= p.InternMaxLen {
+		return p.stringCache().Intern(b)
+	}
+	return string(b)
+}
+
+// checkUTF8 returns the bytes of a string with invalid UTF-8 replaced or
+// an error according to the InvalidUTF8 option. The bytes are returned
+// as is if valid.
+func (p *Parser) checkUTF8(off int, b []byte) ([]byte, error) {
+	if utf8.Valid(b) {
+		return b, nil
+	}
+	if p.InvalidUTF8 == ojg.UTF8Reject {
+		return nil, p.newError(off, ojg.ErrInvalidString, "invalid UTF-8 in string")
+	}
+	fixed := make([]byte, 0, len(b)+8)
+	for i := 0; i < len(b); {
+		r, cnt := utf8.DecodeRune(b[i:])
+		if r == utf8.RuneError && cnt == 1 {
+			fixed = utf8.AppendRune(fixed, utf8.RuneError)
+		} else {
+			fixed = append(fixed, b[i:i+cnt]...)
+		}
+		i += cnt
+	}
+	return fixed, nil
+}
+
+// bufString returns a string that shares memory with the bytes.
+func bufString(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	return unsafe.String(&b[0], len(b))
+}
+
+func (p *Parser) stringCache() *ojg.StringCache {
+	if p.StringCache != nil {
+		return p.StringCache
+	}
+	return &ojg.DefaultStringCache
+}
+
+// inNumber returns true if the parser is part way through a number.
+func (p *Parser) inNumber() bool {
+	switch p.mode {
+	case negMap, zeroMap, digitMap, dotMap, fracMap, expSignMap, expZeroMap, expMap:
+		return true
+	}
+	return false
+}
+
+// addNum adds the current number that ends at the offset. If the
+// NumberConv function is set it is called with the raw text of the number.
+func (p *Parser) addNum(buf []byte, end int) error {
+	if p.NumberConv == nil {
+		p.add(p.num.AsNum())
+		return nil
+	}
+	raw := append(p.numRaw, buf[p.numStart:end]...)
+	p.numRaw = raw[:0]
+	v, err := p.NumberConv(p.keyPath(), string(raw))
+	if err != nil {
+		return p.newError(end, ojg.ErrInvalidNumber, "%s", err)
+	}
+	if v == nil {
+		v = p.num.AsNum()
+	}
+	p.add(v)
+
+	return nil
+}
+
 func (p *Parser) add(n any) {
 	if 2 <= len(p.stack) {
 		if k, ok := p.stack[len(p.stack)-1].(gen.Key); ok {
-			obj, _ := p.stack[len(p.stack)-2].(map[string]any)
-			obj[string(k)] = n
+			switch obj := p.stack[len(p.stack)-2].(type) {
+			case map[string]any:
+				obj[string(k)] = n
+			case *OMap:
+				obj.Set(string(k), n)
+			}
 			p.stack = p.stack[0 : len(p.stack)-1]
 
 			return
@@ -4,12 +4,17 @@ package oj_test
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"strings"
+	"sync"
 	"testing"
 	"testing/iotest"
+	"unsafe"
 
 	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/jp"
 	"github.com/ohler55/ojg/oj"
 	"github.com/ohler55/ojg/tt"
 )
@@ -75,6 +80,8 @@ func TestParserParseString(t *testing.T) {
 		{src: "[[true]]", value: []any{[]any{true}}},
 		{src: `"x\t\n\"\b\f\r\u0041\\\/y"`, value: "x\t\n\"\b\f\r\u0041\\/y"},
 		{src: `"x\u004a\u004Ay"`, value: "xJJy"},
+		{src: `"x\ud83d\ude00y"`, value: "x\U0001f600y"},
+		{src: `"\ud83dx\ude00"`, value: "\ufffdx\ufffd"},
 
 		{src: `[1,"a\tb"]`, value: []any{1, "a\tb"}},
 		{src: `{"a\tb":1}`, value: map[string]any{"a\tb": 1}},
@@ -318,6 +325,56 @@ func TestParserParseReaderMany(t *testing.T) {
 	}
 }
 
+func TestParserLongStrings(t *testing.T) {
+	// Place each escape at every position of keys and values long enough to
+	// be scanned in 8 byte words.
+	for _, special := range []string{`\"`, `\\`, `\n`, `\u0041`, "é"} {
+		for i := 0; i < 24; i++ {
+			str := strings.Repeat("a", i) + special + strings.Repeat("b", 24-i)
+			src := `{"` + str + `":"` + str + `"}`
+			var expect any
+			tt.Nil(t, json.Unmarshal([]byte(src), &expect))
+			v, err := oj.ParseString(src)
+			tt.Nil(t, err, src)
+			tt.Equal(t, expect, v, src)
+		}
+	}
+	_, err := oj.ParseString("\"" + strings.Repeat("a", 20) + "\x01" + strings.Repeat("b", 20) + "\"")
+	tt.NotNil(t, err)
+}
+
+func TestParseReaderManyCallback(t *testing.T) {
+	src := "{\"a\":1}\n[2,3]\n\"x\" 4 {\"b\":" + strings.Repeat(" ", 5000) + "true}\n"
+	var got []any
+	err := oj.ParseReaderMany(strings.NewReader(src), func(v any) error {
+		got = append(got, v)
+		return nil
+	})
+	tt.Nil(t, err)
+	tt.Equal(t, []any{map[string]any{"a": 1}, []any{2, 3}, "x", 4, map[string]any{"b": true}}, got)
+
+	stop := errors.New("stop")
+	got = got[:0]
+	var p oj.Parser
+	err = p.ParseReaderMany(strings.NewReader(src), func(v any) error {
+		got = append(got, v)
+		if len(got) == 2 {
+			return stop
+		}
+		return nil
+	})
+	tt.Equal(t, stop, err)
+	tt.Equal(t, 2, len(got))
+
+	// The parser can be used again after being stopped.
+	v, err := p.Parse([]byte(`[1,{"c":2}]`))
+	tt.Nil(t, err)
+	tt.Equal(t, []any{1, map[string]any{"c": 2}}, v)
+
+	err = oj.ParseReaderMany(strings.NewReader(`{"a":1} [2,`), func(v any) error { return nil })
+	tt.NotNil(t, err)
+}
+
 func TestParserParseChan(t *testing.T) {
 	var results []byte
 	rc := make(chan any, 10)
@@ -383,3 +440,434 @@ func TestParserNumConv(t *testing.T) {
 	v = oj.MustLoad(strings.NewReader("0.1234567890123456789"), ojg.NumConvFloat64)
 	tt.Equal(t, 0.123456789012345678, v)
 }
+
+func TestParserComments(t *testing.T) {
+	for i, d := range []data{
+		{src: "// lead\ntrue", value: true},
+		{src: "/* lead */ true /* trail */", value: true},
+		{src: "true // trail", value: true},
+		{src: "123// trail", value: 123},
+		{src: "12.5/* trail */", value: 12.5},
+		{src: "[1,/* x */2 // y\n,3]", value: []any{1, 2, 3}},
+		{src: "[1/**/]", value: []any{1}},
+		{src: "{/* a */\"a\" /* b */: /* c */1 /* d */, // e\n\"b\":2}", value: map[string]any{"a": 1, "b": 2}},
+		{src: "/* multi\n * line **/\n{}", value: map[string]any{}},
+		{src: "[\"// not a comment\", \"/* nor this */\"]", value: []any{"// not a comment", "/* nor this */"}},
+		{src: "/* unterminated", expect: "incomplete JSON at 1:16"},
+		{src: "/x", expect: "unexpected character 'x' at 1:2"},
+		{src: "[1,/* x */\n/]", expect: "unexpected character ']' at 2:2"},
+		{src: "-/* x */1", expect: "invalid number at 1:2"},
+		{src: "/* x */\n/* y */ x", expect: "unexpected character 'x' at 2:9"},
+	} {
+		p := oj.Parser{Comments: true}
+		v, err := p.Parse([]byte(d.src))
+		if 0 < len(d.expect) {
+			tt.NotNil(t, err, i, ": ", d.src)
+			tt.Equal(t, d.expect, err.Error(), i, ": ", d.src)
+		} else {
+			tt.Nil(t, err, i, ": ", d.src)
+			tt.Equal(t, d.value, v, i, ": ", d.src)
+		}
+		if len(d.expect) == 0 {
+			v, err = p.ParseReader(iotest.OneByteReader(strings.NewReader(d.src)))
+			tt.Nil(t, err, i, ": ", d.src)
+			tt.Equal(t, d.value, v, i, ": ", d.src)
+		}
+	}
+}
+
+func TestParseCommentsArg(t *testing.T) {
+	src := "[1, // one\n 2]"
+	v, err := oj.Parse([]byte(src), oj.AllowComments(true))
+	tt.Nil(t, err)
+	tt.Equal(t, []any{1, 2}, v)
+
+	// The arg only applies to that call.
+	_, err = oj.Parse([]byte(src))
+	tt.NotNil(t, err)
+
+	v, err = oj.Load(strings.NewReader(src), oj.AllowComments(true))
+	tt.Nil(t, err)
+	tt.Equal(t, []any{1, 2}, v)
+
+	var p oj.Parser
+	_, err = p.Parse([]byte(src))
+	tt.NotNil(t, err)
+	tt.Equal(t, false, p.Comments)
+
+	// A plain bool is not mistaken for the option.
+	_, err = oj.Parse([]byte(src), true)
+	tt.NotNil(t, err)
+}
+
+func TestParserTrailingCommas(t *testing.T) {
+	for i, d := range []data{
+		{src: "[1,2,]", value: []any{1, 2}},
+		{src: "[1.5 , ]", value: []any{1.5}},
+		{src: "[\"a\",\n]", value: []any{"a"}},
+		{src: "[[],{},]", value: []any{[]any{}, map[string]any{}}},
+		{src: "{\"a\":1,}", value: map[string]any{"a": 1}},
+		{src: "{\"a\":[true,],\"b\":{\"c\":null,},}", value: map[string]any{"a": []any{true}, "b": map[string]any{"c": nil}}},
+		{src: "[,]", expect: "unexpected character ',' at 1:2"},
+		{src: "[1,,]", expect: "unexpected character ',' at 1:4"},
+		{src: "{,}", expect: "expected a string start or object close, not ',' at 1:2"},
+		{src: "1,", expect: "unexpected comma at 1:2"},
+	} {
+		p := oj.Parser{TrailingCommas: true}
+		v, err := p.Parse([]byte(d.src))
+		if 0 < len(d.expect) {
+			tt.NotNil(t, err, i, ": ", d.src)
+			tt.Equal(t, d.expect, err.Error(), i, ": ", d.src)
+			continue
+		}
+		tt.Nil(t, err, i, ": ", d.src)
+		tt.Equal(t, d.value, v, i, ": ", d.src)
+
+		v, err = p.ParseReader(iotest.OneByteReader(strings.NewReader(d.src)))
+		tt.Nil(t, err, i, ": ", d.src)
+		tt.Equal(t, d.value, v, i, ": ", d.src)
+
+		// Strict by default.
+		_, err = oj.Parse([]byte(d.src))
+		tt.NotNil(t, err, i, ": ", d.src)
+	}
+	p := oj.Parser{TrailingCommas: true, Comments: true}
+	v, err := p.Parse([]byte("[1, // one\n]"))
+	tt.Nil(t, err)
+	tt.Equal(t, []any{1}, v)
+}
+
+func TestParserJSON5(t *testing.T) {
+	for i, d := range []data{
+		{src: "{unquoted: 'and you can quote me on that'}", value: map[string]any{"unquoted": "and you can quote me on that"}},
+		{src: "{'single': 1, $dollar_1: 2, true: 3}", value: map[string]any{"single": 1, "$dollar_1": 2, "true": 3}},
+		{src: `'I can use "double quotes" here'`, value: `I can use "double quotes" here`},
+		{src: `"I can use \'single quotes\' here"`, value: `I can use 'single quotes' here`},
+		{src: "'Look, Mom! \\\nNo \\\r\nnewlines!'", value: "Look, Mom! No newlines!"},
+		{src: `'\x41B\t\v\0\q\''`, value: "AB\t\v\x00q'"},
+		{src: `"\x41\v"`, value: "A\v"},
+		{src: `['\uD83D\uDE00', "\uD83D\uDE00"]`, value: []any{"😀", "😀"}},
+		{src: `['\uD83Dx', '\uDE00', '\uD83D\u0041']`, value: []any{"\ufffdx", "\ufffd", "\ufffdA"}},
+		{src: "0xdecaf", value: 0xdecaf},
+		{src: "[-0X10, +0x1F]", value: []any{-16, 31}},
+		{src: ".8675309", value: 0.8675309},
+		{src: "[+1, -.5, 8675309., +.25]", value: []any{1, -0.5, 8675309, 0.25}},
+		{src: "[Infinity,-Infinity, +Infinity]", value: []any{math.Inf(1), math.Inf(-1), math.Inf(1)}},
+		{src: "Infinity", value: math.Inf(1)},
+		{src: "[1,2,]", value: []any{1, 2}},
+		{src: "{a:1,}", value: map[string]any{"a": 1}},
+		{src: "// comment\n{/* x */a/* y */:0x1/* z */}", value: map[string]any{"a": 1}},
+		{src: "\v[1\f,\v2]\f", value: []any{1, 2}},
+		{src: "0x", expect: "invalid number at 1:3"},
+		{src: "[0x]", expect: "invalid number at 1:4"},
+		{src: "0x8000000000000000", expect: "hex number too large at 1:18"},
+		{src: "[Infinite]", expect: "unexpected identifier 'Infinite' at 1:10"},
+		{src: "[.]", expect: "unexpected character ']' at 1:3"},
+		{src: "{1a: 2}", expect: "expected a string start or object close, not '1' at 1:2"},
+		{src: "'abc", expect: "incomplete JSON at 1:5"},
+		{src: "'\x01'", expect: "unexpected character '\x01' at 1:2"},
+	} {
+		p := oj.Parser{JSON5: true}
+		v, err := p.Parse([]byte(d.src))
+		if 0 < len(d.expect) {
+			tt.NotNil(t, err, i, ": ", d.src)
+			tt.Equal(t, d.expect, err.Error(), i, ": ", d.src)
+			continue
+		}
+		tt.Nil(t, err, i, ": ", d.src)
+		tt.Equal(t, d.value, v, i, ": ", d.src)
+
+		v, err = p.ParseReader(iotest.OneByteReader(strings.NewReader(d.src)))
+		tt.Nil(t, err, i, ": ", d.src)
+		tt.Equal(t, d.value, v, i, ": ", d.src)
+	}
+	p := oj.Parser{JSON5: true}
+	v, err := p.Parse([]byte("NaN"))
+	tt.Nil(t, err)
+	tt.Equal(t, true, math.IsNaN(v.(float64)))
+
+	// Strict by default.
+	_, err = oj.Parse([]byte("{a:1}"))
+	tt.NotNil(t, err)
+}
+
+func TestParserPathHook(t *testing.T) {
+	src := `{
+  "a": 1,
+  "debug": {"x": [1, {"y": "]}\"\\"}], "z": null},
+  "b": [{"c": 2, "d": [3, 4]}, {"c": true}],
+  "e": "skip me",
+  "f": -1.5e3
+}`
+	for i, d := range []struct {
+		prefixes []string
+		expect   string
+	}{
+		{prefixes: []string{"$.debug"}, expect: `{"a":1,"b":[{"c":2,"d":[3,4]},{"c":true}],"e":"skip me","f":-1500}`},
+		{prefixes: []string{"$.debug.*"}, expect: `{"a":1,"b":[{"c":2,"d":[3,4]},{"c":true}],"debug":{},"e":"skip me","f":-1500}`},
+		{prefixes: []string{"$.b[0].d", "$.e"}, expect: `{"a":1,"b":[{"c":2},{"c":true}],"debug":{"x":[1,{"y":"]}\"\\"}],"z":null},"f":-1500}`},
+		{prefixes: []string{"$.a", "$.f", "$.b[1].c"}, expect: `{"b":[{"c":2,"d":[3,4]},{}],"debug":{"x":[1,{"y":"]}\"\\"}],"z":null},"e":"skip me"}`},
+		{prefixes: []string{"$.deb"}, expect: `{"a":1,"b":[{"c":2,"d":[3,4]},{"c":true}],"debug":{"x":[1,{"y":"]}\"\\"}],"z":null},"e":"skip me","f":-1500}`},
+	} {
+		p := oj.Parser{PathHook: oj.PathPrefixHook(d.prefixes...)}
+		v, err := p.Parse([]byte(src))
+		tt.Nil(t, err, i)
+		tt.Equal(t, d.expect, oj.JSON(v, &oj.Options{Sort: true}), i)
+
+		v, err = p.ParseReader(iotest.OneByteReader(strings.NewReader(src)))
+		tt.Nil(t, err, i)
+		tt.Equal(t, d.expect, oj.JSON(v, &oj.Options{Sort: true}), i)
+	}
+	var paths []string
+	p := oj.Parser{PathHook: func(path jp.Expr) oj.PathAction {
+		paths = append(paths, path.String())
+		return oj.PathKeep
+	}}
+	_, err := p.Parse([]byte(src))
+	tt.Nil(t, err)
+	tt.Equal(t, []string{
+		"$.a", "$.debug", "$.debug.x", "$.debug.x[1].y", "$.debug.z",
+		"$.b", "$.b[0].c", "$.b[0].d", "$.b[1].c", "$.e", "$.f",
+	}, paths)
+
+	p.PathHook = func(path jp.Expr) oj.PathAction {
+		if len(path) == 2 && path[1] == jp.Child("debug") {
+			return oj.PathReject
+		}
+		return oj.PathKeep
+	}
+	_, err = p.Parse([]byte(src))
+	tt.NotNil(t, err)
+	tt.Equal(t, "$.debug rejected at 3:10", err.Error())
+
+	p.PathHook = oj.PathPrefixHook("$.a")
+	_, err = p.Parse([]byte(`{"a":}`))
+	tt.NotNil(t, err)
+	_, err = p.Parse([]byte(`{"a":[1,2}`))
+	tt.NotNil(t, err)
+}
+
+func TestParserIntern(t *testing.T) {
+	var sc ojg.StringCache
+	p := oj.Parser{InternKeys: true, StringCache: &sc}
+	v1, err := p.Parse([]byte(`{"name":"ann","id":1}`))
+	tt.Nil(t, err)
+	v2, err := p.Parse([]byte(`{"name":"bob","id":2}`))
+	tt.Nil(t, err)
+	tt.Equal(t, 2, sc.Len())
+	tt.Equal(t, `{"id":2,"name":"bob"}`, oj.JSON(v2, &oj.Options{Sort: true}))
+	k1 := keyData(v1.(map[string]any), "name")
+	k2 := keyData(v2.(map[string]any), "name")
+	tt.Equal(t, true, k1 == k2)
+
+	p.InternMaxLen = 3
+	_, err = p.Parse([]byte(`["abc","abcd","abc"]`))
+	tt.Nil(t, err)
+	tt.Equal(t, 3, sc.Len())
+
+	sc.Clear()
+	sc.Max = 1
+	_, err = p.Parse([]byte(`{"a":1,"b":2,"c":3}`))
+	tt.Nil(t, err)
+	tt.Equal(t, 1, sc.Len())
+
+	sc.Clear()
+	sc.Max = 50
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cp := oj.Parser{InternKeys: true, StringCache: &sc}
+			for j := 0; j < 100; j++ {
+				_, _ = cp.Parse([]byte(fmt.Sprintf(`{"k%d":%d}`, j, j)))
+			}
+		}()
+	}
+	wg.Wait()
+	tt.Equal(t, 50, sc.Len())
+}
+
+// keyData returns the address of the bytes of the key in the map.
+func keyData(m map[string]any, key string) *byte {
+	for k := range m {
+		if k == key {
+			return unsafe.StringData(k)
+		}
+	}
+	return nil
+}
+
+func TestParserReset(t *testing.T) {
+	p := oj.Parser{InitBufSize: 8, MaxBufSize: 64, Reuse: true}
+	v, err := p.Parse([]byte(`{"a":[1,2,3]}`))
+	tt.Nil(t, err)
+	tt.Equal(t, `{"a":[1,2,3]}`, oj.JSON(v))
+
+	big := `"` + strings.Repeat(`\u0041`, 100) + `"` // escapes fill the token buffer
+	v, err = p.Parse([]byte(big))
+	tt.Nil(t, err)
+	tt.Equal(t, strings.Repeat("A", 100), v)
+
+	p.Reset()
+	v, err = p.Parse([]byte(`{"b":true}`))
+	tt.Nil(t, err)
+	tt.Equal(t, `{"b":true}`, oj.JSON(v))
+
+	// A failed parse followed by a reset does not leave state behind.
+	_, err = p.Parse([]byte(`[1,{"x":`))
+	tt.NotNil(t, err)
+	p.Reset()
+	v, err = p.ParseReader(strings.NewReader(`[1,2]`))
+	tt.Nil(t, err)
+	tt.Equal(t, `[1,2]`, oj.JSON(v))
+}
+
+func TestParserZeroCopy(t *testing.T) {
+	buf := []byte(`{"key":"value","esc":"a\tb","empty":""}`)
+	p := oj.Parser{ZeroCopy: true}
+	v, err := p.Parse(buf)
+	tt.Nil(t, err)
+	tt.Equal(t, map[string]any{"key": "value", "esc": "a\tb", "empty": ""}, v)
+
+	// Strings without escapes share the input buffer.
+	copy(buf[8:13], "VALUE")
+	tt.Equal(t, map[string]any{"key": "VALUE", "esc": "a\tb", "empty": ""}, v)
+
+	// Without ZeroCopy the strings are copies.
+	buf = []byte(`{"key":"value"}`)
+	p.ZeroCopy = false
+	v, err = p.Parse(buf)
+	tt.Nil(t, err)
+	copy(buf[8:13], "VALUE")
+	tt.Equal(t, map[string]any{"key": "value"}, v)
+}
+
+func TestParserPathRaw(t *testing.T) {
+	p := oj.Parser{PathHook: func(path jp.Expr) oj.PathAction {
+		if path.String() == "$.a" || path.String() == "$.c[0].d" {
+			return oj.PathRaw
+		}
+		return oj.PathKeep
+	}}
+	v, err := p.Parse([]byte(`{"a": {"x":[1, "}"]} , "b":2, "c":[{"d": 1.50e3}]}`))
+	tt.Nil(t, err)
+	tt.Equal(t, json.RawMessage(`{"x":[1, "}"]}`), jp.C("a").First(v))
+	tt.Equal(t, json.RawMessage(`1.50e3`), jp.MustParseString("c[0].d").First(v))
+	tt.Equal(t, int64(2), jp.C("b").First(v))
+}
+
+func TestParserNumberConv(t *testing.T) {
+	p := oj.Parser{NumberConv: func(path jp.Expr, raw string) (any, error) {
+		switch path.String() {
+		case "$.price", "$.items[1]":
+			return "dec:" + raw, nil
+		case "$.bad":
+			return nil, fmt.Errorf("bad number %s", raw)
+		}
+		return nil, nil
+	}}
+	src := `{"price": 10.50, "count": 3, "items": [1.0, -2.50e-1], "big": 12345678901234567890}`
+	expect := map[string]any{
+		"price": "dec:10.50",
+		"count": int64(3),
+		"items": []any{1.0, "dec:-2.50e-1"},
+		"big":   json.Number("12345678901234567890"),
+	}
+	v, err := p.Parse([]byte(src))
+	tt.Nil(t, err)
+	tt.Equal(t, expect, v)
+
+	v, err = p.ParseReader(iotest.OneByteReader(strings.NewReader(src)))
+	tt.Nil(t, err)
+	tt.Equal(t, expect, v)
+
+	v, err = p.Parse([]byte(`12.00`))
+	tt.Nil(t, err)
+	tt.Equal(t, 12.0, v)
+
+	_, err = p.Parse([]byte(`{"bad":1.5}`))
+	tt.ErrorIs(t, err, ojg.ErrInvalidNumber)
+}
+
+func TestParserBlankKeys(t *testing.T) {
+	src := `{"": 1, " \t": 2, "a": 3}`
+	var p oj.Parser
+	v, err := p.Parse([]byte(src))
+	tt.Nil(t, err)
+	tt.Equal(t, map[string]any{"": int64(1), " \t": int64(2), "a": int64(3)}, v)
+
+	p.BlankKeys = ojg.BlankKeyError
+	_, err = p.Parse([]byte(src))
+	tt.ErrorIs(t, err, ojg.ErrBlankKey)
+	_, err = p.Parse([]byte(`{"a\t":{" ":1}}`))
+	tt.ErrorIs(t, err, ojg.ErrBlankKey)
+
+	p.BlankKeys = ojg.BlankKeyRename
+	v, err = p.Parse([]byte(`{"": 1, "a": {"  ": 3}}`))
+	tt.Nil(t, err)
+	tt.Equal(t, map[string]any{"_": int64(1), "a": map[string]any{"_": int64(3)}}, v)
+
+	p.BlankKeyName = "blank"
+	v, err = p.Parse([]byte(`{"": 1}`))
+	tt.Nil(t, err)
+	tt.Equal(t, map[string]any{"blank": int64(1)}, v)
+}
+
+func TestParserUnsafeKeys(t *testing.T) {
+	src := `{"a":1,"__proto__":{"admin":true},"b":{"constructor":{"prototype":[1,{"x":2}]},"c":3}}`
+	p := oj.Parser{UnsafeKeys: ojg.UnsafeKeyStrip}
+	v, err := p.Parse([]byte(src))
+	tt.Nil(t, err)
+	tt.Equal(t, map[string]any{"a": 1, "b": map[string]any{"c": 3}}, v)
+
+	v, err = p.ParseReader(strings.NewReader(src))
+	tt.Nil(t, err)
+	tt.Equal(t, map[string]any{"a": 1, "b": map[string]any{"c": 3}}, v)
+
+	p.UnsafeKeyList = []string{"a"}
+	v, err = p.Parse([]byte(src))
+	tt.Nil(t, err)
+	tt.Equal(t, 2, len(v.(map[string]any)))
+
+	p = oj.Parser{UnsafeKeys: ojg.UnsafeKeyError}
+	_, err = p.Parse([]byte(src))
+	tt.Equal(t, true, errors.Is(err, ojg.ErrUnsafeKey))
+
+	p = oj.Parser{}
+	v, err = p.Parse([]byte(`{"__proto__":1}`))
+	tt.Nil(t, err)
+	tt.Equal(t, map[string]any{"__proto__": 1}, v)
+}
+
+func TestParserInvalidUTF8(t *testing.T) {
+	src := "\xef\xbb\xbf{\"k\xff\":\"a\xc3b\",\"e\":\"\\u00e9\xfe\"}"
+
+	var p oj.Parser
+	v, err := p.Parse([]byte(src))
+	tt.Nil(t, err)
+	tt.Equal(t, map[string]any{"k\xff": "a\xc3b", "e": "\u00e9\xfe"}, v)
+
+	p.InvalidUTF8 = ojg.UTF8Replace
+	for _, zero := range []bool{false, true} {
+		p.ZeroCopy = zero
+		v, err = p.Parse([]byte(src))
+		tt.Nil(t, err)
+		tt.Equal(t, map[string]any{"k\ufffd": "a\ufffdb", "e": "\u00e9\ufffd"}, v)
+	}
+	p.ZeroCopy = false
+	v, err = p.ParseReader(strings.NewReader(src))
+	tt.Nil(t, err)
+	tt.Equal(t, map[string]any{"k\ufffd": "a\ufffdb", "e": "\u00e9\ufffd"}, v)
+
+	p.InvalidUTF8 = ojg.UTF8Reject
+	_, err = p.Parse([]byte(src))
+	tt.NotNil(t, err)
+	tt.Equal(t, true, errors.Is(err, ojg.ErrInvalidString))
+	_, err = p.ParseReader(strings.NewReader(src))
+	tt.Equal(t, true, errors.Is(err, ojg.ErrInvalidString))
+
+	v, err = p.Parse([]byte("\xef\xbb\xbf[\"ok \u00e9\"]"))
+	tt.Nil(t, err)
+	tt.Equal(t, []any{"ok \u00e9"}, v)
+}
@@ -0,0 +1,561 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj
+
+import (
+	"reflect"
+	"unsafe"
+
+	"github.com/ohler55/ojg/alt"
+	"github.com/ohler55/ojg/jp"
+)
+
+// pathState is the progress of the Include and Exclude expressions at the
+// value being written.
+type pathState struct {
+	inc      [][]int
+	exc      [][]int
+	included bool // matched by an Include or there are no Include expressions
+	whole    bool // included and no Exclude can match so written without checks
+	kept     int  // members written
+}
+
+// view returns the data to write after starting the Include and Exclude
+// expressions at the top level. If the top level value is excluded nil is
+// returned.
+func (wr *Writer) view(data any) any {
+	if !wr.filtering {
+		return data
+	}
+	wr.holds = 0
+	wr.path = pathState{
+		inc:      make([][]int, len(wr.Include)),
+		exc:      make([][]int, len(wr.Exclude)),
+		included: len(wr.Include) == 0,
+	}
+	for i, x := range wr.Include {
+		wr.path.inc[i] = startPositions(x)
+	}
+	for i, x := range wr.Exclude {
+		wr.path.exc[i] = startPositions(x)
+	}
+	if !wr.pathCheck(&wr.path) {
+		return nil
+	}
+	return data
+}
+
+// pathCheck returns false if the value at the state is not written because
+// it is excluded or because neither it nor any value in it can be included.
+func (wr *Writer) pathCheck(ps *pathState) bool {
+	for i, p := range ps.exc {
+		if fullMatch(wr.Exclude[i], p) {
+			return false
+		}
+	}
+	alive := false
+	for i, p := range ps.inc {
+		if fullMatch(wr.Include[i], p) {
+			ps.included = true
+		}
+		alive = alive || 0 < len(p)
+	}
+	excAlive := false
+	for _, p := range ps.exc {
+		excAlive = excAlive || 0 < len(p)
+	}
+	ps.whole = ps.included && !excAlive
+
+	return ps.included || alive
+}
+
+// pathEnter steps the Include and Exclude expressions into a member of the
+// array or object being written. False is returned if the member is not
+// written. Otherwise the state of the array or object is returned to be
+// restored by pathLeave once the member has been written.
+func (wr *Writer) pathEnter(key string, index, size int, member any) (pathState, bool) {
+	parent := wr.path
+	if parent.whole {
+		return parent, true
+	}
+	ps := pathState{
+		inc:      stepAll(wr.Include, parent.inc, key, index, size, member),
+		exc:      stepAll(wr.Exclude, parent.exc, key, index, size, member),
+		included: parent.included,
+	}
+	if !wr.pathCheck(&ps) {
+		return parent, false
+	}
+	if !ps.included {
+		// Nothing is known to be written yet so the buffer must not be
+		// flushed until pathLeave decides.
+		wr.holds++
+	}
+	wr.path = ps
+
+	return parent, true
+}
+
+// pathLeave restores the state of the array or object being written after
+// a member that starts at mark in the buffer. If the member was not written
+// or nothing in it was included the buffer is cut back to mark and false is
+// returned.
+func (wr *Writer) pathLeave(parent pathState, mark int, wrote bool) bool {
+	ps := wr.path
+	wr.path = parent
+	if !ps.included {
+		wr.holds--
+		wrote = wrote && 0 < ps.kept
+	}
+	if !wrote {
+		wr.buf = wr.buf[:mark]
+		return false
+	}
+	wr.path.kept++
+
+	return true
+}
+
+// indents returns the indentation before the close of an array or object
+// and before each member, both starting with a newline, or empty strings if
+// not indenting.
+func (wr *Writer) indents(depth int) (is, cs string) {
+	if wr.Tab {
+		is = tabs[0:min(depth+1, len(tabs))]
+		cs = tabs[0:min(depth+2, len(tabs))]
+	} else if 0 < wr.Indent {
+		is = spaces[0:min(depth*wr.Indent+1, len(spaces))]
+		cs = spaces[0:min((depth+1)*wr.Indent+1, len(spaces))]
+	}
+	return
+}
+
+// pathArray writes the members of an array selected by the Include and
+// Exclude options.
+func pathArray(wr *Writer, n []any, depth int) {
+	is, cs := wr.indents(depth)
+	empty := true
+	wr.buf = append(wr.buf, '[')
+	for i, m := range n {
+		parent, ok := wr.pathEnter("", i, len(n), m)
+		if !ok {
+			continue
+		}
+		mark := len(wr.buf)
+		if !empty {
+			wr.buf = append(wr.buf, ',')
+		}
+		wr.buf = append(wr.buf, cs...)
+		wr.appendJSON(m, depth+1)
+		if wr.pathLeave(parent, mark, true) {
+			empty = false
+		}
+	}
+	if !empty {
+		wr.buf = append(wr.buf, is...)
+	}
+	wr.buf = append(wr.buf, ']')
+}
+
+// pathObject writes the members of an object selected by the Include and
+// Exclude options.
+func pathObject(wr *Writer, n map[string]any, depth int) {
+	var keys []string
+	if wr.sorting {
+		keys = wr.sortedKeys(n)
+	} else {
+		keys = make([]string, 0, len(n))
+		for k := range n {
+			keys = append(keys, k)
+		}
+	}
+	is, cs := wr.indents(depth)
+	empty := true
+	wr.buf = append(wr.buf, '{')
+	for _, k := range keys {
+		m := n[k]
+		if wr.redactKey(k) {
+			m = wr.redactMask
+		}
+		if wr.omitMember(m) {
+			continue
+		}
+		parent, ok := wr.pathEnter(k, -1, 0, n[k])
+		if !ok {
+			continue
+		}
+		mark := len(wr.buf)
+		if !empty {
+			wr.buf = append(wr.buf, ',')
+		}
+		wr.buf = append(wr.buf, cs...)
+		wr.buf = wr.appendString(wr.buf, wr.keyFor(k), !wr.HTMLUnsafe)
+		wr.buf = append(wr.buf, ':')
+		if 0 < len(cs) {
+			wr.buf = append(wr.buf, ' ')
+		}
+		wr.appendJSON(m, depth+1)
+		if wr.pathLeave(parent, mark, true) {
+			empty = false
+		}
+	}
+	if !empty {
+		wr.buf = append(wr.buf, is...)
+	}
+	wr.buf = append(wr.buf, '}')
+}
+
+// pathStruct writes the fields of a struct selected by the Include and
+// Exclude options. Fields are written in the same order and with the same
+// tag options as when not filtering.
+func (wr *Writer) pathStruct(rv reflect.Value, depth int, si *sinfo) {
+	is, cs := wr.indents(depth)
+	empty := true
+	wr.buf = append(wr.buf, '{')
+	if 0 < len(wr.CreateKey) {
+		name := si.rt.Name()
+		if wr.FullTypePath {
+			name = si.rt.PkgPath() + "/" + name
+		}
+		if parent, ok := wr.pathEnter(wr.CreateKey, -1, 0, name); ok {
+			mark := len(wr.buf)
+			wr.buf = append(wr.buf, cs...)
+			wr.buf = wr.appendString(wr.buf, wr.CreateKey, !wr.HTMLUnsafe)
+			wr.buf = append(wr.buf, ':')
+			if 0 < len(cs) {
+				wr.buf = append(wr.buf, ' ')
+			}
+			wr.buf = wr.appendString(wr.buf, name, !wr.HTMLUnsafe)
+			empty = !wr.pathLeave(parent, mark, true)
+		}
+	}
+	var addr uintptr
+	if rv.CanAddr() {
+		addr = rv.UnsafeAddr()
+	}
+	for _, fi := range wr.structFields(si) {
+		if (fi.skippable || wr.skipFields) && wr.skipField(fi, rv) {
+			continue
+		}
+		parent, ok := wr.pathEnter(fi.key, -1, 0, fieldMember(fi, rv))
+		if !ok {
+			continue
+		}
+		mark := len(wr.buf)
+		if !empty {
+			wr.buf = append(wr.buf, ',')
+		}
+		wr.buf = append(wr.buf, cs...)
+		if wr.cycles {
+			wr.stepField(fi)
+		}
+		if wr.pathLeave(parent, mark, wr.appendField(fi, rv, addr, depth+1)) {
+			empty = false
+		}
+	}
+	if !empty {
+		wr.buf = append(wr.buf, is...)
+	}
+	wr.buf = append(wr.buf, '}')
+}
+
+// fieldMember returns the value of a struct field to match against the
+// Include and Exclude expressions.
+func fieldMember(fi *finfo, rv reflect.Value) (member any) {
+	if fv, err := rv.FieldByIndexErr(fi.index); err == nil && fv.CanInterface() {
+		member = fv.Interface()
+	}
+	return
+}
+
+// appendField writes the key and value of a struct field. False is returned
+// if the field is omitted.
+func (wr *Writer) appendField(fi *finfo, rv reflect.Value, addr uintptr, depth int) bool {
+	var v any
+	var stat appendStatus
+	switch {
+	case fi.redact || wr.redactKey(fi.key):
+		wr.buf = append(wr.buf, fi.jkey...)
+		v, stat = wr.redactMask, aChanged
+	case wr.slowField && fi.sAppend != nil:
+		wr.buf, v, stat = fi.sAppend(fi, wr.buf, rv, addr, !wr.HTMLUnsafe)
+	case 0 < addr:
+		wr.buf, v, stat = fi.Append(fi, wr.buf, rv, addr, !wr.HTMLUnsafe)
+	default:
+		wr.buf, v, stat = fi.iAppend(fi, wr.buf, rv, addr, !wr.HTMLUnsafe)
+	}
+	switch stat {
+	case aWrote:
+		return true
+	case aSkip:
+		return false
+	case aChanged:
+		if wr.OmitNil && (*[2]uintptr)(unsafe.Pointer(&v))[1] == 0 {
+			return false
+		}
+	default:
+		if (fi.kind == reflect.Ptr || fi.kind == reflect.Interface) && (*[2]uintptr)(unsafe.Pointer(&v))[1] == 0 {
+			if wr.OmitNil {
+				return false
+			}
+			wr.buf = append(wr.buf, "null"...)
+			return true
+		}
+	}
+	wr.appendJSON(v, depth)
+
+	return true
+}
+
+// pathSlice writes the elements of a reflected slice or array selected by
+// the Include and Exclude options.
+func (wr *Writer) pathSlice(rv reflect.Value, depth int) {
+	is, cs := wr.indents(depth)
+	end := rv.Len()
+	empty := true
+	wr.buf = append(wr.buf, '[')
+	mk := marshalKind(rv.Type().Elem())
+	for j := 0; j < end; j++ {
+		rm := rv.Index(j)
+		parent, ok := wr.pathEnter("", j, end, rm.Interface())
+		if !ok {
+			continue
+		}
+		mark := len(wr.buf)
+		if !empty {
+			wr.buf = append(wr.buf, ',')
+		}
+		wr.buf = append(wr.buf, cs...)
+		if wr.cycles {
+			wr.stepIndex(j)
+		}
+		if m := reflectMarshaler(rm, mk); m != nil {
+			wr.buf = appendMarshaled(wr.buf, m)
+		} else {
+			wr.appendJSON(rm.Interface(), depth+1)
+		}
+		if wr.pathLeave(parent, mark, true) {
+			empty = false
+		}
+	}
+	if !empty {
+		wr.buf = append(wr.buf, is...)
+	}
+	wr.buf = append(wr.buf, ']')
+}
+
+// pathMap writes the members of a reflected map selected by the Include and
+// Exclude options.
+func (wr *Writer) pathMap(rv reflect.Value, depth int) {
+	is, cs := wr.indents(depth)
+	keys, names := alt.MapKeys(rv, wr.sorting)
+	empty := true
+	wr.buf = append(wr.buf, '{')
+	vk := marshalKind(rv.Type().Elem())
+	for i, kv := range keys {
+		var key string
+		if names == nil {
+			key = kv.String()
+		} else {
+			key = names[i]
+		}
+		member := rv.MapIndex(kv)
+		rm := member
+		mk := vk
+		if wr.redactKey(key) {
+			rm = reflect.ValueOf(wr.redactMask)
+			mk = marshalNone
+		}
+		if wr.omitValue(rm) {
+			continue
+		}
+		parent, ok := wr.pathEnter(key, -1, 0, member.Interface())
+		if !ok {
+			continue
+		}
+		mark := len(wr.buf)
+		if !empty {
+			wr.buf = append(wr.buf, ',')
+		}
+		wr.buf = append(wr.buf, cs...)
+		wr.buf = wr.appendString(wr.buf, wr.keyFor(key), !wr.HTMLUnsafe)
+		wr.buf = append(wr.buf, ':')
+		if 0 < len(cs) {
+			wr.buf = append(wr.buf, ' ')
+		}
+		if wr.cycles {
+			wr.stepKey(key, member)
+		}
+		if m := reflectMarshaler(rm, mk); m != nil {
+			wr.buf = appendMarshaled(wr.buf, m)
+		} else {
+			wr.appendJSON(rm.Interface(), depth+1)
+		}
+		if wr.pathLeave(parent, mark, true) {
+			empty = false
+		}
+	}
+	if !empty {
+		wr.buf = append(wr.buf, is...)
+	}
+	wr.buf = append(wr.buf, '}')
+}
+
+// omitValue returns true if a reflected map member should be skipped
+// because of the OmitNil or OmitEmpty options.
+func (wr *Writer) omitValue(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return wr.OmitNil && rv.IsNil()
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return (wr.OmitNil || wr.OmitEmpty) && rv.Len() == 0
+	case reflect.String:
+		return wr.OmitEmpty && rv.Len() == 0
+	}
+	return false
+}
+
+// startPositions returns the positions in the expression that can match
+// the root of the data.
+func startPositions(x jp.Expr) []int {
+	pos := 0
+	if 0 < len(x) {
+		switch x[0].(type) {
+		case jp.Root, jp.At:
+			pos = 1
+		}
+	}
+	return closePositions(x, []int{pos})
+}
+
+// closePositions adds the positions that can be reached without stepping
+// into a child such as the fragment after a descent or a bracket.
+func closePositions(x jp.Expr, ps []int) []int {
+	for i := 0; i < len(ps); i++ {
+		p := ps[i]
+		if len(x) <= p {
+			continue
+		}
+		switch x[p].(type) {
+		case jp.Bracket, jp.Descent:
+			if !hasPosition(ps, p+1) {
+				ps = append(ps, p+1)
+			}
+		}
+	}
+	return ps
+}
+
+func hasPosition(ps []int, p int) bool {
+	for _, v := range ps {
+		if v == p {
+			return true
+		}
+	}
+	return false
+}
+
+func fullMatch(x jp.Expr, ps []int) bool {
+	return hasPosition(ps, len(x))
+}
+
+func stepAll(xs []jp.Expr, states [][]int, key string, index, size int, child any) [][]int {
+	next := make([][]int, len(xs))
+	for i, x := range xs {
+		next[i] = stepPositions(x, states[i], key, index, size, child)
+	}
+	return next
+}
+
+// stepPositions returns the positions reached by stepping into a child
+// identified by a key or, if index is not negative, by an index.
+func stepPositions(x jp.Expr, ps []int, key string, index, size int, child any) (next []int) {
+	add := func(p int) {
+		if !hasPosition(next, p) {
+			next = append(next, p)
+		}
+	}
+	for _, p := range ps {
+		if len(x) <= p {
+			continue
+		}
+		switch f := x[p].(type) {
+		case jp.Descent:
+			add(p)
+		case jp.Wildcard:
+			add(p + 1)
+		case jp.Child:
+			if index < 0 && string(f) == key {
+				add(p + 1)
+			}
+		case jp.Nth:
+			if 0 <= index && normIndex(int(f), size) == index {
+				add(p + 1)
+			}
+		case jp.Union:
+			for _, u := range f {
+				switch tu := u.(type) {
+				case string:
+					if index < 0 && tu == key {
+						add(p + 1)
+					}
+				case int64:
+					if 0 <= index && normIndex(int(tu), size) == index {
+						add(p + 1)
+					}
+				}
+			}
+		case jp.Slice:
+			if 0 <= index && inSlice(f, index, size) {
+				add(p + 1)
+			}
+		case *jp.Filter:
+			if f.Match(child) {
+				add(p + 1)
+			}
+		}
+	}
+	return closePositions(x, next)
+}
+
+func normIndex(i, size int) int {
+	if i < 0 {
+		i += size
+	}
+	return i
+}
+
+// inSlice returns true if the index is selected by the slice using the
+// same bounds as jp.Expr.Get().
+func inSlice(f jp.Slice, index, size int) bool {
+	start := 0
+	end := size
+	step := 1
+	if 0 < len(f) {
+		start = f[0]
+	}
+	if 1 < len(f) {
+		end = f[1]
+	}
+	if 2 < len(f) {
+		step = f[2]
+	}
+	if start < 0 {
+		start = max(size+start, 0)
+	}
+	if end < 0 {
+		end += size
+	}
+	if size <= start {
+		return false
+	}
+	end = min(end, size)
+	switch {
+	case 0 < step:
+		return start <= index && index < end && (index-start)%step == 0
+	case step < 0:
+		end = max(end, -1)
+		return end < index && index <= start && (start-index)%(-step) == 0
+	}
+	return false
+}
@@ -0,0 +1,191 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/alt"
+	"github.com/ohler55/ojg/jp"
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/tt"
+)
+
+func TestWriterIncludeExclude(t *testing.T) {
+	data := map[string]any{
+		"user": map[string]any{"name": "ann", "password": "secret", "tags": []any{"a", "b", "c"}},
+		"items": []any{
+			map[string]any{"id": 1, "price": 3.5, "token": "x"},
+			map[string]any{"id": 2, "price": 7, "token": "y"},
+		},
+		"count": 2,
+	}
+	for _, td := range []struct {
+		include string
+		exclude string
+		expect  string
+	}{
+		{include: "$.user.name", expect: `{"user":{"name":"ann"}}`},
+		{include: "$.items[*].id,$.count", expect: `{"count":2,"items":[{"id":1},{"id":2}]}`},
+		{include: "$.items[-1]", expect: `{"items":[{"id":2,"price":7,"token":"y"}]}`},
+		{include: "$.user.tags[1:]", expect: `{"user":{"tags":["b","c"]}}`},
+		{include: "$.items[?(@.price > 5)].id", expect: `{"items":[{"id":2}]}`},
+		{include: "$..price", expect: `{"items":[{"price":3.5},{"price":7}]}`},
+		{include: "$.nothing", expect: `{}`},
+		{exclude: "$..password,$..token", expect: `{"count":2,"items":[{"id":1,"price":3.5},{"id":2,"price":7}],"user":{"name":"ann","tags":["a","b","c"]}}`},
+		{exclude: "$.user.tags[0]", expect: `{"count":2,"items":[{"id":1,"price":3.5,"token":"x"},{"id":2,"price":7,"token":"y"}],"user":{"name":"ann","password":"secret","tags":["b","c"]}}`},
+		{include: "$.user", exclude: "$.user.password", expect: `{"user":{"name":"ann","tags":["a","b","c"]}}`},
+	} {
+		wr := oj.Writer{Options: oj.Options{Sort: true}}
+		if 0 < len(td.include) {
+			for _, s := range strings.Split(td.include, ",") {
+				wr.Include = append(wr.Include, jp.MustParseString(s))
+			}
+		}
+		if 0 < len(td.exclude) {
+			for _, s := range strings.Split(td.exclude, ",") {
+				wr.Exclude = append(wr.Exclude, jp.MustParseString(s))
+			}
+		}
+		tt.Equal(t, td.expect, wr.JSON(data), td.include, " - ", td.exclude)
+	}
+	// The original data is not modified.
+	tt.Equal(t, "secret", jp.C("user").C("password").First(data))
+}
+
+func TestWriterExcludeStruct(t *testing.T) {
+	type Account struct {
+		Name     string
+		Password string
+	}
+	wr := oj.Writer{Options: oj.Options{Sort: true}, Exclude: []jp.Expr{jp.MustParseString("$..password")}}
+	var b strings.Builder
+	tt.Nil(t, wr.Write(&b, []any{&Account{Name: "ann", Password: "x"}}))
+	tt.Equal(t, `[{"name":"ann"}]`, b.String())
+
+	b.Reset()
+	tt.Nil(t, wr.WriteMany(&b, []any{&Account{Name: "a", Password: "x"}, &Account{Name: "b", Password: "y"}}))
+	tt.Equal(t, "{\"name\":\"a\"}\n{\"name\":\"b\"}\n", b.String())
+}
+
+func TestWriterIncludeStructTags(t *testing.T) {
+	type Item struct {
+		Zebra  string  `json:"zebra"`
+		Amount string  `json:"amount" ojg:"asnumber"`
+		Skip   string  `json:"skip,omitempty"`
+		Alpha  []int64 `json:"alpha"`
+	}
+	type Order struct {
+		ID    int64   `json:"id,string"`
+		Items []*Item `json:"items"`
+		Note  string  `json:"note"`
+	}
+	order := &Order{
+		ID: 7,
+		Items: []*Item{
+			{Zebra: "z1", Amount: "12.50", Alpha: []int64{1, 2}},
+			{Zebra: "z2", Amount: "3", Skip: "s"},
+		},
+		Note: "n",
+	}
+	wr := oj.Writer{Options: oj.Options{UseTags: true, FieldOrder: ojg.FieldOrderDeclared}}
+	wr.Include = []jp.Expr{jp.MustParseString("$.items[*].amount"), jp.MustParseString("$.id")}
+	tt.Equal(t, `{"id":"7","items":[{"amount":12.50},{"amount":3}]}`, string(wr.MustJSON(order)))
+
+	wr.Include = nil
+	wr.Exclude = []jp.Expr{jp.MustParseString("$..alpha"), jp.MustParseString("$.note")}
+	tt.Equal(t, `{"id":"7","items":[{"zebra":"z1","amount":12.50},{"zebra":"z2","amount":3,"skip":"s"}]}`, string(wr.MustJSON(order)))
+
+	wr.Options.Indent = 2
+	tt.Equal(t, `{
+  "id": "7",
+  "items": [
+    {
+      "zebra": "z1",
+      "amount": 12.50
+    },
+    {
+      "zebra": "z2",
+      "amount": 3,
+      "skip": "s"
+    }
+  ]
+}`, string(wr.MustJSON(order)))
+
+	// Members that turn out to have nothing included are removed from the
+	// buffer so nothing is flushed until they are decided.
+	wr = oj.Writer{Options: oj.Options{UseTags: true, FieldOrder: ojg.FieldOrderDeclared, WriteLimit: 1}}
+	wr.Include = []jp.Expr{jp.MustParseString("$..zebra")}
+	var b strings.Builder
+	tt.Nil(t, wr.Write(&b, []any{order, order}))
+	tt.Equal(t, `[{"items":[{"zebra":"z1"},{"zebra":"z2"}]},{"items":[{"zebra":"z1"},{"zebra":"z2"}]}]`, b.String())
+}
+
+func TestWriterRedact(t *testing.T) {
+	type login struct {
+		User     string
+		Password string `json:"password,redact"`
+		Token    string `json:"auth_token"`
+	}
+	data := map[string]any{
+		"user":     &login{User: "ann", Password: "secret", Token: "abc"},
+		"Password": "pw",
+		"refresh_token": map[string]any{
+			"nested": true,
+		},
+		"creds": map[string]string{"api_token": "xyz", "name": "key"},
+		"count": 3,
+	}
+	wr := oj.Writer{Options: oj.Options{Sort: true, UseTags: true}}
+	tt.Equal(t, `{"Password":"pw","count":3,"creds":{"api_token":"xyz","name":"key"},"refresh_token":{"nested":true},"user":{"User":"ann","auth_token":"abc","password":"***"}}`,
+		wr.JSON(data))
+
+	wr.Redact = &alt.Redactor{Keys: []string{"password", "*_token"}}
+	expect := `{"Password":"***","count":3,"creds":{"api_token":"***","name":"key"},"refresh_token":"***","user":{"User":"ann","auth_token":"***","password":"***"}}`
+	tt.Equal(t, expect, wr.JSON(data))
+
+	wr.Indent = 2
+	tt.Equal(t, expect, oj.JSON(oj.MustParseString(wr.JSON(data)), &oj.Options{Sort: true}))
+
+	wr.Indent = 0
+	wr.Redact.Mask = "[hidden]"
+	tt.Equal(t, `{"User":"ann","auth_token":"[hidden]","password":"[hidden]"}`,
+		wr.JSON(&login{User: "ann", Password: "secret", Token: "abc"}))
+}
+
+func TestWriterRedactTagWithOptions(t *testing.T) {
+	type login struct {
+		User     string `json:"user"`
+		Password string `json:"password,redact"`
+	}
+	type account struct {
+		ID    int    `json:"id"`
+		Login *login `json:"login"`
+	}
+	data := []any{&account{ID: 1, Login: &login{User: "ann", Password: "secret"}}}
+
+	wr := oj.Writer{Options: oj.Options{UseTags: true, MaxWriteDepth: 3}}
+	tt.Equal(t, `[{"id":1,"login":{"password":"***","user":"ann"}}]`, wr.JSON(data))
+
+	wr.MaxWriteDepth = 0
+	wr.Include = []jp.Expr{jp.MustParseString("$..password")}
+	tt.Equal(t, `[{"login":{"password":"***"}}]`, wr.JSON(data))
+
+	wr.Include = nil
+	wr.Exclude = []jp.Expr{jp.MustParseString("$..user")}
+	tt.Equal(t, `[{"id":1,"login":{"password":"***"}}]`, wr.JSON(data))
+
+	wr.Exclude = nil
+	for _, opt := range []*oj.Options{
+		{UseTags: true, Color: true},
+		{UseTags: true, Color: true, Indent: 2, MaxWriteDepth: 3},
+	} {
+		wr = oj.Writer{Options: *opt, Include: []jp.Expr{jp.MustParseString("$[*].login")}}
+		out := wr.JSON(data)
+		tt.Equal(t, false, strings.Contains(out, "secret"), out)
+		tt.Equal(t, true, strings.Contains(out, `"***"`), out)
+		tt.Equal(t, false, strings.Contains(out, `"id"`), out)
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj
+
+import (
+	"strings"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/gen"
+	"github.com/ohler55/ojg/jp"
+)
+
+// PathAction is returned by a Parser PathHook to indicate how the value of
+// an object member should be handled.
+type PathAction byte
+
+const (
+	// PathKeep indicates the value should be parsed as usual.
+	PathKeep = PathAction(0)
+
+	// PathSkip indicates the value should be skipped. A skipped value is
+	// scanned but not built so no memory is allocated for it. The scan
+	// matches brackets and quotes but does not otherwise validate the value.
+	PathSkip = PathAction('s')
+
+	// PathReject indicates the parse should stop with an error.
+	PathReject = PathAction('r')
+
+	// PathRaw indicates the value should be kept as the raw JSON bytes of
+	// the value in a json.RawMessage. As with PathSkip the value is scanned
+	// but not validated.
+	PathRaw = PathAction('w')
+
+	skipValue = 'V'
+
+	//   0123456789abcdef0123456789abcdef
+	skipMap = "" +
+		"VVVVVVVVVVVVVVVVVVVVVVVVVVVVVVVV" + // 0x00
+		"VVVVVVVVVVVVVVVVVVVVVVVVVVVVVVVV" + // 0x20
+		"VVVVVVVVVVVVVVVVVVVVVVVVVVVVVVVV" + // 0x40
+		"VVVVVVVVVVVVVVVVVVVVVVVVVVVVVVVV" + // 0x60
+		"VVVVVVVVVVVVVVVVVVVVVVVVVVVVVVVV" + // 0x80
+		"VVVVVVVVVVVVVVVVVVVVVVVVVVVVVVVV" + // 0xa0
+		"VVVVVVVVVVVVVVVVVVVVVVVVVVVVVVVV" + // 0xc0
+		"VVVVVVVVVVVVVVVVVVVVVVVVVVVVVVVV" //   0xe0
+)
+
+// NumberConvFunc converts the raw text of a number at the path to a value.
+// It is the type of the Parser NumberConv field.
+type NumberConvFunc func(path jp.Expr, raw string) (any, error)
+
+// PathPrefixHook returns a PathHook that skips the values of object members
+// with a path that starts with any of the prefixes. The prefixes are in
+// normalized form such as "$.debug" or "$.items[2].meta". A prefix that ends
+// with ".*" skips all members of the prefix but not the prefix value itself
+// so "$.debug.*" skips the members of debug but leaves an empty debug
+// object.
+func PathPrefixHook(prefixes ...string) func(path jp.Expr) PathAction {
+	return func(path jp.Expr) PathAction {
+		ps := path.String()
+		for _, prefix := range prefixes {
+			members := strings.HasSuffix(prefix, ".*")
+			if members {
+				prefix = prefix[:len(prefix)-2]
+			}
+			if !strings.HasPrefix(ps, prefix) {
+				continue
+			}
+			if len(ps) == len(prefix) {
+				if !members {
+					return PathSkip
+				}
+			} else if ps[len(prefix)] == '.' || ps[len(prefix)] == '[' {
+				return PathSkip
+			}
+		}
+		return PathKeep
+	}
+}
+
+// keyPath returns the path to the object member value about to be parsed.
+// The same slice is reused for each call.
+func (p *Parser) keyPath() jp.Expr {
+	p.path = p.path[:0]
+	end := len(p.stack)
+	for d := len(p.starts) - 1; 0 <= d; d-- {
+		if s := p.starts[d]; 0 <= s {
+			// An array is followed by the elements already parsed.
+			p.path = append(p.path, jp.Nth(end-s-1))
+			end = s
+		} else {
+			// An object is followed by the key of the member being parsed.
+			end -= 2
+			k, _ := p.stack[end+1].(gen.Key)
+			p.path = append(p.path, jp.Child(k))
+		}
+	}
+	p.path = append(p.path, jp.Root('$'))
+	for i, j := 0, len(p.path)-1; i < j; i, j = i+1, j-1 {
+		p.path[i], p.path[j] = p.path[j], p.path[i]
+	}
+	return p.path
+}
+
+// skipValue scans past a value that is being skipped. The returned offset
+// is the last byte of the value and done is true if the end of the value
+// was reached.
+func (p *Parser) skipValue(buf []byte, off int) (int, bool, error) {
+	for ; off < len(buf); off++ {
+		b := buf[off]
+		if p.sstr {
+			switch {
+			case p.sesc:
+				p.sesc = false
+			case b == '\\':
+				p.sesc = true
+			case b == '"':
+				p.sstr = false
+				if p.sdepth == 0 {
+					return off, true, nil
+				}
+			}
+			continue
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n', ',', ']', '}':
+			if p.sdepth == 0 {
+				if p.sval { // end of a number or token
+					return off - 1, true, nil
+				}
+				if b == ',' || b == ']' || b == '}' {
+					return off, false, p.newError(off, ojg.ErrUnexpectedChar, "expected a value")
+				}
+			}
+			switch b {
+			case '\n':
+				p.line++
+				p.noff = off
+			case ']', '}':
+				p.sdepth--
+				if p.sdepth == 0 {
+					return off, true, nil
+				}
+			}
+		case '"':
+			p.sval = true
+			p.sstr = true
+		case '[', '{':
+			p.sval = true
+			p.sdepth++
+		default:
+			p.sval = true
+		}
+	}
+	return off, false, nil
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj
+
+import "github.com/ohler55/ojg"
+
+// maxPooledBuf is the largest Writer buffer capacity kept when a Writer is
+// returned to the pool. Larger buffers are dropped so a single large
+// document does not pin memory.
+const maxPooledBuf = 1 << 20
+
+// GetWriter returns a Writer from a package level pool with the options
+// provided or the DefaultOptions if opts is nil. The Writer should be
+// returned with PutWriter when no longer needed so the Writer and its
+// buffer can be reused. Since the buffer is reused any []byte returned from
+// MustJSON must be copied before the Writer is returned.
+func GetWriter(opts *Options) *Writer {
+	wr, _ := writerPool.Get().(*Writer)
+	if opts != nil {
+		wr.Options = *opts
+	}
+	return wr
+}
+
+// PutWriter resets all the settings and state of a Writer to the defaults
+// and returns it to the pool used by GetWriter. Only the buffer is kept.
+// The Writer must not be used after it has been returned.
+func PutWriter(wr *Writer) {
+	if wr == nil {
+		return
+	}
+	buf := wr.buf[:0]
+	if maxPooledBuf < cap(buf) {
+		buf = make([]byte, 0, 1024)
+	}
+	*wr = Writer{Options: DefaultOptions, buf: buf}
+	writerPool.Put(wr)
+}
+
+// GetParser returns a Parser from a package level pool. The Parser has the
+// default settings. It should be returned with PutParser when no longer
+// needed.
+func GetParser() *Parser {
+	return parserPool.Get().(*Parser)
+}
+
+// PutParser resets all the settings and state of a Parser to the defaults
+// and returns it to the pool used by GetParser. Only the buffers are kept.
+// The Parser must not be used after it has been returned.
+func PutParser(p *Parser) {
+	if p == nil {
+		return
+	}
+	tmp := p.tmp[:0]
+	if p.Allocator != nil {
+		// The buffer belongs to the allocator.
+		ojg.Free(p.Allocator, p.tmp)
+		tmp = nil
+	}
+	clear(p.stack[:cap(p.stack)])
+	clear(p.maps)
+	*p = Parser{
+		tmp:       tmp,
+		runeBytes: p.runeBytes,
+		stack:     p.stack[:0],
+		starts:    p.starts[:0],
+		maps:      p.maps[:0],
+		path:      p.path[:0],
+	}
+	parserPool.Put(p)
+}
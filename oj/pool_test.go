@@ -0,0 +1,80 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj_test
+
+import (
+	"testing"
+
+	"github.com/ohler55/ojg/alt"
+	"github.com/ohler55/ojg/jp"
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/tt"
+)
+
+func TestWriterPool(t *testing.T) {
+	wr := oj.GetWriter(&oj.Options{Indent: 2, Sort: true})
+	tt.Equal(t, "{\n  \"a\": 1,\n  \"b\": 2\n}", wr.JSON(map[string]any{"b": 2, "a": 1}))
+	oj.PutWriter(wr)
+
+	wr = oj.GetWriter(nil)
+	tt.Equal(t, 0, wr.Indent)
+	tt.Equal(t, `[1,2]`, wr.JSON([]any{1, 2}))
+	oj.PutWriter(wr)
+	oj.PutWriter(nil)
+
+	wr = oj.GetWriter(nil)
+	wr.Include = []jp.Expr{jp.C("a")}
+	wr.Redact = &alt.Redactor{Keys: []string{"a"}}
+	tt.Equal(t, `{"a":"***"}`, wr.JSON(map[string]any{"a": 1, "b": 2}))
+	oj.PutWriter(wr)
+
+	wr = oj.GetWriter(&oj.Options{Sort: true})
+	tt.Equal(t, `{"a":1,"b":2}`, wr.JSON(map[string]any{"a": 1, "b": 2}))
+	oj.PutWriter(wr)
+
+	// Options of a returned Writer must not leak into the package functions.
+	tt.Equal(t, `{"a":[true]}`, oj.JSON(map[string]any{"a": []any{true}}))
+}
+
+func TestParserPool(t *testing.T) {
+	p := oj.GetParser()
+	p.Comments = true
+	v, err := p.Parse([]byte(`[1, /* two */ 2]`))
+	tt.Nil(t, err)
+	tt.Equal(t, []any{int64(1), int64(2)}, v)
+	oj.PutParser(p)
+	oj.PutParser(nil)
+
+	p = oj.GetParser()
+	tt.Equal(t, false, p.Comments)
+	_, err = p.Parse([]byte(`[1, /* two */ 2]`))
+	tt.NotNil(t, err)
+	oj.PutParser(p)
+}
+
+func TestParserPoolReset(t *testing.T) {
+	p := oj.GetParser()
+	p.OrderedObjects = true
+	p.ZeroCopy = true
+	p.InternKeys = true
+	p.MaxBufSize = 64
+	p.NumberConv = func(path jp.Expr, num string) (any, error) { return num, nil }
+	_, err := p.Parse([]byte(`{"a":"b"}`))
+	tt.Nil(t, err)
+	oj.PutParser(p)
+
+	p = oj.GetParser()
+	tt.Equal(t, false, p.OrderedObjects)
+	tt.Equal(t, false, p.ZeroCopy)
+	tt.Equal(t, false, p.InternKeys)
+	tt.Equal(t, 0, p.MaxBufSize)
+	tt.Equal(t, true, p.NumberConv == nil)
+	oj.PutParser(p)
+
+	// Settings of a returned Parser must not leak into the package functions.
+	buf := []byte(`{"a":"bcd"}`)
+	v, err := oj.Parse(buf)
+	tt.Nil(t, err)
+	buf[7] = 'x'
+	tt.Equal(t, map[string]any{"a": "bcd"}, v)
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj
+
+import (
+	"reflect"
+)
+
+var boolType = reflect.TypeOf(true)
+
+// seqArity returns 1 if the type has the form of an iter.Seq[V], 2 if it has
+// the form of an iter.Seq2[K, V] where K is a string type, and 0 otherwise.
+// The check is on the shape of the function so the iter package is not
+// required and unnamed function types with the same shape also match.
+func seqArity(rt reflect.Type) int {
+	if rt.Kind() != reflect.Func || rt.NumIn() != 1 || rt.NumOut() != 0 {
+		return 0
+	}
+	yt := rt.In(0)
+	if yt.Kind() != reflect.Func || yt.NumOut() != 1 || yt.Out(0) != boolType || yt.IsVariadic() {
+		return 0
+	}
+	switch yt.NumIn() {
+	case 1:
+		return 1
+	case 2:
+		if yt.In(0).Kind() == reflect.String {
+			return 2
+		}
+	}
+	return 0
+}
+
+// appendSeq writes an iter.Seq as a JSON array or an iter.Seq2 with string
+// keys as a JSON object and returns true. If the value is not a sequence
+// false is returned and nothing is written. Elements are written as they are
+// yielded so the sequence is never collected into a slice. Object members
+// are written in the order yielded even if the Sort option is set.
+func (wr *Writer) appendSeq(rv reflect.Value, depth int) bool {
+	arity := seqArity(rv.Type())
+	if arity == 0 {
+		return false
+	}
+	if rv.IsNil() {
+		if wr.Color {
+			wr.buf = append(wr.buf, wr.NullColor...)
+		}
+		wr.buf = append(wr.buf, "null"...)
+		return true
+	}
+	d2 := depth + 1
+	var is string
+	var cs string
+	if wr.Tab {
+		is = tabs[0:min(depth+1, len(tabs))]
+		cs = tabs[0:min(d2+1, len(tabs))]
+	} else if 0 < wr.Indent {
+		is = spaces[0:min(depth*wr.Indent+1, len(spaces))]
+		cs = spaces[0:min(d2*wr.Indent+1, len(spaces))]
+	}
+	open, close := byte('['), byte(']')
+	if arity == 2 {
+		open, close = '{', '}'
+	}
+	if !wr.enterPlain(rv, depth) {
+		return true
+	}
+	wr.appendSyntax(open)
+	empty := true
+	index := 0
+	yt := rv.Type().In(0)
+	yield := reflect.MakeFunc(yt, func(args []reflect.Value) []reflect.Value {
+		m := args[len(args)-1].Interface()
+		member := m
+		var key string
+		i := -1
+		if arity == 2 {
+			key = args[0].String()
+			if wr.redactKey(key) {
+				m = wr.redactMask
+			}
+			if wr.omitMember(m) {
+				return []reflect.Value{reflect.ValueOf(true)}
+			}
+		} else {
+			i = index
+			index++
+		}
+		var parent pathState
+		if wr.filtering {
+			var ok bool
+			if parent, ok = wr.pathEnter(key, i, 0, member); !ok {
+				return []reflect.Value{reflect.ValueOf(true)}
+			}
+		}
+		if wr.cycles {
+			if arity == 2 {
+				wr.stepKey(key, args[1])
+			} else {
+				wr.stepMember(i, args[0])
+			}
+		}
+		mark := len(wr.buf)
+		if !empty {
+			wr.appendSyntax(',')
+		}
+		wr.buf = append(wr.buf, cs...)
+		if arity == 2 {
+			if wr.Color {
+				wr.buf = append(wr.buf, wr.KeyColor...)
+			}
+			wr.buf = wr.appendString(wr.buf, wr.keyFor(key), !wr.HTMLUnsafe)
+			if wr.Color {
+				wr.buf = append(wr.buf, wr.NoColor...)
+			}
+			wr.appendSyntax(':')
+			if 0 < len(cs) {
+				wr.buf = append(wr.buf, ' ')
+			}
+		}
+		if wr.Color {
+			wr.colorJSON(m, d2)
+		} else {
+			wr.appendJSON(m, d2)
+		}
+		if !wr.filtering || wr.pathLeave(parent, mark, true) {
+			empty = false
+		}
+		return []reflect.Value{reflect.ValueOf(true)}
+	})
+	rv.Call([]reflect.Value{yield})
+	wr.leave()
+	if !empty {
+		wr.buf = append(wr.buf, is...)
+	}
+	if wr.Color {
+		wr.buf = append(wr.buf, wr.SyntaxColor...)
+	}
+	wr.buf = append(wr.buf, close)
+
+	return true
+}
+
+// appendSyntax appends a syntax character in the syntax color if writing
+// with color.
+func (wr *Writer) appendSyntax(b byte) {
+	if wr.Color {
+		wr.buf = append(wr.buf, wr.SyntaxColor...)
+		wr.buf = append(wr.buf, b)
+		wr.buf = append(wr.buf, wr.NoColor...)
+		return
+	}
+	wr.buf = append(wr.buf, b)
+}
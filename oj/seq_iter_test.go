@@ -0,0 +1,29 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+//go:build go1.23
+
+package oj_test
+
+import (
+	"iter"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/tt"
+)
+
+func TestWriteIterSeq(t *testing.T) {
+	var seq iter.Seq[int] = countTo(2)
+	tt.Equal(t, `[1,2]`, oj.JSON(seq))
+
+	var seq2 iter.Seq2[string, any] = pairs("a", 1)
+	tt.Equal(t, `{"a":1}`, oj.JSON(seq2))
+
+	tt.Equal(t, `{"a":[{"x":true}]}`, oj.JSON(map[string]any{"a": slices.Values([]any{pairs("x", true)})}))
+	tt.Equal(t, `{"a":1}`, oj.JSON(maps.All(map[string]int{"a": 1})))
+
+	var nilSeq iter.Seq[int]
+	tt.Equal(t, `null`, oj.JSON(nilSeq))
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/tt"
+)
+
+func countTo(n int) func(yield func(int) bool) {
+	return func(yield func(int) bool) {
+		for i := 1; i <= n; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+func pairs(kv ...any) func(yield func(string, any) bool) {
+	return func(yield func(string, any) bool) {
+		for i := 0; i < len(kv); i += 2 {
+			if !yield(kv[i].(string), kv[i+1]) {
+				return
+			}
+		}
+	}
+}
+
+func TestWriteSeq(t *testing.T) {
+	tt.Equal(t, `[1,2,3]`, oj.JSON(countTo(3)))
+	tt.Equal(t, `[]`, oj.JSON(countTo(0)))
+	tt.Equal(t, `{"b":1,"a":[1,2]}`, oj.JSON(pairs("b", 1, "a", countTo(2)), &ojg.Options{Sort: true}))
+	tt.Equal(t, `{"b":2}`, oj.JSON(pairs("a", nil, "b", 2), &ojg.Options{OmitNil: true}))
+	tt.Equal(t, "[\n  1,\n  2\n]", oj.JSON(countTo(2), 2))
+	tt.Equal(t, "{\n  \"a\": 1\n}", oj.JSON(pairs("a", 1), 2))
+	tt.Equal(t, "[\n  {\n    \"a\": 1\n  }\n]", oj.JSON([]any{pairs("a", 1)}, 2))
+
+	var nilSeq func(yield func(int) bool)
+	tt.Equal(t, `null`, oj.JSON(nilSeq))
+
+	opt := ojg.Options{Color: true, SyntaxColor: "S", KeyColor: "K", NumberColor: "N", NoColor: "X"}
+	tt.Equal(t, `S{XK"a"XS:XN1XS}X`, oj.JSON(pairs("a", 1), &opt))
+	tt.Equal(t, `S[XN1XS,XN2XS]X`, oj.JSON(countTo(2), &opt))
+}
+
+func TestWriteSeqStream(t *testing.T) {
+	var buf bytes.Buffer
+	var seen []int
+	seq := func(yield func(int) bool) {
+		for i := 0; i < 100; i++ {
+			// Output is written as the buffer fills, before the sequence is
+			// done.
+			seen = append(seen, buf.Len())
+			if !yield(i) {
+				return
+			}
+		}
+	}
+	wr := oj.Writer{Options: ojg.Options{WriteLimit: 16}}
+	tt.Nil(t, wr.Write(&buf, seq))
+	tt.Equal(t, true, 0 < seen[50])
+
+	var out []any
+	out, _ = oj.MustParse(buf.Bytes()).([]any)
+	tt.Equal(t, 100, len(out))
+
+	_, err := oj.Marshal(func(yield func(int, int) bool) {})
+	tt.NotNil(t, err)
+}
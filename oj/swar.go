@@ -0,0 +1,35 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+//go:build amd64 || arm64
+
+package oj
+
+import "encoding/binary"
+
+const (
+	lsb = 0x0101010101010101
+	msb = 0x8080808080808080
+)
+
+// plainStringLen returns the length, a multiple of 8, of the leading bytes
+// of a string in the buffer that are neither a quote, a backslash, nor a
+// control character. The bytes are checked 8 at a time as a uint64 which
+// is fast on architectures that allow unaligned loads. At least one byte
+// is always left for the caller to check.
+func plainStringLen(buf []byte) (n int) {
+	for ; n+8 < len(buf); n += 8 {
+		v := binary.LittleEndian.Uint64(buf[n:])
+		x := (v - 0x20*lsb) &^ v
+		x |= hasZero(v^('"'*lsb)) | hasZero(v^('\\'*lsb))
+		if x&msb != 0 {
+			break
+		}
+	}
+	return
+}
+
+// hasZero sets the high bit of a byte if the byte is zero. Bytes above a
+// zero byte may also be flagged.
+func hasZero(v uint64) uint64 {
+	return (v - lsb) &^ v
+}
@@ -0,0 +1,11 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+//go:build !amd64 && !arm64
+
+package oj
+
+// plainStringLen returns zero on architectures where loading 8 bytes at a
+// time is not faster than checking each byte.
+func plainStringLen(buf []byte) int {
+	return 0
+}
@@ -5,8 +5,6 @@ package oj
 import (
 	"fmt"
 	"reflect"
-	"sort"
-	"strings"
 	"unsafe"
 
 	"github.com/ohler55/ojg"
@@ -21,6 +19,9 @@ func tightDefault(wr *Writer, data any, _ int) {
 		if kind == reflect.Ptr {
 			rv = rv.Elem()
 			kind = rv.Kind()
+			if wr.encodeValue(rv, 0) {
+				return
+			}
 		}
 		switch kind {
 		case reflect.Struct:
@@ -30,18 +31,30 @@ func tightDefault(wr *Writer, data any, _ int) {
 		case reflect.Map:
 			wr.tightMap(rv, nil)
 		case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+			if kind == reflect.Func && wr.appendSeq(rv, 0) {
+				return
+			}
+			if v, ok := wr.Fallback(data); ok {
+				wr.appendJSON(v, 0)
+				return
+			}
 			if wr.strict {
-				panic(fmt.Errorf("%T can not be encoded as a JSON element", data))
+				panic(ojg.Errorf(ojg.ErrUnsupportedType, "%T can not be encoded as a JSON element", data))
 			}
 			wr.buf = append(wr.buf, "null"...)
 		default:
 			dec := alt.Decompose(data, &wr.Options)
 			wr.appendJSON(dec, 0)
 		}
-	case wr.strict:
-		panic(fmt.Errorf("%T can not be encoded as a JSON element", data))
 	default:
-		wr.buf = ojg.AppendJSONString(wr.buf, fmt.Sprintf("%v", data), !wr.HTMLUnsafe)
+		if v, ok := wr.Fallback(data); ok {
+			wr.appendJSON(v, 0)
+			return
+		}
+		if wr.strict {
+			panic(ojg.Errorf(ojg.ErrUnsupportedType, "%T can not be encoded as a JSON element", data))
+		}
+		wr.buf = wr.appendString(wr.buf, fmt.Sprintf("%v", data), !wr.HTMLUnsafe)
 	}
 }
 
@@ -62,6 +75,9 @@ func tightObject(wr *Writer, n map[string]any, _ int) {
 	comma := false
 	wr.buf = append(wr.buf, '{')
 	for k, m := range n {
+		if wr.redactKey(k) {
+			m = wr.redactMask
+		}
 		switch tm := m.(type) {
 		case nil:
 			if wr.OmitNil {
@@ -80,7 +96,7 @@ func tightObject(wr *Writer, n map[string]any, _ int) {
 				continue
 			}
 		}
-		wr.buf = ojg.AppendJSONString(wr.buf, k, !wr.HTMLUnsafe)
+		wr.buf = wr.appendString(wr.buf, wr.keyFor(k), !wr.HTMLUnsafe)
 		wr.buf = append(wr.buf, ':')
 		wr.appendJSON(m, 0)
 		wr.buf = append(wr.buf, ',')
@@ -96,13 +112,12 @@ func tightObject(wr *Writer, n map[string]any, _ int) {
 func tightSortObject(wr *Writer, n map[string]any, _ int) {
 	comma := false
 	wr.buf = append(wr.buf, '{')
-	keys := make([]string, 0, len(n))
-	for k := range n {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
+	keys := wr.sortedKeys(n)
 	for _, k := range keys {
 		m := n[k]
+		if wr.redactKey(k) {
+			m = wr.redactMask
+		}
 		switch tm := m.(type) {
 		case nil:
 			if wr.OmitNil {
@@ -121,7 +136,7 @@ func tightSortObject(wr *Writer, n map[string]any, _ int) {
 				continue
 			}
 		}
-		wr.buf = ojg.AppendJSONString(wr.buf, k, !wr.HTMLUnsafe)
+		wr.buf = wr.appendString(wr.buf, wr.keyFor(k), !wr.HTMLUnsafe)
 		wr.buf = append(wr.buf, ':')
 		wr.appendJSON(m, 0)
 		wr.buf = append(wr.buf, ',')
@@ -135,10 +150,24 @@ func tightSortObject(wr *Writer, n map[string]any, _ int) {
 }
 
 func (wr *Writer) tightStruct(rv reflect.Value, si *sinfo) {
+	if wr.encodeValue(rv, 0) {
+		return
+	}
+	if wr.ctx != nil {
+		wr.checkContext()
+	}
 	if si == nil {
 		si = getSinfo(rv.Interface(), wr.OmitEmpty)
 	}
-	fields := si.fields[wr.findex]
+	if !wr.enter(rv, 0) {
+		return
+	}
+	if wr.filtering && !wr.path.whole {
+		wr.pathStruct(rv, 0, si)
+		wr.leave()
+		return
+	}
+	fields := wr.structFields(si)
 	wr.buf = append(wr.buf, '{')
 	var v any
 	comma := false
@@ -161,9 +190,21 @@ func (wr *Writer) tightStruct(rv reflect.Value, si *sinfo) {
 	}
 	var stat appendStatus
 	for _, fi := range fields {
-		if 0 < addr {
+		if (fi.skippable || wr.skipFields) && wr.skipField(fi, rv) {
+			continue
+		}
+		if wr.cycles {
+			wr.stepField(fi)
+		}
+		switch {
+		case fi.redact || wr.redactKey(fi.key):
+			wr.buf = append(wr.buf, fi.jkey...)
+			v, stat = wr.redactMask, aChanged
+		case wr.slowField && fi.sAppend != nil:
+			wr.buf, v, stat = fi.sAppend(fi, wr.buf, rv, addr, !wr.HTMLUnsafe)
+		case 0 < addr:
 			wr.buf, v, stat = fi.Append(fi, wr.buf, rv, addr, !wr.HTMLUnsafe)
-		} else {
+		default:
 			wr.buf, v, stat = fi.iAppend(fi, wr.buf, rv, addr, !wr.HTMLUnsafe)
 		}
 		switch stat {
@@ -231,14 +272,42 @@ func (wr *Writer) tightStruct(rv reflect.Value, si *sinfo) {
 	} else {
 		wr.buf = append(wr.buf, '}')
 	}
+	wr.leave()
 }
 
 func (wr *Writer) tightSlice(rv reflect.Value, si *sinfo) {
+	if wr.encodeValue(rv, 0) {
+		return
+	}
+	if wr.appendBase64(rv) {
+		return
+	}
+	if rv.Kind() == reflect.Slice && rv.IsNil() && wr.appendNilCollection(false) {
+		return
+	}
+	if !wr.enter(rv, 0) {
+		return
+	}
+	if wr.filtering && !wr.path.whole {
+		wr.pathSlice(rv, 0)
+		wr.leave()
+		return
+	}
 	end := rv.Len()
 	comma := false
 	wr.buf = append(wr.buf, '[')
+	mk := marshalKind(rv.Type().Elem())
 	for j := 0; j < end; j++ {
+		if wr.cycles {
+			wr.stepIndex(j)
+		}
 		rm := rv.Index(j)
+		if m := reflectMarshaler(rm, mk); m != nil {
+			wr.buf = appendMarshaled(wr.buf, m)
+			wr.buf = append(wr.buf, ',')
+			comma = true
+			continue
+		}
 		if rm.Kind() == reflect.Ptr {
 			rm = rm.Elem()
 		}
@@ -260,17 +329,52 @@ func (wr *Writer) tightSlice(rv reflect.Value, si *sinfo) {
 	} else {
 		wr.buf = append(wr.buf, ']')
 	}
+	wr.leave()
 }
 
 func (wr *Writer) tightMap(rv reflect.Value, si *sinfo) {
-	wr.buf = append(wr.buf, '{')
-	keys := rv.MapKeys()
-	if wr.Sort {
-		sort.Slice(keys, func(i, j int) bool { return 0 > strings.Compare(keys[i].String(), keys[j].String()) })
+	if wr.encodeValue(rv, 0) {
+		return
+	}
+	if rv.IsNil() && wr.appendNilCollection(false) {
+		return
+	}
+	if !wr.enter(rv, 0) {
+		return
+	}
+	if wr.filtering && !wr.path.whole {
+		wr.pathMap(rv, 0)
+		wr.leave()
+		return
 	}
+	wr.buf = append(wr.buf, '{')
+	keys, names := alt.MapKeys(rv, wr.sorting)
 	comma := false
-	for _, kv := range keys {
+	vk := marshalKind(rv.Type().Elem())
+	for i, kv := range keys {
+		var key string
+		if names == nil {
+			key = kv.String()
+		} else {
+			key = names[i]
+		}
 		rm := rv.MapIndex(kv)
+		if wr.cycles {
+			wr.stepKey(key, rm)
+		}
+		mk := vk
+		if wr.redactKey(key) {
+			rm = reflect.ValueOf(wr.redactMask)
+			mk = marshalNone
+		}
+		if m := reflectMarshaler(rm, mk); m != nil {
+			wr.buf = wr.appendString(wr.buf, wr.keyFor(key), !wr.HTMLUnsafe)
+			wr.buf = append(wr.buf, ':')
+			wr.buf = appendMarshaled(wr.buf, m)
+			wr.buf = append(wr.buf, ',')
+			comma = true
+			continue
+		}
 		if rm.Kind() == reflect.Ptr {
 			if wr.OmitNil && rm.IsNil() {
 				continue
@@ -279,32 +383,32 @@ func (wr *Writer) tightMap(rv reflect.Value, si *sinfo) {
 		}
 		switch rm.Kind() {
 		case reflect.Struct:
-			wr.buf = ojg.AppendJSONString(wr.buf, kv.String(), !wr.HTMLUnsafe)
+			wr.buf = wr.appendString(wr.buf, wr.keyFor(key), !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, ':')
 			wr.tightStruct(rm, si)
 		case reflect.Slice, reflect.Array:
 			if (wr.OmitNil || wr.OmitEmpty) && rm.Len() == 0 {
 				continue
 			}
-			wr.buf = ojg.AppendJSONString(wr.buf, kv.String(), !wr.HTMLUnsafe)
+			wr.buf = wr.appendString(wr.buf, wr.keyFor(key), !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, ':')
 			wr.tightSlice(rm, si)
 		case reflect.Map:
 			if (wr.OmitNil || wr.OmitEmpty) && rm.Len() == 0 {
 				continue
 			}
-			wr.buf = ojg.AppendJSONString(wr.buf, kv.String(), !wr.HTMLUnsafe)
+			wr.buf = wr.appendString(wr.buf, wr.keyFor(key), !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, ':')
 			wr.tightMap(rm, si)
 		case reflect.String:
 			if (wr.OmitNil || wr.OmitEmpty) && rm.Len() == 0 {
 				continue
 			}
-			wr.buf = ojg.AppendJSONString(wr.buf, kv.String(), !wr.HTMLUnsafe)
+			wr.buf = wr.appendString(wr.buf, wr.keyFor(key), !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, ':')
 			wr.appendJSON(rm.Interface(), 0)
 		default:
-			wr.buf = ojg.AppendJSONString(wr.buf, kv.String(), !wr.HTMLUnsafe)
+			wr.buf = wr.appendString(wr.buf, wr.keyFor(key), !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, ':')
 			wr.appendJSON(rm.Interface(), 0)
 		}
@@ -316,4 +420,5 @@ func (wr *Writer) tightMap(rv reflect.Value, si *sinfo) {
 	} else {
 		wr.buf = append(wr.buf, '}')
 	}
+	wr.leave()
 }
@@ -2,41 +2,85 @@
 
 package oj
 
+import "github.com/ohler55/ojg"
+
 // TokenHandler describes an interface for handling tokens when using the
-// Tokenizer for parsing JSON or SEN documents.
-type TokenHandler interface {
-	// Null is called when a JSON null is encountered.
-	Null()
+// Tokenizer for parsing JSON or SEN documents. It is the ojg.TokenHandler
+// shared by all formats.
+type TokenHandler = ojg.TokenHandler
 
-	// Bool is called when a JSON true or false is encountered.
-	Bool(bool)
+// PosTokenHandler is a TokenHandler that is also given the position of each
+// token. Position is called just before each of the TokenHandler functions
+// with the byte offset, line, and column of the first byte of the
+// token. Offsets start at zero while lines and columns start at one.
+type PosTokenHandler interface {
+	TokenHandler
 
-	// Int is called when a JSON integer is encountered.
-	Int(int64)
+	// Position is called with the position of the next token.
+	Position(offset, line, column int)
+}
 
-	// Float is called when a JSON decimal is encountered that fits into a
-	// float64.
-	Float(float64)
+// posTokens calls Position on the handler before each token callback.
+type posTokens struct {
+	t *Tokenizer
+	h PosTokenHandler
+}
+
+func (pt *posTokens) pos() {
+	pt.h.Position(pt.t.tokOff, pt.t.tokLine, pt.t.tokCol)
+}
 
-	// Number is called when a JSON number is encountered that does not fit
-	// into an int64 or float64.
-	Number(string)
+func (pt *posTokens) Null() {
+	pt.pos()
+	pt.h.Null()
+}
 
-	// String is called when a JSON string is encountered.
-	String(string)
+func (pt *posTokens) Bool(v bool) {
+	pt.pos()
+	pt.h.Bool(v)
+}
 
-	// ObjectStart is called when a JSON object start '{' is encountered.
-	ObjectStart()
+func (pt *posTokens) Int(v int64) {
+	pt.pos()
+	pt.h.Int(v)
+}
 
-	// ObjectEnd is called when a JSON object end '}' is encountered.
-	ObjectEnd()
+func (pt *posTokens) Float(v float64) {
+	pt.pos()
+	pt.h.Float(v)
+}
 
-	// Key is called when a JSON object key is encountered.
-	Key(string)
+func (pt *posTokens) Number(v string) {
+	pt.pos()
+	pt.h.Number(v)
+}
 
-	// ArrayStart is called when a JSON array start '[' is encountered.
-	ArrayStart()
+func (pt *posTokens) String(v string) {
+	pt.pos()
+	pt.h.String(v)
+}
+
+func (pt *posTokens) ObjectStart() {
+	pt.pos()
+	pt.h.ObjectStart()
+}
+
+func (pt *posTokens) ObjectEnd() {
+	pt.pos()
+	pt.h.ObjectEnd()
+}
+
+func (pt *posTokens) Key(v string) {
+	pt.pos()
+	pt.h.Key(v)
+}
+
+func (pt *posTokens) ArrayStart() {
+	pt.pos()
+	pt.h.ArrayStart()
+}
 
-	// ArrayEnd is called when a JSON array end ']' is encountered.
-	ArrayEnd()
+func (pt *posTokens) ArrayEnd() {
+	pt.pos()
+	pt.h.ArrayEnd()
 }
@@ -6,11 +6,12 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io"
 	"math"
+	"unicode/utf16"
 	"unicode/utf8"
 
+	"github.com/ohler55/ojg"
 	"github.com/ohler55/ojg/gen"
 )
 
@@ -30,8 +31,14 @@ type Tokenizer struct {
 	mi        int
 	num       gen.Number
 	rn        rune
+	hi        rune // pending high surrogate
+	hiEnd     int
 	mode      string
 	nextMode  string
+	tokOff    int // offset of the start of the current token
+	tokLine   int
+	tokCol    int
+	positions bool
 }
 
 // TokenizeString the provided JSON and call the handler functions for each
@@ -57,7 +64,7 @@ func TokenizeLoad(r io.Reader, handler TokenHandler) error {
 
 // Parse the JSON and call the handler functions for each token in the JSON.
 func (t *Tokenizer) Parse(buf []byte, handler TokenHandler) (err error) {
-	t.handler = handler
+	t.setHandler(handler)
 	if t.starts == nil {
 		t.tmp = make([]byte, 0, tmpInitSize)
 		t.starts = make([]byte, 0, 16)
@@ -67,25 +74,29 @@ func (t *Tokenizer) Parse(buf []byte, handler TokenHandler) (err error) {
 	}
 	t.noff = -1
 	t.line = 1
+	t.base = 0
 	t.mode = valueMap
 	t.mi = 0
 	// Skip BOM if present.
 	if 3 < len(buf) && buf[0] == 0xEF {
 		if buf[1] == 0xBB && buf[2] == 0xBF {
+			t.base = 3
 			err = t.tokenizeBuffer(buf[3:], true)
 		} else {
-			err = fmt.Errorf("expected BOM at 1:3")
+			err = ojg.Errorf(ojg.ErrSyntax, "expected BOM at 1:3")
 		}
 	} else {
 		err = t.tokenizeBuffer(buf, true)
 	}
+	t.src = nil
+
 	return
 }
 
 // Load aand parse the JSON and call the handler functions for each token in
 // the JSON.
 func (t *Tokenizer) Load(r io.Reader, handler TokenHandler) (err error) {
-	t.handler = handler
+	t.setHandler(handler)
 	if t.starts == nil {
 		t.tmp = make([]byte, 0, tmpInitSize)
 		t.starts = make([]byte, 0, 16)
@@ -95,6 +106,7 @@ func (t *Tokenizer) Load(r io.Reader, handler TokenHandler) (err error) {
 	}
 	t.noff = -1
 	t.line = 1
+	t.base = 0
 	t.mi = 0
 	buf := make([]byte, readBufSize)
 	eof := false
@@ -114,11 +126,13 @@ func (t *Tokenizer) Load(r io.Reader, handler TokenHandler) (err error) {
 		skip = 3
 	}
 	for {
-		if 0 < skip {
-			err = t.tokenizeBuffer(buf[skip:], eof)
-		} else {
-			err = t.tokenizeBuffer(buf, eof)
-		}
+		chunk := buf[skip:]
+		t.base += skip
+		err = t.tokenizeBuffer(chunk, eof)
+		// Offsets are relative to the buffer so shift the line start and
+		// base to be relative to the next buffer.
+		t.noff -= len(chunk)
+		t.base += len(chunk)
 		if err != nil {
 			return
 		}
@@ -140,6 +154,7 @@ func (t *Tokenizer) Load(r io.Reader, handler TokenHandler) (err error) {
 }
 
 func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) error {
+	t.src = buf
 	var b byte
 	var i int
 	var off int
@@ -165,6 +180,7 @@ func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) error {
 		case strOk:
 			t.tmp = append(t.tmp, b)
 		case keyQuote:
+			t.mark(off)
 			start := off + 1
 			if len(buf) <= start {
 				t.tmp = t.tmp[:0]
@@ -197,6 +213,7 @@ func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) error {
 			}
 			continue
 		case valQuote:
+			t.mark(off)
 			start := off + 1
 			if len(buf) <= start {
 				t.tmp = t.tmp[:0]
@@ -236,6 +253,7 @@ func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) error {
 			t.mode = stringMap
 			continue
 		case openObject:
+			t.mark(off)
 			t.starts = append(t.starts, objectStart)
 			t.handler.ObjectStart()
 			t.mode = key1Map
@@ -244,18 +262,21 @@ func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) error {
 		case closeObject:
 			depth--
 			if depth < 0 || t.starts[depth] != objectStart {
-				return t.newError(off, "unexpected object close")
+				return t.newError(off, ojg.ErrUnexpectedChar, "unexpected object close")
 			}
 			if 256 < len(t.mode) && t.mode[256] == 'n' {
 				t.handleNum()
 			}
 			t.starts = t.starts[0:depth]
+			t.mark(off)
 			t.handler.ObjectEnd()
 			t.mode = afterMap
 		case val0:
+			t.mark(off)
 			t.mode = zeroMap
 			t.num.Reset()
 		case valDigit:
+			t.mark(off)
 			t.num.Reset()
 			t.mode = digitMap
 			t.num.I = uint64(b - '0')
@@ -274,6 +295,7 @@ func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) error {
 			}
 			off += i
 		case valNeg:
+			t.mark(off)
 			t.mode = negMap
 			t.num.Reset()
 			t.num.Neg = true
@@ -284,6 +306,7 @@ func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) error {
 			t.ri = 0
 			continue
 		case openArray:
+			t.mark(off)
 			t.starts = append(t.starts, arrayStart)
 			t.handler.ArrayStart()
 			t.mode = valueMap
@@ -292,7 +315,7 @@ func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) error {
 		case closeArray:
 			depth--
 			if depth < 0 || t.starts[depth] != arrayStart {
-				return t.newError(off, "unexpected array close")
+				return t.newError(off, ojg.ErrUnexpectedChar, "unexpected array close")
 			}
 			// Only modes with a close array are value, after, and numbers
 			// which are all over 256 long.
@@ -300,9 +323,11 @@ func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) error {
 				t.handleNum()
 			}
 			t.starts = t.starts[:len(t.starts)-1]
+			t.mark(off)
 			t.handler.ArrayEnd()
 			t.mode = afterMap
 		case valNull:
+			t.mark(off)
 			if off+4 <= len(buf) && string(buf[off:off+4]) == "null" {
 				off += 3
 				t.mode = afterMap
@@ -312,6 +337,7 @@ func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) error {
 				t.ri = 0
 			}
 		case valTrue:
+			t.mark(off)
 			if off+4 <= len(buf) && string(buf[off:off+4]) == "true" {
 				off += 3
 				t.mode = afterMap
@@ -321,6 +347,7 @@ func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) error {
 				t.ri = 0
 			}
 		case valFalse:
+			t.mark(off)
 			if off+5 <= len(buf) && string(buf[off:off+5]) == "false" {
 				off += 4
 				t.mode = afterMap
@@ -411,7 +438,19 @@ func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) error {
 				if len(t.runeBytes) < 6 {
 					t.runeBytes = make([]byte, 6)
 				}
-				n := utf8.EncodeRune(t.runeBytes, t.rn)
+				r := t.rn
+				switch {
+				case 0xD800 <= r && r < 0xDC00:
+					// A high surrogate is written as U+FFFD unless followed
+					// by a low surrogate escape.
+					t.hi = r
+					t.hiEnd = len(t.tmp) + 3
+				case 0xDC00 <= r && r < 0xE000 && t.hi != 0 && t.hiEnd == len(t.tmp):
+					t.tmp = t.tmp[:len(t.tmp)-3]
+					r = utf16.DecodeRune(t.hi, r)
+					t.hi = 0
+				}
+				n := utf8.EncodeRune(t.runeBytes, r)
 				t.tmp = append(t.tmp, t.runeBytes[:n]...)
 				t.mode = stringMap
 			}
@@ -421,7 +460,7 @@ func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) error {
 			case t.mode['r'] == tokenOk:
 				t.ri++
 				if "true"[t.ri] != b {
-					return t.newError(off, "expected true")
+					return t.newError(off, ojg.ErrInvalidLiteral, "expected true")
 				}
 				if 3 <= t.ri {
 					t.handler.Bool(true)
@@ -430,7 +469,7 @@ func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) error {
 			case t.mode['a'] == tokenOk:
 				t.ri++
 				if "false"[t.ri] != b {
-					return t.newError(off, "expected false")
+					return t.newError(off, ojg.ErrInvalidLiteral, "expected false")
 				}
 				if 4 <= t.ri {
 					t.handler.Bool(false)
@@ -439,7 +478,7 @@ func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) error {
 			case t.mode['u'] == tokenOk && t.mode['l'] == tokenOk:
 				t.ri++
 				if "null"[t.ri] != b {
-					return t.newError(off, "expected null")
+					return t.newError(off, ojg.ErrInvalidLiteral, "expected null")
 				}
 				if 3 <= t.ri {
 					t.handler.Null()
@@ -460,7 +499,7 @@ func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) error {
 	}
 	if last {
 		if len(t.mode) == 256 { // valid finishing maps are one byte longer
-			return t.newError(off, "incomplete JSON")
+			return t.newError(off, ojg.ErrUnexpectedEOF, "incomplete JSON")
 		}
 		if t.mode[256] == 'n' {
 			t.handleNum()
@@ -469,6 +508,25 @@ func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) error {
 	return nil
 }
 
+func (t *Tokenizer) setHandler(handler TokenHandler) {
+	if ph, ok := handler.(PosTokenHandler); ok {
+		t.handler = &posTokens{t: t, h: ph}
+		t.positions = true
+	} else {
+		t.handler = handler
+		t.positions = false
+	}
+}
+
+// mark records the position of the start of a token.
+func (t *Tokenizer) mark(off int) {
+	if t.positions {
+		t.tokOff = t.base + off
+		t.tokLine = t.line
+		t.tokCol = off - t.noff
+	}
+}
+
 func (t *Tokenizer) handleNum() {
 	switch tn := t.num.AsNum().(type) {
 	case int64:
@@ -286,3 +286,41 @@ func TestTokenizerNesting(t *testing.T) {
 	tt.Nil(t, err)
 	tt.Equal(t, "[ { a: [ 1 2 ] } ] ", string(h.buf))
 }
+
+type posHandler struct {
+	oj.ZeroHandler
+	pos []string
+}
+
+func (h *posHandler) Position(offset, line, column int) {
+	h.pos = append(h.pos, fmt.Sprintf("%d:%d:%d", offset, line, column))
+}
+
+func TestTokenizerPositions(t *testing.T) {
+	src := "{\"a\": [1, -2.5,\n  true, null],\n \"b\": \"x\\ty\"}"
+	expect := []string{
+		"0:1:1",   // {
+		"1:1:2",   // "a"
+		"6:1:7",   // [
+		"7:1:8",   // 1
+		"10:1:11", // -2.5
+		"18:2:3",  // true
+		"24:2:9",  // null
+		"28:2:13", // ]
+		"32:3:2",  // "b"
+		"37:3:7",  // "x\ty"
+		"43:3:13", // }
+	}
+	var h posHandler
+	err := oj.TokenizeString(src, &h)
+	tt.Nil(t, err)
+	tt.Equal(t, expect, h.pos)
+
+	// Use a reader larger than the read buffer to check positions across
+	// buffer boundaries.
+	h.pos = h.pos[:0]
+	pad := strings.Repeat(" ", 5000)
+	err = oj.TokenizeLoad(strings.NewReader(pad+"\n"+pad+"[1,\n"+pad+"true]"), &h)
+	tt.Nil(t, err)
+	tt.Equal(t, []string{"10001:2:5001", "10002:2:5002", "15005:3:5001", "15009:3:5005"}, h.pos)
+}
@@ -2,36 +2,53 @@
 
 package oj
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/ohler55/ojg"
+)
 
 type tracker struct {
 	line int
-	noff int // Offset of last newline from start of buf. Can be negative when using a reader.
+	noff int    // Offset of last newline from start of buf. Can be negative when using a reader.
+	base int    // Offset of the start of buf in the document.
+	src  []byte // The buffer being processed, used for error snippets.
 
 	// OnlyOne returns an error if more than one JSON is in the string or stream.
 	OnlyOne bool
 }
 
-func (t *tracker) newError(off int, format string, args ...any) error {
-	return &ParseError{
+func (t *tracker) newError(off int, kind error, format string, args ...any) error {
+	err := &ParseError{
 		Message: fmt.Sprintf(format, args...),
 		Line:    t.line,
 		Column:  off - t.noff,
+		Offset:  t.base + off,
+		Kind:    kind,
 	}
+	err.SetSnippet(t.src, off)
+
+	return err
 }
 
 func (t *tracker) byteError(off int, mode string, b byte, r rune) error {
 	err := &ParseError{
 		Line:   t.line,
 		Column: off - t.noff,
+		Offset: t.base + off,
+		Kind:   ojg.ErrUnexpectedChar,
 	}
+	err.SetSnippet(t.src, off)
 	switch mode {
 	case nullMap:
 		err.Message = "expected null"
+		err.Kind = ojg.ErrInvalidLiteral
 	case trueMap:
 		err.Message = "expected true"
+		err.Kind = ojg.ErrInvalidLiteral
 	case falseMap:
 		err.Message = "expected false"
+		err.Kind = ojg.ErrInvalidLiteral
 	case afterMap:
 		err.Message = fmt.Sprintf("expected a comma or close, not '%c'", r)
 	case key1Map:
@@ -42,12 +59,16 @@ func (t *tracker) byteError(off int, mode string, b byte, r rune) error {
 		err.Message = fmt.Sprintf("expected a colon, not '%c'", r)
 	case negMap, zeroMap, digitMap, dotMap, fracMap, expSignMap, expZeroMap, expMap:
 		err.Message = "invalid number"
+		err.Kind = ojg.ErrInvalidNumber
 	case stringMap:
 		err.Message = fmt.Sprintf("invalid JSON character 0x%02x", b)
+		err.Kind = ojg.ErrInvalidString
 	case escMap:
 		err.Message = fmt.Sprintf("invalid JSON escape character '\\%c'", r)
+		err.Kind = ojg.ErrInvalidString
 	case uMap:
 		err.Message = fmt.Sprintf("invalid JSON unicode character '%c'", r)
+		err.Kind = ojg.ErrInvalidString
 	case spaceMap:
 		err.Message = fmt.Sprintf("extra characters after close, '%c'", r)
 	default:
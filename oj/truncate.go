@@ -0,0 +1,103 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/ohler55/ojg"
+)
+
+// truncated returns true if the MaxWriteDepth has been reached and an array
+// or object is to be replaced by the TruncateMarker.
+func (wr *Writer) truncated() bool {
+	return 0 < wr.MaxWriteDepth && wr.MaxWriteDepth <= wr.level
+}
+
+// appendMarker writes the TruncateMarker. It is written like any other
+// value but without a depth limit.
+func (wr *Writer) appendMarker(depth int) {
+	level := wr.level
+	wr.level = 0
+	if wr.Color {
+		wr.colorJSON(wr.truncateMarker(), depth)
+	} else {
+		wr.appendJSON(wr.truncateMarker(), depth)
+	}
+	wr.level = level
+}
+
+func (wr *Writer) truncateMarker() any {
+	if wr.TruncateMarker == nil {
+		return "…"
+	}
+	return wr.TruncateMarker
+}
+
+// clipString returns the string cut to the MaxStringLength with an ellipsis
+// and, with the TruncateCount option, the number of bytes omitted.
+func (wr *Writer) clipString(s string) string {
+	if wr.MaxStringLength <= 0 || len(s) <= wr.MaxStringLength {
+		return s
+	}
+	end := wr.MaxStringLength
+	for 0 < end && !utf8.RuneStart(s[end]) {
+		end--
+	}
+	if wr.TruncateCount {
+		return fmt.Sprintf("%s…(+%d bytes)", s[:end], len(s)-end)
+	}
+	return s[:end] + "…"
+}
+
+// outputOverflow is raised with panic when the MaxOutputBytes is exceeded
+// and recovered by appendValue.
+type outputOverflow struct{}
+
+// appendValue writes a top level value, enforcing the MaxOutputBytes
+// option if set.
+func (wr *Writer) appendValue(data any, parallel bool) {
+	if 0 < wr.MaxOutputBytes {
+		wr.outStart = len(wr.buf)
+		wr.flushed = 0
+		defer wr.recoverOutputLimit()
+	}
+	wr.root = data
+	switch {
+	case wr.Color:
+		wr.colorJSON(data, 0)
+	case parallel && wr.appendParallel(data):
+	default:
+		wr.appendJSON(data, 0)
+	}
+	wr.root = nil
+}
+
+func (wr *Writer) checkOutput() {
+	if wr.MaxOutputBytes < wr.flushed+len(wr.buf)-wr.outStart {
+		panic(outputOverflow{})
+	}
+}
+
+func (wr *Writer) recoverOutputLimit() {
+	r := recover()
+	if r == nil {
+		return
+	}
+	if _, ok := r.(outputOverflow); !ok {
+		panic(r)
+	}
+	if !wr.TruncateOutput {
+		panic(ojg.Errorf(ojg.ErrOutputLimit, "output exceeds the limit of %d bytes", wr.MaxOutputBytes))
+	}
+	const marker = "…"
+	end := wr.outStart + max(wr.MaxOutputBytes-wr.flushed-len(marker), 0)
+	for wr.outStart < end && !utf8.RuneStart(wr.buf[end]) {
+		end--
+	}
+	wr.buf = append(wr.buf[:end], marker...)
+	if wr.Color {
+		wr.buf = append(wr.buf, wr.NoColor...)
+	}
+}
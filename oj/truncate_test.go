@@ -0,0 +1,132 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/tt"
+)
+
+type truncInner struct {
+	Deep map[string]any
+}
+
+func TestWriteMaxWriteDepth(t *testing.T) {
+	data := map[string]any{
+		"a":    map[string]any{"b": map[string]any{"c": 1}, "x": 2},
+		"list": []any{1, []any{2, []any{3}}},
+		"s":    &truncInner{Deep: map[string]any{"d": []any{4}}},
+	}
+	for _, td := range []struct {
+		depth  int
+		marker any
+		expect string
+	}{
+		{depth: 1, expect: `{"a":"…","list":"…","s":"…"}`},
+		{depth: 2, expect: `{"a":{"b":"…","x":2},"list":[1,"…"],"s":{"deep":"…"}}`},
+		{depth: 3, expect: `{"a":{"b":{"c":1},"x":2},"list":[1,[2,"…"]],"s":{"deep":{"d":"…"}}}`},
+		{depth: 2, marker: map[string]any{"$truncated": true},
+			expect: `{"a":{"b":{"$truncated":true},"x":2},"list":[1,{"$truncated":true}],"s":{"deep":{"$truncated":true}}}`},
+		{depth: 0, expect: `{"a":{"b":{"c":1},"x":2},"list":[1,[2,[3]]],"s":{"deep":{"d":[4]}}}`},
+	} {
+		opt := oj.Options{Sort: true, MaxWriteDepth: td.depth, TruncateMarker: td.marker}
+		tt.Equal(t, td.expect, oj.JSON(data, &opt), "depth %d", td.depth)
+	}
+	// The original data is not modified.
+	tt.Equal(t, `{"b":{"c":1},"x":2}`, oj.JSON(data["a"], &oj.Options{Sort: true}))
+
+	tt.Equal(t, "[\n  1,\n  \"…\"\n]", oj.JSON([]any{1, []any{2}}, &oj.Options{Indent: 2, MaxWriteDepth: 1}))
+}
+
+type truncTagged struct {
+	Zebra  string `json:"zebra"`
+	Amount string `json:"amount" ojg:"asnumber"`
+	Raw    []byte `json:"raw"`
+	Secret string `json:"secret,redact"`
+	Alpha  []int  `json:"alpha"`
+	Inner  *truncTagged
+}
+
+func TestWriteMaxWriteDepthUnreached(t *testing.T) {
+	data := &truncTagged{
+		Zebra:  "z",
+		Amount: "12.50",
+		Raw:    []byte("raw"),
+		Secret: "pw",
+		Alpha:  []int{1, 2},
+		Inner:  &truncTagged{Zebra: "y", Amount: "1"},
+	}
+	for _, opt := range []*oj.Options{
+		{UseTags: true, FieldOrder: ojg.FieldOrderDeclared, BytesAs: ojg.BytesAsBase64},
+		{UseTags: true, Indent: 2},
+	} {
+		expect := oj.JSON(data, opt)
+		tt.Equal(t, true, strings.Contains(expect, `"amount":`) && !strings.Contains(expect, "pw"), expect)
+		limited := *opt
+		limited.MaxWriteDepth = 3
+		tt.Equal(t, expect, oj.JSON(data, &limited))
+	}
+	opt := oj.Options{UseTags: true, FieldOrder: ojg.FieldOrderDeclared, BytesAs: ojg.BytesAsBase64, MaxWriteDepth: 1}
+	tt.Equal(t, `{"zebra":"z","amount":12.50,"raw":"cmF3","secret":"***","alpha":"…","Inner":"…"}`, oj.JSON(data, &opt))
+}
+
+func TestWriteMaxStringLength(t *testing.T) {
+	type blob struct {
+		Name string
+		Data string
+	}
+	data := map[string]any{
+		"short": "abc",
+		"long":  "abcdefghij",
+		"utf8":  "ééééé",
+		"list":  []any{"0123456789"},
+		"blob":  &blob{Name: "x", Data: "0123456789"},
+	}
+	opt := oj.Options{Sort: true, MaxStringLength: 5}
+	tt.Equal(t, `{"blob":{"data":"01234…","name":"x"},"list":["01234…"],"long":"abcde…","short":"abc","utf8":"éé…"}`,
+		oj.JSON(data, &opt))
+
+	opt.TruncateCount = true
+	tt.Equal(t, `{"long":"abcde…(+5 bytes)"}`, oj.JSON(map[string]any{"long": "abcdefghij"}, &opt))
+
+	opt.Color = true
+	opt.StringColor = ""
+	opt.NoColor = ""
+	tt.Equal(t, `"abcde…(+5 bytes)"`, oj.JSON("abcdefghij", &opt))
+}
+
+func TestWriteMaxOutputBytes(t *testing.T) {
+	data := []any{"abcdefghij", "klmnopqrst", map[string]any{"x": "ééééé"}}
+
+	opt := oj.Options{MaxOutputBytes: 46}
+	tt.Equal(t, `["abcdefghij","klmnopqrst",{"x":"ééééé"}]`, oj.JSON(data, &opt))
+
+	opt.MaxOutputBytes = 20
+	var b strings.Builder
+	err := oj.Write(&b, data, &opt)
+	tt.NotNil(t, err)
+	tt.Equal(t, true, errors.Is(err, ojg.ErrOutputLimit))
+
+	opt.TruncateOutput = true
+	tt.Equal(t, `["abcdefghij","kl…`, oj.JSON(data, &opt))
+
+	opt.MaxOutputBytes = 37 // cut in the middle of a é
+	tt.Equal(t, `["abcdefghij","klmnopqrst",{"x":"…`, oj.JSON(data, &opt))
+
+	// Output flushed to the writer counts against the limit.
+	b.Reset()
+	wr := oj.Writer{Options: oj.Options{MaxOutputBytes: 20, TruncateOutput: true, WriteLimit: 4}}
+	tt.Nil(t, wr.Write(&b, data))
+	tt.Equal(t, `["abcdefghij","kl…`, b.String())
+
+	// Each NDJSON line has its own limit.
+	b.Reset()
+	wr = oj.Writer{Options: oj.Options{MaxOutputBytes: 8, TruncateOutput: true}}
+	tt.Nil(t, wr.WriteMany(&b, []any{"abc", "abcdefghij"}))
+	tt.Equal(t, "\"abc\"\n\"abcd…\n", b.String())
+}
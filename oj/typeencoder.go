@@ -0,0 +1,103 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj
+
+import (
+	"reflect"
+)
+
+// EncodeFunc writes a value to a Writer using the Writer Append methods.
+type EncodeFunc func(wr *Writer, v any)
+
+var typeEncoders = map[reflect.Type]EncodeFunc{}
+
+// RegisterEncoder installs a function that writes values of type T in place
+// of the usual encoding. The function is called with values of type T
+// wherever they are found, including struct fields, slice elements, and
+// map values, and takes precedence over the json.Marshaler and other
+// interfaces as well as reflection. T must be a concrete type. A nil
+// function removes the encoder for the type.
+//
+// Registration is not safe to call while values are being written.
+// Encoders should be registered when a program starts, such as in an init
+// function, so that struct descriptions cached by the writers include them.
+func RegisterEncoder[T any](fun func(wr *Writer, v T)) {
+	rt := reflect.TypeOf((*T)(nil)).Elem()
+	if fun == nil {
+		delete(typeEncoders, rt)
+		return
+	}
+	typeEncoders[rt] = func(wr *Writer, v any) { fun(wr, v.(T)) }
+}
+
+// AppendRaw appends JSON to the output as is. It is intended for use by
+// encoders installed with RegisterEncoder. No validation is performed.
+func (wr *Writer) AppendRaw(raw []byte) {
+	wr.buf = append(wr.buf, raw...)
+}
+
+// AppendString appends a string as a quoted JSON string using the string
+// options of the Writer. It is intended for use by encoders installed with
+// RegisterEncoder.
+func (wr *Writer) AppendString(s string) {
+	wr.buf = wr.appendString(wr.buf, s, !wr.HTMLUnsafe)
+}
+
+// AppendValue appends a value as it would be written by the Writer. It is
+// intended for use by encoders installed with RegisterEncoder for writing
+// the members of the values they encode.
+func (wr *Writer) AppendValue(v any) {
+	if wr.Color {
+		wr.colorJSON(v, wr.encodeDepth)
+	} else {
+		wr.appendJSON(v, wr.encodeDepth)
+	}
+}
+
+// encode writes the value with a registered encoder if there is one for
+// the type of the value, or for the element type of a pointer, and returns
+// true if written.
+func (wr *Writer) encode(v any, depth int) bool {
+	rt := reflect.TypeOf(v)
+	fun := typeEncoders[rt]
+	if fun == nil {
+		if rt == nil || rt.Kind() != reflect.Ptr {
+			return false
+		}
+		if fun = typeEncoders[rt.Elem()]; fun == nil {
+			return false
+		}
+		rv := reflect.ValueOf(v)
+		if rv.IsNil() {
+			wr.buf = append(wr.buf, "null"...)
+			return true
+		}
+		v = rv.Elem().Interface()
+	}
+	defer func(d int) { wr.encodeDepth = d }(wr.encodeDepth)
+	wr.encodeDepth = depth
+	fun(wr, v)
+
+	return true
+}
+
+// encodeValue is the same as encode but for a reflect.Value.
+func (wr *Writer) encodeValue(rv reflect.Value, depth int) bool {
+	if len(typeEncoders) == 0 || !rv.CanInterface() || !hasEncoder(rv.Type()) {
+		return false
+	}
+	return wr.encode(rv.Interface(), depth)
+}
+
+// hasEncoder returns true if there is an encoder registered for the type or
+// for the element type of a pointer type.
+func hasEncoder(rt reflect.Type) bool {
+	if _, has := typeEncoders[rt]; has {
+		return true
+	}
+	if rt.Kind() == reflect.Ptr {
+		_, has := typeEncoders[rt.Elem()]
+		return has
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/tt"
+)
+
+type celsius float64
+
+type point struct {
+	X int
+	Y int
+}
+
+// MarshalJSON is bypassed by the registered encoder.
+func (p point) MarshalJSON() ([]byte, error) {
+	return []byte(`"marshaled"`), nil
+}
+
+type reading struct {
+	Temp   celsius
+	At     point
+	Prev   *point
+	Series []celsius
+	Named  map[string]point
+}
+
+func TestRegisterEncoder(t *testing.T) {
+	oj.RegisterEncoder(func(wr *oj.Writer, c celsius) {
+		wr.AppendString(strconv.FormatFloat(float64(c), 'f', 1, 64) + "C")
+	})
+	oj.RegisterEncoder(func(wr *oj.Writer, p point) {
+		wr.AppendRaw([]byte(`[`))
+		wr.AppendValue(p.X)
+		wr.AppendRaw([]byte(`,`))
+		wr.AppendValue(p.Y)
+		wr.AppendRaw([]byte(`]`))
+	})
+	defer oj.RegisterEncoder[celsius](nil)
+	defer oj.RegisterEncoder[point](nil)
+
+	r := reading{
+		Temp:   21.5,
+		At:     point{X: 1, Y: 2},
+		Prev:   &point{X: 3, Y: 4},
+		Series: []celsius{1, 2},
+		Named:  map[string]point{"a": {X: 5, Y: 6}},
+	}
+	tt.Equal(t, `{"at":[1,2],"named":{"a":[5,6]},"prev":[3,4],"series":["1.0C","2.0C"],"temp":"21.5C"}`, oj.JSON(&r))
+	tt.Equal(t, `{"At":[1,2],"Named":{"a":[5,6]},"Prev":[3,4],"Series":["1.0C","2.0C"],"Temp":"21.5C"}`,
+		oj.JSON(&r, &ojg.Options{KeyExact: true}))
+	tt.Equal(t, "\x1b[1m{\x1b[m\x1b[1;34m\"p\"\x1b[m\x1b[1m:\x1b[m[1\x1b[m,2\x1b[m]\x1b[m\x1b[1m}\x1b[m",
+		oj.JSON(map[string]any{"p": point{X: 1, Y: 2}}, &ojg.Options{Color: true, SyntaxColor: "\x1b[1m", KeyColor: "\x1b[1;34m", NoColor: "\x1b[m"}))
+	tt.Equal(t, `["3.0C",[7,8]]`, oj.JSON([]any{celsius(3), &point{X: 7, Y: 8}}))
+	tt.Equal(t, `{"p":[0,0]}`, oj.JSON(map[string]any{"p": point{}}))
+	tt.Equal(t, "[\n  \"4.0C\"\n]", oj.JSON([]celsius{4}, 2))
+
+	oj.RegisterEncoder[point](nil)
+	tt.Equal(t, `["marshaled"]`, oj.JSON([]any{point{}}))
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package oj
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/ohler55/ojg/alt"
+	"github.com/ohler55/ojg/jp"
+)
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// unmarshalerHook returns a PathHook that keeps the raw bytes of the
+// object members that will be recomposed into a value of the type that
+// implements json.Unmarshaler so that UnmarshalJSON is called with the
+// original JSON. Nil is returned if no json.Unmarshaler is reachable from
+// the type. Array elements are not passed to a PathHook so elements that
+// implement json.Unmarshaler are converted back to JSON by the Recomposer
+// instead.
+func unmarshalerHook(rt reflect.Type) func(path jp.Expr) PathAction {
+	if rt == nil || !reachesUnmarshaler(rt, map[reflect.Type]bool{}) {
+		return nil
+	}
+	return func(path jp.Expr) PathAction {
+		if unmarshalerAt(rt, path) {
+			return PathRaw
+		}
+		return PathKeep
+	}
+}
+
+func isUnmarshaler(rt reflect.Type) bool {
+	return rt.Kind() != reflect.Interface &&
+		(rt.Implements(jsonUnmarshalerType) || reflect.PtrTo(rt).Implements(jsonUnmarshalerType))
+}
+
+func reachesUnmarshaler(rt reflect.Type, seen map[reflect.Type]bool) bool {
+	for rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	if seen[rt] {
+		return false
+	}
+	seen[rt] = true
+	if isUnmarshaler(rt) {
+		return true
+	}
+	switch rt.Kind() {
+	case reflect.Struct:
+		for _, f := range alt.StructFields(rt, true, true) {
+			if reachesUnmarshaler(f.Type, seen) {
+				return true
+			}
+		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return reachesUnmarshaler(rt.Elem(), seen)
+	}
+	return false
+}
+
+// unmarshalerAt returns true if the value at the path is recomposed into a
+// json.Unmarshaler.
+func unmarshalerAt(rt reflect.Type, path jp.Expr) bool {
+	for _, frag := range path {
+		for rt.Kind() == reflect.Ptr {
+			rt = rt.Elem()
+		}
+		switch f := frag.(type) {
+		case jp.Root:
+			continue
+		case jp.Child:
+			switch rt.Kind() {
+			case reflect.Struct:
+				sf, ok := fieldForKey(rt, string(f))
+				if !ok {
+					return false
+				}
+				rt = sf.Type
+			case reflect.Map:
+				rt = rt.Elem()
+			default:
+				return false
+			}
+		case jp.Nth:
+			switch rt.Kind() {
+			case reflect.Slice, reflect.Array:
+				rt = rt.Elem()
+			default:
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	for rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	return isUnmarshaler(rt)
+}
+
+// fieldForKey returns the struct field that the Recomposer sets from an
+// object member with the key. As with the Recomposer, a key matches the
+// json tag name, the field name, the field name with a lowercase first
+// letter, or the lowercase field name.
+func fieldForKey(rt reflect.Type, key string) (sf reflect.StructField, ok bool) {
+	for _, f := range alt.StructFields(rt, true, true) {
+		name := f.Key
+		if !f.Tagged && 0 < len(f.Tag.Get("json")) {
+			name = strings.ToLower(f.Name)
+		}
+		if key == name || key == f.Name || key == strings.ToLower(f.Name) || key == strings.ToLower(f.Name[:1])+f.Name[1:] {
+			return f.StructField, true
+		}
+	}
+	return
+}
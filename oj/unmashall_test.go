@@ -3,12 +3,19 @@
 package oj_test
 
 import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 	"testing"
 
+	"github.com/ohler55/ojg"
 	"github.com/ohler55/ojg/alt"
 	"github.com/ohler55/ojg/jp"
 	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/sen"
 	"github.com/ohler55/ojg/tt"
 )
 
@@ -52,6 +59,37 @@ func TestUnmarshalError(t *testing.T) {
 	tt.Equal(t, true, strings.Contains(err.Error(), "value of type bool cannot be converted to type int"))
 }
 
+func TestUnmarshalErrorKinds(t *testing.T) {
+	type Item struct {
+		Count int
+	}
+	type Order struct {
+		Items []Item
+		Tags  map[string]*Item
+	}
+	var order Order
+	err := oj.Unmarshal([]byte(`{"Items":[{"Count":1},{"Count":"two"}]}`), &order)
+	var tm *ojg.ErrTypeMismatch
+	tt.ErrorAs(t, err, &tm)
+	tt.Equal(t, "$.Items[1].Count", tm.Path)
+
+	err = oj.Unmarshal([]byte(`{"Tags":{"a b":{"Count":true}}}`), &order)
+	tt.ErrorAs(t, err, &tm)
+	tt.Equal(t, "$.Tags['a b'].Count", tm.Path)
+
+	err = oj.Unmarshal([]byte(`{"Items":{}}`), &order)
+	tt.ErrorAs(t, err, &tm)
+	tt.Equal(t, "$.Items", tm.Path)
+
+	err = oj.Unmarshal([]byte(`{"Items":[}`), &order)
+	tt.ErrorIs(t, err, ojg.ErrSyntax)
+
+	rec := alt.MustNewRecomposer("", nil)
+	rec.DisallowUnknownFields = true
+	err = oj.Unmarshal([]byte(`{"Extra":1}`), &order, rec)
+	tt.ErrorIs(t, err, ojg.ErrUnknownField)
+}
+
 type TagMap map[string]any
 
 func (tm *TagMap) UnmarshalJSON(data []byte) error {
@@ -86,3 +124,209 @@ func TestUnmarshalArray(t *testing.T) {
 	tt.Equal(t, 2, tri[1])
 	tt.Equal(t, 3, tri[2])
 }
+
+type rawLevel int
+
+func (lv *rawLevel) UnmarshalJSON(b []byte) error {
+	switch string(b) {
+	case `"low"`:
+		*lv = 1
+	case `"high"`:
+		*lv = 2
+	default:
+		return fmt.Errorf("invalid level %s", b)
+	}
+	return nil
+}
+
+type rawKeeper struct {
+	raw string
+}
+
+func (rk *rawKeeper) UnmarshalJSON(b []byte) error {
+	rk.raw = string(b)
+	return nil
+}
+
+type rawHolder struct {
+	Level  rawLevel
+	Big    rawKeeper `json:"big"`
+	Ptr    *rawKeeper
+	Levels map[string]rawLevel
+	List   []rawLevel
+	Name   string
+}
+
+func TestUnmarshalUnmarshaler(t *testing.T) {
+	src := `{
+  "level": "high",
+  "big": 12345678901234567890123,
+  "ptr": {"b": [1, 2.50]},
+  "levels": {"x": "low"},
+  "list": ["low", "high"],
+  "name": "raw"
+}`
+	var h rawHolder
+	err := oj.Unmarshal([]byte(src), &h)
+	tt.Nil(t, err)
+	tt.Equal(t, rawLevel(2), h.Level)
+	tt.Equal(t, "12345678901234567890123", h.Big.raw)
+	tt.Equal(t, `{"b": [1, 2.50]}`, h.Ptr.raw)
+	tt.Equal(t, map[string]rawLevel{"x": 1}, h.Levels)
+	tt.Equal(t, []rawLevel{1, 2}, h.List)
+	tt.Equal(t, "raw", h.Name)
+
+	var p oj.Parser
+	h = rawHolder{}
+	err = p.Unmarshal([]byte(src), &h)
+	tt.Nil(t, err)
+	tt.Equal(t, "12345678901234567890123", h.Big.raw)
+	tt.Nil(t, p.PathHook)
+
+	var rk rawKeeper
+	err = oj.Unmarshal([]byte(` [1,2.0] `), &rk)
+	tt.Nil(t, err)
+	tt.Equal(t, ` [1,2.0] `, rk.raw)
+
+	err = oj.Unmarshal([]byte(`{"level":"medium"}`), &h)
+	tt.NotNil(t, err)
+}
+
+func TestParserUnmarshalDisallowUnknownFields(t *testing.T) {
+	type Item struct {
+		Name string
+	}
+	type Order struct {
+		Items []Item
+	}
+	var order Order
+	p := oj.Parser{DisallowUnknownFields: true}
+	err := p.Unmarshal([]byte(`{"items":[{"name":"a"},{"name":"b","size":3}]}`), &order)
+	tt.ErrorIs(t, err, ojg.ErrUnknownField)
+	var uf *ojg.ErrUnknownFieldAt
+	tt.ErrorAs(t, err, &uf)
+	tt.Equal(t, "$.items[1].size", uf.Path)
+	tt.Equal(t, "size", uf.Key)
+
+	p.DisallowUnknownFields = false
+	err = p.Unmarshal([]byte(`{"items":[{"name":"a","size":3}]}`), &order)
+	tt.Nil(t, err)
+	tt.Equal(t, "a", order.Items[0].Name)
+}
+
+func TestParserUnmarshalDecodeHook(t *testing.T) {
+	type Item struct {
+		Price  int64
+		Labels map[string]string
+	}
+	type Order struct {
+		Items []Item
+	}
+	var paths []string
+	p := oj.Parser{DecodeHook: func(path jp.Expr, value any, target reflect.Type) (any, error) {
+		paths = append(paths, path.String())
+		switch {
+		case path.String() == "$.items[0].labels":
+			labels := map[string]string{}
+			for k, v := range value.(map[string]any) {
+				labels[k] = fmt.Sprint(v)
+			}
+			return labels, nil
+		case target.Kind() == reflect.Int64:
+			if s, ok := value.(string); ok {
+				return strconv.ParseInt(strings.TrimPrefix(s, "$"), 10, 64)
+			}
+		}
+		return value, nil
+	}}
+	var order Order
+	err := p.Unmarshal([]byte(`{"items":[{"price":"$12","labels":{"n":1,"b":true}}]}`), &order)
+	tt.Nil(t, err)
+	tt.Equal(t, Order{Items: []Item{{Price: 12, Labels: map[string]string{"n": "1", "b": "true"}}}}, order)
+	sort.Strings(paths)
+	tt.Equal(t, []string{"$.items", "$.items[0]", "$.items[0].labels", "$.items[0].price"}, paths)
+
+	err = p.Unmarshal([]byte(`{"items":[{"price":"$x"}]}`), &order)
+	tt.NotNil(t, err)
+}
+
+func TestUnmarshalBase64Bytes(t *testing.T) {
+	type Blob []byte
+	type Msg struct {
+		Body  []byte
+		Parts [][]byte
+		Blob  Blob
+		None  []byte
+	}
+	msg := Msg{Body: []byte("hello"), Parts: [][]byte{[]byte("a")}, Blob: Blob("b")}
+	for _, opt := range []*oj.Options{&ojg.GoOptions, {BytesAs: ojg.BytesAsBase64, Indent: 2}} {
+		js, err := oj.Marshal(&msg, opt)
+		tt.Nil(t, err)
+		var std Msg
+		tt.Nil(t, json.Unmarshal(js, &std))
+		tt.Equal(t, msg, std)
+		var back Msg
+		tt.Nil(t, oj.Unmarshal(js, &back))
+		tt.Equal(t, msg, back)
+	}
+	var back Msg
+	err := oj.Unmarshal([]byte(`{"Body":"not base64!"}`), &back)
+	tt.NotNil(t, err)
+}
+
+type pairKey struct {
+	A string
+	B string
+}
+
+func (pk pairKey) MarshalText() ([]byte, error) {
+	return []byte(pk.A + ":" + pk.B), nil
+}
+
+func (pk *pairKey) UnmarshalText(b []byte) error {
+	var found bool
+	if pk.A, pk.B, found = strings.Cut(string(b), ":"); !found {
+		return fmt.Errorf("%q is not a pair", b)
+	}
+	return nil
+}
+
+// nameKey is a string kind so it is used as is even though it has a
+// MarshalText method.
+type nameKey string
+
+func (nk nameKey) MarshalText() ([]byte, error) {
+	return []byte(strings.ToUpper(string(nk))), nil
+}
+
+func TestUnmarshalNonStringMapKeys(t *testing.T) {
+	type Keyed struct {
+		Ints  map[int]string
+		Uints map[uint8]int
+		Pairs map[pairKey]int
+		Names map[nameKey]int
+	}
+	keyed := Keyed{
+		Ints:  map[int]string{1: "a", -2: "b"},
+		Uints: map[uint8]int{3: 3},
+		Pairs: map[pairKey]int{{A: "x", B: "y"}: 1},
+		Names: map[nameKey]int{"b": 2, "a": 1},
+	}
+	for _, opt := range []*oj.Options{{Sort: true}, {Sort: true, Indent: 2}} {
+		js, err := oj.Marshal(&keyed, opt)
+		tt.Nil(t, err)
+		tt.Equal(t, `{"ints":{"-2":"b","1":"a"},"names":{"a":1,"b":2},"pairs":{"x:y":1},"uints":{"3":3}}`,
+			oj.JSON(oj.MustParse(js), &oj.Options{Sort: true}))
+		var back Keyed
+		tt.Nil(t, oj.Unmarshal(js, &back))
+		tt.Equal(t, keyed, back)
+	}
+	tt.Equal(t, `{ints:{-2:b "1":a} names:{a:1 b:2} pairs:{"x:y":1} uints:{"3":3}}`, sen.String(&keyed, &oj.Options{Sort: true}))
+	tt.Equal(t, `{"a":1,"b":2}`, oj.JSON(keyed.Names, &oj.Options{Sort: true}))
+	tt.Equal(t, "{\n  a: 1\n  b: 2\n}", sen.String(keyed.Names, &oj.Options{Sort: true, Indent: 2}))
+
+	var back Keyed
+	tt.NotNil(t, oj.Unmarshal([]byte(`{"ints":{"one":"a"}}`), &back))
+	tt.NotNil(t, oj.Unmarshal([]byte(`{"uints":{"300":1}}`), &back))
+	tt.NotNil(t, oj.Unmarshal([]byte(`{"pairs":{"xy":1}}`), &back))
+}
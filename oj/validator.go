@@ -7,6 +7,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
+
+	"github.com/ohler55/ojg"
 )
 
 const stackMinSize = 32 // for container stack { or [
@@ -30,6 +32,23 @@ type Validator struct {
 	// OnlyOne returns an error if more than one JSON is in the string or
 	// stream.
 	OnlyOne bool
+
+	// MaxDepth if greater than zero is the maximum depth of nested arrays
+	// and objects. Deeper nesting fails with an error that matches
+	// ojg.ErrDepthExceeded.
+	MaxDepth int
+
+	// MaxStringLength if greater than zero is the maximum length in bytes
+	// of an encoded string or key not counting the quotes. Longer strings
+	// fail with an error that matches ojg.ErrInputLimit.
+	MaxStringLength int
+
+	// MaxSize if greater than zero is the maximum size of the input in
+	// bytes. Larger input fails with an error that matches
+	// ojg.ErrInputLimit.
+	MaxSize int
+
+	strStart int // document offset of the first byte of the current string
 }
 
 // Validate a JSON encoded byte slice.
@@ -41,17 +60,24 @@ func (p *Validator) Validate(buf []byte) (err error) {
 	}
 	p.noff = -1
 	p.line = 1
+	p.base = 0
 	p.mode = valueMap
+	if 0 < p.MaxSize && p.MaxSize < len(buf) {
+		return p.limitError(p.MaxSize, ojg.ErrInputLimit, "input exceeds %d bytes", p.MaxSize)
+	}
 	// Skip BOM if present.
 	if 3 < len(buf) && buf[0] == 0xEF {
 		if buf[1] == 0xBB && buf[2] == 0xBF {
+			p.base = 3
 			err = p.validateBuffer(buf[3:], true)
 		} else {
-			err = fmt.Errorf("expected BOM at 1:3")
+			err = ojg.Errorf(ojg.ErrSyntax, "expected BOM at 1:3")
 		}
 	} else {
 		err = p.validateBuffer(buf, true)
 	}
+	p.src = nil
+
 	return
 }
 
@@ -64,6 +90,7 @@ func (p *Validator) ValidateReader(r io.Reader) error {
 	}
 	p.noff = -1
 	p.line = 1
+	p.base = 0
 	p.mode = valueMap
 	buf := make([]byte, readBufSize)
 	eof := false
@@ -81,16 +108,18 @@ func (p *Validator) ValidateReader(r io.Reader) error {
 		skip = 3
 	}
 	for {
-		if 0 < skip {
-			err = p.validateBuffer(buf[skip:], eof)
-		} else {
-			err = p.validateBuffer(buf, eof)
+		chunk := buf[skip:]
+		p.base += skip
+		if 0 < p.MaxSize && p.MaxSize < p.base+len(chunk) {
+			return p.limitError(p.MaxSize-p.base, ojg.ErrInputLimit, "input exceeds %d bytes", p.MaxSize)
 		}
+		err = p.validateBuffer(chunk, eof)
 		skip = 0
 		if err != nil {
 			return err
 		}
-		p.noff -= len(buf)
+		p.noff -= len(chunk)
+		p.base += len(chunk)
 		if eof {
 			break
 		}
@@ -108,6 +137,7 @@ func (p *Validator) ValidateReader(r io.Reader) error {
 }
 
 func (p *Validator) validateBuffer(buf []byte, last bool) error {
+	p.src = buf
 	var b byte
 	var i int
 	var off int
@@ -133,6 +163,7 @@ func (p *Validator) validateBuffer(buf []byte, last bool) error {
 		case strOk:
 			continue
 		case keyQuote:
+			p.strStart = p.base + off + 1
 			i = 0
 			for i, b = range buf[off+1:] {
 				if stringMap[b] != strOk {
@@ -142,6 +173,9 @@ func (p *Validator) validateBuffer(buf []byte, last bool) error {
 			off += i
 			if b == '"' && 0 < i {
 				off++
+				if err := p.checkString(off); err != nil {
+					return err
+				}
 				p.mode = colonMap
 			} else {
 				p.mode = stringMap
@@ -156,6 +190,7 @@ func (p *Validator) validateBuffer(buf []byte, last bool) error {
 			}
 			continue
 		case valQuote:
+			p.strStart = p.base + off + 1
 			i = 0
 			for i, b = range buf[off+1:] {
 				if stringMap[b] != strOk {
@@ -165,6 +200,9 @@ func (p *Validator) validateBuffer(buf []byte, last bool) error {
 			off += i
 			if b == '"' && 0 < i {
 				off++
+				if err := p.checkString(off); err != nil {
+					return err
+				}
 				p.mode = afterMap
 			} else {
 				p.mode = stringMap
@@ -187,11 +225,14 @@ func (p *Validator) validateBuffer(buf []byte, last bool) error {
 			p.stack = append(p.stack, '{')
 			p.mode = key1Map
 			depth++
+			if 0 < p.MaxDepth && p.MaxDepth < depth {
+				return p.limitError(off, ojg.ErrDepthExceeded, "depth exceeds %d", p.MaxDepth)
+			}
 			continue
 		case closeObject:
 			depth--
 			if depth < 0 || p.stack[depth] != '{' {
-				return p.newError(off, "unexpected object close")
+				return p.newError(off, ojg.ErrUnexpectedChar, "unexpected object close")
 			}
 			p.stack = p.stack[0:depth]
 			p.mode = afterMap
@@ -212,11 +253,14 @@ func (p *Validator) validateBuffer(buf []byte, last bool) error {
 			p.stack = append(p.stack, '[')
 			p.mode = valueMap
 			depth++
+			if 0 < p.MaxDepth && p.MaxDepth < depth {
+				return p.limitError(off, ojg.ErrDepthExceeded, "depth exceeds %d", p.MaxDepth)
+			}
 			continue
 		case closeArray:
 			depth--
 			if depth < 0 || p.stack[depth] != '[' {
-				return p.newError(off, "unexpected array close")
+				return p.newError(off, ojg.ErrUnexpectedChar, "unexpected array close")
 			}
 			p.stack = p.stack[0:depth]
 			p.mode = afterMap
@@ -253,6 +297,9 @@ func (p *Validator) validateBuffer(buf []byte, last bool) error {
 			p.mode = expSignMap
 			continue
 		case strQuote:
+			if err := p.checkString(off); err != nil {
+				return err
+			}
 			p.mode = p.nextMode
 		case numZero:
 			p.mode = zeroMap
@@ -290,7 +337,7 @@ func (p *Validator) validateBuffer(buf []byte, last bool) error {
 			case p.mode['r'] == tokenOk:
 				p.ri++
 				if "true"[p.ri] != b {
-					return p.newError(off, "expected true")
+					return p.newError(off, ojg.ErrInvalidLiteral, "expected true")
 				}
 				if 3 <= p.ri {
 					p.mode = afterMap
@@ -298,7 +345,7 @@ func (p *Validator) validateBuffer(buf []byte, last bool) error {
 			case p.mode['a'] == tokenOk:
 				p.ri++
 				if "false"[p.ri] != b {
-					return p.newError(off, "expected false")
+					return p.newError(off, ojg.ErrInvalidLiteral, "expected false")
 				}
 				if 4 <= p.ri {
 					p.mode = afterMap
@@ -306,7 +353,7 @@ func (p *Validator) validateBuffer(buf []byte, last bool) error {
 			case p.mode['u'] == tokenOk && p.mode['l'] == tokenOk:
 				p.ri++
 				if "null"[p.ri] != b {
-					return p.newError(off, "expected null")
+					return p.newError(off, ojg.ErrInvalidLiteral, "expected null")
 				}
 				if 3 <= p.ri {
 					p.mode = afterMap
@@ -323,8 +370,28 @@ func (p *Validator) validateBuffer(buf []byte, last bool) error {
 			}
 		}
 	}
-	if last && len(p.mode) == 256 { // valid finishing maps are one byte longer
-		return p.newError(off, "incomplete JSON")
+	if last && (0 < len(p.stack) || len(p.mode) == 256) { // valid finishing maps are one byte longer
+		return p.newError(off, ojg.ErrUnexpectedEOF, "incomplete JSON")
+	}
+	// Check a string that continues into the next buffer so a long string
+	// fails without reading all of it.
+	switch p.mode {
+	case stringMap, escMap, uMap:
+		return p.checkString(off)
+	}
+	return nil
+}
+
+// checkString returns an error if the string that ends at off is longer
+// than MaxStringLength.
+func (p *Validator) checkString(off int) error {
+	if 0 < p.MaxStringLength && p.MaxStringLength < p.base+off-p.strStart {
+		return p.limitError(p.strStart-p.base+p.MaxStringLength, ojg.ErrInputLimit,
+			"string longer than %d bytes", p.MaxStringLength)
 	}
 	return nil
 }
+
+func (p *Validator) limitError(off int, kind error, format string, args ...any) error {
+	return ojg.Errorf(kind, "%s at %d:%d", fmt.Sprintf(format, args...), p.line, off-p.noff)
+}
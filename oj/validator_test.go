@@ -8,6 +8,7 @@ import (
 	"testing"
 	"testing/iotest"
 
+	"github.com/ohler55/ojg"
 	"github.com/ohler55/ojg/oj"
 	"github.com/ohler55/ojg/tt"
 )
@@ -74,6 +75,8 @@ func TestValidatorValidateString(t *testing.T) {
 
 		{src: "{}}", expect: "unexpected object close at 1:3"},
 		{src: "{ \n", expect: "incomplete JSON at 2:1"},
+		{src: `{"v":`, expect: "incomplete JSON at 1:6"},
+		{src: "[1,", expect: "incomplete JSON at 1:4"},
 		{src: "{]}", expect: "expected a string start or object close, not ']' at 1:2"},
 		{src: "[}]", expect: "unexpected object close at 1:2"},
 		{src: "{\"a\" \n : 1]}", expect: "unexpected array close at 2:5"},
@@ -212,3 +215,37 @@ func TestValidatorValidateReaderErr(t *testing.T) {
 	err = v.ValidateReader(&r)
 	tt.NotNil(t, err)
 }
+
+func TestValidatorLimits(t *testing.T) {
+	err := oj.ValidateReaderLimits(strings.NewReader(`[[[1]]]`), 3, 0, 0)
+	tt.Nil(t, err)
+	err = oj.ValidateReaderLimits(strings.NewReader(`[[{"a":[1]}]]`), 3, 0, 0)
+	tt.ErrorIs(t, err, ojg.ErrDepthExceeded)
+	tt.Equal(t, "depth exceeds 3 at 1:8", err.Error())
+
+	err = oj.ValidateReaderLimits(strings.NewReader(`{"abc":"defg"}`), 0, 4, 0)
+	tt.Nil(t, err)
+	err = oj.ValidateReaderLimits(strings.NewReader(`{"abc":"de\"fg"}`), 0, 4, 0)
+	tt.ErrorIs(t, err, ojg.ErrInputLimit)
+	err = oj.ValidateReaderLimits(strings.NewReader(`{"abcde":1}`), 0, 4, 0)
+	tt.ErrorIs(t, err, ojg.ErrInputLimit)
+	tt.Equal(t, "string longer than 4 bytes at 1:7", err.Error())
+
+	// A string that spans read buffers fails before the end is read.
+	r := tt.ShortReader{Max: 4096, Content: []byte(`["` + strings.Repeat("x", 10000) + `"]`)}
+	err = oj.ValidateReaderLimits(&r, 0, 100, 0)
+	tt.ErrorIs(t, err, ojg.ErrInputLimit)
+
+	err = oj.ValidateReaderLimits(strings.NewReader(`[1,2,3]`), 0, 0, 7)
+	tt.Nil(t, err)
+	err = oj.ValidateReaderLimits(strings.NewReader(`[1,2,3] `), 0, 0, 7)
+	tt.ErrorIs(t, err, ojg.ErrInputLimit)
+
+	v := oj.Validator{MaxSize: 5}
+	err = v.Validate([]byte(`[1,2,3]`))
+	tt.ErrorIs(t, err, ojg.ErrInputLimit)
+
+	// Limits do not hide syntax errors.
+	err = oj.ValidateReaderLimits(strings.NewReader(`[1,]`), 10, 10, 10)
+	tt.ErrorIs(t, err, ojg.ErrSyntax)
+}
@@ -3,20 +3,21 @@
 package oj
 
 import (
+	"context"
 	"encoding"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"reflect"
+	"slices"
 	"sort"
-	"strconv"
-	"strings"
 	"time"
 	"unsafe"
 
 	"github.com/ohler55/ojg"
 	"github.com/ohler55/ojg/alt"
+	"github.com/ohler55/ojg/jp"
 )
 
 const (
@@ -33,10 +34,47 @@ type Writer struct {
 	w             io.Writer
 	findex        byte
 	strict        bool
+	slowField     bool // string, float, and integer fields written with appendJSON
+	skipFields    bool // Groups or OmitZero set so every field is checked
+	redacting     bool // Redact has Keys to match
+	sorting       bool // Sort or StableMaps set
+	outStart      int  // start of the value in buf for the MaxOutputBytes option
+	flushed       int  // bytes of the value already written to w
 	appendArray   func(wr *Writer, data []any, depth int)
 	appendObject  func(wr *Writer, data map[string]any, depth int)
 	appendDefault func(wr *Writer, data any, depth int)
 	appendString  func(buf []byte, s string, htmlSafe bool) []byte
+	keyCache      map[uintptr][]string
+	keyFields     map[*finfo][]*finfo // fields with keys from the KeyFunc
+	redactMask    string
+	ctx           context.Context // checked periodically if not nil
+	ctxCount      int
+	encodeDepth   int // depth for AppendValue calls from an EncodeFunc
+	frames        []refFrame
+	level         int  // nesting of the reflected value being written
+	cycles        bool // track frames for cycles from the top level
+	search        bool // writing again to find the path to a cycle
+	root          any  // top level value searched for paths to cycles
+	filtering     bool // Include or Exclude set
+	path          pathState
+	holds         int // members that may still be removed so output is not flushed
+
+	// Include if not empty limits the values written to those that match at
+	// least one of the expressions along with the arrays and objects that
+	// contain them.
+	Include []jp.Expr
+
+	// Exclude removes the values that match any of the expressions from the
+	// values written.
+	Exclude []jp.Expr
+
+	// Redact if not nil replaces the values of object members and struct
+	// fields with keys that match one of the Redact Keys patterns with the
+	// Redact mask following the same rules as alt.Redactor.Redact. Struct
+	// fields with a redact json tag option such as `json:"password,redact"`
+	// are always replaced by the mask, or by alt.DefaultRedactMask if Redact
+	// is nil.
+	Redact *alt.Redactor
 }
 
 // JSON writes data, JSON encoded. On error, an empty string is returned.
@@ -60,34 +98,14 @@ func (wr *Writer) MustJSON(data any) []byte {
 		wr.InitSize = 256
 	}
 	if cap(wr.buf) < wr.InitSize {
-		wr.buf = make([]byte, 0, wr.InitSize)
+		ojg.Free(wr.Allocator, wr.buf)
+		wr.buf = ojg.Alloc(wr.Allocator, wr.InitSize)
 	} else {
 		wr.buf = wr.buf[:0]
 	}
-	wr.calcFieldsIndex()
-	if wr.Color {
-		wr.colorJSON(data, 0)
-	} else {
-		wr.appendString = ojg.AppendJSONString
-		if wr.Tab || 0 < wr.Indent {
-			wr.appendArray = appendArray
-			if wr.Sort {
-				wr.appendObject = appendSortObject
-			} else {
-				wr.appendObject = appendObject
-			}
-			wr.appendDefault = appendDefault
-		} else {
-			wr.appendArray = tightArray
-			if wr.Sort {
-				wr.appendObject = tightSortObject
-			} else {
-				wr.appendObject = tightObject
-			}
-			wr.appendDefault = tightDefault
-		}
-		wr.appendJSON(data, 0)
-	}
+	wr.prepare()
+	data = wr.view(data)
+	wr.appendValue(data, true)
 	return wr.buf
 }
 
@@ -114,39 +132,353 @@ func (wr *Writer) MustWrite(w io.Writer, data any) {
 		wr.WriteLimit = 1024
 	}
 	if cap(wr.buf) < wr.InitSize {
-		wr.buf = make([]byte, 0, wr.InitSize)
+		ojg.Free(wr.Allocator, wr.buf)
+		wr.buf = ojg.Alloc(wr.Allocator, wr.InitSize)
 	} else {
 		wr.buf = wr.buf[:0]
 	}
-	wr.calcFieldsIndex()
-	if wr.Color {
-		wr.colorJSON(data, 0)
+	wr.prepare()
+	data = wr.view(data)
+	wr.appendValue(data, true)
+	if 0 < len(wr.buf) {
+		if _, err := wr.w.Write(wr.buf); err != nil {
+			panic(err)
+		}
+		wr.buf = wr.buf[:0]
+	}
+}
+
+// WriteMany writes each of the values as newline delimited JSON (NDJSON). The
+// values can be a []any, a chan any, or any other slice, array, or channel
+// type. Channels are read until closed. Each value is written on a single
+// line regardless of the Indent and Tab options and the Writer buffer is
+// reused for all the values.
+func (wr *Writer) WriteMany(w io.Writer, values any) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			wr.buf = wr.buf[:0]
+			err = ojg.NewError(r)
+		}
+	}()
+	wr.MustWriteMany(w, values)
+	return
+}
+
+// MustWriteMany writes each of the values as newline delimited JSON
+// (NDJSON). If an error occurs panic is called with the error.
+func (wr *Writer) MustWriteMany(w io.Writer, values any) {
+	wr.w = w
+	if wr.WriteLimit <= 0 {
+		wr.WriteLimit = 1024
+	}
+	wr.appendMany(values)
+	if 0 < len(wr.buf) {
+		if _, err := wr.w.Write(wr.buf); err != nil {
+			panic(err)
+		}
+		wr.buf = wr.buf[:0]
+	}
+}
+
+func (wr *Writer) appendMany(values any) {
+	if wr.InitSize <= 0 {
+		wr.InitSize = 256
+	}
+	if cap(wr.buf) < wr.InitSize {
+		ojg.Free(wr.Allocator, wr.buf)
+		wr.buf = ojg.Alloc(wr.Allocator, wr.InitSize)
+	} else {
+		wr.buf = wr.buf[:0]
+	}
+	defer func(indent int, tab bool) {
+		wr.Indent = indent
+		wr.Tab = tab
+	}(wr.Indent, wr.Tab)
+	wr.Indent = 0
+	wr.Tab = false
+	wr.prepare()
+
+	switch tv := values.(type) {
+	case []any:
+		for _, v := range tv {
+			wr.appendLine(v)
+		}
+	case chan any:
+		for v := range tv {
+			wr.appendLine(v)
+		}
+	default:
+		rv := reflect.ValueOf(values)
+		switch rv.Kind() {
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < rv.Len(); i++ {
+				wr.appendLine(rv.Index(i).Interface())
+			}
+		case reflect.Chan:
+			for {
+				v, ok := rv.Recv()
+				if !ok {
+					break
+				}
+				wr.appendLine(v.Interface())
+			}
+		default:
+			wr.appendLine(values)
+		}
+	}
+}
+
+func (wr *Writer) appendLine(v any) {
+	wr.frames = wr.frames[:0]
+	wr.level = 0
+	clear(wr.keyCache)
+	v = wr.view(v)
+	wr.appendValue(v, false)
+	wr.buf = append(wr.buf, '\n')
+	if wr.w != nil && wr.WriteLimit < len(wr.buf) {
+		if _, err := wr.w.Write(wr.buf); err != nil {
+			panic(err)
+		}
+		wr.buf = wr.buf[:0]
+	}
+}
+
+// WriteChan writes each value received on the channel as newline delimited
+// JSON (NDJSON) until the channel is closed. Each value is written on a
+// single line regardless of the Indent and Tab options. Values are read one
+// at a time so a producer blocks once the channel buffer is full until the
+// writer catches up. Buffered output is written to w when it exceeds the
+// WriteLimit or when no more values are waiting on the channel. After each
+// write, if w has a Flush() or Flush() error method, such as a
+// *bufio.Writer or an http.Flusher, it is called so values are not held
+// back while the producer is idle.
+func (wr *Writer) WriteChan(w io.Writer, ch <-chan any) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			wr.buf = wr.buf[:0]
+			err = ojg.NewError(r)
+		}
+	}()
+	wr.MustWriteChan(w, ch)
+	return
+}
+
+// MustWriteChan writes each value received on the channel as NDJSON until
+// the channel is closed. If an error occurs panic is called with the error.
+func (wr *Writer) MustWriteChan(w io.Writer, ch <-chan any) {
+	wr.writeChan(w, ch, false)
+}
+
+// WriteChanArray writes the values received on the channel as the elements
+// of a JSON array that is closed when the channel is closed. The Indent and
+// Tab options are honored. Backpressure and flushing are the same as for
+// WriteChan.
+func (wr *Writer) WriteChanArray(w io.Writer, ch <-chan any) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			wr.buf = wr.buf[:0]
+			err = ojg.NewError(r)
+		}
+	}()
+	wr.MustWriteChanArray(w, ch)
+	return
+}
+
+// MustWriteChanArray writes the values received on the channel as a JSON
+// array. If an error occurs panic is called with the error.
+func (wr *Writer) MustWriteChanArray(w io.Writer, ch <-chan any) {
+	wr.writeChan(w, ch, true)
+}
+
+func (wr *Writer) writeChan(w io.Writer, ch <-chan any, array bool) {
+	wr.w = w
+	if wr.InitSize <= 0 {
+		wr.InitSize = 256
+	}
+	if wr.WriteLimit <= 0 {
+		wr.WriteLimit = 1024
+	}
+	if cap(wr.buf) < wr.InitSize {
+		ojg.Free(wr.Allocator, wr.buf)
+		wr.buf = ojg.Alloc(wr.Allocator, wr.InitSize)
 	} else {
-		wr.appendString = ojg.AppendJSONString
-		if wr.Tab || 0 < wr.Indent {
-			wr.appendArray = appendArray
-			if wr.Sort {
-				wr.appendObject = appendSortObject
+		wr.buf = wr.buf[:0]
+	}
+	var cs string
+	if array {
+		switch {
+		case wr.Tab:
+			cs = tabs[:2]
+		case 0 < wr.Indent:
+			x := wr.Indent + 1
+			if len(spaces) < x {
+				x = len(spaces)
+			}
+			cs = spaces[:x]
+		}
+		wr.buf = append(wr.buf, '[')
+	} else {
+		defer func(indent int, tab bool) {
+			wr.Indent = indent
+			wr.Tab = tab
+		}(wr.Indent, wr.Tab)
+		wr.Indent = 0
+		wr.Tab = false
+	}
+	wr.prepare()
+	empty := true
+	for v := range ch {
+		clear(wr.keyCache)
+		v = wr.view(v)
+		if array {
+			if !empty {
+				wr.buf = append(wr.buf, ',')
+			}
+			wr.buf = append(wr.buf, cs...)
+			if wr.Color {
+				wr.colorJSON(v, 1)
 			} else {
-				wr.appendObject = appendObject
+				wr.appendJSON(v, 1)
 			}
-			wr.appendDefault = appendDefault
 		} else {
-			wr.appendArray = tightArray
-			if wr.Sort {
-				wr.appendObject = tightSortObject
+			if wr.Color {
+				wr.colorJSON(v, 0)
 			} else {
-				wr.appendObject = tightObject
+				wr.appendJSON(v, 0)
 			}
-			wr.appendDefault = tightDefault
+			wr.buf = append(wr.buf, '\n')
 		}
-		wr.appendJSON(data, 0)
+		empty = false
+		if wr.WriteLimit < len(wr.buf) || len(ch) == 0 {
+			wr.mustFlush()
+		}
+	}
+	if array {
+		if !empty && 0 < len(cs) {
+			wr.buf = append(wr.buf, '\n')
+		}
+		wr.buf = append(wr.buf, ']')
+	}
+	wr.mustFlush()
+}
+
+// Flush writes any buffered output to the io.Writer of the most recent
+// Write, WriteMany, or WriteChan call and then calls the Flush() or Flush()
+// error method of that io.Writer if it has one, such as a *bufio.Writer or
+// an http.Flusher. While writing, output is passed to the io.Writer each
+// time the buffer grows past the WriteLimit option so a larger WriteLimit
+// means fewer writes and a smaller one means output is seen sooner.
+func (wr *Writer) Flush() (err error) {
+	if wr.w == nil {
+		return nil
 	}
 	if 0 < len(wr.buf) {
-		if _, err := wr.w.Write(wr.buf); err != nil {
-			panic(err)
+		_, err = wr.w.Write(wr.buf)
+		wr.buf = wr.buf[:0]
+		if err != nil {
+			return
 		}
 	}
+	switch tw := wr.w.(type) {
+	case interface{ Flush() error }:
+		err = tw.Flush()
+	case interface{ Flush() }:
+		tw.Flush()
+	}
+	return
+}
+
+// WriteTo writes the buffered output, such as the JSON returned by
+// MustJSON(), to w without copying it and then empties the buffer. On error
+// the output that was not written remains in the buffer. It implements the
+// io.WriterTo interface.
+func (wr *Writer) WriteTo(w io.Writer) (n int64, err error) {
+	if 0 < len(wr.buf) {
+		var cnt int
+		cnt, err = w.Write(wr.buf)
+		n = int64(cnt)
+		if err == nil {
+			wr.buf = wr.buf[:0]
+		} else {
+			wr.buf = wr.buf[:copy(wr.buf, wr.buf[cnt:])]
+		}
+	}
+	return
+}
+
+func (wr *Writer) mustFlush() {
+	if err := wr.Flush(); err != nil {
+		panic(err)
+	}
+}
+
+// prepare sets up the field index and the append functions according to the
+// current options.
+func (wr *Writer) prepare() {
+	wr.frames = wr.frames[:0]
+	wr.level = 0
+	wr.cycles = wr.CycleRefs || wr.search
+	wr.calcFieldsIndex()
+	clear(wr.keyFields)
+	wr.ApplyColorScheme()
+	clear(wr.keyCache)
+	wr.appendString = wr.StringAppender(false)
+	wr.redactMask = alt.DefaultRedactMask
+	wr.redacting = false
+	if wr.Redact != nil {
+		wr.redactMask = wr.Redact.MaskValue()
+		wr.redacting = 0 < len(wr.Redact.Keys)
+	}
+	wr.slowField = wr.CustomString() || wr.CustomFloat() || wr.BigIntAsString || 0 < wr.MaxStringLength
+	wr.skipFields = 0 < len(wr.Groups) || wr.OmitZero
+	wr.filtering = 0 < len(wr.Include) || 0 < len(wr.Exclude)
+	wr.holds = 0
+	wr.sorting = wr.Sort || wr.StableMaps
+	if wr.Tab || 0 < wr.Indent {
+		wr.appendArray = appendArray
+		if wr.sorting {
+			wr.appendObject = appendSortObject
+		} else {
+			wr.appendObject = appendObject
+		}
+		wr.appendDefault = appendDefault
+	} else {
+		wr.appendArray = tightArray
+		if wr.sorting {
+			wr.appendObject = tightSortObject
+		} else {
+			wr.appendObject = tightObject
+		}
+		wr.appendDefault = tightDefault
+	}
+	if wr.filtering {
+		wr.appendArray = pathArray
+		wr.appendObject = pathObject
+	}
+}
+
+// sortedKeys returns the sorted keys of a map. With the StableMaps option
+// the keys are cached by map identity until the next value is written.
+func (wr *Writer) sortedKeys(n map[string]any) []string {
+	var ptr uintptr
+	if wr.StableMaps {
+		ptr = reflect.ValueOf(n).Pointer()
+		if keys, has := wr.keyCache[ptr]; has {
+			return keys
+		}
+	}
+	keys := make([]string, 0, len(n))
+	for k := range n {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if wr.StableMaps {
+		if wr.keyCache == nil {
+			wr.keyCache = map[uintptr][]string{}
+		}
+		wr.keyCache[ptr] = keys
+	}
+	return keys
 }
 
 func (wr *Writer) calcFieldsIndex() {
@@ -164,7 +496,83 @@ func (wr *Writer) calcFieldsIndex() {
 	}
 }
 
+// structFields returns the fields of a struct in the order they are
+// written. With a KeyFunc the keys of fields not named by a json tag are
+// converted and the fields sorted again. The FieldOrder and FieldPriority
+// options also reorder the fields. Keys are escaped the same as string
+// values with the EscapeRunes, RawUnicode, and ASCIIOnly options. The converted fields are
+// cached until the options are next applied.
+func (wr *Writer) structFields(si *sinfo) []*finfo {
+	fields := si.fields[wr.findex]
+	if (wr.KeyFunc == nil && !wr.escapeKeys() && !wr.reorderFields()) || len(fields) == 0 {
+		return fields
+	}
+	if kf, has := wr.keyFields[fields[0]]; has {
+		return kf
+	}
+	kf := make([]*finfo, len(fields))
+	for i, fi := range fields {
+		if fi.tagged && !wr.escapeKeys() {
+			kf[i] = fi
+			continue
+		}
+		cf := *fi
+		if wr.KeyFunc != nil && !fi.tagged {
+			cf.key = wr.KeyFunc(fi.name)
+		}
+		cf.jkey = wr.appendString(nil, cf.key, false)
+		cf.jkey = append(cf.jkey, ':')
+		if fi.jkey[len(fi.jkey)-1] == ' ' { // pretty
+			cf.jkey = append(cf.jkey, ' ')
+		}
+		kf[i] = &cf
+	}
+	wr.sortFields(kf)
+	if wr.keyFields == nil {
+		wr.keyFields = map[*finfo][]*finfo{}
+	}
+	wr.keyFields[fields[0]] = kf
+	return kf
+}
+
+// escapeKeys returns true if the EscapeRunes, RawUnicode, or ASCIIOnly
+// options change how struct field keys are written.
+func (wr *Writer) escapeKeys() bool {
+	return 0 < len(wr.EscapeRunes) || wr.RawUnicode || wr.ASCIIOnly
+}
+
+// reorderFields returns true if the FieldOrder or FieldPriority options
+// change the order of struct fields from the order they are cached in.
+func (wr *Writer) reorderFields() bool {
+	return wr.FieldOrder != ojg.FieldOrderKey || 0 < len(wr.FieldPriority)
+}
+
+// sortFields sorts fields by key or by declaration order with the fields
+// named in FieldPriority first.
+func (wr *Writer) sortFields(fields []*finfo) {
+	priority := func(key string) int {
+		for i, k := range wr.FieldPriority {
+			if k == key {
+				return i
+			}
+		}
+		return len(wr.FieldPriority)
+	}
+	sort.SliceStable(fields, func(i, j int) bool {
+		if pi, pj := priority(fields[i].key), priority(fields[j].key); pi != pj {
+			return pi < pj
+		}
+		if wr.FieldOrder == ojg.FieldOrderDeclared {
+			return slices.Compare(fields[i].index, fields[j].index) < 0
+		}
+		return fields[i].key < fields[j].key
+	})
+}
+
 func (wr *Writer) appendJSON(data any, depth int) {
+	if 0 < len(typeEncoders) && wr.encode(data, depth) {
+		goto Written
+	}
 	switch td := data.(type) {
 	case nil:
 		wr.buf = append(wr.buf, "null"...)
@@ -177,41 +585,33 @@ func (wr *Writer) appendJSON(data any, depth int) {
 		}
 
 	case int:
-		wr.buf = strconv.AppendInt(wr.buf, int64(td), 10)
+		wr.buf = wr.AppendInt(wr.buf, int64(td))
 	case int8:
-		wr.buf = strconv.AppendInt(wr.buf, int64(td), 10)
+		wr.buf = wr.AppendInt(wr.buf, int64(td))
 	case int16:
-		wr.buf = strconv.AppendInt(wr.buf, int64(td), 10)
+		wr.buf = wr.AppendInt(wr.buf, int64(td))
 	case int32:
-		wr.buf = strconv.AppendInt(wr.buf, int64(td), 10)
+		wr.buf = wr.AppendInt(wr.buf, int64(td))
 	case int64:
-		wr.buf = strconv.AppendInt(wr.buf, td, 10)
+		wr.buf = wr.AppendInt(wr.buf, td)
 	case uint:
-		wr.buf = strconv.AppendUint(wr.buf, uint64(td), 10)
+		wr.buf = wr.AppendUint(wr.buf, uint64(td))
 	case uint8:
-		wr.buf = strconv.AppendUint(wr.buf, uint64(td), 10)
+		wr.buf = wr.AppendUint(wr.buf, uint64(td))
 	case uint16:
-		wr.buf = strconv.AppendUint(wr.buf, uint64(td), 10)
+		wr.buf = wr.AppendUint(wr.buf, uint64(td))
 	case uint32:
-		wr.buf = strconv.AppendUint(wr.buf, uint64(td), 10)
+		wr.buf = wr.AppendUint(wr.buf, uint64(td))
 	case uint64:
-		wr.buf = strconv.AppendUint(wr.buf, td, 10)
+		wr.buf = wr.AppendUint(wr.buf, td)
 
 	case float32:
-		if 0 < len(wr.FloatFormat) {
-			wr.buf = fmt.Appendf(wr.buf, wr.FloatFormat, float64(td))
-		} else {
-			wr.buf = strconv.AppendFloat(wr.buf, float64(td), 'g', -1, 32)
-		}
+		wr.buf = wr.AppendFloat(wr.buf, float64(td), 32)
 	case float64:
-		if 0 < len(wr.FloatFormat) {
-			wr.buf = fmt.Appendf(wr.buf, wr.FloatFormat, td)
-		} else {
-			wr.buf = strconv.AppendFloat(wr.buf, td, 'g', -1, 64)
-		}
+		wr.buf = wr.AppendFloat(wr.buf, td, 64)
 
 	case string:
-		wr.buf = wr.appendString(wr.buf, td, !wr.HTMLUnsafe)
+		wr.buf = wr.appendString(wr.buf, wr.clipString(td), !wr.HTMLUnsafe)
 
 	case []byte:
 		switch wr.BytesAs {
@@ -233,25 +633,38 @@ func (wr *Writer) appendJSON(data any, depth int) {
 	case []any:
 		// go marshal treats a nil slice as a special case different from an
 		// empty slice. Seems kind of odd but here is the check.
-		if wr.strict && td == nil {
-			wr.buf = append(wr.buf, "null"...)
+		if td == nil && wr.appendNilCollection(wr.strict) {
+			break
+		}
+		if wr.truncated() {
+			wr.appendMarker(depth)
 			break
 		}
+		wr.level++
 		wr.appendArray(wr, td, depth)
+		wr.level--
 
 	case map[string]any:
+		if td == nil && wr.appendNilCollection(false) {
+			break
+		}
+		if wr.truncated() {
+			wr.appendMarker(depth)
+			break
+		}
+		wr.level++
 		wr.appendObject(wr, td, depth)
+		wr.level--
+
+	case *OMap:
+		wr.appendOMap(td, depth)
 
 	case alt.Simplifier:
 		wr.appendJSON(td.Simplify(), depth)
 	case alt.Genericer:
 		wr.appendJSON(td.Generic().Simplify(), depth)
 	case json.Marshaler:
-		out, err := td.MarshalJSON()
-		if err != nil {
-			panic(err)
-		}
-		wr.buf = append(wr.buf, out...)
+		wr.buf = appendMarshaled(wr.buf, td)
 	case encoding.TextMarshaler:
 		out, err := td.MarshalText()
 		if err != nil {
@@ -262,10 +675,19 @@ func (wr *Writer) appendJSON(data any, depth int) {
 	default:
 		wr.appendDefault(wr, data, depth)
 	}
-	if wr.w != nil && wr.WriteLimit < len(wr.buf) {
+Written:
+	if 0 < wr.MaxOutputBytes {
+		wr.checkOutput()
+	}
+	if wr.ctx != nil {
+		wr.checkContext()
+	}
+	if wr.w != nil && wr.WriteLimit < len(wr.buf) && wr.holds == 0 {
 		if _, err := wr.w.Write(wr.buf); err != nil {
 			panic(err)
 		}
+		wr.flushed += len(wr.buf) - wr.outStart
+		wr.outStart = 0
 		wr.buf = wr.buf[:0]
 	}
 }
@@ -278,6 +700,9 @@ func appendDefault(wr *Writer, data any, depth int) {
 		if kind == reflect.Ptr {
 			rv = rv.Elem()
 			kind = rv.Kind()
+			if wr.encodeValue(rv, depth) {
+				return
+			}
 		}
 		switch kind {
 		case reflect.Struct:
@@ -287,17 +712,29 @@ func appendDefault(wr *Writer, data any, depth int) {
 		case reflect.Map:
 			wr.appendMap(rv, depth, nil)
 		case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+			if kind == reflect.Func && wr.appendSeq(rv, depth) {
+				return
+			}
+			if v, ok := wr.Fallback(data); ok {
+				wr.appendJSON(v, depth)
+				return
+			}
 			if wr.strict {
-				panic(fmt.Errorf("%T can not be encoded as a JSON element", data))
+				panic(ojg.Errorf(ojg.ErrUnsupportedType, "%T can not be encoded as a JSON element", data))
 			}
 			wr.buf = append(wr.buf, "null"...)
 		default:
 			dec := alt.Decompose(data, &wr.Options)
 			wr.appendJSON(dec, depth)
 		}
-	case wr.strict:
-		panic(fmt.Errorf("%T can not be encoded as a JSON element", data))
 	default:
+		if v, ok := wr.Fallback(data); ok {
+			wr.appendJSON(v, depth)
+			return
+		}
+		if wr.strict {
+			panic(ojg.Errorf(ojg.ErrUnsupportedType, "%T can not be encoded as a JSON element", data))
+		}
 		wr.buf = wr.appendString(wr.buf, fmt.Sprintf("%v", data), !wr.HTMLUnsafe)
 	}
 }
@@ -374,6 +811,9 @@ func appendObject(wr *Writer, n map[string]any, depth int) {
 	empty := true
 	wr.buf = append(wr.buf, '{')
 	for k, m := range n {
+		if wr.redactKey(k) {
+			m = wr.redactMask
+		}
 		switch tm := m.(type) {
 		case nil:
 			if wr.OmitNil {
@@ -394,7 +834,7 @@ func appendObject(wr *Writer, n map[string]any, depth int) {
 		}
 		empty = false
 		wr.buf = append(wr.buf, cs...)
-		wr.buf = wr.appendString(wr.buf, k, !wr.HTMLUnsafe)
+		wr.buf = wr.appendString(wr.buf, wr.keyFor(k), !wr.HTMLUnsafe)
 		wr.buf = append(wr.buf, ':')
 		wr.buf = append(wr.buf, ' ')
 		wr.appendJSON(m, d2)
@@ -434,15 +874,14 @@ func appendSortObject(wr *Writer, n map[string]any, depth int) {
 		}
 		cs = spaces[0:x]
 	}
-	keys := make([]string, 0, len(n))
-	for k := range n {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
+	keys := wr.sortedKeys(n)
 	empty := true
 	wr.buf = append(wr.buf, '{')
 	for _, k := range keys {
 		m := n[k]
+		if wr.redactKey(k) {
+			m = wr.redactMask
+		}
 		switch tm := m.(type) {
 		case nil:
 			if wr.OmitNil {
@@ -463,7 +902,7 @@ func appendSortObject(wr *Writer, n map[string]any, depth int) {
 		}
 		empty = false
 		wr.buf = append(wr.buf, cs...)
-		wr.buf = wr.appendString(wr.buf, k, !wr.HTMLUnsafe)
+		wr.buf = wr.appendString(wr.buf, wr.keyFor(k), !wr.HTMLUnsafe)
 		wr.buf = append(wr.buf, ':')
 		wr.buf = append(wr.buf, ' ')
 		wr.appendJSON(m, d2)
@@ -477,11 +916,25 @@ func appendSortObject(wr *Writer, n map[string]any, depth int) {
 }
 
 func (wr *Writer) appendStruct(rv reflect.Value, depth int, si *sinfo) {
+	if wr.encodeValue(rv, depth) {
+		return
+	}
+	if wr.ctx != nil {
+		wr.checkContext()
+	}
 	if si == nil {
 		si = getSinfo(rv.Interface(), wr.OmitEmpty)
 	}
+	if !wr.enter(rv, depth) {
+		return
+	}
+	if wr.filtering && !wr.path.whole {
+		wr.pathStruct(rv, depth, si)
+		wr.leave()
+		return
+	}
 	d2 := depth + 1
-	fields := si.fields[wr.findex]
+	fields := wr.structFields(si)
 	wr.buf = append(wr.buf, '{')
 	empty := true
 	var v any
@@ -536,9 +989,21 @@ func (wr *Writer) appendStruct(rv reflect.Value, depth int, si *sinfo) {
 			wr.buf = append(wr.buf, cs...)
 			indented = true
 		}
-		if 0 < addr {
+		if (fi.skippable || wr.skipFields) && wr.skipField(fi, rv) {
+			continue
+		}
+		if wr.cycles {
+			wr.stepField(fi)
+		}
+		switch {
+		case fi.redact || wr.redactKey(fi.key):
+			wr.buf = append(wr.buf, fi.jkey...)
+			v, stat = wr.redactMask, aChanged
+		case wr.slowField && fi.sAppend != nil:
+			wr.buf, v, stat = fi.sAppend(fi, wr.buf, rv, addr, !wr.HTMLUnsafe)
+		case 0 < addr:
 			wr.buf, v, stat = fi.Append(fi, wr.buf, rv, addr, !wr.HTMLUnsafe)
-		} else {
+		default:
 			wr.buf, v, stat = fi.iAppend(fi, wr.buf, rv, addr, !wr.HTMLUnsafe)
 		}
 		switch stat {
@@ -614,12 +1079,66 @@ func (wr *Writer) appendStruct(rv reflect.Value, depth int, si *sinfo) {
 		wr.buf = append(wr.buf, is...)
 	}
 	wr.buf = append(wr.buf, '}')
+	wr.leave()
+}
+
+// appendBase64 writes a reflected byte slice as a base64 string, or null
+// if nil, when the BytesAs option is BytesAsBase64 to match encoding/json.
+// False is returned if the value was not written.
+func (wr *Writer) appendBase64(rv reflect.Value) bool {
+	if wr.BytesAs != ojg.BytesAsBase64 || rv.Kind() != reflect.Slice || rv.Type().Elem().Kind() != reflect.Uint8 {
+		return false
+	}
+	switch {
+	case !rv.IsNil():
+		wr.buf = wr.appendString(wr.buf, base64.StdEncoding.EncodeToString(rv.Bytes()), !wr.HTMLUnsafe)
+	case wr.NilCollections == ojg.NilAsEmpty:
+		wr.buf = append(wr.buf, `""`...)
+	default:
+		wr.buf = append(wr.buf, "null"...)
+	}
+	return true
+}
+
+// appendNilCollection writes null for a nil slice or map if called for by
+// the NilCollections option and returns true if written. The nullDefault
+// argument is the behavior for NilAsDefault.
+func (wr *Writer) appendNilCollection(nullDefault bool) bool {
+	switch wr.NilCollections {
+	case ojg.NilAsNull:
+	case ojg.NilAsEmpty:
+		return false
+	default:
+		if !nullDefault {
+			return false
+		}
+	}
+	wr.buf = append(wr.buf, "null"...)
+	return true
 }
 
 func (wr *Writer) appendSlice(rv reflect.Value, depth int, si *sinfo) {
+	if wr.encodeValue(rv, depth) {
+		return
+	}
+	if wr.appendBase64(rv) {
+		return
+	}
+	if rv.Kind() == reflect.Slice && rv.IsNil() && wr.appendNilCollection(false) {
+		return
+	}
+	if !wr.enter(rv, depth) {
+		return
+	}
+	if wr.filtering && !wr.path.whole {
+		wr.pathSlice(rv, depth)
+		wr.leave()
+		return
+	}
 	end := rv.Len()
 	if end == 0 {
 		wr.buf = append(wr.buf, "[]"...)
+		wr.leave()
 		return
 	}
 	d2 := depth + 1
@@ -649,9 +1168,18 @@ func (wr *Writer) appendSlice(rv reflect.Value, depth int, si *sinfo) {
 		cs = spaces[0:x]
 	}
 	wr.buf = append(wr.buf, '[')
+	mk := marshalKind(rv.Type().Elem())
 	for j := 0; j < end; j++ {
+		if wr.cycles {
+			wr.stepIndex(j)
+		}
 		wr.buf = append(wr.buf, cs...)
 		rm := rv.Index(j)
+		if m := reflectMarshaler(rm, mk); m != nil {
+			wr.buf = appendMarshaled(wr.buf, m)
+			wr.buf = append(wr.buf, ',')
+			continue
+		}
 		switch rm.Kind() {
 		case reflect.Struct:
 			wr.appendStruct(rm, d2, si)
@@ -667,13 +1195,25 @@ func (wr *Writer) appendSlice(rv reflect.Value, depth int, si *sinfo) {
 	wr.buf[len(wr.buf)-1] = '\n'
 	wr.buf = append(wr.buf, is...)
 	wr.buf = append(wr.buf, ']')
+	wr.leave()
 }
 
 func (wr *Writer) appendMap(rv reflect.Value, depth int, si *sinfo) {
-	keys := rv.MapKeys()
-	if wr.Sort {
-		sort.Slice(keys, func(i, j int) bool { return 0 > strings.Compare(keys[i].String(), keys[j].String()) })
+	if wr.encodeValue(rv, depth) {
+		return
+	}
+	if rv.IsNil() && wr.appendNilCollection(false) {
+		return
+	}
+	if !wr.enter(rv, depth) {
+		return
+	}
+	if wr.filtering && !wr.path.whole {
+		wr.pathMap(rv, depth)
+		wr.leave()
+		return
 	}
+	keys, names := alt.MapKeys(rv, wr.sorting)
 	d2 := depth + 1
 	var is string
 	var cs string
@@ -702,8 +1242,32 @@ func (wr *Writer) appendMap(rv reflect.Value, depth int, si *sinfo) {
 	}
 	empty := true
 	wr.buf = append(wr.buf, '{')
-	for _, kv := range keys {
+	vk := marshalKind(rv.Type().Elem())
+	for i, kv := range keys {
+		var key string
+		if names == nil {
+			key = kv.String()
+		} else {
+			key = names[i]
+		}
 		rm := rv.MapIndex(kv)
+		if wr.cycles {
+			wr.stepKey(key, rm)
+		}
+		mk := vk
+		if wr.redactKey(key) {
+			rm = reflect.ValueOf(wr.redactMask)
+			mk = marshalNone
+		}
+		if m := reflectMarshaler(rm, mk); m != nil {
+			wr.buf = append(wr.buf, cs...)
+			wr.buf = wr.appendString(wr.buf, wr.keyFor(key), !wr.HTMLUnsafe)
+			wr.buf = append(wr.buf, ": "...)
+			wr.buf = appendMarshaled(wr.buf, m)
+			wr.buf = append(wr.buf, ',')
+			empty = false
+			continue
+		}
 		if rm.Kind() == reflect.Ptr {
 			if rm.IsNil() {
 				if wr.OmitNil {
@@ -716,7 +1280,7 @@ func (wr *Writer) appendMap(rv reflect.Value, depth int, si *sinfo) {
 		switch rm.Kind() {
 		case reflect.Struct:
 			wr.buf = append(wr.buf, cs...)
-			wr.buf = wr.appendString(wr.buf, kv.String(), !wr.HTMLUnsafe)
+			wr.buf = wr.appendString(wr.buf, wr.keyFor(key), !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, ": "...)
 			wr.appendStruct(rm, d2, si)
 		case reflect.Slice, reflect.Array:
@@ -724,7 +1288,7 @@ func (wr *Writer) appendMap(rv reflect.Value, depth int, si *sinfo) {
 				continue
 			}
 			wr.buf = append(wr.buf, cs...)
-			wr.buf = wr.appendString(wr.buf, kv.String(), !wr.HTMLUnsafe)
+			wr.buf = wr.appendString(wr.buf, wr.keyFor(key), !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, ": "...)
 			wr.appendSlice(rm, d2, si)
 		case reflect.Map:
@@ -732,7 +1296,7 @@ func (wr *Writer) appendMap(rv reflect.Value, depth int, si *sinfo) {
 				continue
 			}
 			wr.buf = append(wr.buf, cs...)
-			wr.buf = wr.appendString(wr.buf, kv.String(), !wr.HTMLUnsafe)
+			wr.buf = wr.appendString(wr.buf, wr.keyFor(key), !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, ": "...)
 			wr.appendMap(rm, d2, si)
 		case reflect.String:
@@ -740,12 +1304,12 @@ func (wr *Writer) appendMap(rv reflect.Value, depth int, si *sinfo) {
 				continue
 			}
 			wr.buf = append(wr.buf, cs...)
-			wr.buf = wr.appendString(wr.buf, kv.String(), !wr.HTMLUnsafe)
+			wr.buf = wr.appendString(wr.buf, wr.keyFor(key), !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, ": "...)
 			wr.appendJSON(rm.Interface(), d2)
 		default:
 			wr.buf = append(wr.buf, cs...)
-			wr.buf = wr.appendString(wr.buf, kv.String(), !wr.HTMLUnsafe)
+			wr.buf = wr.appendString(wr.buf, wr.keyFor(key), !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, ": "...)
 			wr.appendJSON(rm.Interface(), d2)
 		}
@@ -757,4 +1321,26 @@ func (wr *Writer) appendMap(rv reflect.Value, depth int, si *sinfo) {
 		wr.buf = append(wr.buf, is...)
 	}
 	wr.buf = append(wr.buf, '}')
+	wr.leave()
+}
+
+// redactKey returns true if the value for the key should be replaced by the
+// Redact mask.
+func (wr *Writer) redactKey(key string) bool {
+	return wr.redacting && wr.Redact.Match(key)
+}
+
+// keyFor returns the key to write for an object key according to the
+// BlankKeys option.
+func (wr *Writer) keyFor(key string) string {
+	if wr.BlankKeys == ojg.BlankKeyAllow || !ojg.IsBlankKey(key) {
+		return key
+	}
+	if wr.BlankKeys == ojg.BlankKeyError {
+		panic(ojg.Errorf(ojg.ErrBlankKey, "blank key %q", key))
+	}
+	if 0 < len(wr.BlankKeyName) {
+		return wr.BlankKeyName
+	}
+	return ojg.DefaultBlankKeyName
 }
@@ -3,14 +3,19 @@
 package oj_test
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/ohler55/ojg"
+	"github.com/ohler55/ojg/alt"
 	"github.com/ohler55/ojg/gen"
 	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/sen"
 	"github.com/ohler55/ojg/tt"
 )
 
@@ -941,12 +946,23 @@ type Marsha struct {
 }
 
 func (m *Marsha) MarshalJSON() ([]byte, error) {
-	if m.val == 5 {
+	switch m.val {
+	case 5:
 		return nil, fmt.Errorf("oops")
+	case 7:
+		return []byte(`{"v":`), nil
 	}
 	return []byte(fmt.Sprintf(`{"v":%d}`, m.val)), nil
 }
 
+type Money struct {
+	Cents int
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf(`"$%d.%02d"`, m.Cents/100, m.Cents%100)), nil
+}
+
 func TestMarshalMarshaler(t *testing.T) {
 	j, err := oj.Marshal(&Marsha{val: 3})
 	tt.Nil(t, err)
@@ -954,6 +970,34 @@ func TestMarshalMarshaler(t *testing.T) {
 
 	_, err = oj.Marshal(&Marsha{val: 5})
 	tt.NotNil(t, err)
+
+	_, err = oj.Marshal(&Marsha{val: 7})
+	tt.NotNil(t, err)
+	tt.Equal(t, true, strings.Contains(err.Error(), "MarshalJSON returned invalid JSON"), err.Error())
+}
+
+func TestMarshalMarshalerElements(t *testing.T) {
+	for _, indent := range []int{0, 2} {
+		opt := oj.Options{Indent: indent, Sort: true}
+		j, err := oj.Marshal([]Money{{Cents: 150}, {Cents: 2}}, &opt)
+		tt.Nil(t, err)
+		tt.Equal(t, `["$1.50","$0.02"]`, oj.JSON(oj.MustParse(j), &oj.Options{Sort: true}), indent)
+
+		j, err = oj.Marshal(map[string]Money{"a": {Cents: 150}}, &opt)
+		tt.Nil(t, err)
+		tt.Equal(t, `{"a":"$1.50"}`, oj.JSON(oj.MustParse(j), &oj.Options{Sort: true}), indent)
+
+		j, err = oj.Marshal([]Marsha{{val: 1}, {val: 2}}, &opt)
+		tt.Nil(t, err)
+		tt.Equal(t, `[{"v":1},{"v":2}]`, oj.JSON(oj.MustParse(j), &oj.Options{Sort: true}), indent)
+
+		j, err = oj.Marshal(map[string]*Marsha{"x": {val: 4}}, &opt)
+		tt.Nil(t, err)
+		tt.Equal(t, `{"x":{"v":4}}`, oj.JSON(oj.MustParse(j), &oj.Options{Sort: true}), indent)
+
+		_, err = oj.Marshal([]Marsha{{val: 7}}, &opt)
+		tt.NotNil(t, err, indent)
+	}
 }
 
 type TM struct {
@@ -1000,6 +1044,83 @@ func TestWriteFloatFormat(t *testing.T) {
 	tt.Equal(t, `01.23`, string(j))
 }
 
+func TestWriteFloatOptions(t *testing.T) {
+	type Reading struct {
+		Temp   float64 `json:"temp"`
+		Scale  float32 `json:"scale"`
+		Offset float64 `json:"offset,omitempty"`
+	}
+	r := Reading{Temp: 21, Scale: 0.5}
+	for _, d := range []struct {
+		opt    oj.Options
+		expect string
+	}{
+		{opt: oj.Options{}, expect: `{"scale":0.5,"temp":21}`},
+		{opt: oj.Options{FloatDecimal: true}, expect: `{"scale":0.5,"temp":21.0}`},
+		{opt: oj.Options{FloatVerb: 'f', FloatPrecision: 2}, expect: `{"scale":0.50,"temp":21.00}`},
+		{opt: oj.Options{FloatVerb: 'e', FloatPrecision: 1}, expect: `{"scale":5.0e-01,"temp":2.1e+01}`},
+		{opt: oj.Options{FloatVerb: 'f', FloatDecimal: true}, expect: `{"scale":0.5,"temp":21.0}`},
+		{opt: oj.Options{FloatFormat: "%.0f", FloatDecimal: true}, expect: `{"scale":0.0,"temp":21.0}`},
+	} {
+		d.opt.UseTags = true
+		out, err := oj.Marshal(&r, &d.opt)
+		tt.Nil(t, err)
+		tt.Equal(t, d.expect, string(out), d.opt)
+		out, err = oj.Marshal(r, &d.opt)
+		tt.Nil(t, err)
+		tt.Equal(t, d.expect, string(out), d.opt)
+	}
+	out, err := oj.Marshal(&Reading{Temp: 21, Scale: 0.5, Offset: 1.5}, &oj.Options{FloatDecimal: true, UseTags: true, Indent: 2})
+	tt.Nil(t, err)
+	tt.Equal(t, `{
+  "offset": 1.5,
+  "scale": 0.5,
+  "temp": 21.0
+}`, string(out))
+
+	opt := oj.Options{FloatDecimal: true, Indent: 2}
+	tt.Equal(t, "[\n  -3.0,\n  1e+21,\n  2.5\n]", oj.JSON([]any{-3.0, 1e21, float32(2.5)}, &opt))
+	tt.Equal(t, `{"x":7.0}`, oj.JSON(map[string]any{"x": 7.0}, &oj.Options{FloatDecimal: true}))
+
+	v, err := oj.ParseString(oj.JSON([]any{1.0, 2}, &oj.Options{FloatDecimal: true}))
+	tt.Nil(t, err)
+	tt.Equal(t, []any{1.0, int64(2)}, v)
+}
+
+func TestWriteAsNumber(t *testing.T) {
+	type Price struct {
+		Amount   string `json:"amount" ojg:"asnumber"`
+		Discount string `json:"discount,omitempty" ojg:"asnumber"`
+		Currency string `json:"currency"`
+	}
+	opt := oj.Options{UseTags: true}
+	out, err := oj.Marshal(&Price{Amount: "12.50", Currency: "USD"}, &opt)
+	tt.Nil(t, err)
+	tt.Equal(t, `{"amount":12.50,"currency":"USD"}`, string(out))
+
+	out, err = oj.Marshal(Price{Amount: "-1e3", Discount: "0.1", Currency: "USD"}, &opt)
+	tt.Nil(t, err)
+	tt.Equal(t, `{"amount":-1e3,"currency":"USD","discount":0.1}`, string(out))
+
+	opt.Indent = 2
+	out, err = oj.Marshal(&Price{Amount: "7"}, &opt)
+	tt.Nil(t, err)
+	tt.Equal(t, `{
+  "amount": 7,
+  "currency": ""
+}`, string(out))
+
+	for _, bad := range []string{"", "abc", "01", "1.", ".5", "1e", "+1", "1.5x", "NaN"} {
+		_, err = oj.Marshal(&Price{Amount: bad}, &oj.Options{UseTags: true})
+		tt.NotNil(t, err, bad)
+	}
+	for _, good := range []string{"0", "-0", "0.5", "10", "1E+2", "1e-07", "123456789012345678901234567890"} {
+		out, err = oj.Marshal(&Price{Amount: good}, &oj.Options{UseTags: true})
+		tt.Nil(t, err, good)
+		tt.Equal(t, `{"amount":`+good+`,"currency":""}`, string(out))
+	}
+}
+
 func BenchmarkMarshalFlat(b *testing.B) {
 	m := Mix{
 		Val:   1,
@@ -1067,3 +1188,611 @@ func TestWriteDev(t *testing.T) {
 	s := oj.JSON(data, &opt)
 	tt.Equal(t, `{}`, s)
 }
+
+func TestWriteEscapeRunes(t *testing.T) {
+	type Word struct {
+		Word string
+	}
+	opt := oj.Options{Sort: true, EscapeRunes: []ojg.RuneRange{{Min: 0x80, Max: 0xff}}}
+	data := map[string]any{"cafe": "caf\u00e9", "line": "a\u2028b"}
+	tt.Equal(t, `{"cafe":"caf\u00e9","line":"a\u2028b"}`, oj.JSON(data, &opt))
+
+	opt.Indent = 2
+	tt.Equal(t, `{
+  "cafe": "caf\u00e9",
+  "line": "a\u2028b"
+}`, oj.JSON(data, &opt))
+
+	opt.Indent = 0
+	w := Word{Word: "caf\u00e9"}
+	tt.Equal(t, `{"word":"caf\u00e9"}`, oj.JSON(&w, &opt))
+	tt.Equal(t, `{"word":"caf\u00e9"}`, oj.JSON(w, &opt))
+	opt.Indent = 2
+	tt.Equal(t, `{
+  "word": "caf\u00e9"
+}`, oj.JSON(&w, &opt))
+
+	opt = oj.Options{Sort: true, RawUnicode: true}
+	tt.Equal(t, "{\"cafe\":\"caf\u00e9\",\"line\":\"a\u2028b\"}", oj.JSON(data, &opt))
+
+	type Tagged struct {
+		Cafe string `json:"caf\u00e9"`
+	}
+	opt = oj.Options{UseTags: true, EscapeRunes: []ojg.RuneRange{{Min: 0x80, Max: 0xff}}}
+	tt.Equal(t, `{"caf\u00e9":"caf\u00e9"}`, oj.JSON(&Tagged{Cafe: "caf\u00e9"}, &opt))
+	tt.Equal(t, `{"caf\u00e9":"x"}`, oj.JSON(map[string]any{"caf\u00e9": "x"}, &opt))
+	tt.Equal(t, "{\"caf\u00e9\":\"x\"}", oj.JSON(&Tagged{Cafe: "x"}, &oj.Options{UseTags: true}))
+}
+
+func TestWriteASCIIOnly(t *testing.T) {
+	type Word struct {
+		Word string
+	}
+	opt := oj.Options{Sort: true, ASCIIOnly: true}
+	data := map[string]any{"caf\u00e9": []any{"\u03bb \U0001f600", "a\u2028b\u007f"}}
+	tt.Equal(t, `{"caf\u00e9":["\u03bb \ud83d\ude00","a\u2028b\u007f"]}`, oj.JSON(data, &opt))
+
+	w := Word{Word: "\U0001f600"}
+	tt.Equal(t, `{"word":"\ud83d\ude00"}`, oj.JSON(&w, &opt))
+	opt.Indent = 2
+	tt.Equal(t, `{
+  "word": "\ud83d\ude00"
+}`, oj.JSON(w, &opt))
+
+	v, err := oj.ParseString(oj.JSON(data, &opt))
+	tt.Nil(t, err)
+	tt.Equal(t, data, v)
+}
+
+func TestMarshalMany(t *testing.T) {
+	out, err := oj.MarshalMany([]any{1, "two", map[string]any{"x": []any{true, nil}}}, 2)
+	tt.Nil(t, err)
+	tt.Equal(t, "1\n\"two\"\n{\"x\":[true,null]}\n", string(out))
+
+	out, err = oj.MarshalMany([]*Dummy{{Val: 1}, {Val: 2}})
+	tt.Nil(t, err)
+	tt.Equal(t, "{\"Val\":1}\n{\"Val\":2}\n", string(out))
+
+	out, err = oj.MarshalMany([]any{})
+	tt.Nil(t, err)
+	tt.Equal(t, "", string(out))
+
+	_, err = oj.MarshalMany([]any{1, func() {}})
+	tt.NotNil(t, err)
+}
+
+func TestWriteMany(t *testing.T) {
+	ch := make(chan any, 3)
+	ch <- 1
+	ch <- []any{2, 3}
+	ch <- map[string]any{"four": 4}
+	close(ch)
+
+	var b strings.Builder
+	wr := oj.Writer{Options: oj.Options{Indent: 2, WriteLimit: 4}}
+	err := wr.WriteMany(&b, ch)
+	tt.Nil(t, err)
+	tt.Equal(t, "1\n[2,3]\n{\"four\":4}\n", b.String())
+	tt.Equal(t, 2, wr.Indent)
+
+	b.Reset()
+	ich := make(chan int, 2)
+	ich <- 5
+	ich <- 6
+	close(ich)
+	err = oj.WriteMany(&b, ich)
+	tt.Nil(t, err)
+	tt.Equal(t, "5\n6\n", b.String())
+
+	b.Reset()
+	err = oj.WriteMany(&b, [2]string{"a", "b"}, &oj.Options{})
+	tt.Nil(t, err)
+	tt.Equal(t, "\"a\"\n\"b\"\n", b.String())
+
+	err = oj.WriteMany(&shortWriter{max: 3}, []any{1, 2, 3})
+	tt.NotNil(t, err)
+}
+
+type flushWriter struct {
+	strings.Builder
+	flushed []string
+}
+
+func (w *flushWriter) Flush() error {
+	w.flushed = append(w.flushed, w.String())
+	return nil
+}
+
+func TestMarshalUnsupportedType(t *testing.T) {
+	_, err := oj.Marshal([]any{func() {}})
+	tt.ErrorIs(t, err, ojg.ErrUnsupportedType)
+
+	_, err = oj.Marshal(&Marsha{val: 7})
+	tt.ErrorIs(t, err, ojg.ErrSyntax)
+}
+
+func TestWriteChan(t *testing.T) {
+	ch := make(chan any)
+	var fw flushWriter
+	done := make(chan error)
+	wr := oj.Writer{Options: oj.Options{Indent: 2}}
+	go func() { done <- wr.WriteChan(&fw, ch) }()
+	ch <- 1
+	ch <- []any{2, 3}
+	ch <- map[string]any{"four": 4}
+	close(ch)
+	tt.Nil(t, <-done)
+	tt.Equal(t, "1\n[2,3]\n{\"four\":4}\n", fw.String())
+	// Each value is flushed as it arrives since no others are waiting.
+	tt.Equal(t, []string{"1\n", "1\n[2,3]\n", "1\n[2,3]\n{\"four\":4}\n", "1\n[2,3]\n{\"four\":4}\n"}, fw.flushed)
+	tt.Equal(t, 2, wr.Indent)
+
+	bch := make(chan any, 3)
+	bch <- 1
+	bch <- 2
+	bch <- 3
+	close(bch)
+	var b strings.Builder
+	err := wr.WriteChan(&b, bch)
+	tt.Nil(t, err)
+	tt.Equal(t, "1\n2\n3\n", b.String())
+
+	wch := make(chan any, 3)
+	wch <- 1
+	wch <- 2
+	wch <- 3
+	close(wch)
+	err = wr.WriteChan(&shortWriter{max: 3}, wch)
+	tt.NotNil(t, err)
+}
+
+func TestWriterFlush(t *testing.T) {
+	var wr oj.Writer
+	tt.Nil(t, wr.Flush())
+
+	var fw flushWriter
+	tt.Nil(t, wr.Write(&fw, []any{1, 2}))
+	tt.Nil(t, wr.Flush())
+	tt.Equal(t, []string{"[1,2]"}, fw.flushed)
+
+	tt.Nil(t, wr.Write(&shortWriter{max: 10}, 1))
+	tt.Nil(t, wr.Flush())
+
+	tt.Nil(t, wr.Write(badFlushWriter{}, 1))
+	tt.NotNil(t, wr.Flush())
+}
+
+type badFlushWriter struct{}
+
+func (badFlushWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (badFlushWriter) Flush() error {
+	return fmt.Errorf("flush failed")
+}
+
+func TestWriterWriteTo(t *testing.T) {
+	var wr oj.Writer
+	var _ io.WriterTo = &wr
+
+	out := wr.MustJSON(map[string]any{"a": 1})
+	var b strings.Builder
+	n, err := wr.WriteTo(&b)
+	tt.Nil(t, err)
+	tt.Equal(t, int64(len(out)), n)
+	tt.Equal(t, `{"a":1}`, b.String())
+
+	n, err = wr.WriteTo(&b)
+	tt.Nil(t, err)
+	tt.Equal(t, int64(0), n)
+
+	_ = wr.MustJSON("abc")
+	_, err = wr.WriteTo(&shortWriter{max: 2})
+	tt.NotNil(t, err)
+	b.Reset()
+	_, err = wr.WriteTo(&b)
+	tt.Nil(t, err)
+	tt.Equal(t, `"abc"`, b.String())
+}
+
+func TestWriteChanArray(t *testing.T) {
+	for _, d := range []struct {
+		opt    oj.Options
+		values []any
+		expect string
+	}{
+		{opt: oj.Options{}, values: []any{1, "two", []any{3}}, expect: `[1,"two",[3]]`},
+		{opt: oj.Options{}, values: []any{}, expect: `[]`},
+		{opt: oj.Options{Indent: 2}, values: []any{}, expect: `[]`},
+		{opt: oj.Options{Indent: 2}, values: []any{1, []any{2}}, expect: "[\n  1,\n  [\n    2\n  ]\n]"},
+		{opt: oj.Options{Tab: true}, values: []any{1, 2}, expect: "[\n\t1,\n\t2\n]"},
+		{opt: oj.Options{WriteLimit: 2}, values: []any{"abc", "def"}, expect: `["abc","def"]`},
+	} {
+		ch := make(chan any, len(d.values))
+		for _, v := range d.values {
+			ch <- v
+		}
+		close(ch)
+		var b strings.Builder
+		wr := oj.Writer{Options: d.opt}
+		tt.Nil(t, wr.WriteChanArray(&b, ch))
+		tt.Equal(t, d.expect, b.String(), d.opt)
+	}
+	ch := make(chan any, 1)
+	ch <- 1
+	close(ch)
+	tt.NotNil(t, (&oj.Writer{}).WriteChanArray(&shortWriter{max: 1}, ch))
+}
+
+type zeroPoint struct {
+	X int
+	Y int
+}
+
+func (p zeroPoint) IsZero() bool {
+	return p.X == 0
+}
+
+func TestWriteOmitZero(t *testing.T) {
+	type Sample struct {
+		At    time.Time `json:"at,omitzero"`
+		Count int       `json:"count,omitzero"`
+		Inner struct {
+			A int
+		} `json:"inner,omitzero"`
+		List  []int     `json:"list,omitzero"`
+		Point zeroPoint `json:"point,omitzero"`
+		Plain int       `json:"plain"`
+	}
+	opt := oj.Options{UseTags: true, KeyExact: true}
+	for _, indent := range []int{0, 2} {
+		opt.Indent = indent
+		j, err := oj.Marshal(&Sample{Point: zeroPoint{Y: 3}}, &opt)
+		tt.Nil(t, err)
+		tt.Equal(t, `{"plain":0}`, oj.JSON(oj.MustParse(j)), indent)
+
+		var sample Sample
+		sample.At = time.Unix(0, 0).UTC()
+		sample.Inner.A = 1
+		sample.List = []int{}
+		j, err = oj.Marshal(&sample, &opt)
+		tt.Nil(t, err)
+		tt.Equal(t, `{"at":"1970-01-01T00:00:00Z","inner":{"A":1},"list":[],"plain":0}`,
+			oj.JSON(oj.MustParse(j), &oj.Options{Sort: true}), indent)
+	}
+	type Untagged struct {
+		At    time.Time
+		Count float64
+		Name  string
+	}
+	j, err := oj.Marshal(Untagged{Name: "x"}, &oj.Options{OmitZero: true, Sort: true})
+	tt.Nil(t, err)
+	tt.Equal(t, `{"name":"x"}`, string(j))
+}
+
+func TestWriteOmitEmptyParity(t *testing.T) {
+	type Sample struct {
+		A   int            `json:"a"`
+		B   int            `json:"b,omitempty"`
+		Arr [0]int         `json:"arr,omitempty"`
+		Any any            `json:"any,omitempty"`
+		M   map[string]int `json:"m,omitempty"`
+		P   *int           `json:"p,omitempty"`
+		S   struct{}       `json:"s,omitempty"`
+		T   time.Time      `json:"t,omitempty"`
+		Z   zeroPoint      `json:"z,omitempty"`
+	}
+	expect, err := json.Marshal(&Sample{})
+	tt.Nil(t, err)
+	j, err := oj.Marshal(&Sample{})
+	tt.Nil(t, err)
+	tt.Equal(t, oj.JSON(oj.MustParse(expect), &oj.Options{Sort: true}), oj.JSON(oj.MustParse(j), &oj.Options{Sort: true}))
+}
+
+func TestWriteStableMaps(t *testing.T) {
+	shared := map[string]any{"c": 3, "a": 1, "b": 2}
+	data := []any{shared, map[string]any{"z": shared, "y": nil}, map[string]int{"q": 1, "p": 2}}
+	opt := oj.Options{StableMaps: true}
+	tt.Equal(t, `[{"a":1,"b":2,"c":3},{"y":null,"z":{"a":1,"b":2,"c":3}},{"p":2,"q":1}]`, oj.JSON(data, &opt))
+
+	opt.Indent = 1
+	tt.Equal(t, `[
+ {
+  "a": 1,
+  "b": 2,
+  "c": 3
+ },
+ {
+  "y": null,
+  "z": {
+   "a": 1,
+   "b": 2,
+   "c": 3
+  }
+ },
+ {
+  "p": 2,
+  "q": 1
+ }
+]`, oj.JSON(data, &opt))
+
+	// Keys are not cached across writes.
+	wr := oj.Writer{Options: ojg.Options{StableMaps: true}}
+	tt.Equal(t, `{"a":1,"b":2,"c":3}`, wr.JSON(shared))
+	shared["d"] = 4
+	tt.Equal(t, `{"a":1,"b":2,"c":3,"d":4}`, wr.JSON(shared))
+
+	var b strings.Builder
+	tt.Nil(t, wr.WriteMany(&b, []any{map[string]any{"b": 1, "a": 2}, shared}))
+	tt.Equal(t, "{\"a\":2,\"b\":1}\n{\"a\":1,\"b\":2,\"c\":3,\"d\":4}\n", b.String())
+}
+
+func TestWriteBigIntAsString(t *testing.T) {
+	type ID uint64
+	type Tweet struct {
+		ID    ID
+		Count int32
+		Big   int64
+		Text  string
+	}
+	opt := oj.Options{BigIntAsString: true, Sort: true}
+	tt.Equal(t, `[9007199254740991,"9007199254740992","-9007199254740993","18446744073709551615",7]`,
+		oj.JSON([]any{int64(1<<53 - 1), int64(1 << 53), -(int64(1<<53) + 1), uint64(1<<64 - 1), int8(7)}, &opt))
+
+	tweet := Tweet{ID: 1234567890123456789, Count: 3, Big: -1 << 60, Text: "hi"}
+	j := oj.JSON(&tweet, &opt)
+	tt.Equal(t, `{"big":"-1152921504606846976","count":3,"id":"1234567890123456789","text":"hi"}`, j)
+	tt.Equal(t, `{"big":-1152921504606846976,"count":3,"id":1234567890123456789,"text":"hi"}`,
+		oj.JSON(&tweet, &oj.Options{Sort: true}))
+
+	opt.BigIntLimit = 100
+	opt.Indent = 1
+	tt.Equal(t, "{\n \"big\": \"-1152921504606846976\",\n \"count\": 3,\n \"id\": \"1234567890123456789\",\n \"text\": \"hi\"\n}",
+		oj.JSON(tweet, &opt))
+	tt.Equal(t, `[99,"101"]`, oj.JSON([]any{99, uint16(101)}, &oj.Options{BigIntAsString: true, BigIntLimit: 100}))
+
+	opt.Indent = 0
+	opt.Color = true
+	opt.NumberColor = "#"
+	opt.SyntaxColor = ""
+	opt.NoColor = ""
+	tt.Equal(t, `[#"101"]`, oj.JSON([]any{101}, &opt))
+
+	tt.Equal(t, `{"big":"-1152921504606846976","count":3}`,
+		oj.JSON(&Tweet{Big: -1 << 60, Count: 3}, &oj.Options{BigIntAsString: true, OmitEmpty: true, Sort: true}))
+	tt.Equal(t, `{"id":"1234567890123456789"}`,
+		oj.JSON(&Tweet{ID: 1234567890123456789}, &oj.Options{BigIntAsString: true, OmitEmpty: true}))
+
+	var back Tweet
+	r := alt.MustNewRecomposer("", nil)
+	r.BigIntAsString = true
+	tt.Nil(t, oj.Unmarshal([]byte(j), &back, r))
+	tt.Equal(t, tweet, back)
+
+	err := oj.Unmarshal([]byte(j), &back)
+	tt.NotNil(t, err)
+
+	err = oj.Unmarshal([]byte(`{"count":"3000000000"}`), &back, r)
+	var tm *ojg.ErrTypeMismatch
+	tt.ErrorAs(t, err, &tm)
+	tt.Equal(t, "$.count", tm.Path)
+}
+
+func TestWriteFallbackFunc(t *testing.T) {
+	type Job struct {
+		Done chan int
+		Name string
+	}
+	fallback := func(v any) (any, error) {
+		switch tv := v.(type) {
+		case chan int:
+			return map[string]any{"cap": cap(tv)}, nil
+		case func():
+			return "func", nil
+		case complex128:
+			return nil, fmt.Errorf("no complex please")
+		}
+		return v, nil
+	}
+	opt := oj.Options{FallbackFunc: fallback, Sort: true}
+	tt.Equal(t, `[{"cap":3},"func",null]`, oj.JSON([]any{make(chan int, 3), func() {}, make(chan bool)}, &opt))
+	tt.Equal(t, `{"done":{"cap":2},"name":"x"}`, oj.JSON(&Job{Done: make(chan int, 2), Name: "x"}, &opt))
+	tt.Equal(t, `{"done":null,"name":"x"}`, oj.JSON(&Job{Done: make(chan int, 2), Name: "x"}, &oj.Options{Sort: true}))
+
+	opt.Indent = 2
+	tt.Equal(t, "[\n  {\n    \"cap\": 1\n  }\n]", oj.JSON([]any{make(chan int, 1)}, &opt))
+
+	opt.Indent = 0
+	opt.Color = true
+	opt.SyntaxColor = ""
+	opt.KeyColor = ""
+	opt.NumberColor = ""
+	opt.NoColor = ""
+	tt.Equal(t, `[{"cap":4}]`, oj.JSON([]any{make(chan int, 4)}, &opt))
+
+	opt = oj.Options{FallbackFunc: fallback, NoReflect: true}
+	tt.Equal(t, `["func"]`, oj.JSON([]any{func() {}}, &opt))
+
+	out, err := oj.Marshal([]any{make(chan int, 5)}, &oj.Options{FallbackFunc: fallback})
+	tt.Nil(t, err)
+	tt.Equal(t, `[{"cap":5}]`, string(out))
+
+	_, err = oj.Marshal(make(chan int), &oj.Options{FallbackFunc: func(v any) (any, error) {
+		return nil, fmt.Errorf("not allowed")
+	}})
+	tt.NotNil(t, err, "fallback error")
+
+	// A replacement of the same type is not passed back to the fallback.
+	_, err = oj.Marshal(make(chan bool), &opt)
+	tt.NotNil(t, err, "same type")
+}
+
+func TestWriteGroups(t *testing.T) {
+	type User struct {
+		Name  string
+		Email string `ojg:"groups=admin,self"`
+		Hash  string `json:"pw" ojg:"groups=internal"`
+	}
+	u := User{Name: "ann", Email: "ann@example.com", Hash: "xyz"}
+	tt.Equal(t, `{"email":"ann@example.com","hash":"xyz","name":"ann"}`, oj.JSON(&u))
+	tt.Equal(t, `{"name":"ann"}`, oj.JSON(&u, &oj.Options{Groups: []string{"public"}}))
+	tt.Equal(t, `{"email":"ann@example.com","name":"ann"}`, oj.JSON(&u, &oj.Options{Groups: []string{"self"}}))
+	tt.Equal(t, `{"Email":"ann@example.com","Name":"ann","pw":"xyz"}`,
+		oj.JSON(u, &oj.Options{Groups: []string{"admin", "internal"}, UseTags: true}))
+	tt.Equal(t, "{\n  \"name\": \"ann\"\n}", oj.JSON(&u, &oj.Options{Groups: []string{"public"}, Indent: 2}))
+	tt.Equal(t, `{"name":"ann"}`, oj.JSON(&u, &oj.Options{Groups: []string{"public"}, Color: true,
+		SyntaxColor: "", KeyColor: "", StringColor: "", NoColor: ""}))
+}
+
+func TestWriteKeyFunc(t *testing.T) {
+	type Inner struct {
+		HTTPStatus int
+	}
+	type Sample struct {
+		UserID    int
+		FirstName string `json:"First"`
+		Inner     Inner
+	}
+	s := Sample{UserID: 3, FirstName: "ann", Inner: Inner{HTTPStatus: 200}}
+	tt.Equal(t, `{"first_name":"ann","inner":{"http_status":200},"user_id":3}`,
+		oj.JSON(&s, &oj.Options{KeyFunc: ojg.SnakeCase}))
+	tt.Equal(t, `{"First":"ann","inner":{"http-status":200},"user-id":3}`,
+		oj.JSON(&s, &oj.Options{KeyFunc: ojg.KebabCase, UseTags: true}))
+	tt.Equal(t, "{\n  \"firstName\": \"ann\",\n  \"inner\": {\n    \"httpStatus\": 200\n  },\n  \"userId\": 3\n}",
+		oj.JSON(&s, &oj.Options{KeyFunc: ojg.CamelCase, Indent: 2}))
+	tt.Equal(t, `{"first_name":"ann","inner":{"http_status":200},"user_id":3}`,
+		oj.JSON(&s, &oj.Options{KeyFunc: ojg.SnakeCase, Color: true, Sort: true, SyntaxColor: "", KeyColor: "",
+			StringColor: "", NumberColor: "", NoColor: ""}))
+
+	wr := oj.Writer{Options: oj.Options{KeyFunc: ojg.SnakeCase}}
+	tt.Equal(t, `{"first_name":"ann","inner":{"http_status":200},"user_id":3}`, wr.JSON(&s))
+	wr.KeyFunc = strings.ToUpper
+	tt.Equal(t, `{"FIRSTNAME":"ann","INNER":{"HTTPSTATUS":200},"USERID":3}`, wr.JSON(&s))
+}
+
+type genBox[T any] struct {
+	V    T   `json:"v"`
+	List []T `json:"list,omitempty"`
+}
+
+func TestWriteGeneric(t *testing.T) {
+	opt := oj.Options{UseTags: true, Sort: true}
+	// Instantiations are distinct types and must not share cached field
+	// information.
+	tt.Equal(t, `{"list":[2,3],"v":1}`, oj.JSON(genBox[int]{V: 1, List: []int{2, 3}}, &opt))
+	tt.Equal(t, `{"v":"x"}`, oj.JSON(genBox[string]{V: "x"}, &opt))
+	tt.Equal(t, `{"v":{"v":true}}`, oj.JSON(genBox[genBox[bool]]{V: genBox[bool]{V: true}}, &opt))
+
+	var nested genBox[genBox[[]int]]
+	err := oj.Unmarshal([]byte(`{"v":{"v":[1,2]},"list":[{"v":[3]}]}`), &nested)
+	tt.Nil(t, err)
+	tt.Equal(t, genBox[genBox[[]int]]{V: genBox[[]int]{V: []int{1, 2}}, List: []genBox[[]int]{{V: []int{3}}}}, nested)
+
+	var s genBox[string]
+	err = oj.Unmarshal([]byte(`{"v":"y"}`), &s)
+	tt.Nil(t, err)
+	tt.Equal(t, "y", s.V)
+}
+
+func TestWriteBlankKeys(t *testing.T) {
+	data := map[string]any{"": 1, "a": map[string]any{" ": 2}}
+	opt := ojg.Options{Sort: true}
+	tt.Equal(t, `{"":1,"a":{" ":2}}`, oj.JSON(data, &opt))
+
+	opt.BlankKeys = ojg.BlankKeyRename
+	tt.Equal(t, `{"_":1,"a":{"_":2}}`, oj.JSON(data, &opt))
+
+	opt.BlankKeyName = "blank"
+	opt.Sort = false
+	tt.Equal(t, `{"blank":2}`, oj.JSON(map[string]any{" ": 2}, &opt))
+
+	opt.BlankKeys = ojg.BlankKeyError
+	_, err := oj.Marshal(data, &opt)
+	tt.ErrorIs(t, err, ojg.ErrBlankKey)
+}
+
+type nilHolder struct {
+	List  []int          `json:"list"`
+	Map   map[string]int `json:"map"`
+	Ptr   *int           `json:"ptr"`
+	Bytes []byte         `json:"bytes"`
+}
+
+func TestWriteNilCollections(t *testing.T) {
+	data := map[string]any{"a": []any(nil), "m": map[string]any(nil), "s": []int(nil), "h": &nilHolder{}}
+	opt := ojg.Options{Sort: true, UseTags: true, BytesAs: ojg.BytesAsBase64}
+	tt.Equal(t, `{"a":[],"h":{"bytes":null,"list":[],"map":{},"ptr":null},"m":{},"s":[]}`, oj.JSON(data, &opt))
+	out, err := oj.Marshal(data, &opt)
+	tt.Nil(t, err)
+	tt.Equal(t, `{"a":null,"h":{"bytes":null,"list":[],"map":{},"ptr":null},"m":{},"s":[]}`, string(out))
+
+	opt.NilCollections = ojg.NilAsNull
+	expect := `{"a":null,"h":{"bytes":null,"list":null,"map":null,"ptr":null},"m":null,"s":null}`
+	tt.Equal(t, expect, oj.JSON(data, &opt))
+	out, err = oj.Marshal(data, &opt)
+	tt.Nil(t, err)
+	tt.Equal(t, expect, string(out))
+	opt.Indent = 2
+	tt.Equal(t, expect, oj.JSON(oj.MustParseString(oj.JSON(data, &opt)), &ojg.Options{Sort: true}), "indented")
+	opt.Indent = 0
+
+	opt.NilCollections = ojg.NilAsEmpty
+	expect = `{"a":[],"h":{"bytes":"","list":[],"map":{},"ptr":null},"m":{},"s":[]}`
+	out, err = oj.Marshal(data, &opt)
+	tt.Nil(t, err)
+	tt.Equal(t, expect, string(out))
+
+	// OmitNil still skips nil pointers.
+	opt.OmitNil = true
+	tt.Equal(t, `{"bytes":"","list":[],"map":{}}`, oj.JSON(&nilHolder{}, &opt))
+}
+
+func TestWriteInvalidUTF8(t *testing.T) {
+	type sample struct {
+		S string
+	}
+	data := []any{"a\xffb\u00e9", map[string]any{"k\xfe": "<\xc3"}, &sample{S: "x\xff"}}
+
+	tt.Equal(t, `["a\ufffdb`+"\u00e9"+`",{"k\ufffd":"\u003c\ufffd"},{"s":"x\ufffd"}]`, oj.JSON(data, &ojg.Options{Sort: true}))
+	tt.Equal(t, "[\"a\xffb\u00e9\",{\"k\xfe\":\"\\u003c\xc3\"},{\"s\":\"x\xff\"}]",
+		oj.JSON(data, &ojg.Options{Sort: true, InvalidUTF8: ojg.UTF8Pass}))
+	tt.Equal(t, "[\"a\xffb\\u00e9\"]", oj.JSON([]any{"a\xffb\u00e9"}, &ojg.Options{InvalidUTF8: ojg.UTF8Pass, ASCIIOnly: true}))
+
+	_, err := oj.Marshal(data, &ojg.Options{InvalidUTF8: ojg.UTF8Reject})
+	tt.Equal(t, true, errors.Is(err, ojg.ErrInvalidString))
+	_, err = oj.Marshal(&sample{S: "x\xff"}, &ojg.Options{InvalidUTF8: ojg.UTF8Reject})
+	tt.Equal(t, true, errors.Is(err, ojg.ErrInvalidString))
+	tt.Equal(t, `["ok"]`, oj.JSON([]any{"ok"}, &ojg.Options{InvalidUTF8: ojg.UTF8Reject}))
+
+	tt.Equal(t, "[\"a\xffb\" c]", sen.String([]any{"a\xffb", "c"}, &ojg.Options{InvalidUTF8: ojg.UTF8Pass}))
+}
+
+type orderBase struct {
+	ID int `json:"id"`
+}
+
+type orderSample struct {
+	Zeta  int    `json:"zeta"`
+	Name  string `json:"name"`
+	Alpha bool   `json:"alpha"`
+	orderBase
+}
+
+func TestWriteFieldOrder(t *testing.T) {
+	s := orderSample{Zeta: 1, Name: "x", Alpha: true, orderBase: orderBase{ID: 7}}
+	opt := ojg.Options{UseTags: true}
+	tt.Equal(t, `{"alpha":true,"id":7,"name":"x","zeta":1}`, oj.JSON(&s, &opt))
+
+	opt.FieldOrder = ojg.FieldOrderDeclared
+	tt.Equal(t, `{"zeta":1,"name":"x","alpha":true,"id":7}`, oj.JSON(&s, &opt))
+	tt.Equal(t, "{\n  \"zeta\": 1,\n  \"name\": \"x\",\n  \"alpha\": true,\n  \"id\": 7\n}",
+		oj.JSON(&s, &ojg.Options{UseTags: true, FieldOrder: ojg.FieldOrderDeclared, Indent: 2}))
+
+	opt.FieldPriority = []string{"id", "name", "missing"}
+	tt.Equal(t, `{"id":7,"name":"x","zeta":1,"alpha":true}`, oj.JSON(&s, &opt))
+	opt.FieldOrder = ojg.FieldOrderKey
+	tt.Equal(t, `{"id":7,"name":"x","alpha":true,"zeta":1}`, oj.JSON(&s, &opt))
+	opt.Indent = 2
+	tt.Equal(t, "{\n  \"id\": 7,\n  \"name\": \"x\",\n  \"alpha\": true,\n  \"zeta\": 1\n}", oj.JSON(&s, &opt))
+}
@@ -4,8 +4,11 @@ package ojg
 
 import (
 	"fmt"
+	"reflect"
 	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 const (
@@ -51,6 +54,59 @@ const (
 	// BytesAsArray indicates []byte should be encoded as an array if integers.
 	BytesAsArray
 
+	// BlankKeyAllow indicates empty and whitespace only object keys are
+	// allowed.
+	BlankKeyAllow = 0
+	// BlankKeyError indicates empty and whitespace only object keys are an
+	// error.
+	BlankKeyError = 1
+	// BlankKeyRename indicates empty and whitespace only object keys are
+	// replaced by the BlankKeyName.
+	BlankKeyRename = 2
+
+	// DefaultBlankKeyName is the key used for a renamed blank key if the
+	// BlankKeyName is empty.
+	DefaultBlankKeyName = "_"
+
+	// UnsafeKeyAllow indicates keys on the unsafe key list are allowed.
+	UnsafeKeyAllow = 0
+	// UnsafeKeyError indicates keys on the unsafe key list are an error.
+	UnsafeKeyError = 1
+	// UnsafeKeyStrip indicates object members with keys on the unsafe key
+	// list are removed.
+	UnsafeKeyStrip = 2
+
+	// NilAsDefault indicates nil slices and maps are written as empty arrays
+	// and objects except that a nil []any is written as null by
+	// oj.Marshal.
+	NilAsDefault = 0
+	// NilAsNull indicates nil slices and maps are written as null as the go
+	// json package does.
+	NilAsNull = 1
+	// NilAsEmpty indicates nil slices and maps are always written as empty
+	// arrays and objects.
+	NilAsEmpty = 2
+
+	// UTF8Default indicates the default handling of invalid UTF-8 in
+	// strings which is UTF8Pass when parsing and UTF8Replace when writing.
+	UTF8Default = 0
+	// UTF8Pass indicates invalid UTF-8 bytes in strings are kept as is.
+	UTF8Pass = 1
+	// UTF8Replace indicates each invalid UTF-8 byte in a string is replaced
+	// by U+FFFD.
+	UTF8Replace = 2
+	// UTF8Reject indicates a string with invalid UTF-8 is an error that
+	// matches ErrInvalidString.
+	UTF8Reject = 3
+
+	// FieldOrderKey indicates struct fields are written sorted by key.
+	FieldOrderKey = 0
+	// FieldOrderDeclared indicates struct fields are written in the order
+	// they are declared as the go json package does. Fields of embedded
+	// structs that are promoted are written where the embedded struct is
+	// declared.
+	FieldOrderDeclared = 1
+
 	// MaskByTag is the mask for byTag fields.
 	MaskByTag = byte(0x10)
 	// MaskExact is the mask for Exact fields.
@@ -116,6 +172,18 @@ var (
 		WriteLimit:   1024,
 	}
 
+	// HTMLClassOptions defines color options for generating colored HTML
+	// that wraps each element in a span with one of the CSS classes of the
+	// HTMLClassColorScheme. The encoding is suitable for use in a <pre>
+	// element. HTMLClassCSS provides default styles for the classes.
+	HTMLClassOptions = Options{
+		InitSize:    256,
+		Color:       true,
+		ColorScheme: &HTMLClassColorScheme,
+		HTMLUnsafe:  false,
+		WriteLimit:  1024,
+	}
+
 	// HTMLOptions defines color options for generating colored HTML. The
 	// encoding is suitable for use in a <pre> element.
 	HTMLOptions = Options{
@@ -133,6 +201,95 @@ var (
 	}
 )
 
+// ColorScheme is a set of colors for colorized output. The colors are
+// usually ANSI escape sequences but can be any strings such as HTML tags.
+type ColorScheme struct {
+	Syntax string
+	Key    string
+	Null   string
+	Bool   string
+	Number string
+	String string
+	Time   string
+	// NoColor is written after each colored element.
+	NoColor string
+}
+
+var (
+	// DefaultColorScheme is the color scheme of the DefaultOptions which
+	// works well on dark terminal backgrounds.
+	DefaultColorScheme = ColorScheme{
+		Syntax: Normal,
+		Key:    Blue,
+		Null:   Red,
+		Bool:   Yellow,
+		Number: Cyan,
+		String: Green,
+		Time:   Magenta,
+	}
+
+	// BrightColorScheme is the color scheme of the BrightOptions.
+	BrightColorScheme = ColorScheme{
+		Syntax: Normal,
+		Key:    BrightBlue,
+		Null:   BrightRed,
+		Bool:   BrightYellow,
+		Number: BrightCyan,
+		String: BrightGreen,
+		Time:   BrightMagenta,
+	}
+
+	// LightColorScheme uses darker colors that are easier to read on light
+	// terminal backgrounds.
+	LightColorScheme = ColorScheme{
+		Syntax: Normal,
+		Key:    "\x1b[38;5;25m",
+		Null:   "\x1b[38;5;124m",
+		Bool:   "\x1b[38;5;130m",
+		Number: "\x1b[38;5;30m",
+		String: "\x1b[38;5;28m",
+		Time:   "\x1b[38;5;90m",
+	}
+
+	// HTMLClassColorScheme wraps elements in span elements with the
+	// ojg-syntax, ojg-key, ojg-null, ojg-bool, ojg-number, ojg-string, and
+	// ojg-time CSS classes.
+	HTMLClassColorScheme = ColorScheme{
+		Syntax:  `<span class="ojg-syntax">`,
+		Key:     `<span class="ojg-key">`,
+		Null:    `<span class="ojg-null">`,
+		Bool:    `<span class="ojg-bool">`,
+		Number:  `<span class="ojg-number">`,
+		String:  `<span class="ojg-string">`,
+		Time:    `<span class="ojg-time">`,
+		NoColor: "</span>",
+	}
+
+	// SolarizedLightColorScheme uses the Solarized accent colors that are
+	// intended for the Solarized light background.
+	SolarizedLightColorScheme = ColorScheme{
+		Syntax: "\x1b[38;5;241m",
+		Key:    "\x1b[38;5;33m",
+		Null:   "\x1b[38;5;160m",
+		Bool:   "\x1b[38;5;136m",
+		Number: "\x1b[38;5;37m",
+		String: "\x1b[38;5;64m",
+		Time:   "\x1b[38;5;125m",
+		// Syntax is not the normal color so it must be reset.
+		NoColor: Normal,
+	}
+)
+
+// HTMLClassCSS is a style sheet for the classes of the HTMLClassColorScheme.
+const HTMLClassCSS = `.ojg-syntax { color: #666; }
+.ojg-key { color: #44f; }
+.ojg-null { color: #c00; }
+.ojg-bool { color: #a40; }
+.ojg-number { color: #04a; }
+.ojg-string { color: #080; }
+.ojg-time { color: #a0a; }
+`
+
 // Options for writing data to JSON.
 type Options struct {
 
@@ -145,6 +302,14 @@ type Options struct {
 	// Sort object members if true.
 	Sort bool
 
+	// StableMaps if true sorts the keys of maps so output is deterministic,
+	// as is needed when the output is cached or hashed. The sorted keys of
+	// each map are kept for the duration of a write so a map that appears
+	// more than once in a document is only sorted once. Struct fields are
+	// always written in a fixed order. Only the oj writer honors this
+	// option.
+	StableMaps bool
+
 	// OmitNil skips the writing of nil values in an object.
 	OmitNil bool
 
@@ -153,6 +318,107 @@ type Options struct {
 	// writing but will be with alt.Decompose and alter.
 	OmitEmpty bool
 
+	// OmitZero skips the writing of struct fields with a zero value such as
+	// time.Time{}, an empty struct, or a numeric zero. A field type with an
+	// IsZero() bool method uses that method to determine if it is zero. The
+	// same behavior is available per field with the omitzero tag option.
+	OmitZero bool
+
+	// Groups if not empty selects the struct fields that are written by the
+	// oj and sen writers and by alt.Decompose. Fields are assigned to groups
+	// with the groups option of the ojg tag such as ojg:"groups=public,admin"
+	// which must be the last option in the tag. A field with groups is only
+	// written if one of its groups is listed. Fields without groups are
+	// always written. When Groups is empty all fields are written.
+	Groups []string
+
+	// KeyFunc if not nil converts struct field names to the keys written by
+	// the oj and sen writers and by alt.Decompose. Keys set with a json tag
+	// when UseTags is true are not converted. SnakeCase, CamelCase, and
+	// KebabCase are provided for common naming conventions.
+	KeyFunc func(field string) string
+
+	// SpecialFloats if true writes infinite and NaN floats as the inf, -inf,
+	// and nan tokens in SEN and quotes strings that match those tokens so
+	// they can be read with the sen.Parser SpecialFloats flag set. Only the
+	// sen writer honors this option.
+	SpecialFloats bool
+
+	// QuoteStrings if true quotes all strings and keys written by the sen
+	// writer for SEN dialects that do not allow unquoted strings.
+	QuoteStrings bool
+
+	// Allocator if not nil provides the buffers used by the writers.
+	Allocator Allocator
+
+	// MaxWriteDepth if greater than zero limits the nesting of the arrays
+	// and objects written by the oj writer. Arrays and objects nested deeper
+	// than the limit are replaced by the TruncateMarker. A MaxWriteDepth of
+	// one writes the top level array or object with the marker in place of
+	// any array or object in it.
+	MaxWriteDepth int
+
+	// TruncateMarker is written in place of arrays and objects nested deeper
+	// than the MaxWriteDepth. It is written like any other value so a
+	// map[string]any{"$truncated": true} writes an object. If nil the string
+	// "…" is written.
+	TruncateMarker any
+
+	// MaxStringLength if greater than zero limits the length in bytes of
+	// the string values written by the oj writer. Longer strings are cut
+	// at a UTF-8 character boundary and an ellipsis is appended. Object keys
+	// are not truncated.
+	MaxStringLength int
+
+	// TruncateCount if true adds the number of bytes omitted from a string
+	// truncated by the MaxStringLength option after the ellipsis as in
+	// "abc…(+1234 bytes)".
+	TruncateCount bool
+
+	// MaxOutputBytes if greater than zero limits the size of the JSON
+	// written by the oj writer for a value. For NDJSON the limit applies to
+	// each line. What happens when the limit is exceeded depends on the
+	// TruncateOutput option.
+	MaxOutputBytes int
+
+	// TruncateOutput if true cuts output that exceeds the MaxOutputBytes
+	// and ends it with an ellipsis. The truncated output is not valid JSON
+	// but is useful for logging. If false an error that matches
+	// ErrOutputLimit is returned instead.
+	TruncateOutput bool
+
+	// Parallel if greater than one is the maximum number of goroutines used
+	// by the oj writer to encode the elements of a large top level slice.
+	// Each goroutine encodes a chunk of the elements into a separate buffer
+	// and the buffers are then joined in order. Slices with fewer than
+	// ParallelMin elements per goroutine are encoded with fewer goroutines
+	// or without any extra goroutines at all.
+	Parallel int
+
+	// ParallelMin is the minimum number of slice elements encoded by each
+	// goroutine when Parallel is greater than one. The default is 1024.
+	ParallelMin int
+
+	// BigIntAsString if true writes integers with an absolute value greater
+	// than BigIntLimit as quoted strings so the value is not rounded by
+	// readers such as JavaScript that hold all numbers as float64 values.
+	// Only the oj writer honors this option. Set the alt.Recomposer
+	// BigIntAsString field to recompose the strings into integer fields.
+	BigIntAsString bool
+
+	// BigIntLimit is the largest absolute integer value written as a number
+	// when BigIntAsString is true. If zero the limit is 2^53 - 1, the
+	// largest integer a float64 holds exactly.
+	BigIntLimit uint64
+
+	// FallbackFunc if not nil is called by the oj and sen writers for values
+	// that can not otherwise be encoded such as channels, functions, and,
+	// when NoReflect is true, types not known to the writers. The value
+	// returned is written in place of the original value. If an error is
+	// returned the write fails with that error. Without a FallbackFunc such
+	// values are written as null or as a string formed with %v.
+	FallbackFunc func(v any) (any, error)
+
 	// InitSize is the initial buffer size.
 	InitSize int
 
@@ -221,6 +487,10 @@ type Options struct {
 	// NoColor turns the color off.
 	NoColor string
 
+	// ColorScheme if not nil provides the colors used when Color is true in
+	// place of the individual color options.
+	ColorScheme *ColorScheme
+
 	// UseTags if true will use the json annotation tags when marhsalling,
 	// writing, or decomposing an struct. If no tag is present then the
 	// KeyExact flag is referenced to determine the key.
@@ -240,16 +510,249 @@ type Options struct {
 
 	// BytesAs indicates how []byte fields should be encoded. Choices are
 	// BytesAsString, BytesAsBase64 (the go json package default), or
-	// BytesAsArray.
+	// BytesAsArray. When writing with reflection, byte slices in structs,
+	// slices, and maps are written as base64 strings, or null if nil, only
+	// with BytesAsBase64 and as arrays otherwise. Recomposing and
+	// unmarshalling always accept a base64 string for a byte slice.
 	BytesAs int
 
+	// NilCollections indicates how nil slices and maps are written by the
+	// oj and sen writers. Choices are NilAsDefault, NilAsNull, or
+	// NilAsEmpty. Nil pointers and interfaces are written as null
+	// regardless. Whether an object member with a nil value is written at
+	// all is still decided by OmitNil.
+	NilCollections int
+
+	// CycleRefs if true causes the oj Writer to write a reference cycle
+	// found when writing with reflection as a JSON Reference object such
+	// as {"$ref":"#/items/0"} where the reference is a JSON Pointer to the
+	// value that is already being written. If false a cycle fails the write
+	// with an error that matches ErrCycle.
+	CycleRefs bool
+
+	// InvalidUTF8 indicates how strings that are not valid UTF-8 are
+	// written by the oj and sen writers. Choices are UTF8Default,
+	// UTF8Replace, UTF8Pass, or UTF8Reject. The default writes each invalid
+	// byte as \ufffd.
+	InvalidUTF8 int
+
+	// FieldOrder indicates the order struct fields are written in by the
+	// oj and sen writers. Choices are FieldOrderKey or FieldOrderDeclared.
+	// The order is the same with and without indentation.
+	FieldOrder int
+
+	// FieldPriority lists the keys of struct fields that are written
+	// before all other fields and in the order listed. The remaining fields
+	// follow in the order given by FieldOrder. Keys not found in a struct
+	// are ignored.
+	FieldPriority []string
+
 	// Converter to use when decomposing or altering if non nil. The Converter
 	// type includes more details.
 	Converter *Converter
 
 	// FloatFormat is the fmt.Printf formatting verb and options. The default
-	// is "%g".
+	// is "%g". If set it takes precedence over FloatVerb and FloatPrecision.
 	FloatFormat string
+
+	// FloatVerb is the strconv.FormatFloat format, 'f', 'e', or 'g', used
+	// to write floats. Zero is the same as 'g'.
+	FloatVerb byte
+
+	// FloatPrecision if greater than zero is the precision used with
+	// FloatVerb. Otherwise the fewest digits needed to represent the value
+	// exactly are written.
+	FloatPrecision int
+
+	// FloatDecimal if true appends ".0" to floats that would otherwise be
+	// written without a decimal point or exponent, such as 1.0 written as 1,
+	// so they are read back as floats and not integers.
+	FloatDecimal bool
+
+	// EscapeRunes if not empty are ranges of runes that are always written
+	// as \uXXXX escape sequences. Runes above U+FFFF are written as
+	// surrogate pairs. Combined with RawUnicode strings from mixed sources
+	// are written identically.
+	EscapeRunes []RuneRange
+
+	// RawUnicode if true writes U+2028 and U+2029 as UTF-8 instead of as
+	// escape sequences so that all valid runes not in EscapeRunes are
+	// written as UTF-8.
+	RawUnicode bool
+
+	// ASCIIOnly if true writes all non-ASCII runes in strings as \uXXXX
+	// escape sequences with runes above U+FFFF written as surrogate pairs
+	// so the output is 7-bit ASCII.
+	ASCIIOnly bool
+
+	// BlankKeys is the policy for writing object keys that are empty or
+	// only whitespace. Choices are BlankKeyAllow, BlankKeyError, or
+	// BlankKeyRename.
+	BlankKeys int
+
+	// BlankKeyName replaces blank keys when BlankKeys is BlankKeyRename. If
+	// empty DefaultBlankKeyName is used. A renamed key that is the same as
+	// another key in the object is written twice.
+	BlankKeyName string
+}
+
+// DefaultUnsafeKeys are the keys that can be used for prototype pollution
+// when JSON is consumed by JavaScript. They are used by the parsers when
+// the UnsafeKeys policy is not UnsafeKeyAllow and no other list is given.
+var DefaultUnsafeKeys = []string{"__proto__", "constructor", "prototype"}
+
+// IsBlankKey returns true if the key is empty or only whitespace.
+func IsBlankKey(key string) bool {
+	return len(strings.TrimSpace(key)) == 0
+}
+
+var nonASCII = []RuneRange{{Min: utf8.RuneSelf, Max: utf8.MaxRune}}
+
+// CustomString returns true if strings are not written with the default
+// AppendJSONString or AppendSENString functions.
+func (o *Options) CustomString() bool {
+	return 0 < len(o.EscapeRunes) || o.RawUnicode || o.ASCIIOnly ||
+		o.InvalidUTF8 == UTF8Pass || o.InvalidUTF8 == UTF8Reject
+}
+
+// StringAppender returns the function to use for appending strings given
+// the EscapeRunes, RawUnicode, ASCIIOnly, and InvalidUTF8 options. If sen
+// is true then SEN strings are appended otherwise JSON strings are
+// appended.
+func (o *Options) StringAppender(sen bool) func(buf []byte, s string, htmlSafe bool) []byte {
+	switch o.InvalidUTF8 {
+	case UTF8Pass:
+		return passInvalidUTF8(o.stringAppender(sen))
+	case UTF8Reject:
+		return rejectInvalidUTF8(o.stringAppender(sen))
+	}
+	return o.stringAppender(sen)
+}
+
+func (o *Options) stringAppender(sen bool) func(buf []byte, s string, htmlSafe bool) []byte {
+	if len(o.EscapeRunes) == 0 && !o.RawUnicode && !o.ASCIIOnly {
+		if sen {
+			return AppendSENString
+		}
+		return AppendJSONString
+	}
+	raw := o.RawUnicode
+	escapes := o.EscapeRunes
+	if o.ASCIIOnly {
+		escapes = nonASCII
+	}
+	if sen {
+		return func(buf []byte, s string, htmlSafe bool) []byte {
+			return AppendEscapedSENString(buf, s, htmlSafe, raw, escapes)
+		}
+	}
+	return func(buf []byte, s string, htmlSafe bool) []byte {
+		return AppendEscapedJSONString(buf, s, htmlSafe, raw, escapes)
+	}
+}
+
+// ApplyColorScheme sets the individual color options from the ColorScheme
+// option if it is not nil.
+func (o *Options) ApplyColorScheme() {
+	if cs := o.ColorScheme; cs != nil {
+		o.SyntaxColor = cs.Syntax
+		o.KeyColor = cs.Key
+		o.NullColor = cs.Null
+		o.BoolColor = cs.Bool
+		o.NumberColor = cs.Number
+		o.StringColor = cs.String
+		o.TimeColor = cs.Time
+		o.NoColor = cs.NoColor
+	}
+}
+
+// CustomFloat returns true if any of the float formatting options are set.
+func (o *Options) CustomFloat() bool {
+	return 0 < len(o.FloatFormat) || o.FloatVerb != 0 || 0 < o.FloatPrecision || o.FloatDecimal
+}
+
+// AppendInt appends an integer to the buffer. The integer is quoted if the
+// BigIntAsString option is true and the absolute value is greater than the
+// BigIntLimit.
+func (o *Options) AppendInt(buf []byte, i int64) []byte {
+	if o.BigIntAsString {
+		abs := uint64(i)
+		if i < 0 {
+			abs = -abs
+		}
+		if o.bigIntLimit() < abs {
+			buf = append(buf, '"')
+			buf = strconv.AppendInt(buf, i, 10)
+			return append(buf, '"')
+		}
+	}
+	return strconv.AppendInt(buf, i, 10)
+}
+
+// AppendUint appends an unsigned integer to the buffer. The integer is
+// quoted if the BigIntAsString option is true and the value is greater than
+// the BigIntLimit.
+func (o *Options) AppendUint(buf []byte, u uint64) []byte {
+	if o.BigIntAsString && o.bigIntLimit() < u {
+		buf = append(buf, '"')
+		buf = strconv.AppendUint(buf, u, 10)
+		return append(buf, '"')
+	}
+	return strconv.AppendUint(buf, u, 10)
+}
+
+// Fallback calls the FallbackFunc if it is set and returns the replacement
+// value and true. A FallbackFunc error is raised with panic as the writers
+// do with other errors. To avoid unbounded recursion false is returned if
+// the replacement has the same type as the original value.
+func (o *Options) Fallback(v any) (any, bool) {
+	if o.FallbackFunc == nil {
+		return nil, false
+	}
+	r, err := o.FallbackFunc(v)
+	if err != nil {
+		panic(err)
+	}
+	if r != nil && reflect.TypeOf(r) == reflect.TypeOf(v) {
+		return nil, false
+	}
+	return r, true
+}
+
+func (o *Options) bigIntLimit() uint64 {
+	if o.BigIntLimit == 0 {
+		return 1<<53 - 1
+	}
+	return o.BigIntLimit
+}
+
+// AppendFloat appends a float to the buffer formatted according to the
+// FloatFormat, FloatVerb, FloatPrecision, and FloatDecimal options. The
+// bitSize should be 32 for float32 values and 64 for float64 values.
+func (o *Options) AppendFloat(buf []byte, f float64, bitSize int) []byte {
+	start := len(buf)
+	if 0 < len(o.FloatFormat) {
+		buf = fmt.Appendf(buf, o.FloatFormat, f)
+	} else {
+		verb := o.FloatVerb
+		if verb == 0 {
+			verb = 'g'
+		}
+		prec := -1
+		if 0 < o.FloatPrecision {
+			prec = o.FloatPrecision
+		}
+		buf = strconv.AppendFloat(buf, f, verb, prec, bitSize)
+	}
+	if o.FloatDecimal {
+		for _, b := range buf[start:] {
+			if (b < '0' || '9' < b) && b != '-' {
+				return buf
+			}
+		}
+		buf = append(buf, ".0"...)
+	}
+	return buf
 }
 
 // AppendTime appends a time string to the buffer.
@@ -4,6 +4,7 @@ package ojg_test
 
 import (
 	"fmt"
+	"math"
 	"testing"
 	"time"
 
@@ -109,3 +110,44 @@ func TestOptionsDecomposeTime(t *testing.T) {
 	tt.Equal(t, map[string]any{"@": "2021-05-21T10:11:12.123456789Z"}, m)
 
 }
+
+func TestOptionsStringAppender(t *testing.T) {
+	var o ojg.Options
+	tt.Equal(t, "\"caf\u00e9\"", string(o.StringAppender(false)(nil, "caf\u00e9", false)))
+	tt.Equal(t, "caf\u00e9", string(o.StringAppender(true)(nil, "caf\u00e9", false)))
+
+	o.EscapeRunes = []ojg.RuneRange{{Min: 0x80, Max: 0xff}}
+	tt.Equal(t, `"caf\u00e9"`, string(o.StringAppender(false)(nil, "caf\u00e9", false)))
+	tt.Equal(t, `"caf\u00e9"`, string(o.StringAppender(true)(nil, "caf\u00e9", false)))
+
+	o = ojg.Options{ASCIIOnly: true}
+	tt.Equal(t, `"\ud83d\ude00"`, string(o.StringAppender(false)(nil, "\U0001f600", false)))
+	tt.Equal(t, "abc", string(o.StringAppender(true)(nil, "abc", false)))
+}
+
+func TestOptionsAppendFloat(t *testing.T) {
+	for _, d := range []struct {
+		opt    ojg.Options
+		val    float64
+		expect string
+	}{
+		{opt: ojg.Options{}, val: 1.0, expect: "1"},
+		{opt: ojg.Options{FloatDecimal: true}, val: 1.0, expect: "1.0"},
+		{opt: ojg.Options{FloatDecimal: true}, val: -12.0, expect: "-12.0"},
+		{opt: ojg.Options{FloatDecimal: true}, val: 1.5, expect: "1.5"},
+		{opt: ojg.Options{FloatDecimal: true}, val: 1e21, expect: "1e+21"},
+		{opt: ojg.Options{FloatDecimal: true}, val: math.Inf(1), expect: "+Inf"},
+		{opt: ojg.Options{FloatVerb: 'f'}, val: 1e6, expect: "1000000"},
+		{opt: ojg.Options{FloatVerb: 'f', FloatDecimal: true}, val: 1e6, expect: "1000000.0"},
+		{opt: ojg.Options{FloatVerb: 'f', FloatPrecision: 2}, val: 3.14159, expect: "3.14"},
+		{opt: ojg.Options{FloatVerb: 'e', FloatPrecision: 3}, val: 3.14159, expect: "3.142e+00"},
+		{opt: ojg.Options{FloatVerb: 'g', FloatPrecision: 2}, val: 314.159, expect: "3.1e+02"},
+		{opt: ojg.Options{FloatFormat: "%.1f", FloatVerb: 'e'}, val: 2.25, expect: "2.2"},
+	} {
+		tt.Equal(t, d.expect, string(d.opt.AppendFloat(nil, d.val, 64)), d.opt, d.val)
+	}
+	opt := ojg.Options{FloatDecimal: true}
+	tt.Equal(t, "x:0.1", string(opt.AppendFloat([]byte("x:"), float64(float32(0.1)), 32)))
+	tt.Equal(t, true, opt.CustomFloat())
+	tt.Equal(t, false, (&ojg.Options{}).CustomFloat())
+}
@@ -4,7 +4,6 @@ package pretty
 
 import (
 	"encoding/base64"
-	"fmt"
 	"sort"
 	"strconv"
 	"time"
@@ -139,16 +138,9 @@ func (w *Writer) buildInt(v int64) (n *node) {
 }
 
 func (w *Writer) buildFloat32(v float32) (n *node) {
-	if 0 < len(w.FloatFormat) {
-		n = &node{
-			buf:  fmt.Appendf(nil, w.FloatFormat, float64(v)),
-			kind: numNode,
-		}
-	} else {
-		n = &node{
-			buf:  []byte(strconv.FormatFloat(float64(v), 'g', -1, 32)),
-			kind: numNode,
-		}
+	n = &node{
+		buf:  w.AppendFloat(nil, float64(v), 32),
+		kind: numNode,
 	}
 	n.size = len(n.buf)
 	if w.Color {
@@ -158,16 +150,9 @@ func (w *Writer) buildFloat32(v float32) (n *node) {
 }
 
 func (w *Writer) buildFloat64(v float64) (n *node) {
-	if 0 < len(w.FloatFormat) {
-		n = &node{
-			buf:  fmt.Appendf(nil, w.FloatFormat, v),
-			kind: numNode,
-		}
-	} else {
-		n = &node{
-			buf:  []byte(strconv.FormatFloat(v, 'g', -1, 64)),
-			kind: numNode,
-		}
+	n = &node{
+		buf:  w.AppendFloat(nil, v, 64),
+		kind: numNode,
 	}
 	n.size = len(n.buf)
 	if w.Color {
@@ -0,0 +1,66 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package pretty
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/ohler55/ojg"
+)
+
+// Format wraps an ojg.Format so that values are written pretty. Parsing and
+// tokenizing are left to the wrapped Format. The "json" and "sen" formats
+// are written by the pretty Writer while values for any other format are
+// written by the wrapped Format.
+type Format struct {
+	ojg.Format
+
+	// Width is the suggested maximum width. If zero the default of 80 is
+	// used.
+	Width int
+
+	// MaxDepth is the maximum depth of an element on a single line. If
+	// zero the default of 3 is used.
+	MaxDepth int
+
+	// Align if true attempts to align elements of children in list.
+	Align bool
+}
+
+// Write the data to w pretty using the options provided. If opts is nil the
+// default options are used.
+func (f *Format) Write(w io.Writer, data any, opts *ojg.Options) error {
+	pw := Writer{
+		Options:  ojg.DefaultOptions,
+		Width:    80,
+		MaxDepth: 3,
+		Align:    f.Align,
+	}
+	switch f.Name() {
+	case "json":
+	case "sen":
+		pw.SEN = true
+	default:
+		return f.Format.Write(w, data, opts)
+	}
+	if 0 < f.Width {
+		pw.Width = f.Width
+	}
+	if 0 < f.MaxDepth {
+		pw.MaxDepth = f.MaxDepth
+	}
+	if opts != nil {
+		pw.Options = *opts
+	}
+	return pw.Write(w, data)
+}
+
+// Bytes returns the data written pretty as with Write.
+func (f *Format) Bytes(data any, opts *ojg.Options) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := f.Write(&buf, data, opts); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -99,6 +99,7 @@ func (w *Writer) config(args []any) {
 }
 
 func (w *Writer) encode(data any) (out []byte, err error) {
+	w.ApplyColorScheme()
 	if w.InitSize == 0 {
 		w.InitSize = 256
 	}
@@ -109,7 +110,8 @@ func (w *Writer) encode(data any) (out []byte, err error) {
 		w.WriteLimit = 1024
 	}
 	if cap(w.buf) < w.InitSize {
-		w.buf = make([]byte, 0, w.InitSize)
+		ojg.Free(w.Allocator, w.buf)
+		w.buf = ojg.Alloc(w.Allocator, w.InitSize)
 	} else {
 		w.buf = w.buf[:0]
 	}
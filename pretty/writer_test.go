@@ -488,3 +488,35 @@ func TestWriteFloatFormat(t *testing.T) {
 	j = wr.Encode(float32(1.234))
 	tt.Equal(t, `01.23`, string(j))
 }
+
+func TestWriteFloatOptions(t *testing.T) {
+	var wr pretty.Writer
+	wr.FloatDecimal = true
+	tt.Equal(t, "[\n 1.0,\n 2.5,\n 2\n]", string(wr.Encode([]any{1.0, float32(2.5), 2})))
+
+	wr = pretty.Writer{}
+	wr.FloatVerb = 'e'
+	wr.FloatPrecision = 2
+	tt.Equal(t, `1.23e+03`, string(wr.Encode(1234.5)))
+}
+
+func TestFormat(t *testing.T) {
+	data := sen.MustParse([]byte(sample))
+	for _, name := range []string{"json", "sen"} {
+		f := &pretty.Format{Format: ojg.LookupFormat(name), Width: 20}
+		tt.Equal(t, name, f.Name())
+		b, err := f.Bytes(data, nil)
+		tt.Nil(t, err)
+		if name == "json" {
+			tt.Equal(t, pretty.JSON(data, 20), string(b))
+		} else {
+			tt.Equal(t, pretty.SEN(data, 20), string(b))
+		}
+		var sb strings.Builder
+		tt.Nil(t, f.Write(&sb, data, &ojg.Options{Sort: true}))
+		tt.Equal(t, string(b), sb.String())
+	}
+	v, err := (&pretty.Format{Format: oj.JSONFormat}).Parse([]byte(`[1]`))
+	tt.Nil(t, err)
+	tt.Equal(t, []any{1}, v)
+}
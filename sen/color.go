@@ -58,23 +58,33 @@ func (wr *Writer) colorSEN(data any, depth int) {
 
 	case float32:
 		wr.buf = append(wr.buf, wr.NumberColor...)
-		wr.buf = strconv.AppendFloat(wr.buf, float64(td), 'g', -1, 32)
+		wr.appendFloat(float64(td), 32)
 	case float64:
 		wr.buf = append(wr.buf, wr.NumberColor...)
-		wr.buf = strconv.AppendFloat(wr.buf, td, 'g', -1, 64)
+		wr.appendFloat(td, 64)
 
 	case string:
 		wr.buf = append(wr.buf, wr.StringColor...)
-		wr.buf = ojg.AppendSENString(wr.buf, td, !wr.HTMLUnsafe)
+		wr.buf = wr.appendString(wr.buf, td, !wr.HTMLUnsafe)
 
 	case time.Time:
 		wr.buf = append(wr.buf, wr.TimeColor...)
 		wr.buf = wr.AppendTime(wr.buf, td, true)
 
 	case []any:
+		if td == nil && wr.NilCollections == ojg.NilAsNull {
+			wr.buf = append(wr.buf, wr.NullColor...)
+			wr.buf = append(wr.buf, "null"...)
+			break
+		}
 		wr.colorArray(td, depth)
 
 	case map[string]any:
+		if td == nil && wr.NilCollections == ojg.NilAsNull {
+			wr.buf = append(wr.buf, wr.NullColor...)
+			wr.buf = append(wr.buf, "null"...)
+			break
+		}
 		wr.colorObject(td, depth)
 
 	default:
@@ -212,7 +222,7 @@ func (wr *Writer) colorObject(n map[string]any, depth int) {
 			}
 			wr.buf = append(wr.buf, []byte(cs)...)
 			wr.buf = append(wr.buf, wr.KeyColor...)
-			wr.buf = ojg.AppendSENString(wr.buf, k, !wr.HTMLUnsafe)
+			wr.buf = wr.appendString(wr.buf, k, !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, wr.NoColor...)
 			wr.buf = append(wr.buf, wr.SyntaxColor...)
 			wr.buf = append(wr.buf, ':')
@@ -249,7 +259,7 @@ func (wr *Writer) colorObject(n map[string]any, depth int) {
 			}
 			wr.buf = append(wr.buf, []byte(cs)...)
 			wr.buf = append(wr.buf, wr.KeyColor...)
-			wr.buf = ojg.AppendSENString(wr.buf, k, !wr.HTMLUnsafe)
+			wr.buf = wr.appendString(wr.buf, k, !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, wr.NoColor...)
 			wr.buf = append(wr.buf, wr.SyntaxColor...)
 			wr.buf = append(wr.buf, ':')
@@ -256,3 +256,17 @@ func TestColorMustSen(t *testing.T) {
 	}}
 	tt.Equal(t, "btruex", string(wr.MustSEN(true)))
 }
+
+func TestColorScheme(t *testing.T) {
+	opt := sen.Options{Color: true, Sort: true, ColorScheme: &ojg.ColorScheme{
+		Syntax:  "s",
+		Key:     "k",
+		Null:    "n",
+		Bool:    "b",
+		Number:  "0",
+		String:  "q",
+		NoColor: "x",
+	}}
+	tt.Equal(t, `s{xkaxs:xs[x01x nnullxs]x kbxs:xqcxs}x`,
+		sen.String(map[string]any{"a": []any{1, nil}, "b": "c"}, &opt))
+}
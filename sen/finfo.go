@@ -5,7 +5,9 @@ package sen
 import (
 	"encoding"
 	"encoding/json"
+	"fmt"
 	"reflect"
+	"strings"
 	"unsafe"
 
 	"github.com/ohler55/ojg"
@@ -31,19 +33,208 @@ type appendFunc func(fi *finfo, buf []byte, rv reflect.Value, addr uintptr, safe
 type finfo struct {
 	rt      reflect.Type
 	key     string
+	name    string // struct field name
 	kind    reflect.Kind
 	elem    *sinfo
 	Append  appendFunc
 	iAppend appendFunc
+	sAppend appendFunc // for string and float fields written by the Writer
 	jkey    []byte
 	index   []int
 	offset  uintptr
+	zeroer  byte // isZeroer implemented by the value (v) or pointer (p)
+
+	omitZero  bool
+	tagged    bool // key set by a json tag
+	indirect  bool // reached through an embedded pointer
+	skippable bool // indirect or omitZero so skipField must be checked
+	groups    []string
+}
+
+type isZeroer interface {
+	IsZero() bool
+}
+
+var isZeroerType = reflect.TypeOf((*isZeroer)(nil)).Elem()
+
+// isZero returns true if the field is the zero value. An IsZero() method is
+// used if the field type has one, otherwise reflect.Value.IsZero() is used.
+// Fields of a nil embedded pointer are zero.
+func (f *finfo) isZero(rv reflect.Value) bool {
+	fv, err := rv.FieldByIndexErr(f.index)
+	if err != nil {
+		return true
+	}
+	switch f.zeroer {
+	case 'v':
+		if fv.Kind() == reflect.Ptr && fv.IsNil() {
+			return true
+		}
+		return fv.Interface().(isZeroer).IsZero()
+	case 'p':
+		if fv.CanAddr() {
+			return fv.Addr().Interface().(isZeroer).IsZero()
+		}
+	}
+	return fv.IsZero()
+}
+
+// isEmptyValue returns true for the values encoding/json omits with the
+// omitempty tag option.
+func isEmptyValue(fv reflect.Value) bool {
+	switch fv.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return fv.Len() == 0
+	case reflect.Bool:
+		return !fv.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return fv.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return fv.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return fv.Float() == 0.0
+	case reflect.Interface, reflect.Ptr:
+		return fv.IsNil()
+	}
+	return false
 }
 
 func (f *finfo) keyLen() int {
 	return len(f.jkey)
 }
 
+func appendStringJustKey(fi *finfo, buf []byte, rv reflect.Value, addr uintptr, safe bool) ([]byte, any, appendStatus) {
+	s := rv.FieldByIndex(fi.index).String()
+	buf = append(buf, fi.jkey...)
+	return buf, s, aJustKey
+}
+
+func appendStringJustKeyNotEmpty(fi *finfo, buf []byte, rv reflect.Value, addr uintptr, safe bool) ([]byte, any, appendStatus) {
+	s := rv.FieldByIndex(fi.index).String()
+	if len(s) == 0 {
+		return buf, nil, aSkip
+	}
+	buf = append(buf, fi.jkey...)
+	return buf, s, aJustKey
+}
+
+func appendFloatJustKey(fi *finfo, buf []byte, rv reflect.Value, addr uintptr, safe bool) ([]byte, any, appendStatus) {
+	buf = append(buf, fi.jkey...)
+	return buf, floatFieldValue(fi, rv), aJustKey
+}
+
+func appendFloatJustKeyNotEmpty(fi *finfo, buf []byte, rv reflect.Value, addr uintptr, safe bool) ([]byte, any, appendStatus) {
+	if rv.FieldByIndex(fi.index).Float() == 0.0 {
+		return buf, nil, aSkip
+	}
+	buf = append(buf, fi.jkey...)
+	return buf, floatFieldValue(fi, rv), aJustKey
+}
+
+// floatFieldValue returns the value of a float field as a float32 or
+// float64 even if the field type is a named float type.
+func floatFieldValue(fi *finfo, rv reflect.Value) any {
+	f := rv.FieldByIndex(fi.index).Float()
+	if fi.kind == reflect.Float32 {
+		return float32(f)
+	}
+	return f
+}
+
+func appendStringAsNumber(fi *finfo, buf []byte, rv reflect.Value, addr uintptr, safe bool) ([]byte, any, appendStatus) {
+	s := rv.FieldByIndex(fi.index).String()
+	if !validNumber(s) {
+		panic(fmt.Errorf("%q in field %s is not a valid number", s, fi.key))
+	}
+	buf = append(buf, fi.jkey...)
+	buf = append(buf, s...)
+
+	return buf, nil, aWrote
+}
+
+func appendStringAsNumberNotEmpty(fi *finfo, buf []byte, rv reflect.Value, addr uintptr, safe bool) ([]byte, any, appendStatus) {
+	if rv.FieldByIndex(fi.index).Len() == 0 {
+		return buf, nil, aSkip
+	}
+	return appendStringAsNumber(fi, buf, rv, addr, safe)
+}
+
+// ojgTagOption returns true if the ojg tag of the field includes the
+// option.
+func ojgTagOption(f *reflect.StructField, option string) bool {
+	for _, opt := range strings.Split(f.Tag.Get("ojg"), ",") {
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
+// ojgTagGroups returns the groups listed by the groups= option of the ojg
+// tag of the field. The rest of the tag after groups= is the comma
+// separated list of groups so the option must be the last one in the tag.
+func ojgTagGroups(f *reflect.StructField) []string {
+	tag := f.Tag.Get("ojg")
+	if i := strings.Index(tag, "groups="); i == 0 || (0 < i && tag[i-1] == ',') {
+		return strings.Split(tag[i+len("groups="):], ",")
+	}
+	return nil
+}
+
+// inGroups returns true if the field should be written when the active
+// groups are selected. Fields without groups are always written as are all
+// fields when there are no active groups.
+func (f *finfo) inGroups(active []string) bool {
+	if len(active) == 0 || len(f.groups) == 0 {
+		return true
+	}
+	for _, g := range f.groups {
+		for _, a := range active {
+			if g == a {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// validNumber returns true if s is a JSON number.
+func validNumber(s string) bool {
+	i := 0
+	if i < len(s) && s[i] == '-' {
+		i++
+	}
+	digits := func() int {
+		start := i
+		for i < len(s) && '0' <= s[i] && s[i] <= '9' {
+			i++
+		}
+		return i - start
+	}
+	switch n := digits(); {
+	case n == 0:
+		return false
+	case 1 < n && s[i-n] == '0':
+		return false
+	}
+	if i < len(s) && s[i] == '.' {
+		i++
+		if digits() == 0 {
+			return false
+		}
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i++
+		if i < len(s) && (s[i] == '+' || s[i] == '-') {
+			i++
+		}
+		if digits() == 0 {
+			return false
+		}
+	}
+	return i == len(s)
+}
+
 func appendJustKey(fi *finfo, buf []byte, rv reflect.Value, addr uintptr, safe bool) ([]byte, any, appendStatus) {
 	v := rv.FieldByIndex(fi.index).Interface()
 	buf = append(buf, fi.jkey...)
@@ -133,10 +324,18 @@ func newFinfo(f *reflect.StructField, key string, omitEmpty, asString, pretty, e
 	fi := finfo{
 		rt:     f.Type,
 		key:    key,
+		name:   f.Name,
 		kind:   f.Type.Kind(),
 		index:  f.Index,
 		offset: f.Offset,
 	}
+	switch {
+	case fi.rt.Implements(isZeroerType):
+		fi.zeroer = 'v'
+	case reflect.PointerTo(fi.rt).Implements(isZeroerType):
+		fi.zeroer = 'p'
+	}
+	fi.groups = ojgTagGroups(f)
 	var fx byte
 	// Check for interfaces first since almost any type can implement one of
 	// the supported interfaces.
@@ -192,20 +391,40 @@ func newFinfo(f *reflect.StructField, key string, omitEmpty, asString, pretty, e
 		fi.Append = uint64AppendFuncs[fx]
 		fi.iAppend = uint64AppendFuncs[fx|embedMask]
 
-	case reflect.Float32:
-		fi.Append = float32AppendFuncs[fx]
-		fi.iAppend = float32AppendFuncs[fx|embedMask]
-	case reflect.Float64:
-		fi.Append = float64AppendFuncs[fx]
-		fi.iAppend = float64AppendFuncs[fx|embedMask]
+	case reflect.Float32, reflect.Float64:
+		if fi.kind == reflect.Float32 {
+			fi.Append = float32AppendFuncs[fx]
+			fi.iAppend = float32AppendFuncs[fx|embedMask]
+		} else {
+			fi.Append = float64AppendFuncs[fx]
+			fi.iAppend = float64AppendFuncs[fx|embedMask]
+		}
+		switch {
+		case asString:
+		case omitEmpty:
+			fi.sAppend = appendFloatJustKeyNotEmpty
+		default:
+			fi.sAppend = appendFloatJustKey
+		}
 
 	case reflect.String:
-		if omitEmpty {
+		switch {
+		case ojgTagOption(f, "asnumber"):
+			if omitEmpty {
+				fi.Append = appendStringAsNumberNotEmpty
+				fi.iAppend = appendStringAsNumberNotEmpty
+			} else {
+				fi.Append = appendStringAsNumber
+				fi.iAppend = appendStringAsNumber
+			}
+		case omitEmpty:
 			fi.Append = appendSENStringNotEmpty
 			fi.iAppend = appendSENStringNotEmpty
-		} else {
+			fi.sAppend = appendStringJustKeyNotEmpty
+		default:
 			fi.Append = appendSENString
 			fi.iAppend = appendSENString
+			fi.sAppend = appendStringJustKey
 		}
 	case reflect.Struct:
 		fi.elem = getTypeStruct(fi.rt, true, omitEmpty)
@@ -226,7 +445,7 @@ func newFinfo(f *reflect.StructField, key string, omitEmpty, asString, pretty, e
 			fi.Append = appendJustKey
 			fi.iAppend = appendJustKey
 		}
-	case reflect.Interface:
+	case reflect.Interface, reflect.Chan, reflect.Func, reflect.UnsafePointer:
 		if omitEmpty {
 			fi.Append = appendPtrNotEmpty
 			fi.iAppend = appendPtrNotEmpty
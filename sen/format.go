@@ -0,0 +1,60 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package sen
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/ohler55/ojg"
+)
+
+// SENFormat is the ojg.Format for SEN. It is registered with the name
+// "sen". Since SEN is a superset of JSON the format also parses JSON.
+var SENFormat ojg.Format = senFormat{}
+
+func init() {
+	ojg.RegisterFormat(SENFormat)
+}
+
+type senFormat struct{}
+
+func (senFormat) Name() string {
+	return "sen"
+}
+
+func (senFormat) Parse(buf []byte) (any, error) {
+	var p Parser
+	return p.Parse(buf)
+}
+
+func (senFormat) ParseReader(r io.Reader, cb func(any)) error {
+	var p Parser
+	_, err := p.ParseReader(r, cb)
+	return err
+}
+
+func (senFormat) Tokenize(buf []byte, handler ojg.TokenHandler) error {
+	var t Tokenizer
+	return t.Parse(buf, handler)
+}
+
+func (senFormat) TokenizeLoad(r io.Reader, handler ojg.TokenHandler) error {
+	return TokenizeLoad(r, handler)
+}
+
+func (senFormat) Write(w io.Writer, data any, opts *ojg.Options) error {
+	wr := Writer{Options: DefaultOptions}
+	if opts != nil {
+		wr.Options = *opts
+	}
+	return wr.Write(w, data)
+}
+
+func (f senFormat) Bytes(data any, opts *ojg.Options) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := f.Write(&buf, data, opts); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"unicode/utf16"
 	"unicode/utf8"
 
 	"github.com/ohler55/ojg"
@@ -42,11 +43,15 @@ type Parser struct {
 	cb         func(any)
 	resultChan chan any
 	line       int
-	noff       int // Offset of last newline from start of buf. Can be negative when using a reader.
-	ri         int // read index for null, false, and true
+	noff       int    // Offset of last newline from start of buf. Can be negative when using a reader.
+	base       int    // Offset of the start of buf in the document.
+	src        []byte // The buffer being processed, used for error snippets.
+	ri         int    // read index for null, false, and true
 	mi         int
 	num        gen.Number
 	rn         rune
+	hi         rune // pending high surrogate
+	hiEnd      int
 	result     any
 	mode       string
 	lastKey    gen.Key
@@ -61,6 +66,23 @@ type Parser struct {
 	// OnlyOne returns an error if more than one JSON is in the string or stream.
 	OnlyOne bool
 
+	// SpecialFloats if true parses the inf, -inf, Infinity, -Infinity, nan,
+	// and NaN tokens as float64 values instead of as strings.
+	SpecialFloats bool
+
+	// HexNumbers if true parses integers with a 0x or 0X prefix such as 0x1F
+	// or -0xff as hexadecimal numbers.
+	HexNumbers bool
+
+	// StrictTokens if true returns an error for unquoted string values. Only
+	// null, true, false, and the tokens enabled by SpecialFloats are allowed
+	// as unquoted values. Unquoted object keys are still allowed.
+	StrictTokens bool
+
+	// Allocator if not nil provides the read buffer used by ParseReader and
+	// the buffer used for tokens.
+	Allocator ojg.Allocator
+
 	plus bool
 }
 
@@ -119,7 +141,7 @@ func (p *Parser) Parse(buf []byte, args ...any) (any, error) {
 	}
 	if p.stack == nil {
 		p.stack = make([]any, 0, stackInitSize)
-		p.tmp = make([]byte, 0, tmpInitSize)
+		p.tmp = ojg.Alloc(p.Allocator, tmpInitSize)
 		p.starts = make([]int, 0, 16)
 		p.maps = make([]map[string]any, 0, 16)
 	} else {
@@ -130,19 +152,22 @@ func (p *Parser) Parse(buf []byte, args ...any) (any, error) {
 	p.result = nil
 	p.noff = -1
 	p.line = 1
+	p.base = 0
 	p.mode = valueMap
 	p.mi = 0
 	var err error
 	// Skip BOM if present.
 	if 3 < len(buf) && buf[0] == 0xEF {
 		if buf[1] == 0xBB && buf[2] == 0xBF {
+			p.base = 3
 			err = p.parseBuffer(buf[3:], true)
 		} else {
-			return nil, fmt.Errorf("expected BOM at 1:3")
+			return nil, ojg.Errorf(ojg.ErrSyntax, "expected BOM at 1:3")
 		}
 	} else {
 		err = p.parseBuffer(buf, true)
 	}
+	p.src = nil
 	p.stack = p.stack[:cap(p.stack)]
 	for i := len(p.stack) - 1; 0 <= i; i-- {
 		p.stack[i] = nil
@@ -187,7 +212,7 @@ func (p *Parser) ParseReader(r io.Reader, args ...any) (data any, err error) {
 	}
 	if p.stack == nil {
 		p.stack = make([]any, 0, stackInitSize)
-		p.tmp = make([]byte, 0, tmpInitSize)
+		p.tmp = ojg.Alloc(p.Allocator, tmpInitSize)
 		p.starts = make([]int, 0, 16)
 		p.maps = make([]map[string]any, 0, 16)
 	} else {
@@ -198,8 +223,13 @@ func (p *Parser) ParseReader(r io.Reader, args ...any) (data any, err error) {
 	p.result = nil
 	p.noff = -1
 	p.line = 1
+	p.base = 0
 	p.mi = 0
-	buf := make([]byte, readBufSize)
+	buf := ojg.Alloc(p.Allocator, readBufSize)[:readBufSize]
+	defer func() {
+		p.src = nil
+		ojg.Free(p.Allocator, buf)
+	}()
 	eof := false
 	var cnt int
 	cnt, err = r.Read(buf)
@@ -217,11 +247,11 @@ func (p *Parser) ParseReader(r io.Reader, args ...any) (data any, err error) {
 		skip = 3
 	}
 	for {
-		if 0 < skip {
-			err = p.parseBuffer(buf[skip:], eof)
-		} else {
-			err = p.parseBuffer(buf, eof)
-		}
+		chunk := buf[skip:]
+		p.base += skip
+		err = p.parseBuffer(chunk, eof)
+		p.noff -= len(chunk)
+		p.base += len(chunk)
 		if err != nil {
 			p.stack = p.stack[:cap(p.stack)]
 			for i := len(p.stack) - 1; 0 <= i; i-- {
@@ -251,6 +281,7 @@ func (p *Parser) ParseReader(r io.Reader, args ...any) (data any, err error) {
 }
 
 func (p *Parser) parseBuffer(buf []byte, last bool) (err error) {
+	p.src = buf
 	var b byte
 	var i int
 	var off int
@@ -307,7 +338,9 @@ func (p *Parser) parseBuffer(buf []byte, last bool) (err error) {
 				p.mode = valueMap
 				continue
 			}
-			p.addTokenWith(string(buf[start:off]), off)
+			if err = p.addTokenWith(string(buf[start:off]), off); err != nil {
+				return
+			}
 			off--
 		case strOk:
 			p.tmp = append(p.tmp, b)
@@ -327,7 +360,9 @@ func (p *Parser) parseBuffer(buf []byte, last bool) (err error) {
 						return
 					}
 				case 't':
-					p.addToken(off)
+					if err = p.addToken(off); err != nil {
+						return
+					}
 				}
 			}
 			p.starts = append(p.starts, -1)
@@ -352,7 +387,7 @@ func (p *Parser) parseBuffer(buf []byte, last bool) (err error) {
 		case closeObject:
 			depth--
 			if depth < 0 || 0 <= p.starts[depth] {
-				return p.newError(off, "unexpected object close")
+				return p.newError(off, ojg.ErrUnexpectedChar, "unexpected object close")
 			}
 			if 256 < len(p.mode) {
 				switch p.mode[256] {
@@ -361,7 +396,9 @@ func (p *Parser) parseBuffer(buf []byte, last bool) (err error) {
 						return
 					}
 				case 't':
-					p.addToken(off)
+					if err = p.addToken(off); err != nil {
+						return
+					}
 				}
 			}
 			p.starts = p.starts[0:depth]
@@ -445,7 +482,9 @@ func (p *Parser) parseBuffer(buf []byte, last bool) (err error) {
 						return
 					}
 				case 't':
-					p.addToken(off)
+					if err = p.addToken(off); err != nil {
+						return
+					}
 				}
 			}
 			p.starts = append(p.starts, len(p.stack))
@@ -456,7 +495,7 @@ func (p *Parser) parseBuffer(buf []byte, last bool) (err error) {
 		case closeArray:
 			depth--
 			if depth < 0 || p.starts[depth] < 0 {
-				return p.newError(off, "unexpected array close")
+				return p.newError(off, ojg.ErrUnexpectedChar, "unexpected array close")
 			}
 			// Only modes with a close array are value, token, and numbers
 			// which are all over 256 long.
@@ -465,7 +504,9 @@ func (p *Parser) parseBuffer(buf []byte, last bool) (err error) {
 				// can not fail appending to an array
 				_ = p.add(p.num.AsNum(), off)
 			case 't':
-				p.addToken(off)
+				if err = p.addToken(off); err != nil {
+					return
+				}
 			}
 			start := p.starts[len(p.starts)-1] + 1
 			p.starts = p.starts[:len(p.starts)-1]
@@ -511,12 +552,18 @@ func (p *Parser) parseBuffer(buf []byte, last bool) (err error) {
 		case tokenOk:
 			p.tmp = append(p.tmp, b)
 		case tokenSpc:
-			p.addToken(off)
+			if err = p.addToken(off); err != nil {
+				return
+			}
 		case tokenColon:
-			p.addToken(off)
+			if err = p.addToken(off); err != nil {
+				return
+			}
 			p.mode = valueMap
 		case tokenNlColon:
-			p.addToken(off)
+			if err = p.addToken(off); err != nil {
+				return
+			}
 			p.line++
 			p.noff = off
 			for i, b = range buf[off+1:] {
@@ -581,7 +628,19 @@ func (p *Parser) parseBuffer(buf []byte, last bool) (err error) {
 				if len(p.runeBytes) < 6 {
 					p.runeBytes = make([]byte, 6)
 				}
-				n := utf8.EncodeRune(p.runeBytes, p.rn)
+				r := p.rn
+				switch {
+				case 0xD800 <= r && r < 0xDC00:
+					// A high surrogate is written as U+FFFD unless followed
+					// by a low surrogate escape.
+					p.hi = r
+					p.hiEnd = len(p.tmp) + 3
+				case 0xDC00 <= r && r < 0xE000 && p.hi != 0 && p.hiEnd == len(p.tmp):
+					p.tmp = p.tmp[:len(p.tmp)-3]
+					r = utf16.DecodeRune(p.hi, r)
+					p.hi = 0
+				}
+				n := utf8.EncodeRune(p.runeBytes, r)
 				p.tmp = append(p.tmp, p.runeBytes[:n]...)
 				p.mode = stringMap
 			}
@@ -594,7 +653,9 @@ func (p *Parser) parseBuffer(buf []byte, last bool) (err error) {
 						return
 					}
 				case 't':
-					p.addToken(off)
+					if err = p.addToken(off); err != nil {
+						return
+					}
 				}
 			}
 			p.mode = commentStartMap
@@ -622,7 +683,7 @@ func (p *Parser) parseBuffer(buf []byte, last bool) (err error) {
 		case closeParen:
 			depth--
 			if depth < 0 || p.starts[depth] < 0 {
-				return p.newError(off, "unexpected function close")
+				return p.newError(off, ojg.ErrUnexpectedChar, "unexpected function close")
 			}
 			// Only modes with a close paren are value, token, and numbers
 			// which are all over 256 long.
@@ -631,19 +692,28 @@ func (p *Parser) parseBuffer(buf []byte, last bool) (err error) {
 				// can not fail appending to a function argument set
 				_ = p.add(p.num.AsNum(), off)
 			case 't':
-				p.addToken(off)
+				if err = p.addToken(off); err != nil {
+					return
+				}
 			}
 			start := p.starts[len(p.starts)-1] + 1
 			p.starts = p.starts[:len(p.starts)-1]
 			tf, _ := p.stack[start-1].(TokenFunc)
 			if tf == nil {
-				return p.newError(off, "unexpected character '%c'", b)
+				return p.newError(off, ojg.ErrUnexpectedChar, "unexpected character '%c'", b)
 			}
 			v := tf(p.stack[start:]...)
 			p.stack = p.stack[0 : start-1]
 			_ = p.add(v, off)
 			p.mode = valueMap
+		case hexDigit:
+			if err = p.addHexDigit(off, b); err != nil {
+				return
+			}
 		case charErr:
+			if (p.SpecialFloats || p.HexNumbers) && p.special(b) {
+				continue
+			}
 			return p.byteError(off, p.mode, b, bytes.Runes(buf[off:])[0])
 		}
 		if depth == 0 && 256 < len(p.mode) && p.mode[256] == 'v' {
@@ -668,10 +738,10 @@ func (p *Parser) parseBuffer(buf []byte, last bool) (err error) {
 	}
 	if last {
 		if 0 < len(p.starts) {
-			return p.newError(off, "not closed")
+			return p.newError(off, ojg.ErrUnexpectedEOF, "not closed")
 		}
 		if len(p.mode) == 256 { // valid finishing maps are one byte longer
-			return p.newError(off, "incomplete JSON")
+			return p.newError(off, ojg.ErrUnexpectedEOF, "incomplete JSON")
 		}
 		switch p.mode[256] {
 		case 'n': // number
@@ -687,7 +757,9 @@ func (p *Parser) parseBuffer(buf []byte, last bool) (err error) {
 				}
 			}
 		case 't': // token
-			p.addToken(off)
+			if err = p.addToken(off); err != nil {
+				return
+			}
 			if p.cb == nil && p.resultChan == nil {
 				p.result = p.stack[0]
 			} else {
@@ -714,7 +786,7 @@ func (p *Parser) add(n any, off int) error {
 				p.lastKey = k
 				p.stack = p.stack[0 : len(p.stack)-1]
 			} else {
-				return p.newError(off, "expected a key")
+				return p.newError(off, ojg.ErrUnexpectedChar, "expected a key")
 			}
 		} else { // array
 			p.stack = append(p.stack, n)
@@ -725,81 +797,36 @@ func (p *Parser) add(n any, off int) error {
 	return nil
 }
 
-func (p *Parser) addToken(off int) {
-	s := string(p.tmp)
-	p.mode = valueMap
-	if 0 < len(p.starts) {
-		if p.starts[len(p.starts)-1] == -1 { // object
-			if k, ok := p.stack[len(p.stack)-1].(gen.Key); ok {
-				obj, _ := p.stack[len(p.stack)-2].(map[string]any)
-				switch s {
-				case "null":
-					obj[string(k)] = nil
-				case "true":
-					obj[string(k)] = true
-				case "false":
-					obj[string(k)] = false
-				default:
-					obj[string(k)] = s
-				}
-				p.lastKey = k
-				p.stack = p.stack[0 : len(p.stack)-1]
-			} else {
-				p.stack = append(p.stack, gen.Key(s))
-				p.mode = colonMap
-			}
-			return
-		}
-	}
-	// Array or just a value
-	switch s {
-	case "null":
-		p.stack = append(p.stack, nil)
-	case "true":
-		p.stack = append(p.stack, true)
-	case "false":
-		p.stack = append(p.stack, false)
-	default:
-		p.stack = append(p.stack, s)
-	}
+func (p *Parser) addToken(off int) error {
+	return p.addTokenWith(string(p.tmp), off)
 }
 
-func (p *Parser) addTokenWith(s string, off int) {
+func (p *Parser) addTokenWith(s string, off int) error {
 	p.mode = valueMap
 	if 0 < len(p.starts) {
 		if p.starts[len(p.starts)-1] == -1 { // object
 			if k, ok := p.stack[len(p.stack)-1].(gen.Key); ok {
-				obj, _ := p.stack[len(p.stack)-2].(map[string]any)
-				switch s {
-				case "null":
-					obj[string(k)] = nil
-				case "true":
-					obj[string(k)] = true
-				case "false":
-					obj[string(k)] = false
-				default:
-					obj[string(k)] = s
+				v, err := p.tokenValue(s, off)
+				if err != nil {
+					return err
 				}
+				obj, _ := p.stack[len(p.stack)-2].(map[string]any)
+				obj[string(k)] = v
 				p.lastKey = k
 				p.stack = p.stack[0 : len(p.stack)-1]
 			} else {
 				p.stack = append(p.stack, gen.Key(s))
 				p.mode = colonMap
 			}
-			return
+			return nil
 		}
 	}
 	// Array or just a value
-	switch s {
-	case "null":
-		p.stack = append(p.stack, nil)
-	case "true":
-		p.stack = append(p.stack, true)
-	case "false":
-		p.stack = append(p.stack, false)
-	default:
-		p.stack = append(p.stack, s)
+	v, err := p.tokenValue(s, off)
+	if err == nil {
+		p.stack = append(p.stack, v)
 	}
+	return err
 }
 
 func (p *Parser) addString(s string, off int) {
@@ -839,30 +866,42 @@ func (p *Parser) addString(s string, off int) {
 	p.stack = append(p.stack, s)
 }
 
-func (p *Parser) newError(off int, format string, args ...any) error {
-	return &oj.ParseError{
+func (p *Parser) newError(off int, kind error, format string, args ...any) error {
+	err := &oj.ParseError{
 		Message: fmt.Sprintf(format, args...),
 		Line:    p.line,
 		Column:  off - p.noff,
+		Offset:  p.base + off,
+		Kind:    kind,
 	}
+	err.SetSnippet(p.src, off)
+
+	return err
 }
 
 func (p *Parser) byteError(off int, mode string, b byte, r rune) error {
 	err := &oj.ParseError{
 		Line:   p.line,
 		Column: off - p.noff,
+		Offset: p.base + off,
+		Kind:   ojg.ErrUnexpectedChar,
 	}
+	err.SetSnippet(p.src, off)
 	switch mode {
 	case colonMap:
 		err.Message = fmt.Sprintf("expected a colon, not '%c'", r)
 	case negMap, zeroMap, digitMap, dotMap, fracMap, expSignMap, expZeroMap, expMap:
 		err.Message = "invalid number"
+		err.Kind = ojg.ErrInvalidNumber
 	case stringMap:
 		err.Message = fmt.Sprintf("invalid JSON character 0x%02x", b)
+		err.Kind = ojg.ErrInvalidString
 	case escMap:
 		err.Message = fmt.Sprintf("invalid JSON escape character '\\%c'", r)
+		err.Kind = ojg.ErrInvalidString
 	case uMap:
 		err.Message = fmt.Sprintf("invalid JSON unicode character '%c'", r)
+		err.Kind = ojg.ErrInvalidString
 	case spaceMap:
 		err.Message = fmt.Sprintf("extra characters after close, '%c'", r)
 	default:
@@ -5,6 +5,7 @@ package sen_test
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"strings"
 	"testing"
 	"testing/iotest"
@@ -83,6 +84,8 @@ func TestParserParseString(t *testing.T) {
 		{src: "[[true]]", value: []any{[]any{true}}},
 		{src: `"x\t\n\"\b\f\r\u0041\\\/y"`, value: "x\t\n\"\b\f\r\u0041\\/y"},
 		{src: `"x\u004a\u004Ay"`, value: "xJJy"},
+		{src: `"x\ud83d\ude00y"`, value: "x\U0001f600y"},
+		{src: `"\ud83dx\ude00"`, value: "\ufffdx\ufffd"},
 		{src: `"x\ry"`, value: "x\ry"},
 
 		{src: "{}", value: map[string]any{}},
@@ -215,10 +218,10 @@ func TestParserParseReader(t *testing.T) {
 		{src: strings.Repeat(" ", 4093) + "[abc// comment\n]", value: []any{"abc"}},
 		{src: strings.Repeat(" ", 4093) + "[abc{x:1}]", value: []any{"abc", map[string]any{"x": 1}}},
 
-		{src: strings.Repeat(" ", 4094) + "abc#", expect: "unexpected character '#' at 1:2"},
+		{src: strings.Repeat(" ", 4094) + "abc#", expect: "unexpected character '#' at 1:4098"},
 		{src: strings.Repeat(" ", 4094) + "hello\n #", expect: "extra characters after close, '#' at 2:2"},
-		{src: strings.Repeat(" ", 4094) + "hello]", expect: "unexpected array close at 1:4"},
-		{src: strings.Repeat(" ", 4094) + "hello}", expect: "unexpected object close at 1:4"},
+		{src: strings.Repeat(" ", 4094) + "hello]", expect: "unexpected array close at 1:4100"},
+		{src: strings.Repeat(" ", 4094) + "hello}", expect: "unexpected object close at 1:4100"},
 		{src: strings.Repeat(" ", 4095) + `"x"`, value: "x"},
 	} {
 		if testing.Verbose() {
@@ -553,3 +556,39 @@ func TestParserCComment(t *testing.T) {
 	v = sen.MustParse([]byte(src))
 	tt.Equal(t, []any{"abc", "ghi"}, v)
 }
+
+func TestParserSpecialTokens(t *testing.T) {
+	p := sen.Parser{SpecialFloats: true, HexNumbers: true}
+	v, err := p.Parse([]byte("[inf -inf Infinity -Infinity 0x1f -0xFF abc]"))
+	tt.Nil(t, err)
+	tt.Equal(t, []any{math.Inf(1), math.Inf(-1), math.Inf(1), math.Inf(-1), int64(31), int64(-255), "abc"}, v)
+
+	v, err = p.Parse([]byte("{a:nan b:0x10}"))
+	tt.Nil(t, err)
+	obj, _ := v.(map[string]any)
+	tt.Equal(t, true, math.IsNaN(obj["a"].(float64)))
+	tt.Equal(t, int64(16), obj["b"])
+
+	v, err = p.ParseReader(strings.NewReader("0xA"))
+	tt.Nil(t, err)
+	tt.Equal(t, int64(10), v)
+
+	for _, src := range []string{"[0x]", "0x", "[-abc]", "[0x1ffffffffffffffff]"} {
+		_, err = p.Parse([]byte(src))
+		tt.NotNil(t, err, src)
+	}
+	v, err = sen.Parse([]byte("[inf nan]"))
+	tt.Nil(t, err)
+	tt.Equal(t, []any{"inf", "nan"}, v)
+	_, err = sen.Parse([]byte("[0x1f]"))
+	tt.NotNil(t, err)
+
+	p = sen.Parser{StrictTokens: true}
+	v, err = p.Parse([]byte(`{abc:[true null "x"]}`))
+	tt.Nil(t, err)
+	tt.Equal(t, map[string]any{"abc": []any{true, nil, "x"}}, v)
+	_, err = p.Parse([]byte("{a:b}"))
+	tt.NotNil(t, err)
+	_, err = p.Parse([]byte("[b]"))
+	tt.NotNil(t, err)
+}
@@ -23,6 +23,10 @@ var (
 	// HTMLOptions are the options that can be used to encode as HTML JSON.
 	HTMLOptions = ojg.HTMLOptions
 
+	// HTMLClassOptions are the options that can be used to encode as HTML
+	// JSON with CSS classes for the colors.
+	HTMLClassOptions = ojg.HTMLClassOptions
+
 	writerPool = sync.Pool{
 		New: func() any {
 			return &Writer{Options: DefaultOptions, buf: make([]byte, 0, 1024)}
@@ -215,7 +219,9 @@ func MatchString(data string, onData func(path jp.Expr, data any), targets ...jp
 }
 
 // MatchLoad parses a JSON document from an io.Reader and calls onData when a
-// data element that matches the target path is encountered.
+// data element that matches the target path is encountered. The document is
+// evaluated as it is read and only the matching elements, or for a target
+// with a filter the elements being filtered, are built.
 func MatchLoad(r io.Reader, onData func(path jp.Expr, data any), targets ...jp.Expr) error {
 	return TokenizeLoad(r, jp.NewMatchHandler(onData, targets...))
 }
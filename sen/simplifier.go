@@ -19,10 +19,11 @@ func appendSimplifier(fi *finfo, buf []byte, rv reflect.Value, addr uintptr, saf
 }
 
 func appendSimplifierNotEmpty(fi *finfo, buf []byte, rv reflect.Value, addr uintptr, safe bool) ([]byte, any, appendStatus) {
-	v := rv.FieldByIndex(fi.index).Interface()
-	if (*[2]uintptr)(unsafe.Pointer(&v))[1] == 0 { // real nil check
+	fv := rv.FieldByIndex(fi.index)
+	if isEmptyValue(fv) {
 		return buf, nil, aSkip
 	}
+	v := fv.Interface()
 	buf = append(buf, fi.jkey...)
 	if s, ok := v.(alt.Simplifier); ok {
 		v = s.Simplify()
@@ -9,6 +9,8 @@ import (
 	"strings"
 	"sync"
 	"unsafe"
+
+	"github.com/ohler55/ojg/alt"
 )
 
 const (
@@ -85,117 +87,82 @@ func buildFields(rt reflect.Type, u byte, embedded, omitEmpty bool) (fa []*finfo
 }
 
 func buildTagFields(rt reflect.Type, out, pretty, embedded, omitEmpty bool) (fa []*finfo) {
-	for i := rt.NumField() - 1; 0 <= i; i-- {
-		f := rt.Field(i)
-		name := []byte(f.Name)
-		if len(name) == 0 || 'a' <= name[0] {
-			continue
-		}
-		if f.Anonymous && !out {
-			if f.Type.Kind() == reflect.Ptr {
-				for _, fi := range buildTagFields(f.Type.Elem(), out, pretty, embedded, omitEmpty) {
-					fi.index = append([]int{i}, fi.index...)
-					fi.Append = fi.iAppend
-					fa = append(fa, fi)
-				}
-			} else {
-				for _, fi := range buildTagFields(f.Type, out, pretty, embedded, omitEmpty) {
-					fi.index = append([]int{i}, fi.index...)
-					fi.offset += f.Offset
-					fa = append(fa, fi)
-				}
-			}
-		} else {
-			asString := false
-			key := f.Name
-			if tag, ok := f.Tag.Lookup("json"); ok && 0 < len(tag) {
-				parts := strings.Split(tag, ",")
-				switch parts[0] {
-				case "":
-					key = f.Name
-				case "-":
-					if 1 < len(parts) {
-						key = "-"
-					} else {
-						continue
-					}
-				default:
-					key = parts[0]
-				}
-				for _, p := range parts[1:] {
-					switch p {
-					case "omitempty":
-						omitEmpty = true
-					case "string":
-						asString = true
-					}
+	for _, sf := range alt.StructFields(rt, true, !out) {
+		omitEmpty := omitEmpty
+		omitZero := false
+		asString := false
+		if tag := sf.Tag.Get("json"); 0 < len(tag) {
+			for _, p := range strings.Split(tag, ",")[1:] {
+				switch p {
+				case "omitempty":
+					omitEmpty = true
+				case "omitzero":
+					omitZero = true
+				case "string":
+					asString = true
 				}
 			}
-			fa = append(fa, newFinfo(&f, key, omitEmpty, asString, pretty, embedded))
 		}
+		fi := newFinfo(&sf.StructField, sf.Key, omitEmpty, asString, pretty, embedded)
+		fi.omitZero = omitZero
+		fi.tagged = sf.Tagged
+		fa = append(fa, fi.promoted(&sf))
 	}
 	return
 }
 
 func buildExactFields(rt reflect.Type, out, pretty, embedded, omitEmpty bool) (fa []*finfo) {
-	for i := rt.NumField() - 1; 0 <= i; i-- {
-		f := rt.Field(i)
-		name := []byte(f.Name)
-		if len(name) == 0 || 'a' <= name[0] {
-			continue
-		}
-		if f.Anonymous && !out {
-			if f.Type.Kind() == reflect.Ptr {
-				for _, fi := range buildExactFields(f.Type.Elem(), out, pretty, embedded, omitEmpty) {
-					fi.index = append([]int{i}, fi.index...)
-					fi.Append = fi.iAppend
-					fa = append(fa, fi)
-				}
-			} else {
-				for _, fi := range buildExactFields(f.Type, out, pretty, embedded, omitEmpty) {
-					fi.index = append([]int{i}, fi.index...)
-					fi.offset += f.Offset
-					fa = append(fa, fi)
-				}
-			}
-		} else {
-			fa = append(fa, newFinfo(&f, f.Name, omitEmpty, false, pretty, embedded))
-		}
+	for _, sf := range alt.StructFields(rt, false, !out) {
+		fi := newFinfo(&sf.StructField, sf.Name, omitEmpty, false, pretty, embedded)
+		fa = append(fa, fi.promoted(&sf))
 	}
 	return
 }
 
 func buildLowFields(rt reflect.Type, out, pretty, embedded, omitEmpty bool) (fa []*finfo) {
-	for i := rt.NumField() - 1; 0 <= i; i-- {
-		f := rt.Field(i)
-		name := []byte(f.Name)
-		if len(name) == 0 || 'a' <= name[0] {
-			continue
-		}
-		if f.Anonymous && !out {
-			if f.Type.Kind() == reflect.Ptr {
-				for _, fi := range buildLowFields(f.Type.Elem(), out, pretty, embedded, omitEmpty) {
-					fi.index = append([]int{i}, fi.index...)
-					fi.Append = fi.iAppend
-					fa = append(fa, fi)
-				}
-			} else {
-				for _, fi := range buildLowFields(f.Type, out, pretty, embedded, omitEmpty) {
-					fi.index = append([]int{i}, fi.index...)
-					fi.offset += f.Offset
-					fa = append(fa, fi)
-				}
+	for _, sf := range alt.StructFields(rt, false, !out) {
+		name := []byte(sf.Name)
+		if 3 < len(name) {
+			if name[0] < 0x80 {
+				name[0] |= 0x20
 			}
 		} else {
-			if 3 < len(name) {
-				if name[0] < 0x80 {
-					name[0] |= 0x20
-				}
-			} else {
-				name = bytes.ToLower(name)
-			}
-			fa = append(fa, newFinfo(&f, string(name), omitEmpty, false, pretty, embedded))
+			name = bytes.ToLower(name)
 		}
+		fi := newFinfo(&sf.StructField, string(name), omitEmpty, false, pretty, embedded)
+		fa = append(fa, fi.promoted(&sf))
 	}
 	return
 }
+
+// promoted sets the offset of a field from the start of the outer struct
+// and switches to the index based append function if the field is reached
+// through an embedded pointer.
+func (fi *finfo) promoted(sf *alt.StructField) *finfo {
+	fi.offset = sf.Offset
+	if sf.Indirect {
+		fi.Append = fi.iAppend
+		fi.indirect = true
+	}
+	fi.skippable = fi.indirect || fi.omitZero
+	return fi
+}
+
+// skipField returns true if the field is not written because it is not in
+// one of the active Groups, it is behind a nil embedded pointer, or it is
+// zero and has the omitzero tag option or the OmitZero option is set. It is
+// only called if the field is skippable or the Writer skipFields is set.
+func (wr *Writer) skipField(fi *finfo, rv reflect.Value) bool {
+	return !fi.inGroups(wr.Groups) || fi.unreachable(rv) || (fi.omitZero || wr.OmitZero) && fi.isZero(rv)
+}
+
+// unreachable returns true if the field is behind a nil embedded pointer. As
+// with encoding/json such fields are not written.
+func (fi *finfo) unreachable(rv reflect.Value) bool {
+	if !fi.indirect {
+		return false
+	}
+	_, err := rv.FieldByIndexErr(fi.index)
+
+	return err != nil
+}
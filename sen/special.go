@@ -0,0 +1,115 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package sen
+
+import (
+	"math"
+
+	"github.com/ohler55/ojg"
+)
+
+// The hex maps are used when the Parser HexNumbers flag is set. The 'x' in
+// 0x is a charErr in the zeroMap and is handled when charErr is
+// encountered.
+const (
+	hexDigit = 'H'
+
+	//   0123456789abcdef0123456789abcdef
+	hexStartMap = "" +
+		"................................" + // 0x00
+		"................HHHHHHHHHH......" + // 0x20
+		".HHHHHH........................." + // 0x40
+		".HHHHHH........................." + // 0x60
+		"................................" + // 0x80
+		"................................" + // 0xa0
+		"................................" + // 0xc0
+		"................................" //   0xe0
+	//   0123456789abcdef0123456789abcdef
+	hexMap = "" +
+		".........rs..r.................." + // 0x00
+		"r........p..r..cHHHHHHHHHH......" + // 0x20
+		".HHHHHH....................k.m.." + // 0x40
+		".HHHHHH....................l.n.." + // 0x60
+		"................................" + // 0x80
+		"................................" + // 0xa0
+		"................................" + // 0xc0
+		"................................n" //  0xe0
+)
+
+// special handles bytes that are only valid when the SpecialFloats or
+// HexNumbers flags are set. True is returned if the byte was handled.
+func (p *Parser) special(b byte) bool {
+	switch p.mode {
+	case negMap:
+		switch b {
+		case 'i', 'I', 'n', 'N':
+			if p.SpecialFloats {
+				p.tmp = append(p.tmp[:0], '-', b)
+				p.mode = tokenMap
+				return true
+			}
+		}
+	case zeroMap:
+		if p.HexNumbers && (b == 'x' || b == 'X') {
+			p.mode = hexStartMap
+			return true
+		}
+	}
+	return false
+}
+
+// addHexDigit adds a hexadecimal digit to the number being parsed.
+func (p *Parser) addHexDigit(off int, b byte) error {
+	if math.MaxInt64>>4 < p.num.I {
+		return p.newError(off, ojg.ErrInvalidNumber, "hex number too large")
+	}
+	var v byte
+	switch {
+	case b <= '9':
+		v = b - '0'
+	case b <= 'F':
+		v = b - 'A' + 10
+	default:
+		v = b - 'a' + 10
+	}
+	p.num.I = p.num.I<<4 | uint64(v)
+	p.mode = hexMap
+	return nil
+}
+
+// tokenValue returns the value of an unquoted token that is not an object
+// key.
+func (p *Parser) tokenValue(s string, off int) (any, error) {
+	switch s {
+	case "null":
+		return nil, nil
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	if p.SpecialFloats {
+		switch s {
+		case "inf", "Infinity":
+			return math.Inf(1), nil
+		case "-inf", "-Infinity":
+			return math.Inf(-1), nil
+		case "nan", "NaN", "-nan", "-NaN":
+			return math.NaN(), nil
+		}
+	}
+	if p.StrictTokens || (0 < len(s) && s[0] == '-') {
+		return nil, p.newError(off, ojg.ErrInvalidLiteral, "unexpected token '%s'", s)
+	}
+	return s, nil
+}
+
+// isSpecialFloat returns true if the string would be read as a float by a
+// Parser with the SpecialFloats flag set.
+func isSpecialFloat(s string) bool {
+	switch s {
+	case "inf", "-inf", "Infinity", "-Infinity", "nan", "NaN", "-nan", "-NaN":
+		return true
+	}
+	return false
+}
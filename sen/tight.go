@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"reflect"
 	"sort"
-	"strings"
 	"unsafe"
 
 	"github.com/ohler55/ojg"
@@ -28,6 +27,12 @@ func tightDefault(wr *Writer, data any, _ int) {
 			wr.tightSlice(rv, nil)
 		case reflect.Map:
 			wr.tightMap(rv, nil)
+		case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+			if v, ok := wr.Fallback(data); ok {
+				wr.appendSEN(v, 0)
+				return
+			}
+			wr.appendSEN(nil, 0)
 		default:
 			// Not much should get here except Map, Complex and un-decomposable
 			// values.
@@ -35,8 +40,10 @@ func tightDefault(wr *Writer, data any, _ int) {
 			wr.appendSEN(dec, 0)
 			return
 		}
+	} else if v, ok := wr.Fallback(data); ok {
+		wr.appendSEN(v, 0)
 	} else {
-		wr.buf = ojg.AppendSENString(wr.buf, fmt.Sprintf("%v", data), !wr.HTMLUnsafe)
+		wr.buf = wr.appendString(wr.buf, fmt.Sprintf("%v", data), !wr.HTMLUnsafe)
 	}
 }
 
@@ -85,7 +92,7 @@ func tightObject(wr *Writer, n map[string]any, _ int) {
 				continue
 			}
 		}
-		wr.buf = ojg.AppendSENString(wr.buf, k, !wr.HTMLUnsafe)
+		wr.buf = wr.appendString(wr.buf, k, !wr.HTMLUnsafe)
 		wr.buf = append(wr.buf, ':')
 		wr.appendSEN(m, 0)
 		wr.buf = append(wr.buf, ' ')
@@ -126,7 +133,7 @@ func tightSortObject(wr *Writer, n map[string]any, _ int) {
 				continue
 			}
 		}
-		wr.buf = ojg.AppendSENString(wr.buf, k, !wr.HTMLUnsafe)
+		wr.buf = wr.appendString(wr.buf, k, !wr.HTMLUnsafe)
 		wr.buf = append(wr.buf, ':')
 		wr.appendSEN(m, 0)
 		wr.buf = append(wr.buf, ' ')
@@ -143,7 +150,7 @@ func (wr *Writer) tightStruct(rv reflect.Value, si *sinfo) {
 	if si == nil {
 		si = getSinfo(rv.Interface(), wr.OmitEmpty)
 	}
-	fields := si.fields[wr.findex]
+	fields := wr.structFields(si)
 	wr.buf = append(wr.buf, '{')
 	var v any
 	comma := false
@@ -168,9 +175,15 @@ func (wr *Writer) tightStruct(rv reflect.Value, si *sinfo) {
 	}
 	var stat appendStatus
 	for _, fi := range fields {
-		if 0 < addr {
+		if (fi.skippable || wr.skipFields) && wr.skipField(fi, rv) {
+			continue
+		}
+		switch {
+		case wr.slowField && fi.sAppend != nil:
+			wr.buf, v, stat = fi.sAppend(fi, wr.buf, rv, addr, !wr.HTMLUnsafe)
+		case 0 < addr:
 			wr.buf, v, stat = fi.Append(fi, wr.buf, rv, addr, !wr.HTMLUnsafe)
-		} else {
+		default:
 			wr.buf, v, stat = fi.iAppend(fi, wr.buf, rv, addr, !wr.HTMLUnsafe)
 		}
 		switch stat {
@@ -241,6 +254,10 @@ func (wr *Writer) tightStruct(rv reflect.Value, si *sinfo) {
 }
 
 func (wr *Writer) tightSlice(rv reflect.Value, si *sinfo) {
+	if rv.Kind() == reflect.Slice && rv.IsNil() && wr.NilCollections == ojg.NilAsNull {
+		wr.buf = append(wr.buf, "null"...)
+		return
+	}
 	end := rv.Len()
 	comma := false
 	wr.buf = append(wr.buf, '[')
@@ -267,13 +284,20 @@ func (wr *Writer) tightSlice(rv reflect.Value, si *sinfo) {
 }
 
 func (wr *Writer) tightMap(rv reflect.Value, si *sinfo) {
-	wr.buf = append(wr.buf, '{')
-	keys := rv.MapKeys()
-	if wr.Sort {
-		sort.Slice(keys, func(i, j int) bool { return 0 > strings.Compare(keys[i].String(), keys[j].String()) })
+	if rv.IsNil() && wr.NilCollections == ojg.NilAsNull {
+		wr.buf = append(wr.buf, "null"...)
+		return
 	}
+	wr.buf = append(wr.buf, '{')
+	keys, names := alt.MapKeys(rv, wr.Sort)
 	comma := false
-	for _, kv := range keys {
+	for i, kv := range keys {
+		var key string
+		if names == nil {
+			key = kv.String()
+		} else {
+			key = names[i]
+		}
 		rm := rv.MapIndex(kv)
 		if rm.Kind() == reflect.Ptr {
 			if rm.IsNil() {
@@ -286,32 +310,32 @@ func (wr *Writer) tightMap(rv reflect.Value, si *sinfo) {
 		}
 		switch rm.Kind() {
 		case reflect.Struct:
-			wr.buf = ojg.AppendSENString(wr.buf, kv.String(), !wr.HTMLUnsafe)
+			wr.buf = wr.appendString(wr.buf, key, !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, ':')
 			wr.tightStruct(rm, si)
 		case reflect.Slice, reflect.Array:
 			if (wr.OmitNil || wr.OmitEmpty) && rm.Len() == 0 {
 				continue
 			}
-			wr.buf = ojg.AppendSENString(wr.buf, kv.String(), !wr.HTMLUnsafe)
+			wr.buf = wr.appendString(wr.buf, key, !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, ':')
 			wr.tightSlice(rm, si)
 		case reflect.Map:
 			if (wr.OmitNil || wr.OmitEmpty) && rm.Len() == 0 {
 				continue
 			}
-			wr.buf = ojg.AppendSENString(wr.buf, kv.String(), !wr.HTMLUnsafe)
+			wr.buf = wr.appendString(wr.buf, key, !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, ':')
 			wr.tightMap(rm, si)
 		case reflect.String:
 			if (wr.OmitNil || wr.OmitEmpty) && rm.Len() == 0 {
 				continue
 			}
-			wr.buf = ojg.AppendSENString(wr.buf, kv.String(), !wr.HTMLUnsafe)
+			wr.buf = wr.appendString(wr.buf, key, !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, ':')
 			wr.appendSEN(rm.Interface(), 0)
 		default:
-			wr.buf = ojg.AppendSENString(wr.buf, kv.String(), !wr.HTMLUnsafe)
+			wr.buf = wr.appendString(wr.buf, key, !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, ':')
 			wr.appendSEN(rm.Interface(), 0)
 		}
@@ -28,8 +28,10 @@ type Tokenizer struct {
 	starts    []byte
 	handler   oj.TokenHandler
 	line      int
-	noff      int // Offset of last newline from start of buf. Can be negative when using a reader.
-	ri        int // read index for null, false, and true
+	noff      int    // Offset of last newline from start of buf. Can be negative when using a reader.
+	base      int    // Offset of the start of buf in the document.
+	src       []byte // The buffer being processed, used for error snippets.
+	ri        int    // read index for null, false, and true
 	mi        int
 	num       gen.Number
 	rn        rune
@@ -71,6 +73,7 @@ func (t *Tokenizer) Parse(buf []byte, handler oj.TokenHandler) (err error) {
 	}
 	t.noff = -1
 	t.line = 1
+	t.base = 0
 	t.mode = valueMap
 	t.mi = 0
 	defer func() {
@@ -81,9 +84,10 @@ func (t *Tokenizer) Parse(buf []byte, handler oj.TokenHandler) (err error) {
 	// Skip BOM if present.
 	if 3 < len(buf) && buf[0] == 0xEF {
 		if buf[1] == 0xBB && buf[2] == 0xBF {
+			t.base = 3
 			t.tokenizeBuffer(buf[3:], true)
 		} else {
-			return fmt.Errorf("expected BOM at 1:3")
+			return ojg.Errorf(ojg.ErrSyntax, "expected BOM at 1:3")
 		}
 	} else {
 		t.tokenizeBuffer(buf, true)
@@ -103,6 +107,7 @@ func (t *Tokenizer) Load(r io.Reader, handler oj.TokenHandler) (err error) {
 	}
 	t.noff = -1
 	t.line = 1
+	t.base = 0
 	t.mi = 0
 	buf := make([]byte, readBufSize)
 	eof := false
@@ -128,11 +133,11 @@ func (t *Tokenizer) Load(r io.Reader, handler oj.TokenHandler) (err error) {
 		skip = 3
 	}
 	for {
-		if 0 < skip {
-			t.tokenizeBuffer(buf[skip:], eof)
-		} else {
-			t.tokenizeBuffer(buf, eof)
-		}
+		chunk := buf[skip:]
+		t.base += skip
+		t.tokenizeBuffer(chunk, eof)
+		t.noff -= len(chunk)
+		t.base += len(chunk)
 		skip = 0
 		if eof {
 			break
@@ -152,6 +157,7 @@ func (t *Tokenizer) Load(r io.Reader, handler oj.TokenHandler) (err error) {
 }
 
 func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) {
+	t.src = buf
 	var b byte
 	var i int
 	var off int
@@ -202,7 +208,7 @@ func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) {
 				}
 			}
 			if t.exkey {
-				t.newError(off, "expected a key")
+				t.newError(off, ojg.ErrUnexpectedChar, "expected a key")
 			}
 			t.starts = append(t.starts, objectStart)
 			t.handler.ObjectStart()
@@ -212,7 +218,7 @@ func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) {
 		case closeObject:
 			depth--
 			if depth < 0 || t.starts[depth] != objectStart {
-				t.newError(off, "unexpected object close")
+				t.newError(off, ojg.ErrUnexpectedChar, "unexpected object close")
 			}
 			if 256 < len(t.mode) {
 				switch t.mode[256] {
@@ -297,7 +303,7 @@ func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) {
 				}
 			}
 			if t.exkey {
-				t.newError(off, "expected a key")
+				t.newError(off, ojg.ErrUnexpectedChar, "expected a key")
 			}
 			t.starts = append(t.starts, arrayStart)
 			t.handler.ArrayStart()
@@ -307,7 +313,7 @@ func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) {
 		case closeArray:
 			depth--
 			if depth < 0 || t.starts[depth] != arrayStart {
-				t.newError(off, "unexpected array close")
+				t.newError(off, ojg.ErrUnexpectedChar, "unexpected array close")
 			}
 			// Only modes with a close array are value, token, and numbers
 			// which are all over 256 long.
@@ -445,10 +451,10 @@ func (t *Tokenizer) tokenizeBuffer(buf []byte, last bool) {
 	}
 	if last {
 		if 0 < len(t.starts) {
-			t.newError(off, "not closed")
+			t.newError(off, ojg.ErrUnexpectedEOF, "not closed")
 		}
 		if len(t.mode) == 256 { // valid finishing maps are one byte longer
-			t.newError(off, "incomplete JSON")
+			t.newError(off, ojg.ErrUnexpectedEOF, "incomplete JSON")
 		}
 		switch t.mode[256] {
 		case 'n': // number
@@ -492,30 +498,41 @@ func (t *Tokenizer) addString(s string) {
 	}
 }
 
-func (t *Tokenizer) newError(off int, format string, args ...any) {
-	panic(&oj.ParseError{
+func (t *Tokenizer) newError(off int, kind error, format string, args ...any) {
+	err := &oj.ParseError{
 		Message: fmt.Sprintf(format, args...),
 		Line:    t.line,
 		Column:  off - t.noff,
-	})
+		Offset:  t.base + off,
+		Kind:    kind,
+	}
+	err.SetSnippet(t.src, off)
+	panic(err)
 }
 
 func (t *Tokenizer) byteError(off int, mode string, b byte) {
 	err := &oj.ParseError{
 		Line:   t.line,
 		Column: off - t.noff,
+		Offset: t.base + off,
+		Kind:   ojg.ErrUnexpectedChar,
 	}
+	err.SetSnippet(t.src, off)
 	switch mode {
 	case colonMap:
 		err.Message = fmt.Sprintf("expected a colon, not '%c'", b)
 	case negMap, zeroMap, digitMap, dotMap, fracMap, expSignMap, expZeroMap, expMap:
 		err.Message = "invalid number"
+		err.Kind = ojg.ErrInvalidNumber
 	case stringMap:
 		err.Message = fmt.Sprintf("invalid JSON character 0x%02x", b)
+		err.Kind = ojg.ErrInvalidString
 	case escMap:
 		err.Message = fmt.Sprintf("invalid JSON escape character '\\%c'", b)
+		err.Kind = ojg.ErrInvalidString
 	case uMap:
 		err.Message = fmt.Sprintf("invalid JSON unicode character '%c'", b)
+		err.Kind = ojg.ErrInvalidString
 	case spaceMap:
 		err.Message = fmt.Sprintf("extra characters after close, '%c'", b)
 	default:
@@ -526,7 +543,7 @@ func (t *Tokenizer) byteError(off int, mode string, b byte) {
 
 func (t *Tokenizer) handleNum(off int) {
 	if t.exkey {
-		t.newError(off, "expected a key")
+		t.newError(off, ojg.ErrUnexpectedChar, "expected a key")
 	}
 	t.mode = valueMap
 	t.exkey = 0 < len(t.starts) && t.starts[len(t.starts)-1] == objectStart
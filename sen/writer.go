@@ -8,10 +8,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"reflect"
+	"slices"
 	"sort"
 	"strconv"
-	"strings"
 	"time"
 	"unsafe"
 
@@ -37,6 +38,9 @@ type Writer struct {
 	appendString  func(buf []byte, s string, htmlSafe bool) []byte
 	findex        byte
 	needSep       bool
+	slowField     bool                // string and float fields written with appendSEN
+	skipFields    bool                // Groups or OmitZero set so every field is checked
+	keyFields     map[*finfo][]*finfo // fields with keys from the KeyFunc
 }
 
 // SEN writes data, SEN encoded. On error, an empty string is returned.
@@ -60,32 +64,15 @@ func (wr *Writer) MustSEN(data any) []byte {
 		wr.InitSize = 256
 	}
 	if cap(wr.buf) < wr.InitSize {
-		wr.buf = make([]byte, 0, wr.InitSize)
+		ojg.Free(wr.Allocator, wr.buf)
+		wr.buf = ojg.Alloc(wr.Allocator, wr.InitSize)
 	} else {
 		wr.buf = wr.buf[:0]
 	}
-	wr.calcFieldsIndex()
+	wr.prepare()
 	if wr.Color {
 		wr.colorSEN(data, 0)
 	} else {
-		wr.appendString = ojg.AppendSENString
-		if wr.Tab || 0 < wr.Indent {
-			wr.appendArray = appendArray
-			if wr.Sort {
-				wr.appendObject = appendSortObject
-			} else {
-				wr.appendObject = appendObject
-			}
-			wr.appendDefault = appendDefault
-		} else {
-			wr.appendArray = tightArray
-			if wr.Sort {
-				wr.appendObject = tightSortObject
-			} else {
-				wr.appendObject = tightObject
-			}
-			wr.appendDefault = tightDefault
-		}
 		wr.appendSEN(data, 0)
 	}
 	return wr.buf
@@ -114,38 +101,103 @@ func (wr *Writer) MustWrite(w io.Writer, data any) {
 		wr.WriteLimit = 1024
 	}
 	if cap(wr.buf) < wr.InitSize {
-		wr.buf = make([]byte, 0, wr.InitSize)
+		ojg.Free(wr.Allocator, wr.buf)
+		wr.buf = ojg.Alloc(wr.Allocator, wr.InitSize)
 	} else {
 		wr.buf = wr.buf[:0]
 	}
-	wr.calcFieldsIndex()
+	wr.prepare()
 	if wr.Color {
 		wr.colorSEN(data, 0)
 	} else {
-		wr.appendString = ojg.AppendSENString
-		if wr.Tab || 0 < wr.Indent {
-			wr.appendArray = appendArray
-			if wr.Sort {
-				wr.appendObject = appendSortObject
-			} else {
-				wr.appendObject = appendObject
-			}
-			wr.appendDefault = appendDefault
-		} else {
-			wr.appendArray = tightArray
-			if wr.Sort {
-				wr.appendObject = tightSortObject
-			} else {
-				wr.appendObject = tightObject
-			}
-			wr.appendDefault = tightDefault
-		}
 		wr.appendSEN(data, 0)
 	}
 	if 0 < len(wr.buf) {
 		if _, err := wr.w.Write(wr.buf); err != nil {
 			panic(err)
 		}
+		wr.buf = wr.buf[:0]
+	}
+}
+
+// Flush writes any buffered output to the io.Writer of the most recent
+// Write call and then calls the Flush() or Flush() error method of that
+// io.Writer if it has one, such as a *bufio.Writer or an http.Flusher.
+// While writing, output is passed to the io.Writer each time the buffer
+// grows past the WriteLimit option so a larger WriteLimit means fewer writes
+// and a smaller one means output is seen sooner.
+func (wr *Writer) Flush() (err error) {
+	if wr.w == nil {
+		return nil
+	}
+	if 0 < len(wr.buf) {
+		_, err = wr.w.Write(wr.buf)
+		wr.buf = wr.buf[:0]
+		if err != nil {
+			return
+		}
+	}
+	switch tw := wr.w.(type) {
+	case interface{ Flush() error }:
+		err = tw.Flush()
+	case interface{ Flush() }:
+		tw.Flush()
+	}
+	return
+}
+
+// WriteTo writes the buffered output, such as the SEN returned by
+// MustSEN(), to w without copying it and then empties the buffer. On error
+// the output that was not written remains in the buffer. It implements the
+// io.WriterTo interface.
+func (wr *Writer) WriteTo(w io.Writer) (n int64, err error) {
+	if 0 < len(wr.buf) {
+		var cnt int
+		cnt, err = w.Write(wr.buf)
+		n = int64(cnt)
+		if err == nil {
+			wr.buf = wr.buf[:0]
+		} else {
+			wr.buf = wr.buf[:copy(wr.buf, wr.buf[cnt:])]
+		}
+	}
+	return
+}
+
+// prepare sets up the field index and the append functions according to the
+// current options.
+func (wr *Writer) prepare() {
+	wr.calcFieldsIndex()
+	clear(wr.keyFields)
+	wr.ApplyColorScheme()
+	wr.appendString = wr.StringAppender(!wr.QuoteStrings)
+	if wr.SpecialFloats && !wr.QuoteStrings {
+		appendString := wr.appendString
+		wr.appendString = func(buf []byte, s string, htmlSafe bool) []byte {
+			if isSpecialFloat(s) {
+				return ojg.AppendJSONString(buf, s, htmlSafe)
+			}
+			return appendString(buf, s, htmlSafe)
+		}
+	}
+	wr.slowField = wr.CustomString() || wr.CustomFloat() || wr.SpecialFloats || wr.QuoteStrings
+	wr.skipFields = 0 < len(wr.Groups) || wr.OmitZero
+	if wr.Tab || 0 < wr.Indent {
+		wr.appendArray = appendArray
+		if wr.Sort {
+			wr.appendObject = appendSortObject
+		} else {
+			wr.appendObject = appendObject
+		}
+		wr.appendDefault = appendDefault
+	} else {
+		wr.appendArray = tightArray
+		if wr.Sort {
+			wr.appendObject = tightSortObject
+		} else {
+			wr.appendObject = tightObject
+		}
+		wr.appendDefault = tightDefault
 	}
 }
 
@@ -164,6 +216,99 @@ func (wr *Writer) calcFieldsIndex() {
 	}
 }
 
+// structFields returns the fields of a struct in the order they are
+// written. With a KeyFunc the keys of fields not named by a json tag are
+// converted and the fields sorted again. The FieldOrder and FieldPriority
+// options also reorder the fields. With QuoteStrings the keys are
+// quoted. Keys are escaped the same as string values with the EscapeRunes,
+// RawUnicode, and ASCIIOnly options. The converted fields are cached until the options are
+// next applied.
+func (wr *Writer) structFields(si *sinfo) []*finfo {
+	fields := si.fields[wr.findex]
+	if (wr.KeyFunc == nil && !wr.QuoteStrings && !wr.escapeKeys() && !wr.reorderFields()) || len(fields) == 0 {
+		return fields
+	}
+	if kf, has := wr.keyFields[fields[0]]; has {
+		return kf
+	}
+	kf := make([]*finfo, len(fields))
+	for i, fi := range fields {
+		if fi.tagged && !wr.QuoteStrings && !wr.escapeKeys() {
+			kf[i] = fi
+			continue
+		}
+		cf := *fi
+		if wr.KeyFunc != nil && !fi.tagged {
+			cf.key = wr.KeyFunc(fi.name)
+		}
+		cf.jkey = wr.appendString(nil, cf.key, false)
+		cf.jkey = append(cf.jkey, ':')
+		if fi.jkey[len(fi.jkey)-1] == ' ' { // pretty
+			cf.jkey = append(cf.jkey, ' ')
+		}
+		kf[i] = &cf
+	}
+	wr.sortFields(kf)
+	if wr.keyFields == nil {
+		wr.keyFields = map[*finfo][]*finfo{}
+	}
+	wr.keyFields[fields[0]] = kf
+	return kf
+}
+
+// escapeKeys returns true if the EscapeRunes, RawUnicode, or ASCIIOnly
+// options change how struct field keys are written.
+func (wr *Writer) escapeKeys() bool {
+	return 0 < len(wr.EscapeRunes) || wr.RawUnicode || wr.ASCIIOnly
+}
+
+// reorderFields returns true if the FieldOrder or FieldPriority options
+// change the order of struct fields from the order they are cached in.
+func (wr *Writer) reorderFields() bool {
+	return wr.FieldOrder != ojg.FieldOrderKey || 0 < len(wr.FieldPriority)
+}
+
+// sortFields sorts fields by key or by declaration order with the fields
+// named in FieldPriority first.
+func (wr *Writer) sortFields(fields []*finfo) {
+	priority := func(key string) int {
+		for i, k := range wr.FieldPriority {
+			if k == key {
+				return i
+			}
+		}
+		return len(wr.FieldPriority)
+	}
+	sort.SliceStable(fields, func(i, j int) bool {
+		if pi, pj := priority(fields[i].key), priority(fields[j].key); pi != pj {
+			return pi < pj
+		}
+		if wr.FieldOrder == ojg.FieldOrderDeclared {
+			return slices.Compare(fields[i].index, fields[j].index) < 0
+		}
+		return fields[i].key < fields[j].key
+	})
+}
+
+// appendFloat appends a float using the inf, -inf, and nan tokens for
+// special values if the SpecialFloats option is set.
+func (wr *Writer) appendFloat(f float64, bitSize int) {
+	if wr.SpecialFloats {
+		switch {
+		case math.IsNaN(f):
+			wr.buf = append(wr.buf, "nan"...)
+			return
+		case math.IsInf(f, 1):
+			wr.buf = append(wr.buf, "inf"...)
+			return
+		case math.IsInf(f, -1):
+			wr.buf = append(wr.buf, "-inf"...)
+			return
+		}
+	}
+	wr.buf = wr.AppendFloat(wr.buf, f, bitSize)
+}
+
 func (wr *Writer) appendSEN(data any, depth int) {
 	wr.needSep = true
 	switch td := data.(type) {
@@ -199,17 +344,9 @@ func (wr *Writer) appendSEN(data any, depth int) {
 		wr.buf = strconv.AppendUint(wr.buf, td, 10)
 
 	case float32:
-		if 0 < len(wr.FloatFormat) {
-			wr.buf = fmt.Appendf(wr.buf, wr.FloatFormat, float64(td))
-		} else {
-			wr.buf = strconv.AppendFloat(wr.buf, float64(td), 'g', -1, 32)
-		}
+		wr.appendFloat(float64(td), 32)
 	case float64:
-		if 0 < len(wr.FloatFormat) {
-			wr.buf = fmt.Appendf(wr.buf, wr.FloatFormat, td)
-		} else {
-			wr.buf = strconv.AppendFloat(wr.buf, td, 'g', -1, 64)
-		}
+		wr.appendFloat(td, 64)
 
 	case string:
 		wr.buf = wr.appendString(wr.buf, td, !wr.HTMLUnsafe)
@@ -232,10 +369,18 @@ func (wr *Writer) appendSEN(data any, depth int) {
 		wr.buf = wr.AppendTime(wr.buf, td, true)
 
 	case []any:
+		if td == nil && wr.NilCollections == ojg.NilAsNull {
+			wr.buf = append(wr.buf, "null"...)
+			break
+		}
 		wr.appendArray(wr, td, depth)
 		wr.needSep = false
 
 	case map[string]any:
+		if td == nil && wr.NilCollections == ojg.NilAsNull {
+			wr.buf = append(wr.buf, "null"...)
+			break
+		}
 		wr.appendObject(wr, td, depth)
 		wr.needSep = false
 
@@ -289,6 +434,12 @@ func appendDefault(wr *Writer, data any, depth int) {
 			wr.appendSlice(rv, depth, nil)
 		case reflect.Map:
 			wr.appendMap(rv, depth, nil)
+		case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+			if v, ok := wr.Fallback(data); ok {
+				wr.appendSEN(v, depth)
+				return
+			}
+			wr.appendSEN(nil, depth)
 		default:
 			// Not much should get here except Complex and non-decomposable
 			// values.
@@ -296,6 +447,8 @@ func appendDefault(wr *Writer, data any, depth int) {
 			wr.appendSEN(dec, depth)
 			return
 		}
+	} else if v, ok := wr.Fallback(data); ok {
+		wr.appendSEN(v, depth)
 	} else {
 		wr.buf = wr.appendString(wr.buf, fmt.Sprintf("%v", data), !wr.HTMLUnsafe)
 	}
@@ -464,7 +617,7 @@ func (wr *Writer) appendStruct(rv reflect.Value, depth int, si *sinfo) {
 		si = getSinfo(rv.Interface(), wr.OmitEmpty)
 	}
 	d2 := depth + 1
-	fields := si.fields[wr.findex]
+	fields := wr.structFields(si)
 	wr.buf = append(wr.buf, '{')
 	empty := true
 	var v any
@@ -518,9 +671,15 @@ func (wr *Writer) appendStruct(rv reflect.Value, depth int, si *sinfo) {
 			wr.buf = append(wr.buf, cs...)
 			indented = true
 		}
-		if 0 < addr {
+		if (fi.skippable || wr.skipFields) && wr.skipField(fi, rv) {
+			continue
+		}
+		switch {
+		case wr.slowField && fi.sAppend != nil:
+			wr.buf, v, stat = fi.sAppend(fi, wr.buf, rv, addr, !wr.HTMLUnsafe)
+		case 0 < addr:
 			wr.buf, v, stat = fi.Append(fi, wr.buf, rv, addr, !wr.HTMLUnsafe)
-		} else {
+		default:
 			wr.buf, v, stat = fi.iAppend(fi, wr.buf, rv, addr, !wr.HTMLUnsafe)
 		}
 		switch stat {
@@ -595,6 +754,10 @@ func (wr *Writer) appendStruct(rv reflect.Value, depth int, si *sinfo) {
 }
 
 func (wr *Writer) appendSlice(rv reflect.Value, depth int, si *sinfo) {
+	if rv.Kind() == reflect.Slice && rv.IsNil() && wr.NilCollections == ojg.NilAsNull {
+		wr.buf = append(wr.buf, "null"...)
+		return
+	}
 	end := rv.Len()
 	if end == 0 {
 		wr.buf = append(wr.buf, "[]"...)
@@ -646,6 +809,10 @@ func (wr *Writer) appendSlice(rv reflect.Value, depth int, si *sinfo) {
 }
 
 func (wr *Writer) appendMap(rv reflect.Value, depth int, si *sinfo) {
+	if rv.IsNil() && wr.NilCollections == ojg.NilAsNull {
+		wr.buf = append(wr.buf, "null"...)
+		return
+	}
 	d2 := depth + 1
 	var is string
 	var cs string
@@ -672,13 +839,16 @@ func (wr *Writer) appendMap(rv reflect.Value, depth int, si *sinfo) {
 		}
 		cs = spaces[0:x]
 	}
-	keys := rv.MapKeys()
-	if wr.Sort {
-		sort.Slice(keys, func(i, j int) bool { return 0 > strings.Compare(keys[i].String(), keys[j].String()) })
-	}
+	keys, names := alt.MapKeys(rv, wr.Sort)
 	empty := true
 	wr.buf = append(wr.buf, '{')
-	for _, kv := range keys {
+	for i, kv := range keys {
+		var key string
+		if names == nil {
+			key = kv.String()
+		} else {
+			key = names[i]
+		}
 		rm := rv.MapIndex(kv)
 		if rm.Kind() == reflect.Ptr {
 			if rm.IsNil() {
@@ -692,7 +862,7 @@ func (wr *Writer) appendMap(rv reflect.Value, depth int, si *sinfo) {
 		switch rm.Kind() {
 		case reflect.Struct:
 			wr.buf = append(wr.buf, cs...)
-			wr.buf = wr.appendString(wr.buf, kv.String(), !wr.HTMLUnsafe)
+			wr.buf = wr.appendString(wr.buf, key, !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, ": "...)
 			wr.appendStruct(rm, d2, si)
 		case reflect.Slice, reflect.Array:
@@ -700,7 +870,7 @@ func (wr *Writer) appendMap(rv reflect.Value, depth int, si *sinfo) {
 				continue
 			}
 			wr.buf = append(wr.buf, cs...)
-			wr.buf = wr.appendString(wr.buf, kv.String(), !wr.HTMLUnsafe)
+			wr.buf = wr.appendString(wr.buf, key, !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, ": "...)
 			wr.appendSlice(rm, d2, si)
 		case reflect.Map:
@@ -708,7 +878,7 @@ func (wr *Writer) appendMap(rv reflect.Value, depth int, si *sinfo) {
 				continue
 			}
 			wr.buf = append(wr.buf, cs...)
-			wr.buf = wr.appendString(wr.buf, kv.String(), !wr.HTMLUnsafe)
+			wr.buf = wr.appendString(wr.buf, key, !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, ": "...)
 			wr.appendMap(rm, d2, si)
 		case reflect.String:
@@ -716,12 +886,12 @@ func (wr *Writer) appendMap(rv reflect.Value, depth int, si *sinfo) {
 				continue
 			}
 			wr.buf = append(wr.buf, cs...)
-			wr.buf = wr.appendString(wr.buf, kv.String(), !wr.HTMLUnsafe)
+			wr.buf = wr.appendString(wr.buf, key, !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, ": "...)
 			wr.appendSEN(rm.Interface(), d2)
 		default:
 			wr.buf = append(wr.buf, cs...)
-			wr.buf = wr.appendString(wr.buf, kv.String(), !wr.HTMLUnsafe)
+			wr.buf = wr.appendString(wr.buf, key, !wr.HTMLUnsafe)
 			wr.buf = append(wr.buf, ": "...)
 			wr.appendSEN(rm.Interface(), d2)
 		}
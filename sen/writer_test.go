@@ -4,6 +4,7 @@ package sen_test
 
 import (
 	"fmt"
+	"math"
 	"strings"
 	"testing"
 	"time"
@@ -763,3 +764,198 @@ func TestWriteFloatFormat(t *testing.T) {
 	j = wr.MustSEN(float32(1.234))
 	tt.Equal(t, `01.23`, string(j))
 }
+
+func TestWriteFloatOptions(t *testing.T) {
+	type Reading struct {
+		Temp  float64
+		Scale float32
+	}
+	opt := sen.Options{FloatDecimal: true}
+	tt.Equal(t, `{scale:0.5 temp:21.0}`, sen.String(&Reading{Temp: 21, Scale: 0.5}, &sen.Options{FloatDecimal: true, Sort: true}))
+	tt.Equal(t, `[1.0 2]`, sen.String([]any{1.0, 2}, &opt))
+
+	opt = sen.Options{FloatVerb: 'f', FloatPrecision: 3}
+	tt.Equal(t, `{scale:0.500 temp:21.000}`, sen.String(Reading{Temp: 21, Scale: 0.5}, &opt))
+}
+
+func TestWriteAsNumber(t *testing.T) {
+	type Price struct {
+		Amount   string `ojg:"asnumber"`
+		Discount string `json:",omitempty" ojg:"asnumber"`
+	}
+	tt.Equal(t, `{amount:12.50 discount:0.1}`, sen.String(&Price{Amount: "12.50", Discount: "0.1"}))
+	tt.Equal(t, `{Amount:3}`, sen.String(&Price{Amount: "3"}, &sen.Options{UseTags: true}))
+	tt.Panic(t, func() { _ = sen.Bytes(&Price{Amount: "12,50"}) })
+}
+
+func TestWriteEscapeRunes(t *testing.T) {
+	type Word struct {
+		Word string
+	}
+	opt := sen.Options{Sort: true, EscapeRunes: []ojg.RuneRange{{Min: 0x80, Max: 0xff}}}
+	data := map[string]any{"cafe": "caf\u00e9", "plain": "abc"}
+	tt.Equal(t, `{cafe:"caf\u00e9" plain:abc}`, sen.String(data, &opt))
+
+	opt.Indent = 2
+	tt.Equal(t, `{
+  cafe: "caf\u00e9"
+  plain: abc
+}`, sen.String(data, &opt))
+
+	opt.Indent = 0
+	tt.Equal(t, `{word:"caf\u00e9"}`, sen.String(&Word{Word: "caf\u00e9"}, &opt))
+	tt.Equal(t, "{word:caf\u00e9}", sen.String(&Word{Word: "caf\u00e9"}, &sen.Options{}))
+
+	type Tagged struct {
+		Cafe string `json:"caf\u00e9"`
+	}
+	opt = sen.Options{UseTags: true, EscapeRunes: []ojg.RuneRange{{Min: 0x80, Max: 0xff}}}
+	tt.Equal(t, `{"caf\u00e9":x}`, sen.String(&Tagged{Cafe: "x"}, &opt))
+}
+
+func TestWriteStructOmitZero(t *testing.T) {
+	type Sample struct {
+		At    time.Time `json:"at,omitzero"`
+		Count int       `json:"count,omitzero"`
+		Name  string    `json:"name"`
+	}
+	opt := sen.Options{UseTags: true, Sort: true}
+	tt.Equal(t, `{name:""}`, sen.String(&Sample{}, &opt))
+
+	opt = sen.Options{OmitZero: true, Sort: true}
+	tt.Equal(t, `{count:3}`, sen.String(&Sample{Count: 3}, &opt))
+}
+
+func TestWriteASCIIOnly(t *testing.T) {
+	opt := sen.Options{Sort: true, ASCIIOnly: true}
+	tt.Equal(t, `{a:"caf\u00e9" b:plain}`, sen.String(map[string]any{"a": "café", "b": "plain"}, &opt))
+}
+
+type senFlushWriter struct {
+	strings.Builder
+	flushes int
+}
+
+func (w *senFlushWriter) Flush() {
+	w.flushes++
+}
+
+func TestWriterFlushWriteTo(t *testing.T) {
+	var wr sen.Writer
+	tt.Nil(t, wr.Flush())
+
+	var fw senFlushWriter
+	tt.Nil(t, wr.Write(&fw, []any{1, 2}))
+	tt.Nil(t, wr.Flush())
+	tt.Equal(t, 1, fw.flushes)
+	tt.Equal(t, "[1 2]", fw.String())
+
+	_ = wr.MustSEN(map[string]any{"a": 1})
+	var b strings.Builder
+	n, err := wr.WriteTo(&b)
+	tt.Nil(t, err)
+	tt.Equal(t, int64(5), n)
+	tt.Equal(t, "{a:1}", b.String())
+	n, err = wr.WriteTo(&b)
+	tt.Nil(t, err)
+	tt.Equal(t, int64(0), n)
+}
+
+func TestWriteFallbackFunc(t *testing.T) {
+	opt := sen.Options{FallbackFunc: func(v any) (any, error) {
+		if ch, ok := v.(chan int); ok {
+			return map[string]any{"cap": cap(ch)}, nil
+		}
+		return v, nil
+	}}
+	tt.Equal(t, `[{cap:3}null]`, sen.String([]any{make(chan int, 3), make(chan bool)}, &opt))
+
+	type Job struct {
+		Done chan int
+		Name string
+	}
+	tt.Equal(t, `{done:{cap:2} name:x}`, sen.String(&Job{Done: make(chan int, 2), Name: "x"}, &sen.Options{FallbackFunc: opt.FallbackFunc, Sort: true}))
+	tt.Equal(t, `{done:null name:x}`, sen.String(&Job{Done: make(chan int, 2), Name: "x"}, &sen.Options{Sort: true}))
+
+	opt.Indent = 2
+	tt.Equal(t, "[\n  {\n    cap: 1\n  }\n]", sen.String([]any{make(chan int, 1)}, &opt))
+
+	opt.Indent = 0
+	opt.NoReflect = true
+	tt.Equal(t, `[{cap:2}]`, sen.String([]any{make(chan int, 2)}, &opt))
+}
+
+func TestWriteGroups(t *testing.T) {
+	type User struct {
+		Name  string
+		Email string `ojg:"groups=admin,self"`
+	}
+	u := User{Name: "ann", Email: "ann"}
+	tt.Equal(t, `{email:ann name:ann}`, sen.String(&u))
+	tt.Equal(t, `{name:ann}`, sen.String(&u, &sen.Options{Groups: []string{"public"}}))
+	tt.Equal(t, "{\n  email: ann\n  name: ann\n}", sen.String(&u, &sen.Options{Groups: []string{"admin"}, Indent: 2}))
+}
+
+func TestWriteKeyFunc(t *testing.T) {
+	type Sample struct {
+		UserID    int
+		FirstName string `json:"First"`
+	}
+	s := Sample{UserID: 3, FirstName: "ann"}
+	tt.Equal(t, `{first_name:ann user_id:3}`, sen.String(&s, &sen.Options{KeyFunc: ojg.SnakeCase}))
+	tt.Equal(t, `{First:ann user-id:3}`, sen.String(&s, &sen.Options{KeyFunc: ojg.KebabCase, UseTags: true}))
+	tt.Equal(t, "{\n  firstName: ann\n  userId: 3\n}", sen.String(&s, &sen.Options{KeyFunc: ojg.CamelCase, Indent: 2}))
+}
+
+func TestWriteSpecialTokens(t *testing.T) {
+	type Sample struct {
+		Max  float64
+		Name string
+	}
+	data := []any{math.Inf(1), math.Inf(-1), math.NaN(), float32(1.5), "inf", "nan", "abc"}
+	tt.Equal(t, `[+Inf -Inf NaN 1.5 inf nan abc]`, sen.String(data))
+
+	opt := sen.Options{SpecialFloats: true, Sort: true}
+	out := sen.String(data, &opt)
+	tt.Equal(t, `[inf -inf nan 1.5 "inf" "nan" abc]`, out)
+	back, err := (&sen.Parser{SpecialFloats: true}).Parse([]byte(out))
+	tt.Nil(t, err)
+	tt.Equal(t, "inf", back.([]any)[4])
+	tt.Equal(t, `{max:-inf name:"nan"}`, sen.String(&Sample{Max: math.Inf(-1), Name: "nan"}, &opt))
+
+	opt = sen.Options{QuoteStrings: true, Sort: true}
+	tt.Equal(t, `{"a":["x" 1]}`, sen.String(map[string]any{"a": []any{"x", 1}}, &opt))
+	tt.Equal(t, `{"max":1 "name":"x"}`, sen.String(&Sample{Max: 1, Name: "x"}, &opt))
+	opt.Indent = 2
+	tt.Equal(t, "{\n  \"max\": 1\n  \"name\": \"x\"\n}", sen.String(&Sample{Max: 1, Name: "x"}, &opt))
+}
+
+func TestWriteNilCollections(t *testing.T) {
+	type holder struct {
+		List []int
+		Map  map[string]int
+	}
+	data := map[string]any{"a": []any(nil), "h": &holder{}}
+	opt := sen.Options{Sort: true}
+	tt.Equal(t, `{a:[] h:{list:[] map:{}}}`, sen.String(data, &opt))
+
+	opt.NilCollections = ojg.NilAsNull
+	tt.Equal(t, `{a:null h:{list:null map:null}}`, sen.String(data, &opt))
+	opt.Indent = 2
+	tt.Equal(t, "{\n  a: null\n  h: {\n    list: null\n    map: null\n  }\n}", sen.String(data, &opt))
+}
+
+func TestWriteFieldOrder(t *testing.T) {
+	type Sample struct {
+		Zeta  int
+		Name  string
+		Alpha bool
+	}
+	s := Sample{Zeta: 1, Name: "x", Alpha: true}
+	tt.Equal(t, `{alpha:true name:x zeta:1}`, sen.String(&s, &sen.Options{}))
+	tt.Equal(t, `{zeta:1 name:x alpha:true}`, sen.String(&s, &sen.Options{FieldOrder: ojg.FieldOrderDeclared}))
+	opt := sen.Options{FieldPriority: []string{"name"}}
+	tt.Equal(t, `{name:x alpha:true zeta:1}`, sen.String(&s, &opt))
+	opt.Indent = 2
+	tt.Equal(t, "{\n  name: x\n  alpha: true\n  zeta: 1\n}", sen.String(&s, &opt))
+}
@@ -3,6 +3,7 @@
 package ojg
 
 import (
+	"unicode/utf16"
 	"unicode/utf8"
 )
 
@@ -41,14 +42,12 @@ var (
 func AppendJSONString(buf []byte, s string, htmlSafe bool) []byte {
 	buf = append(buf, '"')
 	start := 0
-	skip := 0
-	for i, b := range []byte(s) {
-		if i < skip {
-			continue
-		}
+	for i := 0; i < len(s); i++ {
+		b := s[i]
 		c := jMap[b]
 		switch c {
 		case 'o':
+			i += plainJSONLen(s[i+1:], htmlSafe)
 			continue
 		case '.':
 			if start < i {
@@ -77,23 +76,23 @@ func AppendJSONString(buf []byte, s string, htmlSafe bool) []byte {
 				}
 				buf = append(buf, `\u2028`...)
 				start = i + cnt
-				skip = start
+				i = start - 1
 			case '\u2029':
 				if start < i {
 					buf = append(buf, s[start:i]...)
 				}
 				buf = append(buf, `\u2029`...)
 				start = i + cnt
-				skip = start
+				i = start - 1
 			case utf8.RuneError:
 				if start < i {
 					buf = append(buf, s[start:i]...)
 				}
 				buf = append(buf, `\ufffd`...)
 				start = i + cnt
-				skip = start
+				i = start - 1
 			default:
-				skip = i + cnt
+				i += cnt - 1
 			}
 		default:
 			if start < i {
@@ -202,3 +201,140 @@ func AppendSENString(buf []byte, s string, htmlSafe bool) []byte {
 
 	return buf[:len(buf)-1]
 }
+
+// RuneRange is an inclusive range of runes.
+type RuneRange struct {
+	Min rune
+	Max rune
+}
+
+// AppendEscapedJSONString appends a JSON encoding of a string to the
+// provided byte slice. Runes that fall in any of the escapes ranges are
+// written as \uXXXX escape sequences with runes above U+FFFF written as
+// surrogate pairs. If raw is true then U+2028 and U+2029 are written as
+// UTF-8 unless included in the escapes ranges.
+func AppendEscapedJSONString(buf []byte, s string, htmlSafe, raw bool, escapes []RuneRange) []byte {
+	buf = append(buf, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		b := s[i]
+		if b < utf8.RuneSelf && !inRuneRanges(rune(b), escapes) {
+			switch c := jMap[b]; c {
+			case 'o':
+			case '.':
+				buf = append(buf, s[start:i]...)
+				buf = appendRuneEscape(buf, rune(b))
+				start = i + 1
+			case 'h':
+				if htmlSafe {
+					buf = append(buf, s[start:i]...)
+					buf = appendRuneEscape(buf, rune(b))
+					start = i + 1
+				}
+			default:
+				buf = append(buf, s[start:i]...)
+				buf = append(buf, '\\', c)
+				start = i + 1
+			}
+			i++
+			continue
+		}
+		r, cnt := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && cnt == 1:
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, `\ufffd`...)
+			start = i + cnt
+		case inRuneRanges(r, escapes) || (!raw && (r == '\u2028' || r == '\u2029')):
+			buf = append(buf, s[start:i]...)
+			buf = appendRuneEscape(buf, r)
+			start = i + cnt
+		}
+		i += cnt
+	}
+	buf = append(buf, s[start:]...)
+
+	return append(buf, '"')
+}
+
+// AppendEscapedSENString appends a SEN encoding of a string to the provided
+// byte slice with the same rune escaping as AppendEscapedJSONString. Strings
+// that include escaped runes are always quoted.
+func AppendEscapedSENString(buf []byte, s string, htmlSafe, raw bool, escapes []RuneRange) []byte {
+	for _, r := range s {
+		if inRuneRanges(r, escapes) || (raw && (r == '\u2028' || r == '\u2029')) {
+			return AppendEscapedJSONString(buf, s, htmlSafe, raw, escapes)
+		}
+	}
+	return AppendSENString(buf, s, htmlSafe)
+}
+
+func inRuneRanges(r rune, ranges []RuneRange) bool {
+	for _, rr := range ranges {
+		if rr.Min <= r && r <= rr.Max {
+			return true
+		}
+	}
+	return false
+}
+
+func appendRuneEscape(buf []byte, r rune) []byte {
+	if 0xffff < r {
+		r1, r2 := utf16.EncodeRune(r)
+		buf = appendRuneEscape(buf, r1)
+		return appendRuneEscape(buf, r2)
+	}
+	return append(buf, '\\', 'u', hex[(r>>12)&0x0f], hex[(r>>8)&0x0f], hex[(r>>4)&0x0f], hex[r&0x0f])
+}
+
+// passInvalidUTF8 returns a string appender that writes invalid UTF-8 bytes
+// as is. Strings with invalid bytes are always quoted.
+func passInvalidUTF8(appender func(buf []byte, s string, htmlSafe bool) []byte) func(buf []byte, s string, htmlSafe bool) []byte {
+	return func(buf []byte, s string, htmlSafe bool) []byte {
+		if utf8.ValidString(s) {
+			return appender(buf, s, htmlSafe)
+		}
+		buf = append(buf, '"')
+		start := 0
+		for i := 0; i < len(s); {
+			r, cnt := utf8.DecodeRuneInString(s[i:])
+			if r != utf8.RuneError || cnt != 1 {
+				i += cnt
+				continue
+			}
+			buf = appendUnquoted(buf, s[start:i], htmlSafe, appender)
+			buf = append(buf, s[i])
+			i++
+			start = i
+		}
+		buf = appendUnquoted(buf, s[start:], htmlSafe, appender)
+
+		return append(buf, '"')
+	}
+}
+
+// appendUnquoted appends a valid UTF-8 string without the quotes the
+// appender may have added.
+func appendUnquoted(buf []byte, s string, htmlSafe bool, appender func(buf []byte, s string, htmlSafe bool) []byte) []byte {
+	if len(s) == 0 {
+		return buf
+	}
+	start := len(buf)
+	buf = appender(buf, s, htmlSafe)
+	if buf[start] == '"' {
+		copy(buf[start:], buf[start+1:len(buf)-1])
+		buf = buf[:len(buf)-2]
+	}
+	return buf
+}
+
+// rejectInvalidUTF8 returns a string appender that panics with an
+// ErrInvalidString error if the string is not valid UTF-8.
+func rejectInvalidUTF8(appender func(buf []byte, s string, htmlSafe bool) []byte) func(buf []byte, s string, htmlSafe bool) []byte {
+	return func(buf []byte, s string, htmlSafe bool) []byte {
+		if !utf8.ValidString(s) {
+			panic(Errorf(ErrInvalidString, "invalid UTF-8 in string %q", s))
+		}
+		return appender(buf, s, htmlSafe)
+	}
+}
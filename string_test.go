@@ -3,6 +3,7 @@
 package ojg_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/ohler55/ojg"
@@ -31,6 +32,20 @@ func TestStringJSON(t *testing.T) {
 	}
 }
 
+func TestStringJSONLong(t *testing.T) {
+	// Place each byte that needs attention at every position of strings long
+	// enough to be checked in 8 byte words.
+	for _, special := range []string{"\"", "\\", "\n", "\x01", "\x7f", "<", ">", "&", "é", "\u2028", "\xff"} {
+		for i := 0; i < 24; i++ {
+			src := strings.Repeat("a", i) + special + strings.Repeat("b", 24-i)
+			for _, htmlSafe := range []bool{false, true} {
+				expect := ojg.AppendEscapedJSONString(nil, src, htmlSafe, false, nil)
+				tt.Equal(t, string(expect), string(ojg.AppendJSONString(nil, src, htmlSafe)), src)
+			}
+		}
+	}
+}
+
 func TestStringSEN(t *testing.T) {
 	type Data struct {
 		src      string
@@ -54,3 +69,37 @@ func TestStringSEN(t *testing.T) {
 		tt.Equal(t, td.expect, string(buf), i, ": ", td.src)
 	}
 }
+
+func TestStringEscapedJSON(t *testing.T) {
+	type Data struct {
+		src     string
+		expect  string
+		raw     bool
+		escapes []ojg.RuneRange
+	}
+	latin := []ojg.RuneRange{{Min: 0x80, Max: 0xff}}
+	high := []ojg.RuneRange{{Min: 0x10000, Max: 0x10ffff}}
+	for i, td := range []*Data{
+		{src: "abc", expect: `"abc"`, escapes: latin},
+		{src: "a\tb\"c<", expect: `"a\tb\"c\u003c"`, escapes: latin},
+		{src: "caf\u00e9", expect: `"caf\u00e9"`, escapes: latin},
+		{src: "caf\u00e9 \u2615", expect: "\"caf\\u00e9 \u2615\"", escapes: latin},
+		{src: "a \U0001D122 note", expect: `"a \ud834\udd22 note"`, escapes: high},
+		{src: "a\u2028b\u2029c", expect: `"a\u2028b\u2029c"`},
+		{src: "a\u2028b\u2029c", expect: "\"a\u2028b\u2029c\"", raw: true},
+		{src: "a\u2028b\u2029", expect: "\"a\\u2028b\u2029\"", raw: true, escapes: []ojg.RuneRange{{Min: 0x2028, Max: 0x2028}}},
+		{src: "abc\xff", expect: `"abc\ufffd"`, raw: true},
+		{src: "xyz", expect: `"\u0078y\u007a"`, escapes: []ojg.RuneRange{{Min: 'x', Max: 'x'}, {Min: 'z', Max: 'z'}}},
+	} {
+		buf := ojg.AppendEscapedJSONString(nil, td.src, true, td.raw, td.escapes)
+		tt.Equal(t, td.expect, string(buf), i, ": ", td.src)
+	}
+}
+
+func TestStringEscapedSEN(t *testing.T) {
+	latin := []ojg.RuneRange{{Min: 0x80, Max: 0xff}}
+	tt.Equal(t, `abc`, string(ojg.AppendEscapedSENString(nil, "abc", false, false, latin)))
+	tt.Equal(t, `"caf\u00e9"`, string(ojg.AppendEscapedSENString(nil, "caf\u00e9", false, false, latin)))
+	tt.Equal(t, `"a\u2028b"`, string(ojg.AppendEscapedSENString(nil, "a\u2028b", false, false, nil)))
+	tt.Equal(t, "\"a\u2028b\"", string(ojg.AppendEscapedSENString(nil, "a\u2028b", false, true, nil)))
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+//go:build amd64 || arm64
+
+package ojg
+
+const (
+	lsb = 0x0101010101010101
+	msb = 0x8080808080808080
+)
+
+// plainJSONLen returns the length, a multiple of 8, of the leading bytes of
+// s that AppendJSONString copies without change. The bytes are checked 8
+// at a time as a uint64 which is fast on architectures that allow
+// unaligned loads. At least one byte is always left for the caller to
+// check.
+func plainJSONLen(s string, htmlSafe bool) (n int) {
+	for ; n+8 < len(s); n += 8 {
+		v := load64(s[n : n+8])
+		// Control characters, DEL, and non-ASCII bytes.
+		x := (v - 0x20*lsb) | (v + 0x01*lsb) | v
+		// Quotes and backslashes.
+		x |= hasZero(v^('"'*lsb)) | hasZero(v^('\\'*lsb))
+		if htmlSafe {
+			x |= hasZero(v^('<'*lsb)) | hasZero(v^('>'*lsb)) | hasZero(v^('&'*lsb))
+		}
+		if x&msb != 0 {
+			break
+		}
+	}
+	return
+}
+
+// hasZero sets the high bit of a byte if the byte is zero. Bytes above a
+// zero byte may also be flagged.
+func hasZero(v uint64) uint64 {
+	return (v - lsb) &^ v
+}
+
+// load64 returns the first 8 bytes of s as a little endian uint64. The
+// compiler combines the byte loads into a single load.
+func load64(s string) uint64 {
+	_ = s[7]
+	return uint64(s[0]) | uint64(s[1])<<8 | uint64(s[2])<<16 | uint64(s[3])<<24 |
+		uint64(s[4])<<32 | uint64(s[5])<<40 | uint64(s[6])<<48 | uint64(s[7])<<56
+}
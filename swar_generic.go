@@ -0,0 +1,11 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+//go:build !amd64 && !arm64
+
+package ojg
+
+// plainJSONLen returns zero on architectures where loading 8 bytes at a time
+// is not faster than checking each byte.
+func plainJSONLen(s string, htmlSafe bool) int {
+	return 0
+}
@@ -21,6 +21,9 @@ func Equal(t *testing.T, expect, actual any, args ...any) (eq bool) {
 	if !eq {
 		var b strings.Builder
 		b.WriteString(fmt.Sprintf("\nexpect: (%T) %v\nactual: (%T) %v\n", expect, expect, actual, actual))
+		if JSONFailures {
+			writeJSONFailure(&b, expect, actual)
+		}
 		finishFail(t, &b, args)
 	}
 	return
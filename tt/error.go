@@ -0,0 +1,151 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package tt
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Color if true highlights the error positions in the source context shown
+// when ErrorAt fails. It is off by default so failure output stays plain
+// text in logs and CI.
+var Color = false
+
+const (
+	red    = "\x1b[31m"
+	green  = "\x1b[32m"
+	normal = "\x1b[m"
+)
+
+// ErrorIs verifies that errors.Is(err, target) is true.
+func ErrorIs(t *testing.T, err, target error, args ...any) {
+	if !errors.Is(err, target) {
+		var b strings.Builder
+		_, _ = fmt.Fprintf(&b, "\nexpect: error matching (%T) %v\nactual: %s\n", target, target, describeError(err))
+		finishFail(t, &b, args)
+	}
+}
+
+// ErrorAs verifies that errors.As(err, target) is true. The target must be
+// a non-nil pointer to a type that implements error or to an interface
+// type. On success the target is set to the matching error.
+func ErrorAs(t *testing.T, err error, target any, args ...any) {
+	if err == nil || !errors.As(err, target) {
+		var b strings.Builder
+		_, _ = fmt.Fprintf(&b, "\nexpect: error assignable to %s\nactual: %s\n",
+			reflect.TypeOf(target).Elem(), describeError(err))
+		finishFail(t, &b, args)
+	}
+}
+
+// ErrorAt verifies that err or an error it wraps has a position with the
+// line and column provided. Errors with integer Line and Column fields such
+// as oj.ParseError and gen.ParseError have a position. If src is not empty
+// it should be the source that was parsed and is used to display the
+// context of the expected and actual positions on failure.
+func ErrorAt(t *testing.T, err error, src string, line, column int, args ...any) {
+	al, ac, ok := errorPosition(err)
+	if ok && al == line && ac == column {
+		return
+	}
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "\nexpect: error at %d:%d\nactual: %s\n", line, column, describeError(err))
+	if 0 < len(src) {
+		b.WriteString(positionContext(src, line, column, green))
+		if ok {
+			b.WriteString(positionContext(src, al, ac, red))
+		}
+	}
+	finishFail(t, &b, args)
+}
+
+// NotPanics verifies that a function does not panic.
+func NotPanics(t *testing.T, fun func(), args ...any) {
+	defer func() {
+		if r := recover(); r != nil {
+			var b strings.Builder
+			b.WriteString("\nexpect: no panic\nactual: panic ")
+			if err, ok := r.(error); ok {
+				b.WriteString(describeError(err))
+			} else {
+				_, _ = fmt.Fprintf(&b, "(%T) %v", r, r)
+			}
+			b.WriteByte('\n')
+			finishFail(t, &b, args)
+		}
+	}()
+	fun()
+}
+
+func describeError(err error) string {
+	if err == nil {
+		return "nil"
+	}
+	s := fmt.Sprintf("(%T) %s", err, err)
+	if line, column, ok := errorPosition(err); ok && !strings.HasSuffix(s, fmt.Sprintf(" at %d:%d", line, column)) {
+		s = fmt.Sprintf("%s at %d:%d", s, line, column)
+	}
+	return s
+}
+
+// errorPosition returns the Line and Column fields of the first error in
+// the chain that has them.
+func errorPosition(err error) (line, column int, ok bool) {
+	for ; err != nil; err = errors.Unwrap(err) {
+		rv := reflect.ValueOf(err)
+		for rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				break
+			}
+			rv = rv.Elem()
+		}
+		if rv.Kind() != reflect.Struct {
+			continue
+		}
+		lv := rv.FieldByName("Line")
+		cv := rv.FieldByName("Column")
+		if lv.Kind() == reflect.Int && cv.Kind() == reflect.Int {
+			return int(lv.Int()), int(cv.Int()), true
+		}
+	}
+	return
+}
+
+// positionContext returns the source line of a position followed by a line
+// with a caret under the column.
+func positionContext(src string, line, column int, color string) string {
+	lines := strings.Split(src, "\n")
+	if line < 1 || len(lines) < line {
+		return ""
+	}
+	s := lines[line-1]
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "%4d | ", line)
+	if Color && 0 < column && column <= len(s) {
+		b.WriteString(s[:column-1])
+		b.WriteString(color)
+		b.WriteByte(s[column-1])
+		b.WriteString(normal)
+		b.WriteString(s[column:])
+	} else {
+		b.WriteString(s)
+	}
+	b.WriteString("\n     | ")
+	if 1 < column {
+		b.WriteString(strings.Repeat(" ", column-1))
+	}
+	if Color {
+		b.WriteString(color)
+		b.WriteByte('^')
+		b.WriteString(normal)
+	} else {
+		b.WriteByte('^')
+	}
+	b.WriteByte('\n')
+
+	return b.String()
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package tt_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/ohler55/ojg/oj"
+	"github.com/ohler55/ojg/tt"
+)
+
+type posError struct {
+	Line   int
+	Column int
+}
+
+func (e *posError) Error() string {
+	return fmt.Sprintf("bad at %d:%d", e.Line, e.Column)
+}
+
+// fails returns true if the check fails the testing.T it is given. The
+// check is run in a separate goroutine as a failure ends the goroutine.
+func fails(check func(t *testing.T)) bool {
+	var ft testing.T
+	done := make(chan bool)
+	go func() {
+		defer close(done)
+		check(&ft)
+	}()
+	<-done
+
+	return ft.Failed()
+}
+
+func TestColorDefault(t *testing.T) {
+	tt.Equal(t, false, tt.Color)
+}
+
+func TestErrorIs(t *testing.T) {
+	wrapped := fmt.Errorf("reading: %w", io.ErrUnexpectedEOF)
+	tt.Equal(t, false, fails(func(t *testing.T) { tt.ErrorIs(t, wrapped, io.ErrUnexpectedEOF) }))
+	tt.Equal(t, true, fails(func(t *testing.T) { tt.ErrorIs(t, wrapped, io.EOF) }))
+	tt.Equal(t, true, fails(func(t *testing.T) { tt.ErrorIs(t, nil, io.EOF) }))
+}
+
+func TestErrorAs(t *testing.T) {
+	wrapped := fmt.Errorf("parsing: %w", &posError{Line: 2, Column: 3})
+	var pe *posError
+	tt.Equal(t, false, fails(func(t *testing.T) { tt.ErrorAs(t, wrapped, &pe) }))
+	tt.Equal(t, 3, pe.Column)
+
+	var pe2 *oj.ParseError
+	tt.Equal(t, true, fails(func(t *testing.T) { tt.ErrorAs(t, wrapped, &pe2) }))
+	tt.Equal(t, true, fails(func(t *testing.T) { tt.ErrorAs(t, nil, &pe) }))
+}
+
+func TestErrorAt(t *testing.T) {
+	src := "[\n  tru]"
+	_, err := oj.ParseString(src)
+	tt.Equal(t, false, fails(func(t *testing.T) { tt.ErrorAt(t, err, src, 2, 6) }))
+	tt.Equal(t, true, fails(func(t *testing.T) { tt.ErrorAt(t, err, src, 2, 5) }))
+	tt.Equal(t, true, fails(func(t *testing.T) { tt.ErrorAt(t, err, "", 1, 6) }))
+
+	wrapped := fmt.Errorf("loading: %w", &posError{Line: 1, Column: 4})
+	tt.Equal(t, false, fails(func(t *testing.T) { tt.ErrorAt(t, wrapped, "", 1, 4) }))
+
+	// Errors without a position always fail.
+	tt.Equal(t, true, fails(func(t *testing.T) { tt.ErrorAt(t, errors.New("no position"), "x", 1, 1) }))
+	tt.Equal(t, true, fails(func(t *testing.T) { tt.ErrorAt(t, nil, "x", 1, 1) }))
+}
+
+func TestNotPanics(t *testing.T) {
+	tt.Equal(t, false, fails(func(t *testing.T) { tt.NotPanics(t, func() {}) }))
+	tt.Equal(t, true, fails(func(t *testing.T) { tt.NotPanics(t, func() { panic("boom") }) }))
+	tt.Equal(t, true, fails(func(t *testing.T) { tt.NotPanics(t, func() { panic(io.EOF) }) }))
+}
+
+func TestPanic(t *testing.T) {
+	tt.Equal(t, false, fails(func(t *testing.T) { tt.Panic(t, func() { panic("boom") }) }))
+	tt.Equal(t, true, fails(func(t *testing.T) { tt.Panic(t, func() {}) }))
+}
@@ -0,0 +1,271 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package tt
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// DocSpec controls the documents generated by GenDoc. Zero values are
+// replaced by defaults.
+type DocSpec struct {
+	// MaxDepth is the maximum nesting depth of arrays and objects. The
+	// default is 3.
+	MaxDepth int
+
+	// MaxWidth is the maximum number of elements in an array or members in
+	// an object. The default is 5.
+	MaxWidth int
+
+	// KeyAlphabet are the runes used for object keys. The default is the
+	// lowercase ASCII letters.
+	KeyAlphabet string
+
+	// MaxKeyLen is the maximum length of an object key. Keys are at least
+	// one rune long. The default is 8.
+	MaxKeyLen int
+
+	// StringAlphabet are the runes used for string values. The default
+	// includes letters, digits, characters that must be escaped in JSON, and
+	// some multibyte runes.
+	StringAlphabet string
+
+	// MaxStringLen is the maximum length of a string value. The default is
+	// 16.
+	MaxStringLen int
+
+	// The weights determine the mix of value types. A value type is picked
+	// with a probability of its weight divided by the sum of all the
+	// weights. If all weights are zero each type has a weight of one. Arrays
+	// and objects are not picked at the maximum depth. Floats have no more
+	// than 10 significant digits and always have a fractional part so they
+	// remain float64 values when written and parsed.
+	NullWeight   int
+	BoolWeight   int
+	IntWeight    int
+	FloatWeight  int
+	StringWeight int
+	ArrayWeight  int
+	ObjectWeight int
+}
+
+const (
+	defaultKeyAlphabet    = "abcdefghijklmnopqrstuvwxyz"
+	defaultStringAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789 \"\\/\t\n<>&éλ😀"
+)
+
+// GenDoc returns a random document made up of nil, bool, int64, float64,
+// string, []any, and map[string]any values. The same rng seed and spec
+// always produce the same document so a failing case can be reproduced. A
+// nil spec uses the defaults.
+func GenDoc(rng *rand.Rand, spec *DocSpec) any {
+	var s DocSpec
+	if spec != nil {
+		s = *spec
+	}
+	if s.MaxDepth <= 0 {
+		s.MaxDepth = 3
+	}
+	if s.MaxWidth <= 0 {
+		s.MaxWidth = 5
+	}
+	if len(s.KeyAlphabet) == 0 {
+		s.KeyAlphabet = defaultKeyAlphabet
+	}
+	if s.MaxKeyLen <= 0 {
+		s.MaxKeyLen = 8
+	}
+	if len(s.StringAlphabet) == 0 {
+		s.StringAlphabet = defaultStringAlphabet
+	}
+	if s.MaxStringLen <= 0 {
+		s.MaxStringLen = 16
+	}
+	if s.NullWeight+s.BoolWeight+s.IntWeight+s.FloatWeight+s.StringWeight+s.ArrayWeight+s.ObjectWeight <= 0 {
+		s.NullWeight = 1
+		s.BoolWeight = 1
+		s.IntWeight = 1
+		s.FloatWeight = 1
+		s.StringWeight = 1
+		s.ArrayWeight = 1
+		s.ObjectWeight = 1
+	}
+	g := docGen{
+		spec:    &s,
+		rng:     rng,
+		keyRs:   []rune(s.KeyAlphabet),
+		strRs:   []rune(s.StringAlphabet),
+		weights: []int{s.NullWeight, s.BoolWeight, s.IntWeight, s.FloatWeight, s.StringWeight, s.ArrayWeight, s.ObjectWeight},
+	}
+	return g.value(0)
+}
+
+type docGen struct {
+	spec    *DocSpec
+	rng     *rand.Rand
+	keyRs   []rune
+	strRs   []rune
+	weights []int
+}
+
+func (g *docGen) value(depth int) any {
+	weights := g.weights
+	if g.spec.MaxDepth <= depth {
+		weights = weights[:5]
+	}
+	sum := 0
+	for _, w := range weights {
+		sum += w
+	}
+	if sum <= 0 {
+		return nil
+	}
+	pick := g.rng.Intn(sum)
+	kind := 0
+	for i, w := range weights {
+		if pick < w {
+			kind = i
+			break
+		}
+		pick -= w
+	}
+	switch kind {
+	case 1:
+		return g.rng.Intn(2) == 1
+	case 2:
+		return g.rng.Int63n(2000001) - 1000000
+	case 3:
+		f := float64(g.rng.Int63n(2000000001)-1000000000) / math.Pow10(g.rng.Intn(9)+1)
+		if f == math.Trunc(f) {
+			f += 0.5
+		}
+		return f
+	case 4:
+		return g.str(g.strRs, g.rng.Intn(g.spec.MaxStringLen+1))
+	case 5:
+		a := make([]any, g.rng.Intn(g.spec.MaxWidth+1))
+		for i := range a {
+			a[i] = g.value(depth + 1)
+		}
+		return a
+	case 6:
+		cnt := g.rng.Intn(g.spec.MaxWidth + 1)
+		obj := make(map[string]any, cnt)
+		for i := 0; i < cnt; i++ {
+			obj[g.str(g.keyRs, g.rng.Intn(g.spec.MaxKeyLen)+1)] = g.value(depth + 1)
+		}
+		return obj
+	}
+	return nil
+}
+
+func (g *docGen) str(rs []rune, size int) string {
+	s := make([]rune, size)
+	for i := range s {
+		s[i] = rs[g.rng.Intn(len(rs))]
+	}
+	return string(s)
+}
+
+// Shrink returns documents that are each one step smaller or simpler than
+// the provided document. Containers are replaced by each of their children
+// and by copies with one element or member removed. Scalars are replaced by
+// simpler values such as zero, false, nil, or a shorter string. An empty
+// slice is returned if the document can not be made smaller.
+func Shrink(doc any) (smaller []any) {
+	switch td := doc.(type) {
+	case nil:
+	case bool:
+		if td {
+			smaller = append(smaller, false)
+		}
+		smaller = append(smaller, nil)
+	case int64:
+		if td != 0 {
+			smaller = append(smaller, int64(0), td/2)
+		}
+		smaller = append(smaller, nil)
+	case float64:
+		if f := math.Trunc(td/2) + 0.5; f != td {
+			smaller = append(smaller, f)
+		}
+		smaller = append(smaller, nil)
+	case string:
+		if 0 < len(td) {
+			rs := []rune(td)
+			smaller = append(smaller, "", string(rs[:len(rs)/2]), string(rs[1:]))
+		}
+		smaller = append(smaller, nil)
+	case []any:
+		smaller = append(smaller, td...)
+		for i := range td {
+			a := make([]any, 0, len(td)-1)
+			a = append(a, td[:i]...)
+			smaller = append(smaller, append(a, td[i+1:]...))
+		}
+		for i, v := range td {
+			for _, sv := range Shrink(v) {
+				a := make([]any, len(td))
+				copy(a, td)
+				a[i] = sv
+				smaller = append(smaller, a)
+			}
+		}
+	case map[string]any:
+		keys := sortedKeys(td)
+		for _, k := range keys {
+			smaller = append(smaller, td[k])
+		}
+		for _, k := range keys {
+			obj := make(map[string]any, len(td)-1)
+			for k2, v := range td {
+				if k2 != k {
+					obj[k2] = v
+				}
+			}
+			smaller = append(smaller, obj)
+		}
+		for _, k := range keys {
+			for _, sv := range Shrink(td[k]) {
+				obj := make(map[string]any, len(td))
+				for k2, v := range td {
+					obj[k2] = v
+				}
+				obj[k] = sv
+				smaller = append(smaller, obj)
+			}
+		}
+	}
+	return
+}
+
+// Minimize repeatedly shrinks a document for which fails returns true until
+// no smaller document fails. The smallest failing document found is
+// returned which makes the cause of a property test failure easier to see.
+func Minimize(doc any, fails func(doc any) bool) any {
+	for {
+		shrunk := false
+		for _, s := range Shrink(doc) {
+			if fails(s) {
+				doc = s
+				shrunk = true
+				break
+			}
+		}
+		if !shrunk {
+			return doc
+		}
+	}
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
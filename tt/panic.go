@@ -20,6 +20,5 @@ func Panic(t *testing.T, fun func(), args ...any) (r any) {
 		}
 	}()
 	fun()
-	ff()
 	return
 }
@@ -0,0 +1,130 @@
+// Copyright (c) 2025, Peter Ohler, All rights reserved.
+
+package tt
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ohler55/ojg/gen"
+)
+
+// FailurePrefix starts the line with the JSON description of an Equal
+// failure when JSONFailures is true.
+const FailurePrefix = "tt-failure: "
+
+// JSONFailures if true adds a line to the output of an Equal failure that
+// starts with FailurePrefix and is followed by a JSON object with the
+// expect and actual values and the JSONPath of the first difference. Tools
+// can parse the line, after trimming the indentation added by the testing
+// package, to display a richer diff. The initial value is true if
+// the TT_JSON_FAILURES environment variable is set to a non-empty value.
+var JSONFailures = os.Getenv("TT_JSON_FAILURES") != ""
+
+type failure struct {
+	Expect any    `json:"expect"`
+	Actual any    `json:"actual"`
+	Path   string `json:"path"`
+}
+
+func writeJSONFailure(b *strings.Builder, expect, actual any) {
+	f := failure{
+		Expect: jsonSafe(expect),
+		Actual: jsonSafe(actual),
+		Path:   firstDiff("$", expect, actual),
+	}
+	j, err := json.Marshal(&f)
+	if err != nil {
+		return
+	}
+	b.WriteString(FailurePrefix)
+	b.Write(j)
+	b.WriteByte('\n')
+}
+
+// jsonSafe returns the value if it can be encoded as JSON and a string
+// representation otherwise.
+func jsonSafe(v any) any {
+	if n, ok := v.(gen.Node); ok {
+		v = n.Simplify()
+	}
+	if _, err := json.Marshal(v); err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return v
+}
+
+// firstDiff returns the JSONPath of the first difference between the
+// expected and actual values. Object members are checked in key order.
+func firstDiff(path string, expect, actual any) string {
+	switch te := expect.(type) {
+	case gen.Array:
+		expect = te.Simplify()
+	case gen.Object:
+		expect = te.Simplify()
+	}
+	switch ta := actual.(type) {
+	case gen.Array:
+		actual = ta.Simplify()
+	case gen.Object:
+		actual = ta.Simplify()
+	}
+	switch te := expect.(type) {
+	case []any:
+		ta, ok := actual.([]any)
+		if !ok {
+			return path
+		}
+		for i, ve := range te {
+			p := path + "[" + strconv.Itoa(i) + "]"
+			if len(ta) <= i {
+				return p
+			}
+			if !valuesEqual(ve, ta[i]) {
+				return firstDiff(p, ve, ta[i])
+			}
+		}
+		if len(te) < len(ta) {
+			return path + "[" + strconv.Itoa(len(te)) + "]"
+		}
+	case map[string]any:
+		ta, ok := actual.(map[string]any)
+		if !ok {
+			return path
+		}
+		keys := make([]string, 0, len(te)+len(ta))
+		for k := range te {
+			keys = append(keys, k)
+		}
+		for k := range ta {
+			if _, has := te[k]; !has {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			ve, has := te[k]
+			va, has2 := ta[k]
+			if !has || !has2 || !valuesEqual(ve, va) {
+				return firstDiff(path+childPath(k), ve, va)
+			}
+		}
+	}
+	return path
+}
+
+func childPath(key string) string {
+	if len(key) == 0 {
+		return `[""]`
+	}
+	for i, b := range []byte(key) {
+		if !('a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' || b == '_' || (0 < i && '0' <= b && b <= '9')) {
+			return "[" + strconv.Quote(key) + "]"
+		}
+	}
+	return "." + key
+}
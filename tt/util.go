@@ -50,7 +50,7 @@ func stackFill(b *strings.Builder) {
 }
 
 func isNil(v any) bool {
-	return (*[2]uintptr)(unsafe.Pointer(&v))[1] == 0
+	return v == nil || (*[2]uintptr)(unsafe.Pointer(&v))[1] == 0
 }
 
 func asInt(v any) (i int64, ok bool) {